@@ -0,0 +1,4 @@
+-- Rollback of: 005_course_settings
+-- Description: Drops the per-course settings; courses fall back to the application defaults.
+
+ALTER TABLE courses DROP COLUMN IF EXISTS settings;
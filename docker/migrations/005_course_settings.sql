@@ -0,0 +1,9 @@
+-- Migration: Add per-course settings
+-- Description: Adds a JSONB settings column on courses holding per-course settings
+-- such as the retry window. Unset keys use application defaults.
+
+BEGIN;
+
+ALTER TABLE courses ADD COLUMN IF NOT EXISTS settings JSONB NOT NULL DEFAULT '{}'::jsonb;
+
+COMMIT;
@@ -0,0 +1,4 @@
+-- Rollback of: 006_submission_result_timing
+-- Description: Drops the per-test-case execution time.
+
+ALTER TABLE submission_results DROP COLUMN IF EXISTS execution_time_ms;
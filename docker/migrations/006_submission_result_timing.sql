@@ -0,0 +1,8 @@
+-- Migration: Add per-test-case execution timing
+-- Description: Stores wall-clock execution time (ms) for each submission result
+
+BEGIN;
+
+ALTER TABLE submission_results ADD COLUMN IF NOT EXISTS execution_time_ms BIGINT NOT NULL DEFAULT 0;
+
+COMMIT;
@@ -0,0 +1,4 @@
+-- Rollback of: 007_course_api_keys
+-- Description: Drops the course API keys; integrations using them stop working.
+
+DROP TABLE IF EXISTS course_api_keys;
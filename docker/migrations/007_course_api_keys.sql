@@ -0,0 +1,25 @@
+-- Migration: Add course-scoped API keys
+-- Description: Creates course_api_keys for read-only LMS integrations. Each key is bound
+-- to a list of course IDs (JSONB array); only a SHA-256 hash of the raw key is stored.
+
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS course_api_keys (
+    key_id VARCHAR(36) PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    key_hash VARCHAR(64) NOT NULL,
+    key_prefix VARCHAR(16) NOT NULL,
+    course_ids JSONB NOT NULL,
+    role VARCHAR(20) NOT NULL DEFAULT 'read_only',
+    created_by VARCHAR(36) NOT NULL,
+    expires_at TIMESTAMP,
+    revoked_at TIMESTAMP,
+    last_used_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_course_api_keys_key_hash ON course_api_keys(key_hash);
+CREATE INDEX IF NOT EXISTS idx_course_api_keys_created_by ON course_api_keys(created_by);
+
+COMMIT;
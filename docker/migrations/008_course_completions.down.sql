@@ -0,0 +1,4 @@
+-- Rollback of: 008_course_completions
+-- Description: Drops the recorded course completions.
+
+DROP TABLE IF EXISTS course_completions;
@@ -0,0 +1,19 @@
+-- Migration: Add course completions
+-- Description: Records when a student first completes every exercise in a course.
+-- The unique (user_id, course_id) index guards against duplicate course.completed events.
+
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS course_completions (
+    completion_id VARCHAR(36) PRIMARY KEY,
+    user_id VARCHAR(36) NOT NULL,
+    course_id VARCHAR(36) NOT NULL,
+    completed_at TIMESTAMP NOT NULL,
+    notified_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_course_completion_user_course ON course_completions(user_id, course_id);
+CREATE INDEX IF NOT EXISTS idx_course_completions_course_id ON course_completions(course_id);
+
+COMMIT;
@@ -0,0 +1,4 @@
+-- Rollback of: 009_submission_submitted_by
+-- Description: Drops the record of teachers submitting on a student's behalf.
+
+ALTER TABLE submissions DROP COLUMN IF EXISTS submitted_by;
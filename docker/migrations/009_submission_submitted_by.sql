@@ -0,0 +1,9 @@
+-- Migration: Add submitted_by to submissions
+-- Description: Records the teacher who submitted code on a student's behalf.
+-- NULL means the student submitted it themselves.
+
+BEGIN;
+
+ALTER TABLE submissions ADD COLUMN IF NOT EXISTS submitted_by VARCHAR(36);
+
+COMMIT;
@@ -0,0 +1,5 @@
+-- Rollback of: 010_unique_enrollments
+-- Description: Drops the unique enrollment index. Duplicate enrollments removed by the
+-- migration are not restored.
+
+DROP INDEX IF EXISTS idx_enrollments_course_user;
@@ -0,0 +1,22 @@
+-- Migration: Enforce one enrollment per user per course
+-- Description: Removes duplicate enrollment rows (keeping the highest role, ties broken by earliest)
+-- and adds a unique index on (course_id, user_id) so duplicates cannot be created again.
+
+BEGIN;
+
+DELETE FROM enrollments e
+USING (
+    SELECT enrollment_id,
+           ROW_NUMBER() OVER (
+               PARTITION BY course_id, user_id
+               ORDER BY CASE role WHEN 'teacher' THEN 0 WHEN 'ta' THEN 1 ELSE 2 END,
+                        enrolled_at ASC,
+                        enrollment_id
+           ) AS rn
+    FROM enrollments
+) d
+WHERE e.enrollment_id = d.enrollment_id AND d.rn > 1;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_enrollments_course_user ON enrollments(course_id, user_id);
+
+COMMIT;
@@ -0,0 +1,4 @@
+-- Rollback of: 011_user_sessions
+-- Description: Drops the per-device sessions; their holders have to log in again.
+
+DROP TABLE IF EXISTS user_sessions;
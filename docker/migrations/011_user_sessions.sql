@@ -0,0 +1,21 @@
+-- Migration: Add per-device login sessions
+-- Description: Each login gets its own row holding that device's refresh token so that
+-- sessions can be listed and revoked individually. Tokens stored on users stay in place
+-- for sessions issued before this migration and are moved over on their next refresh.
+
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS user_sessions (
+    session_id VARCHAR(36) PRIMARY KEY,
+    user_id VARCHAR(36) NOT NULL,
+    refresh_token TEXT,
+    user_agent TEXT,
+    ip_address VARCHAR(64),
+    last_used_at TIMESTAMP NOT NULL,
+    revoked_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_sessions_user_id ON user_sessions(user_id);
+
+COMMIT;
@@ -0,0 +1,4 @@
+-- Rollback of: 012_code_exercise_output_mode
+-- Description: Drops the output mode; every code exercise compares JSON again.
+
+ALTER TABLE code_exercises DROP COLUMN IF EXISTS output_mode;
@@ -0,0 +1,8 @@
+-- Migration: Add output comparison mode to code exercises
+-- Description: 'json' (default) parses student stdout as JSON; 'plain' compares trimmed text.
+
+BEGIN;
+
+ALTER TABLE code_exercises ADD COLUMN IF NOT EXISTS output_mode VARCHAR(10) NOT NULL DEFAULT 'json';
+
+COMMIT;
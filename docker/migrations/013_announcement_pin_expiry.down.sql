@@ -0,0 +1,7 @@
+-- Rollback of: 013_announcement_pin_expiry
+-- Description: Drops announcement pinning and expiry.
+
+DROP INDEX IF EXISTS idx_announcements_course_pinned;
+
+ALTER TABLE announcements DROP COLUMN IF EXISTS expires_at;
+ALTER TABLE announcements DROP COLUMN IF EXISTS is_pinned;
@@ -0,0 +1,11 @@
+-- Migration: Add pinning and expiry to announcements
+-- Description: Pinned announcements are listed first; expired announcements are hidden from students.
+
+BEGIN;
+
+ALTER TABLE announcements ADD COLUMN IF NOT EXISTS is_pinned BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE announcements ADD COLUMN IF NOT EXISTS expires_at TIMESTAMP;
+
+CREATE INDEX IF NOT EXISTS idx_announcements_course_pinned ON announcements(course_id, is_pinned, created_at);
+
+COMMIT;
@@ -0,0 +1,4 @@
+-- Rollback of: 014_pdf_exercise_max_pages
+-- Description: Drops the per-exercise page limit; SUBMISSION_PDF_MAX_PAGES applies to every PDF exercise.
+
+ALTER TABLE pdf_exercises DROP COLUMN IF EXISTS max_pages;
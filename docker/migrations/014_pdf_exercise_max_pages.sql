@@ -0,0 +1,8 @@
+-- Migration: Add per-exercise page limit to PDF exercises
+-- Description: NULL falls back to SUBMISSION_PDF_MAX_PAGES; 0 allows any number of pages.
+
+BEGIN;
+
+ALTER TABLE pdf_exercises ADD COLUMN IF NOT EXISTS max_pages INT;
+
+COMMIT;
@@ -0,0 +1,5 @@
+-- Rollback of: 015_exercise_lock_after_approval
+-- Description: Drops the lock-after-approval policy.
+
+ALTER TABLE pdf_exercises DROP COLUMN IF EXISTS lock_after_approval;
+ALTER TABLE code_exercises DROP COLUMN IF EXISTS lock_after_approval;
@@ -0,0 +1,9 @@
+-- Migration: Add lock-after-approval policy to exercises
+-- Description: When set, students cannot resubmit once their progress on the exercise is completed.
+
+BEGIN;
+
+ALTER TABLE code_exercises ADD COLUMN IF NOT EXISTS lock_after_approval BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE pdf_exercises ADD COLUMN IF NOT EXISTS lock_after_approval BOOLEAN NOT NULL DEFAULT FALSE;
+
+COMMIT;
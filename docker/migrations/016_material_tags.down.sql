@@ -0,0 +1,8 @@
+-- Rollback of: 016_material_tags
+-- Description: Drops material tags.
+
+ALTER TABLE announcements DROP COLUMN IF EXISTS tags;
+ALTER TABLE videos DROP COLUMN IF EXISTS tags;
+ALTER TABLE documents DROP COLUMN IF EXISTS tags;
+ALTER TABLE pdf_exercises DROP COLUMN IF EXISTS tags;
+ALTER TABLE code_exercises DROP COLUMN IF EXISTS tags;
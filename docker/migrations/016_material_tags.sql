@@ -0,0 +1,12 @@
+-- Migration: Add tags to materials
+-- Description: Free-form, lowercase labels (e.g. "recursion") for organizing materials across weeks; filterable in the materials listing.
+
+BEGIN;
+
+ALTER TABLE code_exercises ADD COLUMN IF NOT EXISTS tags JSONB NOT NULL DEFAULT '[]'::jsonb;
+ALTER TABLE pdf_exercises ADD COLUMN IF NOT EXISTS tags JSONB NOT NULL DEFAULT '[]'::jsonb;
+ALTER TABLE documents ADD COLUMN IF NOT EXISTS tags JSONB NOT NULL DEFAULT '[]'::jsonb;
+ALTER TABLE videos ADD COLUMN IF NOT EXISTS tags JSONB NOT NULL DEFAULT '[]'::jsonb;
+ALTER TABLE announcements ADD COLUMN IF NOT EXISTS tags JSONB NOT NULL DEFAULT '[]'::jsonb;
+
+COMMIT;
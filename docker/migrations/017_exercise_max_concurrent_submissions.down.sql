@@ -0,0 +1,8 @@
+-- Rollback of: 017_exercise_max_concurrent_submissions
+-- Description: Drops the concurrency cap. Jobs still held are left pending and are not published;
+-- resubmit or retry them after rolling back.
+
+DROP INDEX IF EXISTS idx_queue_jobs_held_material;
+
+ALTER TABLE queue_jobs DROP COLUMN IF EXISTS held;
+ALTER TABLE code_exercises DROP COLUMN IF EXISTS max_concurrent_submissions;
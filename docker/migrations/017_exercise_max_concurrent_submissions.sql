@@ -0,0 +1,12 @@
+-- Migration: Add a per-exercise cap on concurrently graded submissions
+-- Description: Code exercises may limit how many of their submissions are graded at once (e.g. during an exam).
+-- Code execution jobs over the cap are stored held and published as running ones finish.
+
+BEGIN;
+
+ALTER TABLE code_exercises ADD COLUMN IF NOT EXISTS max_concurrent_submissions INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE queue_jobs ADD COLUMN IF NOT EXISTS held BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE INDEX IF NOT EXISTS idx_queue_jobs_held_material ON queue_jobs (material_id, created_at) WHERE held;
+
+COMMIT;
@@ -0,0 +1,5 @@
+-- Rollback of: 018_exercise_prerequisites
+-- Description: Drops exercise prerequisites.
+
+ALTER TABLE pdf_exercises DROP COLUMN IF EXISTS prerequisites;
+ALTER TABLE code_exercises DROP COLUMN IF EXISTS prerequisites;
@@ -0,0 +1,9 @@
+-- Migration: Add prerequisites to exercises
+-- Description: Material IDs of exercises a student must complete before submitting to this one.
+
+BEGIN;
+
+ALTER TABLE code_exercises ADD COLUMN IF NOT EXISTS prerequisites JSONB NOT NULL DEFAULT '[]'::jsonb;
+ALTER TABLE pdf_exercises ADD COLUMN IF NOT EXISTS prerequisites JSONB NOT NULL DEFAULT '[]'::jsonb;
+
+COMMIT;
@@ -0,0 +1,4 @@
+-- Rollback of: 019_exercise_trim_whitespace
+-- Description: Drops the whitespace trimming policy.
+
+ALTER TABLE code_exercises DROP COLUMN IF EXISTS trim_whitespace;
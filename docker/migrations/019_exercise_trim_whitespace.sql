@@ -0,0 +1,8 @@
+-- Migration: Add whitespace trimming policy to code exercises
+-- Description: When set (the default), leading and trailing whitespace is ignored when comparing output.
+
+BEGIN;
+
+ALTER TABLE code_exercises ADD COLUMN IF NOT EXISTS trim_whitespace BOOLEAN NOT NULL DEFAULT TRUE;
+
+COMMIT;
@@ -0,0 +1,4 @@
+-- Rollback of: 020_exercise_number_tolerance
+-- Description: Drops the numeric tolerance.
+
+ALTER TABLE code_exercises DROP COLUMN IF EXISTS number_tolerance;
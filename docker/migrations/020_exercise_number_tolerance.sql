@@ -0,0 +1,8 @@
+-- Migration: Add numeric tolerance to code exercises
+-- Description: How far apart numbers in JSON output may be and still match (relative above 1).
+
+BEGIN;
+
+ALTER TABLE code_exercises ADD COLUMN IF NOT EXISTS number_tolerance DOUBLE PRECISION NOT NULL DEFAULT 1e-9;
+
+COMMIT;
@@ -0,0 +1,6 @@
+-- Rollback of: 021_test_case_input_key
+-- Description: Drops stored test case inputs. Test cases that used one fall back to their
+-- inline input_data, which is usually empty, so update them first; the stored objects are
+-- left in storage.
+
+ALTER TABLE test_cases DROP COLUMN IF EXISTS input_key;
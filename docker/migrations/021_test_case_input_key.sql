@@ -0,0 +1,8 @@
+-- Migration: Add stored inputs to test cases
+-- Description: Storage key of a test case input too large to keep inline; input_data is unused when set.
+
+BEGIN;
+
+ALTER TABLE test_cases ADD COLUMN IF NOT EXISTS input_key TEXT;
+
+COMMIT;
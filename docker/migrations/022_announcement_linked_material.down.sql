@@ -0,0 +1,6 @@
+-- Rollback of: 022_announcement_linked_material
+-- Description: Drops the link from announcements to the material they were posted for.
+
+DROP INDEX IF EXISTS idx_announcements_linked_material_id;
+
+ALTER TABLE announcements DROP COLUMN IF EXISTS linked_material_id;
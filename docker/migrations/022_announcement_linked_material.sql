@@ -0,0 +1,9 @@
+-- Migration: Link announcements to the material they were posted for
+-- Description: Set when a course auto-announces newly published materials.
+
+BEGIN;
+
+ALTER TABLE announcements ADD COLUMN IF NOT EXISTS linked_material_id VARCHAR(36);
+CREATE INDEX IF NOT EXISTS idx_announcements_linked_material_id ON announcements(linked_material_id);
+
+COMMIT;
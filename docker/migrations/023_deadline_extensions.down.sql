@@ -0,0 +1,4 @@
+-- Rollback of: 023_deadline_extensions
+-- Description: Drops per-student deadline extensions.
+
+DROP TABLE IF EXISTS deadline_extensions;
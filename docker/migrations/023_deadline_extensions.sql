@@ -0,0 +1,22 @@
+-- Migration: Add per-student deadline extensions
+-- Description: A student with an extension may submit to the exercise until the extended
+-- deadline. One row per student and exercise; granting again replaces the deadline, reason
+-- and granting teacher.
+
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS deadline_extensions (
+    extension_id VARCHAR(36) PRIMARY KEY,
+    material_id VARCHAR(36) NOT NULL,
+    user_id VARCHAR(36) NOT NULL,
+    deadline TIMESTAMP NOT NULL,
+    reason TEXT NOT NULL,
+    granted_by VARCHAR(36) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_deadline_extension_material_user ON deadline_extensions(material_id, user_id);
+CREATE INDEX IF NOT EXISTS idx_deadline_extensions_user_id ON deadline_extensions(user_id);
+
+COMMIT;
@@ -0,0 +1,9 @@
+-- Rollback of: 024_exercise_max_attempts
+-- Description: Drops attempt limits and counts, and the unique progress index. Duplicate
+-- progress rows removed by the migration are not restored.
+
+DROP INDEX IF EXISTS idx_student_progress_user_material;
+
+ALTER TABLE student_progress DROP COLUMN IF EXISTS attempt_count;
+ALTER TABLE pdf_exercises DROP COLUMN IF EXISTS max_attempts;
+ALTER TABLE code_exercises DROP COLUMN IF EXISTS max_attempts;
@@ -0,0 +1,35 @@
+-- Migration: Add attempt limits to exercises
+-- Description: Exercises may cap how many times a student submits (0 = unlimited). The count
+-- lives on student_progress and is claimed with a single upsert, which needs one progress row
+-- per student and material: duplicates are removed (keeping the most recently updated) and a
+-- unique index added. Existing counts are backfilled from submissions.
+
+BEGIN;
+
+ALTER TABLE code_exercises ADD COLUMN IF NOT EXISTS max_attempts INT NOT NULL DEFAULT 0;
+ALTER TABLE pdf_exercises ADD COLUMN IF NOT EXISTS max_attempts INT NOT NULL DEFAULT 0;
+ALTER TABLE student_progress ADD COLUMN IF NOT EXISTS attempt_count INT NOT NULL DEFAULT 0;
+
+DELETE FROM student_progress p
+USING (
+    SELECT progress_id,
+           ROW_NUMBER() OVER (
+               PARTITION BY user_id, material_id
+               ORDER BY updated_at DESC, progress_id
+           ) AS rn
+    FROM student_progress
+) d
+WHERE p.progress_id = d.progress_id AND d.rn > 1;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_student_progress_user_material ON student_progress(user_id, material_id);
+
+UPDATE student_progress p
+SET attempt_count = s.attempts
+FROM (
+    SELECT user_id, material_id, COUNT(*) AS attempts
+    FROM submissions
+    GROUP BY user_id, material_id
+) s
+WHERE p.user_id = s.user_id AND p.material_id = s.material_id;
+
+COMMIT;
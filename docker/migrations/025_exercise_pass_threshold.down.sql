@@ -0,0 +1,4 @@
+-- Rollback of: 025_exercise_pass_threshold
+-- Description: Drops the pass threshold; every test case must pass again.
+
+ALTER TABLE code_exercises DROP COLUMN IF EXISTS pass_threshold;
@@ -0,0 +1,9 @@
+-- Migration: Add pass threshold to code exercises
+-- Description: Percentage of test cases a submission must pass for the student's progress to
+-- become ready for review. Defaults to 100, every test case, as before.
+
+BEGIN;
+
+ALTER TABLE code_exercises ADD COLUMN IF NOT EXISTS pass_threshold INT NOT NULL DEFAULT 100;
+
+COMMIT;
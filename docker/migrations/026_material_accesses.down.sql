@@ -0,0 +1,4 @@
+-- Rollback of: 026_material_accesses
+-- Description: Drops the recorded material accesses.
+
+DROP TABLE IF EXISTS material_accesses;
@@ -0,0 +1,17 @@
+-- Migration: Add material access tracking
+-- Description: One row per time a student views or downloads a document or video material,
+-- for the engagement report. Repeats by the same student within a few minutes are not recorded.
+
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS material_accesses (
+    access_id VARCHAR(36) PRIMARY KEY,
+    material_id VARCHAR(36) NOT NULL,
+    user_id VARCHAR(36) NOT NULL,
+    kind VARCHAR(20) NOT NULL,
+    accessed_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_material_access_material_user ON material_accesses(material_id, user_id);
+
+COMMIT;
@@ -0,0 +1,8 @@
+-- Rollback of: 027_exercise_submission_window
+-- Description: Drops submission windows; only deadlines apply again.
+
+ALTER TABLE pdf_exercises DROP COLUMN IF EXISTS closes_at;
+ALTER TABLE pdf_exercises DROP COLUMN IF EXISTS opens_at;
+
+ALTER TABLE code_exercises DROP COLUMN IF EXISTS closes_at;
+ALTER TABLE code_exercises DROP COLUMN IF EXISTS opens_at;
@@ -0,0 +1,14 @@
+-- Migration: Add submission windows to exercises
+-- Description: Optional times before and after which students cannot submit, e.g. for a
+-- timed lab. Inside the window the deadline only marks submissions late. NULL leaves that
+-- side of the window open, as before.
+
+BEGIN;
+
+ALTER TABLE code_exercises ADD COLUMN IF NOT EXISTS opens_at TIMESTAMP;
+ALTER TABLE code_exercises ADD COLUMN IF NOT EXISTS closes_at TIMESTAMP;
+
+ALTER TABLE pdf_exercises ADD COLUMN IF NOT EXISTS opens_at TIMESTAMP;
+ALTER TABLE pdf_exercises ADD COLUMN IF NOT EXISTS closes_at TIMESTAMP;
+
+COMMIT;
@@ -0,0 +1,4 @@
+-- Rollback of: 028_submission_cancellation
+-- Description: Drops the progress status kept for cancelling a submission.
+
+ALTER TABLE submissions DROP COLUMN IF EXISTS prior_progress_status;
@@ -0,0 +1,9 @@
+-- Migration: Allow code submissions to be cancelled
+-- Description: Records the student's progress status before a code submission reset it,
+-- so that cancelling the submission before it is graded can put it back.
+
+BEGIN;
+
+ALTER TABLE submissions ADD COLUMN IF NOT EXISTS prior_progress_status VARCHAR(20);
+
+COMMIT;
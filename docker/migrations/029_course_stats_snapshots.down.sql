@@ -0,0 +1,4 @@
+-- Rollback of: 029_course_stats_snapshots
+-- Description: Drops the course statistics snapshots.
+
+DROP TABLE IF EXISTS course_stats_snapshots;
@@ -0,0 +1,21 @@
+-- Migration: Add course statistics snapshots
+-- Description: Per-course aggregates (students, exercises, completion rate, average score,
+-- submissions) taken by a nightly scheduled task, so dashboards and reports read the latest
+-- snapshot instead of aggregating on every request.
+
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS course_stats_snapshots (
+    snapshot_id VARCHAR(36) PRIMARY KEY,
+    course_id VARCHAR(36) NOT NULL,
+    student_count BIGINT NOT NULL DEFAULT 0,
+    exercise_count BIGINT NOT NULL DEFAULT 0,
+    completion_rate DOUBLE PRECISION NOT NULL DEFAULT 0,
+    average_score DOUBLE PRECISION NOT NULL DEFAULT 0,
+    submission_count BIGINT NOT NULL DEFAULT 0,
+    taken_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_course_stats_course_taken ON course_stats_snapshots(course_id, taken_at);
+
+COMMIT;
@@ -0,0 +1,7 @@
+-- Rollback of: 030_submission_result_cache
+-- Description: Drops the result cache key; identical submissions are run again.
+
+DROP INDEX IF EXISTS idx_submission_result_cache;
+
+ALTER TABLE submissions DROP COLUMN IF EXISTS test_set_hash;
+ALTER TABLE submissions DROP COLUMN IF EXISTS code_hash;
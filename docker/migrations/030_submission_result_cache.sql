@@ -0,0 +1,13 @@
+-- Migration: Add the code submission result cache key
+-- Description: Hashes of a graded code submission's code and of the test cases it was graded
+-- against. With the result_cache_enabled course setting, an identical later submission reuses
+-- its results instead of running the code again. Both stay NULL for runs that are not reusable.
+
+BEGIN;
+
+ALTER TABLE submissions ADD COLUMN IF NOT EXISTS code_hash VARCHAR(64);
+ALTER TABLE submissions ADD COLUMN IF NOT EXISTS test_set_hash VARCHAR(64);
+
+CREATE INDEX IF NOT EXISTS idx_submission_result_cache ON submissions(code_hash, test_set_hash);
+
+COMMIT;
@@ -6,8 +6,10 @@ import (
 	"log"
 	"os"
 	"path/filepath"
+	"regexp"
 	"sort"
 	"strings"
+	"time"
 
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
@@ -17,13 +19,30 @@ var (
 	dbURL         = flag.String("db-url", "", "Database connection URL (required)")
 	migrationsDir = flag.String("migrations-dir", "./migrations", "Directory containing migration files")
 	up            = flag.Bool("up", false, "Run migrations up")
-	down          = flag.Bool("down", false, "Run migrations down")
+	down          = flag.Bool("down", false, "Roll back the latest applied migrations using their .down.sql files")
+	steps         = flag.Int("steps", 1, "Number of migrations -down rolls back")
 	version       = flag.Bool("version", false, "Show current migration version")
 )
 
 func main() {
+	flag.Usage = usage
 	flag.Parse()
 
+	// migrate [flags] new <name> [flags] needs no database
+	if flag.Arg(0) == "new" {
+		if flag.NArg() < 2 {
+			log.Fatal("Usage: migrate [-migrations-dir DIR] new <name>")
+		}
+		name := flag.Arg(1)
+		if err := flag.CommandLine.Parse(flag.Args()[2:]); err != nil {
+			os.Exit(2)
+		}
+		if err := newMigration(*migrationsDir, name, time.Now()); err != nil {
+			log.Fatalf("Failed to create migration: %v", err)
+		}
+		return
+	}
+
 	if *dbURL == "" {
 		// Try to get from environment
 		*dbURL = os.Getenv("DATABASE_URL")
@@ -76,7 +95,9 @@ func main() {
 	}
 
 	if *down {
-		log.Println("Down migrations not implemented yet")
+		if err := runMigrationsDown(db, *migrationsDir, *steps); err != nil {
+			log.Fatalf("Failed to run migrations down: %v", err)
+		}
 		return
 	}
 
@@ -84,6 +105,63 @@ func main() {
 	flag.Usage()
 }
 
+func usage() {
+	out := flag.CommandLine.Output()
+	fmt.Fprintln(out, "Usage:")
+	fmt.Fprintln(out, "  migrate [flags] -up | -down [-steps N] | -version")
+	fmt.Fprintln(out, "  migrate [-migrations-dir DIR] new <name>")
+	fmt.Fprintln(out, "Flags:")
+	flag.PrintDefaults()
+}
+
+// migrationTimeFormat prefixes generated migration files, so they sort in creation order
+// after the hand-numbered ones
+const migrationTimeFormat = "20060102150405"
+
+// nonNameChars are replaced by underscores in a generated migration's name
+var nonNameChars = regexp.MustCompile(`[^a-z0-9]+`)
+
+// newMigration creates an empty up/down pair named <UTC timestamp>_<name> in
+// migrationsDir. It refuses to overwrite existing files.
+func newMigration(migrationsDir, name string, now time.Time) error {
+	slug := strings.Trim(nonNameChars.ReplaceAllString(strings.ToLower(name), "_"), "_")
+	if slug == "" {
+		return fmt.Errorf("migration name %q has no letters or digits", name)
+	}
+	if err := os.MkdirAll(migrationsDir, 0o755); err != nil {
+		return fmt.Errorf("failed to create migrations directory: %w", err)
+	}
+
+	version := now.UTC().Format(migrationTimeFormat) + "_" + slug
+	created := now.UTC().Format(time.RFC3339)
+	files := []struct {
+		name, header string
+	}{
+		{version + upSuffix, fmt.Sprintf("-- Migration: %s\n-- Created: %s\n-- Rolled back by %s%s\n\n", version, created, version, downSuffix)},
+		{version + downSuffix, fmt.Sprintf("-- Rollback of: %s\n-- Created: %s\n-- Undoes %s%s; runs on migrate -down\n\n", version, created, version, upSuffix)},
+	}
+
+	for i, file := range files {
+		path := filepath.Join(migrationsDir, file.name)
+		f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+		if err != nil {
+			if i > 0 {
+				os.Remove(filepath.Join(migrationsDir, files[0].name))
+			}
+			return fmt.Errorf("failed to create %s: %w", file.name, err)
+		}
+		_, err = f.WriteString(file.header)
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			return fmt.Errorf("failed to write %s: %w", file.name, err)
+		}
+		log.Printf("Created %s", path)
+	}
+	return nil
+}
+
 func createMigrationsTable(db *gorm.DB) error {
 	query := `
 		CREATE TABLE IF NOT EXISTS schema_migrations (
@@ -114,10 +192,10 @@ func runMigrationsUp(db *gorm.DB, migrationsDir string) error {
 		return fmt.Errorf("failed to read migrations directory: %w", err)
 	}
 
-	// Filter and sort migration files
+	// Filter and sort migration files; rollback files only run on -down
 	var migrationFiles []string
 	for _, file := range files {
-		if !file.IsDir() && strings.HasSuffix(file.Name(), ".sql") {
+		if !file.IsDir() && strings.HasSuffix(file.Name(), ".sql") && !strings.HasSuffix(file.Name(), downSuffix) {
 			migrationFiles = append(migrationFiles, file.Name())
 		}
 	}
@@ -131,7 +209,7 @@ func runMigrationsUp(db *gorm.DB, migrationsDir string) error {
 
 	// Run each migration
 	for _, filename := range migrationFiles {
-		version := strings.TrimSuffix(filename, ".sql")
+		version := migrationVersion(filename)
 
 		// Skip if already applied
 		if appliedMigrations[version] {
@@ -173,6 +251,84 @@ func runMigrationsUp(db *gorm.DB, migrationsDir string) error {
 	return nil
 }
 
+// Migration files are NNN_name.sql or NNN_name.up.sql, both recording version NNN_name,
+// and are rolled back by NNN_name.down.sql when there is one
+const (
+	upSuffix   = ".up.sql"
+	downSuffix = ".down.sql"
+)
+
+// migrationVersion returns the version an up migration file records
+func migrationVersion(filename string) string {
+	if strings.HasSuffix(filename, upSuffix) {
+		return strings.TrimSuffix(filename, upSuffix)
+	}
+	return strings.TrimSuffix(filename, ".sql")
+}
+
+// runMigrationsDown rolls back the latest steps applied migrations, newest first. Each
+// runs its .down.sql file and removes its schema_migrations row in one transaction.
+// Every rollback file is checked to exist before anything runs, so a migration without
+// one stops the rollback up front rather than half way. In docker/migrations the schema
+// migrations before 005 and the 01_/02_ seed data files have none; the seed files sort
+// among the numbered migrations, so a rollback reaching them is refused as well.
+func runMigrationsDown(db *gorm.DB, migrationsDir string, steps int) error {
+	if steps < 1 {
+		return fmt.Errorf("-steps must be at least 1, got %d", steps)
+	}
+
+	var versions []string
+	if err := db.Raw("SELECT version FROM schema_migrations").Scan(&versions).Error; err != nil {
+		return fmt.Errorf("failed to get applied migrations: %w", err)
+	}
+	if len(versions) == 0 {
+		log.Println("No migrations to roll back")
+		return nil
+	}
+	// Newest first, in the order -up applies them (the database collation may sort differently)
+	sort.Sort(sort.Reverse(sort.StringSlice(versions)))
+	if len(versions) < steps {
+		log.Printf("Only %d migrations are applied, rolling back all of them", len(versions))
+	} else {
+		versions = versions[:steps]
+	}
+
+	contents := make([][]byte, len(versions))
+	for i, version := range versions {
+		filename := version + downSuffix
+		content, err := os.ReadFile(filepath.Join(migrationsDir, filename))
+		if os.IsNotExist(err) {
+			return fmt.Errorf("migration %s has no %s, nothing was rolled back", version, filename)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read migration file %s: %w", filename, err)
+		}
+		contents[i] = content
+	}
+
+	for i, version := range versions {
+		log.Printf("Rolling back migration: %s", version)
+
+		err := db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Exec(string(contents[i])).Error; err != nil {
+				return fmt.Errorf("failed to execute rollback of %s: %w", version, err)
+			}
+			if err := tx.Exec("DELETE FROM schema_migrations WHERE version = ?", version).Error; err != nil {
+				return fmt.Errorf("failed to remove migration record %s: %w", version, err)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		log.Printf("Successfully rolled back migration: %s", version)
+	}
+
+	log.Printf("Rolled back %d migrations", len(versions))
+	return nil
+}
+
 func getAppliedMigrations(db *gorm.DB) (map[string]bool, error) {
 	var versions []string
 	if err := db.Raw("SELECT version FROM schema_migrations").Scan(&versions).Error; err != nil {
@@ -0,0 +1,314 @@
+// Command seed fills a database with demo data for local testing and evaluation: a demo
+// teacher and students, a course with one material of each type, test cases for its code
+// exercise, and a few graded submissions. It is safe to run again; existing demo users are
+// reused and the course is only filled while it has no materials, e.g. when a previous run
+// stopped after creating it.
+//
+//	APP_ENV=development go run ./cmd/seed -confirm
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/Project-DSView/backend/go/internal/application/services"
+	models "github.com/Project-DSView/backend/go/internal/domain/entities"
+	"github.com/Project-DSView/backend/go/internal/domain/enums"
+	"github.com/Project-DSView/backend/go/internal/infrastructure/config"
+	"github.com/Project-DSView/backend/go/internal/infrastructure/setup"
+	"gorm.io/gorm"
+)
+
+var confirm = flag.Bool("confirm", false, "Write demo data to the configured database (required)")
+
+// demoEnrollKey identifies the demo course, so a second run finds it instead of adding another
+const demoEnrollKey = "DSVIEW-DEMO"
+
+// demoUser is a user the seed creates, matched by email
+type demoUser struct {
+	email     string
+	firstName string
+	lastName  string
+	isTeacher bool
+}
+
+var (
+	demoTeacher  = demoUser{"demo.teacher@dsview.local", "Demo", "Teacher", true}
+	demoStudents = []demoUser{
+		{"demo.student1@dsview.local", "Alice", "Student", false},
+		{"demo.student2@dsview.local", "Bob", "Student", false},
+		{"demo.student3@dsview.local", "Carol", "Student", false},
+	}
+)
+
+// sumListCode is a correct solution to the demo code exercise
+const sumListCode = `import json
+
+numbers = json.loads(input())
+print(sum(numbers))
+`
+
+// sumListBuggyCode drops the last number, so it fails the demo test cases
+const sumListBuggyCode = `import json
+
+numbers = json.loads(input())
+print(sum(numbers[:-1]))
+`
+
+func main() {
+	flag.Parse()
+
+	env := os.Getenv("APP_ENV")
+	if env == "" {
+		env = "development"
+	}
+	cfg, err := config.Load(env)
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+
+	if cfg.Server.Environment == "production" {
+		log.Fatal("Refusing to seed demo data into a production environment")
+	}
+	if !*confirm {
+		fmt.Fprintf(os.Stderr, "This writes demo users, a course and submissions to database %q on %s.\n", cfg.Database.DBName, cfg.Database.Host)
+		fmt.Fprintln(os.Stderr, "Run again with -confirm to proceed.")
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	db, err := setup.SetupDatabase(cfg)
+	if err != nil {
+		log.Fatalf("Failed to setup database: %v", err)
+	}
+
+	if err := seed(db); err != nil {
+		log.Fatalf("Seeding failed: %v", err)
+	}
+	log.Println("Demo data is in place")
+}
+
+func seed(db *gorm.DB) error {
+	userService := services.NewUserService(db)
+	enrollmentService := services.NewEnrollmentService(db, userService)
+	courseService := services.NewCourseService(db, userService, enrollmentService)
+	materialService := services.NewCourseMaterialService(db, nil)
+
+	teacher, err := ensureUser(userService, demoTeacher)
+	if err != nil {
+		return err
+	}
+	students := make([]*models.User, len(demoStudents))
+	for i, u := range demoStudents {
+		if students[i], err = ensureUser(userService, u); err != nil {
+			return err
+		}
+	}
+
+	course, err := courseService.GetCourseByEnrollKey(demoEnrollKey)
+	if err != nil {
+		return err
+	}
+	if course == nil {
+		course = &models.Course{
+			Name:        "DSView Demo: Data Structures",
+			Description: "Demo course created by cmd/seed",
+			CreatedBy:   teacher.UserID,
+			EnrollKey:   demoEnrollKey,
+			Status:      enums.CourseStatusActive,
+		}
+		if err := courseService.CreateCourse(course); err != nil {
+			return fmt.Errorf("create course: %w", err)
+		}
+		log.Printf("Created course %s", course.CourseID)
+	} else {
+		log.Printf("Course %s already exists", course.CourseID)
+	}
+
+	for _, student := range students {
+		if _, err := enrollmentService.EnrollUser(course.CourseID, student.UserID, demoEnrollKey, enums.EnrollmentRoleStudent); err != nil && !errors.Is(err, services.ErrAlreadyEnrolled) {
+			return fmt.Errorf("enroll %s: %w", student.Email, err)
+		}
+	}
+
+	// Materials and submissions are only added to a course without materials, so runs never
+	// duplicate them
+	materialCount, err := courseService.GetMaterialsCountByCourse(course.CourseID)
+	if err != nil {
+		return err
+	}
+	if materialCount > 0 {
+		log.Printf("Course %s already has %d materials", course.CourseID, materialCount)
+		return nil
+	}
+	exercise, err := seedMaterials(materialService, course.CourseID, teacher.UserID)
+	if err != nil {
+		return err
+	}
+	return seedSubmissions(db, exercise, students)
+}
+
+// ensureUser returns the demo user with u's email, creating it when missing
+func ensureUser(userService *services.UserService, u demoUser) (*models.User, error) {
+	user, err := userService.GetUserByEmail(u.email)
+	if err != nil {
+		return nil, fmt.Errorf("get user %s: %w", u.email, err)
+	}
+	if user == nil {
+		user = &models.User{FirstName: u.firstName, LastName: u.lastName, Email: u.email, IsTeacher: u.isTeacher}
+		if err := userService.CreateUserDirect(user); err != nil {
+			return nil, fmt.Errorf("create user %s: %w", u.email, err)
+		}
+		log.Printf("Created user %s", u.email)
+	} else if user.IsTeacher != u.isTeacher {
+		if err := userService.UpdateTeacherStatus(user.UserID, u.isTeacher); err != nil {
+			return nil, fmt.Errorf("update user %s: %w", u.email, err)
+		}
+	}
+	return user, nil
+}
+
+// seedMaterials adds one material of each type to the course and returns the code exercise.
+// The document and PDF exercise point at storage keys no file was uploaded to.
+func seedMaterials(materialService *services.CourseMaterialService, courseID, teacherID string) (*models.CodeExercise, error) {
+	base := func(title, description string, week int) models.MaterialBase {
+		return models.MaterialBase{
+			CourseID:    courseID,
+			Title:       title,
+			Description: description,
+			Week:        week,
+			IsPublic:    true,
+			CreatedBy:   teacherID,
+		}
+	}
+	points := 10
+	graded := true
+
+	if err := materialService.CreateAnnouncement(&models.Announcement{
+		MaterialBase: base("Welcome", "", 1),
+		Content:      "Welcome to the DSView demo course. Start with the week 1 materials.",
+		IsPinned:     true,
+	}); err != nil {
+		return nil, fmt.Errorf("create announcement: %w", err)
+	}
+
+	if err := materialService.CreateVideo(&models.Video{
+		MaterialBase: base("Introduction to Lists", "Lecture recording", 1),
+		VideoURL:     "https://example.com/dsview-demo/lists.mp4",
+	}); err != nil {
+		return nil, fmt.Errorf("create video: %w", err)
+	}
+
+	if err := materialService.CreateDocument(&models.Document{
+		MaterialBase: base("Lecture Notes: Lists", "Slides for week 1", 1),
+		FileURL:      "demo/lecture-notes-lists.pdf",
+		FileName:     "lecture-notes-lists.pdf",
+		MimeType:     "application/pdf",
+	}); err != nil {
+		return nil, fmt.Errorf("create document: %w", err)
+	}
+
+	if err := materialService.CreatePDFExercise(&models.PDFExercise{
+		MaterialBase: base("Linked List Diagrams", "Draw the list after each operation and upload a PDF", 2),
+		TotalPoints:  &points,
+		IsGraded:     &graded,
+		FileURL:      "demo/linked-list-diagrams.pdf",
+		FileName:     "linked-list-diagrams.pdf",
+		MimeType:     "application/pdf",
+	}); err != nil {
+		return nil, fmt.Errorf("create PDF exercise: %w", err)
+	}
+
+	exercise := &models.CodeExercise{
+		MaterialBase:     base("Sum of a List", "Warm-up exercise", 1),
+		TotalPoints:      &points,
+		IsGraded:         &graded,
+		ProblemStatement: "Read a JSON list of integers from standard input and print their sum.",
+		OutputMode:       "json",
+	}
+	testCases := []models.TestCase{
+		{InputData: []byte(`[1, 2, 3]`), ExpectedOutput: []byte(`6`), IsPublic: true, DisplayName: "Small list"},
+		{InputData: []byte(`[]`), ExpectedOutput: []byte(`0`), DisplayName: "Empty list"},
+		{InputData: []byte(`[-5, 10, 20, 7]`), ExpectedOutput: []byte(`32`), DisplayName: "Negative numbers"},
+	}
+	for i := range testCases {
+		testCases[i].MaterialType = string(enums.MaterialTypeCodeExercise)
+	}
+	if err := materialService.CreateCodeExercise(exercise, testCases); err != nil {
+		return nil, fmt.Errorf("create code exercise: %w", err)
+	}
+	exercise.TestCases = testCases
+	return exercise, nil
+}
+
+// seedSubmissions records graded submissions to the code exercise as if the executor had
+// run them: the first student passes every test case, the second fails some, and the
+// rest have not submitted
+func seedSubmissions(db *gorm.DB, exercise *models.CodeExercise, students []*models.User) error {
+	attempts := []struct {
+		code   string
+		passes func(i int) bool
+	}{
+		{sumListCode, func(int) bool { return true }},
+		{sumListBuggyCode, func(i int) bool { return i == 1 }}, // only the empty list sums correctly
+	}
+
+	for n, attempt := range attempts {
+		if n >= len(students) {
+			break
+		}
+		student := students[n]
+		submittedAt := time.Now().Add(-time.Duration(len(attempts)-n) * time.Hour)
+
+		sub := models.Submission{
+			UserID:       student.UserID,
+			MaterialID:   exercise.MaterialID,
+			MaterialType: string(enums.MaterialTypeCodeExercise),
+			Code:         attempt.code,
+			MimeType:     "text/x-python",
+			Status:       enums.SubmissionCompleted,
+			SubmittedAt:  submittedAt,
+		}
+		for i, tc := range exercise.TestCases {
+			result := models.SubmissionResult{TestCaseID: tc.TestCaseID, Status: "failed", ActualOutput: []byte(`null`)}
+			if attempt.passes(i) {
+				result.Status = "passed"
+				result.ActualOutput = tc.ExpectedOutput
+				sub.PassedCount++
+			} else {
+				sub.FailedCount++
+			}
+			sub.Results = append(sub.Results, result)
+		}
+		sub.TotalScore = *exercise.TotalPoints * sub.PassedCount / len(exercise.TestCases)
+
+		status := enums.ProgressInProgress
+		if exercise.MeetsPassThreshold(sub.PassedCount, len(exercise.TestCases)) {
+			status = enums.ProgressWaitingReview
+		}
+		progress := models.StudentProgress{
+			UserID:          student.UserID,
+			MaterialID:      exercise.MaterialID,
+			MaterialType:    string(enums.MaterialTypeCodeExercise),
+			Status:          status,
+			Score:           sub.TotalScore,
+			LastSubmittedAt: &submittedAt,
+			AttemptCount:    1,
+		}
+
+		if err := db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Create(&sub).Error; err != nil {
+				return err
+			}
+			return tx.Create(&progress).Error
+		}); err != nil {
+			return fmt.Errorf("create submission for %s: %w", student.Email, err)
+		}
+		log.Printf("Created submission for %s (%d/%d passed)", student.Email, sub.PassedCount, len(exercise.TestCases))
+	}
+	return nil
+}
@@ -20,8 +20,11 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"os"
+	"os/signal"
+	"syscall"
 
 	_ "github.com/Project-DSView/backend/go/docs"
 	"github.com/Project-DSView/backend/go/internal/api/routes"
@@ -44,11 +47,16 @@ func main() {
 	}
 
 	// Initialize structured logger early (before DB setup)
-	appLogger, logErr := logger.NewLogger("go-app", logger.INFO, "/app/logs")
+	logFormat, err := logger.ParseLogFormat(cfg.Server.LogFormat)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v, writing JSON logs\n", err)
+		logFormat = logger.FormatJSON
+	}
+	appLogger, logErr := logger.NewLogger("go-app", logger.INFO, "/app/logs", logFormat)
 	if logErr != nil {
 		fmt.Fprintf(os.Stderr, "Warning: Failed to initialize file logger: %v\n", logErr)
 		// Fallback: create logger writing to current dir
-		appLogger, _ = logger.NewLogger("go-app", logger.INFO, "./logs")
+		appLogger, _ = logger.NewLogger("go-app", logger.INFO, "./logs", logFormat)
 	}
 	if appLogger != nil {
 		logger.SetGlobalLogger(appLogger)
@@ -86,9 +94,29 @@ func main() {
 		services.DeadlineCheckerService,
 		services.CourseScoreService,
 		services.CourseMaterialService,
+		services.CompletionService,
+		services.SessionService,
+		services.Scheduler,
 		services.DB,
 	)
 
+	// Start periodic background tasks; stop them, the server and the queue consumers on SIGINT/SIGTERM
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	services.Scheduler.Start(ctx)
+	shutdownDone := make(chan struct{})
+	go func() {
+		defer close(shutdownDone)
+		<-ctx.Done()
+		logger.Info("Shutting down...")
+		services.Scheduler.Stop()
+		services.CourseService.StopArchiveWaits()
+		if err := app.Shutdown(); err != nil {
+			logger.Warnf("Server shutdown failed: %v", err)
+		}
+		services.QueueService.DrainConsumers()
+	}()
+
 	// Start server
 	serverAddr := cfg.Server.Host + ":" + cfg.Server.Port
 	logger.Infof("Server starting on %s", serverAddr)
@@ -105,5 +133,8 @@ func main() {
 
 	// Fallback to HTTP if no HTTPS certificates
 	logger.Infof("No HTTPS certificates found, starting HTTP server on %s", serverAddr)
-	logger.Fatal("Server failed", app.Listen(serverAddr))
+	if err := app.Listen(serverAddr); err != nil {
+		logger.Fatal("Server failed", err)
+	}
+	<-shutdownDone
 }
@@ -3,10 +3,13 @@ package handler
 import (
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/Project-DSView/backend/go/internal/api/types"
 	"github.com/Project-DSView/backend/go/internal/application/services"
 	models "github.com/Project-DSView/backend/go/internal/domain/entities"
+	internaltypes "github.com/Project-DSView/backend/go/internal/types"
+	"github.com/Project-DSView/backend/go/pkg/auth"
 	"github.com/Project-DSView/backend/go/pkg/config"
 	"github.com/Project-DSView/backend/go/pkg/enrollment"
 	"github.com/Project-DSView/backend/go/pkg/response"
@@ -50,11 +53,18 @@ func (h *AnnouncementHandler) CreateAnnouncement(c *fiber.Ctx) error {
 
 	// Validate request
 	if err := config.Validate.Struct(req); err != nil {
-		return response.ErrorResponse(c, http.StatusBadRequest, "Validation failed", err.Error())
+		return response.ValidationErrorResponse(c, err, config.FieldErrors(err))
+	}
+
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(time.Now()) {
+		return response.ErrorResponse(c, http.StatusBadRequest, "Validation failed", "expires_at must be in the future")
 	}
 
 	// Get user ID from context (set by auth middleware)
-	userID := c.Locals("user_id").(string)
+	userID, err := auth.UserIDFromContext(c)
+	if err != nil {
+		return response.ErrorResponse(c, http.StatusUnauthorized, "Invalid authentication", nil)
+	}
 
 	// Create announcement
 	announcement := &models.Announcement{
@@ -63,7 +73,9 @@ func (h *AnnouncementHandler) CreateAnnouncement(c *fiber.Ctx) error {
 			Title:     req.Title,
 			CreatedBy: userID,
 		},
-		Content: req.Content,
+		Content:   req.Content,
+		IsPinned:  req.IsPinned,
+		ExpiresAt: req.ExpiresAt,
 	}
 
 	if err := h.announcementService.CreateAnnouncement(announcement); err != nil {
@@ -75,11 +87,12 @@ func (h *AnnouncementHandler) CreateAnnouncement(c *fiber.Ctx) error {
 
 // GetAnnouncements retrieves announcements for a course
 // @Summary Get announcements
-// @Description Get announcements for a specific course with optional filtering. Non-teachers can only see announcements from courses they are enrolled in.
+// @Description Get announcements for a specific course with optional filtering, pinned first. Non-teachers can only see announcements from courses they are enrolled in, and do not see expired ones.
 // @Tags announcements
 // @Produce json
 // @Param course_id query string true "Course ID"
 // @Param week query int false "Filter by week"
+// @Param page query int false "Page number; sets the offset when offset is not given" default(1)
 // @Param limit query int false "Limit results" default(20)
 // @Param offset query int false "Offset results" default(0)
 // @Success 200 {object} response.StandardResponse{data=[]models.Announcement}
@@ -101,16 +114,10 @@ func (h *AnnouncementHandler) GetAnnouncements(c *fiber.Ctx) error {
 	}
 
 	// Parse optional parameters
-	limit := 20
-	offset := 0
+	page, limit := response.ParsePagination(c, 20, 0)
+	offset := (page - 1) * limit
 	var week *int
 
-	if limitStr := c.Query("limit"); limitStr != "" {
-		if parsed, err := strconv.Atoi(limitStr); err == nil && parsed > 0 {
-			limit = parsed
-		}
-	}
-
 	if offsetStr := c.Query("offset"); offsetStr != "" {
 		if parsed, err := strconv.Atoi(offsetStr); err == nil && parsed >= 0 {
 			offset = parsed
@@ -123,7 +130,7 @@ func (h *AnnouncementHandler) GetAnnouncements(c *fiber.Ctx) error {
 		}
 	}
 
-	announcements, total, err := h.announcementService.GetAnnouncementsByCourse(courseID, week, limit, offset)
+	announcements, total, err := h.announcementService.GetAnnouncementsByCourse(courseID, week, limit, offset, canSeeExpiredAnnouncements(c))
 	if err != nil {
 		return response.ErrorResponse(c, http.StatusInternalServerError, "Failed to get announcements", err.Error())
 	}
@@ -206,11 +213,14 @@ func (h *AnnouncementHandler) UpdateAnnouncement(c *fiber.Ctx) error {
 
 	// Validate request
 	if err := config.Validate.Struct(req); err != nil {
-		return response.ErrorResponse(c, http.StatusBadRequest, "Validation failed", err.Error())
+		return response.ValidationErrorResponse(c, err, config.FieldErrors(err))
 	}
 
 	// Get user ID from context
-	userID := c.Locals("user_id").(string)
+	userID, err := auth.UserIDFromContext(c)
+	if err != nil {
+		return response.ErrorResponse(c, http.StatusUnauthorized, "Invalid authentication", nil)
+	}
 
 	// Prepare updates
 	updates := make(map[string]interface{})
@@ -220,6 +230,14 @@ func (h *AnnouncementHandler) UpdateAnnouncement(c *fiber.Ctx) error {
 	if req.Content != nil && *req.Content != "" {
 		updates["content"] = *req.Content
 	}
+	if req.ClearExpiresAt {
+		updates["expires_at"] = nil
+	} else if req.ExpiresAt != nil {
+		if !req.ExpiresAt.After(time.Now()) {
+			return response.ErrorResponse(c, http.StatusBadRequest, "Validation failed", "expires_at must be in the future")
+		}
+		updates["expires_at"] = *req.ExpiresAt
+	}
 
 	if err := h.announcementService.UpdateAnnouncement(announcementID, userID, updates); err != nil {
 		if err.Error() == "announcement not found" {
@@ -261,7 +279,10 @@ func (h *AnnouncementHandler) DeleteAnnouncement(c *fiber.Ctx) error {
 	}
 
 	// Get user ID from context
-	userID := c.Locals("user_id").(string)
+	userID, err := auth.UserIDFromContext(c)
+	if err != nil {
+		return response.ErrorResponse(c, http.StatusUnauthorized, "Invalid authentication", nil)
+	}
 
 	if err := h.announcementService.DeleteAnnouncement(announcementID, userID); err != nil {
 		if err.Error() == "announcement not found" {
@@ -276,6 +297,78 @@ func (h *AnnouncementHandler) DeleteAnnouncement(c *fiber.Ctx) error {
 	return response.SuccessResponse(c, http.StatusOK, "Announcement deleted successfully", nil)
 }
 
+// PinAnnouncement pins an announcement
+// @Summary Pin announcement
+// @Description Pin an announcement so it is listed first (creator only)
+// @Tags announcements
+// @Produce json
+// @Param id path string true "Announcement ID"
+// @Success 200 {object} response.StandardResponse{data=models.Announcement}
+// @Failure 403 {object} response.StandardResponse
+// @Failure 404 {object} response.StandardResponse
+// @Failure 500 {object} response.StandardResponse
+// @Router /api/announcements/{id}/pin [put]
+// @Security BearerAuth
+func (h *AnnouncementHandler) PinAnnouncement(c *fiber.Ctx) error {
+	return h.setPinned(c, true)
+}
+
+// UnpinAnnouncement unpins an announcement
+// @Summary Unpin announcement
+// @Description Remove the pin from an announcement (creator only)
+// @Tags announcements
+// @Produce json
+// @Param id path string true "Announcement ID"
+// @Success 200 {object} response.StandardResponse{data=models.Announcement}
+// @Failure 403 {object} response.StandardResponse
+// @Failure 404 {object} response.StandardResponse
+// @Failure 500 {object} response.StandardResponse
+// @Router /api/announcements/{id}/pin [delete]
+// @Security BearerAuth
+func (h *AnnouncementHandler) UnpinAnnouncement(c *fiber.Ctx) error {
+	return h.setPinned(c, false)
+}
+
+func (h *AnnouncementHandler) setPinned(c *fiber.Ctx, pinned bool) error {
+	announcementID := c.Params("id")
+	if announcementID == "" {
+		return response.ErrorResponse(c, http.StatusBadRequest, "Announcement ID is required", nil)
+	}
+
+	// Get user ID from context
+	userID, err := auth.UserIDFromContext(c)
+	if err != nil {
+		return response.ErrorResponse(c, http.StatusUnauthorized, "Invalid authentication", nil)
+	}
+
+	if err := h.announcementService.SetPinned(announcementID, userID, pinned); err != nil {
+		if err.Error() == "announcement not found" {
+			return response.ErrorResponse(c, http.StatusNotFound, "Announcement not found", nil)
+		}
+		if err.Error() == "only the creator can pin this announcement" {
+			return response.ErrorResponse(c, http.StatusForbidden, "Access denied", err.Error())
+		}
+		return response.ErrorResponse(c, http.StatusInternalServerError, "Failed to update announcement", err.Error())
+	}
+
+	announcement, err := h.announcementService.GetAnnouncementByID(announcementID)
+	if err != nil {
+		return response.ErrorResponse(c, http.StatusInternalServerError, "Failed to get updated announcement", err.Error())
+	}
+
+	message := "Announcement unpinned successfully"
+	if pinned {
+		message = "Announcement pinned successfully"
+	}
+	return response.SuccessResponse(c, http.StatusOK, message, announcement.ToJSON())
+}
+
+// canSeeExpiredAnnouncements reports whether the caller is a teacher, who also sees expired announcements
+func canSeeExpiredAnnouncements(c *fiber.Ctx) bool {
+	claims, ok := c.Locals("claims").(*internaltypes.Claims)
+	return ok && claims.IsTeacher
+}
+
 // GetAnnouncementStats retrieves announcement statistics
 // @Summary Get announcement stats
 // @Description Get statistics for announcements in a course. Non-teachers can only see stats from courses they are enrolled in.
@@ -320,17 +413,15 @@ func (h *AnnouncementHandler) GetAnnouncementStats(c *fiber.Ctx) error {
 // @Security BearerAuth
 func (h *AnnouncementHandler) GetRecentAnnouncements(c *fiber.Ctx) error {
 	// Parse limit parameter
-	limit := 10
-	if limitStr := c.Query("limit"); limitStr != "" {
-		if parsed, err := strconv.Atoi(limitStr); err == nil && parsed > 0 {
-			limit = parsed
-		}
-	}
+	_, limit := response.ParsePagination(c, 10, 0)
 
 	// Get user ID from context
-	userID := c.Locals("user_id").(string)
+	userID, err := auth.UserIDFromContext(c)
+	if err != nil {
+		return response.ErrorResponse(c, http.StatusUnauthorized, "Invalid authentication", nil)
+	}
 
-	announcements, err := h.announcementService.GetRecentAnnouncements(userID, limit)
+	announcements, err := h.announcementService.GetRecentAnnouncements(userID, limit, canSeeExpiredAnnouncements(c))
 	if err != nil {
 		return response.ErrorResponse(c, http.StatusInternalServerError, "Failed to get recent announcements", err.Error())
 	}
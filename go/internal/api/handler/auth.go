@@ -20,6 +20,7 @@ type AuthHandler struct {
 	oauthService   *services.OAuthService
 	jwtService     *services.JWTService
 	userService    *services.UserService
+	sessionService *services.SessionService
 	frontendConfig *config.FrontendConfig
 	storageService storage.StorageService
 	// Cache to prevent duplicate OAuth code usage
@@ -31,6 +32,7 @@ func NewAuthHandler(
 	oauthService *services.OAuthService,
 	jwtService *services.JWTService,
 	userService *services.UserService,
+	sessionService *services.SessionService,
 	frontendConfig *config.FrontendConfig,
 	storageService storage.StorageService,
 ) *AuthHandler {
@@ -38,6 +40,7 @@ func NewAuthHandler(
 		oauthService:   oauthService,
 		jwtService:     jwtService,
 		userService:    userService,
+		sessionService: sessionService,
 		frontendConfig: frontendConfig,
 		storageService: storageService,
 		usedCodes:      make(map[string]time.Time),
@@ -202,16 +205,18 @@ func (h *AuthHandler) GoogleCallback(c *fiber.Ctx) error {
 		return c.Redirect(redirectURL, fiber.StatusFound)
 	}
 
-	// Store refresh token if available
-	if token.RefreshToken != "" {
-		if err := h.userService.StoreRefreshToken(dbUser.UserID, token.RefreshToken); err != nil {
-			// Log error but don't fail the login
-			// log.Printf("Failed to store refresh token: %v", err)
-		}
+	// Start a session for this device; it holds the refresh token (if any)
+	session, err := h.sessionService.CreateSession(dbUser.UserID, token.RefreshToken, c.Get(fiber.HeaderUserAgent), c.IP())
+	if err != nil {
+		errorMessage := "Failed to create login session"
+		redirectURL := fmt.Sprintf("%s/error?error=%s&code=session_creation_failed",
+			h.frontendConfig.BaseURL,
+			url.QueryEscape(errorMessage))
+		return c.Redirect(redirectURL, fiber.StatusFound)
 	}
 
 	// Generate JWT token
-	jwtToken, err := h.jwtService.GenerateToken(dbUser.UserID, dbUser.Email, dbUser.FirstName+" "+dbUser.LastName, dbUser.IsTeacher)
+	jwtToken, err := h.jwtService.GenerateSessionToken(dbUser.UserID, dbUser.Email, dbUser.FirstName+" "+dbUser.LastName, dbUser.IsTeacher, session.SessionID)
 	if err != nil {
 		errorMessage := "Failed to generate JWT token"
 		redirectURL := fmt.Sprintf("%s/error?error=%s&code=jwt_generation_failed",
@@ -293,6 +298,40 @@ func (h *AuthHandler) RefreshToken(c *fiber.Ctx) error {
 		})
 	}
 
+	// Tokens issued before sessions existed carry no session ID and fall back to
+	// the single refresh token stored on the user
+	if claims.SessionID == "" {
+		return h.refreshLegacyToken(c, claims)
+	}
+
+	session, err := h.sessionService.GetActiveSession(claims.UserID, claims.SessionID)
+	if err != nil || session.RefreshToken == "" {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"error":   "Session is no longer active - please login again",
+		})
+	}
+
+	// Try to refresh Google token
+	newToken, err := h.oauthService.RefreshToken(c.Context(), session.RefreshToken)
+	if err != nil {
+		// Refresh token might be expired, end the session
+		h.sessionService.RevokeSession(claims.UserID, session.SessionID)
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"error":   "Refresh token expired - please login again",
+		})
+	}
+
+	// Store new refresh token if provided
+	h.sessionService.TouchSession(session.SessionID, newToken.RefreshToken)
+
+	return h.issueSessionToken(c, claims.UserID, session.SessionID)
+}
+
+// refreshLegacyToken refreshes a token that has no session ID, moving it onto a
+// new session so it can be listed and revoked from then on
+func (h *AuthHandler) refreshLegacyToken(c *fiber.Ctx, claims *types.Claims) error {
 	// Get stored refresh token
 	refreshToken, err := h.userService.GetRefreshToken(claims.UserID)
 	if err != nil || refreshToken == "" {
@@ -313,13 +352,24 @@ func (h *AuthHandler) RefreshToken(c *fiber.Ctx) error {
 		})
 	}
 
-	// Store new refresh token if provided
 	if newToken.RefreshToken != "" {
-		h.userService.StoreRefreshToken(claims.UserID, newToken.RefreshToken)
+		refreshToken = newToken.RefreshToken
 	}
+	session, err := h.sessionService.CreateSession(claims.UserID, refreshToken, c.Get(fiber.HeaderUserAgent), c.IP())
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"error":   "Failed to create session",
+		})
+	}
+	h.userService.RemoveRefreshToken(claims.UserID)
 
-	// Generate new JWT
-	jwtUser, err := h.userService.GetUserByID(claims.UserID)
+	return h.issueSessionToken(c, claims.UserID, session.SessionID)
+}
+
+// issueSessionToken generates a new JWT for the session and writes the refresh response
+func (h *AuthHandler) issueSessionToken(c *fiber.Ctx, userID, sessionID string) error {
+	jwtUser, err := h.userService.GetUserByID(userID)
 	if err != nil || jwtUser == nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"success": false,
@@ -327,7 +377,7 @@ func (h *AuthHandler) RefreshToken(c *fiber.Ctx) error {
 		})
 	}
 
-	jwtToken, err := h.jwtService.GenerateToken(jwtUser.UserID, jwtUser.Email, jwtUser.FirstName+" "+jwtUser.LastName, jwtUser.IsTeacher)
+	jwtToken, err := h.jwtService.GenerateSessionToken(jwtUser.UserID, jwtUser.Email, jwtUser.FirstName+" "+jwtUser.LastName, jwtUser.IsTeacher, sessionID)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"success": false,
@@ -358,8 +408,12 @@ func (h *AuthHandler) RefreshToken(c *fiber.Ctx) error {
 func (h *AuthHandler) Logout(c *fiber.Ctx) error {
 	// Get user ID for cleanup
 	if claims, ok := c.Locals("claims").(*types.Claims); ok {
-		// Remove refresh token from database
-		h.userService.RemoveRefreshToken(claims.UserID)
+		// End this device's session, or remove the legacy refresh token
+		if claims.SessionID != "" {
+			h.sessionService.RevokeSession(claims.UserID, claims.SessionID)
+		} else {
+			h.userService.RemoveRefreshToken(claims.UserID)
+		}
 	}
 
 	// Clear all OAuth codes to prevent reuse
@@ -1,7 +1,10 @@
 package handler
 
 import (
+	"bufio"
+	stderrors "errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/Project-DSView/backend/go/internal/application/services"
@@ -9,11 +12,14 @@ import (
 	"github.com/Project-DSView/backend/go/internal/domain/enums"
 	"github.com/Project-DSView/backend/go/internal/types"
 	"github.com/Project-DSView/backend/go/pkg/auth"
+	"github.com/Project-DSView/backend/go/pkg/authz"
 	"github.com/Project-DSView/backend/go/pkg/errors"
+	"github.com/Project-DSView/backend/go/pkg/logger"
 	"github.com/Project-DSView/backend/go/pkg/response"
 	"github.com/Project-DSView/backend/go/pkg/storage"
 	"github.com/Project-DSView/backend/go/pkg/validation"
 	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
 )
 
 type CourseHandler struct {
@@ -23,9 +29,10 @@ type CourseHandler struct {
 	enrollmentService     *services.EnrollmentService
 	queueService          *services.QueueService
 	storageService        storage.StorageService
+	db                    *gorm.DB
 }
 
-func NewCourseHandler(courseService *services.CourseService, courseMaterialService *services.CourseMaterialService, userService *services.UserService, enrollmentService *services.EnrollmentService, queueService *services.QueueService, storageService storage.StorageService) *CourseHandler {
+func NewCourseHandler(courseService *services.CourseService, courseMaterialService *services.CourseMaterialService, userService *services.UserService, enrollmentService *services.EnrollmentService, queueService *services.QueueService, storageService storage.StorageService, db *gorm.DB) *CourseHandler {
 	return &CourseHandler{
 		courseService:         courseService,
 		courseMaterialService: courseMaterialService,
@@ -33,6 +40,7 @@ func NewCourseHandler(courseService *services.CourseService, courseMaterialServi
 		enrollmentService:     enrollmentService,
 		queueService:          queueService,
 		storageService:        storageService,
+		db:                    db,
 	}
 }
 
@@ -66,18 +74,10 @@ func (h *CourseHandler) GetCourses(c *fiber.Ctx) error {
 	}
 
 	// Parse query parameters
-	page := c.QueryInt("page", 1)
-	limit := c.QueryInt("limit", 20)
+	page, limit := response.ParsePagination(c, 20, 0)
 	status := c.Query("status")
 	search := c.Query("search")
 
-	if page < 1 {
-		page = 1
-	}
-	if limit < 1 || limit > 100 {
-		limit = 20
-	}
-
 	// Get courses using isTeacher instead of role
 	courses, total, err := h.courseService.GetCoursesWithFilters(page, limit, status, search, claims.UserID, currentUser.IsTeacher)
 	if err != nil {
@@ -87,21 +87,53 @@ func (h *CourseHandler) GetCourses(c *fiber.Ctx) error {
 	// Convert to response format
 	courseData := make([]response.CourseResponse, len(courses))
 	for i, courseModel := range courses {
-		// Teachers can see enroll key
-		includeEnrollKey := currentUser.IsTeacher || courseModel.CreatedBy == claims.UserID
+		// Only the course's managers can see the enroll key
+		includeEnrollKey := authz.ManagesCourse(&courseModel, claims.UserID)
 		courseData[i] = response.ConvertToCourseResponse(&courseModel, includeEnrollKey)
 	}
 
 	return c.JSON(fiber.Map{
 		"success": true,
 		"data": fiber.Map{
-			"courses": courseData,
-			"pagination": fiber.Map{
-				"page":        page,
-				"limit":       limit,
-				"total":       total,
-				"total_pages": (total + limit - 1) / limit,
-			},
+			"courses":    courseData,
+			"pagination": response.Pagination(c, page, limit, total),
+		},
+	})
+}
+
+// DiscoverCourses godoc
+// @Summary Discover courses to join
+// @Description List active courses the current user has not joined yet, newest first, for a self-service course catalog. Enroll keys are never included; courses marked requires_enroll_key are joined with the key from their teacher. Requires both API key and JWT authentication.
+// @Tags courses
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Produce json
+// @Param page query int false "Page number (default: 1)"
+// @Param limit query int false "Items per page (default: 20)"
+// @Param search query string false "Search in name and description"
+// @Success 200 {object} object{success=bool,data=object{courses=[]types.DiscoverableCourse,pagination=object}} "Joinable courses"
+// @Failure 401 {object} map[string]string "Unauthorized - Both API key and JWT token required"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /api/courses/discover [get]
+func (h *CourseHandler) DiscoverCourses(c *fiber.Ctx) error {
+	claims, ok := c.Locals("claims").(*types.Claims)
+	if !ok {
+		return response.SendUnauthorizedError(c, "Invalid authentication")
+	}
+
+	page, limit := response.ParsePagination(c, 20, 0)
+	search := c.Query("search")
+
+	courses, total, err := h.courseService.GetDiscoverableCourses(claims.UserID, search, page, limit)
+	if err != nil {
+		return response.SendGenericError(c, errors.Wrap(err, "Failed to fetch courses"))
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data": fiber.Map{
+			"courses":    courses,
+			"pagination": response.Pagination(c, page, limit, total),
 		},
 	})
 }
@@ -262,7 +294,7 @@ func (h *CourseHandler) GetCourse(c *fiber.Ctx) error {
 	}
 
 	// Check if user can see enroll key
-	includeEnrollKey := auth.CanEditCourse(currentUser.IsTeacher, courseModel.CreatedBy, claims.UserID)
+	includeEnrollKey := authz.ManagesCourse(courseModel, claims.UserID)
 	courseResp := response.ConvertToCourseResponse(courseModel, includeEnrollKey)
 
 	return response.SendSuccess(c, "Course retrieved successfully", courseResp)
@@ -270,7 +302,7 @@ func (h *CourseHandler) GetCourse(c *fiber.Ctx) error {
 
 // UpdateCourse godoc
 // @Summary Update course
-// @Description Update course information (Teacher/Admin only, teachers can only update their own). Requires both API key and JWT authentication.
+// @Description Update course information (Teacher/Admin only, teachers can only update their own). Requires both API key and JWT authentication. Archiving a course stops new submissions to it and finishes or cancels its queued grading jobs per QUEUE_ARCHIVE_JOB_POLICY; making it active again restarts its queue consumers.
 // @Tags courses
 // @Security BearerAuth
 // @Security ApiKeyAuth
@@ -302,7 +334,10 @@ func (h *CourseHandler) UpdateCourse(c *fiber.Ctx) error {
 	}
 
 	// Check permissions
-	canModify, err := h.courseService.CanUserModifyCourse(claims.UserID, courseID, currentUser.IsTeacher)
+	canModify, err := authz.CanManageCourse(h.db, claims.UserID, courseID)
+	if err == authz.ErrCourseNotFound {
+		return response.SendNotFound(c, "Course not found")
+	}
 	if err != nil {
 		return response.SendInternalError(c, "Failed to check permissions: "+err.Error())
 	}
@@ -327,16 +362,29 @@ func (h *CourseHandler) UpdateCourse(c *fiber.Ctx) error {
 		return response.SendValidationError(c, err.Error())
 	}
 
-	// Build updates map
-	updates := response.BuildCourseUpdates(req.Name, req.Description, req.Status, req.EnrollKey)
+	// Build updates map; a status change goes through the archive flow instead
+	updates := response.BuildCourseUpdates(req.Name, req.Description, nil, req.EnrollKey)
+	statusChange := req.Status != nil && enums.IsValidCourseStatus(*req.Status)
 
-	if len(updates) == 0 {
+	if len(updates) == 0 && !statusChange {
 		return response.SendBadRequest(c, "No valid updates provided")
 	}
 
 	// Update course
-	if err := h.courseService.UpdateCourse(courseID, updates); err != nil {
-		return response.SendInternalError(c, "Failed to update course: "+err.Error())
+	if len(updates) > 0 {
+		if err := h.courseService.UpdateCourse(courseID, updates); err != nil {
+			return response.SendInternalError(c, "Failed to update course: "+err.Error())
+		}
+	}
+	if statusChange {
+		if enums.CourseStatus(*req.Status) == enums.CourseStatusArchived {
+			err = h.courseService.ArchiveCourse(courseID)
+		} else {
+			err = h.courseService.ReactivateCourse(courseID)
+		}
+		if err != nil {
+			return response.SendInternalError(c, "Failed to update course status: "+err.Error())
+		}
 	}
 
 	// Get updated course
@@ -381,7 +429,10 @@ func (h *CourseHandler) DeleteCourse(c *fiber.Ctx) error {
 	}
 
 	// Check permissions
-	canModify, err := h.courseService.CanUserModifyCourse(claims.UserID, courseID, currentUser.IsTeacher)
+	canModify, err := authz.CanManageCourse(h.db, claims.UserID, courseID)
+	if err == authz.ErrCourseNotFound {
+		return response.SendNotFound(c, "Course not found")
+	}
 	if err != nil {
 		return response.SendInternalError(c, "Failed to check permissions: "+err.Error())
 	}
@@ -399,12 +450,13 @@ func (h *CourseHandler) DeleteCourse(c *fiber.Ctx) error {
 
 // GetCourseReportForTeacher godoc
 // @Summary Get course report for teacher
-// @Description Get comprehensive course report including enrollment count, today's queue jobs, and materials count
+// @Description Get comprehensive course report including today's queue jobs, materials count, and the course stats from the latest nightly snapshot (computed live with live=true). enrollment_count and exercises_created are the student and exercise counts of those stats
 // @Tags courses
 // @Security BearerAuth
 // @Produce json
 // @Param id path string true "Course ID"
-// @Success 200 {object} object{success=bool,message=string,data=object{enrollment_count=int,today_queue_jobs=[]object,exercises_created=int,current_materials_count=int}}
+// @Param live query bool false "Compute the stats now instead of reading the latest snapshot"
+// @Success 200 {object} object{success=bool,message=string,data=object{enrollment_count=int,today_queue_jobs=[]object,exercises_created=int,current_materials_count=int,stats=types.CourseStats}}
 // @Failure 401 {object} map[string]string "Unauthorized"
 // @Failure 403 {object} map[string]string "Forbidden"
 // @Failure 404 {object} map[string]string "Course not found"
@@ -426,24 +478,22 @@ func (h *CourseHandler) GetCourseReportForTeacher(c *fiber.Ctx) error {
 		return response.SendBadRequest(c, "Course ID is required")
 	}
 
-	// Check if course exists
-	courseModel, err := h.courseService.GetCourseByID(courseID)
-	if err != nil {
-		return response.SendInternalError(c, "Failed to fetch course: "+err.Error())
-	}
-	if courseModel == nil {
+	// Check permissions - only the course creator can access
+	canManage, err := authz.CanManageCourse(h.db, claims.UserID, courseID)
+	if err == authz.ErrCourseNotFound {
 		return response.SendNotFound(c, "Course not found")
 	}
-
-	// Check permissions - only course creator or teachers can access
-	if !currentUser.IsTeacher && courseModel.CreatedBy != claims.UserID {
-		return response.SendError(c, fiber.StatusForbidden, "Only course creator or teachers can access this report")
+	if err != nil {
+		return response.SendInternalError(c, "Failed to check permissions: "+err.Error())
+	}
+	if !canManage {
+		return response.SendError(c, fiber.StatusForbidden, "Only the course creator can access this report")
 	}
 
-	// Get enrollment count
-	enrollmentCount, err := h.courseService.GetCourseEnrollmentCount(courseID)
+	// The aggregate figures come from the latest snapshot unless live is set
+	stats, err := h.courseService.GetCourseStats(courseID, c.QueryBool("live"))
 	if err != nil {
-		return response.SendInternalError(c, "Failed to get enrollment count: "+err.Error())
+		return response.SendInternalError(c, "Failed to get course stats: "+err.Error())
 	}
 
 	// Get materials count
@@ -469,15 +519,65 @@ func (h *CourseHandler) GetCourseReportForTeacher(c *fiber.Ctx) error {
 	}
 
 	reportData := fiber.Map{
-		"enrollment_count":        enrollmentCount,
+		"enrollment_count":        stats.StudentCount,
 		"today_queue_jobs":        queueJobData,
-		"exercises_created":       materialsCount, // Same as current materials count
+		"exercises_created":       stats.ExerciseCount,
 		"current_materials_count": materialsCount,
+		"stats":                   stats,
 	}
 
 	return response.SendSuccess(c, "Course report retrieved successfully", reportData)
 }
 
+// GetCourseStats godoc
+// @Summary Get course stats
+// @Description Get the course's aggregate stats (students, exercises, completion rate, average score, submissions) from the latest nightly snapshot; snapshot_at tells when they were taken. With live=true, or before the first snapshot, they are computed for this request (course creator only)
+// @Tags courses
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Course ID"
+// @Param live query bool false "Compute the stats now instead of reading the latest snapshot"
+// @Success 200 {object} object{success=bool,message=string,data=types.CourseStats}
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Forbidden"
+// @Failure 404 {object} map[string]string "Course not found"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /api/courses/{id}/stats [get]
+func (h *CourseHandler) GetCourseStats(c *fiber.Ctx) error {
+	claims, ok := c.Locals("claims").(*types.Claims)
+	if !ok {
+		return response.SendUnauthorized(c, "Invalid authentication")
+	}
+
+	courseID := c.Params("id")
+	if courseID == "" {
+		return response.SendBadRequest(c, "Course ID is required")
+	}
+
+	courseModel, err := h.courseService.GetCourseByID(courseID)
+	if err != nil {
+		return response.SendInternalError(c, "Failed to fetch course: "+err.Error())
+	}
+	if courseModel == nil {
+		return response.SendNotFound(c, "Course not found")
+	}
+
+	canManage, err := authz.CanManageCourse(h.db, claims.UserID, courseID)
+	if err != nil {
+		return response.SendInternalError(c, "Failed to check permissions: "+err.Error())
+	}
+	if !canManage {
+		return response.SendError(c, fiber.StatusForbidden, "Only the course creator can access course stats")
+	}
+
+	stats, err := h.courseService.GetCourseStats(courseID, c.QueryBool("live"))
+	if err != nil {
+		return response.SendInternalError(c, "Failed to get course stats: "+err.Error())
+	}
+
+	return response.SendSuccess(c, "Course stats retrieved successfully", stats)
+}
+
 // GetCourseReportForTA godoc
 // @Summary Get course report for TA
 // @Description Get course report for TA including today's queue jobs
@@ -516,12 +616,12 @@ func (h *CourseHandler) GetCourseReportForTA(c *fiber.Ctx) error {
 		return response.SendNotFound(c, "Course not found")
 	}
 
-	// Check permissions - only TAs enrolled in this course can access
-	isEnrolled, enrollmentRole, err := h.courseService.IsUserEnrolledInCourse(courseID, claims.UserID)
+	// Check permissions - only TAs enrolled in this course (and its creator) can access
+	canReview, err := authz.CanReviewInCourse(h.db, claims.UserID, courseID)
 	if err != nil {
-		return response.SendInternalError(c, "Failed to check enrollment: "+err.Error())
+		return response.SendInternalError(c, "Failed to check permissions: "+err.Error())
 	}
-	if !isEnrolled || enrollmentRole != enums.EnrollmentRoleTA {
+	if !canReview {
 		return response.SendError(c, fiber.StatusForbidden, "Only TAs enrolled in this course can access this report")
 	}
 
@@ -548,6 +648,424 @@ func (h *CourseHandler) GetCourseReportForTA(c *fiber.Ctx) error {
 	return response.SendSuccess(c, "Course report retrieved successfully", reportData)
 }
 
+// ExportQueueJobs godoc
+// @Summary Export queue job history as CSV
+// @Description Stream the course's queue jobs created between two dates (inclusive, Asia/Bangkok) as CSV with student, material, type, status, submitted/claimed/completed times and reviewer. Defaults to the last 30 days. Finished jobs are kept for 180 days, so from cannot be earlier (course creator or TAs of the course).
+// @Tags courses
+// @Security BearerAuth
+// @Produce text/csv
+// @Param id path string true "Course ID"
+// @Param from query string false "First day, YYYY-MM-DD"
+// @Param to query string false "Last day, YYYY-MM-DD (defaults to today)"
+// @Success 200 {file} file "CSV file"
+// @Failure 400 {object} map[string]string "Invalid date range"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Forbidden"
+// @Failure 404 {object} map[string]string "Course not found"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /api/courses/{id}/queue/export [get]
+func (h *CourseHandler) ExportQueueJobs(c *fiber.Ctx) error {
+	claims, ok := c.Locals("claims").(*types.Claims)
+	if !ok {
+		return response.SendUnauthorized(c, "Invalid authentication")
+	}
+
+	courseID := c.Params("id")
+	if courseID == "" {
+		return response.SendBadRequest(c, "Course ID is required")
+	}
+
+	canReview, err := authz.CanReviewInCourse(h.db, claims.UserID, courseID)
+	if err == authz.ErrCourseNotFound {
+		return response.SendNotFound(c, "Course not found")
+	}
+	if err != nil {
+		return response.SendInternalError(c, "Failed to check permissions: "+err.Error())
+	}
+	if !canReview {
+		return response.SendError(c, fiber.StatusForbidden, "Only the course teacher and TAs can export the queue history")
+	}
+
+	location, err := time.LoadLocation("Asia/Bangkok")
+	if err != nil {
+		location = time.Local
+	}
+	now := time.Now().In(location)
+	lastDay := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, location)
+	if to := c.Query("to"); to != "" {
+		if lastDay, err = time.ParseInLocation("2006-01-02", to, location); err != nil {
+			return response.SendBadRequest(c, "Invalid to date, expected YYYY-MM-DD")
+		}
+	}
+	firstDay := lastDay.AddDate(0, 0, -29)
+	if from := c.Query("from"); from != "" {
+		if firstDay, err = time.ParseInLocation("2006-01-02", from, location); err != nil {
+			return response.SendBadRequest(c, "Invalid from date, expected YYYY-MM-DD")
+		}
+	}
+	if firstDay.After(lastDay) {
+		return response.SendBadRequest(c, "from must not be after to")
+	}
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, location)
+	if firstDay.Before(today.AddDate(0, 0, -services.QueueJobRetentionDays)) {
+		return response.SendBadRequest(c, fmt.Sprintf("from must be within the last %d days; older queue jobs are deleted", services.QueueJobRetentionDays))
+	}
+
+	filename := fmt.Sprintf("queue-%s-%s-%s.csv", courseID, firstDay.Format("20060102"), lastDay.Format("20060102"))
+	c.Set("Content-Type", "text/csv; charset=utf-8")
+	c.Set("Content-Disposition", storage.ContentDisposition("attachment", filename))
+
+	// The status is already sent once streaming starts, so a failure part way is only logged
+	c.Status(fiber.StatusOK)
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		if err := h.queueService.ExportJobsCSV(w, courseID, firstDay, lastDay.AddDate(0, 0, 1)); err != nil {
+			logger.Error("Failed to export queue jobs for course "+courseID, err)
+		}
+	})
+	return nil
+}
+
+// CopyCourse godoc
+// @Summary Copy course
+// @Description Create a new course owned by the caller with all weeks, materials, test cases and files of the source course. Enrollments, submissions and progress are not copied and a new enroll key is generated (Teacher only). Requires both API key and JWT authentication.
+// @Tags courses
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Source course ID"
+// @Param request body object{name=string} false "Name of the new course (defaults to the source name with a (Copy) suffix)"
+// @Success 201 {object} object{success=bool,message=string,data=object} "Copied course"
+// @Failure 400 {object} map[string]string "Bad request"
+// @Failure 401 {object} map[string]string "Unauthorized - Both API key and JWT token required"
+// @Failure 403 {object} map[string]string "Forbidden"
+// @Failure 404 {object} map[string]string "Course not found"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /api/courses/{id}/copy [post]
+func (h *CourseHandler) CopyCourse(c *fiber.Ctx) error {
+	courseID, errResp := h.requireCourseModifier(c)
+	if errResp != nil {
+		return errResp
+	}
+
+	var req struct {
+		Name string `json:"name"`
+	}
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return response.SendBadRequest(c, "Invalid request body: "+err.Error())
+		}
+	}
+
+	name := validation.SanitizeInput(req.Name)
+	userID, err := auth.UserIDFromContext(c)
+	if err != nil {
+		return response.SendUnauthorized(c, "Invalid authentication")
+	}
+
+	newCourse, err := h.courseService.CopyCourse(courseID, name, userID)
+	if err != nil {
+		if err.Error() == "course not found" {
+			return response.SendNotFound(c, "Course not found")
+		}
+		return response.SendInternalError(c, "Failed to copy course: "+err.Error())
+	}
+
+	courseResp := response.ConvertToCourseResponse(newCourse, true)
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"success": true,
+		"message": "Course copied successfully",
+		"data":    courseResp,
+	})
+}
+
+// GetInstructorOverview godoc
+// @Summary Get instructor overview
+// @Description Summarize every course the current teacher owns: student count, average exercise completion, progress waiting for review, and storage used by course and submission files
+// @Tags courses
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} object{success=bool,message=string,data=types.InstructorOverview} "Instructor overview"
+// @Failure 401 {object} object{success=bool,error=string} "Unauthorized"
+// @Failure 403 {object} object{success=bool,error=string} "Not a teacher"
+// @Failure 500 {object} object{success=bool,error=string} "Internal server error"
+// @Router /api/users/me/instructor-overview [get]
+func (h *CourseHandler) GetInstructorOverview(c *fiber.Ctx) error {
+	claims, ok := c.Locals("claims").(*types.Claims)
+	if !ok {
+		return response.SendUnauthorized(c, "Invalid authentication")
+	}
+
+	currentUser, err := h.userService.GetUserByID(claims.UserID)
+	if err != nil || currentUser == nil {
+		return response.SendUnauthorized(c, "User not found")
+	}
+	if !currentUser.IsTeacher {
+		return response.SendError(c, fiber.StatusForbidden, "Only teachers have an instructor overview")
+	}
+
+	overview, err := h.courseService.GetInstructorOverview(claims.UserID)
+	if err != nil {
+		return response.SendInternalError(c, "Failed to get instructor overview: "+err.Error())
+	}
+
+	return response.SendSuccess(c, "Instructor overview retrieved successfully", overview)
+}
+
+// GetDeadlineCalendar godoc
+// @Summary Get course deadline calendar
+// @Description List the current user's deadlines in a course: every published exercise with a deadline or a closing submission window, with their deadline extension applied, their progress and whether it is overdue, earliest effective deadline first
+// @Tags courses
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Course ID"
+// @Success 200 {object} object{success=bool,message=string,data=[]types.DeadlineCalendarEntry} "Deadline calendar"
+// @Failure 401 {object} object{success=bool,error=string} "Unauthorized"
+// @Failure 403 {object} object{success=bool,error=string} "Not enrolled in the course"
+// @Failure 404 {object} object{success=bool,error=string} "Course not found"
+// @Failure 500 {object} object{success=bool,error=string} "Internal server error"
+// @Router /api/courses/{id}/calendar [get]
+func (h *CourseHandler) GetDeadlineCalendar(c *fiber.Ctx) error {
+	claims, ok := c.Locals("claims").(*types.Claims)
+	if !ok {
+		return response.SendUnauthorized(c, "Invalid authentication")
+	}
+
+	courseID := c.Params("id")
+	courseModel, err := h.courseService.GetCourseByID(courseID)
+	if err != nil {
+		return response.SendInternalError(c, "Failed to fetch course: "+err.Error())
+	}
+	if courseModel == nil {
+		return response.SendNotFound(c, "Course not found")
+	}
+
+	calendar, err := h.courseMaterialService.GetDeadlineCalendar(courseID, claims.UserID)
+	if err != nil {
+		if stderrors.Is(err, services.ErrNotCourseMember) {
+			return response.SendError(c, fiber.StatusForbidden, err.Error())
+		}
+		return response.SendInternalError(c, "Failed to get deadline calendar: "+err.Error())
+	}
+
+	return response.SendSuccess(c, "Deadline calendar retrieved successfully", calendar)
+}
+
+// GetMyDeadlineCalendar godoc
+// @Summary Get my deadline calendar
+// @Description List the current user's deadlines across every active course they are enrolled in as a student, earliest effective deadline first
+// @Tags courses
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} object{success=bool,message=string,data=[]types.DeadlineCalendarEntry} "Deadline calendar"
+// @Failure 401 {object} object{success=bool,error=string} "Unauthorized"
+// @Failure 500 {object} object{success=bool,error=string} "Internal server error"
+// @Router /api/users/me/calendar [get]
+func (h *CourseHandler) GetMyDeadlineCalendar(c *fiber.Ctx) error {
+	claims, ok := c.Locals("claims").(*types.Claims)
+	if !ok {
+		return response.SendUnauthorized(c, "Invalid authentication")
+	}
+
+	calendar, err := h.courseMaterialService.GetMyDeadlineCalendar(claims.UserID)
+	if err != nil {
+		return response.SendInternalError(c, "Failed to get deadline calendar: "+err.Error())
+	}
+
+	return response.SendSuccess(c, "Deadline calendar retrieved successfully", calendar)
+}
+
+// TransferCourseOwnership godoc
+// @Summary Transfer course ownership
+// @Description Make another teacher the owner of a course, e.g. when its teacher leaves (admins only, see ADMIN_EMAILS). The new owner manages the course from then on; enrollments and materials are kept, and the API keys the previous owner made for the course are revoked. With reassign_materials, weeks and materials the previous owner created are reassigned to the new owner too. Requires both API key and JWT authentication.
+// @Tags courses
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Course ID"
+// @Param request body object{new_owner_id=string,reassign_materials=bool} true "New owner and whether to reassign the previous owner's materials"
+// @Success 200 {object} object{success=bool,message=string,data=types.OwnershipTransfer} "Ownership transferred"
+// @Failure 400 {object} map[string]string "Bad request or new owner is not a teacher"
+// @Failure 401 {object} map[string]string "Unauthorized - Both API key and JWT token required"
+// @Failure 403 {object} map[string]string "Forbidden - admins only"
+// @Failure 404 {object} map[string]string "Course not found"
+// @Failure 409 {object} map[string]string "New owner already owns the course"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /api/courses/{id}/transfer [post]
+func (h *CourseHandler) TransferCourseOwnership(c *fiber.Ctx) error {
+	claims, ok := c.Locals("claims").(*types.Claims)
+	if !ok {
+		return response.SendUnauthorized(c, "Invalid authentication")
+	}
+
+	courseID := c.Params("id")
+	if courseID == "" {
+		return response.SendBadRequest(c, "Course ID is required")
+	}
+
+	var req struct {
+		NewOwnerID        string `json:"new_owner_id"`
+		ReassignMaterials bool   `json:"reassign_materials"`
+	}
+	if err := c.BodyParser(&req); err != nil {
+		return response.SendBadRequest(c, "Invalid request body: "+err.Error())
+	}
+	if req.NewOwnerID == "" {
+		return response.SendBadRequest(c, "new_owner_id is required")
+	}
+
+	result, err := h.courseService.TransferOwnership(courseID, req.NewOwnerID, claims.UserID, req.ReassignMaterials)
+	if err != nil {
+		switch {
+		case stderrors.Is(err, services.ErrNotAdmin):
+			return response.SendError(c, fiber.StatusForbidden, err.Error())
+		case stderrors.Is(err, services.ErrNewOwnerNotTeacher):
+			return response.SendBadRequest(c, err.Error())
+		case stderrors.Is(err, services.ErrNewOwnerAlreadyOwner):
+			return response.SendError(c, fiber.StatusConflict, err.Error())
+		case err.Error() == "course not found":
+			return response.SendNotFound(c, "Course not found")
+		}
+		return response.SendInternalError(c, "Failed to transfer course ownership: "+err.Error())
+	}
+
+	return response.SendSuccess(c, "Course ownership transferred successfully", result)
+}
+
+// GetCourseSettings godoc
+// @Summary Get course settings
+// @Description Get the feature-flag settings of a course with defaults applied (teachers only). Requires both API key and JWT authentication.
+// @Tags courses
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Produce json
+// @Param id path string true "Course ID"
+// @Success 200 {object} object{success=bool,message=string,data=object} "Course settings"
+// @Failure 400 {object} map[string]string "Bad request"
+// @Failure 401 {object} map[string]string "Unauthorized - Both API key and JWT token required"
+// @Failure 403 {object} map[string]string "Forbidden"
+// @Failure 404 {object} map[string]string "Course not found"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /api/courses/{id}/settings [get]
+func (h *CourseHandler) GetCourseSettings(c *fiber.Ctx) error {
+	courseID, errResp := h.requireCourseModifier(c)
+	if errResp != nil {
+		return errResp
+	}
+
+	settings, err := h.courseService.GetCourseSettings(courseID)
+	if err != nil {
+		if err.Error() == "course not found" {
+			return response.SendNotFound(c, "Course not found")
+		}
+		return response.SendInternalError(c, "Failed to get course settings: "+err.Error())
+	}
+
+	return response.SendSuccess(c, "Course settings retrieved successfully", settings.Resolved())
+}
+
+// UpdateCourseSettings godoc
+// @Summary Update course settings
+// @Description Update the feature-flag settings of a course (teachers only). Omitted fields keep their current value. Requires both API key and JWT authentication.
+// @Tags courses
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Course ID"
+// @Param settings body models.CourseSettings true "Settings to update"
+// @Success 200 {object} object{success=bool,message=string,data=object} "Updated course settings"
+// @Failure 400 {object} map[string]string "Bad request"
+// @Failure 401 {object} map[string]string "Unauthorized - Both API key and JWT token required"
+// @Failure 403 {object} map[string]string "Forbidden"
+// @Failure 404 {object} map[string]string "Course not found"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /api/courses/{id}/settings [put]
+func (h *CourseHandler) UpdateCourseSettings(c *fiber.Ctx) error {
+	courseID, errResp := h.requireCourseModifier(c)
+	if errResp != nil {
+		return errResp
+	}
+
+	var req models.CourseSettings
+	if err := c.BodyParser(&req); err != nil {
+		return response.SendBadRequest(c, "Invalid request body: "+err.Error())
+	}
+
+	settings, err := h.courseService.UpdateCourseSettings(courseID, req)
+	if err != nil {
+		switch {
+		case err.Error() == "course not found":
+			return response.SendNotFound(c, "Course not found")
+		case strings.HasPrefix(err.Error(), "retry_window_hours"), strings.HasPrefix(err.Error(), "week_increment"),
+			strings.HasPrefix(err.Error(), "deadline_offset_days"), strings.HasPrefix(err.Error(), "submission_retention_days"):
+			return response.SendValidationError(c, err.Error())
+		}
+		return response.SendInternalError(c, "Failed to update course settings: "+err.Error())
+	}
+
+	return response.SendSuccess(c, "Course settings updated successfully", settings.Resolved())
+}
+
+// GetMaterialDefaults godoc
+// @Summary Get defaults for a new material
+// @Description Suggested week and deadline for the next material, from the course's week_increment and deadline_offset_days settings. The same values are applied when a material is created without them.
+// @Tags courses
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Produce json
+// @Param id path string true "Course ID"
+// @Success 200 {object} object{success=bool,message=string,data=types.MaterialDefaults} "Material defaults"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Forbidden"
+// @Failure 404 {object} map[string]string "Course not found"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /api/courses/{id}/material-defaults [get]
+func (h *CourseHandler) GetMaterialDefaults(c *fiber.Ctx) error {
+	courseID, errResp := h.requireCourseModifier(c)
+	if errResp != nil {
+		return errResp
+	}
+
+	defaults, err := h.courseMaterialService.GetMaterialDefaults(courseID)
+	if err != nil {
+		if err.Error() == "course not found" {
+			return response.SendNotFound(c, "Course not found")
+		}
+		return response.SendInternalError(c, "Failed to get material defaults: "+err.Error())
+	}
+
+	return response.SendSuccess(c, "Material defaults retrieved successfully", defaults)
+}
+
+// requireCourseModifier validates the course ID param and that the caller may modify the course.
+// It returns the course ID, or a non-nil error response that the handler should return as-is.
+func (h *CourseHandler) requireCourseModifier(c *fiber.Ctx) (string, error) {
+	claims, ok := c.Locals("claims").(*types.Claims)
+	if !ok {
+		return "", response.SendUnauthorized(c, "Invalid authentication")
+	}
+
+	courseID := c.Params("id")
+	if courseID == "" {
+		return "", response.SendBadRequest(c, "Course ID is required")
+	}
+
+	canModify, err := authz.CanManageCourse(h.db, claims.UserID, courseID)
+	if err == authz.ErrCourseNotFound {
+		return "", response.SendNotFound(c, "Course not found")
+	}
+	if err != nil {
+		return "", response.SendInternalError(c, "Failed to check permissions: "+err.Error())
+	}
+	if !canModify {
+		return "", response.SendError(c, fiber.StatusForbidden, "You don't have permission to modify this course")
+	}
+
+	return courseID, nil
+}
+
 // GetCourseExercises godoc
 // @Summary Get exercises in course
 // @Description Get exercises in a specific course with role-based filtering
@@ -590,17 +1108,9 @@ func (h *CourseHandler) GetCourseExercises(c *fiber.Ctx) error {
 	}
 
 	// Parse query parameters
-	page := c.QueryInt("page", 1)
-	limit := c.QueryInt("limit", 20)
+	page, limit := response.ParsePagination(c, 20, 0)
 	statusFilter := c.Query("status")
 
-	if page < 1 {
-		page = 1
-	}
-	if limit < 1 || limit > 100 {
-		limit = 20
-	}
-
 	// Check access permissions and get filtered materials
 	materials, total, userPermissions, err := h.getCourseMaterialsWithPermissions(
 		courseID, claims.UserID, currentUser.IsTeacher, page, limit, statusFilter)
@@ -628,56 +1138,143 @@ func (h *CourseHandler) GetCourseExercises(c *fiber.Ctx) error {
 	return c.JSON(fiber.Map{
 		"success": true,
 		"data": fiber.Map{
-			"materials": materialData,
-			"pagination": fiber.Map{
-				"page":        page,
-				"limit":       limit,
-				"total":       total,
-				"total_pages": (total + limit - 1) / limit,
-			},
+			"materials":        materialData,
+			"pagination":       response.Pagination(c, page, limit, total),
 			"course_info":      courseInfo,
 			"user_permissions": userPermissions,
 		},
 	})
 }
 
+// GetCourseWeeks godoc
+// @Summary Get course weeks
+// @Description Get the weeks of a course that have materials, with the number and types of materials in each. Hidden materials are only counted for the course creator.
+// @Tags courses
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Produce json
+// @Param id path string true "Course ID"
+// @Success 200 {object} object{success=bool,message=string,data=[]types.WeekSummary} "Weeks summary"
+// @Failure 401 {object} object{success=bool,error=string} "Unauthorized"
+// @Failure 403 {object} object{success=bool,error=string} "Forbidden - not enrolled"
+// @Failure 404 {object} object{success=bool,error=string} "Course not found"
+// @Failure 500 {object} object{success=bool,error=string} "Internal server error"
+// @Router /api/courses/{id}/weeks [get]
+func (h *CourseHandler) GetCourseWeeks(c *fiber.Ctx) error {
+	claims, ok := c.Locals("claims").(*types.Claims)
+	if !ok {
+		return response.SendUnauthorized(c, "Invalid authentication")
+	}
+
+	courseID := c.Params("id")
+	courseModel, err := h.courseService.GetCourseByID(courseID)
+	if err != nil {
+		return response.SendInternalError(c, "Failed to fetch course: "+err.Error())
+	}
+	if courseModel == nil {
+		return response.SendNotFound(c, "Course not found")
+	}
+
+	includeHidden, ok, err := h.checkMaterialVisibility(c, claims.UserID, courseID)
+	if !ok {
+		return err
+	}
+
+	weeks, err := h.courseMaterialService.GetWeeksSummary(courseID, includeHidden)
+	if err != nil {
+		return response.SendInternalError(c, "Failed to get course weeks: "+err.Error())
+	}
+
+	return response.SendSuccess(c, "Course weeks retrieved successfully", weeks)
+}
+
+// GetCourseTags godoc
+// @Summary Get course material tags
+// @Description List the distinct tags of a course's materials with the number of materials carrying each, most used first. Tags of hidden materials are only listed for the course creator.
+// @Tags courses
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Produce json
+// @Param id path string true "Course ID"
+// @Success 200 {object} object{success=bool,message=string,data=[]types.TagCount} "Tags with counts"
+// @Failure 401 {object} object{success=bool,error=string} "Unauthorized"
+// @Failure 403 {object} object{success=bool,error=string} "Forbidden - not enrolled"
+// @Failure 404 {object} object{success=bool,error=string} "Course not found"
+// @Failure 500 {object} object{success=bool,error=string} "Internal server error"
+// @Router /api/courses/{id}/tags [get]
+func (h *CourseHandler) GetCourseTags(c *fiber.Ctx) error {
+	claims, ok := c.Locals("claims").(*types.Claims)
+	if !ok {
+		return response.SendUnauthorized(c, "Invalid authentication")
+	}
+
+	courseID := c.Params("id")
+	courseModel, err := h.courseService.GetCourseByID(courseID)
+	if err != nil {
+		return response.SendInternalError(c, "Failed to fetch course: "+err.Error())
+	}
+	if courseModel == nil {
+		return response.SendNotFound(c, "Course not found")
+	}
+
+	includeHidden, ok, err := h.checkMaterialVisibility(c, claims.UserID, courseID)
+	if !ok {
+		return err
+	}
+
+	tags, err := h.courseMaterialService.GetCourseTags(courseID, includeHidden)
+	if err != nil {
+		return response.SendInternalError(c, "Failed to get course tags: "+err.Error())
+	}
+
+	return response.SendSuccess(c, "Course tags retrieved successfully", tags)
+}
+
+// checkMaterialVisibility checks that the caller can see the course's materials, sending
+// the error response when not. includeHidden reports whether the caller also sees
+// materials hidden from students, which only the course's managers do.
+func (h *CourseHandler) checkMaterialVisibility(c *fiber.Ctx, userID, courseID string) (includeHidden, ok bool, err error) {
+	canView, err := authz.CanViewCourse(h.db, userID, courseID)
+	if err != nil {
+		return false, false, response.SendInternalError(c, "Failed to check enrollment: "+err.Error())
+	}
+	if !canView {
+		return false, false, response.SendError(c, fiber.StatusForbidden, "You don't have access to view materials in this course. Please enroll first.")
+	}
+
+	includeHidden, err = authz.CanManageCourse(h.db, userID, courseID)
+	if err != nil {
+		return false, false, response.SendInternalError(c, "Failed to check permissions: "+err.Error())
+	}
+	return includeHidden, true, nil
+}
+
 // Helper method for getting materials with permissions
 func (h *CourseHandler) getCourseMaterialsWithPermissions(courseID, userID string, isTeacher bool, page, limit int, statusFilter string) ([]services.CourseMaterialWithWeek, int, map[string]interface{}, error) {
 	// Check user permissions
-	canViewDrafts := isTeacher
+	canView, err := authz.CanViewCourse(h.db, userID, courseID)
+	if err != nil {
+		return nil, 0, nil, fmt.Errorf("failed to check course access: %w", err)
+	}
+	if !canView {
+		return nil, 0, nil, fmt.Errorf("access_denied")
+	}
 
-	// For students, check enrollment
-	var isEnrolled bool
-	var enrollmentRole string
+	// Only the course's managers see drafts and archived materials
+	canViewDrafts, err := authz.CanManageCourse(h.db, userID, courseID)
+	if err != nil {
+		return nil, 0, nil, fmt.Errorf("failed to check course access: %w", err)
+	}
 
-	if !isTeacher {
-		enrolled, err := h.enrollmentService.IsUserEnrolled(courseID, userID)
+	isEnrolled := true
+	enrollmentRole := "instructor"
+	if !canViewDrafts {
+		role, _, err := h.enrollmentService.GetUserRole(courseID, userID)
 		if err != nil {
 			return nil, 0, nil, fmt.Errorf("failed to check enrollment: %w", err)
 		}
-		if !enrolled {
-			return nil, 0, nil, fmt.Errorf("access_denied")
-		}
-		isEnrolled = true
-
-		// Get enrollment role for additional permissions
-		enrollments, err := h.enrollmentService.GetCourseEnrollments(courseID)
-		if err == nil {
-			for _, enrollment := range enrollments {
-				if enrollment.UserID == userID {
-					enrollmentRole = string(enrollment.Role)
-					// TAs might have some additional permissions
-					if enrollment.Role == enums.EnrollmentRoleTA {
-						// TAs can see more but still can't see drafts
-					}
-					break
-				}
-			}
-		}
-	} else {
-		// Teachers always have access
-		isEnrolled = true
-		enrollmentRole = "instructor"
+		// Enrollment role for additional permissions (TAs still can't see drafts)
+		enrollmentRole = string(role)
 	}
 
 	// Build status filter based on permissions
@@ -760,7 +1357,10 @@ func (h *CourseHandler) UploadCourseImage(c *fiber.Ctx) error {
 	}
 
 	// Check permissions
-	canModify, err := h.courseService.CanUserModifyCourse(claims.UserID, courseID, currentUser.IsTeacher)
+	canModify, err := authz.CanManageCourse(h.db, claims.UserID, courseID)
+	if err == authz.ErrCourseNotFound {
+		return response.SendNotFound(c, "Course not found")
+	}
 	if err != nil {
 		return response.SendInternalError(c, "Failed to check permissions: "+err.Error())
 	}
@@ -863,7 +1463,10 @@ func (h *CourseHandler) DeleteCourseImage(c *fiber.Ctx) error {
 	}
 
 	// Check permissions
-	canModify, err := h.courseService.CanUserModifyCourse(claims.UserID, courseID, currentUser.IsTeacher)
+	canModify, err := authz.CanManageCourse(h.db, claims.UserID, courseID)
+	if err == authz.ErrCourseNotFound {
+		return response.SendNotFound(c, "Course not found")
+	}
 	if err != nil {
 		return response.SendInternalError(c, "Failed to check permissions: "+err.Error())
 	}
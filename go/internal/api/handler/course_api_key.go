@@ -0,0 +1,244 @@
+package handler
+
+import (
+	"time"
+
+	"github.com/Project-DSView/backend/go/internal/application/services"
+	"github.com/Project-DSView/backend/go/internal/types"
+	"github.com/Project-DSView/backend/go/pkg/response"
+	"github.com/gofiber/fiber/v2"
+)
+
+type CourseAPIKeyHandler struct {
+	keyService         *services.CourseAPIKeyService
+	courseService      *services.CourseService
+	enrollmentService  *services.EnrollmentService
+	courseScoreService *services.CourseScoreService
+}
+
+func NewCourseAPIKeyHandler(
+	keyService *services.CourseAPIKeyService,
+	courseService *services.CourseService,
+	enrollmentService *services.EnrollmentService,
+	courseScoreService *services.CourseScoreService,
+) *CourseAPIKeyHandler {
+	return &CourseAPIKeyHandler{
+		keyService:         keyService,
+		courseService:      courseService,
+		enrollmentService:  enrollmentService,
+		courseScoreService: courseScoreService,
+	}
+}
+
+// CreateAPIKey godoc
+// @Summary Create course API key
+// @Description Mint a read-only API key bound to one or more courses for LMS integration (Teacher who is course creator only). The raw key is only returned once.
+// @Tags course-api-keys
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Accept json
+// @Produce json
+// @Param request body object{name=string,course_ids=[]string,expires_at=string} true "Key name, bound course IDs and optional expiry (RFC3339)"
+// @Success 201 {object} object{success=bool,message=string,data=object{api_key=string,key=object}} "API key created successfully"
+// @Failure 400 {object} map[string]string "Bad request"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Forbidden"
+// @Failure 404 {object} map[string]string "Course not found"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /api/course-api-keys [post]
+func (h *CourseAPIKeyHandler) CreateAPIKey(c *fiber.Ctx) error {
+	claims, ok := c.Locals("claims").(*types.Claims)
+	if !ok {
+		return response.SendUnauthorized(c, "Invalid authentication")
+	}
+	if !claims.IsTeacher {
+		return response.SendError(c, fiber.StatusForbidden, "Only teachers can create API keys")
+	}
+
+	var req struct {
+		Name      string     `json:"name"`
+		CourseIDs []string   `json:"course_ids"`
+		ExpiresAt *time.Time `json:"expires_at"`
+	}
+	if err := c.BodyParser(&req); err != nil {
+		return response.SendBadRequest(c, "Invalid request body")
+	}
+
+	key, rawKey, err := h.keyService.CreateKey(claims.UserID, req.Name, req.CourseIDs, req.ExpiresAt)
+	if err != nil {
+		switch err.Error() {
+		case "name is required", "at least one course ID is required", "expires_at must be in the future":
+			return response.SendBadRequest(c, err.Error())
+		case "course not found":
+			return response.SendNotFound(c, "Course not found")
+		case "only course creator can create API keys":
+			return response.SendError(c, fiber.StatusForbidden, "Only course creator can create API keys")
+		}
+		return response.SendInternalError(c, "Failed to create API key: "+err.Error())
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"success": true,
+		"message": "API key created successfully. Store it now; it will not be shown again.",
+		"data": fiber.Map{
+			"api_key": rawKey,
+			"key":     key.ToJSON(),
+		},
+	})
+}
+
+// ListAPIKeys godoc
+// @Summary List course API keys
+// @Description List the course API keys created by the current teacher
+// @Tags course-api-keys
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Produce json
+// @Success 200 {object} object{success=bool,message=string,data=[]object} "List of API keys"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Forbidden"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /api/course-api-keys [get]
+func (h *CourseAPIKeyHandler) ListAPIKeys(c *fiber.Ctx) error {
+	claims, ok := c.Locals("claims").(*types.Claims)
+	if !ok {
+		return response.SendUnauthorized(c, "Invalid authentication")
+	}
+	if !claims.IsTeacher {
+		return response.SendError(c, fiber.StatusForbidden, "Only teachers can view API keys")
+	}
+
+	keys, err := h.keyService.ListKeys(claims.UserID)
+	if err != nil {
+		return response.SendInternalError(c, "Failed to get API keys: "+err.Error())
+	}
+
+	keyData := make([]map[string]interface{}, len(keys))
+	for i := range keys {
+		keyData[i] = keys[i].ToJSON()
+	}
+
+	return response.SendSuccess(c, "API keys retrieved successfully", keyData)
+}
+
+// RevokeAPIKey godoc
+// @Summary Revoke course API key
+// @Description Revoke a course API key (key creator, or the owner of a course the key is bound to)
+// @Tags course-api-keys
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Produce json
+// @Param id path string true "API key ID"
+// @Success 200 {object} object{success=bool,message=string} "API key revoked successfully"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Forbidden"
+// @Failure 404 {object} map[string]string "API key not found"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /api/course-api-keys/{id} [delete]
+func (h *CourseAPIKeyHandler) RevokeAPIKey(c *fiber.Ctx) error {
+	claims, ok := c.Locals("claims").(*types.Claims)
+	if !ok {
+		return response.SendUnauthorized(c, "Invalid authentication")
+	}
+
+	if err := h.keyService.RevokeKey(c.Params("id"), claims.UserID); err != nil {
+		switch err.Error() {
+		case "API key not found":
+			return response.SendNotFound(c, "API key not found")
+		case "only key creator or course owner can revoke this API key":
+			return response.SendError(c, fiber.StatusForbidden, "Only key creator or course owner can revoke this API key")
+		}
+		return response.SendInternalError(c, "Failed to revoke API key: "+err.Error())
+	}
+
+	return response.SendSuccess(c, "API key revoked successfully", nil)
+}
+
+// GetLMSCourse godoc
+// @Summary Get course (LMS)
+// @Description Get course details using a course-scoped API key. The enroll key is not included
+// @Tags lms
+// @Security ApiKeyAuth
+// @Produce json
+// @Param id path string true "Course ID"
+// @Success 200 {object} object{success=bool,message=string,data=object} "Course details"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Forbidden"
+// @Failure 404 {object} map[string]string "Course not found"
+// @Router /api/lms/courses/{id} [get]
+func (h *CourseAPIKeyHandler) GetLMSCourse(c *fiber.Ctx) error {
+	course, err := h.courseService.GetCourseByID(c.Params("id"))
+	if err != nil {
+		return response.SendInternalError(c, "Failed to get course: "+err.Error())
+	}
+	if course == nil {
+		return response.SendNotFound(c, "Course not found")
+	}
+
+	// Integrations read the course; the enroll key would let them join it
+	return response.SendSuccess(c, "Course retrieved successfully", response.ConvertToCourseResponse(course, false))
+}
+
+// GetLMSCourseEnrollments godoc
+// @Summary Get course roster (LMS)
+// @Description Get the enrollments of a course using a course-scoped API key
+// @Tags lms
+// @Security ApiKeyAuth
+// @Produce json
+// @Param id path string true "Course ID"
+// @Success 200 {object} object{success=bool,message=string,data=[]object} "Course enrollments"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Forbidden"
+// @Router /api/lms/courses/{id}/enrollments [get]
+func (h *CourseAPIKeyHandler) GetLMSCourseEnrollments(c *fiber.Ctx) error {
+	enrollments, err := h.enrollmentService.GetCourseEnrollments(c.Params("id"))
+	if err != nil {
+		return response.SendInternalError(c, "Failed to get enrollments: "+err.Error())
+	}
+
+	enrollmentData := make([]response.EnrollmentResponse, len(enrollments))
+	for i := range enrollments {
+		enrollmentData[i] = response.ConvertToEnrollmentResponse(&enrollments[i])
+	}
+
+	return response.SendSuccess(c, "Enrollments retrieved successfully", enrollmentData)
+}
+
+// GetLMSCourseScores godoc
+// @Summary Get course scores (LMS)
+// @Description Get every student's total score and the score statistics of a course using a course-scoped API key
+// @Tags lms
+// @Security ApiKeyAuth
+// @Produce json
+// @Param id path string true "Course ID"
+// @Success 200 {object} object{success=bool,message=string,data=object{scores=[]object,stats=object}} "Course scores"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Forbidden"
+// @Router /api/lms/courses/{id}/scores [get]
+func (h *CourseAPIKeyHandler) GetLMSCourseScores(c *fiber.Ctx) error {
+	courseID := c.Params("id")
+
+	scores, err := h.courseScoreService.GetCourseScores(c.Context(), courseID)
+	if err != nil {
+		return response.SendInternalError(c, "Failed to get course scores: "+err.Error())
+	}
+
+	stats, err := h.courseScoreService.GetCourseScoreStats(c.Context(), courseID)
+	if err != nil {
+		return response.SendInternalError(c, "Failed to get course score stats: "+err.Error())
+	}
+
+	scoreData := make([]fiber.Map, len(scores))
+	for i, score := range scores {
+		scoreData[i] = fiber.Map{
+			"user_id":      score.UserID,
+			"total_score":  score.TotalScore,
+			"last_updated": score.LastUpdated,
+		}
+	}
+
+	return response.SendSuccess(c, "Course scores retrieved successfully", fiber.Map{
+		"scores": scoreData,
+		"stats":  stats,
+	})
+}
@@ -0,0 +1,45 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Project-DSView/backend/go/internal/application/services"
+	models "github.com/Project-DSView/backend/go/internal/domain/entities"
+	"github.com/gofiber/fiber/v2"
+)
+
+// TestGetLMSCourseHidesEnrollKey checks that an API key can read a course without
+// learning the key students enroll with
+func TestGetLMSCourseHidesEnrollKey(t *testing.T) {
+	db := newTestDB(t, &models.User{}, &models.Course{}, &models.Enrollment{}, &models.CourseMaterial{})
+	if err := db.Create(&models.Course{CourseID: "course-1", Name: "Data Structures", CreatedBy: "teacher-1", EnrollKey: "secret-key"}).Error; err != nil {
+		t.Fatalf("create course: %v", err)
+	}
+
+	h := NewCourseAPIKeyHandler(nil, services.NewCourseService(db, services.NewUserService(db), nil), nil, nil)
+	app := fiber.New()
+	app.Get("/api/lms/courses/:id", h.GetLMSCourse)
+
+	resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/api/lms/courses/course-1", nil))
+	if err != nil {
+		t.Fatalf("request: %v", err)
+	}
+	if resp.StatusCode != fiber.StatusOK {
+		t.Fatalf("status = %d, want %d", resp.StatusCode, fiber.StatusOK)
+	}
+
+	var body struct {
+		Data map[string]interface{} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if body.Data["course_id"] != "course-1" || body.Data["name"] != "Data Structures" {
+		t.Errorf("data = %v, want course-1 details", body.Data)
+	}
+	if key, ok := body.Data["enroll_key"]; ok {
+		t.Errorf("enroll_key = %v, want it left out", key)
+	}
+}
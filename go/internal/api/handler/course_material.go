@@ -2,17 +2,25 @@ package handler
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/Project-DSView/backend/go/internal/api/types"
 	"github.com/Project-DSView/backend/go/internal/application/services"
 	models "github.com/Project-DSView/backend/go/internal/domain/entities"
+	"github.com/Project-DSView/backend/go/internal/domain/enums"
 	internaltypes "github.com/Project-DSView/backend/go/internal/types"
+	"github.com/Project-DSView/backend/go/pkg/auth"
+	"github.com/Project-DSView/backend/go/pkg/authz"
 	"github.com/Project-DSView/backend/go/pkg/config"
 	"github.com/Project-DSView/backend/go/pkg/enrollment"
+	materialpkg "github.com/Project-DSView/backend/go/pkg/material"
 	"github.com/Project-DSView/backend/go/pkg/response"
 	"github.com/Project-DSView/backend/go/pkg/storage"
+	"github.com/Project-DSView/backend/go/pkg/validation"
 	"github.com/gofiber/fiber/v2"
 )
 
@@ -20,6 +28,11 @@ type CourseMaterialHandler struct {
 	materialService     *services.CourseMaterialService
 	enrollmentValidator *enrollment.EnrollmentValidator
 	storageService      storage.StorageService
+
+	// Per-kind upload size limits in bytes; 0 = unlimited
+	maxPDFSize      int64
+	maxDocumentSize int64
+	maxImageSize    int64
 }
 
 func NewCourseMaterialHandler(materialService *services.CourseMaterialService, enrollmentValidator *enrollment.EnrollmentValidator, storageService storage.StorageService) *CourseMaterialHandler {
@@ -30,6 +43,13 @@ func NewCourseMaterialHandler(materialService *services.CourseMaterialService, e
 	}
 }
 
+// SetUploadLimits sets the maximum size of uploaded PDF, document and image files
+func (h *CourseMaterialHandler) SetUploadLimits(maxPDFSize, maxDocumentSize, maxImageSize int64) {
+	h.maxPDFSize = maxPDFSize
+	h.maxDocumentSize = maxDocumentSize
+	h.maxImageSize = maxImageSize
+}
+
 // Request models are now defined in internal/api/types/requests.go
 
 // CreateCourseMaterial creates a new course material
@@ -42,15 +62,28 @@ func NewCourseMaterialHandler(materialService *services.CourseMaterialService, e
 // @Param Title formData string true "Material title"
 // @Param Description formData string false "Material description"
 // @Param Type formData string true "Material type" Enums(pdf_exercise,code_exercise,document,video)
-// @Param Week formData int false "Week number"
+// @Param Week formData int false "Week number (default: course week_increment setting, else 1)"
 // @Param IsPublic formData bool false "Is public"
+// @Param Tags formData string false "Comma-separated tags, e.g. recursion,arrays"
 // @Param TotalPoints formData int false "Total points"
-// @Param Deadline formData string false "Deadline (ISO 8601)"
+// @Param Deadline formData string false "Deadline (ISO 8601; exercises default to the course deadline_offset_days setting)"
+// @Param OutputMode formData string false "How stdout is compared for code exercises" Enums(json,plain)
+// @Param TrimWhitespace formData bool false "Code exercises: ignore leading and trailing whitespace when comparing output (default true)"
+// @Param NumberTolerance formData number false "Code exercises: how far apart numbers in JSON output may be and still match, relative above 1 (default 1e-9, 0 = exact)"
+// @Param PassThreshold formData int false "Code exercises: percentage of test cases a submission must pass to be ready for review, 1-100 (default 100)"
+// @Param LockAfterApproval formData bool false "Reject resubmissions once a student's work is approved (code and PDF exercises)"
+// @Param MaxAttempts formData int false "How many times a student may submit (code and PDF exercises; 0 = unlimited)"
+// @Param MaxConcurrentSubmissions formData int false "Code exercises: how many submissions are graded at once, the rest wait in line (0 = no cap)"
+// @Param MaxPages formData int false "Maximum pages of a PDF submission (PDF exercises; 0 = unlimited, empty = server default)"
+// @Param Prerequisites formData string false "Comma-separated material IDs of exercises in the course a student must complete before submitting (code and PDF exercises)"
+// @Param OpensAt formData string false "When students may start submitting (RFC 3339; code and PDF exercises)"
+// @Param ClosesAt formData string false "When submissions close; between OpensAt and ClosesAt the deadline only marks submissions late (RFC 3339; code and PDF exercises)"
 // @Param File formData file false "File to upload"
-// @Success 201 {object} response.StandardResponse{data=models.CourseMaterial}
+// @Success 201 {object} response.StandardResponse{data=models.CourseMaterial} "Created material; data.warnings lists non-fatal configuration issues"
 // @Failure 400 {object} response.StandardResponse
 // @Failure 401 {object} response.StandardResponse
 // @Failure 403 {object} response.StandardResponse
+// @Failure 413 {object} response.StandardResponse
 // @Failure 500 {object} response.StandardResponse
 // @Router /api/course-materials [post]
 // @Security BearerAuth
@@ -70,6 +103,9 @@ func (h *CourseMaterialHandler) CreateCourseMaterial(c *fiber.Ctx) error {
 	problemStatement := c.FormValue("ProblemStatement")
 	constraints := c.FormValue("Constraints")
 	hints := c.FormValue("Hints")
+	outputMode := c.FormValue("OutputMode", string(enums.OutputModeJSON))
+	lockAfterApproval := c.FormValue("LockAfterApproval") == "true"
+	trimWhitespace := c.FormValue("TrimWhitespace") != "false"
 
 	// Parse announcement content
 	content := c.FormValue("Content")
@@ -88,8 +124,22 @@ func (h *CourseMaterialHandler) CreateCourseMaterial(c *fiber.Ctx) error {
 		return response.ErrorResponse(c, http.StatusBadRequest, "Missing required fields", "CourseID, Title, and Type are required")
 	}
 
+	isExercise := materialType == "code_exercise" || materialType == "pdf_exercise"
+
+	// An omitted week (or exercise deadline) falls back to the course's defaults
+	defaults := &internaltypes.MaterialDefaults{Week: 1}
+	if weekStr == "" || (isExercise && deadline == "") {
+		defaults, err = h.materialService.GetMaterialDefaults(courseID)
+		if err != nil {
+			if err.Error() == "course not found" {
+				return response.ErrorResponse(c, http.StatusNotFound, "Course not found", nil)
+			}
+			return response.ErrorResponse(c, http.StatusInternalServerError, "Failed to get course defaults", err.Error())
+		}
+	}
+
 	// Parse optional fields
-	week := 1
+	week := defaults.Week
 	if weekStr != "" {
 		if w, err := strconv.Atoi(weekStr); err == nil {
 			week = w
@@ -101,31 +151,103 @@ func (h *CourseMaterialHandler) CreateCourseMaterial(c *fiber.Ctx) error {
 		isPublic = false
 	}
 
+	tags, err := materialpkg.NormalizeTags(materialpkg.SplitTags(c.FormValue("Tags")))
+	if err != nil {
+		return response.ErrorResponse(c, http.StatusBadRequest, "Invalid tags", err.Error())
+	}
+
+	var maxAttempts int
+	if maxAttemptsStr := c.FormValue("MaxAttempts"); isExercise && maxAttemptsStr != "" {
+		maxAttempts, err = strconv.Atoi(maxAttemptsStr)
+		if err != nil || maxAttempts < 0 {
+			return response.ErrorResponse(c, http.StatusBadRequest, "Invalid max attempts", "MaxAttempts must be a non-negative integer")
+		}
+	}
+
+	var prerequisites internaltypes.StringList
+	if isExercise {
+		prerequisites, err = h.materialService.ValidatePrerequisites(courseID, "", strings.Split(c.FormValue("Prerequisites"), ","))
+		if err != nil {
+			if errors.Is(err, services.ErrInvalidPrerequisites) {
+				return response.ErrorResponse(c, http.StatusBadRequest, "Invalid prerequisites", err.Error())
+			}
+			return response.ErrorResponse(c, http.StatusInternalServerError, "Failed to validate prerequisites", err.Error())
+		}
+	}
+
+	var opensAt, closesAt *time.Time
+	if isExercise {
+		if opensAt, err = parseOptionalTime(c.FormValue("OpensAt")); err != nil {
+			return response.ErrorResponse(c, http.StatusBadRequest, "Invalid opens at", "OpensAt must be an RFC 3339 time")
+		}
+		if closesAt, err = parseOptionalTime(c.FormValue("ClosesAt")); err != nil {
+			return response.ErrorResponse(c, http.StatusBadRequest, "Invalid closes at", "ClosesAt must be an RFC 3339 time")
+		}
+		if !models.ValidSubmissionWindow(opensAt, closesAt) {
+			return response.ErrorResponse(c, http.StatusBadRequest, "Invalid submission window", services.ErrInvalidSubmissionWindow.Error())
+		}
+	}
+
 	var totalPoints *int = nil
 	if totalPointsStr != "" {
 		if tp, err := strconv.Atoi(totalPointsStr); err == nil {
 			totalPoints = &tp
 		}
-	} else if materialType == "code_exercise" || materialType == "pdf_exercise" {
+	} else if isExercise {
 		// Only set default for exercises
 		defaultPoints := 100
 		totalPoints = &defaultPoints
 	}
 
 	// Get user ID from context (set by auth middleware)
-	userID := c.Locals("user_id").(string)
+	userID, err := auth.UserIDFromContext(c)
+	if err != nil {
+		return response.ErrorResponse(c, http.StatusUnauthorized, "Invalid authentication", nil)
+	}
 
 	// Handle deadline if provided
 	var deadlinePtr *string
 	if deadline != "" {
 		deadlinePtr = &deadline
+	} else if isExercise {
+		deadlinePtr = defaults.Deadline
 	}
 
 	// Create the specific material type based on materialType
 	var materialID string
+	// Non-fatal issues found after creation, returned with the material
+	var warnings []string
 
 	switch materialType {
 	case "code_exercise":
+		if !enums.IsValidOutputMode(outputMode) {
+			return response.ErrorResponse(c, http.StatusBadRequest, "Invalid output mode", "OutputMode must be 'json' or 'plain'")
+		}
+
+		numberTolerance := models.DefaultNumberTolerance
+		if toleranceStr := c.FormValue("NumberTolerance"); toleranceStr != "" {
+			numberTolerance, err = strconv.ParseFloat(toleranceStr, 64)
+			if err != nil || numberTolerance < 0 {
+				return response.ErrorResponse(c, http.StatusBadRequest, "Invalid number tolerance", "NumberTolerance must be a non-negative number")
+			}
+		}
+
+		passThreshold := models.DefaultPassThreshold
+		if thresholdStr := c.FormValue("PassThreshold"); thresholdStr != "" {
+			passThreshold, err = strconv.Atoi(thresholdStr)
+			if err != nil || passThreshold < 1 || passThreshold > 100 {
+				return response.ErrorResponse(c, http.StatusBadRequest, "Invalid pass threshold", "PassThreshold must be an integer from 1 to 100")
+			}
+		}
+
+		var maxConcurrent int
+		if maxConcurrentStr := c.FormValue("MaxConcurrentSubmissions"); maxConcurrentStr != "" {
+			maxConcurrent, err = strconv.Atoi(maxConcurrentStr)
+			if err != nil || maxConcurrent < 0 {
+				return response.ErrorResponse(c, http.StatusBadRequest, "Invalid max concurrent submissions", "MaxConcurrentSubmissions must be a non-negative integer")
+			}
+		}
+
 		// Create CodeExercise
 		codeExercise := &models.CodeExercise{
 			MaterialBase: models.MaterialBase{
@@ -134,13 +256,25 @@ func (h *CourseMaterialHandler) CreateCourseMaterial(c *fiber.Ctx) error {
 				Description: description,
 				Week:        week,
 				IsPublic:    isPublic,
+				Tags:        tags,
 				CreatedBy:   userID,
 			},
-			TotalPoints:      totalPoints,
-			Deadline:         deadlinePtr,
-			ProblemStatement: problemStatement,
-			Constraints:      constraints,
-			Hints:            hints,
+			TotalPoints:       totalPoints,
+			Deadline:          deadlinePtr,
+			ProblemStatement:  problemStatement,
+			Constraints:       constraints,
+			Hints:             hints,
+			OutputMode:        outputMode,
+			TrimWhitespace:    &trimWhitespace,
+			NumberTolerance:   &numberTolerance,
+			PassThreshold:     &passThreshold,
+			LockAfterApproval: lockAfterApproval,
+			MaxAttempts:       maxAttempts,
+			Prerequisites:     prerequisites,
+			OpensAt:           opensAt,
+			ClosesAt:          closesAt,
+
+			MaxConcurrentSubmissions: maxConcurrent,
 		}
 
 		// Note: File upload for problem images will be handled after material creation
@@ -253,16 +387,19 @@ func (h *CourseMaterialHandler) CreateCourseMaterial(c *fiber.Ctx) error {
 
 		// Create code exercise (this will also create the CourseMaterial reference)
 		if err := h.materialService.CreateCodeExercise(codeExercise, testCases); err != nil {
+			if errors.Is(err, services.ErrTestCaseTooLarge) {
+				return response.ErrorResponse(c, http.StatusBadRequest, "Test case too large", err.Error())
+			}
 			return response.ErrorResponse(c, http.StatusInternalServerError, "Failed to create code exercise", err.Error())
 		}
 		materialID = codeExercise.MaterialID
+		warnings = materialpkg.Lint(codeExercise, testCases)
 
 		// Handle file upload for problem images (optional) - after material creation
 		if file != nil {
 			// Validate file size
-			const maxFileSize = 10 * 1024 * 1024 // 10MB
-			if file.Size > maxFileSize {
-				return response.ErrorResponse(c, http.StatusBadRequest, "File too large", "Maximum file size is 10MB")
+			if h.maxImageSize > 0 && file.Size > h.maxImageSize {
+				return response.ErrorResponse(c, http.StatusRequestEntityTooLarge, "File too large", "Maximum file size is "+validation.FormatFileSize(h.maxImageSize))
 			}
 
 			// Validate file type (should be image)
@@ -311,9 +448,8 @@ func (h *CourseMaterialHandler) CreateCourseMaterial(c *fiber.Ctx) error {
 
 		if file != nil {
 			// Validate file size
-			const maxFileSize = 10 * 1024 * 1024 // 10MB
-			if file.Size > maxFileSize {
-				return response.ErrorResponse(c, http.StatusBadRequest, "File too large", "Maximum file size is 10MB")
+			if h.maxPDFSize > 0 && file.Size > h.maxPDFSize {
+				return response.ErrorResponse(c, http.StatusRequestEntityTooLarge, "File too large", "Maximum file size is "+validation.FormatFileSize(h.maxPDFSize))
 			}
 
 			// Open file
@@ -329,13 +465,22 @@ func (h *CourseMaterialHandler) CreateCourseMaterial(c *fiber.Ctx) error {
 			if err != nil {
 				return response.ErrorResponse(c, http.StatusInternalServerError, "Failed to upload file", err.Error())
 			}
-			fileName = file.Filename
+			fileName = h.storageService.SanitizeFilename(file.Filename)
 			fileSize = file.Size
 			mimeType = file.Header.Get("Content-Type")
 		} else {
 			return response.ErrorResponse(c, http.StatusBadRequest, "File is required for PDF exercise", "Please upload a PDF file")
 		}
 
+		var maxPages *int
+		if maxPagesStr := c.FormValue("MaxPages"); maxPagesStr != "" {
+			mp, err := strconv.Atoi(maxPagesStr)
+			if err != nil || mp < 0 {
+				return response.ErrorResponse(c, http.StatusBadRequest, "Invalid max pages", "MaxPages must be a non-negative integer")
+			}
+			maxPages = &mp
+		}
+
 		// Create PDFExercise
 		pdfExercise := &models.PDFExercise{
 			MaterialBase: models.MaterialBase{
@@ -344,20 +489,28 @@ func (h *CourseMaterialHandler) CreateCourseMaterial(c *fiber.Ctx) error {
 				Description: description,
 				Week:        week,
 				IsPublic:    isPublic,
+				Tags:        tags,
 				CreatedBy:   userID,
 			},
-			TotalPoints: totalPoints,
-			Deadline:    deadlinePtr,
-			FileURL:     fileURL,
-			FileName:    fileName,
-			FileSize:    fileSize,
-			MimeType:    mimeType,
+			TotalPoints:       totalPoints,
+			Deadline:          deadlinePtr,
+			FileURL:           fileURL,
+			FileName:          fileName,
+			FileSize:          fileSize,
+			MimeType:          mimeType,
+			MaxPages:          maxPages,
+			LockAfterApproval: lockAfterApproval,
+			MaxAttempts:       maxAttempts,
+			Prerequisites:     prerequisites,
+			OpensAt:           opensAt,
+			ClosesAt:          closesAt,
 		}
 
 		if err := h.materialService.CreatePDFExercise(pdfExercise); err != nil {
 			return response.ErrorResponse(c, http.StatusInternalServerError, "Failed to create PDF exercise", err.Error())
 		}
 		materialID = pdfExercise.MaterialID
+		warnings = materialpkg.Lint(pdfExercise, nil)
 
 	case "document":
 		// For documents, handle file upload first if provided
@@ -367,9 +520,8 @@ func (h *CourseMaterialHandler) CreateCourseMaterial(c *fiber.Ctx) error {
 
 		if file != nil {
 			// Validate file size
-			const maxFileSize = 10 * 1024 * 1024 // 10MB
-			if file.Size > maxFileSize {
-				return response.ErrorResponse(c, http.StatusBadRequest, "File too large", "Maximum file size is 10MB")
+			if h.maxDocumentSize > 0 && file.Size > h.maxDocumentSize {
+				return response.ErrorResponse(c, http.StatusRequestEntityTooLarge, "File too large", "Maximum file size is "+validation.FormatFileSize(h.maxDocumentSize))
 			}
 
 			// Open file
@@ -385,7 +537,7 @@ func (h *CourseMaterialHandler) CreateCourseMaterial(c *fiber.Ctx) error {
 			if err != nil {
 				return response.ErrorResponse(c, http.StatusInternalServerError, "Failed to upload file", err.Error())
 			}
-			fileName = file.Filename
+			fileName = h.storageService.SanitizeFilename(file.Filename)
 			fileSize = file.Size
 			mimeType = file.Header.Get("Content-Type")
 		} else {
@@ -400,6 +552,7 @@ func (h *CourseMaterialHandler) CreateCourseMaterial(c *fiber.Ctx) error {
 				Description: description,
 				Week:        week,
 				IsPublic:    isPublic,
+				Tags:        tags,
 				CreatedBy:   userID,
 			},
 			FileURL:  fileURL,
@@ -427,6 +580,7 @@ func (h *CourseMaterialHandler) CreateCourseMaterial(c *fiber.Ctx) error {
 				Description: description,
 				Week:        week,
 				IsPublic:    isPublic,
+				Tags:        tags,
 				CreatedBy:   userID,
 			},
 			VideoURL: videoURL,
@@ -451,6 +605,7 @@ func (h *CourseMaterialHandler) CreateCourseMaterial(c *fiber.Ctx) error {
 				Description: description,
 				Week:        week,
 				IsPublic:    isPublic,
+				Tags:        tags,
 				CreatedBy:   userID,
 			},
 			Content: content,
@@ -465,8 +620,8 @@ func (h *CourseMaterialHandler) CreateCourseMaterial(c *fiber.Ctx) error {
 		return response.ErrorResponse(c, http.StatusBadRequest, "Invalid material type", "Material type must be one of: code_exercise, pdf_exercise, document, video, announcement")
 	}
 
-	// Get the created material to return
-	material, err := h.materialService.GetCourseMaterialByID(materialID)
+	// Get the created material to return, in the form the creator edits
+	material, err := h.materialService.GetMaterialForEdit(materialID, userID)
 	if err != nil {
 		return response.ErrorResponse(c, http.StatusInternalServerError, "Failed to retrieve created material", err.Error())
 	}
@@ -475,6 +630,11 @@ func (h *CourseMaterialHandler) CreateCourseMaterial(c *fiber.Ctx) error {
 	// For code_exercise, file uploads (optional problem images) would be handled separately if needed
 	// The file URL for code exercises would need to be stored in a separate table or handled differently
 
+	if warnings == nil {
+		warnings = []string{}
+	}
+	material["warnings"] = warnings
+
 	// Material is already a map[string]interface{} from service, no need to call ToJSON()
 	return response.SuccessResponse(c, http.StatusCreated, "Course material created successfully", material)
 }
@@ -492,6 +652,7 @@ func (h *CourseMaterialHandler) CreateCourseMaterial(c *fiber.Ctx) error {
 // @Failure 400 {object} response.StandardResponse
 // @Failure 401 {object} response.StandardResponse
 // @Failure 403 {object} response.StandardResponse
+// @Failure 413 {object} response.StandardResponse
 // @Failure 500 {object} response.StandardResponse
 // @Router /api/course-materials/upload [post]
 // @Security BearerAuth
@@ -527,7 +688,10 @@ func (h *CourseMaterialHandler) UploadCourseMaterialFile(c *fiber.Ctx) error {
 	defer src.Close()
 
 	// Get user ID from context
-	userID := c.Locals("user_id").(string)
+	userID, err := auth.UserIDFromContext(c)
+	if err != nil {
+		return response.ErrorResponse(c, http.StatusUnauthorized, "Invalid authentication", nil)
+	}
 
 	// Upload file
 	fileURL, err := h.materialService.UploadCourseMaterialFile(c.Context(), courseID, userID, materialType, src, file.Filename, file.Header.Get("Content-Type"))
@@ -537,7 +701,7 @@ func (h *CourseMaterialHandler) UploadCourseMaterialFile(c *fiber.Ctx) error {
 
 	return response.SuccessResponse(c, http.StatusOK, "File uploaded successfully", map[string]string{
 		"file_url":  fileURL,
-		"file_name": file.Filename,
+		"file_name": h.storageService.SanitizeFilename(file.Filename),
 		"file_size": strconv.FormatInt(file.Size, 10),
 	})
 }
@@ -550,6 +714,8 @@ func (h *CourseMaterialHandler) UploadCourseMaterialFile(c *fiber.Ctx) error {
 // @Param course_id query string true "Course ID"
 // @Param week query int false "Filter by week"
 // @Param type query string false "Filter by material type"
+// @Param tags query string false "Comma-separated tags; materials carrying any of them match"
+// @Param page query int false "Page number; sets the offset when offset is not given" default(1)
 // @Param limit query int false "Limit results" default(20)
 // @Param offset query int false "Offset results" default(0)
 // @Success 200 {object} response.StandardResponse{data=[]models.CourseMaterial}
@@ -570,17 +736,11 @@ func (h *CourseMaterialHandler) GetCourseMaterials(c *fiber.Ctx) error {
 	}
 
 	// Parse optional parameters
-	limit := 20
-	offset := 0
+	page, limit := response.ParsePagination(c, 20, 0)
+	offset := (page - 1) * limit
 	var week *int
 	var materialType *string
 
-	if limitStr := c.Query("limit"); limitStr != "" {
-		if parsed, err := strconv.Atoi(limitStr); err == nil && parsed > 0 {
-			limit = parsed
-		}
-	}
-
 	if offsetStr := c.Query("offset"); offsetStr != "" {
 		if parsed, err := strconv.Atoi(offsetStr); err == nil && parsed >= 0 {
 			offset = parsed
@@ -599,7 +759,12 @@ func (h *CourseMaterialHandler) GetCourseMaterials(c *fiber.Ctx) error {
 		}
 	}
 
-	materials, total, err := h.materialService.GetCourseMaterialsByCourse(courseID, week, materialType, limit, offset)
+	tags, err := materialpkg.NormalizeTags(materialpkg.SplitTags(c.Query("tags")))
+	if err != nil {
+		return response.ErrorResponse(c, http.StatusBadRequest, "Invalid tags", err.Error())
+	}
+
+	materials, total, err := h.materialService.GetCourseMaterialsByCourse(courseID, week, materialType, tags, limit, offset)
 	if err != nil {
 		return response.ErrorResponse(c, http.StatusInternalServerError, "Failed to get course materials", err.Error())
 	}
@@ -615,12 +780,13 @@ func (h *CourseMaterialHandler) GetCourseMaterials(c *fiber.Ctx) error {
 
 // GetCourseMaterial retrieves a specific course material
 // @Summary Get course material
-// @Description Get a specific course material by ID
+// @Description Get a specific course material by ID. Fetching a document or video counts as a view in its engagement report. Exercises also report whether they are locked for the caller and which prerequisites are missing, and carry an attempts object (types.AttemptStatus) with the caller's attempts used, whether they can submit, and when their latest queue job can be retried.
 // @Tags course-materials
 // @Produce json
 // @Param id path string true "Material ID"
 // @Success 200 {object} response.StandardResponse{data=models.CourseMaterial}
 // @Failure 400 {object} response.StandardResponse
+// @Failure 403 {object} response.StandardResponse
 // @Failure 404 {object} response.StandardResponse
 // @Failure 500 {object} response.StandardResponse
 // @Router /api/course-materials/{id} [get]
@@ -632,6 +798,10 @@ func (h *CourseMaterialHandler) GetCourseMaterial(c *fiber.Ctx) error {
 		return response.ErrorResponse(c, http.StatusBadRequest, "Material ID is required", nil)
 	}
 
+	if ok, err := h.validateMaterialAccess(c, materialID); !ok {
+		return err
+	}
+
 	material, err := h.materialService.GetCourseMaterialByID(materialID)
 	if err != nil {
 		if err.Error() == "course material not found" {
@@ -640,10 +810,354 @@ func (h *CourseMaterialHandler) GetCourseMaterial(c *fiber.Ctx) error {
 		return response.ErrorResponse(c, http.StatusInternalServerError, "Failed to get course material", err.Error())
 	}
 
+	// Exercises show the caller whether they are still locked by prerequisites, and their
+	// attempts and retry state
+	if _, isExercise := material["prerequisites"]; isExercise {
+		if claims, ok := c.Locals("claims").(*internaltypes.Claims); ok {
+			status, err := h.materialService.GetPrerequisiteStatus(claims.UserID, materialID)
+			if err != nil {
+				return response.ErrorResponse(c, http.StatusInternalServerError, "Failed to get prerequisite status", err.Error())
+			}
+			material["locked"] = status.Locked
+			material["missing_prerequisites"] = status.Missing
+
+			attempts, err := h.materialService.GetAttemptStatus(claims.UserID, materialID)
+			if err != nil {
+				return response.ErrorResponse(c, http.StatusInternalServerError, "Failed to get attempt status", err.Error())
+			}
+			if attempts != nil {
+				material["attempts"] = attempts
+			}
+		}
+	}
+
+	if claims, ok := c.Locals("claims").(*internaltypes.Claims); ok {
+		h.materialService.RecordMaterialAccess(claims.UserID, materialID, enums.MaterialAccessView)
+	}
+
 	// Material already contains full details from service (no need to call ToJSON())
 	return response.SuccessResponse(c, http.StatusOK, "Course material retrieved successfully", material)
 }
 
+// GetCourseMaterialForEdit retrieves a course material in the form its creator edits
+// @Summary Get course material for editing
+// @Description Get every editable field of a material, including settings such as pass threshold and attempt limits and, for code exercises, all test cases with hidden ones (creator only). GET /api/course-materials/{id} is the student-facing view and lists only public test cases.
+// @Tags course-materials
+// @Produce json
+// @Param id path string true "Material ID"
+// @Success 200 {object} response.StandardResponse
+// @Failure 400 {object} response.StandardResponse
+// @Failure 401 {object} response.StandardResponse
+// @Failure 403 {object} response.StandardResponse
+// @Failure 404 {object} response.StandardResponse
+// @Failure 500 {object} response.StandardResponse
+// @Router /api/course-materials/{id}/edit [get]
+// @Security BearerAuth
+// @Security ApiKeyAuth
+func (h *CourseMaterialHandler) GetCourseMaterialForEdit(c *fiber.Ctx) error {
+	userID, err := auth.UserIDFromContext(c)
+	if err != nil {
+		return response.ErrorResponse(c, http.StatusUnauthorized, "Invalid authentication", nil)
+	}
+
+	materialID := c.Params("id")
+	if materialID == "" {
+		return response.ErrorResponse(c, http.StatusBadRequest, "Material ID is required", nil)
+	}
+
+	material, err := h.materialService.GetMaterialForEdit(materialID, userID)
+	if err != nil {
+		if errors.Is(err, services.ErrNotMaterialCreator) {
+			return response.ErrorResponse(c, http.StatusForbidden, "Access denied", err.Error())
+		}
+		if err.Error() == "course material not found" {
+			return response.ErrorResponse(c, http.StatusNotFound, "Course material not found", nil)
+		}
+		return response.ErrorResponse(c, http.StatusInternalServerError, "Failed to get course material", err.Error())
+	}
+
+	return response.SuccessResponse(c, http.StatusOK, "Course material retrieved successfully", material)
+}
+
+// RecordMaterialAccess records a student's view or download of a document or video
+// @Summary Record material access
+// @Description Record that the caller viewed or downloaded a document or video material, for accesses the server does not see such as downloading its file from storage. Recording happens in the background; only students' accesses of documents and videos are kept, and repeats within a few minutes count once.
+// @Tags course-materials
+// @Accept json
+// @Produce json
+// @Param id path string true "Material ID"
+// @Param request body types.RecordMaterialAccessRequest true "Access kind"
+// @Success 202 {object} response.StandardResponse
+// @Failure 400 {object} response.StandardResponse
+// @Failure 403 {object} response.StandardResponse
+// @Failure 404 {object} response.StandardResponse
+// @Router /api/course-materials/{id}/engagement [post]
+// @Security BearerAuth
+// @Security ApiKeyAuth
+func (h *CourseMaterialHandler) RecordMaterialAccess(c *fiber.Ctx) error {
+	claims, ok := c.Locals("claims").(*internaltypes.Claims)
+	if !ok {
+		return response.ErrorResponse(c, http.StatusUnauthorized, "Invalid authentication", nil)
+	}
+
+	materialID := c.Params("id")
+	if materialID == "" {
+		return response.ErrorResponse(c, http.StatusBadRequest, "Material ID is required", nil)
+	}
+
+	var req types.RecordMaterialAccessRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.ErrorResponse(c, http.StatusBadRequest, "Invalid request body", err.Error())
+	}
+	if err := config.Validate.Struct(req); err != nil {
+		return response.ValidationErrorResponse(c, err, config.FieldErrors(err))
+	}
+
+	if ok, err := h.validateMaterialAccess(c, materialID); !ok {
+		return err
+	}
+
+	h.materialService.RecordMaterialAccess(claims.UserID, materialID, enums.MaterialAccessKind(req.Kind))
+	return response.SuccessResponse(c, http.StatusAccepted, "Material access recorded", nil)
+}
+
+// GetMaterialEngagement reports how students used a document or video
+// @Summary Get material engagement
+// @Description Get how many students viewed or downloaded a document or video material and how often (course teacher only). Repeats by a student within a few minutes count once.
+// @Tags course-materials
+// @Produce json
+// @Param id path string true "Material ID"
+// @Success 200 {object} response.StandardResponse{data=internaltypes.MaterialEngagement}
+// @Failure 400 {object} response.StandardResponse
+// @Failure 403 {object} response.StandardResponse
+// @Failure 404 {object} response.StandardResponse
+// @Failure 500 {object} response.StandardResponse
+// @Router /api/course-materials/{id}/engagement [get]
+// @Security BearerAuth
+// @Security ApiKeyAuth
+func (h *CourseMaterialHandler) GetMaterialEngagement(c *fiber.Ctx) error {
+	claims, ok := c.Locals("claims").(*internaltypes.Claims)
+	if !ok {
+		return response.ErrorResponse(c, http.StatusUnauthorized, "Invalid authentication", nil)
+	}
+
+	materialID := c.Params("id")
+	if materialID == "" {
+		return response.ErrorResponse(c, http.StatusBadRequest, "Material ID is required", nil)
+	}
+
+	engagement, err := h.materialService.GetMaterialEngagement(materialID, claims.UserID)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrEngagementNotTracked):
+			return response.ErrorResponse(c, http.StatusBadRequest, "Engagement not tracked", err.Error())
+		case errors.Is(err, services.ErrEngagementForbidden):
+			return response.ErrorResponse(c, http.StatusForbidden, "Access denied", err.Error())
+		case err.Error() == "course material not found":
+			return response.ErrorResponse(c, http.StatusNotFound, "Course material not found", nil)
+		}
+		return response.ErrorResponse(c, http.StatusInternalServerError, "Failed to get material engagement", err.Error())
+	}
+
+	return response.SuccessResponse(c, http.StatusOK, "Material engagement retrieved successfully", engagement)
+}
+
+// GetCourseMaterialsBatch retrieves several course materials by ID
+// @Summary Get course materials by IDs
+// @Description Get the full details of up to 100 materials, which may belong to different courses. The result is keyed by material ID; IDs that do not exist or belong to a course the caller cannot access are listed in not_found.
+// @Tags course-materials
+// @Accept json
+// @Produce json
+// @Param request body types.BatchGetCourseMaterialsRequest true "Material IDs"
+// @Success 200 {object} response.StandardResponse{data=object{materials=map[string]object,not_found=[]string}}
+// @Failure 400 {object} response.StandardResponse
+// @Failure 401 {object} response.StandardResponse
+// @Failure 500 {object} response.StandardResponse
+// @Router /api/course-materials/batch [post]
+// @Security BearerAuth
+// @Security ApiKeyAuth
+func (h *CourseMaterialHandler) GetCourseMaterialsBatch(c *fiber.Ctx) error {
+	var req types.BatchGetCourseMaterialsRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.ErrorResponse(c, http.StatusBadRequest, "Invalid request body", err.Error())
+	}
+	if err := config.Validate.Struct(req); err != nil {
+		return response.ValidationErrorResponse(c, err, config.FieldErrors(err))
+	}
+
+	found, err := h.materialService.GetMaterialsByIDs(req.MaterialIDs)
+	if err != nil {
+		return response.ErrorResponse(c, http.StatusInternalServerError, "Failed to get course materials", err.Error())
+	}
+
+	// Check access once per course. Inaccessible materials are reported like missing
+	// ones so that the response does not reveal which IDs exist.
+	courseAccess := make(map[string]bool)
+	seen := make(map[string]bool, len(req.MaterialIDs))
+	materials := make(map[string]map[string]interface{}, len(found))
+	notFound := []string{}
+	for _, materialID := range req.MaterialIDs {
+		if seen[materialID] {
+			continue
+		}
+		seen[materialID] = true
+
+		material, ok := found[materialID]
+		if ok {
+			courseID, _ := material["course_id"].(string)
+			allowed, checked := courseAccess[courseID]
+			if !checked {
+				allowed = h.enrollmentValidator.ValidateCourseAccess(c, courseID) == nil
+				courseAccess[courseID] = allowed
+			}
+			ok = allowed
+		}
+		if !ok {
+			notFound = append(notFound, materialID)
+			continue
+		}
+		materials[materialID] = material
+	}
+
+	return response.SuccessResponse(c, http.StatusOK, "Course materials retrieved successfully", fiber.Map{
+		"materials": materials,
+		"not_found": notFound,
+	})
+}
+
+// BulkSetVisibility shows or hides several materials at once
+// @Summary Set visibility of several materials
+// @Description Make up to 100 materials public or hidden in one transaction, e.g. a week's worth at the start of a lab (material creator only). If any material is missing or was created by someone else, nothing changes. Materials that become public are announced as when published one by one.
+// @Tags course-materials
+// @Accept json
+// @Produce json
+// @Param request body types.BulkSetVisibilityRequest true "Materials and visibility"
+// @Success 200 {object} response.StandardResponse{data=internaltypes.BulkVisibilityResult}
+// @Failure 400 {object} response.StandardResponse
+// @Failure 401 {object} response.StandardResponse
+// @Failure 403 {object} response.StandardResponse
+// @Failure 404 {object} response.StandardResponse
+// @Failure 500 {object} response.StandardResponse
+// @Router /api/course-materials/bulk-visibility [post]
+// @Security BearerAuth
+// @Security ApiKeyAuth
+func (h *CourseMaterialHandler) BulkSetVisibility(c *fiber.Ctx) error {
+	claims, ok := c.Locals("claims").(*internaltypes.Claims)
+	if !ok {
+		return response.ErrorResponse(c, http.StatusUnauthorized, "Invalid authentication", nil)
+	}
+
+	var req types.BulkSetVisibilityRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.ErrorResponse(c, http.StatusBadRequest, "Invalid request body", err.Error())
+	}
+	if err := config.Validate.Struct(req); err != nil {
+		return response.ValidationErrorResponse(c, err, config.FieldErrors(err))
+	}
+
+	result, err := h.materialService.BulkSetVisibility(req.MaterialIDs, *req.IsPublic, claims.UserID)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrMaterialNotFound):
+			return response.ErrorResponse(c, http.StatusNotFound, "Course materials not found", err.Error())
+		case errors.Is(err, services.ErrNotMaterialCreator):
+			return response.ErrorResponse(c, http.StatusForbidden, "Access denied", err.Error())
+		}
+		return response.ErrorResponse(c, http.StatusInternalServerError, "Failed to update material visibility", err.Error())
+	}
+
+	return response.SuccessResponse(c, http.StatusOK, "Material visibility updated successfully", result)
+}
+
+// CheckMaterialIntegrity reports broken course material references
+// @Summary Check course material integrity
+// @Description Report course_materials rows whose reference is unset, missing, of the wrong type or in another course, and specific material records (videos, documents, exercises, announcements) that no course material references. Nothing is changed. Operator endpoint, requires the service API key.
+// @Tags admin
+// @Produce json
+// @Success 200 {object} response.StandardResponse{data=internaltypes.MaterialIntegrityReport}
+// @Failure 401 {object} response.StandardResponse
+// @Failure 500 {object} response.StandardResponse
+// @Router /api/admin/materials/integrity [get]
+// @Security ApiKeyAuth
+func (h *CourseMaterialHandler) CheckMaterialIntegrity(c *fiber.Ctx) error {
+	report, err := h.materialService.CheckMaterialIntegrity(c.Context(), false)
+	if err != nil {
+		return response.ErrorResponse(c, http.StatusInternalServerError, "Failed to check material integrity", err.Error())
+	}
+	return response.SuccessResponse(c, http.StatusOK, "Material integrity checked", report)
+}
+
+// RepairMaterialIntegrity deletes dangling course materials and orphaned material records
+// @Summary Repair course material integrity
+// @Description Run the integrity check and delete what it can repair: course_materials rows whose referenced record is missing and specific records no course material references. References of the wrong type or course are only reported. Files in storage are not deleted. Operator endpoint, requires the service API key.
+// @Tags admin
+// @Produce json
+// @Success 200 {object} response.StandardResponse{data=internaltypes.MaterialIntegrityReport}
+// @Failure 401 {object} response.StandardResponse
+// @Failure 500 {object} response.StandardResponse
+// @Router /api/admin/materials/integrity/repair [post]
+// @Security ApiKeyAuth
+func (h *CourseMaterialHandler) RepairMaterialIntegrity(c *fiber.Ctx) error {
+	report, err := h.materialService.CheckMaterialIntegrity(c.Context(), true)
+	if err != nil {
+		return response.ErrorResponse(c, http.StatusInternalServerError, "Failed to repair material integrity", err.Error())
+	}
+	return response.SuccessResponse(c, http.StatusOK, "Material integrity repaired", report)
+}
+
+// PreviewCourseMaterial renders a material's markdown fields as sanitized HTML
+// @Summary Preview course material rendering
+// @Description Render the problem statement, constraints, hints and description of a material to sanitized HTML (course creator only)
+// @Tags course-materials
+// @Produce json
+// @Param id path string true "Material ID"
+// @Success 200 {object} response.StandardResponse
+// @Failure 400 {object} response.StandardResponse
+// @Failure 401 {object} response.StandardResponse
+// @Failure 403 {object} response.StandardResponse
+// @Failure 404 {object} response.StandardResponse
+// @Failure 500 {object} response.StandardResponse
+// @Router /api/course-materials/{id}/preview [get]
+// @Security BearerAuth
+// @Security ApiKeyAuth
+func (h *CourseMaterialHandler) PreviewCourseMaterial(c *fiber.Ctx) error {
+	materialID := c.Params("id")
+	if materialID == "" {
+		return response.ErrorResponse(c, http.StatusBadRequest, "Material ID is required", nil)
+	}
+
+	claims, ok := c.Locals("claims").(*internaltypes.Claims)
+	if !ok {
+		return response.ErrorResponse(c, http.StatusUnauthorized, "Invalid authentication", nil)
+	}
+
+	// Previews are for the course's teachers, so check before rendering anything
+	courseID, err := authz.MaterialCourseID(h.materialService.GetDB(), materialID)
+	if err != nil {
+		if errors.Is(err, authz.ErrMaterialNotFound) {
+			return response.ErrorResponse(c, http.StatusNotFound, "Course material not found", nil)
+		}
+		return response.ErrorResponse(c, http.StatusInternalServerError, "Failed to get course material", err.Error())
+	}
+	canManage, err := authz.CanManageCourse(h.materialService.GetDB(), claims.UserID, courseID)
+	if err != nil {
+		return response.ErrorResponse(c, http.StatusInternalServerError, "Failed to check permissions", err.Error())
+	}
+	if !canManage {
+		return response.ErrorResponse(c, http.StatusForbidden, "Only the course teacher can preview materials", nil)
+	}
+
+	preview, err := h.materialService.RenderCourseMaterialPreview(materialID)
+	if err != nil {
+		if err.Error() == "course material not found" {
+			return response.ErrorResponse(c, http.StatusNotFound, "Course material not found", nil)
+		}
+		return response.ErrorResponse(c, http.StatusInternalServerError, "Failed to render course material", err.Error())
+	}
+
+	return response.SuccessResponse(c, http.StatusOK, "Course material preview rendered successfully", preview)
+}
+
 // UpdateCourseMaterial updates a course material
 // @Summary Update course material
 // @Description Update an existing course material (creator only)
@@ -710,11 +1224,14 @@ func (h *CourseMaterialHandler) UpdateCourseMaterial(c *fiber.Ctx) error {
 	reqForValidation := req
 	reqForValidation.TestCases = nil
 	if err := config.Validate.Struct(reqForValidation); err != nil {
-		return response.ErrorResponse(c, http.StatusBadRequest, "Validation failed", err.Error())
+		return response.ValidationErrorResponse(c, err, config.FieldErrors(err))
 	}
 
 	// Get user ID from context
-	userID := c.Locals("user_id").(string)
+	userID, err := auth.UserIDFromContext(c)
+	if err != nil {
+		return response.ErrorResponse(c, http.StatusUnauthorized, "Invalid authentication", nil)
+	}
 
 	// Prepare updates
 	updates := make(map[string]interface{})
@@ -736,6 +1253,13 @@ func (h *CourseMaterialHandler) UpdateCourseMaterial(c *fiber.Ctx) error {
 	if req.IsPublic != nil {
 		updates["is_public"] = *req.IsPublic
 	}
+	if req.Tags != nil {
+		tags, err := materialpkg.NormalizeTags(*req.Tags)
+		if err != nil {
+			return response.ErrorResponse(c, http.StatusBadRequest, "Invalid tags", err.Error())
+		}
+		updates["tags"] = internaltypes.StringList(tags)
+	}
 	if req.Content != nil {
 		updates["content"] = *req.Content
 	}
@@ -755,147 +1279,89 @@ func (h *CourseMaterialHandler) UpdateCourseMaterial(c *fiber.Ctx) error {
 	if req.Hints != nil {
 		updates["hints"] = *req.Hints
 	}
+	if req.OutputMode != nil {
+		updates["output_mode"] = *req.OutputMode
+	}
+	if req.LockAfterApproval != nil {
+		updates["lock_after_approval"] = *req.LockAfterApproval
+	}
+	if req.MaxAttempts != nil {
+		updates["max_attempts"] = *req.MaxAttempts
+	}
+	if req.TrimWhitespace != nil {
+		updates["trim_whitespace"] = *req.TrimWhitespace
+	}
+	if req.NumberTolerance != nil {
+		updates["number_tolerance"] = *req.NumberTolerance
+	}
+	if req.PassThreshold != nil {
+		updates["pass_threshold"] = *req.PassThreshold
+	}
+	if req.MaxConcurrentSubmissions != nil {
+		updates["max_concurrent_submissions"] = *req.MaxConcurrentSubmissions
+	}
+	if req.ClearOpensAt {
+		updates["opens_at"] = nil
+	} else if req.OpensAt != nil {
+		updates["opens_at"] = *req.OpensAt
+	}
+	if req.ClearClosesAt {
+		updates["closes_at"] = nil
+	} else if req.ClosesAt != nil {
+		updates["closes_at"] = *req.ClosesAt
+	}
+	if req.Prerequisites != nil {
+		courseID, err := authz.MaterialCourseID(h.materialService.GetDB(), materialID)
+		if err != nil {
+			if errors.Is(err, authz.ErrMaterialNotFound) {
+				return response.ErrorResponse(c, http.StatusNotFound, "Course material not found", nil)
+			}
+			return response.ErrorResponse(c, http.StatusInternalServerError, "Failed to get course material", err.Error())
+		}
+		prerequisites, err := h.materialService.ValidatePrerequisites(courseID, materialID, *req.Prerequisites)
+		if err != nil {
+			if errors.Is(err, services.ErrInvalidPrerequisites) {
+				return response.ErrorResponse(c, http.StatusBadRequest, "Invalid prerequisites", err.Error())
+			}
+			return response.ErrorResponse(c, http.StatusInternalServerError, "Failed to validate prerequisites", err.Error())
+		}
+		updates["prerequisites"] = prerequisites
+	}
+	// PDF exercise fields
+	if req.MaxPages != nil {
+		updates["max_pages"] = *req.MaxPages
+	}
 	// Note: test_cases are handled separately below
 
-	// Handle test cases update for code exercises (before updating material)
-	// Update test cases if provided (even if empty array to clear all test cases)
+	// Handle test cases update for code exercises (before updating material).
+	// test_cases is the complete list: entries with a test_case_id update that test case
+	// (fields they leave out keep their values), entries without one are added and need
+	// input_data and expected_output, and test cases left out are deleted (an empty array
+	// clears them all).
 	if req.TestCases != nil {
-		// Get material to check if it's a code exercise
-		material, err := h.materialService.GetCourseMaterialByID(materialID)
+		courseMaterial, err := h.materialService.GetCourseMaterialByID(materialID)
 		if err == nil {
-			if materialType, ok := material["type"].(string); ok && materialType == "code_exercise" {
-				// Delete all existing test cases
-				existingTestCases, _ := h.materialService.GetTestCases(materialID)
-				for _, tc := range existingTestCases {
-					h.materialService.DeleteTestCase(tc.TestCaseID, userID)
-				}
-
-				// Create new test cases
-				var exampleInputs []string
-				var exampleOutputs []string
-				testCases := []models.TestCase{}
+			if materialType, ok := courseMaterial["type"].(string); ok && materialType == "code_exercise" {
+				changes := make([]internaltypes.TestCaseChange, 0, len(*req.TestCases))
 				for _, tcData := range *req.TestCases {
-					// Get input_data and expected_output
-					inputDataRaw, ok := tcData["input_data"]
-					if !ok {
-						continue
-					}
-					expectedOutputRaw, ok := tcData["expected_output"]
-					if !ok {
-						continue
-					}
-
-					// Convert input_data to JSON
-					var inputDataBytes []byte
-					var inputStr string
-					if inputStrVal, ok := inputDataRaw.(string); ok {
-						inputStr = inputStrVal
-						// Try to parse as JSON first (handles both JSON strings and plain text)
-						var parsedInput interface{}
-						if err := json.Unmarshal([]byte(inputStr), &parsedInput); err == nil {
-							// Valid JSON, use it as is
-							inputDataBytes, _ = json.Marshal(parsedInput)
-							inputStr = string(inputDataBytes)
-						} else {
-							// Not valid JSON, treat as plain text
-							inputDataBytes, _ = json.Marshal(inputStr)
-							inputStr = string(inputDataBytes)
-						}
-					} else {
-						// Already an object, marshal it
-						inputDataBytes, _ = json.Marshal(inputDataRaw)
-						inputStr = string(inputDataBytes)
-					}
-
-					// Convert expected_output to JSON
-					var expectedOutputBytes []byte
-					var outputStr string
-					if outputStrVal, ok := expectedOutputRaw.(string); ok {
-						outputStr = outputStrVal
-						// Try to parse as JSON first
-						var parsedOutput interface{}
-						if err := json.Unmarshal([]byte(outputStr), &parsedOutput); err == nil {
-							// Valid JSON, check if it's an object with "output" key
-							if outputMap, ok := parsedOutput.(map[string]interface{}); ok {
-								if _, hasOutput := outputMap["output"]; hasOutput {
-									// Already has "output" key, use as is
-									expectedOutputBytes, _ = json.Marshal(parsedOutput)
-									outputStr = string(expectedOutputBytes)
-								} else {
-									// Valid JSON object but no "output" key, wrap it
-									outputObj := map[string]interface{}{"output": parsedOutput}
-									expectedOutputBytes, _ = json.Marshal(outputObj)
-									outputStr = string(expectedOutputBytes)
-								}
-							} else {
-								// Valid JSON but not an object (array, number, etc.), wrap it
-								outputObj := map[string]interface{}{"output": parsedOutput}
-								expectedOutputBytes, _ = json.Marshal(outputObj)
-								outputStr = string(expectedOutputBytes)
-							}
-						} else {
-							// Not valid JSON, treat as plain text and wrap
-							outputObj := map[string]interface{}{"output": outputStr}
-							expectedOutputBytes, _ = json.Marshal(outputObj)
-							outputStr = string(expectedOutputBytes)
-						}
-					} else {
-						// Already an object, check if it has "output" key
-						if outputMap, ok := expectedOutputRaw.(map[string]interface{}); ok {
-							if _, hasOutput := outputMap["output"]; hasOutput {
-								// Already has "output" key, use as is
-								expectedOutputBytes, _ = json.Marshal(expectedOutputRaw)
-								outputStr = string(expectedOutputBytes)
-							} else {
-								// No "output" key, wrap it
-								outputObj := map[string]interface{}{"output": expectedOutputRaw}
-								expectedOutputBytes, _ = json.Marshal(outputObj)
-								outputStr = string(expectedOutputBytes)
-							}
-						} else {
-							// Not a map, wrap it
-							outputObj := map[string]interface{}{"output": expectedOutputRaw}
-							expectedOutputBytes, _ = json.Marshal(outputObj)
-							outputStr = string(expectedOutputBytes)
-						}
-					}
-
-					// Get display_name if present
-					displayName := ""
-					if dn, ok := tcData["display_name"].(string); ok && dn != "" {
-						displayName = dn
-					}
-
-					exampleInputs = append(exampleInputs, inputStr)
-					exampleOutputs = append(exampleOutputs, outputStr)
-
-					testCase := models.TestCase{
-						MaterialID:     &materialID,
-						MaterialType:   "code_exercise",
-						InputData:      internaltypes.JSONData(inputDataBytes),
-						ExpectedOutput: internaltypes.JSONData(expectedOutputBytes),
-						DisplayName:    displayName,
-						IsPublic:       true,
-					}
-					testCases = append(testCases, testCase)
+					changes = append(changes, parseTestCaseChange(tcData))
 				}
 
-				// Create new test cases
-				for i := range testCases {
-					if err := h.materialService.AddTestCase(materialID, &testCases[i]); err != nil {
-						return response.ErrorResponse(c, http.StatusInternalServerError, "Failed to create test case", err.Error())
+				synced, err := h.materialService.SyncTestCases(materialID, userID, changes)
+				if err != nil {
+					switch {
+					case errors.Is(err, services.ErrTestCaseTooLarge):
+						return response.ErrorResponse(c, http.StatusBadRequest, "Test case too large", err.Error())
+					case errors.Is(err, services.ErrUnknownTestCase), errors.Is(err, services.ErrIncompleteTestCase):
+						return response.ErrorResponse(c, http.StatusBadRequest, "Invalid test case", err.Error())
+					case err.Error() == "only the creator can update test cases":
+						return response.ErrorResponse(c, http.StatusForbidden, "Access denied", err.Error())
 					}
+					return response.ErrorResponse(c, http.StatusInternalServerError, "Failed to update test cases", err.Error())
 				}
 
 				// Update example inputs/outputs
-				if len(exampleInputs) > 0 {
-					exampleInputsJSON, _ := json.Marshal(exampleInputs)
-					updates["example_inputs"] = internaltypes.JSONData(exampleInputsJSON)
-				}
-				if len(exampleOutputs) > 0 {
-					exampleOutputsJSON, _ := json.Marshal(exampleOutputs)
-					updates["example_outputs"] = internaltypes.JSONData(exampleOutputsJSON)
-				}
+				updates["example_inputs"], updates["example_outputs"] = testCaseExamples(synced)
 			}
 		}
 	}
@@ -907,11 +1373,14 @@ func (h *CourseMaterialHandler) UpdateCourseMaterial(c *fiber.Ctx) error {
 		if err.Error() == "only the creator can update this course material" {
 			return response.ErrorResponse(c, http.StatusForbidden, "Access denied", err.Error())
 		}
+		if errors.Is(err, services.ErrInvalidSubmissionWindow) {
+			return response.ErrorResponse(c, http.StatusBadRequest, "Invalid submission window", err.Error())
+		}
 		return response.ErrorResponse(c, http.StatusInternalServerError, "Failed to update course material", err.Error())
 	}
 
-	// Get updated material
-	material, err := h.materialService.GetCourseMaterialByID(materialID)
+	// Get updated material, in the form the creator edits
+	material, err := h.materialService.GetMaterialForEdit(materialID, userID)
 	if err != nil {
 		return response.ErrorResponse(c, http.StatusInternalServerError, "Failed to get updated course material", err.Error())
 	}
@@ -920,17 +1389,105 @@ func (h *CourseMaterialHandler) UpdateCourseMaterial(c *fiber.Ctx) error {
 	return response.SuccessResponse(c, http.StatusOK, "Course material updated successfully", material)
 }
 
+// testCaseExamples returns the example_inputs and example_outputs of a code exercise
+// whose test cases were synced. Only public test cases are in synced, so the expected
+// outputs of hidden ones are never shown to students.
+func testCaseExamples(synced *internaltypes.TestCaseSyncResult) (inputs, outputs internaltypes.JSONData) {
+	exampleInputs := make([]string, 0, len(synced.InputData))
+	exampleOutputs := make([]string, 0, len(synced.ExpectedOutput))
+	for i := range synced.InputData {
+		exampleInputs = append(exampleInputs, string(synced.InputData[i]))
+		exampleOutputs = append(exampleOutputs, string(synced.ExpectedOutput[i]))
+	}
+	inputs, _ = json.Marshal(exampleInputs)
+	outputs, _ = json.Marshal(exampleOutputs)
+	return inputs, outputs
+}
+
+// normalizeTestCaseInput converts a test case's input_data from the edit form to JSON. A
+// string holding JSON is used as that JSON, any other string as a JSON string.
+func normalizeTestCaseInput(raw interface{}) internaltypes.JSONData {
+	if str, ok := raw.(string); ok {
+		var parsed interface{}
+		if err := json.Unmarshal([]byte(str), &parsed); err == nil {
+			data, _ := json.Marshal(parsed)
+			return data
+		}
+		data, _ := json.Marshal(str)
+		return data
+	}
+	data, _ := json.Marshal(raw)
+	return data
+}
+
+// parseTestCaseChange reads one test_cases entry of a material update. Fields the entry
+// leaves out stay nil, so an existing test case keeps their values.
+func parseTestCaseChange(tcData map[string]interface{}) internaltypes.TestCaseChange {
+	var change internaltypes.TestCaseChange
+	if inputDataRaw, ok := tcData["input_data"]; ok {
+		change.InputData = normalizeTestCaseInput(inputDataRaw)
+	}
+	if expectedOutputRaw, ok := tcData["expected_output"]; ok {
+		change.ExpectedOutput = normalizeExpectedOutput(expectedOutputRaw)
+	}
+	if id, ok := tcData["test_case_id"].(string); ok {
+		change.TestCaseID = id
+	}
+	if dn, ok := tcData["display_name"].(string); ok {
+		change.DisplayName = &dn
+	}
+	if isPublic, ok := tcData["is_public"].(bool); ok {
+		change.IsPublic = &isPublic
+	}
+	return change
+}
+
+// normalizeExpectedOutput converts a test case's expected_output from the edit form to
+// JSON wrapped as {"output": ...}, the form the executor produces. Strings are parsed as
+// JSON when they hold JSON; values already wrapped are kept as they are.
+func normalizeExpectedOutput(raw interface{}) internaltypes.JSONData {
+	value := raw
+	if str, ok := raw.(string); ok {
+		var parsed interface{}
+		if err := json.Unmarshal([]byte(str), &parsed); err == nil {
+			value = parsed
+		}
+	}
+	if outputMap, ok := value.(map[string]interface{}); ok {
+		if _, hasOutput := outputMap["output"]; hasOutput {
+			data, _ := json.Marshal(outputMap)
+			return data
+		}
+	}
+	data, _ := json.Marshal(map[string]interface{}{"output": value})
+	return data
+}
+
+// parseOptionalTime parses an RFC 3339 form value; an empty value is nil
+func parseOptionalTime(value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
 // DeleteCourseMaterial deletes a course material
 // @Summary Delete course material
-// @Description Delete a course material (creator only)
+// @Description Delete a course material (creator only). A material students have submitted to or made progress on is only deleted with force=true, which also deletes their submissions, submission files and progress; without it the request fails with the counts.
 // @Tags course-materials
 // @Produce json
 // @Param id path string true "Material ID"
+// @Param force query bool false "Also delete the students' submissions and progress"
 // @Success 200 {object} response.StandardResponse
 // @Failure 400 {object} response.StandardResponse
 // @Failure 401 {object} response.StandardResponse
 // @Failure 403 {object} response.StandardResponse
 // @Failure 404 {object} response.StandardResponse
+// @Failure 409 {object} response.StandardResponse "Material has student submissions or progress"
 // @Failure 500 {object} response.StandardResponse
 // @Router /api/course-materials/{id} [delete]
 // @Security BearerAuth
@@ -942,12 +1499,18 @@ func (h *CourseMaterialHandler) DeleteCourseMaterial(c *fiber.Ctx) error {
 	}
 
 	// Get user ID from context
-	userID := c.Locals("user_id").(string)
+	userID, err := auth.UserIDFromContext(c)
+	if err != nil {
+		return response.ErrorResponse(c, http.StatusUnauthorized, "Invalid authentication", nil)
+	}
 
-	if err := h.materialService.DeleteCourseMaterial(materialID, userID); err != nil {
+	if err := h.materialService.DeleteCourseMaterial(materialID, userID, c.QueryBool("force")); err != nil {
 		if err.Error() == "course material not found" {
 			return response.ErrorResponse(c, http.StatusNotFound, "Course material not found", nil)
 		}
+		if errors.Is(err, services.ErrMaterialHasStudentWork) {
+			return response.ErrorResponse(c, http.StatusConflict, "Course material has student work", err.Error())
+		}
 		if err.Error() == "only the creator can delete this course material" {
 			return response.ErrorResponse(c, http.StatusForbidden, "Access denied", err.Error())
 		}
@@ -970,6 +1533,7 @@ func (h *CourseMaterialHandler) DeleteCourseMaterial(c *fiber.Ctx) error {
 // @Success 200 {object} response.StandardResponse{data=[]models.TestCase}
 // @Failure 400 {object} response.StandardResponse
 // @Failure 401 {object} response.StandardResponse
+// @Failure 403 {object} response.StandardResponse
 // @Failure 404 {object} response.StandardResponse
 // @Failure 500 {object} response.StandardResponse
 // @Router /api/course-materials/{id}/test-cases [get]
@@ -981,6 +1545,10 @@ func (h *CourseMaterialHandler) GetTestCases(c *fiber.Ctx) error {
 		return response.ErrorResponse(c, http.StatusBadRequest, "Material ID is required", nil)
 	}
 
+	if ok, err := h.validateMaterialAccess(c, materialID); !ok {
+		return err
+	}
+
 	testCases, err := h.materialService.GetTestCases(materialID)
 	if err != nil {
 		if err.Error() == "course material not found" {
@@ -1000,12 +1568,12 @@ func (h *CourseMaterialHandler) GetTestCases(c *fiber.Ctx) error {
 
 // AddTestCase adds a test case to a material
 // @Summary Add test case
-// @Description Add a test case to a material (teachers only)
+// @Description Add a test case to a material (teachers only). Instead of inline input_data, input_key may name a file uploaded to the course's storage holding the JSON input, for inputs too large to store inline; it is streamed to the program when grading.
 // @Tags course-materials
 // @Accept json
 // @Produce json
 // @Param id path string true "Material ID"
-// @Param request body object{input_data=object,expected_output=object} true "Test case data"
+// @Param request body object{input_data=object,input_key=string,expected_output=object} true "Test case data"
 // @Success 201 {object} response.StandardResponse{data=models.TestCase}
 // @Failure 400 {object} response.StandardResponse
 // @Failure 401 {object} response.StandardResponse
@@ -1022,7 +1590,8 @@ func (h *CourseMaterialHandler) AddTestCase(c *fiber.Ctx) error {
 	}
 
 	var req struct {
-		InputData      map[string]interface{} `json:"input_data" validate:"required"`
+		InputData      map[string]interface{} `json:"input_data" validate:"required_without=InputKey"`
+		InputKey       string                 `json:"input_key,omitempty"`
 		ExpectedOutput map[string]interface{} `json:"expected_output" validate:"required"`
 	}
 
@@ -1032,10 +1601,10 @@ func (h *CourseMaterialHandler) AddTestCase(c *fiber.Ctx) error {
 
 	// Validate request
 	if err := config.Validate.Struct(req); err != nil {
-		return response.ErrorResponse(c, http.StatusBadRequest, "Validation failed", err.Error())
+		return response.ValidationErrorResponse(c, err, config.FieldErrors(err))
 	}
 
-	// Convert maps to JSON bytes
+	// Convert maps to JSON bytes; a stored input leaves input_data null
 	inputDataBytes, _ := json.Marshal(req.InputData)
 	expectedOutputBytes, _ := json.Marshal(req.ExpectedOutput)
 
@@ -1044,6 +1613,9 @@ func (h *CourseMaterialHandler) AddTestCase(c *fiber.Ctx) error {
 		InputData:      internaltypes.JSONData(inputDataBytes),
 		ExpectedOutput: internaltypes.JSONData(expectedOutputBytes),
 	}
+	if req.InputKey != "" {
+		testCase.InputKey = &req.InputKey
+	}
 
 	if err := h.materialService.AddTestCase(materialID, testCase); err != nil {
 		if err.Error() == "course material not found" {
@@ -1052,6 +1624,12 @@ func (h *CourseMaterialHandler) AddTestCase(c *fiber.Ctx) error {
 		if err.Error() == "can only add test cases to code exercises" {
 			return response.ErrorResponse(c, http.StatusBadRequest, "Can only add test cases to code exercises", nil)
 		}
+		if errors.Is(err, services.ErrTestCaseTooLarge) {
+			return response.ErrorResponse(c, http.StatusBadRequest, "Test case too large", err.Error())
+		}
+		if errors.Is(err, services.ErrInvalidInputKey) {
+			return response.ErrorResponse(c, http.StatusBadRequest, "Invalid input key", err.Error())
+		}
 		return response.ErrorResponse(c, http.StatusInternalServerError, "Failed to add test case", err.Error())
 	}
 
@@ -1060,12 +1638,12 @@ func (h *CourseMaterialHandler) AddTestCase(c *fiber.Ctx) error {
 
 // UpdateTestCase updates a test case
 // @Summary Update test case
-// @Description Update a test case (teachers only)
+// @Description Update a test case (teachers only). Setting input_key reads the input from that stored file; an empty input_key goes back to input_data.
 // @Tags course-materials
 // @Accept json
 // @Produce json
 // @Param test_case_id path string true "Test Case ID"
-// @Param request body object{input_data=object,expected_output=object} true "Test case data"
+// @Param request body object{input_data=object,input_key=string,expected_output=object} true "Test case data"
 // @Success 200 {object} response.StandardResponse{data=models.TestCase}
 // @Failure 400 {object} response.StandardResponse
 // @Failure 401 {object} response.StandardResponse
@@ -1083,6 +1661,7 @@ func (h *CourseMaterialHandler) UpdateTestCase(c *fiber.Ctx) error {
 
 	var req struct {
 		InputData      map[string]interface{} `json:"input_data,omitempty"`
+		InputKey       *string                `json:"input_key,omitempty"`
 		ExpectedOutput map[string]interface{} `json:"expected_output,omitempty"`
 	}
 
@@ -1091,13 +1670,19 @@ func (h *CourseMaterialHandler) UpdateTestCase(c *fiber.Ctx) error {
 	}
 
 	// Get user ID from context
-	userID := c.Locals("user_id").(string)
+	userID, err := auth.UserIDFromContext(c)
+	if err != nil {
+		return response.ErrorResponse(c, http.StatusUnauthorized, "Invalid authentication", nil)
+	}
 
 	// Prepare updates
 	updates := make(map[string]interface{})
 	if req.InputData != nil {
 		updates["input_data"] = req.InputData
 	}
+	if req.InputKey != nil {
+		updates["input_key"] = *req.InputKey
+	}
 	if req.ExpectedOutput != nil {
 		updates["expected_output"] = req.ExpectedOutput
 	}
@@ -1109,6 +1694,12 @@ func (h *CourseMaterialHandler) UpdateTestCase(c *fiber.Ctx) error {
 		if err.Error() == "only the creator can update test cases" {
 			return response.ErrorResponse(c, http.StatusForbidden, "Access denied", err.Error())
 		}
+		if errors.Is(err, services.ErrTestCaseTooLarge) {
+			return response.ErrorResponse(c, http.StatusBadRequest, "Test case too large", err.Error())
+		}
+		if errors.Is(err, services.ErrInvalidInputKey) {
+			return response.ErrorResponse(c, http.StatusBadRequest, "Invalid input key", err.Error())
+		}
 		return response.ErrorResponse(c, http.StatusInternalServerError, "Failed to update test case", err.Error())
 	}
 
@@ -1137,7 +1728,10 @@ func (h *CourseMaterialHandler) DeleteTestCase(c *fiber.Ctx) error {
 	}
 
 	// Get user ID from context
-	userID := c.Locals("user_id").(string)
+	userID, err := auth.UserIDFromContext(c)
+	if err != nil {
+		return response.ErrorResponse(c, http.StatusUnauthorized, "Invalid authentication", nil)
+	}
 
 	if err := h.materialService.DeleteTestCase(testCaseID, userID); err != nil {
 		if err.Error() == "test case not found" {
@@ -1152,6 +1746,107 @@ func (h *CourseMaterialHandler) DeleteTestCase(c *fiber.Ctx) error {
 	return response.SuccessResponse(c, http.StatusOK, "Test case deleted successfully", nil)
 }
 
+// DeleteAllTestCases deletes every test case of a material
+// @Summary Delete all test cases
+// @Description Delete all test cases of a code exercise in one operation and clear its example inputs/outputs (creator only)
+// @Tags course-materials
+// @Produce json
+// @Param id path string true "Material ID"
+// @Success 200 {object} response.StandardResponse{data=object{deleted=int}}
+// @Failure 400 {object} response.StandardResponse
+// @Failure 401 {object} response.StandardResponse
+// @Failure 403 {object} response.StandardResponse
+// @Failure 404 {object} response.StandardResponse
+// @Failure 500 {object} response.StandardResponse
+// @Router /api/course-materials/{id}/test-cases [delete]
+// @Security BearerAuth
+// @Security ApiKeyAuth
+func (h *CourseMaterialHandler) DeleteAllTestCases(c *fiber.Ctx) error {
+	materialID := c.Params("id")
+	if materialID == "" {
+		return response.ErrorResponse(c, http.StatusBadRequest, "Material ID is required", nil)
+	}
+
+	// Get user ID from context
+	userID, err := auth.UserIDFromContext(c)
+	if err != nil {
+		return response.ErrorResponse(c, http.StatusUnauthorized, "Invalid authentication", nil)
+	}
+
+	deleted, err := h.materialService.DeleteAllTestCases(materialID, userID)
+	if err != nil {
+		switch err.Error() {
+		case "course material not found":
+			return response.ErrorResponse(c, http.StatusNotFound, "Course material not found", nil)
+		case "test cases can only be deleted for code exercises":
+			return response.ErrorResponse(c, http.StatusBadRequest, err.Error(), nil)
+		case "only the creator can delete test cases":
+			return response.ErrorResponse(c, http.StatusForbidden, "Access denied", err.Error())
+		}
+		return response.ErrorResponse(c, http.StatusInternalServerError, "Failed to delete test cases", err.Error())
+	}
+
+	return response.SuccessResponse(c, http.StatusOK, "Test cases deleted successfully", fiber.Map{
+		"deleted": deleted,
+	})
+}
+
+// UpdateTestCaseDisplayNames renames several test cases of a material at once
+// @Summary Bulk update test case display names
+// @Description Set the display names of several test cases of a code exercise in one transaction, keyed by test case ID. Every ID must belong to the material; an empty name clears it (creator only)
+// @Tags course-materials
+// @Accept json
+// @Produce json
+// @Param id path string true "Material ID"
+// @Param request body object{display_names=map[string]string} true "Display names by test case ID"
+// @Success 200 {object} response.StandardResponse{data=object{updated=int}}
+// @Failure 400 {object} response.StandardResponse
+// @Failure 401 {object} response.StandardResponse
+// @Failure 403 {object} response.StandardResponse
+// @Failure 404 {object} response.StandardResponse
+// @Failure 500 {object} response.StandardResponse
+// @Router /api/course-materials/{id}/test-cases/display-names [put]
+// @Security BearerAuth
+// @Security ApiKeyAuth
+func (h *CourseMaterialHandler) UpdateTestCaseDisplayNames(c *fiber.Ctx) error {
+	materialID := c.Params("id")
+	if materialID == "" {
+		return response.ErrorResponse(c, http.StatusBadRequest, "Material ID is required", nil)
+	}
+
+	var req struct {
+		DisplayNames map[string]string `json:"display_names"`
+	}
+	if err := c.BodyParser(&req); err != nil {
+		return response.ErrorResponse(c, http.StatusBadRequest, "Invalid request body", err.Error())
+	}
+
+	// Get user ID from context
+	userID, err := auth.UserIDFromContext(c)
+	if err != nil {
+		return response.ErrorResponse(c, http.StatusUnauthorized, "Invalid authentication", nil)
+	}
+
+	if err := h.materialService.UpdateTestCaseDisplayNames(materialID, req.DisplayNames, userID); err != nil {
+		if errors.Is(err, services.ErrInvalidDisplayNames) {
+			return response.ErrorResponse(c, http.StatusBadRequest, "Invalid display names", err.Error())
+		}
+		switch err.Error() {
+		case "course material not found":
+			return response.ErrorResponse(c, http.StatusNotFound, "Course material not found", nil)
+		case "test cases can only be updated for code exercises":
+			return response.ErrorResponse(c, http.StatusBadRequest, err.Error(), nil)
+		case "only the creator can update test cases":
+			return response.ErrorResponse(c, http.StatusForbidden, "Access denied", err.Error())
+		}
+		return response.ErrorResponse(c, http.StatusInternalServerError, "Failed to update test case display names", err.Error())
+	}
+
+	return response.SuccessResponse(c, http.StatusOK, "Test case display names updated successfully", fiber.Map{
+		"updated": len(req.DisplayNames),
+	})
+}
+
 // UploadProblemImage godoc
 // @Summary Upload problem image
 // @Description Upload an image for exercise problem statement
@@ -1163,6 +1858,7 @@ func (h *CourseMaterialHandler) DeleteTestCase(c *fiber.Ctx) error {
 // @Success 200 {object} response.StandardResponse{data=object{image_url=string}}
 // @Failure 400 {object} response.StandardResponse
 // @Failure 401 {object} response.StandardResponse
+// @Failure 413 {object} response.StandardResponse
 // @Failure 500 {object} response.StandardResponse
 // @Router /api/course-materials/{id}/images [post]
 // @Security BearerAuth
@@ -1180,9 +1876,8 @@ func (h *CourseMaterialHandler) UploadProblemImage(c *fiber.Ctx) error {
 	}
 
 	// Validate file size
-	const maxFileSize = 5 * 1024 * 1024 // 5MB for images
-	if file.Size > maxFileSize {
-		return response.ErrorResponse(c, http.StatusBadRequest, "Image too large", "Maximum image size is 5MB")
+	if h.maxImageSize > 0 && file.Size > h.maxImageSize {
+		return response.ErrorResponse(c, http.StatusRequestEntityTooLarge, "Image too large", "Maximum image size is "+validation.FormatFileSize(h.maxImageSize))
 	}
 
 	// Validate file type
@@ -1221,6 +1916,18 @@ func (h *CourseMaterialHandler) UploadProblemImage(c *fiber.Ctx) error {
 	})
 }
 
+// validateMaterialAccess checks that the caller can see the material's course,
+// sending the error response when not. It reports whether the request may proceed.
+func (h *CourseMaterialHandler) validateMaterialAccess(c *fiber.Ctx, materialID string) (bool, error) {
+	if err := h.enrollmentValidator.ValidateMaterialAccess(c, materialID); err != nil {
+		if errors.Is(err, authz.ErrMaterialNotFound) {
+			return false, response.ErrorResponse(c, http.StatusNotFound, "Course material not found", nil)
+		}
+		return false, response.ErrorResponse(c, http.StatusForbidden, "Access denied", err.Error())
+	}
+	return true, nil
+}
+
 // Helper method to check if content type is allowed for images
 func (h *CourseMaterialHandler) isAllowedImageType(contentType string) bool {
 	allowedTypes := []string{
@@ -0,0 +1,82 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Project-DSView/backend/go/internal/application/services"
+	models "github.com/Project-DSView/backend/go/internal/domain/entities"
+	"github.com/Project-DSView/backend/go/internal/domain/enums"
+	"github.com/Project-DSView/backend/go/internal/types"
+	"github.com/gofiber/fiber/v2"
+)
+
+// TestPreviewCourseMaterial checks that only the course teacher gets a rendered preview,
+// and that an unknown material is reported as missing rather than rendered
+func TestPreviewCourseMaterial(t *testing.T) {
+	tests := []struct {
+		name       string
+		userID     string
+		materialID string
+		wantStatus int
+	}{
+		{name: "course owner", userID: "teacher-1", materialID: "code-1", wantStatus: fiber.StatusOK},
+		{name: "enrolled student", userID: "student-1", materialID: "code-1", wantStatus: fiber.StatusForbidden},
+		{name: "other teacher", userID: "teacher-2", materialID: "code-1", wantStatus: fiber.StatusForbidden},
+		{name: "unknown material", userID: "teacher-1", materialID: "missing", wantStatus: fiber.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := newTestDB(t, &models.Course{}, &models.Enrollment{}, &models.CourseMaterial{}, &models.CodeExercise{})
+			points := 10
+			rows := []interface{}{
+				&models.Course{CourseID: "course-1", Name: "Data Structures", CreatedBy: "teacher-1"},
+				&models.Enrollment{CourseID: "course-1", UserID: "student-1", Role: enums.EnrollmentRoleStudent},
+				&models.CourseMaterial{MaterialID: "code-1", CourseID: "course-1", Type: enums.MaterialTypeCodeExercise},
+				&models.CodeExercise{MaterialBase: models.MaterialBase{MaterialID: "code-1", CourseID: "course-1", Title: "Sum", CreatedBy: "teacher-1"},
+					TotalPoints: &points, ProblemStatement: "Add **two** numbers<script>alert(1)</script>"},
+			}
+			for _, row := range rows {
+				if err := db.Create(row).Error; err != nil {
+					t.Fatalf("create %T: %v", row, err)
+				}
+			}
+
+			h := NewCourseMaterialHandler(services.NewCourseMaterialService(db, nil), nil, nil)
+			app := fiber.New()
+			app.Use(func(c *fiber.Ctx) error {
+				c.Locals("claims", &types.Claims{UserID: tt.userID})
+				return c.Next()
+			})
+			app.Get("/api/course-materials/:id/preview", h.PreviewCourseMaterial)
+
+			resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/api/course-materials/"+tt.materialID+"/preview", nil))
+			if err != nil {
+				t.Fatalf("request: %v", err)
+			}
+			if resp.StatusCode != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", resp.StatusCode, tt.wantStatus)
+			}
+
+			var body struct {
+				Data map[string]interface{} `json:"data"`
+			}
+			if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+				t.Fatalf("decode response: %v", err)
+			}
+			statement, _ := body.Data["problem_statement_html"].(string)
+			if tt.wantStatus != fiber.StatusOK {
+				if statement != "" {
+					t.Errorf("problem_statement_html = %q, want nothing rendered", statement)
+				}
+				return
+			}
+			if !strings.Contains(statement, "<strong>two</strong>") || strings.Contains(statement, "<script>") {
+				t.Errorf("problem_statement_html = %q, want sanitized markdown", statement)
+			}
+		})
+	}
+}
@@ -0,0 +1,118 @@
+package handler
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/Project-DSView/backend/go/internal/application/services"
+	models "github.com/Project-DSView/backend/go/internal/domain/entities"
+	"github.com/Project-DSView/backend/go/internal/domain/enums"
+	internaltypes "github.com/Project-DSView/backend/go/internal/types"
+)
+
+func TestParseTestCaseChange(t *testing.T) {
+	tests := []struct {
+		name          string
+		entry         map[string]interface{}
+		wantID        string
+		wantInput     string // empty when input_data should stay nil
+		wantOutput    string // empty when expected_output should stay nil
+		wantName      *string
+		wantPublicNil bool
+	}{
+		{
+			name:          "id only leaves every field nil",
+			entry:         map[string]interface{}{"test_case_id": "tc-1"},
+			wantID:        "tc-1",
+			wantPublicNil: true,
+		},
+		{
+			name:          "display name only",
+			entry:         map[string]interface{}{"test_case_id": "tc-1", "display_name": "edge case"},
+			wantID:        "tc-1",
+			wantName:      strPtr("edge case"),
+			wantPublicNil: true,
+		},
+		{
+			name:       "new entry is normalized",
+			entry:      map[string]interface{}{"input_data": "[1, 2]", "expected_output": float64(3), "is_public": false},
+			wantInput:  `[1,2]`,
+			wantOutput: `{"output":3}`,
+		},
+		{
+			name:          "new entry without output keeps it nil",
+			entry:         map[string]interface{}{"input_data": "hello"},
+			wantInput:     `"hello"`,
+			wantPublicNil: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			change := parseTestCaseChange(tt.entry)
+			if change.TestCaseID != tt.wantID {
+				t.Errorf("TestCaseID = %q, want %q", change.TestCaseID, tt.wantID)
+			}
+			if string(change.InputData) != tt.wantInput || (tt.wantInput == "") != (change.InputData == nil) {
+				t.Errorf("InputData = %q, want %q", change.InputData, tt.wantInput)
+			}
+			if string(change.ExpectedOutput) != tt.wantOutput || (tt.wantOutput == "") != (change.ExpectedOutput == nil) {
+				t.Errorf("ExpectedOutput = %q, want %q", change.ExpectedOutput, tt.wantOutput)
+			}
+			switch {
+			case tt.wantName == nil && change.DisplayName != nil:
+				t.Errorf("DisplayName = %q, want nil", *change.DisplayName)
+			case tt.wantName != nil && (change.DisplayName == nil || *change.DisplayName != *tt.wantName):
+				t.Errorf("DisplayName = %v, want %q", change.DisplayName, *tt.wantName)
+			}
+			if (change.IsPublic == nil) != tt.wantPublicNil {
+				t.Errorf("IsPublic = %v, want nil %v", change.IsPublic, tt.wantPublicNil)
+			}
+		})
+	}
+}
+
+// TestTestCaseExamplesHideHiddenOutputs checks that the examples saved after a test case
+// sync, which students see, leave out the expected output of hidden test cases
+func TestTestCaseExamplesHideHiddenOutputs(t *testing.T) {
+	db := newTestDB(t, &models.CourseMaterial{}, &models.CodeExercise{}, &models.TestCase{})
+	exercise := models.CodeExercise{
+		MaterialBase:     models.MaterialBase{CourseID: "course-1", Title: "Sum", CreatedBy: "teacher-1"},
+		TotalPoints:      new(int),
+		ProblemStatement: "Add the numbers",
+	}
+	if err := db.Create(&exercise).Error; err != nil {
+		t.Fatalf("create exercise: %v", err)
+	}
+	refType := string(enums.MaterialTypeCodeExercise)
+	if err := db.Create(&models.CourseMaterial{
+		MaterialID: exercise.MaterialID, CourseID: "course-1", Type: enums.MaterialTypeCodeExercise,
+		ReferenceID: &exercise.MaterialID, ReferenceType: &refType,
+	}).Error; err != nil {
+		t.Fatalf("create material: %v", err)
+	}
+
+	hidden := false
+	synced, err := services.NewCourseMaterialService(db, nil).SyncTestCases(exercise.MaterialID, "teacher-1", []internaltypes.TestCaseChange{
+		{InputData: internaltypes.JSONData(`[1,2]`), ExpectedOutput: internaltypes.JSONData(`{"output":"visible-answer"}`)},
+		{InputData: internaltypes.JSONData(`[3,4]`), ExpectedOutput: internaltypes.JSONData(`{"output":"hidden-answer"}`), IsPublic: &hidden},
+	})
+	if err != nil {
+		t.Fatalf("SyncTestCases() error = %v", err)
+	}
+
+	exercise.ExampleInputs, exercise.ExampleOutputs = testCaseExamples(synced)
+	studentJSON, err := json.Marshal(exercise.ToJSON())
+	if err != nil {
+		t.Fatalf("marshal exercise: %v", err)
+	}
+	if !strings.Contains(string(studentJSON), "visible-answer") {
+		t.Errorf("student JSON lacks the public example: %s", studentJSON)
+	}
+	if strings.Contains(string(studentJSON), "hidden-answer") || strings.Contains(string(studentJSON), "[3,4]") {
+		t.Errorf("student JSON shows the hidden test case: %s", studentJSON)
+	}
+}
+
+func strPtr(s string) *string { return &s }
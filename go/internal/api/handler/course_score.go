@@ -5,6 +5,7 @@ import (
 
 	"github.com/Project-DSView/backend/go/internal/api/types"
 	"github.com/Project-DSView/backend/go/internal/application/services"
+	"github.com/Project-DSView/backend/go/pkg/auth"
 	"github.com/Project-DSView/backend/go/pkg/enrollment"
 	"github.com/Project-DSView/backend/go/pkg/response"
 	"github.com/gofiber/fiber/v2"
@@ -47,7 +48,10 @@ func (h *CourseScoreHandler) GetStudentCourseScore(c *fiber.Ctx) error {
 		return response.ErrorResponse(c, http.StatusForbidden, "Access denied", err.Error())
 	}
 
-	userID := c.Locals("user_id").(string)
+	userID, err := auth.UserIDFromContext(c)
+	if err != nil {
+		return response.ErrorResponse(c, http.StatusUnauthorized, "Invalid authentication", nil)
+	}
 
 	courseScore, err := h.courseScoreService.GetStudentCourseScore(c.Context(), userID, courseID)
 	if err != nil {
@@ -79,9 +83,12 @@ func (h *CourseScoreHandler) UpdateCourseScore(c *fiber.Ctx) error {
 		return response.ErrorResponse(c, http.StatusBadRequest, "Course ID is required", nil)
 	}
 
-	userID := c.Locals("user_id").(string)
+	userID, err := auth.UserIDFromContext(c)
+	if err != nil {
+		return response.ErrorResponse(c, http.StatusUnauthorized, "Invalid authentication", nil)
+	}
 
-	err := h.courseScoreService.UpdateCourseScore(c.Context(), userID, courseID)
+	err = h.courseScoreService.UpdateCourseScore(c.Context(), userID, courseID)
 	if err != nil {
 		return response.ErrorResponse(c, http.StatusInternalServerError, "Failed to update course score", err.Error())
 	}
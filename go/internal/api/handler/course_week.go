@@ -5,6 +5,7 @@ import (
 	"strconv"
 
 	"github.com/Project-DSView/backend/go/internal/application/services"
+	"github.com/Project-DSView/backend/go/pkg/auth"
 	"github.com/Project-DSView/backend/go/pkg/response"
 	"github.com/gofiber/fiber/v2"
 )
@@ -47,8 +48,8 @@ func (h *CourseWeekHandler) CreateCourseWeek(c *fiber.Ctx) error {
 	}
 
 	// Get user ID from context (assuming it's set by auth middleware)
-	userID := c.Locals("user_id")
-	if userID == nil {
+	userID, err := auth.UserIDFromContext(c)
+	if err != nil {
 		return response.ErrorResponse(c, http.StatusUnauthorized, "User not authenticated", nil)
 	}
 
@@ -57,7 +58,7 @@ func (h *CourseWeekHandler) CreateCourseWeek(c *fiber.Ctx) error {
 		req.WeekNumber,
 		req.Title,
 		req.Description,
-		userID.(string),
+		userID,
 	)
 	if err != nil {
 		return response.ErrorResponse(c, http.StatusInternalServerError, "Failed to create course week", err.Error())
@@ -1,10 +1,15 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 
+	"github.com/Project-DSView/backend/go/internal/api/types"
 	"github.com/Project-DSView/backend/go/internal/application/services"
+	internaltypes "github.com/Project-DSView/backend/go/internal/types"
+	"github.com/Project-DSView/backend/go/pkg/auth"
+	"github.com/Project-DSView/backend/go/pkg/config"
 	"github.com/Project-DSView/backend/go/pkg/response"
 	"github.com/gofiber/fiber/v2"
 )
@@ -30,7 +35,10 @@ func NewDeadlineCheckerHandler(deadlineService *services.DeadlineCheckerService)
 // @Router /api/materials/available [get]
 // @Security BearerAuth
 func (h *DeadlineCheckerHandler) GetAvailableMaterials(c *fiber.Ctx) error {
-	userID := c.Locals("user_id").(string)
+	userID, err := auth.UserIDFromContext(c)
+	if err != nil {
+		return response.ErrorResponse(c, http.StatusUnauthorized, "Invalid authentication", nil)
+	}
 	courseID := c.Query("course_id")
 
 	materials, err := h.deadlineService.GetAvailableMaterialsForUser(userID, courseID)
@@ -58,7 +66,10 @@ func (h *DeadlineCheckerHandler) GetAvailableMaterials(c *fiber.Ctx) error {
 // @Router /api/materials/expired [get]
 // @Security BearerAuth
 func (h *DeadlineCheckerHandler) GetExpiredMaterials(c *fiber.Ctx) error {
-	userID := c.Locals("user_id").(string)
+	userID, err := auth.UserIDFromContext(c)
+	if err != nil {
+		return response.ErrorResponse(c, http.StatusUnauthorized, "Invalid authentication", nil)
+	}
 	courseID := c.Query("course_id")
 
 	materials, err := h.deadlineService.GetExpiredMaterialsForUser(userID, courseID)
@@ -120,7 +131,10 @@ func (h *DeadlineCheckerHandler) CanSubmitExercise(c *fiber.Ctx) error {
 		return response.ErrorResponse(c, http.StatusBadRequest, "Exercise ID is required", nil)
 	}
 
-	userID := c.Locals("user_id").(string)
+	userID, err := auth.UserIDFromContext(c)
+	if err != nil {
+		return response.ErrorResponse(c, http.StatusUnauthorized, "Invalid authentication", nil)
+	}
 
 	canSubmit, message, err := h.deadlineService.CanSubmitExercise(userID, exerciseID)
 	if err != nil {
@@ -220,3 +234,63 @@ func (h *DeadlineCheckerHandler) GetDeadlineStats(c *fiber.Ctx) error {
 
 	return response.SuccessResponse(c, http.StatusOK, "Deadline statistics retrieved successfully", stats)
 }
+
+// BulkGrantExtension extends an exercise's deadline for several students
+// @Summary Grant deadline extensions in bulk
+// @Description Move the exercise's deadline for each listed student, e.g. when a lab section had a documented issue. Every student must be enrolled in the course; either all extensions are saved or none. A student who already has an extension gets the new deadline and reason (course creator only)
+// @Tags deadline-checker
+// @Accept json
+// @Produce json
+// @Param id path string true "Material ID"
+// @Param request body types.BulkGrantExtensionRequest true "Students, new deadline and reason"
+// @Success 200 {object} response.StandardResponse{data=object{material_id=string,extensions=[]internaltypes.ExtensionResult}}
+// @Failure 400 {object} response.StandardResponse "Invalid request, too many students, not an exercise or students not enrolled"
+// @Failure 401 {object} response.StandardResponse
+// @Failure 403 {object} response.StandardResponse
+// @Failure 404 {object} response.StandardResponse
+// @Failure 500 {object} response.StandardResponse
+// @Router /api/course-materials/{id}/extensions/bulk [post]
+// @Security BearerAuth
+func (h *DeadlineCheckerHandler) BulkGrantExtension(c *fiber.Ctx) error {
+	claims, ok := c.Locals("claims").(*internaltypes.Claims)
+	if !ok {
+		return response.ErrorResponse(c, http.StatusUnauthorized, "Invalid authentication", nil)
+	}
+
+	materialID := c.Params("id")
+	if materialID == "" {
+		return response.ErrorResponse(c, http.StatusBadRequest, "Material ID is required", nil)
+	}
+
+	var req types.BulkGrantExtensionRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.ErrorResponse(c, http.StatusBadRequest, "Invalid request body", err.Error())
+	}
+	if err := config.Validate.Struct(req); err != nil {
+		return response.ValidationErrorResponse(c, err, config.FieldErrors(err))
+	}
+
+	results, err := h.deadlineService.BulkGrantExtension(materialID, req.UserIDs, req.Deadline, req.Reason, claims.UserID)
+	if err != nil {
+		if errors.Is(err, services.ErrUsersNotEnrolled) {
+			return response.ErrorResponse(c, http.StatusBadRequest, "Some students are not enrolled in the course", err.Error())
+		}
+		if errors.Is(err, services.ErrTooManyExtensions) {
+			return response.ErrorResponse(c, http.StatusBadRequest, err.Error(), nil)
+		}
+		switch err.Error() {
+		case "course material not found":
+			return response.ErrorResponse(c, http.StatusNotFound, "Material not found", nil)
+		case "only the course teacher can grant extensions":
+			return response.ErrorResponse(c, http.StatusForbidden, "Access denied", err.Error())
+		case "a reason is required", "at least one student is required", "the new deadline must be in the future", "only exercises have deadlines":
+			return response.ErrorResponse(c, http.StatusBadRequest, err.Error(), nil)
+		}
+		return response.ErrorResponse(c, http.StatusInternalServerError, "Failed to grant extensions", err.Error())
+	}
+
+	return response.SuccessResponse(c, http.StatusOK, "Extensions granted successfully", map[string]interface{}{
+		"material_id": materialID,
+		"extensions":  results,
+	})
+}
@@ -82,7 +82,7 @@ func (h *DraftHandler) UploadPythonFile(c *fiber.Ctx) error {
 	}
 
 	// ทำความสะอาดชื่อไฟล์
-	sanitizedFilename := validation.SanitizeFilename(file.Filename)
+	sanitizedFilename := h.storageService.SanitizeFilename(file.Filename)
 
 	// Upload to object storage (MinIO)
 	filePath, err := h.storageService.UploadCodeFile(
@@ -120,13 +120,15 @@ func (h *EnrollmentHandler) EnrollInCourse(c *fiber.Ctx) error {
 
 // GetCourseEnrollments godoc
 // @Summary Get course enrollments
-// @Description Get list of users enrolled in a course
+// @Description Get a page of the users enrolled in a course
 // @Tags enrollments
 // @Security BearerAuth
 // @Security ApiKeyAuth
 // @Produce json
 // @Param id path string true "Course ID"
-// @Success 200 {object} object{success=bool,data=object{enrollments=[]object}} "List of enrollments"
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Items per page (max 100)" default(50)
+// @Success 200 {object} object{success=bool,data=object{enrollments=[]object,pagination=object}} "List of enrollments"
 // @Failure 401 {object} map[string]string "Unauthorized"
 // @Failure 403 {object} map[string]string "Forbidden"
 // @Failure 404 {object} map[string]string "Course not found"
@@ -153,8 +155,10 @@ func (h *EnrollmentHandler) GetCourseEnrollments(c *fiber.Ctx) error {
 		return response.SendError(c, fiber.StatusForbidden, "Only teachers can view enrollments")
 	}
 
+	page, limit := response.ParsePagination(c, 50, 0)
+
 	// Get enrollments
-	enrollments, err := h.enrollmentService.GetCourseEnrollments(courseID)
+	enrollments, total, err := h.enrollmentService.GetCourseEnrollmentsPaginated(courseID, page, limit)
 	if err != nil {
 		return response.SendInternalError(c, "Failed to fetch enrollments: "+err.Error())
 	}
@@ -169,6 +173,7 @@ func (h *EnrollmentHandler) GetCourseEnrollments(c *fiber.Ctx) error {
 		"success": true,
 		"data": fiber.Map{
 			"enrollments": enrollmentData,
+			"pagination":  response.Pagination(c, page, limit, total),
 		},
 	})
 }
@@ -244,14 +249,9 @@ func (h *EnrollmentHandler) UpdateEnrollmentRole(c *fiber.Ctx) error {
 	}
 
 	// Get updated enrollment
-	enrollments, err := h.enrollmentService.GetCourseEnrollments(courseID)
-	if err == nil {
-		for _, enrollment := range enrollments {
-			if enrollment.UserID == userID {
-				enrollmentResp := response.ConvertToEnrollmentResponse(&enrollment)
-				return response.SendSuccess(c, "Enrollment role updated successfully", enrollmentResp)
-			}
-		}
+	if enrollment, err := h.enrollmentService.GetEnrollment(courseID, userID); err == nil {
+		enrollmentResp := response.ConvertToEnrollmentResponse(enrollment)
+		return response.SendSuccess(c, "Enrollment role updated successfully", enrollmentResp)
 	}
 
 	return response.SendSuccess(c, "Enrollment role updated successfully", fiber.Map{
@@ -378,3 +378,33 @@ func (h *EnrollmentHandler) GetMyEnrollment(c *fiber.Ctx) error {
 		},
 	})
 }
+
+// GetMyCourses godoc
+// @Summary Get my courses
+// @Description List the courses the current user is enrolled in with their role in each, plus the courses they created. Students also get their completion percentage.
+// @Tags enrollments
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Produce json
+// @Success 200 {object} object{success=bool,data=object{courses=[]types.UserCourse}} "User's courses"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /api/users/me/courses [get]
+func (h *EnrollmentHandler) GetMyCourses(c *fiber.Ctx) error {
+	claims, ok := c.Locals("claims").(*types.Claims)
+	if !ok {
+		return response.SendUnauthorized(c, "Invalid authentication")
+	}
+
+	courses, err := h.enrollmentService.GetUserCourses(claims.UserID)
+	if err != nil {
+		return response.SendInternalError(c, "Failed to fetch courses: "+err.Error())
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data": fiber.Map{
+			"courses": courses,
+		},
+	})
+}
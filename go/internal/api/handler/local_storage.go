@@ -0,0 +1,108 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"net/url"
+
+	"github.com/Project-DSView/backend/go/pkg/response"
+	"github.com/Project-DSView/backend/go/pkg/storage"
+	"github.com/gofiber/fiber/v2"
+)
+
+// LocalStorageHandler serves the files of the local storage backend, standing in for
+// MinIO's object URLs and presigned URLs during development
+type LocalStorageHandler struct {
+	storage *storage.LocalStorageService
+}
+
+func NewLocalStorageHandler(localStorage *storage.LocalStorageService) *LocalStorageHandler {
+	return &LocalStorageHandler{storage: localStorage}
+}
+
+// objectKey returns the object key from the wildcard part of the route
+func (h *LocalStorageHandler) objectKey(c *fiber.Ctx) (string, error) {
+	return url.PathUnescape(c.Params("*"))
+}
+
+// authorizationError checks the request's signature (see LocalStorageService.Authorize)
+// and returns the message to reject it with, or "" when it is allowed
+func (h *LocalStorageHandler) authorizationError(c *fiber.Ctx, method, key string) string {
+	err := h.storage.Authorize(method, key, c.Query("expires"), c.Query("signature"))
+	switch {
+	case err == nil:
+		return ""
+	case errors.Is(err, storage.ErrURLExpired):
+		return "Signed URL has expired"
+	default:
+		return "Invalid or missing signature"
+	}
+}
+
+// GetFile godoc
+// @Summary Download a stored file (local storage backend)
+// @Description Serve a file stored by the local storage backend. Unsigned requests are allowed when STORAGE_LOCAL_PUBLIC_READ is set; otherwise a signed URL is required.
+// @Tags storage
+// @Produce octet-stream
+// @Param key path string true "Object key"
+// @Param expires query int false "Expiry of a signed URL (Unix seconds)"
+// @Param signature query string false "Signature of a signed URL"
+// @Success 200 {file} file "File content"
+// @Failure 403 {object} object{success=bool,error=string} "Invalid, missing or expired signature"
+// @Failure 404 {object} object{success=bool,error=string}
+// @Router /files/{key} [get]
+func (h *LocalStorageHandler) GetFile(c *fiber.Ctx) error {
+	key, err := h.objectKey(c)
+	if err != nil {
+		return response.SendBadRequest(c, "Invalid file path")
+	}
+	if message := h.authorizationError(c, http.MethodGet, key); message != "" {
+		return response.SendError(c, fiber.StatusForbidden, message)
+	}
+
+	file, contentType, size, err := h.storage.Open(key)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return response.SendNotFound(c, "File not found")
+		}
+		return response.SendBadRequest(c, "Invalid file path")
+	}
+
+	c.Set("Content-Type", contentType)
+	c.Set("Content-Length", fmt.Sprintf("%d", size))
+	// fasthttp closes the file once the response has been sent
+	return c.Status(fiber.StatusOK).SendStream(file, int(size))
+}
+
+// PutFile godoc
+// @Summary Upload a file to a presigned URL (local storage backend)
+// @Description Store the request body at the key of a URL from GeneratePresignedUploadURL
+// @Tags storage
+// @Accept octet-stream
+// @Produce json
+// @Param key path string true "Object key"
+// @Param expires query int true "Expiry of the signed URL (Unix seconds)"
+// @Param signature query string true "Signature of the signed URL"
+// @Success 200 {object} object{success=bool,message=string,data=object{url=string}}
+// @Failure 403 {object} object{success=bool,error=string} "Invalid, missing or expired signature"
+// @Router /files/{key} [put]
+func (h *LocalStorageHandler) PutFile(c *fiber.Ctx) error {
+	key, err := h.objectKey(c)
+	if err != nil {
+		return response.SendBadRequest(c, "Invalid file path")
+	}
+	if message := h.authorizationError(c, http.MethodPut, key); message != "" {
+		return response.SendError(c, fiber.StatusForbidden, message)
+	}
+
+	fileURL, err := h.storage.UploadFile(context.Background(), key, bytes.NewReader(c.Body()), c.Get("Content-Type"))
+	if err != nil {
+		return response.SendInternalError(c, "Failed to store file: "+err.Error())
+	}
+
+	return response.SendSuccess(c, "File stored successfully", fiber.Map{"url": fileURL})
+}
@@ -0,0 +1,68 @@
+package handler
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/Project-DSView/backend/go/internal/application/services"
+	"github.com/gofiber/fiber/v2"
+)
+
+// prometheusContentType is the content type of the Prometheus text exposition format
+const prometheusContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// MetricsHandler exposes operational gauges in the Prometheus text format
+type MetricsHandler struct {
+	queueService      *services.QueueService
+	submissionService *services.SubmissionService
+}
+
+func NewMetricsHandler(queueService *services.QueueService, submissionService *services.SubmissionService) *MetricsHandler {
+	return &MetricsHandler{queueService: queueService, submissionService: submissionService}
+}
+
+// GetMetrics godoc
+// @Summary Prometheus metrics
+// @Description Gauges in the Prometheus text format: dsview_queue_stuck_jobs (jobs processing for longer than the threshold, by type), dsview_queue_stuck_job_threshold_seconds, and dsview_executor_containers_running/waiting/limit (container usage on this host)
+// @Tags system
+// @Produce plain
+// @Success 200 {string} string "Metrics"
+// @Failure 500 {string} string "Metrics could not be collected"
+// @Router /metrics [get]
+func (h *MetricsHandler) GetMetrics(c *fiber.Ctx) error {
+	stuckCounts, err := h.queueService.CountStuckJobs()
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).SendString("failed to collect metrics: " + err.Error())
+	}
+
+	queueTypes := make([]string, 0, len(stuckCounts))
+	for queueType := range stuckCounts {
+		queueTypes = append(queueTypes, queueType)
+	}
+	sort.Strings(queueTypes)
+
+	var b strings.Builder
+	b.WriteString("# HELP dsview_queue_stuck_jobs Queue jobs in processing for longer than the stuck job threshold.\n")
+	b.WriteString("# TYPE dsview_queue_stuck_jobs gauge\n")
+	for _, queueType := range queueTypes {
+		fmt.Fprintf(&b, "dsview_queue_stuck_jobs{type=%q} %d\n", queueType, stuckCounts[queueType])
+	}
+	b.WriteString("# HELP dsview_queue_stuck_job_threshold_seconds Processing time after which a queue job counts as stuck.\n")
+	b.WriteString("# TYPE dsview_queue_stuck_job_threshold_seconds gauge\n")
+	fmt.Fprintf(&b, "dsview_queue_stuck_job_threshold_seconds %g\n", h.queueService.StuckJobThreshold().Seconds())
+
+	usage := h.submissionService.ContainerUsage()
+	b.WriteString("# HELP dsview_executor_containers_running Executor containers currently running on this host.\n")
+	b.WriteString("# TYPE dsview_executor_containers_running gauge\n")
+	fmt.Fprintf(&b, "dsview_executor_containers_running %d\n", usage.Running)
+	b.WriteString("# HELP dsview_executor_containers_waiting Executor runs waiting for a free container slot.\n")
+	b.WriteString("# TYPE dsview_executor_containers_waiting gauge\n")
+	fmt.Fprintf(&b, "dsview_executor_containers_waiting %d\n", usage.Waiting)
+	b.WriteString("# HELP dsview_executor_containers_limit Maximum executor containers running at once on this host.\n")
+	b.WriteString("# TYPE dsview_executor_containers_limit gauge\n")
+	fmt.Fprintf(&b, "dsview_executor_containers_limit %d\n", usage.Limit)
+
+	c.Set(fiber.HeaderContentType, prometheusContentType)
+	return c.SendString(b.String())
+}
@@ -1,28 +1,41 @@
 package handler
 
 import (
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
-	"strings"
 
+	"github.com/Project-DSView/backend/go/internal/api/middleware/security"
 	"github.com/Project-DSView/backend/go/internal/api/types"
 	"github.com/Project-DSView/backend/go/internal/application/services"
+	"github.com/Project-DSView/backend/go/internal/domain/enums"
+	"github.com/Project-DSView/backend/go/pkg/auth"
 	"github.com/Project-DSView/backend/go/pkg/config"
 	"github.com/Project-DSView/backend/go/pkg/response"
+	"github.com/Project-DSView/backend/go/pkg/storage"
+	"github.com/Project-DSView/backend/go/pkg/validation"
 	"github.com/gofiber/fiber/v2"
 )
 
 type PDFExerciseHandler struct {
-	pdfSubmissionService *services.PDFExerciseSubmissionService
+	pdfSubmissionService    *services.PDFExerciseSubmissionService
+	streamingAllowedOrigins []string
+	maxPDFSize              int64 // bytes; 0 = unlimited
 }
 
-func NewPDFExerciseHandler(pdfSubmissionService *services.PDFExerciseSubmissionService) *PDFExerciseHandler {
+func NewPDFExerciseHandler(pdfSubmissionService *services.PDFExerciseSubmissionService, streamingAllowedOrigins []string) *PDFExerciseHandler {
 	return &PDFExerciseHandler{
-		pdfSubmissionService: pdfSubmissionService,
+		pdfSubmissionService:    pdfSubmissionService,
+		streamingAllowedOrigins: streamingAllowedOrigins,
 	}
 }
 
+// SetMaxPDFSize sets the maximum size of submitted PDFs and feedback files
+func (h *PDFExerciseHandler) SetMaxPDFSize(maxPDFSize int64) {
+	h.maxPDFSize = maxPDFSize
+}
+
 // SubmitPDFExercise godoc
 // @Summary Submit PDF exercise
 // @Description Submit a PDF file for an exercise
@@ -34,6 +47,9 @@ func NewPDFExerciseHandler(pdfSubmissionService *services.PDFExerciseSubmissionS
 // @Success 201 {object} response.StandardResponse{data=models.Submission}
 // @Failure 400 {object} response.StandardResponse
 // @Failure 401 {object} response.StandardResponse
+// @Failure 403 {object} response.StandardResponse
+// @Failure 409 {object} response.StandardResponse
+// @Failure 413 {object} response.StandardResponse
 // @Failure 500 {object} response.StandardResponse
 // @Router /api/materials/{material_id}/submit [post]
 // @Security BearerAuth
@@ -43,8 +59,22 @@ func (h *PDFExerciseHandler) SubmitPDFExercise(c *fiber.Ctx) error {
 		return response.ErrorResponse(c, http.StatusBadRequest, "Material ID is required", nil)
 	}
 
+	// Reject submissions to other material types before reading the file
+	if err := h.pdfSubmissionService.CheckSubmissionType(materialID, enums.MaterialTypePDFExercise); err != nil {
+		switch {
+		case errors.Is(err, services.ErrMaterialNotFound):
+			return response.ErrorResponse(c, http.StatusNotFound, "Material not found", nil)
+		case errors.Is(err, services.ErrNotPDFExercise):
+			return response.ErrorResponse(c, http.StatusBadRequest, err.Error(), nil)
+		}
+		return response.ErrorResponse(c, http.StatusInternalServerError, "Failed to get material", err.Error())
+	}
+
 	// Get user ID from context
-	userID := c.Locals("user_id").(string)
+	userID, err := auth.UserIDFromContext(c)
+	if err != nil {
+		return response.ErrorResponse(c, http.StatusUnauthorized, "Invalid authentication", nil)
+	}
 
 	// Get uploaded file
 	file, err := c.FormFile("file")
@@ -57,10 +87,9 @@ func (h *PDFExerciseHandler) SubmitPDFExercise(c *fiber.Ctx) error {
 		return response.ErrorResponse(c, http.StatusBadRequest, "Only PDF files are allowed", nil)
 	}
 
-	// Validate file size (max 10MB)
-	const maxFileSize = 10 * 1024 * 1024
-	if file.Size > maxFileSize {
-		return response.ErrorResponse(c, http.StatusBadRequest, "File size too large. Maximum size is 10MB", nil)
+	// Validate file size
+	if h.maxPDFSize > 0 && file.Size > h.maxPDFSize {
+		return response.ErrorResponse(c, http.StatusRequestEntityTooLarge, "File size too large. Maximum size is "+validation.FormatFileSize(h.maxPDFSize), nil)
 	}
 
 	// Open file for reading
@@ -80,6 +109,21 @@ func (h *PDFExerciseHandler) SubmitPDFExercise(c *fiber.Ctx) error {
 		file.Header.Get("Content-Type"),
 	)
 	if err != nil {
+		if errors.Is(err, services.ErrPDFTooManyPages) {
+			return response.ErrorResponse(c, http.StatusRequestEntityTooLarge, "PDF has too many pages", err.Error())
+		}
+		if errors.Is(err, services.ErrSubmissionNotAllowed) {
+			return response.ErrorResponse(c, http.StatusForbidden, "Submission not allowed", err.Error())
+		}
+		if errors.Is(err, services.ErrResubmissionLocked) {
+			return response.ErrorResponse(c, http.StatusConflict, "Resubmission not allowed", err.Error())
+		}
+		if errors.Is(err, services.ErrAttemptLimitReached) {
+			return response.ErrorResponse(c, http.StatusConflict, "Attempt limit reached", err.Error())
+		}
+		if errors.Is(err, services.ErrPrerequisitesNotMet) {
+			return response.ErrorResponse(c, http.StatusForbidden, "Prerequisites not completed", err.Error())
+		}
 		return response.ErrorResponse(c, http.StatusInternalServerError, "Failed to submit PDF exercise", err.Error())
 	}
 
@@ -88,18 +132,19 @@ func (h *PDFExerciseHandler) SubmitPDFExercise(c *fiber.Ctx) error {
 
 // ApprovePDFSubmission godoc
 // @Summary Approve PDF submission
-// @Description Approve a PDF submission and assign score (Teachers/TAs only). Supports optional feedback file upload.
+// @Description Approve a PDF submission and assign score (Teachers/TAs only). The score is checked against the exercise's total points. Supports optional feedback file upload.
 // @Tags pdf-exercises
 // @Accept multipart/form-data
 // @Produce json
 // @Param submission_id path string true "Submission ID"
-// @Param score formData int true "Score (0-100)"
+// @Param score formData int true "Score (0 to the exercise's total points)"
 // @Param comment formData string true "Comment/Feedback"
 // @Param feedback_file formData file false "Optional feedback PDF file"
 // @Success 200 {object} response.StandardResponse
 // @Failure 400 {object} response.StandardResponse
 // @Failure 401 {object} response.StandardResponse
 // @Failure 403 {object} response.StandardResponse
+// @Failure 413 {object} response.StandardResponse
 // @Failure 500 {object} response.StandardResponse
 // @Router /api/submissions/{submission_id}/approve [post]
 // @Security BearerAuth
@@ -111,7 +156,6 @@ func (h *PDFExerciseHandler) ApprovePDFSubmission(c *fiber.Ctx) error {
 
 	// Parse form data
 	scoreStr := c.FormValue("score")
-	maxScoreStr := c.FormValue("maxScore")
 	comment := c.FormValue("comment")
 
 	if scoreStr == "" {
@@ -127,24 +171,10 @@ func (h *PDFExerciseHandler) ApprovePDFSubmission(c *fiber.Ctx) error {
 		return response.ErrorResponse(c, http.StatusBadRequest, "Invalid score format", nil)
 	}
 
-	// Parse maxScore (optional, defaults to 100 if not provided)
-	var maxScore int = 100
-	if maxScoreStr != "" {
-		if _, err := fmt.Sscanf(maxScoreStr, "%d", &maxScore); err != nil {
-			return response.ErrorResponse(c, http.StatusBadRequest, "Invalid maxScore format", nil)
-		}
-		if maxScore <= 0 {
-			return response.ErrorResponse(c, http.StatusBadRequest, "MaxScore must be greater than 0", nil)
-		}
-	}
-
-	// Validate score range
+	// The upper bound is the exercise's total points, checked by the service
 	if score < 0 {
 		return response.ErrorResponse(c, http.StatusBadRequest, "Score must be greater than or equal to 0", nil)
 	}
-	if score > maxScore {
-		return response.ErrorResponse(c, http.StatusBadRequest, fmt.Sprintf("Score must be between 0 and %d", maxScore), nil)
-	}
 
 	// Get optional feedback file
 	var feedbackFileReader io.Reader
@@ -159,10 +189,9 @@ func (h *PDFExerciseHandler) ApprovePDFSubmission(c *fiber.Ctx) error {
 			return response.ErrorResponse(c, http.StatusBadRequest, "Feedback file must be a PDF", nil)
 		}
 
-		// Validate file size (max 10MB)
-		const maxFileSize = 10 * 1024 * 1024
-		if file.Size > maxFileSize {
-			return response.ErrorResponse(c, http.StatusBadRequest, "Feedback file size too large. Maximum size is 10MB", nil)
+		// Validate file size
+		if h.maxPDFSize > 0 && file.Size > h.maxPDFSize {
+			return response.ErrorResponse(c, http.StatusRequestEntityTooLarge, "Feedback file size too large. Maximum size is "+validation.FormatFileSize(h.maxPDFSize), nil)
 		}
 
 		feedbackFileReader, err = file.Open()
@@ -176,7 +205,10 @@ func (h *PDFExerciseHandler) ApprovePDFSubmission(c *fiber.Ctx) error {
 	}
 
 	// Get user ID from context
-	userID := c.Locals("user_id").(string)
+	userID, err := auth.UserIDFromContext(c)
+	if err != nil {
+		return response.ErrorResponse(c, http.StatusUnauthorized, "Invalid authentication", nil)
+	}
 
 	// Approve submission
 	if err := h.pdfSubmissionService.ApprovePDFSubmissionWithFile(
@@ -189,6 +221,9 @@ func (h *PDFExerciseHandler) ApprovePDFSubmission(c *fiber.Ctx) error {
 		feedbackFileSize,
 		feedbackFileMimeType,
 	); err != nil {
+		if errors.Is(err, services.ErrScoreOutOfRange) {
+			return response.ErrorResponse(c, http.StatusBadRequest, "Invalid score", err.Error())
+		}
 		return response.ErrorResponse(c, http.StatusInternalServerError, "Failed to approve submission", err.Error())
 	}
 
@@ -207,6 +242,7 @@ func (h *PDFExerciseHandler) ApprovePDFSubmission(c *fiber.Ctx) error {
 // @Failure 400 {object} response.StandardResponse
 // @Failure 401 {object} response.StandardResponse
 // @Failure 403 {object} response.StandardResponse
+// @Failure 413 {object} response.StandardResponse
 // @Failure 500 {object} response.StandardResponse
 // @Router /api/submissions/{submission_id}/reject [post]
 // @Security BearerAuth
@@ -223,11 +259,14 @@ func (h *PDFExerciseHandler) RejectPDFSubmission(c *fiber.Ctx) error {
 
 	// Validate request
 	if err := config.Validate.Struct(req); err != nil {
-		return response.ErrorResponse(c, http.StatusBadRequest, "Validation failed", err.Error())
+		return response.ValidationErrorResponse(c, err, config.FieldErrors(err))
 	}
 
 	// Get user ID from context
-	userID := c.Locals("user_id").(string)
+	userID, err := auth.UserIDFromContext(c)
+	if err != nil {
+		return response.ErrorResponse(c, http.StatusUnauthorized, "Invalid authentication", nil)
+	}
 
 	// Reject submission
 	if err := h.pdfSubmissionService.RejectPDFSubmission(submissionID, userID, req.Comment); err != nil {
@@ -302,6 +341,7 @@ func (h *PDFExerciseHandler) GetPDFSubmission(c *fiber.Ctx) error {
 // @Tags pdf-exercises
 // @Produce application/pdf
 // @Param submission_id path string true "Submission ID"
+// @Param inline query bool false "Display the PDF in the browser (Content-Disposition: inline) instead of downloading it"
 // @Success 200 {file} file "PDF file"
 // @Failure 400 {object} response.StandardResponse
 // @Failure 401 {object} response.StandardResponse
@@ -329,36 +369,12 @@ func (h *PDFExerciseHandler) DownloadPDFSubmission(c *fiber.Ctx) error {
 		}
 	}()
 
-	// Get origin from request for CORS
-	origin := c.Get("Origin")
-	if origin == "" {
-		// Try to extract from Referer header
-		referer := c.Get("Referer")
-		if referer != "" {
-			// Extract origin from referer URL (e.g., "https://localhost:3000" from "https://localhost:3000/page")
-			if strings.HasPrefix(referer, "https://localhost:3000") {
-				origin = "https://localhost:3000"
-			} else if strings.HasPrefix(referer, "http://localhost:3000") {
-				origin = "http://localhost:3000"
-			}
-		}
-	}
-
 	// Set CORS headers explicitly (must be set before streaming)
-	// Note: Cannot use "*" with Allow-Credentials, so we must use specific origin
-	if origin != "" {
-		c.Set("Access-Control-Allow-Origin", origin)
-		c.Set("Access-Control-Allow-Credentials", "true")
-	} else {
-		// If no origin, allow all (but cannot use credentials)
-		c.Set("Access-Control-Allow-Origin", "*")
-	}
-	c.Set("Access-Control-Allow-Methods", "GET, OPTIONS")
-	c.Set("Access-Control-Allow-Headers", "Origin, Content-Type, Accept, Authorization, Cookie, X-Requested-With, X-CSRF-Token, dsview-api-key")
-
-	// Set response headers for file download
+	security.SetStreamingCORSHeaders(c, h.streamingAllowedOrigins)
+
+	// Set response headers for file download (or inline viewing with ?inline=true)
 	c.Set("Content-Type", contentType)
-	c.Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+	c.Set("Content-Disposition", pdfContentDisposition(c, filename))
 	c.Set("Content-Length", fmt.Sprintf("%d", size))
 
 	// Stream the file using io.Copy for better CORS compatibility
@@ -391,7 +407,10 @@ func (h *PDFExerciseHandler) CancelPDFSubmission(c *fiber.Ctx) error {
 	}
 
 	// Get user ID from context
-	userID := c.Locals("user_id").(string)
+	userID, err := auth.UserIDFromContext(c)
+	if err != nil {
+		return response.ErrorResponse(c, http.StatusUnauthorized, "Invalid authentication", nil)
+	}
 
 	// Cancel submission
 	if err := h.pdfSubmissionService.CancelPDFSubmission(submissionID, userID); err != nil {
@@ -426,7 +445,10 @@ func (h *PDFExerciseHandler) GetMyPDFSubmission(c *fiber.Ctx) error {
 	}
 
 	// Get user ID from context
-	userID := c.Locals("user_id").(string)
+	userID, err := auth.UserIDFromContext(c)
+	if err != nil {
+		return response.ErrorResponse(c, http.StatusUnauthorized, "Invalid authentication", nil)
+	}
 
 	// Get user's submission
 	submission, err := h.pdfSubmissionService.GetUserSubmissionForMaterial(userID, materialID)
@@ -475,6 +497,7 @@ func (h *PDFExerciseHandler) GetCoursePDFSubmissions(c *fiber.Ctx) error {
 // @Tags pdf-exercises
 // @Produce application/pdf
 // @Param submission_id path string true "Submission ID"
+// @Param inline query bool false "Display the PDF in the browser (Content-Disposition: inline) instead of downloading it"
 // @Success 200 {file} file "PDF file"
 // @Failure 400 {object} response.StandardResponse
 // @Failure 401 {object} response.StandardResponse
@@ -490,7 +513,10 @@ func (h *PDFExerciseHandler) DownloadFeedbackFile(c *fiber.Ctx) error {
 	}
 
 	// Get user ID from context
-	userID := c.Locals("user_id").(string)
+	userID, err := auth.UserIDFromContext(c)
+	if err != nil {
+		return response.ErrorResponse(c, http.StatusUnauthorized, "Invalid authentication", nil)
+	}
 
 	// Stream feedback file directly from MinIO
 	reader, contentType, filename, size, err := h.pdfSubmissionService.StreamFeedbackFile(submissionID, userID)
@@ -509,33 +535,12 @@ func (h *PDFExerciseHandler) DownloadFeedbackFile(c *fiber.Ctx) error {
 		}
 	}()
 
-	// Get origin from request for CORS
-	origin := c.Get("Origin")
-	if origin == "" {
-		// Try to extract from Referer header
-		referer := c.Get("Referer")
-		if referer != "" {
-			if strings.HasPrefix(referer, "https://localhost:3000") {
-				origin = "https://localhost:3000"
-			} else if strings.HasPrefix(referer, "http://localhost:3000") {
-				origin = "http://localhost:3000"
-			}
-		}
-	}
-
 	// Set CORS headers explicitly
-	if origin != "" {
-		c.Set("Access-Control-Allow-Origin", origin)
-		c.Set("Access-Control-Allow-Credentials", "true")
-	} else {
-		c.Set("Access-Control-Allow-Origin", "*")
-	}
-	c.Set("Access-Control-Allow-Methods", "GET, OPTIONS")
-	c.Set("Access-Control-Allow-Headers", "Origin, Content-Type, Accept, Authorization, Cookie, X-Requested-With, X-CSRF-Token, dsview-api-key")
+	security.SetStreamingCORSHeaders(c, h.streamingAllowedOrigins)
 
-	// Set response headers for file download
+	// Set response headers for file download (or inline viewing with ?inline=true)
 	c.Set("Content-Type", contentType)
-	c.Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+	c.Set("Content-Disposition", pdfContentDisposition(c, filename))
 	c.Set("Content-Length", fmt.Sprintf("%d", size))
 
 	// Stream the file
@@ -546,3 +551,13 @@ func (h *PDFExerciseHandler) DownloadFeedbackFile(c *fiber.Ctx) error {
 	}
 	return nil
 }
+
+// pdfContentDisposition returns the Content-Disposition for a streamed PDF: inline when
+// the request has ?inline=true so the browser renders it in a tab, attachment otherwise
+func pdfContentDisposition(c *fiber.Ctx, filename string) string {
+	disposition := "attachment"
+	if c.QueryBool("inline") {
+		disposition = "inline"
+	}
+	return storage.ContentDisposition(disposition, filename)
+}
@@ -1,11 +1,15 @@
 package handler
 
 import (
+	apitypes "github.com/Project-DSView/backend/go/internal/api/types"
 	"github.com/Project-DSView/backend/go/internal/application/services"
 	"github.com/Project-DSView/backend/go/internal/domain/enums"
 	"github.com/Project-DSView/backend/go/internal/types"
+	"github.com/Project-DSView/backend/go/pkg/authz"
+	"github.com/Project-DSView/backend/go/pkg/config"
 	"github.com/Project-DSView/backend/go/pkg/response"
 	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
 )
 
 type ProgressHandler struct {
@@ -13,6 +17,7 @@ type ProgressHandler struct {
 	userService       *services.UserService
 	enrollmentService *services.EnrollmentService
 	courseService     *services.CourseService
+	db                *gorm.DB
 }
 
 func NewProgressHandler(
@@ -20,12 +25,14 @@ func NewProgressHandler(
 	userSvc *services.UserService,
 	enrollmentSvc *services.EnrollmentService,
 	courseSvc *services.CourseService,
+	db *gorm.DB,
 ) *ProgressHandler {
 	return &ProgressHandler{
 		progressService:   progressSvc,
 		userService:       userSvc,
 		enrollmentService: enrollmentSvc,
 		courseService:     courseSvc,
+		db:                db,
 	}
 }
 
@@ -55,6 +62,42 @@ func (h *ProgressHandler) GetSelfProgress(c *fiber.Ctx) error {
 	})
 }
 
+// GetProgressBatch godoc
+// @Summary Get own progress on several materials
+// @Description Get the caller's progress on up to 100 materials in one call, e.g. to show a status on each exercise of a week. The result is keyed by material ID; materials the caller has not started are absent
+// @Tags progress
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body apitypes.BatchGetProgressRequest true "Material IDs"
+// @Success 200 {object} object{success=bool,message=string,data=object{progress=map[string]object}}
+// @Failure 400 {object} object{success=bool,error=string} "Bad request"
+// @Failure 401 {object} object{success=bool,error=string} "Unauthorized"
+// @Failure 500 {object} object{success=bool,error=string} "Internal server error"
+// @Router /api/students/progress/batch [post]
+func (h *ProgressHandler) GetProgressBatch(c *fiber.Ctx) error {
+	claims, ok := c.Locals("claims").(*types.Claims)
+	if !ok {
+		return response.SendUnauthorized(c, "Invalid authentication")
+	}
+
+	var req apitypes.BatchGetProgressRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.SendBadRequest(c, "Invalid request body")
+	}
+	if err := config.Validate.Struct(req); err != nil {
+		return response.ValidationErrorResponse(c, err, config.FieldErrors(err))
+	}
+
+	progress, err := h.progressService.GetProgressForMaterials(claims.UserID, req.MaterialIDs)
+	if err != nil {
+		return response.SendInternalError(c, "Failed to fetch progress: "+err.Error())
+	}
+	return response.SendSuccess(c, "Progress retrieved successfully", fiber.Map{
+		"progress": progress,
+	})
+}
+
 // GetCourseProgress godoc
 // @Summary Get course progress (Teachers/TAs only)
 // @Description ดูความก้าวหน้าของนักเรียนในรายวิชา
@@ -84,7 +127,7 @@ func (h *ProgressHandler) GetCourseProgress(c *fiber.Ctx) error {
 	}
 
 	// Check permissions: must be teacher or TA in this course
-	canView, err := h.canViewCourseProgress(claims.UserID, courseID, curUser.IsTeacher)
+	canView, err := h.canViewCourseProgress(claims.UserID, courseID)
 	if err != nil {
 		return response.SendInternalError(c, "Failed to check permissions: "+err.Error())
 	}
@@ -101,6 +144,72 @@ func (h *ProgressHandler) GetCourseProgress(c *fiber.Ctx) error {
 	})
 }
 
+// GetNonSubmitters godoc
+// @Summary List students who have not submitted (Teachers/TAs only)
+// @Description List users enrolled in the material's course with no submission for it, with contact details. role filters by enrollment role (default student, "all" for every role); incomplete=true also lists those who submitted but have not completed it
+// @Tags progress
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Material ID"
+// @Param role query string false "Enrollment role" Enums(student,ta,all)
+// @Param incomplete query bool false "Also list users who submitted but have not completed the material"
+// @Success 200 {object} object{success=bool,message=string,data=object{non_submitters=[]object{user_id=string,firstname=string,lastname=string,email=string,role=string,has_submitted=bool,progress_status=string},count=int}}
+// @Failure 400 {object} object{success=bool,error=string} "Bad request"
+// @Failure 401 {object} object{success=bool,error=string} "Unauthorized"
+// @Failure 403 {object} object{success=bool,error=string} "Forbidden"
+// @Failure 404 {object} object{success=bool,error=string} "Material not found"
+// @Failure 500 {object} object{success=bool,error=string} "Internal server error"
+// @Router /api/course-materials/{id}/non-submitters [get]
+func (h *ProgressHandler) GetNonSubmitters(c *fiber.Ctx) error {
+	claims, ok := c.Locals("claims").(*types.Claims)
+	if !ok {
+		return response.SendUnauthorized(c, "Invalid authentication")
+	}
+
+	materialID := c.Params("id")
+	if materialID == "" {
+		return response.SendBadRequest(c, "Material ID is required")
+	}
+
+	role := enums.EnrollmentRoleStudent
+	switch c.Query("role") {
+	case "", string(enums.EnrollmentRoleStudent):
+	case string(enums.EnrollmentRoleTA):
+		role = enums.EnrollmentRoleTA
+	case "all":
+		role = ""
+	default:
+		return response.SendBadRequest(c, "role must be student, ta or all")
+	}
+
+	courseID, err := authz.MaterialCourseID(h.db, materialID)
+	if err == authz.ErrMaterialNotFound {
+		return response.SendNotFound(c, "Material not found")
+	}
+	if err != nil {
+		return response.SendInternalError(c, "Failed to get material: "+err.Error())
+	}
+	canView, err := h.canViewCourseProgress(claims.UserID, courseID)
+	if err != nil {
+		return response.SendInternalError(c, "Failed to check permissions: "+err.Error())
+	}
+	if !canView {
+		return response.SendError(c, fiber.StatusForbidden, "Only teachers or TAs can view who has not submitted")
+	}
+
+	nonSubmitters, err := h.progressService.GetNonSubmitters(materialID, role, c.QueryBool("incomplete"))
+	if err != nil {
+		if err.Error() == "course material not found" {
+			return response.SendNotFound(c, "Material not found")
+		}
+		return response.SendInternalError(c, "Failed to fetch non-submitters: "+err.Error())
+	}
+	return response.SendSuccess(c, "Non-submitters retrieved successfully", fiber.Map{
+		"non_submitters": nonSubmitters,
+		"count":          len(nonSubmitters),
+	})
+}
+
 // VerifyProgress godoc
 // @Summary Verify progress (Teachers/TAs only)
 // @Description ตรวจสอบและอนุมัติ/ปฏิเสธความก้าวหน้า หาก approved จะเปลี่ยนสถานะ progress เป็น completed
@@ -135,7 +244,7 @@ func (h *ProgressHandler) VerifyProgress(c *fiber.Ctx) error {
 	}
 
 	// Check permissions: must be teacher or TA in the course related to this progress
-	canVerify, err := h.canVerifyProgress(claims.UserID, progressID, curUser.IsTeacher)
+	canVerify, err := h.canVerifyProgress(claims.UserID, progressID)
 	if err != nil {
 		return response.SendInternalError(c, "Failed to check permissions: "+err.Error())
 	}
@@ -208,7 +317,7 @@ func (h *ProgressHandler) GetVerificationLogs(c *fiber.Ctx) error {
 	}
 
 	// Check permissions: must be teacher or TA in the course related to this progress
-	canView, err := h.canVerifyProgress(claims.UserID, progressID, curUser.IsTeacher)
+	canView, err := h.canVerifyProgress(claims.UserID, progressID)
 	if err != nil {
 		return response.SendInternalError(c, "Failed to check permissions: "+err.Error())
 	}
@@ -226,6 +335,63 @@ func (h *ProgressHandler) GetVerificationLogs(c *fiber.Ctx) error {
 	})
 }
 
+// GetVerificationHistory godoc
+// @Summary Get a student's review history for a material
+// @Description Every approval, rejection and review request on the student's progress for the material, oldest first, with the reviewer and comment (teachers/TAs of the course, or the student for their own history)
+// @Tags progress
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Material ID"
+// @Param user_id path string true "Student user ID"
+// @Success 200 {object} object{success=bool,message=string,data=object{material_id=string,user_id=string,history=[]object{log_id=string,status=string,comment=string,verified_at=string,reviewer_id=string,reviewer_firstname=string,reviewer_lastname=string}}}
+// @Failure 400 {object} object{success=bool,error=string} "Bad request"
+// @Failure 401 {object} object{success=bool,error=string} "Unauthorized"
+// @Failure 403 {object} object{success=bool,error=string} "Forbidden"
+// @Failure 404 {object} object{success=bool,error=string} "Material not found"
+// @Failure 500 {object} object{success=bool,error=string} "Internal server error"
+// @Router /api/course-materials/{id}/progress/{user_id}/history [get]
+func (h *ProgressHandler) GetVerificationHistory(c *fiber.Ctx) error {
+	claims, ok := c.Locals("claims").(*types.Claims)
+	if !ok {
+		return response.SendUnauthorized(c, "Invalid authentication")
+	}
+
+	materialID := c.Params("id")
+	userID := c.Params("user_id")
+	if materialID == "" || userID == "" {
+		return response.SendBadRequest(c, "Material ID and user ID are required")
+	}
+
+	courseID, err := authz.MaterialCourseID(h.db, materialID)
+	if err == authz.ErrMaterialNotFound {
+		return response.SendNotFound(c, "Material not found")
+	}
+	if err != nil {
+		return response.SendInternalError(c, "Failed to get material: "+err.Error())
+	}
+	if userID != claims.UserID {
+		canView, err := h.canViewCourseProgress(claims.UserID, courseID)
+		if err != nil {
+			return response.SendInternalError(c, "Failed to check permissions: "+err.Error())
+		}
+		if !canView {
+			return response.SendError(c, fiber.StatusForbidden, "Only teachers or TAs can view another student's review history")
+		}
+	}
+
+	history, err := h.progressService.GetVerificationHistory(userID, materialID)
+	if err != nil {
+		if err.Error() == "course material not found" {
+			return response.SendNotFound(c, "Material not found")
+		}
+		return response.SendInternalError(c, "Failed to get review history: "+err.Error())
+	}
+	return response.SendSuccess(c, "Review history retrieved successfully", fiber.Map{
+		"material_id": materialID,
+		"user_id":     userID,
+		"history":     history,
+	})
+}
 
 // RequestApproval godoc
 // @Summary Request approval for material completion
@@ -293,32 +459,16 @@ func (h *ProgressHandler) RequestApproval(c *fiber.Ctx) error {
 // Helper methods for permission checking
 
 // canViewCourseProgress checks if user can view course progress
-func (h *ProgressHandler) canViewCourseProgress(userID, courseID string, isTeacher bool) (bool, error) {
-	// Teachers can view any course progress
-	if isTeacher {
-		return true, nil
-	}
-
-	// Check if user is enrolled as TA in this course
-	enrollment, err := h.enrollmentService.GetUserEnrollmentInCourse(courseID, userID)
-	if err != nil {
-		return false, err
-	}
-	if enrollment == nil {
-		return false, nil // Not enrolled
+func (h *ProgressHandler) canViewCourseProgress(userID, courseID string) (bool, error) {
+	canReview, err := authz.CanReviewInCourse(h.db, userID, courseID)
+	if err == authz.ErrCourseNotFound {
+		return false, nil
 	}
-
-	// TAs can view course progress
-	return enrollment.Role == enums.EnrollmentRoleTA, nil
+	return canReview, err
 }
 
 // canVerifyProgress checks if user can verify progress
-func (h *ProgressHandler) canVerifyProgress(userID, progressID string, isTeacher bool) (bool, error) {
-	// Teachers can verify any progress
-	if isTeacher {
-		return true, nil
-	}
-
+func (h *ProgressHandler) canVerifyProgress(userID, progressID string) (bool, error) {
 	// Get the course related to this progress
 	courseID, err := h.getCourseIDFromProgressID(progressID)
 	if err != nil {
@@ -328,17 +478,7 @@ func (h *ProgressHandler) canVerifyProgress(userID, progressID string, isTeacher
 		return false, nil
 	}
 
-	// Check if user is enrolled as TA in the related course
-	enrollment, err := h.enrollmentService.GetUserEnrollmentInCourse(courseID, userID)
-	if err != nil {
-		return false, err
-	}
-	if enrollment == nil {
-		return false, nil // Not enrolled
-	}
-
-	// TAs can verify progress
-	return enrollment.Role == enums.EnrollmentRoleTA, nil
+	return h.canViewCourseProgress(userID, courseID)
 }
 
 // getCourseIDFromProgressID gets course ID from progress ID
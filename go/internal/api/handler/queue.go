@@ -1,10 +1,12 @@
 package handler
 
 import (
+	"strings"
+
 	"github.com/Project-DSView/backend/go/internal/application/services"
-	models "github.com/Project-DSView/backend/go/internal/domain/entities"
 	"github.com/Project-DSView/backend/go/internal/domain/enums"
 	"github.com/Project-DSView/backend/go/internal/types"
+	"github.com/Project-DSView/backend/go/pkg/authz"
 	"github.com/Project-DSView/backend/go/pkg/response"
 	"github.com/gofiber/fiber/v2"
 )
@@ -55,39 +57,18 @@ func (h *QueueHandler) GetQueueJobs(c *fiber.Ctx) error {
 	courseID := c.Query("course_id")
 	fromDate := c.Query("from_date")
 	toDate := c.Query("to_date")
-	page := c.QueryInt("page", 1)
-	limit := c.QueryInt("limit", 20)
-
-	if page < 1 {
-		page = 1
-	}
-	if limit < 1 || limit > 100 {
-		limit = 20
-	}
+	page, limit := response.ParsePagination(c, 20, 0)
 
 	// Check permissions for course_id filter
 	// Students can view queue jobs in courses they're enrolled in (read-only)
 	// Teachers and TAs can view and manage queue jobs
 	if courseID != "" {
-		if !currentUser.IsTeacher {
-			// Check if user is TA in the specified course
-			isTA, err := h.queueService.IsUserTAInCourse(courseID, claims.UserID)
-			if err != nil {
-				return response.SendInternalError(c, "Failed to check TA status: "+err.Error())
-			}
-			// If not TA, check if user is enrolled in the course (students can view)
-			if !isTA {
-				// Check enrollment - students can view queue jobs in courses they're enrolled in
-				var enrollmentCount int64
-				if err := h.queueService.GetDB().Model(&models.Enrollment{}).
-					Where("course_id = ? AND user_id = ?", courseID, claims.UserID).
-					Count(&enrollmentCount).Error; err != nil {
-					return response.SendInternalError(c, "Failed to check enrollment: "+err.Error())
-				}
-				if enrollmentCount == 0 {
-					return response.SendError(c, fiber.StatusForbidden, "You are not enrolled in this course")
-				}
-			}
+		canView, err := authz.CanViewCourse(h.queueService.GetDB(), claims.UserID, courseID)
+		if err != nil && err != authz.ErrCourseNotFound {
+			return response.SendInternalError(c, "Failed to check enrollment: "+err.Error())
+		}
+		if !canView {
+			return response.SendError(c, fiber.StatusForbidden, "You are not enrolled in this course")
 		}
 	}
 
@@ -106,13 +87,8 @@ func (h *QueueHandler) GetQueueJobs(c *fiber.Ctx) error {
 	return c.JSON(fiber.Map{
 		"success": true,
 		"data": fiber.Map{
-			"jobs": jobData,
-			"pagination": fiber.Map{
-				"page":        page,
-				"limit":       limit,
-				"total":       total,
-				"total_pages": (total + limit - 1) / limit,
-			},
+			"jobs":       jobData,
+			"pagination": response.Pagination(c, page, limit, total),
 		},
 	})
 }
@@ -161,7 +137,16 @@ func (h *QueueHandler) GetQueueJob(c *fiber.Ctx) error {
 		return response.SendError(c, fiber.StatusForbidden, "You can only view your own jobs")
 	}
 
-	return response.SendSuccess(c, "Queue job retrieved successfully", job.ToJSON())
+	data := job.ToJSON()
+	if job.Held {
+		position, err := h.queueService.QueuePosition(job)
+		if err != nil {
+			return response.SendInternalError(c, "Failed to get queue position: "+err.Error())
+		}
+		data["queue_position"] = position
+	}
+
+	return response.SendSuccess(c, "Queue job retrieved successfully", data)
 }
 
 // CancelQueueJob godoc
@@ -248,6 +233,45 @@ func (h *QueueHandler) GetQueueStats(c *fiber.Ctx) error {
 	return response.SendSuccess(c, "Queue statistics retrieved successfully", stats)
 }
 
+// GetQueueConsumers godoc
+// @Summary List queue consumers
+// @Description List the per-course queue consumers running on this instance with their status (running, reconnecting, failed, stopped), last message time and recent errors. Operator endpoint, requires the service API key.
+// @Tags admin
+// @Security ApiKeyAuth
+// @Produce json
+// @Success 200 {object} object{success=bool,data=object{broker=string,consumers=[]types.QueueConsumerStatus}} "Queue consumers"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Router /api/admin/queue/consumers [get]
+func (h *QueueHandler) GetQueueConsumers(c *fiber.Ctx) error {
+	return response.SendSuccess(c, "Queue consumers retrieved successfully", fiber.Map{
+		"broker":    h.queueService.BrokerStatus(),
+		"consumers": h.queueService.ListConsumers(),
+	})
+}
+
+// GetStuckQueueJobs godoc
+// @Summary List stuck queue jobs
+// @Description List jobs that have been in processing for longer than QUEUE_STUCK_JOB_THRESHOLD (e.g. an abandoned TA review or a dead worker), oldest first, with their age. Operator endpoint, requires the service API key.
+// @Tags admin
+// @Security ApiKeyAuth
+// @Produce json
+// @Success 200 {object} object{success=bool,data=object{threshold=string,total=int,jobs=[]types.StuckQueueJob}} "Stuck jobs"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /api/admin/queue/stuck [get]
+func (h *QueueHandler) GetStuckQueueJobs(c *fiber.Ctx) error {
+	jobs, err := h.queueService.GetStuckJobs()
+	if err != nil {
+		return response.SendInternalError(c, "Failed to get stuck queue jobs: "+err.Error())
+	}
+
+	return response.SendSuccess(c, "Stuck queue jobs retrieved successfully", fiber.Map{
+		"threshold": h.queueService.StuckJobThreshold().String(),
+		"total":     len(jobs),
+		"jobs":      jobs,
+	})
+}
+
 // SubmitCodeExecution godoc
 // @Summary Submit code for execution (DEPRECATED)
 // @Description This endpoint is deprecated. Code execution is now handled automatically in course materials submission.
@@ -405,19 +429,16 @@ func (h *QueueHandler) ClaimQueueJob(c *fiber.Ctx) error {
 		return response.SendNotFound(c, "Job not found")
 	}
 
-	// Check permissions - only teachers and TAs can claim jobs
-	if !currentUser.IsTeacher {
-		// Check if user is TA in the course
-		if job.CourseID == nil {
-			return response.SendError(c, fiber.StatusForbidden, "Job does not have a course ID")
-		}
-		isTA, err := h.queueService.IsUserTAInCourse(*job.CourseID, claims.UserID)
-		if err != nil {
-			return response.SendInternalError(c, "Failed to check TA status: "+err.Error())
-		}
-		if !isTA {
-			return response.SendError(c, fiber.StatusForbidden, "Only teachers and TAs can claim jobs")
-		}
+	// Check permissions - only the course's teachers and TAs can claim jobs
+	if job.CourseID == nil {
+		return response.SendError(c, fiber.StatusForbidden, "Job does not have a course ID")
+	}
+	canReview, err := authz.CanReviewInCourse(h.queueService.GetDB(), claims.UserID, *job.CourseID)
+	if err != nil {
+		return response.SendInternalError(c, "Failed to check TA status: "+err.Error())
+	}
+	if !canReview {
+		return response.SendError(c, fiber.StatusForbidden, "Only teachers and TAs can claim jobs")
 	}
 
 	// Claim job
@@ -485,19 +506,16 @@ func (h *QueueHandler) CompleteReview(c *fiber.Ctx) error {
 		return response.SendNotFound(c, "Job not found")
 	}
 
-	// Check permissions - only teachers and TAs can complete reviews
-	if !currentUser.IsTeacher {
-		// Check if user is TA in the course
-		if job.CourseID == nil {
-			return response.SendError(c, fiber.StatusForbidden, "Job does not have a course ID")
-		}
-		isTA, err := h.queueService.IsUserTAInCourse(*job.CourseID, claims.UserID)
-		if err != nil {
-			return response.SendInternalError(c, "Failed to check TA status: "+err.Error())
-		}
-		if !isTA {
-			return response.SendError(c, fiber.StatusForbidden, "Only teachers and TAs can complete reviews")
-		}
+	// Check permissions - only the course's teachers and TAs can complete reviews
+	if job.CourseID == nil {
+		return response.SendError(c, fiber.StatusForbidden, "Job does not have a course ID")
+	}
+	canReview, err := authz.CanReviewInCourse(h.queueService.GetDB(), claims.UserID, *job.CourseID)
+	if err != nil {
+		return response.SendInternalError(c, "Failed to check TA status: "+err.Error())
+	}
+	if !canReview {
+		return response.SendError(c, fiber.StatusForbidden, "Only teachers and TAs can complete reviews")
 	}
 
 	var req struct {
@@ -571,8 +589,8 @@ func (h *QueueHandler) RetryQueueJob(c *fiber.Ctx) error {
 		if err.Error() == "queue job does not belong to user" {
 			return response.SendError(c, fiber.StatusForbidden, "You can only retry your own queue jobs")
 		}
-		if err.Error() == "submission is less than 1 day old, cannot retry yet" || err.Error() == "queue job is less than 1 day old, cannot retry yet" {
-			return response.SendBadRequest(c, "Cannot retry yet. Please wait at least 1 day from the original submission.")
+		if strings.HasSuffix(err.Error(), "cannot retry yet") {
+			return response.SendBadRequest(c, "Cannot retry yet: "+err.Error())
 		}
 		return response.SendInternalError(c, "Failed to create retry job: "+err.Error())
 	}
@@ -0,0 +1,151 @@
+package handler
+
+import (
+	"errors"
+
+	"github.com/Project-DSView/backend/go/internal/application/services"
+	"github.com/Project-DSView/backend/go/internal/types"
+	"github.com/Project-DSView/backend/go/pkg/response"
+	"github.com/gofiber/fiber/v2"
+)
+
+type SessionHandler struct {
+	sessionService *services.SessionService
+	userService    *services.UserService
+}
+
+func NewSessionHandler(sessionService *services.SessionService, userService *services.UserService) *SessionHandler {
+	return &SessionHandler{
+		sessionService: sessionService,
+		userService:    userService,
+	}
+}
+
+// authorize checks that the caller is a teacher of a course the target user is
+// enrolled in. When it reports false the error response has already been sent.
+func (h *SessionHandler) authorize(c *fiber.Ctx) (string, bool, error) {
+	claims, ok := c.Locals("claims").(*types.Claims)
+	if !ok {
+		return "", false, response.SendUnauthorized(c, "Invalid authentication")
+	}
+
+	currentUser, err := h.userService.GetUserByID(claims.UserID)
+	if err != nil || currentUser == nil {
+		return "", false, response.SendUnauthorized(c, "User not found")
+	}
+	if !currentUser.IsTeacher {
+		return "", false, response.SendError(c, fiber.StatusForbidden, "Only teachers can manage user sessions")
+	}
+
+	userID := c.Params("id")
+	targetUser, err := h.userService.GetUserByID(userID)
+	if err != nil || targetUser == nil {
+		return "", false, response.SendNotFound(c, "User not found")
+	}
+
+	allowed, err := h.sessionService.CanManageSessions(claims.UserID, userID)
+	if err != nil {
+		return "", false, response.SendInternalError(c, "Failed to check permissions: "+err.Error())
+	}
+	if !allowed {
+		return "", false, response.SendError(c, fiber.StatusForbidden, "User is not enrolled in any of your courses")
+	}
+
+	return userID, true, nil
+}
+
+// ListUserSessions godoc
+// @Summary List user sessions
+// @Description List the active login sessions (devices) of a student enrolled in one of the teacher's courses (Teacher only)
+// @Tags sessions
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Produce json
+// @Param id path string true "User ID"
+// @Success 200 {object} object{success=bool,message=string,data=[]object} "Active sessions"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Forbidden"
+// @Failure 404 {object} map[string]string "User not found"
+// @Router /api/users/{id}/sessions [get]
+func (h *SessionHandler) ListUserSessions(c *fiber.Ctx) error {
+	userID, ok, err := h.authorize(c)
+	if !ok {
+		return err
+	}
+
+	sessions, err := h.sessionService.ListActiveSessions(userID)
+	if err != nil {
+		return response.SendInternalError(c, "Failed to get sessions: "+err.Error())
+	}
+
+	result := make([]map[string]interface{}, len(sessions))
+	for i := range sessions {
+		result[i] = sessions[i].ToJSON()
+	}
+
+	return response.SendSuccess(c, "Sessions retrieved successfully", result)
+}
+
+// RevokeUserSession godoc
+// @Summary Revoke a user session
+// @Description Revoke one login session of a student. Refreshing fails from then on and tokens already issued for it are rejected (Teacher only)
+// @Tags sessions
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Produce json
+// @Param id path string true "User ID"
+// @Param session_id path string true "Session ID"
+// @Success 200 {object} object{success=bool,message=string} "Session revoked"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Forbidden"
+// @Failure 404 {object} map[string]string "Session not found"
+// @Router /api/users/{id}/sessions/{session_id} [delete]
+func (h *SessionHandler) RevokeUserSession(c *fiber.Ctx) error {
+	userID, ok, err := h.authorize(c)
+	if !ok {
+		return err
+	}
+
+	if err := h.sessionService.RevokeSession(userID, c.Params("session_id")); err != nil {
+		if errors.Is(err, services.ErrSessionNotFound) {
+			return response.SendNotFound(c, "Session not found or already revoked")
+		}
+		return response.SendInternalError(c, "Failed to revoke session: "+err.Error())
+	}
+
+	return response.SendSuccess(c, "Session revoked successfully", nil)
+}
+
+// RevokeAllUserSessions godoc
+// @Summary Revoke all user sessions
+// @Description Sign a student out of every device (Teacher only)
+// @Tags sessions
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Produce json
+// @Param id path string true "User ID"
+// @Success 200 {object} object{success=bool,message=string,data=object{revoked=int}} "Sessions revoked"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Forbidden"
+// @Failure 404 {object} map[string]string "User not found"
+// @Router /api/users/{id}/sessions [delete]
+func (h *SessionHandler) RevokeAllUserSessions(c *fiber.Ctx) error {
+	userID, ok, err := h.authorize(c)
+	if !ok {
+		return err
+	}
+
+	revoked, err := h.sessionService.RevokeAllSessions(userID)
+	if err != nil {
+		return response.SendInternalError(c, "Failed to revoke sessions: "+err.Error())
+	}
+
+	// Also drop the refresh token kept on the user from before sessions were tracked
+	if err := h.userService.RemoveRefreshToken(userID); err != nil {
+		return response.SendInternalError(c, "Failed to remove refresh token: "+err.Error())
+	}
+
+	return response.SendSuccess(c, "Sessions revoked successfully", fiber.Map{
+		"revoked": revoked,
+	})
+}
@@ -1,20 +1,37 @@
 package handler
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
 	"github.com/Project-DSView/backend/go/internal/application/services"
 	models "github.com/Project-DSView/backend/go/internal/domain/entities"
 	"github.com/Project-DSView/backend/go/internal/domain/enums"
 	"github.com/Project-DSView/backend/go/internal/types"
+	"github.com/Project-DSView/backend/go/pkg/authz"
+	"github.com/Project-DSView/backend/go/pkg/external"
 	"github.com/Project-DSView/backend/go/pkg/response"
+	"github.com/Project-DSView/backend/go/pkg/storage"
+	"github.com/Project-DSView/backend/go/pkg/validation"
 	"github.com/gofiber/fiber/v2"
 	"gorm.io/gorm"
 )
 
+// validateCodeTimeout bounds a ValidateCode request, including the wait for a container
+const validateCodeTimeout = 30 * time.Second
+
+// simulateRegradeTimeout bounds a SimulateRegrade request; submissions not run by then are left out
+const simulateRegradeTimeout = 2 * time.Minute
+
 type SubmissionHandler struct {
 	submissionService *services.SubmissionService
 	userService       *services.UserService
 	enrollmentService *services.EnrollmentService
 	db                *gorm.DB
+	maxPDFSize        int64 // bytes; 0 = unlimited
 }
 
 func NewSubmissionHandler(
@@ -31,6 +48,11 @@ func NewSubmissionHandler(
 	}
 }
 
+// SetMaxPDFSize sets the maximum size of submitted PDFs
+func (h *SubmissionHandler) SetMaxPDFSize(maxPDFSize int64) {
+	h.maxPDFSize = maxPDFSize
+}
+
 // SubmitExercise godoc
 // @Summary Submit exercise (DEPRECATED)
 // @Description This endpoint is deprecated. Please use POST /api/course-materials/{id}/submit instead.
@@ -90,11 +112,6 @@ func (h *SubmissionHandler) GetSubmission(c *fiber.Ctx) error {
 		return response.SendNotFound(c, "Submission not found")
 	}
 
-	curUser, err := h.userService.GetUserByID(claims.UserID)
-	if err != nil {
-		return response.SendInternalError(c, "Failed to get user: "+err.Error())
-	}
-
 	// Allow owner to view their own submission
 	if sub.UserID == claims.UserID {
 		return h.sendSubmissionResponse(c, sub)
@@ -102,7 +119,7 @@ func (h *SubmissionHandler) GetSubmission(c *fiber.Ctx) error {
 
 	// For others, check if they can view submissions for this material
 	if sub.MaterialID != "" {
-		canView, err := h.canViewMaterialSubmissions(claims.UserID, sub.MaterialID, curUser.IsTeacher)
+		canView, err := h.canViewMaterialSubmissions(claims.UserID, sub.MaterialID)
 		if err != nil {
 			return response.SendInternalError(c, "Failed to check permissions: "+err.Error())
 		}
@@ -117,34 +134,392 @@ func (h *SubmissionHandler) GetSubmission(c *fiber.Ctx) error {
 	return h.sendSubmissionResponse(c, sub)
 }
 
-// Helper method สำหรับตรวจสอบสิทธิ์การดู material submissions
-func (h *SubmissionHandler) canViewMaterialSubmissions(userID, materialID string, isTeacher bool) (bool, error) {
-	// Teachers สามารถดู submissions ทั้งหมดได้
-	if isTeacher {
-		return true, nil
-	}
-
-	// สำหรับ non-teachers ต้องเช็คว่าเป็น TA ในคอร์สที่มี material นี้หรือไม่
-	// ดึง course ที่มี material นี้
-	var courseID string
-	err := h.db.Model(&models.CourseMaterial{}).
-		Select("course_id").
-		Where("material_id = ?", materialID).
-		Scan(&courseID).Error
+// GetTestCaseStats godoc
+// @Summary Get pass rate per test case
+// @Description Count how many students passed or failed each test case of a code exercise, using each student's latest graded submission (Teachers or TAs enrolled in the course)
+// @Tags submissions
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Material ID"
+// @Success 200 {object} object{success=bool,message=string,data=[]types.TestCasePassRate} "Pass rate per test case"
+// @Failure 401 {object} object{success=bool,error=string} "Unauthorized"
+// @Failure 403 {object} object{success=bool,error=string} "Forbidden"
+// @Failure 500 {object} object{success=bool,error=string} "Internal server error"
+// @Router /api/course-materials/{id}/test-case-stats [get]
+func (h *SubmissionHandler) GetTestCaseStats(c *fiber.Ctx) error {
+	claims, ok := c.Locals("claims").(*types.Claims)
+	if !ok {
+		return response.SendUnauthorized(c, "Invalid authentication")
+	}
+
+	materialID := c.Params("id")
+	if materialID == "" {
+		return response.SendBadRequest(c, "Material ID is required")
+	}
+
+	canView, err := h.canViewMaterialSubmissions(claims.UserID, materialID)
 	if err != nil {
-		return false, err
+		return response.SendInternalError(c, "Failed to check permissions: "+err.Error())
+	}
+	if !canView {
+		return response.SendError(c, fiber.StatusForbidden, "Only teachers and TAs can view test case statistics")
 	}
 
-	// เช็คว่า user เป็น TA ในคอร์สนี้หรือไม่
-	enrollment, err := h.enrollmentService.GetUserEnrollmentInCourse(courseID, userID)
+	rates, err := h.submissionService.GetTestCasePassRates(materialID)
 	if err != nil {
-		return false, err
+		return response.SendInternalError(c, "Failed to get test case statistics: "+err.Error())
+	}
+
+	return response.SendSuccess(c, "Test case statistics retrieved successfully", rates)
+}
+
+// GetExecutorCapabilities godoc
+// @Summary Get executor languages and limits
+// @Description List the languages code exercises can be submitted in, with their runtime images and versions, and the limits every run is held to: time, memory, CPUs, concurrent containers, and the largest test case input and expected output (0 is unlimited)
+// @Tags submissions
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} object{success=bool,message=string,data=types.ExecutorCapabilities} "Executor capabilities"
+// @Failure 401 {object} object{success=bool,error=string} "Unauthorized"
+// @Router /api/executor/capabilities [get]
+func (h *SubmissionHandler) GetExecutorCapabilities(c *fiber.Ctx) error {
+	return response.SendSuccess(c, "Executor capabilities retrieved successfully", h.submissionService.ExecutorCapabilities())
+}
+
+// RejudgeSubmission godoc
+// @Summary Rejudge a submission
+// @Description Grade one code submission again against the material's current test cases, replacing its results and recomputing the student's progress (course creator or TAs of the course)
+// @Tags submissions
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Submission ID"
+// @Success 200 {object} object{success=bool,message=string,data=object{submission_id=string,status=string,passed_count=int,failed_count=int,total_score=int,results=[]object}}
+// @Failure 400 {object} object{success=bool,error=string} "Not a code submission"
+// @Failure 401 {object} object{success=bool,error=string} "Unauthorized"
+// @Failure 403 {object} object{success=bool,error=string} "Forbidden"
+// @Failure 404 {object} object{success=bool,error=string} "Submission not found"
+// @Failure 500 {object} object{success=bool,error=string} "Internal server error"
+// @Router /api/submissions/{id}/rejudge [post]
+func (h *SubmissionHandler) RejudgeSubmission(c *fiber.Ctx) error {
+	claims, ok := c.Locals("claims").(*types.Claims)
+	if !ok {
+		return response.SendUnauthorized(c, "Invalid authentication")
+	}
+
+	submissionID := c.Params("id")
+	if submissionID == "" {
+		return response.SendBadRequest(c, "Submission ID is required")
+	}
+
+	sub, err := h.submissionService.Rejudge(submissionID, claims.UserID)
+	if err != nil {
+		if errors.Is(err, services.ErrCourseArchived) {
+			return response.SendError(c, fiber.StatusForbidden, err.Error())
+		}
+		switch err.Error() {
+		case "submission not found":
+			return response.SendNotFound(c, "Submission not found")
+		case "only code submissions can be rejudged":
+			return response.SendBadRequest(c, "Only code submissions can be rejudged")
+		case "only course teachers and TAs can rejudge submissions":
+			return response.SendError(c, fiber.StatusForbidden, "Only course teachers and TAs can rejudge submissions")
+		}
+		return response.SendInternalError(c, "Failed to rejudge submission: "+err.Error())
+	}
+
+	return response.SendSuccess(c, "Submission rejudge started successfully", fiber.Map{
+		"submission_id": sub.SubmissionID,
+		"status":        sub.Status,
+		"passed_count":  sub.PassedCount,
+		"failed_count":  sub.FailedCount,
+		"total_score":   sub.TotalScore,
+		"results":       sub.Results,
+	})
+}
+
+// CancelSubmission godoc
+// @Summary Cancel a queued or running submission
+// @Description Stop the caller's own code submission while it is still queued or running, e.g. one stuck in an infinite loop. Its results are discarded, and the attempt it used and the progress status it reset are given back. A PDF submission is cancelled as described for the PDF exercise route of the same path
+// @Tags submissions
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Submission ID"
+// @Success 200 {object} object{success=bool,message=string,data=object{submission_id=string,status=string}}
+// @Failure 401 {object} object{success=bool,error=string} "Unauthorized"
+// @Failure 403 {object} object{success=bool,error=string} "Not the caller's submission"
+// @Failure 404 {object} object{success=bool,error=string} "Submission not found"
+// @Failure 409 {object} object{success=bool,error=string} "Submission is no longer queued or running"
+// @Failure 500 {object} object{success=bool,error=string} "Internal server error"
+// @Router /api/submissions/{id}/cancel [delete]
+func (h *SubmissionHandler) CancelSubmission(c *fiber.Ctx) error {
+	claims, ok := c.Locals("claims").(*types.Claims)
+	if !ok {
+		return response.SendUnauthorized(c, "Invalid authentication")
+	}
+
+	submissionID := c.Params("id")
+	if submissionID == "" {
+		return response.SendBadRequest(c, "Submission ID is required")
+	}
+
+	sub, err := h.submissionService.CancelRunningSubmission(submissionID, claims.UserID)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrNotCodeExercise):
+			// PDF submissions share this path; their cancel route is registered after this one
+			return c.Next()
+		case err.Error() == "submission not found":
+			return response.SendNotFound(c, "Submission not found")
+		case errors.Is(err, services.ErrNotSubmissionOwner):
+			return response.SendError(c, fiber.StatusForbidden, err.Error())
+		case errors.Is(err, services.ErrSubmissionNotRunning):
+			return response.SendError(c, fiber.StatusConflict, err.Error())
+		}
+		return response.SendInternalError(c, "Failed to cancel submission: "+err.Error())
+	}
+
+	return response.SendSuccess(c, "Submission cancelled successfully", fiber.Map{
+		"submission_id": sub.SubmissionID,
+		"status":        sub.Status,
+	})
+}
+
+// SimulateRegrade godoc
+// @Summary Preview a regrade against proposed test cases
+// @Description Run each student's latest submission to a code exercise against proposed test cases without saving anything, and return the projected score distribution. At most 100 submissions and 500 test case runs are run, within two minutes; truncated is set when submissions were left out (course creator only)
+// @Tags submissions
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Material ID"
+// @Param request body object{test_cases=[]object{input_data=object,expected_output=object}} true "Proposed test cases"
+// @Success 200 {object} object{success=bool,message=string,data=object{test_case_count=int,submission_count=int,truncated=bool,current_average=number,projected_average=number,changed_count=int,distribution=[]object{score=int,count=int},submissions=[]object{submission_id=string,user_id=string,current_score=int,projected_score=int,passed_count=int}}}
+// @Failure 400 {object} object{success=bool,error=string} "Invalid test cases or not a code exercise"
+// @Failure 401 {object} object{success=bool,error=string} "Unauthorized"
+// @Failure 403 {object} object{success=bool,error=string} "Forbidden"
+// @Failure 404 {object} object{success=bool,error=string} "Material not found"
+// @Failure 500 {object} object{success=bool,error=string} "Internal server error"
+// @Router /api/course-materials/{id}/simulate-regrade [post]
+func (h *SubmissionHandler) SimulateRegrade(c *fiber.Ctx) error {
+	claims, ok := c.Locals("claims").(*types.Claims)
+	if !ok {
+		return response.SendUnauthorized(c, "Invalid authentication")
+	}
+
+	materialID := c.Params("id")
+	if materialID == "" {
+		return response.SendBadRequest(c, "Material ID is required")
+	}
+
+	var req struct {
+		TestCases []struct {
+			InputData      types.JSONData `json:"input_data"`
+			ExpectedOutput types.JSONData `json:"expected_output"`
+		} `json:"test_cases"`
+	}
+	if err := c.BodyParser(&req); err != nil {
+		return response.SendBadRequest(c, "Invalid request body: "+err.Error())
+	}
+
+	proposed := make([]models.TestCase, len(req.TestCases))
+	for i, tc := range req.TestCases {
+		if len(tc.InputData) == 0 || len(tc.ExpectedOutput) == 0 {
+			return response.SendBadRequest(c, fmt.Sprintf("Test case %d needs input_data and expected_output", i+1))
+		}
+		proposed[i] = models.TestCase{InputData: tc.InputData, ExpectedOutput: tc.ExpectedOutput}
+	}
+
+	ctx, cancel := context.WithTimeout(c.UserContext(), simulateRegradeTimeout)
+	defer cancel()
+	simulation, err := h.submissionService.SimulateRegrade(ctx, materialID, proposed, claims.UserID)
+	if err != nil {
+		if errors.Is(err, services.ErrTestCaseTooLarge) || errors.Is(err, services.ErrTooManySimulatedTestCases) {
+			return response.SendBadRequest(c, err.Error())
+		}
+		switch err.Error() {
+		case "course material not found":
+			return response.SendNotFound(c, "Material not found")
+		case "at least one test case is required", "only code exercises can be regraded":
+			return response.SendBadRequest(c, err.Error())
+		case "only the course teacher can simulate a regrade":
+			return response.SendError(c, fiber.StatusForbidden, "Only the course teacher can simulate a regrade")
+		}
+		return response.SendInternalError(c, "Failed to simulate regrade: "+err.Error())
+	}
+
+	return response.SendSuccess(c, "Regrade simulated successfully", simulation)
+}
+
+// ReprocessSubmissionFile godoc
+// @Summary Reprocess a submission's file
+// @Description Queue the file processing job (PDF, image or video) of a file submission again, e.g. after processing failed, without the student resubmitting (course creator or TAs of the course)
+// @Tags submissions
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Submission ID"
+// @Success 202 {object} object{success=bool,message=string,data=object{submission_id=string,job_id=string,status=string}}
+// @Failure 400 {object} object{success=bool,error=string} "Submission has no file"
+// @Failure 401 {object} object{success=bool,error=string} "Unauthorized"
+// @Failure 403 {object} object{success=bool,error=string} "Forbidden"
+// @Failure 404 {object} object{success=bool,error=string} "Submission not found"
+// @Failure 409 {object} object{success=bool,error=string} "File processing already in progress"
+// @Failure 500 {object} object{success=bool,error=string} "Internal server error"
+// @Router /api/submissions/{id}/reprocess [post]
+func (h *SubmissionHandler) ReprocessSubmissionFile(c *fiber.Ctx) error {
+	claims, ok := c.Locals("claims").(*types.Claims)
+	if !ok {
+		return response.SendUnauthorized(c, "Invalid authentication")
+	}
+
+	submissionID := c.Params("id")
+	if submissionID == "" {
+		return response.SendBadRequest(c, "Submission ID is required")
+	}
+
+	job, err := h.submissionService.ReprocessFile(submissionID, claims.UserID)
+	if err != nil {
+		switch err.Error() {
+		case "submission not found":
+			return response.SendNotFound(c, "Submission not found")
+		case "submission has no file to process":
+			return response.SendBadRequest(c, "Submission has no file to process")
+		case "only course teachers and TAs can reprocess submissions":
+			return response.SendError(c, fiber.StatusForbidden, "Only course teachers and TAs can reprocess submissions")
+		case "file processing is already in progress for this submission":
+			return response.SendError(c, fiber.StatusConflict, "File processing is already in progress for this submission")
+		}
+		return response.SendInternalError(c, "Failed to reprocess submission: "+err.Error())
+	}
+
+	return response.SuccessResponse(c, fiber.StatusAccepted, "File processing queued", fiber.Map{
+		"submission_id": submissionID,
+		"job_id":        job.ID,
+		"status":        job.Status,
+	})
+}
+
+// DownloadSubmissionCode godoc
+// @Summary Download submission code
+// @Description Download the source code of a code submission as a file (owner, Teachers, or TAs enrolled in the course)
+// @Tags submissions
+// @Security BearerAuth
+// @Produce octet-stream
+// @Param id path string true "Submission ID"
+// @Success 200 {file} file "Source code file"
+// @Failure 400 {object} object{success=bool,error=string} "Not a code submission"
+// @Failure 401 {object} object{success=bool,error=string} "Unauthorized"
+// @Failure 403 {object} object{success=bool,error=string} "Forbidden"
+// @Failure 404 {object} object{success=bool,error=string} "Submission not found"
+// @Failure 500 {object} object{success=bool,error=string} "Internal server error"
+// @Router /api/submissions/{id}/code/download [get]
+func (h *SubmissionHandler) DownloadSubmissionCode(c *fiber.Ctx) error {
+	claims, ok := c.Locals("claims").(*types.Claims)
+	if !ok {
+		return response.SendUnauthorized(c, "Invalid authentication")
+	}
+
+	subID := c.Params("id")
+	if subID == "" {
+		return response.SendBadRequest(c, "Submission ID is required")
+	}
+
+	var sub models.Submission
+	if err := h.db.Where("submission_id = ?", subID).First(&sub).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return response.SendNotFound(c, "Submission not found")
+		}
+		return response.SendInternalError(c, "Failed to get submission: "+err.Error())
+	}
+
+	// Owner, or teachers/TAs allowed to view this material's submissions
+	if sub.UserID != claims.UserID {
+		canView, err := h.canViewMaterialSubmissions(claims.UserID, sub.MaterialID)
+		if err != nil {
+			return response.SendInternalError(c, "Failed to check permissions: "+err.Error())
+		}
+		if !canView {
+			return response.SendError(c, fiber.StatusForbidden, "You don't have permission to download this submission")
+		}
+	}
+
+	reader, filename, contentType, size, err := h.submissionService.OpenSubmissionCode(&sub)
+	if err != nil {
+		if err.Error() == "submission has no code" {
+			return response.SendBadRequest(c, "Submission has no code to download")
+		}
+		return response.SendInternalError(c, "Failed to get submission code: "+err.Error())
+	}
+	if closer, ok := reader.(io.Closer); ok {
+		defer closer.Close()
+	}
+
+	c.Set("Content-Type", contentType)
+	c.Set("Content-Disposition", storage.ContentDisposition("attachment", filename))
+	c.Set("Content-Length", fmt.Sprintf("%d", size))
+
+	c.Status(fiber.StatusOK)
+	if _, err := io.Copy(c.Response().BodyWriter(), reader); err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "Failed to stream file: "+err.Error())
+	}
+	return nil
+}
+
+// GetStudentLatestCode godoc
+// @Summary Preview a student's latest code
+// @Description Get the source of a student's most recent code submission to a material as JSON, for showing inline during live help (Teachers or TAs of the course)
+// @Tags submissions
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Material ID"
+// @Param user_id path string true "Student user ID"
+// @Success 200 {object} object{success=bool,message=string,data=types.StudentCode} "Latest code"
+// @Failure 401 {object} object{success=bool,error=string} "Unauthorized"
+// @Failure 403 {object} object{success=bool,error=string} "Forbidden"
+// @Failure 404 {object} object{success=bool,error=string} "The student has not submitted code"
+// @Failure 500 {object} object{success=bool,error=string} "Internal server error"
+// @Router /api/course-materials/{id}/students/{user_id}/code [get]
+func (h *SubmissionHandler) GetStudentLatestCode(c *fiber.Ctx) error {
+	claims, ok := c.Locals("claims").(*types.Claims)
+	if !ok {
+		return response.SendUnauthorized(c, "Invalid authentication")
+	}
+
+	materialID := c.Params("id")
+	studentID := c.Params("user_id")
+	if materialID == "" || studentID == "" {
+		return response.SendBadRequest(c, "Material ID and user ID are required")
+	}
+
+	canView, err := h.canViewMaterialSubmissions(claims.UserID, materialID)
+	if err != nil {
+		return response.SendInternalError(c, "Failed to check permissions: "+err.Error())
 	}
-	if enrollment != nil && enrollment.Role == enums.EnrollmentRoleTA {
-		return true, nil // เป็น TA ใน course ที่มี material นี้
+	if !canView {
+		return response.SendError(c, fiber.StatusForbidden, "Only teachers and TAs can view students' code")
 	}
 
-	return false, nil // ไม่ใช่ teacher และไม่ใช่ TA ในคอร์สที่เกี่ยวข้อง
+	code, err := h.submissionService.GetLatestCode(studentID, materialID)
+	if err != nil {
+		return response.SendInternalError(c, "Failed to get code: "+err.Error())
+	}
+	if code == nil {
+		return response.SendNotFound(c, "The student has not submitted code to this material")
+	}
+
+	return response.SendSuccess(c, "Code retrieved successfully", code)
+}
+
+// Helper method สำหรับตรวจสอบสิทธิ์การดู material submissions
+// เฉพาะผู้สร้างคอร์สและ TA ของคอร์สที่มี material นี้
+func (h *SubmissionHandler) canViewMaterialSubmissions(userID, materialID string) (bool, error) {
+	courseID, err := authz.MaterialCourseID(h.db, materialID)
+	if err == authz.ErrMaterialNotFound {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return authz.CanReviewInCourse(h.db, userID, courseID)
 }
 
 // Helper method สำหรับส่ง submission response
@@ -172,6 +547,15 @@ func (h *SubmissionHandler) sendSubmissionResponse(c *fiber.Ctx, sub *models.Sub
 		"graded_by":          sub.GradedBy,
 		"review_status":      sub.ReviewStatus,
 		"queue_job_id":       sub.QueueJobID,
+		"submitted_by":       sub.SubmittedBy,
+	}
+
+	// Highlight the slowest test case for performance-focused exercises
+	if slowest := sub.SlowestResult(); slowest != nil {
+		responseData["slowest_result"] = fiber.Map{
+			"test_case_id":      slowest.TestCaseID,
+			"execution_time_ms": slowest.ExecutionTimeMs,
+		}
 	}
 
 	// Get graded_by_user information if graded_by exists
@@ -243,9 +627,13 @@ func (h *SubmissionHandler) sendSubmissionResponse(c *fiber.Ctx, sub *models.Sub
 // @Produce json
 // @Param id path string true "Material ID"
 // @Param request body object{code=string} true "Code submission"
-// @Success 201 {object} object{success=bool,message=string,data=object{submissionId=string,passedCount=int,failedCount=int,totalScore=int,results=[]object{resultId=string,testCaseId=string,status=string,actualOutput=object,errorMessage=string}}}
+// @Success 200 {object} object{success=bool,message=string,data=object{submission_id=string,status=string,queued=bool,job_id=string,passed_count=int,failed_count=int,total_score=int,results=[]object{result_id=string,test_case_id=string,status=string,actual_output=object,error_message=string}}} "Graded synchronously"
+// @Success 202 {object} object{success=bool,message=string,data=object{submission_id=string,status=string,queued=bool,job_id=string,queue_position=int,passed_count=int,failed_count=int,total_score=int,results=[]object}} "Receipt: queued for grading, status is processing (or waiting, with queue_position, while the exercise is at its concurrent submission cap)"
 // @Failure 400 {object} object{success=bool,error=string}
 // @Failure 401 {object} object{success=bool,error=string}
+// @Failure 403 {object} object{success=bool,error=string} "Deadline passed, outside the submission window, or exercise not available"
+// @Failure 409 {object} object{success=bool,error=string} "Exercise locked after approval, or attempt limit reached"
+// @Failure 429 {object} object{success=bool,error=string} "Previous submission still processing"
 // @Failure 500 {object} object{success=bool,error=string}
 // @Router /api/course-materials/{id}/submit [post]
 func (h *SubmissionHandler) SubmitMaterialExercise(c *fiber.Ctx) error {
@@ -258,6 +646,9 @@ func (h *SubmissionHandler) SubmitMaterialExercise(c *fiber.Ctx) error {
 	if materialID == "" {
 		return response.SendBadRequest(c, "Material ID is required")
 	}
+	if err := h.submissionService.CheckSubmissionType(materialID, enums.MaterialTypeCodeExercise); err != nil {
+		return sendSubmissionTypeError(c, err)
+	}
 
 	var req struct {
 		Code string `json:"code" validate:"required"`
@@ -274,20 +665,218 @@ func (h *SubmissionHandler) SubmitMaterialExercise(c *fiber.Ctx) error {
 	// Submit material exercise
 	result, err := h.submissionService.SubmitMaterialExercise(claims.UserID, materialID, req.Code)
 	if err != nil {
+		if errors.Is(err, services.ErrSubmissionNotAllowed) {
+			return response.SendError(c, fiber.StatusForbidden, err.Error())
+		}
+		if errors.Is(err, services.ErrResubmissionLocked) {
+			return response.SendError(c, fiber.StatusConflict, err.Error())
+		}
+		if errors.Is(err, services.ErrAttemptLimitReached) {
+			return response.SendError(c, fiber.StatusConflict, err.Error())
+		}
+		if errors.Is(err, services.ErrPrerequisitesNotMet) {
+			return response.SendError(c, fiber.StatusForbidden, err.Error())
+		}
+		if errors.Is(err, services.ErrSubmissionInProgress) {
+			return response.SendError(c, fiber.StatusTooManyRequests, err.Error())
+		}
 		return response.SendInternalError(c, "Failed to submit material exercise: "+err.Error())
 	}
 
-	// Extract submission data from result
+	if result.Queued {
+		return response.SuccessResponse(c, fiber.StatusAccepted, "Material exercise submitted and queued for grading", submitResultData(result))
+	}
+	return response.SendSuccess(c, "Material exercise submitted successfully", submitResultData(result))
+}
+
+// ValidateCode godoc
+// @Summary Check code for compile errors
+// @Description Compile or parse code for a code exercise without running it or any test case, and report the errors found with line and column. Nothing is submitted or queued. language defaults to python; see /api/executor/capabilities for the languages available
+// @Tags submissions
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Material ID"
+// @Param request body object{language=string,code=string} true "Code to check"
+// @Success 200 {object} object{success=bool,message=string,data=object{valid=bool,language=string,diagnostics=[]object{line=int,column=int,message=string}}}
+// @Failure 400 {object} object{success=bool,error=string} "Invalid code, unsupported language or not a code exercise"
+// @Failure 401 {object} object{success=bool,error=string}
+// @Failure 403 {object} object{success=bool,error=string} "Not enrolled in the course"
+// @Failure 404 {object} object{success=bool,error=string} "Material not found"
+// @Failure 500 {object} object{success=bool,error=string}
+// @Failure 503 {object} object{success=bool,error=string} "No container was free in time"
+// @Router /api/course-materials/{id}/validate [post]
+func (h *SubmissionHandler) ValidateCode(c *fiber.Ctx) error {
+	claims, ok := c.Locals("claims").(*types.Claims)
+	if !ok {
+		return response.SendUnauthorized(c, "Invalid authentication")
+	}
+
+	materialID := c.Params("id")
+	if materialID == "" {
+		return response.SendBadRequest(c, "Material ID is required")
+	}
+	if err := h.submissionService.CheckSubmissionType(materialID, enums.MaterialTypeCodeExercise); err != nil {
+		return sendSubmissionTypeError(c, err)
+	}
+	canView, err := authz.CanViewMaterial(h.db, claims.UserID, materialID)
+	if err != nil {
+		return response.SendInternalError(c, "Failed to check enrollment: "+err.Error())
+	}
+	if !canView {
+		return response.SendError(c, fiber.StatusForbidden, "You must be enrolled in this course to check code for its exercises")
+	}
+
+	var req struct {
+		Language string `json:"language"`
+		Code     string `json:"code"`
+	}
+	if err := c.BodyParser(&req); err != nil {
+		return response.SendBadRequest(c, "Invalid request body: "+err.Error())
+	}
+	if err := validation.ValidateCodeContent(req.Code); err != nil {
+		return response.SendValidationError(c, err.Error())
+	}
+	if req.Language == "" {
+		req.Language = "python"
+	}
+
+	// The check runs a container while the request waits, so it gets a bounded share of it
+	ctx, cancel := context.WithTimeout(c.UserContext(), validateCodeTimeout)
+	defer cancel()
+	diagnostics, err := h.submissionService.Validate(ctx, req.Language, req.Code)
+	if err != nil {
+		if errors.Is(err, services.ErrUnsupportedLanguage) {
+			return response.SendBadRequest(c, err.Error())
+		}
+		if errors.Is(err, external.ErrRunCancelled) {
+			return response.SendError(c, fiber.StatusServiceUnavailable, "The code checker is busy, please try again")
+		}
+		return response.SendInternalError(c, "Failed to check code: "+err.Error())
+	}
+
+	return response.SendSuccess(c, "Code checked successfully", fiber.Map{
+		"valid":       len(diagnostics) == 0,
+		"language":    req.Language,
+		"diagnostics": diagnostics,
+	})
+}
+
+// SubmitOnBehalf godoc
+// @Summary Submit code on behalf of a student
+// @Description Submit code for a code exercise attributed to a student, e.g. when the student's environment is broken (Teacher who is course creator only). The submission is graded and updates the student's progress; the acting teacher is recorded in submitted_by.
+// @Tags submissions
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Material ID"
+// @Param request body object{student_id=string,code=string} true "Student and code"
+// @Success 200 {object} object{success=bool,message=string,data=object{submission_id=string,user_id=string,submitted_by=string,status=string,queued=bool,job_id=string,passed_count=int,failed_count=int,total_score=int,results=[]object}}
+// @Success 202 {object} object{success=bool,message=string,data=object{submission_id=string,user_id=string,submitted_by=string,status=string,queued=bool,job_id=string,queue_position=int,passed_count=int,failed_count=int,total_score=int,results=[]object}} "Receipt: queued for grading"
+// @Failure 400 {object} object{success=bool,error=string}
+// @Failure 401 {object} object{success=bool,error=string}
+// @Failure 403 {object} object{success=bool,error=string}
+// @Failure 404 {object} object{success=bool,error=string}
+// @Failure 500 {object} object{success=bool,error=string}
+// @Router /api/course-materials/{id}/submit-on-behalf [post]
+func (h *SubmissionHandler) SubmitOnBehalf(c *fiber.Ctx) error {
+	claims, ok := c.Locals("claims").(*types.Claims)
+	if !ok {
+		return response.SendUnauthorized(c, "Invalid authentication")
+	}
+	if !claims.IsTeacher {
+		return response.SendError(c, fiber.StatusForbidden, "Only teachers can submit on behalf of students")
+	}
+
+	materialID := c.Params("id")
+	if materialID == "" {
+		return response.SendBadRequest(c, "Material ID is required")
+	}
+	if err := h.submissionService.CheckSubmissionType(materialID, enums.MaterialTypeCodeExercise); err != nil {
+		return sendSubmissionTypeError(c, err)
+	}
+
+	var req struct {
+		StudentID string `json:"student_id" validate:"required"`
+		Code      string `json:"code" validate:"required"`
+	}
+
+	if err := c.BodyParser(&req); err != nil {
+		return response.SendBadRequest(c, "Invalid request body: "+err.Error())
+	}
+
+	if req.StudentID == "" {
+		return response.SendBadRequest(c, "Student ID is required")
+	}
+	if req.Code == "" {
+		return response.SendBadRequest(c, "Code is required")
+	}
+
+	result, err := h.submissionService.SubmitOnBehalf(claims.UserID, req.StudentID, materialID, req.Code)
+	if err != nil {
+		if errors.Is(err, services.ErrCourseArchived) {
+			return response.SendError(c, fiber.StatusForbidden, err.Error())
+		}
+		switch err.Error() {
+		case "material not found":
+			return response.SendNotFound(c, "Material not found")
+		case "only course creator can submit on behalf of students":
+			return response.SendError(c, fiber.StatusForbidden, "Only course creator can submit on behalf of students")
+		case "student is not enrolled in this course":
+			return response.SendBadRequest(c, "Student is not enrolled in this course")
+		}
+		return response.SendInternalError(c, "Failed to submit on behalf of student: "+err.Error())
+	}
+
 	submission := result.Submission.(*models.Submission)
+	data := submitResultData(result)
+	data["user_id"] = submission.UserID
+	data["submitted_by"] = submission.SubmittedBy
+
+	if result.Queued {
+		return response.SuccessResponse(c, fiber.StatusAccepted, "Material exercise submitted on behalf of student and queued for grading", data)
+	}
+	return response.SendSuccess(c, "Material exercise submitted on behalf of student successfully", data)
+}
+
+// sendSubmissionTypeError responds to a CheckSubmissionType error: 404 for a missing
+// material, 400 for a material that does not accept this kind of submission
+func sendSubmissionTypeError(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, services.ErrMaterialNotFound):
+		return response.SendNotFound(c, "Material not found")
+	case errors.Is(err, services.ErrNotCodeExercise), errors.Is(err, services.ErrNotPDFExercise):
+		return response.SendBadRequest(c, err.Error())
+	}
+	return response.SendInternalError(c, "Failed to get material: "+err.Error())
+}
 
-	return response.SendSuccess(c, "Material exercise submitted successfully", fiber.Map{
+// submitResultData builds the response for a code submission. Queued submissions are a
+// receipt: status is "processing", job_id names the queue job and the scores and results
+// stay empty until grading finishes (poll the submission or the queue job for them).
+func submitResultData(result *types.SubmitResult) fiber.Map {
+	submission := result.Submission.(*models.Submission)
+
+	data := fiber.Map{
 		"submission_id": submission.SubmissionID,
-		"status":        submission.Status,
+		"status":        string(submission.Status),
+		"queued":        result.Queued,
+		"job_id":        nil,
 		"passed_count":  submission.PassedCount,
 		"failed_count":  submission.FailedCount,
 		"total_score":   submission.TotalScore,
 		"results":       result.Results,
-	})
+	}
+	if result.Queued {
+		data["status"] = "processing"
+		data["job_id"] = result.JobID
+		// Held back by the exercise's concurrent submission cap
+		if result.QueuePosition > 0 {
+			data["status"] = "waiting"
+			data["queue_position"] = result.QueuePosition
+		}
+	}
+	return data
 }
 
 // GetMyMaterialSubmission godoc
@@ -297,7 +886,7 @@ func (h *SubmissionHandler) SubmitMaterialExercise(c *fiber.Ctx) error {
 // @Security BearerAuth
 // @Produce json
 // @Param id path string true "Material ID"
-// @Success 200 {object} object{success=bool,message=string,data=object{submission_id=string,user_id=string,material_id=string,code=string,passed_count=int,failed_count=int,total_score=int,status=string,error_message=string,submitted_at=string,results=[]object{result_id=string,test_case_id=string,status=string,actual_output=object,error_message=string}}}
+// @Success 200 {object} object{success=bool,message=string,data=object{submission_id=string,user_id=string,material_id=string,code=string,passed_count=int,failed_count=int,total_score=int,status=string,error_message=string,submitted_at=string,results=[]object{result_id=string,test_case_id=string,status=string,actual_output=object,error_message=string,execution_time_ms=int},slowest_result=object{test_case_id=string,execution_time_ms=int}}}
 // @Failure 400 {object} object{success=bool,error=string}
 // @Failure 401 {object} object{success=bool,error=string}
 // @Failure 500 {object} object{success=bool,error=string}
@@ -329,6 +918,40 @@ func (h *SubmissionHandler) GetMyMaterialSubmission(c *fiber.Ctx) error {
 	return h.sendSubmissionResponse(c, &sub)
 }
 
+// GetMySubmissions godoc
+// @Summary Get my submissions across courses
+// @Description List the current user's submissions in every course they are enrolled in, newest first, with the exercise title, course and status of each
+// @Tags submissions
+// @Security BearerAuth
+// @Produce json
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Items per page (max 100)" default(20)
+// @Success 200 {object} object{success=bool,data=object{submissions=[]types.UserSubmission,pagination=object{page=int,limit=int,total=int,total_pages=int}}} "Recent submissions"
+// @Failure 401 {object} object{success=bool,error=string}
+// @Failure 500 {object} object{success=bool,error=string}
+// @Router /api/users/me/submissions [get]
+func (h *SubmissionHandler) GetMySubmissions(c *fiber.Ctx) error {
+	claims, ok := c.Locals("claims").(*types.Claims)
+	if !ok {
+		return response.SendUnauthorized(c, "Invalid authentication")
+	}
+
+	page, limit := response.ParsePagination(c, 20, 0)
+
+	submissions, total, err := h.submissionService.GetUserRecentSubmissions(claims.UserID, page, limit)
+	if err != nil {
+		return response.SendInternalError(c, "Failed to get submissions: "+err.Error())
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data": fiber.Map{
+			"submissions": submissions,
+			"pagination":  response.Pagination(c, page, limit, total),
+		},
+	})
+}
+
 // SubmitPDFExercise godoc
 // @Summary Submit PDF exercise
 // @Description Submit a PDF file for a PDF exercise material
@@ -341,6 +964,8 @@ func (h *SubmissionHandler) GetMyMaterialSubmission(c *fiber.Ctx) error {
 // @Success 200 {object} object{success=bool,message=string,data=object{submission_id=string,file_url=string,file_name=string,file_size=int64,status=string,submitted_at=string}}
 // @Failure 400 {object} object{success=bool,error=string}
 // @Failure 401 {object} object{success=bool,error=string}
+// @Failure 403 {object} object{success=bool,error=string} "Deadline passed, outside the submission window, or exercise not available"
+// @Failure 409 {object} object{success=bool,error=string}
 // @Failure 413 {object} object{success=bool,error=string}
 // @Failure 415 {object} object{success=bool,error=string}
 // @Failure 500 {object} object{success=bool,error=string}
@@ -355,6 +980,9 @@ func (h *SubmissionHandler) SubmitPDFExercise(c *fiber.Ctx) error {
 	if materialID == "" {
 		return response.SendBadRequest(c, "Material ID is required")
 	}
+	if err := h.submissionService.CheckSubmissionType(materialID, enums.MaterialTypePDFExercise); err != nil {
+		return sendSubmissionTypeError(c, err)
+	}
 
 	// Get uploaded file
 	file, err := c.FormFile("file")
@@ -367,15 +995,29 @@ func (h *SubmissionHandler) SubmitPDFExercise(c *fiber.Ctx) error {
 		return response.SendError(c, fiber.StatusUnsupportedMediaType, "Only PDF files are allowed")
 	}
 
-	// Validate file size (10MB limit)
-	const maxFileSize = 10 * 1024 * 1024 // 10MB
-	if file.Size > maxFileSize {
-		return response.SendError(c, fiber.StatusRequestEntityTooLarge, "File size exceeds 10MB limit")
+	// Validate file size
+	if h.maxPDFSize > 0 && file.Size > h.maxPDFSize {
+		return response.SendError(c, fiber.StatusRequestEntityTooLarge, "File size exceeds "+validation.FormatFileSize(h.maxPDFSize)+" limit")
 	}
 
 	// Submit PDF exercise
 	submission, err := h.submissionService.SubmitPDFExercise(claims.UserID, materialID, file)
 	if err != nil {
+		if errors.Is(err, services.ErrPDFTooManyPages) {
+			return response.SendError(c, fiber.StatusRequestEntityTooLarge, err.Error())
+		}
+		if errors.Is(err, services.ErrSubmissionNotAllowed) {
+			return response.SendError(c, fiber.StatusForbidden, err.Error())
+		}
+		if errors.Is(err, services.ErrResubmissionLocked) {
+			return response.SendError(c, fiber.StatusConflict, err.Error())
+		}
+		if errors.Is(err, services.ErrAttemptLimitReached) {
+			return response.SendError(c, fiber.StatusConflict, err.Error())
+		}
+		if errors.Is(err, services.ErrPrerequisitesNotMet) {
+			return response.SendError(c, fiber.StatusForbidden, err.Error())
+		}
 		return response.SendInternalError(c, "Failed to submit PDF exercise: "+err.Error())
 	}
 
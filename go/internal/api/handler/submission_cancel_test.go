@@ -0,0 +1,73 @@
+package handler
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Project-DSView/backend/go/internal/application/services"
+	models "github.com/Project-DSView/backend/go/internal/domain/entities"
+	"github.com/Project-DSView/backend/go/internal/domain/enums"
+	"github.com/Project-DSView/backend/go/internal/types"
+	"github.com/Project-DSView/backend/go/pkg/external"
+	"github.com/gofiber/fiber/v2"
+)
+
+// TestCancelSubmissionRoute checks that the code cancel route leaves PDF submissions to
+// the PDF exercise route registered on the same path after it
+func TestCancelSubmissionRoute(t *testing.T) {
+	tests := []struct {
+		name         string
+		submissionID string
+		wantStatus   int
+		wantPDFRoute bool
+	}{
+		{name: "running code submission", submissionID: "code-sub", wantStatus: fiber.StatusOK},
+		{name: "graded code submission", submissionID: "graded-sub", wantStatus: fiber.StatusConflict},
+		{name: "PDF submission", submissionID: "pdf-sub", wantStatus: fiber.StatusOK, wantPDFRoute: true},
+		{name: "unknown submission", submissionID: "missing", wantStatus: fiber.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := newTestDB(t, &models.CourseMaterial{}, &models.Submission{}, &models.QueueJob{}, &models.StudentProgress{})
+			rows := []interface{}{
+				&models.CourseMaterial{MaterialID: "code-1", CourseID: "course-1", Type: enums.MaterialTypeCodeExercise},
+				&models.CourseMaterial{MaterialID: "pdf-1", CourseID: "course-1", Type: enums.MaterialTypePDFExercise},
+				&models.Submission{SubmissionID: "code-sub", UserID: "student-1", MaterialID: "code-1", Status: enums.SubmissionRunning},
+				&models.Submission{SubmissionID: "graded-sub", UserID: "student-1", MaterialID: "code-1", Status: enums.SubmissionCompleted},
+				&models.Submission{SubmissionID: "pdf-sub", UserID: "student-1", MaterialID: "pdf-1", Status: enums.SubmissionPending},
+			}
+			for _, row := range rows {
+				if err := db.Create(row).Error; err != nil {
+					t.Fatalf("create %T: %v", row, err)
+				}
+			}
+
+			svc := services.NewSubmissionService(db, nil, nil, nil, nil, nil, external.NewDockerExecutor(external.DockerConfig{}), nil, nil)
+			h := NewSubmissionHandler(svc, nil, nil, db)
+
+			pdfRoute := false
+			app := fiber.New()
+			app.Use(func(c *fiber.Ctx) error {
+				c.Locals("claims", &types.Claims{UserID: "student-1"})
+				return c.Next()
+			})
+			app.Delete("/api/submissions/:id/cancel", h.CancelSubmission)
+			app.Delete("/api/submissions/:submission_id/cancel", func(c *fiber.Ctx) error {
+				pdfRoute = true
+				return c.SendStatus(fiber.StatusOK)
+			})
+
+			resp, err := app.Test(httptest.NewRequest(fiber.MethodDelete, "/api/submissions/"+tt.submissionID+"/cancel", nil))
+			if err != nil {
+				t.Fatalf("request: %v", err)
+			}
+			if resp.StatusCode != tt.wantStatus {
+				t.Errorf("status = %d, want %d", resp.StatusCode, tt.wantStatus)
+			}
+			if pdfRoute != tt.wantPDFRoute {
+				t.Errorf("PDF route reached = %v, want %v", pdfRoute, tt.wantPDFRoute)
+			}
+		})
+	}
+}
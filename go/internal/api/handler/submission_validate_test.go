@@ -0,0 +1,98 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Project-DSView/backend/go/internal/application/services"
+	models "github.com/Project-DSView/backend/go/internal/domain/entities"
+	"github.com/Project-DSView/backend/go/internal/domain/enums"
+	"github.com/Project-DSView/backend/go/internal/types"
+	"github.com/Project-DSView/backend/go/pkg/external"
+	"github.com/gofiber/fiber/v2"
+)
+
+// TestValidateCode checks that only users who can see the exercise may run the compile
+// check, and that its diagnostics come from the compile step
+func TestValidateCode(t *testing.T) {
+	tests := []struct {
+		name       string
+		userID     string
+		wantStatus int
+		wantDocker bool
+	}{
+		{name: "course owner", userID: "teacher-1", wantStatus: fiber.StatusOK, wantDocker: true},
+		{name: "enrolled student", userID: "student-1", wantStatus: fiber.StatusOK, wantDocker: true},
+		{name: "not enrolled", userID: "outsider", wantStatus: fiber.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// The fake docker fails the compile check the way Python reports a syntax error
+			dir := t.TempDir()
+			ranFile := filepath.Join(dir, "ran")
+			script := "#!/bin/sh\ncat >/dev/null\ntouch \"$FAKE_DOCKER_RAN\"\n" +
+				"printf '  File \"submission.py\", line 1\\n    print(\\n         ^\\nSyntaxError: bad\\n' >&2\nexit 1\n"
+			if err := os.WriteFile(filepath.Join(dir, "docker"), []byte(script), 0o755); err != nil {
+				t.Fatalf("write fake docker: %v", err)
+			}
+			t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+			t.Setenv("FAKE_DOCKER_RAN", ranFile)
+
+			db := newTestDB(t, &models.Course{}, &models.Enrollment{}, &models.CourseMaterial{})
+			rows := []interface{}{
+				&models.Course{CourseID: "course-1", Name: "Data Structures", CreatedBy: "teacher-1"},
+				&models.Enrollment{CourseID: "course-1", UserID: "student-1", Role: enums.EnrollmentRoleStudent},
+				&models.CourseMaterial{MaterialID: "code-1", CourseID: "course-1", Type: enums.MaterialTypeCodeExercise},
+			}
+			for _, row := range rows {
+				if err := db.Create(row).Error; err != nil {
+					t.Fatalf("create %T: %v", row, err)
+				}
+			}
+
+			svc := services.NewSubmissionService(db, nil, nil, nil, nil, nil, external.NewDockerExecutor(external.DockerConfig{}), nil, nil)
+			h := NewSubmissionHandler(svc, nil, nil, db)
+			app := fiber.New()
+			app.Use(func(c *fiber.Ctx) error {
+				c.Locals("claims", &types.Claims{UserID: tt.userID})
+				return c.Next()
+			})
+			app.Post("/api/course-materials/:id/validate", h.ValidateCode)
+
+			req := httptest.NewRequest(fiber.MethodPost, "/api/course-materials/code-1/validate", strings.NewReader(`{"code":"print("}`))
+			req.Header.Set("Content-Type", "application/json")
+			resp, err := app.Test(req)
+			if err != nil {
+				t.Fatalf("request: %v", err)
+			}
+			if resp.StatusCode != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", resp.StatusCode, tt.wantStatus)
+			}
+			if _, err := os.Stat(ranFile); (err == nil) != tt.wantDocker {
+				t.Errorf("docker ran = %v, want %v", err == nil, tt.wantDocker)
+			}
+			if resp.StatusCode != fiber.StatusOK {
+				return
+			}
+
+			var body struct {
+				Data struct {
+					Valid       bool                  `json:"valid"`
+					Diagnostics []external.Diagnostic `json:"diagnostics"`
+				} `json:"data"`
+			}
+			if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+				t.Fatalf("decode response: %v", err)
+			}
+			want := []external.Diagnostic{{Line: 1, Column: 6, Message: "bad"}}
+			if body.Data.Valid || len(body.Data.Diagnostics) != 1 || body.Data.Diagnostics[0] != want[0] {
+				t.Errorf("valid = %v, diagnostics = %+v, want %+v", body.Data.Valid, body.Data.Diagnostics, want)
+			}
+		})
+	}
+}
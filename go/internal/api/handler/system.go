@@ -6,20 +6,64 @@ import (
 	"net/http"
 	"time"
 
+	"github.com/Project-DSView/backend/go/internal/application/services"
 	"github.com/Project-DSView/backend/go/internal/infrastructure/config"
+	"github.com/Project-DSView/backend/go/internal/types"
+	"github.com/Project-DSView/backend/go/pkg/response"
+	"github.com/Project-DSView/backend/go/pkg/storage"
 	"github.com/gofiber/fiber/v2"
 	"gorm.io/gorm"
 )
 
 type SystemHandler struct {
-	db  *gorm.DB
-	cfg *config.Config
+	db             *gorm.DB
+	cfg            *config.Config
+	queueService   *services.QueueService
+	storageService storage.StorageService
+	scheduler      ScheduledTaskLister
+}
+
+// ScheduledTaskLister reports the periodic background tasks (see setup.Scheduler)
+type ScheduledTaskLister interface {
+	Tasks() []types.ScheduledTaskStatus
 }
 
 func NewSystemHandler(db *gorm.DB, cfg *config.Config) *SystemHandler {
 	return &SystemHandler{db: db, cfg: cfg}
 }
 
+// SetQueueService sets the queue service used to report broker connection health
+func (h *SystemHandler) SetQueueService(queueService *services.QueueService) {
+	h.queueService = queueService
+}
+
+// SetStorageService sets the storage service checked when the local storage backend is used
+func (h *SystemHandler) SetStorageService(storageService storage.StorageService) {
+	h.storageService = storageService
+}
+
+// SetScheduler sets the scheduler whose tasks are listed by GetScheduledTasks
+func (h *SystemHandler) SetScheduler(scheduler ScheduledTaskLister) {
+	h.scheduler = scheduler
+}
+
+// GetScheduledTasks godoc
+// @Summary List scheduled tasks
+// @Description List the periodic background tasks with their interval and the status, time, duration and error of their last run. Operator endpoint, requires the service API key.
+// @Tags admin
+// @Security ApiKeyAuth
+// @Produce json
+// @Success 200 {object} object{success=bool,data=object{tasks=[]types.ScheduledTaskStatus}} "Scheduled tasks"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Router /api/admin/scheduler/tasks [get]
+func (h *SystemHandler) GetScheduledTasks(c *fiber.Ctx) error {
+	tasks := []types.ScheduledTaskStatus{}
+	if h.scheduler != nil {
+		tasks = h.scheduler.Tasks()
+	}
+	return response.SendSuccess(c, "Scheduled tasks retrieved successfully", fiber.Map{"tasks": tasks})
+}
+
 // HealthCheck godoc
 // @Summary Health probe
 // @Description Check if the service process is running and dependencies are reachable
@@ -47,21 +91,30 @@ func (h *SystemHandler) HealthCheck(c *fiber.Ctx) error {
 	}
 	deps["postgres"] = dbCheck
 
-	// 2. Check MinIO
-	minioCheck := "ok"
-	minioUrl := "http://" + h.cfg.MinIO.Endpoint + "/minio/health/live"
-	if h.cfg.MinIO.UseSSL {
-		minioUrl = "https://" + h.cfg.MinIO.Endpoint + "/minio/health/live"
-	}
-	reqMinio, _ := http.NewRequestWithContext(ctx, "GET", minioUrl, nil)
+	// 2. Check file storage: MinIO, or the storage directory when running without it
 	client := &http.Client{}
-	if resp, err := client.Do(reqMinio); err != nil || resp.StatusCode != 200 {
-		minioCheck = "down"
-		hasError = true
+	if h.cfg.Storage.Backend == config.StorageBackendLocal && h.storageService != nil {
+		storageCheck := "ok"
+		if err := h.storageService.HealthCheck(ctx); err != nil {
+			storageCheck = "down"
+			hasError = true
+		}
+		deps["local_storage"] = storageCheck
 	} else {
-		resp.Body.Close()
+		minioCheck := "ok"
+		minioUrl := "http://" + h.cfg.MinIO.Endpoint + "/minio/health/live"
+		if h.cfg.MinIO.UseSSL {
+			minioUrl = "https://" + h.cfg.MinIO.Endpoint + "/minio/health/live"
+		}
+		reqMinio, _ := http.NewRequestWithContext(ctx, "GET", minioUrl, nil)
+		if resp, err := client.Do(reqMinio); err != nil || resp.StatusCode != 200 {
+			minioCheck = "down"
+			hasError = true
+		} else {
+			resp.Body.Close()
+		}
+		deps["minio"] = minioCheck
 	}
-	deps["minio"] = minioCheck
 
 	// 3. Check RabbitMQ (Management API ping)
 	rabbitCheck := "ok"
@@ -107,3 +160,54 @@ func (h *SystemHandler) HealthCheck(c *fiber.Ctx) error {
 		"dependencies": deps,
 	})
 }
+
+// ReadinessCheck godoc
+// @Summary Readiness probe
+// @Description Check if the instance can serve traffic: Postgres is reachable and the RabbitMQ connection is not being recovered
+// @Tags system
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Failure 503 {object} map[string]interface{}
+// @Router /readyz [get]
+func (h *SystemHandler) ReadinessCheck(c *fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	deps := fiber.Map{}
+	ready := true
+
+	dbCheck := "ok"
+	sqlDB, err := h.db.DB()
+	if err != nil {
+		dbCheck = "down"
+		ready = false
+	} else if err := sqlDB.PingContext(ctx); err != nil {
+		dbCheck = "down"
+		ready = false
+	}
+	deps["postgres"] = dbCheck
+
+	// A broker that was never configured is not fatal (the app runs without
+	// queues), but a dropped connection that is still recovering is
+	brokerCheck := "disabled"
+	if h.queueService != nil {
+		brokerCheck = h.queueService.BrokerStatus()
+	}
+	if brokerCheck == "reconnecting" {
+		ready = false
+	}
+	deps["rabbitmq"] = brokerCheck
+
+	status := "ready"
+	statusCode := fiber.StatusOK
+	if !ready {
+		status = "not_ready"
+		statusCode = fiber.StatusServiceUnavailable
+	}
+
+	return c.Status(statusCode).JSON(fiber.Map{
+		"status":       status,
+		"timestamp":    time.Now().UTC().Format(time.RFC3339),
+		"dependencies": deps,
+	})
+}
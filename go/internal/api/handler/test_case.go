@@ -2,6 +2,7 @@ package handler
 
 import (
 	"encoding/json"
+	stderrors "errors"
 
 	"github.com/Project-DSView/backend/go/internal/application/services"
 	models "github.com/Project-DSView/backend/go/internal/domain/entities"
@@ -128,6 +129,9 @@ func (h *TestCaseHandler) CreateTestCase(c *fiber.Ctx) error {
 	}
 
 	if err := h.testCaseService.CreateTestCase(&testCase); err != nil {
+		if stderrors.Is(err, services.ErrTestCaseTooLarge) {
+			return response.SendBadRequest(c, err.Error())
+		}
 		return response.SendInternalError(c, "Failed to create test case: "+err.Error())
 	}
 
@@ -196,6 +200,9 @@ func (h *TestCaseHandler) UpdateTestCase(c *fiber.Ctx) error {
 
 	// Update test case
 	if err := h.testCaseService.UpdateTestCase(testCaseID, updates); err != nil {
+		if stderrors.Is(err, services.ErrTestCaseTooLarge) {
+			return response.SendBadRequest(c, err.Error())
+		}
 		return response.SendInternalError(c, "Failed to update test case: "+err.Error())
 	}
 
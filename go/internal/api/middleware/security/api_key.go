@@ -116,3 +116,51 @@ func APIKeyAndJWTAuth(cfg *config.Config, jwtService *services.JWTService) fiber
 		return c.Next()
 	}
 }
+
+// CourseAPIKeyAuth middleware authenticates course-scoped API keys (e.g. from an LMS).
+// Such keys are read-only and may only reach the course named by the :id route param,
+// so it must be attached to each route rather than a group's Use.
+func CourseAPIKeyAuth(cfg *config.Config, keyService *services.CourseAPIKeyService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		apiKey := c.Get(cfg.APIKey.APIKeyName)
+		if apiKey == "" {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+				"error":  "API key is required",
+				"header": cfg.APIKey.APIKeyName,
+			})
+		}
+
+		if !services.IsCourseAPIKey(apiKey) {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+				"error": "Invalid API key",
+			})
+		}
+
+		key, err := keyService.ValidateKey(apiKey)
+		if err != nil {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+				"error": "Invalid API key: " + err.Error(),
+			})
+		}
+
+		// Course-scoped keys are read-only
+		if c.Method() != fiber.MethodGet && c.Method() != fiber.MethodHead {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"error": "API key is read-only",
+			})
+		}
+
+		courseID := c.Params("id")
+		if courseID == "" || !key.CanAccessCourse(courseID) {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"error": "API key is not allowed to access this course",
+			})
+		}
+
+		c.Locals("course_api_key", key)
+		c.Locals("auth_type", "course_api_key")
+		c.Locals("api_key_name", cfg.APIKey.APIKeyName)
+
+		return c.Next()
+	}
+}
@@ -0,0 +1,73 @@
+package security
+
+import (
+	"fmt"
+
+	"github.com/Project-DSView/backend/go/pkg/validation"
+	"github.com/gofiber/fiber/v2"
+)
+
+// MultipartOverhead is the room allowed on top of a file size limit for the multipart
+// boundaries and the other form fields sent with the file
+const MultipartOverhead = 1024 * 1024
+
+// MaxUploadSize rejects upload requests whose body exceeds maxFileSize (plus multipart
+// overhead) with 413, and advertises the limit in the X-Max-File-Size response header.
+// The declared Content-Length is checked first so that oversized requests are refused
+// without parsing the multipart form; the server-wide BodyLimit caps the transfer itself.
+func MaxUploadSize(maxFileSize int64) fiber.Handler {
+	limit := maxFileSize + MultipartOverhead
+
+	return func(c *fiber.Ctx) error {
+		c.Set("X-Max-File-Size", fmt.Sprintf("%d", maxFileSize))
+
+		size := int64(c.Request().Header.ContentLength())
+		if size < 0 {
+			// Chunked request without Content-Length
+			size = int64(len(c.Request().Body()))
+		}
+
+		if size > limit {
+			return c.Status(fiber.StatusRequestEntityTooLarge).JSON(fiber.Map{
+				"success":   false,
+				"error":     fmt.Sprintf("File too large. Maximum file size is %s", validation.FormatFileSize(maxFileSize)),
+				"max_bytes": maxFileSize,
+			})
+		}
+
+		return c.Next()
+	}
+}
+
+// BodyLimit enforces the server-wide body limit on streamed request bodies, which the
+// server only checks for bodies it reads in full, and reads bodies up to memoryLimit into
+// memory. Larger bodies are left on the connection, so a multipart form is parsed as it
+// arrives and its file parts go to temporary files instead of being held in memory.
+// Chunked bodies are refused, since their size is not known until they are read.
+// Requests without a body (GET, HEAD, or no Content-Length) pass through.
+func BodyLimit(maxBodySize, memoryLimit int64) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		size := int64(c.Request().Header.ContentLength())
+		// fasthttp reports -2 when there is neither a Content-Length nor chunked encoding,
+		// which for a request means no body; GET and HEAD carry none either
+		if size == -2 || c.Method() == fiber.MethodGet || c.Method() == fiber.MethodHead {
+			size = 0
+		}
+		switch {
+		case size == -1:
+			return c.Status(fiber.StatusLengthRequired).JSON(fiber.Map{
+				"success": false,
+				"error":   "Content-Length is required",
+			})
+		case size > maxBodySize:
+			return c.Status(fiber.StatusRequestEntityTooLarge).JSON(fiber.Map{
+				"success":   false,
+				"error":     fmt.Sprintf("Request too large. Maximum size is %s", validation.FormatFileSize(maxBodySize)),
+				"max_bytes": maxBodySize,
+			})
+		case size <= memoryLimit:
+			c.Request().Body() // reads the body into memory
+		}
+		return c.Next()
+	}
+}
@@ -0,0 +1,59 @@
+package security
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestBodyLimit(t *testing.T) {
+	const maxBodySize, memoryLimit = 1024, 256
+
+	tests := []struct {
+		name          string
+		method        string
+		body          string
+		contentLength int64 // -1 sends the body chunked
+		want          int
+	}{
+		{"get without body", http.MethodGet, "", 0, fiber.StatusOK},
+		{"head without body", http.MethodHead, "", 0, fiber.StatusOK},
+		{"delete without body", http.MethodDelete, "", 0, fiber.StatusOK},
+		{"small post read into memory", http.MethodPost, "hello", 5, fiber.StatusOK},
+		{"post streamed above the memory limit", http.MethodPost, strings.Repeat("a", 512), 512, fiber.StatusOK},
+		{"oversized upload", http.MethodPost, strings.Repeat("a", 2048), 2048, fiber.StatusRequestEntityTooLarge},
+		{"chunked upload", http.MethodPost, "hello", -1, fiber.StatusLengthRequired},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := fiber.New()
+			app.Use(BodyLimit(maxBodySize, memoryLimit))
+			app.All("/", func(c *fiber.Ctx) error {
+				return c.SendStatus(fiber.StatusOK)
+			})
+
+			var body io.Reader
+			if tt.body != "" {
+				body = strings.NewReader(tt.body)
+			}
+			req := httptest.NewRequest(tt.method, "/", body)
+			req.ContentLength = tt.contentLength
+			if tt.contentLength < 0 {
+				req.TransferEncoding = []string{"chunked"}
+			}
+
+			resp, err := app.Test(req)
+			if err != nil {
+				t.Fatalf("request failed: %v", err)
+			}
+			if resp.StatusCode != tt.want {
+				t.Errorf("status = %d, want %d", resp.StatusCode, tt.want)
+			}
+		})
+	}
+}
@@ -1,6 +1,9 @@
 package security
 
 import (
+	"net/url"
+	"strings"
+
 	"github.com/gofiber/fiber/v2"
 )
 
@@ -36,7 +39,7 @@ func CORSHeaders() fiber.Handler {
 		if c.Method() == "OPTIONS" {
 			c.Set("Access-Control-Allow-Origin", "*")
 			c.Set("Access-Control-Allow-Methods", "GET,POST,PUT,DELETE,OPTIONS")
-			c.Set("Access-Control-Allow-Headers", "Origin,Content-Type,Accept,Authorization,Cookie,X-Requested-With,X-CSRF-Token,dsview-api-key")
+			c.Set("Access-Control-Allow-Headers", "Origin,Content-Type,Accept,Accept-Version,Authorization,Cookie,X-Requested-With,X-CSRF-Token,dsview-api-key")
 			c.Set("Access-Control-Max-Age", "86400")
 			return c.SendStatus(204)
 		}
@@ -44,3 +47,50 @@ func CORSHeaders() fiber.Handler {
 		return c.Next()
 	}
 }
+
+// SetStreamingCORSHeaders sets CORS headers for credentialed file streaming responses.
+// The request origin (or the origin derived from Referer) is echoed back with
+// Allow-Credentials only when it is in allowedOrigins. Requests without an origin
+// get a wildcard without credentials; disallowed origins get no Allow-Origin at all.
+func SetStreamingCORSHeaders(c *fiber.Ctx, allowedOrigins []string) {
+	origin := c.Get("Origin")
+	if origin == "" {
+		origin = originFromReferer(c.Get("Referer"))
+	}
+
+	c.Set("Vary", "Origin")
+	switch {
+	case origin == "":
+		c.Set("Access-Control-Allow-Origin", "*")
+	case isOriginAllowed(origin, allowedOrigins):
+		// Cannot use "*" with Allow-Credentials, so echo the specific origin
+		c.Set("Access-Control-Allow-Origin", origin)
+		c.Set("Access-Control-Allow-Credentials", "true")
+	default:
+		return
+	}
+	c.Set("Access-Control-Allow-Methods", "GET, OPTIONS")
+	c.Set("Access-Control-Allow-Headers", "Origin, Content-Type, Accept, Authorization, Cookie, X-Requested-With, X-CSRF-Token, dsview-api-key")
+}
+
+// originFromReferer extracts "scheme://host" from a Referer URL
+func originFromReferer(referer string) string {
+	if referer == "" {
+		return ""
+	}
+	u, err := url.Parse(referer)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return ""
+	}
+	return u.Scheme + "://" + u.Host
+}
+
+// isOriginAllowed reports whether origin exactly matches one of allowedOrigins
+func isOriginAllowed(origin string, allowedOrigins []string) bool {
+	for _, allowed := range allowedOrigins {
+		if strings.TrimSpace(allowed) == origin {
+			return true
+		}
+	}
+	return false
+}
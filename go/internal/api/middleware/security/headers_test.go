@@ -0,0 +1,89 @@
+package security
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestSetStreamingCORSHeaders(t *testing.T) {
+	allowed := []string{"https://dsview.example.com", " http://localhost:3000 "}
+
+	tests := []struct {
+		name            string
+		origin          string
+		referer         string
+		wantOrigin      string
+		wantCredentials string
+		wantMethods     bool
+	}{
+		{"allowed origin", "https://dsview.example.com", "", "https://dsview.example.com", "true", true},
+		{"allowed origin with padded config", "http://localhost:3000", "", "http://localhost:3000", "true", true},
+		{"allowed origin from referer", "", "https://dsview.example.com/courses/1/pdf", "https://dsview.example.com", "true", true},
+		{"disallowed origin", "https://evil.example.com", "", "", "", false},
+		{"disallowed referer", "", "https://evil.example.com/page", "", "", false},
+		{"origin differing only by port", "https://dsview.example.com:8443", "", "", "", false},
+		{"missing origin and referer", "", "", "*", "", true},
+		{"unparsable referer", "", "not a url", "*", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := fiber.New()
+			app.Get("/", func(c *fiber.Ctx) error {
+				SetStreamingCORSHeaders(c, allowed)
+				return c.SendStatus(fiber.StatusOK)
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.origin != "" {
+				req.Header.Set("Origin", tt.origin)
+			}
+			if tt.referer != "" {
+				req.Header.Set("Referer", tt.referer)
+			}
+
+			resp, err := app.Test(req)
+			if err != nil {
+				t.Fatalf("request failed: %v", err)
+			}
+			if got := resp.Header.Get("Access-Control-Allow-Origin"); got != tt.wantOrigin {
+				t.Errorf("Allow-Origin = %q, want %q", got, tt.wantOrigin)
+			}
+			if got := resp.Header.Get("Access-Control-Allow-Credentials"); got != tt.wantCredentials {
+				t.Errorf("Allow-Credentials = %q, want %q", got, tt.wantCredentials)
+			}
+			if got := resp.Header.Get("Access-Control-Allow-Methods") != ""; got != tt.wantMethods {
+				t.Errorf("Allow-Methods set = %v, want %v", got, tt.wantMethods)
+			}
+			if got := resp.Header.Get("Vary"); got != "Origin" {
+				t.Errorf("Vary = %q, want %q", got, "Origin")
+			}
+		})
+	}
+}
+
+func TestIsOriginAllowed(t *testing.T) {
+	tests := []struct {
+		name    string
+		origin  string
+		allowed []string
+		want    bool
+	}{
+		{"exact match", "https://a.example.com", []string{"https://a.example.com"}, true},
+		{"match after trimming", "https://a.example.com", []string{"  https://a.example.com  "}, true},
+		{"different scheme", "http://a.example.com", []string{"https://a.example.com"}, false},
+		{"prefix is not a match", "https://a.example.com.evil.io", []string{"https://a.example.com"}, false},
+		{"empty list", "https://a.example.com", nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isOriginAllowed(tt.origin, tt.allowed); got != tt.want {
+				t.Errorf("isOriginAllowed(%q) = %v, want %v", tt.origin, got, tt.want)
+			}
+		})
+	}
+}
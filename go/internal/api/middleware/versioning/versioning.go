@@ -0,0 +1,35 @@
+package versioning
+
+import (
+	"strings"
+
+	"github.com/Project-DSView/backend/go/pkg/apiversion"
+	"github.com/gofiber/fiber/v2"
+)
+
+// Negotiate picks the response shape version from the Accept-Version header, Current
+// when it is absent, and reports it with the server build on every response. A version
+// the server cannot serve is rejected with 406 and the supported versions.
+func Negotiate() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		c.Set(apiversion.BuildHeader, apiversion.Build)
+		c.Append(fiber.HeaderVary, apiversion.RequestHeader)
+
+		version := strings.TrimPrefix(strings.TrimSpace(c.Get(apiversion.RequestHeader)), "v")
+		if version == "" {
+			version = apiversion.Current
+		}
+		if !apiversion.IsSupported(version) {
+			c.Set(apiversion.ResponseHeader, apiversion.Current)
+			return c.Status(fiber.StatusNotAcceptable).JSON(fiber.Map{
+				"success":            false,
+				"message":            "Unsupported API version: " + version,
+				"supported_versions": apiversion.Supported,
+			})
+		}
+
+		apiversion.SetContext(c, version)
+		c.Set(apiversion.ResponseHeader, version)
+		return c.Next()
+	}
+}
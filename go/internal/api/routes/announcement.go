@@ -28,7 +28,9 @@ func SetupAnnouncementRoutes(
 	announcementGroup.Get("/recent", announcementHandler.GetRecentAnnouncements) // GET /api/announcements/recent
 
 	// POST/PUT/DELETE routes (teachers only)
-	announcementGroup.Post("/", announcementHandler.CreateAnnouncement)      // POST /api/announcements
-	announcementGroup.Put("/:id", announcementHandler.UpdateAnnouncement)    // PUT /api/announcements/:id
-	announcementGroup.Delete("/:id", announcementHandler.DeleteAnnouncement) // DELETE /api/announcements/:id
+	announcementGroup.Post("/", announcementHandler.CreateAnnouncement)         // POST /api/announcements
+	announcementGroup.Put("/:id", announcementHandler.UpdateAnnouncement)       // PUT /api/announcements/:id
+	announcementGroup.Delete("/:id", announcementHandler.DeleteAnnouncement)    // DELETE /api/announcements/:id
+	announcementGroup.Put("/:id/pin", announcementHandler.PinAnnouncement)      // PUT /api/announcements/:id/pin
+	announcementGroup.Delete("/:id/pin", announcementHandler.UnpinAnnouncement) // DELETE /api/announcements/:id/pin
 }
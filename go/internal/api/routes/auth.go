@@ -16,10 +16,11 @@ func SetupAuthRoutes(
 	oauthService *services.OAuthService,
 	jwtService *services.JWTService,
 	userService *services.UserService,
+	sessionService *services.SessionService,
 	storageService storage.StorageService,
 ) {
 	// Create auth handler
-	authHandler := handler.NewAuthHandler(oauthService, jwtService, userService, &cfg.Frontend, storageService)
+	authHandler := handler.NewAuthHandler(oauthService, jwtService, userService, sessionService, &cfg.Frontend, storageService)
 
 	// Test routes group
 	testGroup := app.Group("/test")
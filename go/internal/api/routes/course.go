@@ -8,6 +8,7 @@ import (
 	"github.com/Project-DSView/backend/go/internal/application/services"
 	"github.com/Project-DSView/backend/go/internal/infrastructure/config"
 	"github.com/Project-DSView/backend/go/pkg/storage"
+	"gorm.io/gorm"
 )
 
 func SetupCourseRoutes(
@@ -21,9 +22,10 @@ func SetupCourseRoutes(
 	invitationService *services.InvitationService,
 	queueService *services.QueueService,
 	storageService storage.StorageService,
+	db *gorm.DB,
 ) {
 	// Create handlers
-	courseHandler := handler.NewCourseHandler(courseService, courseMaterialService, userService, enrollmentService, queueService, storageService)
+	courseHandler := handler.NewCourseHandler(courseService, courseMaterialService, userService, enrollmentService, queueService, storageService, db)
 	enrollmentHandler := handler.NewEnrollmentHandler(enrollmentService, userService, courseService)
 	invitationHandler := handler.NewInvitationHandler(invitationService, userService)
 
@@ -34,18 +36,31 @@ func SetupCourseRoutes(
 	courseGroup.Use(security.APIKeyAndJWTAuth(cfg, jwtService))
 
 	// Course management routes
-	courseGroup.Get("/", courseHandler.GetCourses)         // GET /api/courses
-	courseGroup.Post("/", courseHandler.CreateCourse)      // POST /api/courses
-	courseGroup.Get("/:id", courseHandler.GetCourse)       // GET /api/courses/:id
-	courseGroup.Put("/:id", courseHandler.UpdateCourse)    // PUT /api/courses/:id
-	courseGroup.Delete("/:id", courseHandler.DeleteCourse) // DELETE /api/courses/:id
+	courseGroup.Get("/", courseHandler.GetCourses)                           // GET /api/courses
+	courseGroup.Get("/discover", courseHandler.DiscoverCourses)              // GET /api/courses/discover
+	courseGroup.Post("/", courseHandler.CreateCourse)                        // POST /api/courses
+	courseGroup.Get("/:id", courseHandler.GetCourse)                         // GET /api/courses/:id
+	courseGroup.Put("/:id", courseHandler.UpdateCourse)                      // PUT /api/courses/:id
+	courseGroup.Delete("/:id", courseHandler.DeleteCourse)                   // DELETE /api/courses/:id
+	courseGroup.Post("/:id/copy", courseHandler.CopyCourse)                  // POST /api/courses/:id/copy
+	courseGroup.Post("/:id/transfer", courseHandler.TransferCourseOwnership) // POST /api/courses/:id/transfer
+
+	// Course settings routes
+	courseGroup.Get("/:id/settings", courseHandler.GetCourseSettings)            // GET /api/courses/:id/settings
+	courseGroup.Put("/:id/settings", courseHandler.UpdateCourseSettings)         // PUT /api/courses/:id/settings
+	courseGroup.Get("/:id/material-defaults", courseHandler.GetMaterialDefaults) // GET /api/courses/:id/material-defaults
 
 	// Course exercise routes
 	courseGroup.Get("/:id/exercises", courseHandler.GetCourseExercises) // GET /api/courses/:id/exercises
+	courseGroup.Get("/:id/weeks", courseHandler.GetCourseWeeks)         // GET /api/courses/:id/weeks
+	courseGroup.Get("/:id/tags", courseHandler.GetCourseTags)           // GET /api/courses/:id/tags
+	courseGroup.Get("/:id/calendar", courseHandler.GetDeadlineCalendar) // GET /api/courses/:id/calendar
 
 	// Course report routes
 	courseGroup.Get("/:id/report/teacher", courseHandler.GetCourseReportForTeacher) // GET /api/courses/:id/report/teacher
 	courseGroup.Get("/:id/report/ta", courseHandler.GetCourseReportForTA)           // GET /api/courses/:id/report/ta
+	courseGroup.Get("/:id/stats", courseHandler.GetCourseStats)                     // GET /api/courses/:id/stats
+	courseGroup.Get("/:id/queue/export", courseHandler.ExportQueueJobs)             // GET /api/courses/:id/queue/export
 
 	// Course image management routes
 	courseGroup.Delete("/:id/image", courseHandler.DeleteCourseImage) // DELETE /api/courses/:id/image
@@ -72,6 +87,13 @@ func SetupCourseRoutes(
 	enrollmentGroup.Get("/courses/:id", enrollmentHandler.GetCourseEnrollments)  // GET /api/enrollments/courses/:id
 	enrollmentGroup.Delete("/courses/:id", enrollmentHandler.UnenrollFromCourse) // DELETE /api/enrollments/courses/:id
 
+	// Current user's courses
+	userGroup := app.Group("/api/users")
+	userGroup.Use(security.APIKeyAndJWTAuth(cfg, jwtService))
+	userGroup.Get("/me/courses", enrollmentHandler.GetMyCourses)                  // GET /api/users/me/courses
+	userGroup.Get("/me/instructor-overview", courseHandler.GetInstructorOverview) // GET /api/users/me/instructor-overview
+	userGroup.Get("/me/calendar", courseHandler.GetMyDeadlineCalendar)            // GET /api/users/me/calendar
+
 	// Invitation routes (separate group for public invitation endpoint)
 	invitationGroup := app.Group("/api/courses/invite")
 	invitationGroup.Use(security.APIKeyAndJWTAuth(cfg, jwtService))
@@ -0,0 +1,33 @@
+package routes
+
+import (
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/Project-DSView/backend/go/internal/api/handler"
+	"github.com/Project-DSView/backend/go/internal/api/middleware/security"
+	"github.com/Project-DSView/backend/go/internal/application/services"
+	"github.com/Project-DSView/backend/go/internal/infrastructure/config"
+)
+
+func SetupCourseAPIKeyRoutes(
+	app *fiber.App,
+	cfg *config.Config,
+	keyHandler *handler.CourseAPIKeyHandler,
+	keyService *services.CourseAPIKeyService,
+	jwtService *services.JWTService,
+) {
+	// Key management routes (teachers, JWT required)
+	keyGroup := app.Group("/api/course-api-keys")
+	keyGroup.Use(security.APIKeyAndJWTAuth(cfg, jwtService))
+	keyGroup.Post("/", keyHandler.CreateAPIKey)      // POST /api/course-api-keys
+	keyGroup.Get("/", keyHandler.ListAPIKeys)        // GET /api/course-api-keys
+	keyGroup.Delete("/:id", keyHandler.RevokeAPIKey) // DELETE /api/course-api-keys/:id
+
+	// LMS read-only routes authenticated by a course-scoped API key.
+	// The middleware is attached per route so it can see the :id param.
+	lmsGroup := app.Group("/api/lms")
+	courseKeyAuth := security.CourseAPIKeyAuth(cfg, keyService)
+	lmsGroup.Get("/courses/:id", courseKeyAuth, keyHandler.GetLMSCourse)                        // GET /api/lms/courses/:id
+	lmsGroup.Get("/courses/:id/enrollments", courseKeyAuth, keyHandler.GetLMSCourseEnrollments) // GET /api/lms/courses/:id/enrollments
+	lmsGroup.Get("/courses/:id/scores", courseKeyAuth, keyHandler.GetLMSCourseScores)           // GET /api/lms/courses/:id/scores
+}
@@ -23,23 +23,41 @@ func SetupCourseMaterialRoutes(
 	materialGroup.Use(security.APIKeyAndJWTAuth(cfg, jwtService))
 
 	// GET routes with enrollment validation
-	materialGroup.Get("/", materialHandler.GetCourseMaterials)   // GET /api/course-materials?course_id=xxx
-	materialGroup.Get("/:id", materialHandler.GetCourseMaterial) // GET /api/course-materials/:id
+	materialGroup.Get("/", materialHandler.GetCourseMaterials)               // GET /api/course-materials?course_id=xxx
+	materialGroup.Get("/:id", materialHandler.GetCourseMaterial)             // GET /api/course-materials/:id
+	materialGroup.Get("/:id/preview", materialHandler.PreviewCourseMaterial) // GET /api/course-materials/:id/preview
+	materialGroup.Get("/:id/edit", materialHandler.GetCourseMaterialForEdit) // GET /api/course-materials/:id/edit (creator only)
+	materialGroup.Post("/batch", materialHandler.GetCourseMaterialsBatch)    // POST /api/course-materials/batch
+
+	// Engagement of documents and videos
+	materialGroup.Get("/:id/engagement", materialHandler.GetMaterialEngagement) // GET /api/course-materials/:id/engagement
+	materialGroup.Post("/:id/engagement", materialHandler.RecordMaterialAccess) // POST /api/course-materials/:id/engagement
+
+	// Creation and generic upload accept any material file, so they get the largest limit
+	maxMaterialFileSize := cfg.Upload.GetLargestSizeBytes()
 
 	// POST/PUT/DELETE routes (teachers only)
-	materialGroup.Post("/", materialHandler.CreateCourseMaterial)           // POST /api/course-materials
-	materialGroup.Post("/upload", materialHandler.UploadCourseMaterialFile) // POST /api/course-materials/upload
-	materialGroup.Put("/:id", materialHandler.UpdateCourseMaterial)         // PUT /api/course-materials/:id
-	materialGroup.Delete("/:id", materialHandler.DeleteCourseMaterial)      // DELETE /api/course-materials/:id
+	materialGroup.Post("/", security.MaxUploadSize(maxMaterialFileSize), materialHandler.CreateCourseMaterial)           // POST /api/course-materials
+	materialGroup.Post("/upload", security.MaxUploadSize(maxMaterialFileSize), materialHandler.UploadCourseMaterialFile) // POST /api/course-materials/upload
+	materialGroup.Post("/bulk-visibility", materialHandler.BulkSetVisibility)                                            // POST /api/course-materials/bulk-visibility
+	materialGroup.Put("/:id", materialHandler.UpdateCourseMaterial)                                                      // PUT /api/course-materials/:id
+	materialGroup.Delete("/:id", materialHandler.DeleteCourseMaterial)                                                   // DELETE /api/course-materials/:id
 
 	// Material-based exercise routes
-	materialGroup.Post("/:id/submit", submissionHandler.SubmitMaterialExercise)         // POST /api/course-materials/:id/submit
-	materialGroup.Get("/:id/submissions/me", submissionHandler.GetMyMaterialSubmission) // GET /api/course-materials/:id/submissions/me
-	materialGroup.Get("/:id/test-cases", materialHandler.GetTestCases)                  // GET /api/course-materials/:id/test-cases
-	materialGroup.Post("/:id/test-cases", materialHandler.AddTestCase)                  // POST /api/course-materials/:id/test-cases
-	materialGroup.Put("/test-cases/:test_case_id", materialHandler.UpdateTestCase)      // PUT /api/course-materials/test-cases/:test_case_id
-	materialGroup.Delete("/test-cases/:test_case_id", materialHandler.DeleteTestCase)   // DELETE /api/course-materials/test-cases/:test_case_id
+	materialGroup.Post("/:id/submit", submissionHandler.SubmitMaterialExercise)                    // POST /api/course-materials/:id/submit
+	materialGroup.Get("/:id/submissions/me", submissionHandler.GetMyMaterialSubmission)            // GET /api/course-materials/:id/submissions/me
+	materialGroup.Get("/:id/test-cases", materialHandler.GetTestCases)                             // GET /api/course-materials/:id/test-cases
+	materialGroup.Post("/:id/test-cases", materialHandler.AddTestCase)                             // POST /api/course-materials/:id/test-cases
+	materialGroup.Delete("/:id/test-cases", materialHandler.DeleteAllTestCases)                    // DELETE /api/course-materials/:id/test-cases
+	materialGroup.Put("/:id/test-cases/display-names", materialHandler.UpdateTestCaseDisplayNames) // PUT /api/course-materials/:id/test-cases/display-names
+	materialGroup.Put("/test-cases/:test_case_id", materialHandler.UpdateTestCase)                 // PUT /api/course-materials/test-cases/:test_case_id
+	materialGroup.Delete("/test-cases/:test_case_id", materialHandler.DeleteTestCase)              // DELETE /api/course-materials/test-cases/:test_case_id
 
 	// Problem image management routes
-	materialGroup.Post("/:id/images", materialHandler.UploadProblemImage) // POST /api/course-materials/:id/images
+	materialGroup.Post("/:id/images", security.MaxUploadSize(cfg.Upload.GetMaxImageSizeBytes()), materialHandler.UploadProblemImage) // POST /api/course-materials/:id/images
+
+	// Operator routes; SetupQueueRoutes already puts the service API key check on /api/admin
+	adminGroup := app.Group("/api/admin")
+	adminGroup.Get("/materials/integrity", materialHandler.CheckMaterialIntegrity)          // GET /api/admin/materials/integrity
+	adminGroup.Post("/materials/integrity/repair", materialHandler.RepairMaterialIntegrity) // POST /api/admin/materials/integrity/repair
 }
@@ -29,4 +29,9 @@ func SetupDeadlineCheckerRoutes(
 	deadlineGroup.Get("/available", deadlineHandler.GetAvailableMaterials) // GET /api/materials/available?course_id=xxx
 	deadlineGroup.Get("/expired", deadlineHandler.GetExpiredMaterials)     // GET /api/materials/expired?course_id=xxx
 	deadlineGroup.Get("/can-submit", deadlineHandler.CanSubmitExercise)    // GET /api/materials/can-submit?exercise_id=xxx
+
+	// Per-student extensions (course teacher only)
+	materialGroup := app.Group("/api/course-materials")
+	materialGroup.Use(security.APIKeyAndJWTAuth(cfg, jwtService))
+	materialGroup.Post("/:id/extensions/bulk", deadlineHandler.BulkGrantExtension) // POST /api/course-materials/:id/extensions/bulk
 }
@@ -15,6 +15,8 @@ func SetupPDFExerciseRoutes(
 	pdfExerciseHandler *handler.PDFExerciseHandler,
 	jwtService *services.JWTService,
 ) {
+	maxPDFUpload := security.MaxUploadSize(cfg.Upload.GetMaxPDFSizeBytes())
+
 	// PDF Exercise routes
 	pdfGroup := app.Group("/api/materials")
 
@@ -22,7 +24,7 @@ func SetupPDFExerciseRoutes(
 	pdfGroup.Use(security.APIKeyAndJWTAuth(cfg, jwtService))
 
 	// Submit PDF exercise (students)
-	pdfGroup.Post("/:material_id/submit", pdfExerciseHandler.SubmitPDFExercise)
+	pdfGroup.Post("/:material_id/submit", maxPDFUpload, pdfExerciseHandler.SubmitPDFExercise)
 
 	// Get my PDF submission for a material (students)
 	pdfGroup.Get("/:material_id/submissions/me", pdfExerciseHandler.GetMyPDFSubmission)
@@ -49,10 +51,10 @@ func SetupPDFExerciseRoutes(
 	submissionGroup.Get("/:submission_id", pdfExerciseHandler.GetPDFSubmission)
 
 	// Approve submission (teachers/TAs)
-	submissionGroup.Post("/:submission_id/approve", pdfExerciseHandler.ApprovePDFSubmission)
+	submissionGroup.Post("/:submission_id/approve", maxPDFUpload, pdfExerciseHandler.ApprovePDFSubmission)
 
 	// Reject submission (teachers/TAs)
-	submissionGroup.Post("/:submission_id/reject", pdfExerciseHandler.RejectPDFSubmission)
+	submissionGroup.Post("/:submission_id/reject", maxPDFUpload, pdfExerciseHandler.RejectPDFSubmission)
 
 	// Download submission (teachers/TAs)
 	submissionGroup.Get("/:submission_id/download", pdfExerciseHandler.DownloadPDFSubmission)
@@ -36,4 +36,10 @@ func SetupQueueRoutes(
 	queueGroup.Get("/stats", queueHandler.GetQueueStats)               // GET /api/queue/stats
 
 	queueGroup.Post("/review", queueHandler.SubmitCodeReview) // POST /api/queue/review
+
+	// Operator routes (service API key only)
+	adminGroup := app.Group("/api/admin")
+	adminGroup.Use(security.APIKeyAuth(cfg))
+	adminGroup.Get("/queue/consumers", queueHandler.GetQueueConsumers) // GET /api/admin/queue/consumers
+	adminGroup.Get("/queue/stuck", queueHandler.GetStuckQueueJobs)     // GET /api/admin/queue/stuck
 }
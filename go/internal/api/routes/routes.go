@@ -14,8 +14,10 @@ import (
 	"github.com/Project-DSView/backend/go/internal/api/handler"
 	"github.com/Project-DSView/backend/go/internal/api/middleware/logging"
 	"github.com/Project-DSView/backend/go/internal/api/middleware/security"
+	"github.com/Project-DSView/backend/go/internal/api/middleware/versioning"
 	"github.com/Project-DSView/backend/go/internal/application/services"
 	"github.com/Project-DSView/backend/go/internal/infrastructure/config"
+	"github.com/Project-DSView/backend/go/pkg/apiversion"
 	"github.com/Project-DSView/backend/go/pkg/enrollment"
 	"github.com/Project-DSView/backend/go/pkg/response"
 	"github.com/Project-DSView/backend/go/pkg/storage"
@@ -40,9 +42,21 @@ func SetupRoutes(
 	deadlineChecker *services.DeadlineCheckerService,
 	courseScoreService *services.CourseScoreService,
 	courseMaterialService *services.CourseMaterialService,
+	completionService *services.CourseCompletionService,
+	sessionService *services.SessionService,
+	scheduler handler.ScheduledTaskLister,
 	db *gorm.DB,
 ) *fiber.App {
+	maxBodySize := cfg.Upload.GetMaxBodySizeBytes(security.MultipartOverhead)
 	app := fiber.New(fiber.Config{
+		// Requests declaring a larger body are refused before it is read; upload
+		// routes apply their own per-kind limit on top (security.MaxUploadSize)
+		BodyLimit: int(maxBodySize),
+		// Bodies are streamed and multipart forms parsed on demand, so large uploads go
+		// to temporary files instead of memory; security.BodyLimit decides what is
+		// buffered and enforces BodyLimit on the rest
+		StreamRequestBody:            true,
+		DisablePreParseMultipartForm: true,
 		ErrorHandler: func(c *fiber.Ctx, err error) error {
 			code := fiber.StatusInternalServerError
 			if e, ok := err.(*fiber.Error); ok {
@@ -54,11 +68,14 @@ func SetupRoutes(
 
 	// Note: Structured logger is initialized in main.go before SetupRoutes is called
 
+	response.SetMaxPageLimit(cfg.Server.MaxPageLimit)
+
 	// Global middleware
 	app.Use(logging.StructuredLoggingMiddleware())
 	app.Use(logging.ErrorLoggingMiddleware())
 	app.Use(logging.PerformanceLoggingMiddleware(1.0)) // Log requests slower than 1 second
 	app.Use(recover.New())
+	app.Use(security.BodyLimit(maxBodySize, cfg.Upload.GetMultipartMemoryLimitBytes()))
 
 	// Security headers (must be before CORS)
 	app.Use(security.SecurityHeaders())
@@ -91,7 +108,8 @@ func SetupRoutes(
 
 	app.Use(cors.New(cors.Config{
 		AllowMethods:     "GET,POST,HEAD,PUT,DELETE,PATCH,OPTIONS",
-		AllowHeaders:     "Origin,Content-Type,Accept,Authorization,Cookie,X-Requested-With,X-CSRF-Token,Cache-Control,Pragma," + cfg.APIKey.APIKeyName,
+		AllowHeaders:     "Origin,Content-Type,Accept,Accept-Version,Authorization,Cookie,X-Requested-With,X-CSRF-Token,Cache-Control,Pragma," + cfg.APIKey.APIKeyName,
+		ExposeHeaders:    apiversion.ResponseHeader + "," + apiversion.BuildHeader,
 		AllowCredentials: true,
 		MaxAge:           86400,
 		// Enable CORS debugging in development
@@ -126,6 +144,9 @@ func SetupRoutes(
 		},
 	}))
 
+	// Response shape version negotiation (after CORS so rejections stay readable cross-origin)
+	app.Use(versioning.Negotiate())
+
 	// Rate limiting for general API endpoints
 	app.Use(security.RateLimit(100, 1*time.Minute)) // 100 requests per minute
 
@@ -136,13 +157,26 @@ func SetupRoutes(
 
 	// Initialize System Handler
 	systemHandler := handler.NewSystemHandler(db, cfg)
+	systemHandler.SetQueueService(queueService)
+	systemHandler.SetStorageService(storageService)
+	systemHandler.SetScheduler(scheduler)
 
 	// Health check (public)
 	app.Get("/health", systemHandler.HealthCheck)
 
+	// Readiness check (public)
+	app.Get("/readyz", systemHandler.ReadinessCheck)
+
 	// API key protected health check
 	app.Get("/health/secure", security.APIKeyAuth(cfg), systemHandler.HealthCheck)
 
+	// Prometheus metrics (public, like the health probes)
+	metricsHandler := handler.NewMetricsHandler(queueService, submissionService)
+	app.Get("/metrics", metricsHandler.GetMetrics) // GET /metrics
+
+	// Scheduled task status (operator, API key only)
+	app.Get("/api/admin/scheduler/tasks", security.APIKeyAuth(cfg), systemHandler.GetScheduledTasks) // GET /api/admin/scheduler/tasks
+
 	// APIInfo godoc
 	// @Summary API information
 	// @Description Get API information and available endpoints
@@ -170,24 +204,40 @@ func SetupRoutes(
 					"refresh":  "POST /api/auth/refresh",
 				},
 				"user": fiber.Map{
-					"profile": "GET /api/profile",
-					"update":  "PUT /api/profile",
+					"profile":             "GET /api/profile",
+					"update":              "PUT /api/profile",
+					"my_courses":          "GET /api/users/me/courses",
+					"my_submissions":      "GET /api/users/me/submissions",
+					"instructor_overview": "GET /api/users/me/instructor-overview",
+					"my_calendar":         "GET /api/users/me/calendar",
+				},
+				"sessions": fiber.Map{
+					"list_sessions":       "GET /api/users/:id/sessions",
+					"revoke_session":      "DELETE /api/users/:id/sessions/:session_id",
+					"revoke_all_sessions": "DELETE /api/users/:id/sessions",
 				},
 				"course_materials": fiber.Map{
 					"list_materials":  "GET /api/course-materials?course_id=xxx",
 					"create_material": "POST /api/course-materials",
 					"upload_file":     "POST /api/course-materials/upload",
 					"get_material":    "GET /api/course-materials/:id",
+					"edit_material":   "GET /api/course-materials/:id/edit",
+					"get_materials":   "POST /api/course-materials/batch",
+					"get_engagement":  "GET /api/course-materials/:id/engagement",
+					"record_access":   "POST /api/course-materials/:id/engagement",
 					"update_material": "PUT /api/course-materials/:id",
+					"bulk_visibility": "POST /api/course-materials/bulk-visibility",
 					"delete_material": "DELETE /api/course-materials/:id",
 					// removed: materials_by_type
 					// removed: search_materials
 					// removed: material_stats
-					"submit_exercise":  "POST /api/course-materials/:id/submit",
-					"test_cases":       "GET /api/course-materials/:id/test-cases",
-					"add_test_case":    "POST /api/course-materials/:id/test-cases",
-					"update_test_case": "PUT /api/course-materials/test-cases/:test_case_id",
-					"delete_test_case": "DELETE /api/course-materials/test-cases/:test_case_id",
+					"submit_exercise":       "POST /api/course-materials/:id/submit",
+					"test_cases":            "GET /api/course-materials/:id/test-cases",
+					"add_test_case":         "POST /api/course-materials/:id/test-cases",
+					"update_test_case":      "PUT /api/course-materials/test-cases/:test_case_id",
+					"delete_test_case":      "DELETE /api/course-materials/test-cases/:test_case_id",
+					"delete_all_test_cases": "DELETE /api/course-materials/:id/test-cases",
+					"rename_test_cases":     "PUT /api/course-materials/:id/test-cases/display-names",
 				},
 				"workflow": fiber.Map{
 					"create_code_exercise": []string{
@@ -202,23 +252,32 @@ func SetupRoutes(
 					},
 				},
 				"courses": fiber.Map{
-					"list_courses":     "GET /api/courses",
-					"create_course":    "POST /api/courses",
-					"get_course":       "GET /api/courses/:id",
-					"update_course":    "PUT /api/courses/:id",
-					"delete_course":    "DELETE /api/courses/:id",
-					"course_materials": "GET /api/course-materials?course_id=xxx",
-					"enroll":           "POST /api/courses/:id/enroll",
-					"list_enrollments": "GET /api/courses/:id/enrollments",
-					"unenroll":         "DELETE /api/courses/:id/enroll",
-					"teacher_report":   "GET /api/courses/:id/report/teacher",
-					"ta_report":        "GET /api/courses/:id/report/ta",
+					"list_courses":      "GET /api/courses",
+					"discover_courses":  "GET /api/courses/discover",
+					"create_course":     "POST /api/courses",
+					"get_course":        "GET /api/courses/:id",
+					"update_course":     "PUT /api/courses/:id",
+					"delete_course":     "DELETE /api/courses/:id",
+					"transfer_course":   "POST /api/courses/:id/transfer",
+					"course_materials":  "GET /api/course-materials?course_id=xxx",
+					"course_weeks":      "GET /api/courses/:id/weeks",
+					"course_tags":       "GET /api/courses/:id/tags",
+					"material_defaults": "GET /api/courses/:id/material-defaults",
+					"enroll":            "POST /api/courses/:id/enroll",
+					"list_enrollments":  "GET /api/courses/:id/enrollments",
+					"unenroll":          "DELETE /api/courses/:id/enroll",
+					"teacher_report":    "GET /api/courses/:id/report/teacher",
+					"ta_report":         "GET /api/courses/:id/report/ta",
+					"course_stats":      "GET /api/courses/:id/stats",
+					"calendar":          "GET /api/courses/:id/calendar",
+					"queue_export":      "GET /api/courses/:id/queue/export",
 				},
 				"execution": fiber.Map{
 					"run_code":            "POST /api/exec/run",
 					"get_executions":      "GET /api/exec/:source",
 					"get_execution_by_id": "GET /api/exec/:source/:id",
 					"health_check":        "GET /api/exec/health",
+					"capabilities":        "GET /api/executor/capabilities",
 				},
 				"test_cases": fiber.Map{
 					"list_test_cases":  "GET /api/course-materials/:id/test-cases",
@@ -228,14 +287,24 @@ func SetupRoutes(
 				},
 				"submissions": fiber.Map{
 					"submit":           "POST /api/course-materials/:id/submit",
+					"validate_code":    "POST /api/course-materials/:id/validate",
 					"list_submissions": "GET /api/course-materials/:id/submissions",
 					"get_submission":   "GET /api/submissions/:id",
+					"test_case_stats":  "GET /api/course-materials/:id/test-case-stats",
+					"rejudge":          "POST /api/submissions/:id/rejudge",
+					"cancel":           "DELETE /api/submissions/:id/cancel",
+					"simulate_regrade": "POST /api/course-materials/:id/simulate-regrade",
+					"reprocess_file":   "POST /api/submissions/:id/reprocess",
+					"student_code":     "GET /api/course-materials/:id/students/:user_id/code",
 				},
 				"progress": fiber.Map{
 					"self_progress":     "GET /api/students/progress",
+					"progress_batch":    "POST /api/students/progress/batch",
 					"course_progress":   "GET /api/courses/:id/progress",
 					"verify_progress":   "POST /api/progress/:id/verify",
 					"verification_logs": "GET /api/progress/:id/logs",
+					"non_submitters":    "GET /api/course-materials/:id/non-submitters",
+					"review_history":    "GET /api/course-materials/:id/progress/:user_id/history",
 				},
 				"announcements": fiber.Map{
 					"list_announcements":   "GET /api/announcements?course_id=xxx",
@@ -244,12 +313,21 @@ func SetupRoutes(
 					"update_announcement":  "PUT /api/announcements/:id",
 					"delete_announcement":  "DELETE /api/announcements/:id",
 					"pin_announcement":     "PUT /api/announcements/:id/pin",
+					"unpin_announcement":   "DELETE /api/announcements/:id/pin",
 					"announcement_stats":   "GET /api/announcements/stats?course_id=xxx",
 					"recent_announcements": "GET /api/announcements/recent",
 				},
 				"course_scores": fiber.Map{
 					"get_course_score": "GET /api/course-scores/course?course_id=xxx",
 				},
+				"course_api_keys": fiber.Map{
+					"create_key":      "POST /api/course-api-keys",
+					"list_keys":       "GET /api/course-api-keys",
+					"revoke_key":      "DELETE /api/course-api-keys/:id",
+					"lms_course":      "GET /api/lms/courses/:id",
+					"lms_enrollments": "GET /api/lms/courses/:id/enrollments",
+					"lms_scores":      "GET /api/lms/courses/:id/scores",
+				},
 				"deadline_checker": fiber.Map{
 					"get_available":      "GET /api/course-materials/available?course_id=xxx",
 					"get_expired":        "GET /api/course-materials/expired?course_id=xxx",
@@ -258,17 +336,25 @@ func SetupRoutes(
 					"by_deadline_status": "GET /api/course-materials/by-deadline-status?course_id=xxx",
 					"upcoming_deadlines": "GET /api/course-materials/upcoming-deadlines?course_id=xxx&hours=24",
 					"deadline_stats":     "GET /api/course-materials/deadline-stats?course_id=xxx",
+					"bulk_extensions":    "POST /api/course-materials/:id/extensions/bulk",
 				},
 				"queue": fiber.Map{
-					"get_jobs":      "GET /api/queue/jobs",
-					"get_job":       "GET /api/queue/jobs/:id",
-					"cancel_job":    "POST /api/queue/jobs/:id/cancel",
-					"process_job":   "POST /api/queue/jobs/:id/process",
-					"claim_job":     "POST /api/queue/jobs/:id/claim",
-					"complete_job":  "POST /api/queue/jobs/:id/complete",
-					"retry_job":     "POST /api/queue/jobs/:id/retry",
-					"queue_stats":   "GET /api/queue/stats",
-					"submit_review": "POST /api/queue/review",
+					"get_jobs":        "GET /api/queue/jobs",
+					"get_job":         "GET /api/queue/jobs/:id",
+					"cancel_job":      "POST /api/queue/jobs/:id/cancel",
+					"process_job":     "POST /api/queue/jobs/:id/process",
+					"claim_job":       "POST /api/queue/jobs/:id/claim",
+					"complete_job":    "POST /api/queue/jobs/:id/complete",
+					"retry_job":       "POST /api/queue/jobs/:id/retry",
+					"queue_stats":     "GET /api/queue/stats",
+					"submit_review":   "POST /api/queue/review",
+					"queue_consumers": "GET /api/admin/queue/consumers",
+					"stuck_jobs":      "GET /api/admin/queue/stuck",
+				},
+				"admin": fiber.Map{
+					"scheduled_tasks":    "GET /api/admin/scheduler/tasks",
+					"material_integrity": "GET /api/admin/materials/integrity",
+					"repair_materials":   "POST /api/admin/materials/integrity/repair",
 				},
 			},
 			"roles": fiber.Map{
@@ -293,20 +379,28 @@ func SetupRoutes(
 	})
 
 	// Setup separated routes
-	SetupAuthRoutes(app, cfg, oauthService, jwtService, userService, storageService)
+	SetupAuthRoutes(app, cfg, oauthService, jwtService, userService, sessionService, storageService)
+	SetupSessionRoutes(app, cfg, jwtService, sessionService, userService)
 	SetupTestCaseRoutes(app, cfg, jwtService, testCaseService, userService)
-	SetupCourseRoutes(app, cfg, jwtService, courseService, courseMaterialService, userService, enrollmentService, invitationService, queueService, storageService)
+	SetupCourseRoutes(app, cfg, jwtService, courseService, courseMaterialService, userService, enrollmentService, invitationService, queueService, storageService, db)
 	SetupSubmissionRoutes(app, cfg, jwtService, submissionService, progressService, courseService, enrollmentService, userService, draftService, courseMaterialService, db)
 	SetupDraftRoutes(app, cfg, jwtService, draftService, userService, storageService)
 	SetupQueueRoutes(app, cfg, jwtService, queueService, userService)
 	SetupPlaygroundRoutes(app, cfg, jwtService)
 
+	// Local development storage serves its files from the API instead of MinIO
+	if localStorage, ok := storageService.(*storage.LocalStorageService); ok {
+		SetupLocalStorageRoutes(app, localStorage)
+	}
+
 	// Setup new announcement and course material routes
 	announcementService := services.NewAnnouncementService(db) // Pass proper DB instance
-	enrollmentValidator := enrollment.NewEnrollmentValidator(enrollmentService, courseService)
+	enrollmentValidator := enrollment.NewEnrollmentValidator(enrollmentService, db)
 	announcementHandler := handler.NewAnnouncementHandler(announcementService, enrollmentValidator)
 	courseMaterialHandler := handler.NewCourseMaterialHandler(courseMaterialService, enrollmentValidator, storageService)
+	courseMaterialHandler.SetUploadLimits(cfg.Upload.GetMaxPDFSizeBytes(), cfg.Upload.GetMaxDocumentSizeBytes(), cfg.Upload.GetMaxImageSizeBytes())
 	submissionHandler := handler.NewSubmissionHandler(submissionService, userService, enrollmentService, db)
+	submissionHandler.SetMaxPDFSize(cfg.Upload.GetMaxPDFSizeBytes())
 	SetupAnnouncementRoutes(app, cfg, announcementHandler, jwtService)
 	SetupCourseMaterialRoutes(app, cfg, courseMaterialHandler, submissionHandler, jwtService)
 
@@ -314,6 +408,11 @@ func SetupRoutes(
 	courseScoreHandler := handler.NewCourseScoreHandler(courseScoreService, enrollmentValidator)
 	SetupCourseScoreRoutes(app, cfg, courseScoreHandler, jwtService)
 
+	// Setup course-scoped API key (LMS integration) routes
+	courseAPIKeyService := services.NewCourseAPIKeyService(db, courseService)
+	courseAPIKeyHandler := handler.NewCourseAPIKeyHandler(courseAPIKeyService, courseService, enrollmentService, courseScoreService)
+	SetupCourseAPIKeyRoutes(app, cfg, courseAPIKeyHandler, courseAPIKeyService, jwtService)
+
 	// Setup deadline checker routes
 	deadlineCheckerService := services.NewDeadlineCheckerService(db) // Pass proper DB instance
 	deadlineCheckerHandler := handler.NewDeadlineCheckerHandler(deadlineCheckerService)
@@ -321,7 +420,10 @@ func SetupRoutes(
 
 	// Setup PDF exercise routes
 	pdfExerciseSubmissionService := services.NewPDFExerciseSubmissionService(db, deadlineChecker, storageService)
-	pdfExerciseHandler := handler.NewPDFExerciseHandler(pdfExerciseSubmissionService)
+	pdfExerciseSubmissionService.SetCourseCompletionService(completionService)
+	pdfExerciseSubmissionService.SetMaxPDFPages(cfg.Submission.PDFMaxPages)
+	pdfExerciseHandler := handler.NewPDFExerciseHandler(pdfExerciseSubmissionService, cfg.Frontend.StreamingAllowedOrigins)
+	pdfExerciseHandler.SetMaxPDFSize(cfg.Upload.GetMaxPDFSizeBytes())
 	SetupPDFExerciseRoutes(app, cfg, pdfExerciseHandler, jwtService)
 
 	app.Get("/test-public", func(c *fiber.Ctx) error {
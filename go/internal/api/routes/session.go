@@ -0,0 +1,32 @@
+package routes
+
+import (
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/Project-DSView/backend/go/internal/api/handler"
+	"github.com/Project-DSView/backend/go/internal/api/middleware/security"
+	"github.com/Project-DSView/backend/go/internal/application/services"
+	"github.com/Project-DSView/backend/go/internal/infrastructure/config"
+)
+
+func SetupSessionRoutes(
+	app *fiber.App,
+	cfg *config.Config,
+	jwtService *services.JWTService,
+	sessionService *services.SessionService,
+	userService *services.UserService,
+) {
+	// Create handler
+	sessionHandler := handler.NewSessionHandler(sessionService, userService)
+
+	// User session routes group
+	userGroup := app.Group("/api/users")
+
+	// Protected routes (JWT or API key authentication required)
+	userGroup.Use(security.APIKeyAndJWTAuth(cfg, jwtService))
+
+	// Session management routes (Teacher only)
+	userGroup.Get("/:id/sessions", sessionHandler.ListUserSessions)                 // GET /api/users/:id/sessions
+	userGroup.Delete("/:id/sessions", sessionHandler.RevokeAllUserSessions)         // DELETE /api/users/:id/sessions
+	userGroup.Delete("/:id/sessions/:session_id", sessionHandler.RevokeUserSession) // DELETE /api/users/:id/sessions/:session_id
+}
@@ -0,0 +1,17 @@
+package routes
+
+import (
+	"github.com/Project-DSView/backend/go/internal/api/handler"
+	"github.com/Project-DSView/backend/go/pkg/storage"
+	"github.com/gofiber/fiber/v2"
+)
+
+// SetupLocalStorageRoutes serves the files of the local storage backend. Access is
+// controlled by signed URLs rather than JWTs, as with MinIO object URLs.
+func SetupLocalStorageRoutes(app *fiber.App, localStorage *storage.LocalStorageService) {
+	localStorageHandler := handler.NewLocalStorageHandler(localStorage)
+
+	files := app.Group(localStorage.RoutePath())
+	files.Get("/*", localStorageHandler.GetFile) // GET /files/*
+	files.Put("/*", localStorageHandler.PutFile) // PUT /files/*
+}
@@ -25,7 +25,8 @@ func SetupSubmissionRoutes(
 ) {
 	// Create handlers
 	submissionHandler := handler.NewSubmissionHandler(submissionService, userService, enrollmentService, db)
-	progressHandler := handler.NewProgressHandler(progressService, userService, enrollmentService, courseService)
+	submissionHandler.SetMaxPDFSize(cfg.Upload.GetMaxPDFSizeBytes())
+	progressHandler := handler.NewProgressHandler(progressService, userService, enrollmentService, courseService, db)
 
 	// Submission routes group
 	submissionGroup := app.Group("/api/submissions")
@@ -34,16 +35,37 @@ func SetupSubmissionRoutes(
 	submissionGroup.Use(security.APIKeyAndJWTAuth(cfg, jwtService))
 
 	// Submission management routes
-	submissionGroup.Post("/exercises/:id", submissionHandler.SubmitExercise)         // POST /api/submissions/exercises/:id
-	submissionGroup.Get("/exercises/:id", submissionHandler.ListExerciseSubmissions) // GET /api/submissions/exercises/:id
-	submissionGroup.Get("/:id", submissionHandler.GetSubmission)                     // GET /api/submissions/:id
+	submissionGroup.Post("/exercises/:id", submissionHandler.SubmitExercise)            // POST /api/submissions/exercises/:id
+	submissionGroup.Get("/exercises/:id", submissionHandler.ListExerciseSubmissions)    // GET /api/submissions/exercises/:id
+	submissionGroup.Get("/:id", submissionHandler.GetSubmission)                        // GET /api/submissions/:id
+	submissionGroup.Get("/:id/code/download", submissionHandler.DownloadSubmissionCode) // GET /api/submissions/:id/code/download
+	submissionGroup.Post("/:id/rejudge", submissionHandler.RejudgeSubmission)           // POST /api/submissions/:id/rejudge
+	submissionGroup.Post("/:id/reprocess", submissionHandler.ReprocessSubmissionFile)   // POST /api/submissions/:id/reprocess
+	submissionGroup.Delete("/:id/cancel", submissionHandler.CancelSubmission)           // DELETE /api/submissions/:id/cancel
 
 	// Course materials submission routes
 	courseMaterialGroup := app.Group("/api/course-materials")
 	courseMaterialGroup.Use(security.APIKeyAndJWTAuth(cfg, jwtService))
-	courseMaterialGroup.Post("/:id/submit", submissionHandler.SubmitMaterialExercise)         // POST /api/course-materials/:id/submit
-	courseMaterialGroup.Post("/:id/submit-pdf", submissionHandler.SubmitPDFExercise)          // POST /api/course-materials/:id/submit-pdf
-	courseMaterialGroup.Get("/:id/submissions/me", submissionHandler.GetMyMaterialSubmission) // GET /api/course-materials/:id/submissions/me
+	courseMaterialGroup.Post("/:id/submit", submissionHandler.SubmitMaterialExercise)                                                         // POST /api/course-materials/:id/submit
+	courseMaterialGroup.Post("/:id/submit-pdf", security.MaxUploadSize(cfg.Upload.GetMaxPDFSizeBytes()), submissionHandler.SubmitPDFExercise) // POST /api/course-materials/:id/submit-pdf
+	courseMaterialGroup.Post("/:id/submit-on-behalf", submissionHandler.SubmitOnBehalf)                                                       // POST /api/course-materials/:id/submit-on-behalf
+	courseMaterialGroup.Post("/:id/validate", submissionHandler.ValidateCode)                                                                 // POST /api/course-materials/:id/validate
+	courseMaterialGroup.Get("/:id/submissions/me", submissionHandler.GetMyMaterialSubmission)                                                 // GET /api/course-materials/:id/submissions/me
+	courseMaterialGroup.Get("/:id/test-case-stats", submissionHandler.GetTestCaseStats)                                                       // GET /api/course-materials/:id/test-case-stats
+	courseMaterialGroup.Get("/:id/non-submitters", progressHandler.GetNonSubmitters)                                                          // GET /api/course-materials/:id/non-submitters
+	courseMaterialGroup.Get("/:id/progress/:user_id/history", progressHandler.GetVerificationHistory)                                         // GET /api/course-materials/:id/progress/:user_id/history
+	courseMaterialGroup.Post("/:id/simulate-regrade", submissionHandler.SimulateRegrade)                                                      // POST /api/course-materials/:id/simulate-regrade
+	courseMaterialGroup.Get("/:id/students/:user_id/code", submissionHandler.GetStudentLatestCode)                                            // GET /api/course-materials/:id/students/:user_id/code
+
+	// Languages and limits of the code executor
+	executorGroup := app.Group("/api/executor")
+	executorGroup.Use(security.APIKeyAndJWTAuth(cfg, jwtService))
+	executorGroup.Get("/capabilities", submissionHandler.GetExecutorCapabilities) // GET /api/executor/capabilities
+
+	// Current user's submissions across courses
+	userGroup := app.Group("/api/users")
+	userGroup.Use(security.APIKeyAndJWTAuth(cfg, jwtService))
+	userGroup.Get("/me/submissions", submissionHandler.GetMySubmissions) // GET /api/users/me/submissions
 
 	// Progress routes group
 	progressGroup := app.Group("/api/progress")
@@ -59,7 +81,8 @@ func SetupSubmissionRoutes(
 	// Students progress route (separate group to match swagger docs)
 	studentsGroup := app.Group("/api/students")
 	studentsGroup.Use(security.APIKeyAndJWTAuth(cfg, jwtService))
-	studentsGroup.Get("/progress", progressHandler.GetSelfProgress) // GET /api/students/progress
+	studentsGroup.Get("/progress", progressHandler.GetSelfProgress)         // GET /api/students/progress
+	studentsGroup.Post("/progress/batch", progressHandler.GetProgressBatch) // POST /api/students/progress/batch
 
 	// Course progress route
 	coursesGroup := app.Group("/api/courses")
@@ -1,5 +1,7 @@
 package types
 
+import "time"
+
 // Course Requests
 type CreateCourseRequest struct {
 	Name        string `json:"name" validate:"required,min=1,max=255"`
@@ -72,43 +74,103 @@ type UpdateCourseMaterialRequest struct {
 	FileSize    *int64  `json:"file_size,omitempty"`
 	MimeType    *string `json:"mime_type,omitempty"`
 	IsPublic    *bool   `json:"is_public,omitempty"`
+	// Replaces the material's tags; an empty array clears them
+	Tags *[]string `json:"tags,omitempty"`
 
 	// Exercise-specific fields
 	ExerciseID     *string `json:"exercise_id,omitempty"`
 	TotalPoints    *int    `json:"total_points,omitempty"`
 	Deadline       *string `json:"deadline,omitempty"`
 	SubmissionType *string `json:"submission_type,omitempty" validate:"omitempty,oneof=file code"`
+	// Reject resubmissions once a student's work is approved
+	LockAfterApproval *bool `json:"lock_after_approval,omitempty"`
+	// How many times a student may submit (0 = unlimited)
+	MaxAttempts *int `json:"max_attempts,omitempty" validate:"omitempty,min=0"`
+	// Replaces the exercises a student must complete before submitting; an empty array clears them
+	Prerequisites *[]string `json:"prerequisites,omitempty"`
+	// When students may start and must stop submitting; inside the window the deadline only marks submissions late
+	OpensAt       *time.Time `json:"opens_at,omitempty"`
+	ClosesAt      *time.Time `json:"closes_at,omitempty"`
+	ClearOpensAt  bool       `json:"clear_opens_at,omitempty"`  // Remove the open time so submissions are allowed right away
+	ClearClosesAt bool       `json:"clear_closes_at,omitempty"` // Remove the close time so only the deadline applies
 
 	// Code exercise-specific fields
-	ProblemStatement *string                `json:"problem_statement,omitempty"`
-	Constraints      *string                `json:"constraints,omitempty"`
-	Hints            *string                `json:"hints,omitempty"`
-	TestCases        *[]map[string]interface{} `json:"test_cases,omitempty"`
+	ProblemStatement *string `json:"problem_statement,omitempty"`
+	Constraints      *string `json:"constraints,omitempty"`
+	Hints            *string `json:"hints,omitempty"`
+	OutputMode       *string `json:"output_mode,omitempty" validate:"omitempty,oneof=json plain"`
+	// The complete list of test cases: entries with a test_case_id update that test case,
+	// entries without one are added, and test cases left out are deleted
+	TestCases *[]map[string]interface{} `json:"test_cases,omitempty"`
+	// Ignore leading and trailing whitespace when comparing output
+	TrimWhitespace *bool `json:"trim_whitespace,omitempty"`
+	// How far apart numbers in JSON output may be and still match (relative above 1; 0 = exact)
+	NumberTolerance *float64 `json:"number_tolerance,omitempty" validate:"omitempty,min=0"`
+	// Percentage of test cases a submission must pass to be ready for review (default 100)
+	PassThreshold *int `json:"pass_threshold,omitempty" validate:"omitempty,min=1,max=100"`
+	// How many submissions are graded at once, e.g. during an exam (0 = no cap)
+	MaxConcurrentSubmissions *int `json:"max_concurrent_submissions,omitempty" validate:"omitempty,min=0"`
+
+	// PDF exercise-specific fields
+	MaxPages *int `json:"max_pages,omitempty" validate:"omitempty,min=0"`
 
 	// Announcement-specific fields
 	Content *string `json:"content,omitempty"`
 }
 
+// BatchGetProgressRequest lists the materials to get the caller's progress on in one call
+type BatchGetProgressRequest struct {
+	MaterialIDs []string `json:"material_ids" validate:"required,min=1,max=100,dive,required"`
+}
+
+// BatchGetCourseMaterialsRequest lists the materials to fetch in one call
+type BatchGetCourseMaterialsRequest struct {
+	MaterialIDs []string `json:"material_ids" validate:"required,min=1,max=100,dive,required"`
+}
+
+// BulkSetVisibilityRequest shows or hides several materials at once
+type BulkSetVisibilityRequest struct {
+	MaterialIDs []string `json:"material_ids" validate:"required,min=1,max=100,dive,required"`
+	IsPublic    *bool    `json:"is_public" validate:"required"`
+}
+
+// RecordMaterialAccessRequest reports a student's access of a document or video that the
+// server does not see, e.g. downloading its file from storage
+type RecordMaterialAccessRequest struct {
+	Kind string `json:"kind" validate:"required,oneof=view download"`
+}
+
 // PDF Exercise Submission Requests
 type ApprovePDFSubmissionRequest struct {
-	Score   int    `json:"score" validate:"required,min=0,max=100"`
+	Score   int    `json:"score" validate:"min=0"` // At most the exercise's total points, checked by the service
 	Comment string `json:"comment" validate:"required,min=1"`
 }
 
+// BulkGrantExtensionRequest extends the deadline of an exercise for several students at once
+type BulkGrantExtensionRequest struct {
+	UserIDs  []string  `json:"user_ids" validate:"required,min=1,max=200,dive,required"`
+	Deadline time.Time `json:"deadline" validate:"required"`
+	Reason   string    `json:"reason" validate:"required,min=1,max=500"`
+}
+
 type RejectPDFSubmissionRequest struct {
 	Comment string `json:"comment" validate:"required,min=1"`
 }
 
 // Announcement Requests
 type CreateAnnouncementRequest struct {
-	CourseID string `json:"course_id" validate:"required"`
-	Title    string `json:"title" validate:"required,min=1,max=255"`
-	Content  string `json:"content" validate:"required,min=1"`
+	CourseID  string     `json:"course_id" validate:"required"`
+	Title     string     `json:"title" validate:"required,min=1,max=255"`
+	Content   string     `json:"content" validate:"required,min=1"`
+	IsPinned  bool       `json:"is_pinned,omitempty"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
 }
 
 type UpdateAnnouncementRequest struct {
-	Title   *string `json:"title,omitempty" validate:"omitempty,min=1,max=255"`
-	Content *string `json:"content,omitempty" validate:"omitempty,min=1"`
+	Title          *string    `json:"title,omitempty" validate:"omitempty,min=1,max=255"`
+	Content        *string    `json:"content,omitempty" validate:"omitempty,min=1"`
+	ExpiresAt      *time.Time `json:"expires_at,omitempty"`
+	ClearExpiresAt bool       `json:"clear_expires_at,omitempty"` // Remove the expiry so the announcement never expires
 }
 
 // Playground Requests
@@ -30,10 +30,10 @@ type CourseMaterialServiceInterface interface {
 	GetCourseMaterialByID(materialID string) (map[string]interface{}, error)
 	GetCourseMaterialsWithFilters(page, limit int, courseID, materialType, search, createdBy string, isTeacher bool) ([]models.CourseMaterial, int, error)
 	UpdateCourseMaterial(materialID string, userID string, updates map[string]interface{}) error
-	DeleteCourseMaterial(materialID string, userID string) error
+	DeleteCourseMaterial(materialID string, userID string, force bool) error
 	GetCourseMaterialStatistics() (map[string]interface{}, error)
 	CanUserModifyCourseMaterial(userID, materialID string, isTeacher bool) (bool, error)
-	GetCourseMaterialsByCourse(courseID string, week *int, materialType *string, limit, offset int) ([]map[string]interface{}, int64, error)
+	GetCourseMaterialsByCourse(courseID string, week *int, materialType *string, tags []string, limit, offset int) ([]map[string]interface{}, int64, error)
 	CreateCodeExercise(codeExercise *models.CodeExercise, testCases []models.TestCase) error
 	GetTestCases(materialID string) ([]models.TestCase, error)
 	AddTestCase(materialID string, testCase *models.TestCase) error
@@ -50,7 +50,6 @@ type CourseServiceInterface interface {
 	UpdateCourse(courseID string, updates map[string]interface{}) error
 	DeleteCourse(courseID string) error
 	GetCourseStatistics() (map[string]interface{}, error)
-	CanUserModifyCourse(userID, courseID string, isTeacher bool) (bool, error)
 }
 
 // TestCaseServiceInterface defines the interface for test case operations
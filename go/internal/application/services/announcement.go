@@ -49,13 +49,18 @@ func (s *AnnouncementService) CreateAnnouncement(announcement *models.Announceme
 	return nil
 }
 
-// GetAnnouncementsByCourse retrieves announcements for a specific course
-func (s *AnnouncementService) GetAnnouncementsByCourse(courseID string, week *int, limit, offset int) ([]models.Announcement, int64, error) {
+// GetAnnouncementsByCourse retrieves announcements for a specific course, pinned first.
+// Expired announcements are left out unless includeExpired is set (for teachers).
+func (s *AnnouncementService) GetAnnouncementsByCourse(courseID string, week *int, limit, offset int, includeExpired bool) ([]models.Announcement, int64, error) {
 	var announcements []models.Announcement
 	var total int64
+	now := time.Now()
 
 	// Count total with optimized query
 	countQuery := s.db.Model(&models.Announcement{}).Where("course_id = ?", courseID)
+	if !includeExpired {
+		countQuery = countQuery.Where("expires_at IS NULL OR expires_at > ?", now)
+	}
 	if err := countQuery.Count(&total).Error; err != nil {
 		return nil, 0, err
 	}
@@ -70,6 +75,8 @@ func (s *AnnouncementService) GetAnnouncementsByCourse(courseID string, week *in
 			a.week,
 			a.is_public,
 			a.content,
+			a.is_pinned,
+			a.expires_at,
 			a.created_at,
 			a.updated_at,
 			a.created_by,
@@ -78,10 +85,13 @@ func (s *AnnouncementService) GetAnnouncementsByCourse(courseID string, week *in
 		`).
 		Joins("LEFT JOIN users u ON a.created_by = u.user_id").
 		Where("a.course_id = ?", courseID)
+	if !includeExpired {
+		query = query.Where("a.expires_at IS NULL OR a.expires_at > ?", now)
+	}
 
 	// Execute query with optimized pagination
 	if err := query.
-		Order("a.created_at DESC").
+		Order("a.is_pinned DESC, a.created_at DESC").
 		Limit(limit).
 		Offset(offset).
 		Find(&announcements).Error; err != nil {
@@ -115,6 +125,8 @@ func (s *AnnouncementService) GetAnnouncementsByCourseCursor(courseID string, we
 			a.week,
 			a.is_public,
 			a.content,
+			a.is_pinned,
+			a.expires_at,
 			a.created_at,
 			a.updated_at,
 			a.created_by,
@@ -189,6 +201,28 @@ func (s *AnnouncementService) UpdateAnnouncement(announcementID string, userID s
 	return nil
 }
 
+// SetPinned pins or unpins an announcement
+func (s *AnnouncementService) SetPinned(announcementID string, userID string, pinned bool) error {
+	var announcement models.Announcement
+	if err := s.db.First(&announcement, "material_id = ?", announcementID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New("announcement not found")
+		}
+		return err
+	}
+
+	// Check if user is the creator
+	if announcement.CreatedBy != userID {
+		return errors.New("only the creator can pin this announcement")
+	}
+
+	if err := s.db.Model(&announcement).Update("is_pinned", pinned).Error; err != nil {
+		return fmt.Errorf("failed to update announcement pin: %w", err)
+	}
+
+	return nil
+}
+
 // DeleteAnnouncement deletes an announcement
 func (s *AnnouncementService) DeleteAnnouncement(announcementID string, userID string) error {
 	// Check if announcement exists and user is the creator
@@ -213,8 +247,9 @@ func (s *AnnouncementService) DeleteAnnouncement(announcementID string, userID s
 	return nil
 }
 
-// GetRecentAnnouncements retrieves recent announcements across all courses for a user
-func (s *AnnouncementService) GetRecentAnnouncements(userID string, limit int) ([]models.Announcement, error) {
+// GetRecentAnnouncements retrieves recent announcements across all courses for a user.
+// Expired announcements are left out unless includeExpired is set.
+func (s *AnnouncementService) GetRecentAnnouncements(userID string, limit int, includeExpired bool) ([]models.Announcement, error) {
 	var announcements []models.Announcement
 
 	// Get user's enrolled courses
@@ -234,7 +269,11 @@ func (s *AnnouncementService) GetRecentAnnouncements(userID string, limit int) (
 	}
 
 	// Get recent announcements from enrolled courses
-	if err := s.db.Where("course_id IN ?", courseIDs).
+	query := s.db.Where("course_id IN ?", courseIDs)
+	if !includeExpired {
+		query = query.Where("expires_at IS NULL OR expires_at > ?", time.Now())
+	}
+	if err := query.
 		Preload("Creator").
 		Preload("Course").
 		Order("created_at DESC").
@@ -0,0 +1,62 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	models "github.com/Project-DSView/backend/go/internal/domain/entities"
+	"github.com/Project-DSView/backend/go/internal/domain/enums"
+	"github.com/Project-DSView/backend/go/pkg/logger"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ErrAttemptLimitReached is returned when a student submits to an exercise after using all its attempts
+var ErrAttemptLimitReached = errors.New("you have used all attempts for this exercise")
+
+// claimAttempt counts a submission against the student's attempts on an exercise, creating
+// their progress row on the first one. The increment and the limit check are a single
+// statement, so parallel submissions cannot both read the last free attempt and pass.
+// maxAttempts 0 counts without a limit.
+func claimAttempt(db *gorm.DB, userID, materialID string, materialType enums.MaterialType, maxAttempts int) error {
+	now := time.Now()
+	result := db.Exec(`INSERT INTO student_progress
+			(progress_id, user_id, material_id, material_type, status, score, attempt_count, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, 0, 1, ?, ?)
+		ON CONFLICT (user_id, material_id) DO UPDATE
+		SET attempt_count = student_progress.attempt_count + 1, updated_at = EXCLUDED.updated_at
+		WHERE CAST(? AS INT) = 0 OR student_progress.attempt_count < ?`,
+		uuid.New().String(), userID, materialID, string(materialType), enums.ProgressNotStarted, now, now,
+		maxAttempts, maxAttempts)
+	if result.Error != nil {
+		return fmt.Errorf("claim attempt: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrAttemptLimitReached
+	}
+	return nil
+}
+
+// refundAttempt gives back an attempt claimAttempt counted, for a submission that was
+// cancelled or could not be stored
+func refundAttempt(db *gorm.DB, userID, materialID string) error {
+	if err := db.Model(&models.StudentProgress{}).
+		Where("user_id = ? AND material_id = ? AND attempt_count > 0", userID, materialID).
+		Update("attempt_count", gorm.Expr("attempt_count - 1")).Error; err != nil {
+		return fmt.Errorf("refund attempt: %w", err)
+	}
+	return nil
+}
+
+// refundUnstoredAttempt is deferred after claiming an attempt whose submission is stored
+// outside the claim's transaction: unless *stored was set, the submission failed first
+// and the attempt is given back
+func refundUnstoredAttempt(db *gorm.DB, userID, materialID string, stored *bool) {
+	if *stored {
+		return
+	}
+	if err := refundAttempt(db, userID, materialID); err != nil {
+		logger.Warnf("Failed to refund the attempt of user %s on material %s: %v", userID, materialID, err)
+	}
+}
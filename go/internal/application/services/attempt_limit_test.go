@@ -0,0 +1,158 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+
+	models "github.com/Project-DSView/backend/go/internal/domain/entities"
+	"github.com/Project-DSView/backend/go/internal/domain/enums"
+)
+
+func TestClaimAttempt(t *testing.T) {
+	tests := []struct {
+		name        string
+		maxAttempts int
+		claims      int
+		wantClaimed int
+	}{
+		{name: "unlimited", maxAttempts: 0, claims: 5, wantClaimed: 5},
+		{name: "under the limit", maxAttempts: 3, claims: 2, wantClaimed: 2},
+		{name: "at the limit", maxAttempts: 3, claims: 3, wantClaimed: 3},
+		{name: "over the limit", maxAttempts: 3, claims: 5, wantClaimed: 3},
+		{name: "single attempt", maxAttempts: 1, claims: 2, wantClaimed: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := newTestDB(t, &models.StudentProgress{})
+
+			claimed := 0
+			for i := 0; i < tt.claims; i++ {
+				err := claimAttempt(db, "student-1", "code-1", enums.MaterialTypeCodeExercise, tt.maxAttempts)
+				switch {
+				case err == nil:
+					claimed++
+				case !errors.Is(err, ErrAttemptLimitReached):
+					t.Fatalf("claim %d: %v", i+1, err)
+				}
+			}
+			if claimed != tt.wantClaimed {
+				t.Errorf("claimed %d attempts, want %d", claimed, tt.wantClaimed)
+			}
+
+			var progress models.StudentProgress
+			if err := db.First(&progress, "user_id = ? AND material_id = ?", "student-1", "code-1").Error; err != nil {
+				t.Fatalf("load progress: %v", err)
+			}
+			if progress.AttemptCount != tt.wantClaimed {
+				t.Errorf("attempt_count = %d, want %d", progress.AttemptCount, tt.wantClaimed)
+			}
+		})
+	}
+}
+
+// TestClaimAttemptConcurrently checks that parallel submissions cannot both take the
+// last free attempt
+func TestClaimAttemptConcurrently(t *testing.T) {
+	const maxAttempts, submitters = 3, 10
+	db := newTestDB(t, &models.StudentProgress{})
+
+	var wg sync.WaitGroup
+	errs := make(chan error, submitters)
+	for i := 0; i < submitters; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs <- claimAttempt(db, "student-1", "code-1", enums.MaterialTypeCodeExercise, maxAttempts)
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	claimed := 0
+	for err := range errs {
+		switch {
+		case err == nil:
+			claimed++
+		case !errors.Is(err, ErrAttemptLimitReached):
+			t.Errorf("unexpected error: %v", err)
+		}
+	}
+	if claimed != maxAttempts {
+		t.Errorf("claimed %d attempts, want %d", claimed, maxAttempts)
+	}
+}
+
+// TestSubmitCodeAttemptLimit checks that code submissions stop at the exercise's limit
+// while a teacher submitting on the student's behalf is counted but not capped
+func TestSubmitCodeAttemptLimit(t *testing.T) {
+	tests := []struct {
+		name string
+		// onBehalf submits as teacher-1 for student-1
+		onBehalf     bool
+		wantAccepted int
+	}{
+		{name: "student", wantAccepted: 2},
+		{name: "teacher on behalf", onBehalf: true, wantAccepted: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, materialID := newCodeSubmissionDB(t)
+			if err := db.Model(&models.CodeExercise{}).Where("material_id = ?", materialID).Update("max_attempts", 2).Error; err != nil {
+				t.Fatalf("set max attempts: %v", err)
+			}
+			svc := newCodeSubmissionService(t, db, &uploadedCode{})
+
+			accepted := 0
+			for i := 0; i < 3; i++ {
+				var err error
+				code := fmt.Sprintf("print(%d)", i)
+				if tt.onBehalf {
+					_, err = svc.SubmitOnBehalf("teacher-1", "student-1", materialID, code)
+				} else {
+					_, err = svc.SubmitMaterialExercise("student-1", materialID, code)
+				}
+				switch {
+				case err == nil:
+					accepted++
+				case !errors.Is(err, ErrAttemptLimitReached):
+					t.Fatalf("submission %d: %v", i+1, err)
+				}
+			}
+			if accepted != tt.wantAccepted {
+				t.Errorf("accepted %d submissions, want %d", accepted, tt.wantAccepted)
+			}
+		})
+	}
+}
+
+// TestSubmitCodeRefundsFailedAttempt checks that a submission failing before it is
+// stored, here on the upload, does not use up the student's only attempt
+func TestSubmitCodeRefundsFailedAttempt(t *testing.T) {
+	db, materialID := newCodeSubmissionDB(t)
+	if err := db.Model(&models.CodeExercise{}).Where("material_id = ?", materialID).Update("max_attempts", 1).Error; err != nil {
+		t.Fatalf("set max attempts: %v", err)
+	}
+
+	store := &uploadedCode{err: errors.New("storage unavailable")}
+	svc := newCodeSubmissionService(t, db, store)
+	if _, err := svc.SubmitMaterialExercise("student-1", materialID, "print(3)"); err == nil {
+		t.Fatal("submission succeeded despite the failed upload")
+	}
+
+	var progress models.StudentProgress
+	if err := db.First(&progress, "user_id = ? AND material_id = ?", "student-1", materialID).Error; err != nil {
+		t.Fatalf("load progress: %v", err)
+	}
+	if progress.AttemptCount != 0 {
+		t.Errorf("attempt_count = %d after the failed upload, want 0", progress.AttemptCount)
+	}
+
+	store.err = nil
+	if _, err := svc.SubmitMaterialExercise("student-1", materialID, "print(3)"); err != nil {
+		t.Fatalf("retry after the failed upload: %v", err)
+	}
+}
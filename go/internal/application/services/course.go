@@ -1,30 +1,56 @@
 package services
 
 import (
+	"context"
 	"fmt"
 	"strings"
+	"sync"
 	"time"
 
 	models "github.com/Project-DSView/backend/go/internal/domain/entities"
 	"github.com/Project-DSView/backend/go/internal/domain/enums"
 	"github.com/Project-DSView/backend/go/pkg/logger"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type CourseService struct {
 	db                *gorm.DB
 	userService       *UserService
 	enrollmentService *EnrollmentService
+	materialService   *CourseMaterialService
+	adminEmails       map[string]bool // Lower-cased; see SetAdminEmails
+
+	// Archiving; see ArchiveCourse
+	queueService         *QueueService
+	archiveJobPolicy     ArchiveJobPolicy
+	archiveFinishTimeout time.Duration
+	archivePollInterval  time.Duration
+	archiveCtx           context.Context // Cancelled by StopArchiveWaits
+	stopArchiveWaits     context.CancelFunc
+	archiveWaits         sync.WaitGroup
 }
 
 func NewCourseService(db *gorm.DB, userService *UserService, enrollmentService *EnrollmentService) *CourseService {
+	archiveCtx, stopArchiveWaits := context.WithCancel(context.Background())
 	return &CourseService{
 		db:                db,
 		userService:       userService,
 		enrollmentService: enrollmentService,
+
+		archiveJobPolicy:     ArchiveJobsFinish,
+		archiveFinishTimeout: defaultArchiveFinishTimeout,
+		archivePollInterval:  archiveFinishPollInterval,
+		archiveCtx:           archiveCtx,
+		stopArchiveWaits:     stopArchiveWaits,
 	}
 }
 
+// SetCourseMaterialService sets the material service used when copying courses
+func (s *CourseService) SetCourseMaterialService(materialService *CourseMaterialService) {
+	s.materialService = materialService
+}
+
 func (s *CourseService) CreateCourse(courseData *models.Course) error {
 	return s.db.Create(courseData).Error
 }
@@ -133,6 +159,139 @@ func (s *CourseService) UpdateCourse(courseID string, updates map[string]interfa
 	return nil
 }
 
+// CopyCourse creates a new course owned by userID with the structure of sourceID:
+// settings, weeks, materials, test cases and files. Materials that refer to each other
+// (prerequisites, linked announcements) refer to each other's copies. Enrollments,
+// submissions and progress are not copied, and the new course gets its own enroll key.
+func (s *CourseService) CopyCourse(sourceID, newName, userID string) (*models.Course, error) {
+	if s.materialService == nil {
+		return nil, fmt.Errorf("course material service is not configured")
+	}
+
+	var source models.Course
+	if err := s.db.Where("course_id = ?", sourceID).First(&source).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("course not found")
+		}
+		return nil, fmt.Errorf("failed to get course: %w", err)
+	}
+
+	newName = strings.TrimSpace(newName)
+	if newName == "" {
+		newName = source.Name + " (Copy)"
+	}
+
+	newCourse := &models.Course{
+		Name:        newName,
+		Description: source.Description,
+		CreatedBy:   userID,
+		Status:      enums.CourseStatusActive,
+		Settings:    source.Settings,
+	}
+
+	var copiedFiles []string
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit(clause.Associations).Create(newCourse).Error; err != nil {
+			return fmt.Errorf("failed to create course: %w", err)
+		}
+
+		if source.ImageURL != "" && s.materialService.storageService != nil {
+			imageURL, err := s.materialService.storageService.CopyFileToCourse(context.Background(), source.ImageURL, newCourse.CourseID)
+			if err != nil {
+				return fmt.Errorf("failed to copy course image: %w", err)
+			}
+			copiedFiles = append(copiedFiles, imageURL)
+			if err := tx.Model(&models.Course{}).Where("course_id = ?", newCourse.CourseID).Update("image_url", imageURL).Error; err != nil {
+				return fmt.Errorf("failed to set course image: %w", err)
+			}
+		}
+
+		var weeks []models.CourseWeek
+		if err := tx.Where("course_id = ?", sourceID).Order("week_number ASC").Find(&weeks).Error; err != nil {
+			return fmt.Errorf("failed to get course weeks: %w", err)
+		}
+		for i := range weeks {
+			weeks[i].CourseWeekID = ""
+			weeks[i].CourseID = newCourse.CourseID
+			weeks[i].CreatedBy = userID
+			weeks[i].CreatedAt = time.Time{}
+			weeks[i].UpdatedAt = time.Time{}
+			if err := tx.Omit(clause.Associations).Create(&weeks[i]).Error; err != nil {
+				return fmt.Errorf("failed to copy week %d: %w", weeks[i].WeekNumber, err)
+			}
+		}
+
+		var materials []models.CourseMaterial
+		if err := tx.Where("course_id = ?", sourceID).Order("week ASC, created_at ASC").Find(&materials).Error; err != nil {
+			return fmt.Errorf("failed to get course materials: %w", err)
+		}
+		idMap := make(map[string]string, len(materials))
+		for i := range materials {
+			cloneID, files, err := s.materialService.CloneMaterial(tx, &materials[i], newCourse.CourseID, userID)
+			copiedFiles = append(copiedFiles, files...)
+			if err != nil {
+				return fmt.Errorf("failed to copy material %s: %w", materials[i].MaterialID, err)
+			}
+			idMap[materials[i].MaterialID] = cloneID
+		}
+
+		// Prerequisites and linked materials must point into the new course
+		return s.materialService.RemapClonedReferences(tx, idMap)
+	})
+	if err != nil {
+		// Remove files copied before the failure so they don't leak in storage
+		for _, url := range copiedFiles {
+			if delErr := s.materialService.storageService.DeleteFile(context.Background(), url); delErr != nil {
+				logger.Warnf("Failed to remove copied file %s: %v", url, delErr)
+			}
+		}
+		return nil, err
+	}
+
+	return s.GetCourseByID(newCourse.CourseID)
+}
+
+// GetCourseSettings returns the feature-flag settings of a course
+func (s *CourseService) GetCourseSettings(courseID string) (*models.CourseSettings, error) {
+	var course models.Course
+	if err := s.db.Select("course_id", "settings").Where("course_id = ?", courseID).First(&course).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("course not found")
+		}
+		return nil, fmt.Errorf("failed to get course settings: %w", err)
+	}
+	return &course.Settings, nil
+}
+
+// UpdateCourseSettings merges the provided settings into the course's current settings
+func (s *CourseService) UpdateCourseSettings(courseID string, updates models.CourseSettings) (*models.CourseSettings, error) {
+	if updates.RetryWindowHours != nil && *updates.RetryWindowHours < 0 {
+		return nil, fmt.Errorf("retry_window_hours must not be negative")
+	}
+	if updates.WeekIncrement != nil && *updates.WeekIncrement < 0 {
+		return nil, fmt.Errorf("week_increment must not be negative")
+	}
+	if updates.DeadlineOffsetDays != nil && *updates.DeadlineOffsetDays < 0 {
+		return nil, fmt.Errorf("deadline_offset_days must not be negative")
+	}
+	if updates.SubmissionRetentionDays != nil && *updates.SubmissionRetentionDays < models.RetainSubmissionsIndefinitely {
+		return nil, fmt.Errorf("submission_retention_days must be %d (keep indefinitely) or not negative", models.RetainSubmissionsIndefinitely)
+	}
+
+	current, err := s.GetCourseSettings(courseID)
+	if err != nil {
+		return nil, err
+	}
+
+	merged := current.Merge(updates)
+	if err := s.db.Model(&models.Course{}).
+		Where("course_id = ?", courseID).
+		Update("settings", merged).Error; err != nil {
+		return nil, fmt.Errorf("failed to update course settings: %w", err)
+	}
+	return &merged, nil
+}
+
 func (s *CourseService) DeleteCourse(courseID string) error {
 	return s.db.Transaction(func(tx *gorm.DB) error {
 
@@ -243,13 +402,6 @@ func (s *CourseService) GetCourseStatistics() (map[string]interface{}, error) {
 	return stats, nil
 }
 
-func (s *CourseService) CanUserModifyCourse(userID, courseID string, isTeacher bool) (bool, error) {
-	if isTeacher {
-		return true, nil
-	}
-	return false, nil
-}
-
 func (s *CourseService) GetCourseByEnrollKey(enrollKey string) (*models.Course, error) {
 	var courseModel models.Course
 	if err := s.db.Where("enroll_key = ?", enrollKey).First(&courseModel).Error; err != nil {
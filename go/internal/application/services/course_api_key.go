@@ -0,0 +1,215 @@
+package services
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	models "github.com/Project-DSView/backend/go/internal/domain/entities"
+	"github.com/Project-DSView/backend/go/internal/domain/enums"
+	"github.com/Project-DSView/backend/go/internal/types"
+	"github.com/Project-DSView/backend/go/pkg/authz"
+	"gorm.io/gorm"
+)
+
+// CourseAPIKeyPrefix marks raw keys as course-scoped so they are easy to recognise in logs and configs
+const CourseAPIKeyPrefix = "dsv_ck_"
+
+type CourseAPIKeyService struct {
+	db            *gorm.DB
+	courseService *CourseService
+}
+
+func NewCourseAPIKeyService(db *gorm.DB, courseService *CourseService) *CourseAPIKeyService {
+	return &CourseAPIKeyService{
+		db:            db,
+		courseService: courseService,
+	}
+}
+
+// hashCourseAPIKey returns the hex SHA-256 of a raw key, which is what gets stored
+func hashCourseAPIKey(rawKey string) string {
+	sum := sha256.Sum256([]byte(rawKey))
+	return hex.EncodeToString(sum[:])
+}
+
+// IsCourseAPIKey reports whether a raw key looks like a course-scoped key
+func IsCourseAPIKey(rawKey string) bool {
+	return strings.HasPrefix(rawKey, CourseAPIKeyPrefix)
+}
+
+// CreateKey mints a read-only key bound to the given courses. The raw key is only
+// returned here; afterwards just its hash and display prefix are kept.
+func (s *CourseAPIKeyService) CreateKey(userID, name string, courseIDs []string, expiresAt *time.Time) (*models.CourseAPIKey, string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, "", errors.New("name is required")
+	}
+	if len(courseIDs) == 0 {
+		return nil, "", errors.New("at least one course ID is required")
+	}
+	if expiresAt != nil && !expiresAt.After(time.Now()) {
+		return nil, "", errors.New("expires_at must be in the future")
+	}
+
+	// Only the course creator may expose a course to an external system
+	seen := make(map[string]bool, len(courseIDs))
+	uniqueIDs := make([]string, 0, len(courseIDs))
+	for _, courseID := range courseIDs {
+		if courseID == "" || seen[courseID] {
+			continue
+		}
+		seen[courseID] = true
+
+		course, err := s.courseService.GetCourseByID(courseID)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to get course: %w", err)
+		}
+		if course == nil {
+			return nil, "", errors.New("course not found")
+		}
+		if !authz.ManagesCourse(course, userID) {
+			return nil, "", errors.New("only course creator can create API keys")
+		}
+		uniqueIDs = append(uniqueIDs, courseID)
+	}
+
+	token, err := generateToken()
+	if err != nil {
+		return nil, "", err
+	}
+	secret, err := generateToken()
+	if err != nil {
+		return nil, "", err
+	}
+	rawKey := CourseAPIKeyPrefix + token + secret
+
+	courseIDsJSON, err := json.Marshal(uniqueIDs)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to encode course IDs: %w", err)
+	}
+
+	key := models.CourseAPIKey{
+		Name:      name,
+		KeyHash:   hashCourseAPIKey(rawKey),
+		KeyPrefix: rawKey[:len(CourseAPIKeyPrefix)+8],
+		CourseIDs: types.JSONData(courseIDsJSON),
+		Role:      enums.APIKeyRoleReadOnly,
+		CreatedBy: userID,
+		ExpiresAt: expiresAt,
+	}
+
+	if err := s.db.Create(&key).Error; err != nil {
+		return nil, "", fmt.Errorf("failed to create API key: %w", err)
+	}
+
+	return &key, rawKey, nil
+}
+
+// ValidateKey looks up an active key by its raw value and records its use
+func (s *CourseAPIKeyService) ValidateKey(rawKey string) (*models.CourseAPIKey, error) {
+	var key models.CourseAPIKey
+	if err := s.db.Where("key_hash = ?", hashCourseAPIKey(rawKey)).First(&key).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.New("API key not found")
+		}
+		return nil, fmt.Errorf("failed to get API key: %w", err)
+	}
+
+	if key.RevokedAt != nil {
+		return nil, errors.New("API key has been revoked")
+	}
+	if !key.IsActive() {
+		return nil, errors.New("API key has expired")
+	}
+
+	now := time.Now()
+	s.db.Model(&key).UpdateColumn("last_used_at", now)
+	key.LastUsedAt = &now
+
+	return &key, nil
+}
+
+// ListKeys returns the keys created by a user, newest first
+func (s *CourseAPIKeyService) ListKeys(userID string) ([]models.CourseAPIKey, error) {
+	var keys []models.CourseAPIKey
+	if err := s.db.Where("created_by = ?", userID).Order("created_at DESC").Find(&keys).Error; err != nil {
+		return nil, fmt.Errorf("failed to get API keys: %w", err)
+	}
+	return keys, nil
+}
+
+// RevokeKey revokes a key. Its creator may do so, and so may the owner of a course the
+// key is bound to, e.g. after the course was transferred to them.
+func (s *CourseAPIKeyService) RevokeKey(keyID, userID string) error {
+	var key models.CourseAPIKey
+	if err := s.db.Where("key_id = ?", keyID).First(&key).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return errors.New("API key not found")
+		}
+		return fmt.Errorf("failed to get API key: %w", err)
+	}
+
+	if key.CreatedBy != userID {
+		ownsCourse, err := s.ownsBoundCourse(&key, userID)
+		if err != nil {
+			return err
+		}
+		if !ownsCourse {
+			return errors.New("only key creator or course owner can revoke this API key")
+		}
+	}
+	if key.RevokedAt != nil {
+		return nil
+	}
+
+	if err := s.db.Model(&key).Update("revoked_at", time.Now()).Error; err != nil {
+		return fmt.Errorf("failed to revoke API key: %w", err)
+	}
+	return nil
+}
+
+// ownsBoundCourse reports whether the user manages one of the courses the key is bound to
+func (s *CourseAPIKeyService) ownsBoundCourse(key *models.CourseAPIKey, userID string) (bool, error) {
+	for _, courseID := range key.BoundCourseIDs() {
+		canManage, err := authz.CanManageCourse(s.db, userID, courseID)
+		if errors.Is(err, authz.ErrCourseNotFound) {
+			continue
+		}
+		if err != nil {
+			return false, err
+		}
+		if canManage {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// revokeCourseKeys revokes the active keys createdBy made that are bound to the course,
+// and returns how many it revoked. A key bound to other courses as well is revoked as a
+// whole.
+func revokeCourseKeys(tx *gorm.DB, courseID, createdBy string) (int, error) {
+	var keys []models.CourseAPIKey
+	if err := tx.Where("created_by = ? AND revoked_at IS NULL", createdBy).Find(&keys).Error; err != nil {
+		return 0, fmt.Errorf("failed to get API keys: %w", err)
+	}
+	var keyIDs []string
+	for i := range keys {
+		if keys[i].CanAccessCourse(courseID) {
+			keyIDs = append(keyIDs, keys[i].KeyID)
+		}
+	}
+	if len(keyIDs) == 0 {
+		return 0, nil
+	}
+	if err := tx.Model(&models.CourseAPIKey{}).Where("key_id IN ?", keyIDs).
+		Update("revoked_at", time.Now()).Error; err != nil {
+		return 0, fmt.Errorf("failed to revoke API keys: %w", err)
+	}
+	return len(keyIDs), nil
+}
@@ -0,0 +1,50 @@
+package services
+
+import (
+	"testing"
+
+	models "github.com/Project-DSView/backend/go/internal/domain/entities"
+	"github.com/Project-DSView/backend/go/internal/types"
+)
+
+func TestRevokeKey(t *testing.T) {
+	tests := []struct {
+		name        string
+		userID      string
+		wantErr     string
+		wantRevoked bool
+	}{
+		{name: "key creator", userID: "old-owner", wantRevoked: true},
+		{name: "owner of a bound course", userID: "new-owner", wantRevoked: true},
+		{name: "owner of another course", userID: "other-owner", wantErr: "only key creator or course owner can revoke this API key"},
+		{name: "student", userID: "student-1", wantErr: "only key creator or course owner can revoke this API key"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := newTestDB(t, &models.Course{}, &models.CourseAPIKey{})
+			createRows(t, db,
+				// course-1 was transferred to new-owner after old-owner made the key
+				&models.Course{CourseID: "course-1", Name: "Data Structures", CreatedBy: "new-owner"},
+				&models.Course{CourseID: "course-3", Name: "Algorithms", CreatedBy: "other-owner"},
+				&models.CourseAPIKey{KeyID: "key-1", KeyHash: "h1", KeyPrefix: "p1", Name: "LMS", CourseIDs: types.JSONData(`["missing-course","course-1"]`), CreatedBy: "old-owner"},
+			)
+
+			err := NewCourseAPIKeyService(db, nil).RevokeKey("key-1", tt.userID)
+			switch {
+			case tt.wantErr == "" && err != nil:
+				t.Fatalf("RevokeKey() error = %v", err)
+			case tt.wantErr != "" && (err == nil || err.Error() != tt.wantErr):
+				t.Fatalf("RevokeKey() error = %v, want %s", err, tt.wantErr)
+			}
+
+			var key models.CourseAPIKey
+			if err := db.First(&key, "key_id = ?", "key-1").Error; err != nil {
+				t.Fatalf("load key: %v", err)
+			}
+			if revoked := key.RevokedAt != nil; revoked != tt.wantRevoked {
+				t.Errorf("revoked = %v, want %v", revoked, tt.wantRevoked)
+			}
+		})
+	}
+}
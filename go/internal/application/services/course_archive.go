@@ -0,0 +1,203 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	models "github.com/Project-DSView/backend/go/internal/domain/entities"
+	"github.com/Project-DSView/backend/go/internal/domain/enums"
+	"github.com/Project-DSView/backend/go/pkg/logger"
+	"gorm.io/gorm"
+)
+
+// ErrCourseArchived is returned by the submission entry points of an archived course.
+// It wraps ErrSubmissionNotAllowed, so callers checking for that treat it the same way.
+var ErrCourseArchived = fmt.Errorf("%w: course is archived", ErrSubmissionNotAllowed)
+
+// ArchiveJobPolicy decides what happens to a course's queued grading work when it is archived
+type ArchiveJobPolicy string
+
+const (
+	// ArchiveJobsFinish keeps the course's consumers running until its pending and
+	// processing jobs are done, cancelling whatever is left after the finish timeout
+	ArchiveJobsFinish ArchiveJobPolicy = "finish"
+	// ArchiveJobsCancel cancels the course's pending and running jobs straight away
+	ArchiveJobsCancel ArchiveJobPolicy = "cancel"
+)
+
+// ParseArchiveJobPolicy parses a QUEUE_ARCHIVE_JOB_POLICY value
+func ParseArchiveJobPolicy(s string) (ArchiveJobPolicy, error) {
+	switch policy := ArchiveJobPolicy(s); policy {
+	case ArchiveJobsFinish, ArchiveJobsCancel:
+		return policy, nil
+	}
+	return "", fmt.Errorf("unknown archive job policy %q (expected %q or %q)", s, ArchiveJobsFinish, ArchiveJobsCancel)
+}
+
+// Defaults used until SetArchiveJobPolicy is called
+const (
+	defaultArchiveFinishTimeout = 30 * time.Minute
+	// archiveFinishPollInterval is how often an archived course's remaining jobs are counted
+	archiveFinishPollInterval = 5 * time.Second
+)
+
+// SetQueueService sets the queue service whose consumers follow the course's status
+func (s *CourseService) SetQueueService(queueService *QueueService) {
+	s.queueService = queueService
+}
+
+// SetArchiveJobPolicy sets how ArchiveCourse handles the course's queued jobs, and how
+// long ArchiveJobsFinish waits for them
+func (s *CourseService) SetArchiveJobPolicy(policy ArchiveJobPolicy, finishTimeout time.Duration) {
+	s.archiveJobPolicy = policy
+	if finishTimeout > 0 {
+		s.archiveFinishTimeout = finishTimeout
+	}
+}
+
+// checkCourseNotArchived returns ErrCourseArchived when the course is archived
+func checkCourseNotArchived(db *gorm.DB, courseID string) error {
+	var course models.Course
+	if err := db.Select("course_id", "status").First(&course, "course_id = ?", courseID).Error; err != nil {
+		return fmt.Errorf("failed to get course details: %w", err)
+	}
+	if course.Status == enums.CourseStatusArchived {
+		return ErrCourseArchived
+	}
+	return nil
+}
+
+// setCourseStatus moves the course from one status to another. changed is false when
+// the course already had the new status.
+func (s *CourseService) setCourseStatus(courseID string, from, to enums.CourseStatus) (changed bool, err error) {
+	res := s.db.Model(&models.Course{}).
+		Where("course_id = ? AND status = ?", courseID, from).
+		Update("status", to)
+	if res.Error != nil {
+		return false, fmt.Errorf("failed to update course status: %w", res.Error)
+	}
+	if res.RowsAffected > 0 {
+		return true, nil
+	}
+
+	var count int64
+	if err := s.db.Model(&models.Course{}).Where("course_id = ?", courseID).Count(&count).Error; err != nil {
+		return false, fmt.Errorf("failed to get course: %w", err)
+	}
+	if count == 0 {
+		return false, fmt.Errorf("course not found")
+	}
+	return false, nil
+}
+
+// ArchiveCourse archives a course. From then on its exercises refuse new submissions
+// with ErrCourseArchived, its outstanding grading jobs are finished or cancelled
+// according to the archive job policy, and its queue consumers on this instance are
+// stopped. Archiving an archived course does nothing.
+func (s *CourseService) ArchiveCourse(courseID string) error {
+	changed, err := s.setCourseStatus(courseID, enums.CourseStatusActive, enums.CourseStatusArchived)
+	if err != nil || !changed {
+		return err
+	}
+	logger.Infof("Course %s archived", courseID)
+	if s.queueService == nil {
+		return nil
+	}
+
+	if s.archiveJobPolicy == ArchiveJobsCancel {
+		s.cancelArchivedCourseJobs(courseID)
+		s.queueService.StopCourseConsumers(courseID)
+		return nil
+	}
+	s.archiveWaits.Add(1)
+	go func() {
+		defer s.archiveWaits.Done()
+		s.finishArchivedCourseJobs(s.archiveCtx, courseID)
+	}()
+	return nil
+}
+
+// StopArchiveWaits stops waiting for the jobs of courses archived with ArchiveJobsFinish
+// and returns once the waits have ended. It is called on shutdown; the jobs are left as
+// they are.
+func (s *CourseService) StopArchiveWaits() {
+	s.stopArchiveWaits()
+	s.archiveWaits.Wait()
+}
+
+// ReactivateCourse makes an archived course active again and restarts its queue
+// consumers, which pick up any messages left in its queues. A consumer that fails to
+// start is logged and reported by the queue consumer listing. Reactivating an active
+// course does nothing.
+func (s *CourseService) ReactivateCourse(courseID string) error {
+	changed, err := s.setCourseStatus(courseID, enums.CourseStatusArchived, enums.CourseStatusActive)
+	if err != nil || !changed {
+		return err
+	}
+	logger.Infof("Course %s reactivated", courseID)
+	if s.queueService == nil {
+		return nil
+	}
+
+	if err := s.queueService.StartCourseConsumers(courseID); err != nil {
+		logger.Warnf("Failed to restart queue consumers for reactivated course %s: %v", courseID, err)
+	}
+	return nil
+}
+
+// finishArchivedCourseJobs waits for an archived course's jobs to finish, up to the
+// finish timeout, then cancels the rest and stops its consumers. It stops waiting
+// without touching anything if the course is reactivated meanwhile or ctx is done.
+func (s *CourseService) finishArchivedCourseJobs(ctx context.Context, courseID string) {
+	ticker := time.NewTicker(s.archivePollInterval)
+	defer ticker.Stop()
+
+	deadline := time.Now().Add(s.archiveFinishTimeout)
+	for {
+		var course models.Course
+		if err := s.db.Select("course_id", "status").First(&course, "course_id = ?", courseID).Error; err != nil {
+			if !errors.Is(err, gorm.ErrRecordNotFound) {
+				logger.Warnf("Failed to get archived course %s: %v", courseID, err)
+			}
+			break
+		}
+		if course.Status != enums.CourseStatusArchived {
+			return
+		}
+
+		remaining, err := s.queueService.CountActiveCourseJobs(courseID)
+		if err != nil {
+			logger.Warnf("Failed to count jobs of archived course %s: %v", courseID, err)
+			break
+		}
+		if remaining == 0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			logger.Warnf("Archived course %s still has %d jobs after %s, cancelling them", courseID, remaining, s.archiveFinishTimeout)
+			s.cancelArchivedCourseJobs(courseID)
+			break
+		}
+		select {
+		case <-ctx.Done():
+			logger.Infof("Stopped waiting for the jobs of archived course %s", courseID)
+			return
+		case <-ticker.C:
+		}
+	}
+
+	s.queueService.StopCourseConsumers(courseID)
+}
+
+// cancelArchivedCourseJobs cancels an archived course's jobs, logging the outcome
+func (s *CourseService) cancelArchivedCourseJobs(courseID string) {
+	cancelled, err := s.queueService.CancelCourseJobs(courseID)
+	if err != nil {
+		logger.Warnf("Failed to cancel jobs of archived course %s: %v", courseID, err)
+	}
+	if cancelled > 0 {
+		logger.Infof("Cancelled %d jobs of archived course %s", cancelled, courseID)
+	}
+}
@@ -0,0 +1,177 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	models "github.com/Project-DSView/backend/go/internal/domain/entities"
+	"github.com/Project-DSView/backend/go/internal/domain/enums"
+	"gorm.io/gorm"
+)
+
+// newArchiveTest returns a course service for an active course-1 with one pending code
+// execution job, job-1, and a running consumer; the returned context is done once the
+// course's consumers are stopped
+func newArchiveTest(t *testing.T, policy ArchiveJobPolicy, finishTimeout time.Duration) (*CourseService, *gorm.DB, context.Context) {
+	t.Helper()
+	db := newTestDB(t, &models.Course{}, &models.CourseMaterial{}, &models.Submission{}, &models.QueueJob{})
+	courseID := "course-1"
+	createRows(t, db,
+		&models.Course{CourseID: courseID, Name: "Data Structures", CreatedBy: "teacher-1", Status: enums.CourseStatusActive},
+		&models.QueueJob{ID: "job-1", Type: enums.QueueTypeCodeExecution, Status: enums.QueueStatusPending, UserID: "student-1", CourseID: &courseID})
+
+	queueSvc := NewQueueService(db, nil, nil)
+	consumerCtx, stopConsumer := context.WithCancel(context.Background())
+	queueSvc.courseConsumerStop[courseID] = stopConsumer
+
+	svc := NewCourseService(db, nil, nil)
+	svc.SetQueueService(queueSvc)
+	svc.SetArchiveJobPolicy(policy, finishTimeout)
+	svc.archivePollInterval = time.Millisecond
+	t.Cleanup(svc.StopArchiveWaits)
+	return svc, db, consumerCtx
+}
+
+func assertArchiveState(t *testing.T, db *gorm.DB, wantCourse enums.CourseStatus, wantJob enums.QueueStatus) {
+	t.Helper()
+	var course models.Course
+	if err := db.First(&course, "course_id = ?", "course-1").Error; err != nil {
+		t.Fatalf("load course: %v", err)
+	}
+	if course.Status != wantCourse {
+		t.Errorf("course status = %s, want %s", course.Status, wantCourse)
+	}
+	var job models.QueueJob
+	if err := db.First(&job, "id = ?", "job-1").Error; err != nil {
+		t.Fatalf("load job: %v", err)
+	}
+	if job.Status != wantJob {
+		t.Errorf("job status = %s, want %s", job.Status, wantJob)
+	}
+}
+
+// waitFor polls until done reports true or a second has passed
+func waitFor(t *testing.T, what string, done func() bool) {
+	t.Helper()
+	for deadline := time.Now().Add(time.Second); time.Now().Before(deadline); time.Sleep(time.Millisecond) {
+		if done() {
+			return
+		}
+	}
+	t.Fatalf("timed out waiting for %s", what)
+}
+
+func TestArchiveCourseCancelPolicy(t *testing.T) {
+	svc, db, consumer := newArchiveTest(t, ArchiveJobsCancel, time.Hour)
+
+	if err := svc.ArchiveCourse("course-1"); err != nil {
+		t.Fatalf("ArchiveCourse() error = %v", err)
+	}
+	assertArchiveState(t, db, enums.CourseStatusArchived, enums.QueueStatusCancelled)
+	if consumer.Err() == nil {
+		t.Error("course consumers still running")
+	}
+}
+
+func TestArchiveCourseFinishPolicy(t *testing.T) {
+	t.Run("jobs finish", func(t *testing.T) {
+		svc, db, consumer := newArchiveTest(t, ArchiveJobsFinish, time.Hour)
+		if err := svc.ArchiveCourse("course-1"); err != nil {
+			t.Fatalf("ArchiveCourse() error = %v", err)
+		}
+		// The consumers keep running while the job is pending
+		time.Sleep(10 * time.Millisecond)
+		if consumer.Err() != nil {
+			t.Fatal("course consumers stopped before the job finished")
+		}
+
+		db.Model(&models.QueueJob{}).Where("id = ?", "job-1").Update("status", enums.QueueStatusCompleted)
+		waitFor(t, "consumers to stop", func() bool { return consumer.Err() != nil })
+		assertArchiveState(t, db, enums.CourseStatusArchived, enums.QueueStatusCompleted)
+	})
+
+	t.Run("finish timeout cancels the rest", func(t *testing.T) {
+		svc, db, consumer := newArchiveTest(t, ArchiveJobsFinish, 5*time.Millisecond)
+		if err := svc.ArchiveCourse("course-1"); err != nil {
+			t.Fatalf("ArchiveCourse() error = %v", err)
+		}
+		waitFor(t, "consumers to stop", func() bool { return consumer.Err() != nil })
+		assertArchiveState(t, db, enums.CourseStatusArchived, enums.QueueStatusCancelled)
+	})
+
+	t.Run("shutdown stops waiting", func(t *testing.T) {
+		svc, db, consumer := newArchiveTest(t, ArchiveJobsFinish, time.Hour)
+		if err := svc.ArchiveCourse("course-1"); err != nil {
+			t.Fatalf("ArchiveCourse() error = %v", err)
+		}
+
+		stopped := make(chan struct{})
+		go func() {
+			svc.StopArchiveWaits()
+			close(stopped)
+		}()
+		select {
+		case <-stopped:
+		case <-time.After(time.Second):
+			t.Fatal("StopArchiveWaits() did not return")
+		}
+		// Shutting down leaves the job and consumers alone
+		assertArchiveState(t, db, enums.CourseStatusArchived, enums.QueueStatusPending)
+		if consumer.Err() != nil {
+			t.Error("course consumers stopped by shutdown")
+		}
+	})
+
+	t.Run("reactivated while waiting", func(t *testing.T) {
+		svc, db, consumer := newArchiveTest(t, ArchiveJobsFinish, time.Hour)
+		if err := svc.ArchiveCourse("course-1"); err != nil {
+			t.Fatalf("ArchiveCourse() error = %v", err)
+		}
+		if err := svc.ReactivateCourse("course-1"); err != nil {
+			t.Fatalf("ReactivateCourse() error = %v", err)
+		}
+		// Give the wait a few polls to see the course is active again
+		time.Sleep(20 * time.Millisecond)
+		svc.StopArchiveWaits()
+		assertArchiveState(t, db, enums.CourseStatusActive, enums.QueueStatusPending)
+		if consumer.Err() != nil {
+			t.Error("consumers of the reactivated course were stopped")
+		}
+	})
+}
+
+func TestArchiveAndReactivateCourse(t *testing.T) {
+	svc, db, _ := newArchiveTest(t, ArchiveJobsCancel, time.Hour)
+
+	steps := []struct {
+		name string
+		run  func(string) error
+		want enums.CourseStatus
+	}{
+		{"archive", svc.ArchiveCourse, enums.CourseStatusArchived},
+		{"archive again", svc.ArchiveCourse, enums.CourseStatusArchived},
+		{"reactivate", svc.ReactivateCourse, enums.CourseStatusActive},
+		{"reactivate again", svc.ReactivateCourse, enums.CourseStatusActive},
+	}
+	for _, step := range steps {
+		if err := step.run("course-1"); err != nil {
+			t.Fatalf("%s: error = %v", step.name, err)
+		}
+		var course models.Course
+		if err := db.First(&course, "course_id = ?", "course-1").Error; err != nil {
+			t.Fatalf("load course: %v", err)
+		}
+		if course.Status != step.want {
+			t.Errorf("%s: status = %s, want %s", step.name, course.Status, step.want)
+		}
+		wantArchivedErr := step.want == enums.CourseStatusArchived
+		if err := checkCourseNotArchived(db, "course-1"); (err == ErrCourseArchived) != wantArchivedErr {
+			t.Errorf("%s: checkCourseNotArchived() = %v, want archived %v", step.name, err, wantArchivedErr)
+		}
+	}
+
+	if err := svc.ArchiveCourse("missing"); err == nil || err.Error() != "course not found" {
+		t.Errorf("ArchiveCourse(missing) error = %v, want course not found", err)
+	}
+}
@@ -0,0 +1,179 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	models "github.com/Project-DSView/backend/go/internal/domain/entities"
+	"github.com/Project-DSView/backend/go/internal/domain/enums"
+	"github.com/Project-DSView/backend/go/pkg/external"
+	"github.com/Project-DSView/backend/go/pkg/logger"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	// completionWebhookAttempts bounds how often a course.completed event is posted
+	completionWebhookAttempts = 4
+	// completionWebhookBackoff is the wait before the first retry; it doubles after each
+	completionWebhookBackoff = 2 * time.Second
+)
+
+// CourseCompletionService detects when a student finishes a course and emits a
+// course.completed event for downstream systems (e.g. certificate issuing)
+type CourseCompletionService struct {
+	db             *gorm.DB
+	notifier       *external.WebhookNotifier
+	webhookBackoff time.Duration
+}
+
+// NewCourseCompletionService creates a completion service; notifier may be nil
+func NewCourseCompletionService(db *gorm.DB, notifier *external.WebhookNotifier) *CourseCompletionService {
+	return &CourseCompletionService{
+		db:             db,
+		notifier:       notifier,
+		webhookBackoff: completionWebhookBackoff,
+	}
+}
+
+// CheckMaterialCompletion runs CheckCourseCompletion for the course that owns a material
+func (s *CourseCompletionService) CheckMaterialCompletion(userID, materialID string) {
+	var material models.CourseMaterial
+	if err := s.db.Select("course_id").Where("material_id = ?", materialID).First(&material).Error; err != nil {
+		logger.Warnf("Failed to look up material %s for completion check: %v", materialID, err)
+		return
+	}
+	s.CheckCourseCompletion(userID, material.CourseID)
+}
+
+// CheckCourseCompletion records the completion and fires the event the first
+// time every exercise in the course is completed. Later calls (resubmissions,
+// re-approvals) are no-ops because the completion row already exists.
+// Errors are logged rather than returned so progress updates never fail on it.
+func (s *CourseCompletionService) CheckCourseCompletion(userID, courseID string) {
+	completed, total, err := s.countCompletion(userID, courseID)
+	if err != nil {
+		logger.Warnf("Failed to check course completion for user %s in course %s: %v", userID, courseID, err)
+		return
+	}
+	if total == 0 || completed < total {
+		return
+	}
+
+	completion := models.CourseCompletion{
+		UserID:      userID,
+		CourseID:    courseID,
+		CompletedAt: time.Now(),
+	}
+	result := s.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&completion)
+	if result.Error != nil {
+		logger.Warnf("Failed to record course completion for user %s in course %s: %v", userID, courseID, result.Error)
+		return
+	}
+	if result.RowsAffected == 0 {
+		return // already completed before
+	}
+
+	logger.Infof("User %s completed course %s", userID, courseID)
+	if s.notifier != nil {
+		go s.notifyCompletion(completion, total)
+	}
+}
+
+// countCompletion returns how many of the course's published exercises the student has
+// completed, and how many there are. Hidden exercises, e.g. drafts, do not count.
+func (s *CourseCompletionService) countCompletion(userID, courseID string) (int64, int64, error) {
+	published := func(model interface{}) *gorm.DB {
+		return s.db.Model(model).Where("course_id = ? AND is_public = ?", courseID, true)
+	}
+
+	var total int64
+	for _, model := range []interface{}{&models.CodeExercise{}, &models.PDFExercise{}} {
+		var count int64
+		if err := published(model).Count(&count).Error; err != nil {
+			return 0, 0, fmt.Errorf("count exercises: %w", err)
+		}
+		total += count
+	}
+
+	var completed int64
+	if err := s.db.Model(&models.StudentProgress{}).
+		Where("user_id = ? AND status = ?", userID, enums.ProgressCompleted).
+		Where("material_id IN (?) OR material_id IN (?)",
+			published(&models.CodeExercise{}).Select("material_id"),
+			published(&models.PDFExercise{}).Select("material_id")).
+		Count(&completed).Error; err != nil {
+		return 0, 0, fmt.Errorf("count completed exercises: %w", err)
+	}
+
+	return completed, total, nil
+}
+
+// notifyCompletion sends the course.completed webhook and stamps notified_at on success
+func (s *CourseCompletionService) notifyCompletion(completion models.CourseCompletion, totalExercises int64) {
+	var user models.User
+	if err := s.db.Where("user_id = ?", completion.UserID).First(&user).Error; err != nil {
+		logger.Warnf("Failed to load user %s for completion event: %v", completion.UserID, err)
+		return
+	}
+	var course models.Course
+	if err := s.db.Where("course_id = ?", completion.CourseID).First(&course).Error; err != nil {
+		logger.Warnf("Failed to load course %s for completion event: %v", completion.CourseID, err)
+		return
+	}
+
+	var totalScore int
+	s.db.Model(&models.StudentCourseScore{}).
+		Where("user_id = ? AND course_id = ?", completion.UserID, completion.CourseID).
+		Select("total_score").Scan(&totalScore)
+
+	event := external.WebhookEvent{
+		Event: external.WebhookEventCourseCompleted,
+		Data: map[string]interface{}{
+			"completion_id": completion.CompletionID,
+			"student": map[string]interface{}{
+				"user_id":   user.UserID,
+				"firstname": user.FirstName,
+				"lastname":  user.LastName,
+				"email":     user.Email,
+			},
+			"course": map[string]interface{}{
+				"course_id": course.CourseID,
+				"name":      course.Name,
+			},
+			"total_exercises": totalExercises,
+			"total_score":     totalScore,
+			"completed_at":    completion.CompletedAt,
+		},
+		OccurredAt: completion.CompletedAt,
+	}
+
+	if err := s.notifyWithRetry(event); err != nil {
+		logger.Errorf("Failed to send course.completed webhook for user %s in course %s: %v", completion.UserID, completion.CourseID, err)
+		return
+	}
+
+	now := time.Now()
+	s.db.Model(&models.CourseCompletion{}).
+		Where("completion_id = ?", completion.CompletionID).
+		Update("notified_at", now)
+}
+
+// notifyWithRetry posts event, retrying failures with a doubling backoff; a receiver that
+// is down for a moment does not lose the event
+func (s *CourseCompletionService) notifyWithRetry(event external.WebhookEvent) error {
+	backoff := s.webhookBackoff
+	var err error
+	for attempt := 1; attempt <= completionWebhookAttempts; attempt++ {
+		if err = s.notifier.Notify(context.Background(), event); err == nil {
+			return nil
+		}
+		if attempt < completionWebhookAttempts {
+			logger.Warnf("course.completed webhook failed on attempt %d/%d, retrying in %s: %v", attempt, completionWebhookAttempts, backoff, err)
+			time.Sleep(backoff)
+			backoff *= 2
+		}
+	}
+	return fmt.Errorf("giving up after %d attempts: %w", completionWebhookAttempts, err)
+}
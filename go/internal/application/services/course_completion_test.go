@@ -0,0 +1,133 @@
+package services
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	models "github.com/Project-DSView/backend/go/internal/domain/entities"
+	"github.com/Project-DSView/backend/go/internal/domain/enums"
+	"github.com/Project-DSView/backend/go/pkg/external"
+)
+
+func TestCheckCourseCompletion(t *testing.T) {
+	tests := []struct {
+		name string
+		// completed lists the exercises, by title, the student has completed
+		completed []string
+		want      bool
+	}{
+		{name: "every published exercise", completed: []string{"code", "pdf"}, want: true},
+		{name: "published and hidden exercises", completed: []string{"code", "pdf", "hidden code", "hidden pdf"}, want: true},
+		{name: "one published exercise left", completed: []string{"code", "hidden code", "hidden pdf"}, want: false},
+		{name: "hidden exercises only", completed: []string{"hidden code", "hidden pdf"}, want: false},
+		{name: "other course's exercise does not count", completed: []string{"code", "other course"}, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := newTestDB(t, &models.CodeExercise{}, &models.PDFExercise{}, &models.StudentProgress{}, &models.CourseCompletion{})
+
+			exercises := map[string]string{} // title -> material ID
+			for _, ex := range []struct {
+				title, courseID string
+				public          bool
+				pdf             bool
+			}{
+				{"code", "course-1", true, false},
+				{"pdf", "course-1", true, true},
+				{"hidden code", "course-1", false, false},
+				{"hidden pdf", "course-1", false, true},
+				{"other course", "course-2", true, true},
+			} {
+				base := models.MaterialBase{CourseID: ex.courseID, Title: ex.title, CreatedBy: "teacher-1"}
+				var model interface{}
+				if ex.pdf {
+					pdf := &models.PDFExercise{MaterialBase: base, TotalPoints: new(int), FileURL: "f.pdf", FileName: "f.pdf"}
+					if err := db.Create(pdf).Error; err != nil {
+						t.Fatalf("create exercise: %v", err)
+					}
+					exercises[ex.title], model = pdf.MaterialID, pdf
+				} else {
+					code := &models.CodeExercise{MaterialBase: base, TotalPoints: new(int), ProblemStatement: "p"}
+					if err := db.Create(code).Error; err != nil {
+						t.Fatalf("create exercise: %v", err)
+					}
+					exercises[ex.title], model = code.MaterialID, code
+				}
+				// is_public defaults to true, so a hidden exercise is hidden after creating it
+				if !ex.public {
+					if err := db.Model(model).Update("is_public", false).Error; err != nil {
+						t.Fatalf("hide exercise: %v", err)
+					}
+				}
+			}
+			for _, title := range tt.completed {
+				progress := models.NewStudentProgress("student-1", exercises[title])
+				progress.Status = enums.ProgressCompleted
+				if err := db.Create(progress).Error; err != nil {
+					t.Fatalf("create progress: %v", err)
+				}
+			}
+
+			NewCourseCompletionService(db, nil).CheckCourseCompletion("student-1", "course-1")
+
+			var count int64
+			db.Model(&models.CourseCompletion{}).Where("user_id = ? AND course_id = ?", "student-1", "course-1").Count(&count)
+			if got := count == 1; got != tt.want {
+				t.Errorf("course completed = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestNotifyCompletionRetries checks that a failed course.completed POST is retried a
+// bounded number of times, and that notified_at is only stamped once it is delivered
+func TestNotifyCompletionRetries(t *testing.T) {
+	tests := []struct {
+		name         string
+		failures     int32
+		wantRequests int32
+		wantNotified bool
+	}{
+		{name: "delivered first time", failures: 0, wantRequests: 1, wantNotified: true},
+		{name: "delivered after failures", failures: 2, wantRequests: 3, wantNotified: true},
+		{name: "receiver stays down", failures: 100, wantRequests: completionWebhookAttempts, wantNotified: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests atomic.Int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if requests.Add(1) <= tt.failures {
+					w.WriteHeader(http.StatusServiceUnavailable)
+				}
+			}))
+			defer server.Close()
+
+			db := newTestDB(t, &models.User{}, &models.Course{}, &models.StudentCourseScore{}, &models.CourseCompletion{})
+			completion := models.CourseCompletion{CompletionID: "completion-1", UserID: "student-1", CourseID: "course-1", CompletedAt: time.Now()}
+			createRows(t, db,
+				&models.User{UserID: "student-1", FirstName: "Ann", LastName: "Lee", Email: "ann@example.com"},
+				&models.Course{CourseID: "course-1", Name: "Data Structures", CreatedBy: "teacher-1"},
+				&completion)
+
+			svc := NewCourseCompletionService(db, external.NewWebhookNotifier(server.URL, "", time.Second))
+			svc.webhookBackoff = time.Millisecond
+			svc.notifyCompletion(completion, 2)
+
+			if got := requests.Load(); got != tt.wantRequests {
+				t.Errorf("webhook requests = %d, want %d", got, tt.wantRequests)
+			}
+			var got models.CourseCompletion
+			if err := db.First(&got, "completion_id = ?", "completion-1").Error; err != nil {
+				t.Fatalf("load completion: %v", err)
+			}
+			if notified := got.NotifiedAt != nil; notified != tt.wantNotified {
+				t.Errorf("notified = %v, want %v", notified, tt.wantNotified)
+			}
+		})
+	}
+}
@@ -0,0 +1,98 @@
+package services
+
+import (
+	"testing"
+
+	models "github.com/Project-DSView/backend/go/internal/domain/entities"
+	"github.com/Project-DSView/backend/go/internal/domain/enums"
+	"github.com/Project-DSView/backend/go/internal/types"
+	"gorm.io/gorm"
+)
+
+// TestCopyCourseRemapsReferences checks that the copies of exercises and announcements
+// refer to the copies of their prerequisites and linked materials, not to the source
+// course, and that references to materials outside it are dropped
+func TestCopyCourseRemapsReferences(t *testing.T) {
+	db := newTestDB(t, &models.User{}, &models.Enrollment{}, &models.Course{}, &models.CourseWeek{}, &models.CourseMaterial{}, &models.CodeExercise{},
+		&models.PDFExercise{}, &models.Announcement{}, &models.TestCase{})
+	createRows(t, db, &models.Course{CourseID: "source", Name: "Data Structures", CreatedBy: "teacher-1"})
+
+	base := func(id string) models.MaterialBase {
+		return models.MaterialBase{MaterialID: id, CourseID: "source", Title: id, CreatedBy: "teacher-1"}
+	}
+	points := 10
+	linkedB, linkedGone := "code-b", "deleted-material"
+	createMaterial(t, db, enums.MaterialTypeCodeExercise, &models.CodeExercise{MaterialBase: base("code-a"), TotalPoints: &points})
+	createMaterial(t, db, enums.MaterialTypeCodeExercise, &models.CodeExercise{MaterialBase: base("code-b"), TotalPoints: &points,
+		Prerequisites: types.StringList{"code-a", "other-course-exercise"}})
+	createMaterial(t, db, enums.MaterialTypePDFExercise, &models.PDFExercise{MaterialBase: base("pdf-c"), TotalPoints: &points,
+		FileURL: "pdf-c.pdf", FileName: "pdf-c.pdf", Prerequisites: types.StringList{"code-b"}})
+	createMaterial(t, db, enums.MaterialTypeAnnouncement, &models.Announcement{MaterialBase: base("ann-b"), Content: "B is out", LinkedMaterialID: &linkedB})
+	createMaterial(t, db, enums.MaterialTypeAnnouncement, &models.Announcement{MaterialBase: base("ann-gone"), Content: "Gone", LinkedMaterialID: &linkedGone})
+
+	courseSvc := NewCourseService(db, NewUserService(db), nil)
+	courseSvc.SetCourseMaterialService(NewCourseMaterialService(db, nil))
+	copied, err := courseSvc.CopyCourse("source", "Copy", "teacher-2")
+	if err != nil {
+		t.Fatalf("CopyCourse() error = %v", err)
+	}
+
+	// Copies keep their source's title
+	cloneID := func(title string, table interface{}) string {
+		t.Helper()
+		var base models.MaterialBase
+		if err := db.Model(table).Select("material_id").Where("course_id = ? AND title = ?", copied.CourseID, title).Take(&base).Error; err != nil {
+			t.Fatalf("get copy of %s: %v", title, err)
+		}
+		return base.MaterialID
+	}
+	codeA, codeB := cloneID("code-a", &models.CodeExercise{}), cloneID("code-b", &models.CodeExercise{})
+
+	var b models.CodeExercise
+	if err := db.First(&b, "material_id = ?", codeB).Error; err != nil {
+		t.Fatalf("load copy of code-b: %v", err)
+	}
+	if len(b.Prerequisites) != 1 || b.Prerequisites[0] != codeA {
+		t.Errorf("code-b copy prerequisites = %v, want [%s]", b.Prerequisites, codeA)
+	}
+
+	var c models.PDFExercise
+	if err := db.First(&c, "material_id = ?", cloneID("pdf-c", &models.PDFExercise{})).Error; err != nil {
+		t.Fatalf("load copy of pdf-c: %v", err)
+	}
+	if len(c.Prerequisites) != 1 || c.Prerequisites[0] != codeB {
+		t.Errorf("pdf-c copy prerequisites = %v, want [%s]", c.Prerequisites, codeB)
+	}
+
+	var annB, annGone models.Announcement
+	if err := db.First(&annB, "material_id = ?", cloneID("ann-b", &models.Announcement{})).Error; err != nil {
+		t.Fatalf("load copy of ann-b: %v", err)
+	}
+	if annB.LinkedMaterialID == nil || *annB.LinkedMaterialID != codeB {
+		t.Errorf("ann-b copy linked material = %v, want %s", annB.LinkedMaterialID, codeB)
+	}
+	if err := db.First(&annGone, "material_id = ?", cloneID("ann-gone", &models.Announcement{})).Error; err != nil {
+		t.Fatalf("load copy of ann-gone: %v", err)
+	}
+	if annGone.LinkedMaterialID != nil {
+		t.Errorf("ann-gone copy linked material = %s, want none", *annGone.LinkedMaterialID)
+	}
+
+	// The source course is unchanged
+	var sourceB models.CodeExercise
+	if err := db.First(&sourceB, "material_id = ?", "code-b").Error; err != nil {
+		t.Fatalf("load code-b: %v", err)
+	}
+	if len(sourceB.Prerequisites) != 2 || sourceB.Prerequisites[0] != "code-a" {
+		t.Errorf("code-b prerequisites = %v, want unchanged", sourceB.Prerequisites)
+	}
+}
+
+// createMaterial adds a material of the source course with its course_materials row
+func createMaterial(t *testing.T, db *gorm.DB, materialType enums.MaterialType, material interface{ GetMaterialID() string }) {
+	t.Helper()
+	createRows(t, db, material)
+	id := material.GetMaterialID()
+	refType := string(materialType)
+	createRows(t, db, &models.CourseMaterial{MaterialID: id, CourseID: "source", Type: materialType, ReferenceID: &id, ReferenceType: &refType})
+}
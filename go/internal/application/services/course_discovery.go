@@ -0,0 +1,66 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Project-DSView/backend/go/internal/domain/enums"
+	"github.com/Project-DSView/backend/go/internal/types"
+)
+
+// GetDiscoverableCourses lists the active courses the user has not joined, newest first,
+// for a course catalog students browse before enrolling. search matches the name or
+// description. Every course currently needs its enroll key to join, so each is marked as
+// requiring one; the key itself is never returned.
+func (s *CourseService) GetDiscoverableCourses(userID, search string, page, limit int) ([]types.DiscoverableCourse, int, error) {
+	query := s.db.Table("courses c").
+		Where("c.status = ?", enums.CourseStatusActive).
+		Where("NOT EXISTS (SELECT 1 FROM enrollments e WHERE e.course_id = c.course_id AND e.user_id = ?)", userID)
+	if search != "" {
+		searchTerm := "%" + strings.ToLower(search) + "%"
+		query = query.Where("LOWER(c.name) LIKE ? OR LOWER(c.description) LIKE ?", searchTerm, searchTerm)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count courses: %w", err)
+	}
+
+	var rows []struct {
+		CourseID         string
+		Name             string
+		Description      string
+		ImageURL         string
+		CreatedAt        time.Time
+		TeacherFirstName string
+		TeacherLastName  string
+		StudentCount     int64
+	}
+	if err := query.
+		Select(`c.course_id, c.name, c.description, c.image_url, c.created_at,
+			u.first_name AS teacher_first_name, u.last_name AS teacher_last_name,
+			(SELECT COUNT(*) FROM enrollments e WHERE e.course_id = c.course_id AND e.role = ?) AS student_count`,
+			enums.EnrollmentRoleStudent).
+		Joins("LEFT JOIN users u ON u.user_id = c.created_by").
+		Order("c.created_at DESC").
+		Limit(limit).Offset((page - 1) * limit).
+		Scan(&rows).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to fetch courses: %w", err)
+	}
+
+	courses := make([]types.DiscoverableCourse, len(rows))
+	for i, row := range rows {
+		courses[i] = types.DiscoverableCourse{
+			CourseID:          row.CourseID,
+			Name:              row.Name,
+			Description:       row.Description,
+			ImageURL:          row.ImageURL,
+			TeacherName:       strings.TrimSpace(row.TeacherFirstName + " " + row.TeacherLastName),
+			StudentCount:      row.StudentCount,
+			CreatedAt:         row.CreatedAt,
+			RequiresEnrollKey: true,
+		}
+	}
+	return courses, int(total), nil
+}
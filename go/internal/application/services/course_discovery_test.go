@@ -0,0 +1,73 @@
+package services
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	models "github.com/Project-DSView/backend/go/internal/domain/entities"
+	"github.com/Project-DSView/backend/go/internal/domain/enums"
+)
+
+// TestGetDiscoverableCourses checks that the catalog lists active courses the student has
+// not joined, newest first, with their teacher and student count
+func TestGetDiscoverableCourses(t *testing.T) {
+	db := newTestDB(t, &models.User{}, &models.Course{}, &models.Enrollment{})
+	course := func(id, name, description string, hoursAgo int) *models.Course {
+		return &models.Course{CourseID: id, Name: name, Description: description, CreatedBy: "teacher-1",
+			EnrollKey: "key-" + id, CreatedAt: time.Now().Add(-time.Duration(hoursAgo) * time.Hour)}
+	}
+	archived := course("archived", "Old Algorithms", "", 1)
+	archived.Status = enums.CourseStatusArchived
+	createRows(t, db,
+		&models.User{UserID: "teacher-1", FirstName: "Ada", LastName: "Lovelace", Email: "ada@example.com", IsTeacher: true},
+		course("graphs", "Graph Theory", "Paths and trees", 1),
+		course("sorting", "Sorting", "Merge sort and quicksort", 2),
+		course("trees", "Search Trees", "Balanced trees", 3),
+		course("joined", "Data Structures", "", 4),
+		archived,
+		&models.Enrollment{CourseID: "joined", UserID: "student-1", Role: enums.EnrollmentRoleStudent},
+		&models.Enrollment{CourseID: "graphs", UserID: "student-2", Role: enums.EnrollmentRoleStudent},
+		&models.Enrollment{CourseID: "graphs", UserID: "student-3", Role: enums.EnrollmentRoleStudent},
+		&models.Enrollment{CourseID: "graphs", UserID: "ta-1", Role: enums.EnrollmentRoleTA},
+	)
+	svc := NewCourseService(db, nil, nil)
+
+	tests := []struct {
+		name        string
+		search      string
+		page, limit int
+		wantIDs     []string
+		wantTotal   int
+	}{
+		{name: "all joinable courses", page: 1, limit: 10, wantIDs: []string{"graphs", "sorting", "trees"}, wantTotal: 3},
+		{name: "second page", page: 2, limit: 2, wantIDs: []string{"trees"}, wantTotal: 3},
+		{name: "search by name", search: "SORT", page: 1, limit: 10, wantIDs: []string{"sorting"}, wantTotal: 1},
+		{name: "search by description", search: "trees", page: 1, limit: 10, wantIDs: []string{"graphs", "trees"}, wantTotal: 2},
+		{name: "joined and archived courses are not found", search: "data", page: 1, limit: 10, wantTotal: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			courses, total, err := svc.GetDiscoverableCourses("student-1", tt.search, tt.page, tt.limit)
+			if err != nil {
+				t.Fatalf("GetDiscoverableCourses() error = %v", err)
+			}
+			var ids []string
+			for _, c := range courses {
+				ids = append(ids, c.CourseID)
+			}
+			if !reflect.DeepEqual(ids, tt.wantIDs) || total != tt.wantTotal {
+				t.Errorf("courses %v of %d, want %v of %d", ids, total, tt.wantIDs, tt.wantTotal)
+			}
+		})
+	}
+
+	courses, _, err := svc.GetDiscoverableCourses("student-1", "graph", 1, 10)
+	if err != nil || len(courses) != 1 {
+		t.Fatalf("GetDiscoverableCourses() = %v, %v; want the graph course", courses, err)
+	}
+	if got := courses[0]; got.TeacherName != "Ada Lovelace" || got.StudentCount != 2 || !got.RequiresEnrollKey {
+		t.Errorf("graph course = %+v, want teacher Ada Lovelace, 2 students and an enroll key", got)
+	}
+}
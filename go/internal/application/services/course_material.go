@@ -2,24 +2,36 @@ package services
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"path/filepath"
+	"sort"
 	"strings"
 	"time"
 
 	models "github.com/Project-DSView/backend/go/internal/domain/entities"
 	"github.com/Project-DSView/backend/go/internal/domain/enums"
+	"github.com/Project-DSView/backend/go/internal/types"
+	"github.com/Project-DSView/backend/go/pkg/external"
 	"github.com/Project-DSView/backend/go/pkg/logger"
 	materialpkg "github.com/Project-DSView/backend/go/pkg/material"
 	"github.com/Project-DSView/backend/go/pkg/storage"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+// ErrInvalidSubmissionWindow is returned when an exercise's submission window would close
+// before it opens
+var ErrInvalidSubmissionWindow = errors.New("closes_at must be after opens_at")
+
 type CourseMaterialService struct {
 	db             *gorm.DB
 	storageService storage.StorageInterface
+	testCaseLimits TestCaseLimits
+	// Notified when a material is auto-announced; nil disables the webhook
+	publishNotifier *external.WebhookNotifier
 }
 
 func NewCourseMaterialService(db *gorm.DB, storageService storage.StorageInterface) *CourseMaterialService {
@@ -29,6 +41,16 @@ func NewCourseMaterialService(db *gorm.DB, storageService storage.StorageInterfa
 	}
 }
 
+// SetTestCaseLimits sets the maximum test case input/output sizes
+func (s *CourseMaterialService) SetTestCaseLimits(limits TestCaseLimits) {
+	s.testCaseLimits = limits
+}
+
+// ValidateTestCases checks test cases against the configured size limits without saving them
+func (s *CourseMaterialService) ValidateTestCases(testCases []models.TestCase) error {
+	return s.testCaseLimits.CheckTestCases(testCases)
+}
+
 // GetDB returns the database instance (for handler access)
 func (s *CourseMaterialService) GetDB() *gorm.DB {
 	return s.db
@@ -50,13 +72,8 @@ func (s *CourseMaterialService) CreateCourseMaterial(material *models.CourseMate
 		return err
 	}
 
-	// Validate that reference_id and reference_type are set
-	if material.ReferenceID == nil || material.ReferenceType == nil {
-		return errors.New("reference_id and reference_type are required")
-	}
-
-	// Verify that the referenced material exists
-	if err := materialpkg.VerifyReferenceExists(s.db, *material.ReferenceID, *material.ReferenceType); err != nil {
+	// Verify that the referenced material exists in the table its type implies
+	if err := materialpkg.VerifyReference(s.db, material); err != nil {
 		return err
 	}
 
@@ -93,7 +110,7 @@ func (s *CourseMaterialService) UploadCourseMaterialFile(ctx context.Context, co
 	}
 
 	// Generate unique filename
-	ext := strings.ToLower(filepath.Ext(filename))
+	ext := filepath.Ext(s.storageService.SanitizeFilename(filename))
 	if ext == "" {
 		ext = ".bin"
 	}
@@ -114,7 +131,9 @@ func (s *CourseMaterialService) UploadCourseMaterialFile(ctx context.Context, co
 }
 
 // GetCourseMaterialsByCourse retrieves materials for a specific course with full details
-func (s *CourseMaterialService) GetCourseMaterialsByCourse(courseID string, week *int, materialType *string, limit, offset int) ([]map[string]interface{}, int64, error) {
+// When tags are given, only materials carrying at least one of them are returned; they
+// must already be normalized (material.NormalizeTags).
+func (s *CourseMaterialService) GetCourseMaterialsByCourse(courseID string, week *int, materialType *string, tags []string, limit, offset int) ([]map[string]interface{}, int64, error) {
 	var materials []models.CourseMaterial
 	var total int64
 
@@ -130,6 +149,15 @@ func (s *CourseMaterialService) GetCourseMaterialsByCourse(courseID string, week
 		query = query.Where("type = ?", *materialType)
 	}
 
+	// Filter by tags if specified
+	if len(tags) > 0 {
+		tagged := joinMaterialDetails(s.db.Table("course_materials AS cm")).
+			Select("cm.material_id").
+			Where("cm.course_id = ?", courseID).
+			Where("EXISTS (SELECT 1 FROM jsonb_array_elements_text("+materialTagsColumn+") AS t(tag) WHERE t.tag IN ?)", tags)
+		query = query.Where("material_id IN (?)", tagged)
+	}
+
 	// Count total
 	if err := query.Count(&total).Error; err != nil {
 		return nil, 0, err
@@ -180,6 +208,172 @@ func (s *CourseMaterialService) GetCourseMaterialByID(materialID string) (map[st
 	return details, nil
 }
 
+// GetMaterialForEdit returns everything a teacher edits on a material: every field and
+// setting and, for code exercises, all test cases including hidden ones. It is separate
+// from GetCourseMaterialByID, which is what students see. Only the creator may read it.
+func (s *CourseMaterialService) GetMaterialForEdit(materialID, userID string) (map[string]interface{}, error) {
+	var material models.CourseMaterial
+	if err := s.db.First(&material, "material_id = ?", materialID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("course material not found")
+		}
+		return nil, err
+	}
+
+	specific, err := loadSpecificMaterial(s.db, &material)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get material details: %w", err)
+	}
+	if specific == nil {
+		return nil, errors.New("course material not found")
+	}
+	if specific.GetCreatedBy() != userID {
+		return nil, ErrNotMaterialCreator
+	}
+
+	exercise, isCodeExercise := specific.(*models.CodeExercise)
+	if isCodeExercise {
+		if err := s.db.Where("material_id = ?", exercise.MaterialID).
+			Order("created_at ASC").
+			Find(&exercise.TestCases).Error; err != nil {
+			return nil, fmt.Errorf("failed to get test cases: %w", err)
+		}
+	}
+
+	payload := specific.ToJSON()
+	if isCodeExercise {
+		testCases := make([]map[string]interface{}, len(exercise.TestCases))
+		summary := materialpkg.TestCaseSummary{Scored: int64(len(exercise.TestCases))}
+		for i := range exercise.TestCases {
+			testCases[i] = exercise.TestCases[i].ToJSON()
+			if exercise.TestCases[i].IsPublic {
+				summary.Examples++
+			}
+		}
+		summary.Hidden = summary.Scored - summary.Examples
+		payload["test_cases"] = testCases
+		payload["test_case_summary"] = summary
+	}
+	return payload, nil
+}
+
+// copySubmissionWindowUpdates copies opens_at and closes_at, set to a time or nil to clear
+// them, into an exercise's updates
+func copySubmissionWindowUpdates(updates, specificUpdates map[string]interface{}) {
+	for _, key := range []string{"opens_at", "closes_at"} {
+		if value, ok := updates[key]; ok {
+			specificUpdates[key] = value
+		}
+	}
+}
+
+// checkSubmissionWindowUpdate rejects exercise updates that would leave the submission
+// window closing before it opens, taking the end not being changed from the saved exercise
+func checkSubmissionWindowUpdate(db *gorm.DB, material *models.CourseMaterial, updates map[string]interface{}) error {
+	newOpensAt, changesOpensAt := updates["opens_at"]
+	newClosesAt, changesClosesAt := updates["closes_at"]
+	if !changesOpensAt && !changesClosesAt {
+		return nil
+	}
+
+	specific, err := loadSpecificMaterial(db, material)
+	if err != nil {
+		return fmt.Errorf("failed to get material details: %w", err)
+	}
+	var opensAt, closesAt *time.Time
+	switch m := specific.(type) {
+	case *models.CodeExercise:
+		opensAt, closesAt = m.OpensAt, m.ClosesAt
+	case *models.PDFExercise:
+		opensAt, closesAt = m.OpensAt, m.ClosesAt
+	}
+
+	windowTime := func(value interface{}) *time.Time {
+		if t, ok := value.(time.Time); ok {
+			return &t
+		}
+		return nil
+	}
+	if changesOpensAt {
+		opensAt = windowTime(newOpensAt)
+	}
+	if changesClosesAt {
+		closesAt = windowTime(newClosesAt)
+	}
+	if !models.ValidSubmissionWindow(opensAt, closesAt) {
+		return ErrInvalidSubmissionWindow
+	}
+	return nil
+}
+
+// GetMaterialsByIDs retrieves several course materials with full details, keyed by
+// material ID. IDs that do not exist are absent from the result; access checks are left
+// to the caller since the materials may belong to different courses.
+func (s *CourseMaterialService) GetMaterialsByIDs(ids []string) (map[string]map[string]interface{}, error) {
+	var materials []models.CourseMaterial
+	if err := s.db.Where("material_id IN ?", ids).Find(&materials).Error; err != nil {
+		return nil, fmt.Errorf("get course materials: %w", err)
+	}
+
+	return materialpkg.GetMaterialsWithDetails(s.db, materials)
+}
+
+// RenderCourseMaterialPreview renders the markdown text fields of a material to sanitized HTML
+func (s *CourseMaterialService) RenderCourseMaterialPreview(materialID string) (map[string]interface{}, error) {
+	var material models.CourseMaterial
+	if err := s.db.First(&material, "material_id = ?", materialID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("course material not found")
+		}
+		return nil, err
+	}
+
+	rendered := map[string]interface{}{
+		"material_id": material.MaterialID,
+		"course_id":   material.CourseID,
+		"type":        material.Type,
+	}
+
+	switch material.Type {
+	case enums.MaterialTypeCodeExercise:
+		var exercise models.CodeExercise
+		if err := s.db.First(&exercise, "material_id = ?", materialID).Error; err != nil {
+			return nil, fmt.Errorf("failed to get code exercise: %w", err)
+		}
+		rendered["description_html"] = materialpkg.RenderStatement(exercise.Description)
+		rendered["problem_statement_html"] = materialpkg.RenderStatement(exercise.ProblemStatement)
+		rendered["constraints_html"] = materialpkg.RenderStatement(exercise.Constraints)
+		rendered["hints_html"] = materialpkg.RenderStatement(exercise.Hints)
+	case enums.MaterialTypeAnnouncement:
+		var announcement models.Announcement
+		if err := s.db.First(&announcement, "material_id = ?", materialID).Error; err != nil {
+			return nil, fmt.Errorf("failed to get announcement: %w", err)
+		}
+		rendered["description_html"] = materialpkg.RenderStatement(announcement.Description)
+		rendered["content_html"] = materialpkg.RenderStatement(announcement.Content)
+	case enums.MaterialTypePDFExercise:
+		var exercise models.PDFExercise
+		if err := s.db.First(&exercise, "material_id = ?", materialID).Error; err != nil {
+			return nil, fmt.Errorf("failed to get PDF exercise: %w", err)
+		}
+		rendered["description_html"] = materialpkg.RenderStatement(exercise.Description)
+	case enums.MaterialTypeDocument:
+		var document models.Document
+		if err := s.db.First(&document, "material_id = ?", materialID).Error; err != nil {
+			return nil, fmt.Errorf("failed to get document: %w", err)
+		}
+		rendered["description_html"] = materialpkg.RenderStatement(document.Description)
+	case enums.MaterialTypeVideo:
+		var video models.Video
+		if err := s.db.First(&video, "material_id = ?", materialID).Error; err != nil {
+			return nil, fmt.Errorf("failed to get video: %w", err)
+		}
+		rendered["description_html"] = materialpkg.RenderStatement(video.Description)
+	}
+
+	return rendered, nil
+}
+
 // UpdateCourseMaterial updates an existing course material
 func (s *CourseMaterialService) UpdateCourseMaterial(materialID string, userID string, updates map[string]interface{}) error {
 	// Check if material exists
@@ -218,6 +412,7 @@ func (s *CourseMaterialService) UpdateCourseMaterial(materialID string, userID s
 		"type":        true,
 		"week":        true,
 		"is_public":   false, // Not in CourseMaterial, stored in specific tables
+		"tags":        false, // Not in CourseMaterial, stored in specific tables
 		"video_url":   false, // Not in CourseMaterial, stored in Video table
 		"file_url":    false, // Not in CourseMaterial, stored in Document/PDFExercise tables
 	}
@@ -240,6 +435,16 @@ func (s *CourseMaterialService) UpdateCourseMaterial(materialID string, userID s
 		}
 	}
 
+	// A hidden material being made public counts as publishing it
+	var unpublished models.Material
+	if isPublic, ok := updates["is_public"].(bool); ok && isPublic {
+		if current, err := s.loadPublishableMaterial(&material); err != nil {
+			logger.Warnf("Failed to load material %s before update: %v", materialID, err)
+		} else if current != nil && !current.GetIsPublic() {
+			unpublished = current
+		}
+	}
+
 	// Update specific material table based on type
 	if material.ReferenceID != nil && material.ReferenceType != nil {
 		specificUpdates := make(map[string]interface{})
@@ -254,6 +459,9 @@ func (s *CourseMaterialService) UpdateCourseMaterial(materialID string, userID s
 		if isPublic, ok := updates["is_public"].(bool); ok {
 			specificUpdates["is_public"] = isPublic
 		}
+		if tags, ok := updates["tags"].(types.StringList); ok {
+			specificUpdates["tags"] = tags
+		}
 		if week, ok := updates["week"].(int); ok {
 			specificUpdates["week"] = week
 		}
@@ -287,6 +495,31 @@ func (s *CourseMaterialService) UpdateCourseMaterial(materialID string, userID s
 			if hints, ok := updates["hints"].(string); ok {
 				specificUpdates["hints"] = hints
 			}
+			if outputMode, ok := updates["output_mode"].(string); ok {
+				specificUpdates["output_mode"] = outputMode
+			}
+			if trim, ok := updates["trim_whitespace"].(bool); ok {
+				specificUpdates["trim_whitespace"] = trim
+			}
+			if tolerance, ok := updates["number_tolerance"].(float64); ok {
+				specificUpdates["number_tolerance"] = tolerance
+			}
+			if threshold, ok := updates["pass_threshold"].(int); ok {
+				specificUpdates["pass_threshold"] = threshold
+			}
+			if maxConcurrent, ok := updates["max_concurrent_submissions"].(int); ok {
+				specificUpdates["max_concurrent_submissions"] = maxConcurrent
+			}
+			if lock, ok := updates["lock_after_approval"].(bool); ok {
+				specificUpdates["lock_after_approval"] = lock
+			}
+			if maxAttempts, ok := updates["max_attempts"].(int); ok {
+				specificUpdates["max_attempts"] = maxAttempts
+			}
+			if prerequisites, ok := updates["prerequisites"].(types.StringList); ok {
+				specificUpdates["prerequisites"] = prerequisites
+			}
+			copySubmissionWindowUpdates(updates, specificUpdates)
 			if exampleInputs, ok := updates["example_inputs"]; ok {
 				specificUpdates["example_inputs"] = exampleInputs
 			}
@@ -300,6 +533,23 @@ func (s *CourseMaterialService) UpdateCourseMaterial(materialID string, userID s
 			if deadline, ok := updates["deadline"].(string); ok {
 				specificUpdates["deadline"] = deadline
 			}
+			if maxPages, ok := updates["max_pages"].(int); ok {
+				specificUpdates["max_pages"] = maxPages
+			}
+			if lock, ok := updates["lock_after_approval"].(bool); ok {
+				specificUpdates["lock_after_approval"] = lock
+			}
+			if maxAttempts, ok := updates["max_attempts"].(int); ok {
+				specificUpdates["max_attempts"] = maxAttempts
+			}
+			if prerequisites, ok := updates["prerequisites"].(types.StringList); ok {
+				specificUpdates["prerequisites"] = prerequisites
+			}
+			copySubmissionWindowUpdates(updates, specificUpdates)
+		}
+
+		if err := checkSubmissionWindowUpdate(s.db, &material, specificUpdates); err != nil {
+			return err
 		}
 
 		// Update the specific material table if there are fields to update
@@ -329,11 +579,22 @@ func (s *CourseMaterialService) UpdateCourseMaterial(materialID string, userID s
 		}
 	}
 
+	if unpublished != nil {
+		if published, err := s.loadPublishableMaterial(&material); err != nil {
+			logger.Warnf("Failed to reload published material %s: %v", materialID, err)
+		} else if published != nil {
+			s.announcePublished(published)
+		}
+	}
+
 	return nil
 }
 
-// DeleteCourseMaterial deletes a course material
-func (s *CourseMaterialService) DeleteCourseMaterial(materialID string, userID string) error {
+// DeleteCourseMaterial deletes a course material. A material students have submitted to
+// or made progress on is only deleted with force, which deletes their submissions,
+// submission files and progress along with it; otherwise ErrMaterialHasStudentWork is
+// returned with the counts.
+func (s *CourseMaterialService) DeleteCourseMaterial(materialID string, userID string, force bool) error {
 	// Check if material exists
 	var material models.CourseMaterial
 	if err := s.db.First(&material, "material_id = ?", materialID).Error; err != nil {
@@ -356,18 +617,44 @@ func (s *CourseMaterialService) DeleteCourseMaterial(materialID string, userID s
 		return errors.New("only the creator can delete this course material")
 	}
 
-	// Delete file from storage if exists
-	if fileURL != "" {
-		ctx := context.Background()
-		if err := s.storageService.DeleteFile(ctx, fileURL); err != nil {
-			// Log error but don't fail the deletion
-			logger.Warnf("Failed to delete file from storage: %v", err)
+	var submissionFiles []string
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		submissions, progress, err := countMaterialStudentWork(tx, materialID)
+		if err != nil {
+			return err
+		}
+		if submissions > 0 || progress > 0 {
+			if !force {
+				return fmt.Errorf("%w (%d submissions, %d progress records); delete with force to remove them too",
+					ErrMaterialHasStudentWork, submissions, progress)
+			}
+			if submissionFiles, err = deleteMaterialStudentWork(tx, &material); err != nil {
+				return err
+			}
+			logger.Infof("User %s force-deleted material %s with %d submissions and %d progress records",
+				userID, materialID, submissions, progress)
+		}
+
+		// Delete material from database
+		if err := tx.Delete(&material).Error; err != nil {
+			return fmt.Errorf("failed to delete course material: %w", err)
 		}
+		return nil
+	})
+	if err != nil {
+		return err
 	}
 
-	// Delete material from database
-	if err := s.db.Delete(&material).Error; err != nil {
-		return fmt.Errorf("failed to delete course material: %w", err)
+	// Files go once the rows are gone, so a failed deletion never leaves rows without files
+	ctx := context.Background()
+	for _, url := range append(submissionFiles, fileURL) {
+		if url == "" {
+			continue
+		}
+		if err := s.storageService.DeleteFile(ctx, url); err != nil {
+			// Log error but don't fail the deletion
+			logger.Warnf("Failed to delete file from storage: %v", err)
+		}
 	}
 
 	return nil
@@ -456,6 +743,121 @@ func (s *CourseMaterialService) GetCourseMaterialStats(courseID string) (map[str
 	}, nil
 }
 
+// GetMaterialDefaults suggests the week and deadline for a new material from the course
+// settings. With week_increment set, the week is that many weeks after the course's latest
+// (capped at 52); otherwise week 1. With deadline_offset_days set, the deadline is that
+// many days from now; otherwise none.
+func (s *CourseMaterialService) GetMaterialDefaults(courseID string) (*types.MaterialDefaults, error) {
+	var course models.Course
+	if err := s.db.Select("course_id", "settings").Where("course_id = ?", courseID).First(&course).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("course not found")
+		}
+		return nil, fmt.Errorf("failed to get course settings: %w", err)
+	}
+
+	defaults := &types.MaterialDefaults{Week: 1}
+
+	if increment := course.Settings.GetWeekIncrement(); increment > 0 {
+		var latestWeek *int
+		if err := s.db.Model(&models.CourseMaterial{}).
+			Where("course_id = ?", courseID).
+			Select("MAX(week)").
+			Scan(&latestWeek).Error; err != nil {
+			return nil, fmt.Errorf("failed to get latest week: %w", err)
+		}
+		if latestWeek != nil {
+			defaults.Week = *latestWeek + increment
+			if defaults.Week > 52 {
+				defaults.Week = 52
+			}
+		}
+	}
+
+	if offset := course.Settings.GetDeadlineOffset(); offset > 0 {
+		deadline := time.Now().Add(offset).Truncate(time.Minute).Format(time.RFC3339)
+		defaults.Deadline = &deadline
+	}
+
+	return defaults, nil
+}
+
+// materialTagsColumn is the tags of a course material, read from its specific table
+// (see joinMaterialDetails)
+const materialTagsColumn = "COALESCE(d.tags, v.tags, ce.tags, pe.tags, a.tags, '[]'::jsonb)"
+
+// joinMaterialDetails joins the specific material tables onto a query over
+// "course_materials AS cm", aliased d, v, ce, pe and a
+func joinMaterialDetails(query *gorm.DB) *gorm.DB {
+	return query.
+		Joins("LEFT JOIN documents AS d ON cm.reference_type = 'document' AND d.material_id = cm.reference_id").
+		Joins("LEFT JOIN videos AS v ON cm.reference_type = 'video' AND v.material_id = cm.reference_id").
+		Joins("LEFT JOIN code_exercises AS ce ON cm.reference_type = 'code_exercise' AND ce.material_id = cm.reference_id").
+		Joins("LEFT JOIN pdf_exercises AS pe ON cm.reference_type = 'pdf_exercise' AND pe.material_id = cm.reference_id").
+		Joins("LEFT JOIN announcements AS a ON cm.reference_type = 'announcement' AND a.material_id = cm.reference_id")
+}
+
+// onlyVisibleMaterials limits a query built with joinMaterialDetails to what students
+// see: public materials and announcements that have not expired
+func onlyVisibleMaterials(query *gorm.DB) *gorm.DB {
+	return query.
+		Where("COALESCE(d.is_public, v.is_public, ce.is_public, pe.is_public, a.is_public, false)").
+		Where("a.expires_at IS NULL OR a.expires_at > ?", time.Now())
+}
+
+// GetCourseTags lists the distinct tags of a course's materials with how many materials
+// carry each, most used first. Unless includeHidden is set, only materials visible to
+// students count.
+func (s *CourseMaterialService) GetCourseTags(courseID string, includeHidden bool) ([]types.TagCount, error) {
+	query := joinMaterialDetails(s.db.Table("course_materials AS cm")).
+		Joins("CROSS JOIN LATERAL jsonb_array_elements_text("+materialTagsColumn+") AS t(tag)").
+		Select("t.tag, COUNT(*) AS count").
+		Where("cm.course_id = ?", courseID)
+	if !includeHidden {
+		query = onlyVisibleMaterials(query)
+	}
+
+	tags := []types.TagCount{}
+	if err := query.Group("t.tag").Order("count DESC, t.tag ASC").Scan(&tags).Error; err != nil {
+		return nil, fmt.Errorf("failed to get course tags: %w", err)
+	}
+	return tags, nil
+}
+
+// GetWeeksSummary lists the weeks of a course that have materials, with how many
+// materials and which types each week holds, ordered by week. Unless includeHidden
+// is set, only public materials (and announcements that have not expired) count.
+func (s *CourseMaterialService) GetWeeksSummary(courseID string, includeHidden bool) ([]types.WeekSummary, error) {
+	query := s.db.Table("course_materials AS cm").
+		Select("cm.week, COUNT(*) AS count, STRING_AGG(DISTINCT cm.type::text, ',') AS types").
+		Where("cm.course_id = ?", courseID)
+
+	if !includeHidden {
+		query = onlyVisibleMaterials(joinMaterialDetails(query))
+	}
+
+	var rows []struct {
+		Week  int
+		Count int
+		Types string
+	}
+	if err := query.Group("cm.week").Order("cm.week ASC").Scan(&rows).Error; err != nil {
+		return nil, fmt.Errorf("failed to get weeks summary: %w", err)
+	}
+
+	weeks := make([]types.WeekSummary, len(rows))
+	for i, row := range rows {
+		materialTypes := strings.Split(row.Types, ",")
+		sort.Strings(materialTypes)
+		weeks[i] = types.WeekSummary{
+			Week:  row.Week,
+			Count: row.Count,
+			Types: materialTypes,
+		}
+	}
+	return weeks, nil
+}
+
 // SearchCourseMaterials searches materials by title or description
 func (s *CourseMaterialService) SearchCourseMaterials(courseID string, query string, limit, offset int) ([]models.CourseMaterial, int64, error) {
 	var materials []models.CourseMaterial
@@ -490,9 +892,12 @@ func (s *CourseMaterialService) CreateCodeExercise(codeExercise *models.CodeExer
 	if codeExercise.TotalPoints == nil || *codeExercise.TotalPoints <= 0 {
 		return errors.New("code exercise must have total points")
 	}
+	if err := s.testCaseLimits.CheckTestCases(testCases); err != nil {
+		return err
+	}
 
 	// Start transaction
-	return s.db.Transaction(func(tx *gorm.DB) error {
+	err := s.db.Transaction(func(tx *gorm.DB) error {
 		// Create code exercise first
 		if err := tx.Create(codeExercise).Error; err != nil {
 			return fmt.Errorf("failed to create code exercise: %w", err)
@@ -523,6 +928,12 @@ func (s *CourseMaterialService) CreateCodeExercise(codeExercise *models.CodeExer
 
 		return nil
 	})
+	if err != nil {
+		return err
+	}
+
+	s.announcePublished(codeExercise)
+	return nil
 }
 
 // UpdateCodeExercise updates a code exercise
@@ -558,7 +969,7 @@ func (s *CourseMaterialService) UpdateCodeExercise(materialID string, userID str
 // CreateDocument creates a document material with CourseMaterial reference
 func (s *CourseMaterialService) CreateDocument(document *models.Document) error {
 	// Start transaction
-	return s.db.Transaction(func(tx *gorm.DB) error {
+	err := s.db.Transaction(func(tx *gorm.DB) error {
 		// Create document first
 		if err := tx.Create(document).Error; err != nil {
 			return fmt.Errorf("failed to create document: %w", err)
@@ -580,12 +991,18 @@ func (s *CourseMaterialService) CreateDocument(document *models.Document) error
 
 		return nil
 	})
+	if err != nil {
+		return err
+	}
+
+	s.announcePublished(document)
+	return nil
 }
 
 // CreateVideo creates a video material with CourseMaterial reference
 func (s *CourseMaterialService) CreateVideo(video *models.Video) error {
 	// Start transaction
-	return s.db.Transaction(func(tx *gorm.DB) error {
+	err := s.db.Transaction(func(tx *gorm.DB) error {
 		// Create video first
 		if err := tx.Create(video).Error; err != nil {
 			return fmt.Errorf("failed to create video: %w", err)
@@ -607,6 +1024,12 @@ func (s *CourseMaterialService) CreateVideo(video *models.Video) error {
 
 		return nil
 	})
+	if err != nil {
+		return err
+	}
+
+	s.announcePublished(video)
+	return nil
 }
 
 // CreatePDFExercise creates a PDF exercise material with CourseMaterial reference
@@ -617,7 +1040,7 @@ func (s *CourseMaterialService) CreatePDFExercise(pdfExercise *models.PDFExercis
 	}
 
 	// Start transaction
-	return s.db.Transaction(func(tx *gorm.DB) error {
+	err := s.db.Transaction(func(tx *gorm.DB) error {
 		// Create PDF exercise first
 		if err := tx.Create(pdfExercise).Error; err != nil {
 			return fmt.Errorf("failed to create PDF exercise: %w", err)
@@ -639,6 +1062,12 @@ func (s *CourseMaterialService) CreatePDFExercise(pdfExercise *models.PDFExercis
 
 		return nil
 	})
+	if err != nil {
+		return err
+	}
+
+	s.announcePublished(pdfExercise)
+	return nil
 }
 
 // CreateAnnouncement creates an announcement material with CourseMaterial reference
@@ -707,6 +1136,15 @@ func (s *CourseMaterialService) AddTestCase(materialID string, testCase *models.
 		return errors.New("can only add test cases to code exercises")
 	}
 
+	if err := s.testCaseLimits.Check(testCase.InputData, testCase.ExpectedOutput); err != nil {
+		return err
+	}
+	if testCase.HasStoredInput() {
+		if err := s.checkStoredInput(material.CourseID, *testCase.InputKey); err != nil {
+			return err
+		}
+	}
+
 	// Set material ID
 	testCase.MaterialID = &materialID
 
@@ -718,6 +1156,23 @@ func (s *CourseMaterialService) AddTestCase(materialID string, testCase *models.
 	return nil
 }
 
+// checkStoredInput verifies that a test case input key names an existing object among
+// the course's files
+func (s *CourseMaterialService) checkStoredInput(courseID, key string) error {
+	if !storage.IsCourseKey(courseID, key) {
+		return fmt.Errorf("%w: %s is not one of this course's files", ErrInvalidInputKey, key)
+	}
+	if s.storageService == nil {
+		return fmt.Errorf("%w: storage is not configured", ErrInvalidInputKey)
+	}
+	object, _, err := s.storageService.OpenObject(context.Background(), key)
+	if err != nil {
+		return fmt.Errorf("%w: %s cannot be opened: %v", ErrInvalidInputKey, key, err)
+	}
+	object.Close()
+	return nil
+}
+
 // UpdateTestCase updates an existing test case
 func (s *CourseMaterialService) UpdateTestCase(testCaseID string, userID string, updates map[string]interface{}) error {
 	// Check if test case exists
@@ -751,6 +1206,18 @@ func (s *CourseMaterialService) UpdateTestCase(testCaseID string, userID string,
 		return errors.New("only the creator can update test cases")
 	}
 
+	if err := s.testCaseLimits.CheckUpdates(updates); err != nil {
+		return err
+	}
+	// An empty input_key goes back to the inline input_data
+	if key, ok := updates["input_key"].(string); ok {
+		if key == "" {
+			updates["input_key"] = nil
+		} else if err := s.checkStoredInput(material.CourseID, key); err != nil {
+			return err
+		}
+	}
+
 	// Update test case
 	if err := s.db.Model(&testCase).Updates(updates).Error; err != nil {
 		return fmt.Errorf("failed to update test case: %w", err)
@@ -800,6 +1267,136 @@ func (s *CourseMaterialService) DeleteTestCase(testCaseID string, userID string)
 	return nil
 }
 
+// DeleteAllTestCases deletes every test case of a code exercise in a single query
+// and clears its example inputs/outputs. Returns the number of test cases removed.
+func (s *CourseMaterialService) DeleteAllTestCases(materialID string, userID string) (int64, error) {
+	var material models.CourseMaterial
+	if err := s.db.First(&material, "material_id = ?", materialID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return 0, errors.New("course material not found")
+		}
+		return 0, err
+	}
+
+	// Only code exercises have test cases
+	if !material.IsCodeExercise() {
+		return 0, errors.New("test cases can only be deleted for code exercises")
+	}
+
+	// Get creator from actual material table
+	var createdBy string
+	if material.ReferenceID != nil && material.ReferenceType != nil {
+		createdBy, _ = materialpkg.GetMaterialCreator(s.db, *material.ReferenceID, *material.ReferenceType)
+	}
+
+	// Check if user is the creator of the material
+	if createdBy != "" && createdBy != userID {
+		return 0, errors.New("only the creator can delete test cases")
+	}
+
+	var deleted int64
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("material_id = ?", materialID).Delete(&models.TestCase{})
+		if result.Error != nil {
+			return fmt.Errorf("failed to delete test cases: %w", result.Error)
+		}
+		deleted = result.RowsAffected
+
+		if err := tx.Model(&models.CodeExercise{}).
+			Where("material_id = ?", materialID).
+			Updates(map[string]interface{}{
+				"example_inputs":  types.JSONData("[]"),
+				"example_outputs": types.JSONData("[]"),
+			}).Error; err != nil {
+			return fmt.Errorf("failed to reset examples: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return deleted, nil
+}
+
+// maxTestCaseDisplayNameLength matches the display_name column
+const maxTestCaseDisplayNameLength = 255
+
+// ErrInvalidDisplayNames is wrapped by UpdateTestCaseDisplayNames when a test case ID or name is rejected
+var ErrInvalidDisplayNames = errors.New("invalid test case display names")
+
+// UpdateTestCaseDisplayNames renames several test cases of a code exercise at once, keyed
+// by test case ID. Every ID must belong to the material; an empty name clears it. The
+// names are written in one transaction, so either all of them change or none do.
+func (s *CourseMaterialService) UpdateTestCaseDisplayNames(materialID string, names map[string]string, userID string) error {
+	if len(names) == 0 {
+		return fmt.Errorf("%w: no display names given", ErrInvalidDisplayNames)
+	}
+
+	var material models.CourseMaterial
+	if err := s.db.First(&material, "material_id = ?", materialID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New("course material not found")
+		}
+		return err
+	}
+
+	// Only code exercises have test cases
+	if !material.IsCodeExercise() {
+		return errors.New("test cases can only be updated for code exercises")
+	}
+
+	// Get creator from actual material table
+	var createdBy string
+	if material.ReferenceID != nil && material.ReferenceType != nil {
+		createdBy, _ = materialpkg.GetMaterialCreator(s.db, *material.ReferenceID, *material.ReferenceType)
+	}
+
+	// Check if user is the creator of the material
+	if createdBy != "" && createdBy != userID {
+		return errors.New("only the creator can update test cases")
+	}
+
+	testCaseIDs := make([]string, 0, len(names))
+	for testCaseID, name := range names {
+		name = strings.TrimSpace(name)
+		if len(name) > maxTestCaseDisplayNameLength {
+			return fmt.Errorf("%w: display name of test case %s is longer than %d characters", ErrInvalidDisplayNames, testCaseID, maxTestCaseDisplayNameLength)
+		}
+		names[testCaseID] = name
+		testCaseIDs = append(testCaseIDs, testCaseID)
+	}
+
+	var found []string
+	if err := s.db.Model(&models.TestCase{}).
+		Where("material_id = ? AND test_case_id IN ?", materialID, testCaseIDs).
+		Pluck("test_case_id", &found).Error; err != nil {
+		return fmt.Errorf("failed to get test cases: %w", err)
+	}
+	if len(found) != len(testCaseIDs) {
+		belongs := make(map[string]bool, len(found))
+		for _, id := range found {
+			belongs[id] = true
+		}
+		for _, id := range testCaseIDs {
+			if !belongs[id] {
+				return fmt.Errorf("%w: test case %s does not belong to this material", ErrInvalidDisplayNames, id)
+			}
+		}
+	}
+
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		for testCaseID, name := range names {
+			if err := tx.Model(&models.TestCase{}).
+				Where("test_case_id = ?", testCaseID).
+				Update("display_name", name).Error; err != nil {
+				return fmt.Errorf("failed to update test case %s: %w", testCaseID, err)
+			}
+		}
+		return nil
+	})
+}
+
 // GetCourseMaterialWithTestCases retrieves a material with its test cases (only for code exercises)
 func (s *CourseMaterialService) GetCourseMaterialWithTestCases(materialID string) (*models.CourseMaterial, []models.TestCase, error) {
 	var material models.CourseMaterial
@@ -823,3 +1420,235 @@ func (s *CourseMaterialService) GetCourseMaterialWithTestCases(materialID string
 
 	return &material, testCases, nil
 }
+
+// CloneMaterial copies a material into targetCourseID as a new material owned by userID
+// and returns the clone's ID. The type-specific record, its course_materials reference,
+// test cases and stored files are all duplicated. References to other materials
+// (prerequisites, an announcement's linked material) are copied as they are; call
+// RemapClonedReferences once every material they may refer to is cloned. Returned URLs
+// are the files copied so far, so callers can clean them up if the surrounding
+// transaction fails.
+func (s *CourseMaterialService) CloneMaterial(tx *gorm.DB, material *models.CourseMaterial, targetCourseID, userID string) (string, []string, error) {
+	ctx := context.Background()
+	var copiedFiles []string
+
+	copyFile := func(url string) (string, error) {
+		if url == "" || s.storageService == nil {
+			return url, nil
+		}
+		newURL, err := s.storageService.CopyFileToCourse(ctx, url, targetCourseID)
+		if err != nil {
+			return "", err
+		}
+		copiedFiles = append(copiedFiles, newURL)
+		return newURL, nil
+	}
+	resetBase := func(base *models.MaterialBase) {
+		base.MaterialID = ""
+		base.CourseID = targetCourseID
+		base.CreatedBy = userID
+		base.CreatedAt = time.Time{}
+		base.UpdatedAt = time.Time{}
+	}
+
+	var newID string
+	switch material.Type {
+	case enums.MaterialTypeCodeExercise:
+		var exercise models.CodeExercise
+		if err := tx.First(&exercise, "material_id = ?", material.MaterialID).Error; err != nil {
+			return "", copiedFiles, fmt.Errorf("failed to get code exercise: %w", err)
+		}
+		var testCases []models.TestCase
+		if err := tx.Where("material_id = ?", material.MaterialID).Order("created_at ASC").Find(&testCases).Error; err != nil {
+			return "", copiedFiles, fmt.Errorf("failed to get test cases: %w", err)
+		}
+		images, err := s.cloneProblemImages(exercise.ProblemImages, copyFile)
+		if err != nil {
+			return "", copiedFiles, fmt.Errorf("failed to copy problem images: %w", err)
+		}
+		resetBase(&exercise.MaterialBase)
+		exercise.ProblemImages = images
+		exercise.TestCases = nil
+		if err := tx.Omit(clause.Associations).Create(&exercise).Error; err != nil {
+			return "", copiedFiles, fmt.Errorf("failed to create code exercise: %w", err)
+		}
+		newID = exercise.MaterialID
+
+		for i := range testCases {
+			testCases[i].TestCaseID = ""
+			testCases[i].MaterialID = &newID
+			testCases[i].CourseMaterial = nil
+			testCases[i].CreatedAt = time.Time{}
+			testCases[i].UpdatedAt = time.Time{}
+			if err := tx.Omit(clause.Associations).Create(&testCases[i]).Error; err != nil {
+				return "", copiedFiles, fmt.Errorf("failed to create test case %d: %w", i+1, err)
+			}
+		}
+	case enums.MaterialTypePDFExercise:
+		var exercise models.PDFExercise
+		if err := tx.First(&exercise, "material_id = ?", material.MaterialID).Error; err != nil {
+			return "", copiedFiles, fmt.Errorf("failed to get PDF exercise: %w", err)
+		}
+		fileURL, err := copyFile(exercise.FileURL)
+		if err != nil {
+			return "", copiedFiles, fmt.Errorf("failed to copy PDF exercise file: %w", err)
+		}
+		resetBase(&exercise.MaterialBase)
+		exercise.FileURL = fileURL
+		if err := tx.Omit(clause.Associations).Create(&exercise).Error; err != nil {
+			return "", copiedFiles, fmt.Errorf("failed to create PDF exercise: %w", err)
+		}
+		newID = exercise.MaterialID
+	case enums.MaterialTypeDocument:
+		var document models.Document
+		if err := tx.First(&document, "material_id = ?", material.MaterialID).Error; err != nil {
+			return "", copiedFiles, fmt.Errorf("failed to get document: %w", err)
+		}
+		fileURL, err := copyFile(document.FileURL)
+		if err != nil {
+			return "", copiedFiles, fmt.Errorf("failed to copy document file: %w", err)
+		}
+		resetBase(&document.MaterialBase)
+		document.FileURL = fileURL
+		if err := tx.Omit(clause.Associations).Create(&document).Error; err != nil {
+			return "", copiedFiles, fmt.Errorf("failed to create document: %w", err)
+		}
+		newID = document.MaterialID
+	case enums.MaterialTypeVideo:
+		var video models.Video
+		if err := tx.First(&video, "material_id = ?", material.MaterialID).Error; err != nil {
+			return "", copiedFiles, fmt.Errorf("failed to get video: %w", err)
+		}
+		resetBase(&video.MaterialBase)
+		if err := tx.Omit(clause.Associations).Create(&video).Error; err != nil {
+			return "", copiedFiles, fmt.Errorf("failed to create video: %w", err)
+		}
+		newID = video.MaterialID
+	case enums.MaterialTypeAnnouncement:
+		var announcement models.Announcement
+		if err := tx.First(&announcement, "material_id = ?", material.MaterialID).Error; err != nil {
+			return "", copiedFiles, fmt.Errorf("failed to get announcement: %w", err)
+		}
+		resetBase(&announcement.MaterialBase)
+		if err := tx.Omit(clause.Associations).Create(&announcement).Error; err != nil {
+			return "", copiedFiles, fmt.Errorf("failed to create announcement: %w", err)
+		}
+		newID = announcement.MaterialID
+	default:
+		return "", copiedFiles, fmt.Errorf("unsupported material type: %s", material.Type)
+	}
+
+	referenceType := string(material.Type)
+	courseMaterial := &models.CourseMaterial{
+		MaterialID:    newID,
+		CourseID:      targetCourseID,
+		Type:          material.Type,
+		Week:          material.Week,
+		ReferenceID:   &newID,
+		ReferenceType: &referenceType,
+	}
+	if err := tx.Omit(clause.Associations).Create(courseMaterial).Error; err != nil {
+		return "", copiedFiles, fmt.Errorf("failed to create course material reference: %w", err)
+	}
+
+	return newID, copiedFiles, nil
+}
+
+// RemapClonedReferences points the prerequisites and linked materials of cloned materials
+// at the clones of the materials they refer to. idMap maps each source material ID to
+// the ID of its clone; references to materials that were not cloned are dropped, since
+// they belong to another course.
+func (s *CourseMaterialService) RemapClonedReferences(tx *gorm.DB, idMap map[string]string) error {
+	if len(idMap) == 0 {
+		return nil
+	}
+	cloneIDs := make([]string, 0, len(idMap))
+	for _, cloneID := range idMap {
+		cloneIDs = append(cloneIDs, cloneID)
+	}
+	remap := func(ids types.StringList) types.StringList {
+		remapped := types.StringList{}
+		for _, id := range ids {
+			if cloneID, ok := idMap[id]; ok {
+				remapped = append(remapped, cloneID)
+			}
+		}
+		return remapped
+	}
+
+	for _, table := range []interface{}{&models.CodeExercise{}, &models.PDFExercise{}} {
+		var exercises []struct {
+			MaterialID    string
+			Prerequisites types.StringList
+		}
+		if err := tx.Model(table).Select("material_id", "prerequisites").
+			Where("material_id IN ?", cloneIDs).Find(&exercises).Error; err != nil {
+			return fmt.Errorf("failed to get prerequisites: %w", err)
+		}
+		for _, exercise := range exercises {
+			if len(exercise.Prerequisites) == 0 {
+				continue
+			}
+			if err := tx.Model(table).Where("material_id = ?", exercise.MaterialID).
+				Update("prerequisites", remap(exercise.Prerequisites)).Error; err != nil {
+				return fmt.Errorf("failed to update prerequisites of %s: %w", exercise.MaterialID, err)
+			}
+		}
+	}
+
+	var announcements []models.Announcement
+	if err := tx.Select("material_id", "linked_material_id").
+		Where("material_id IN ? AND linked_material_id IS NOT NULL", cloneIDs).Find(&announcements).Error; err != nil {
+		return fmt.Errorf("failed to get linked materials: %w", err)
+	}
+	for _, announcement := range announcements {
+		var linked *string
+		if cloneID, ok := idMap[*announcement.LinkedMaterialID]; ok {
+			linked = &cloneID
+		}
+		if err := tx.Model(&models.Announcement{}).Where("material_id = ?", announcement.MaterialID).
+			Update("linked_material_id", linked).Error; err != nil {
+			return fmt.Errorf("failed to update linked material of %s: %w", announcement.MaterialID, err)
+		}
+	}
+	return nil
+}
+
+// cloneProblemImages copies every image referenced by a problem_images array.
+// Entries may be plain URL strings or objects with a "url" field.
+func (s *CourseMaterialService) cloneProblemImages(images types.JSONData, copyFile func(string) (string, error)) (types.JSONData, error) {
+	if len(images) == 0 {
+		return images, nil
+	}
+
+	var entries []interface{}
+	if err := json.Unmarshal(images, &entries); err != nil {
+		// Not an array we understand; keep the original value
+		return images, nil
+	}
+
+	for i, entry := range entries {
+		switch v := entry.(type) {
+		case string:
+			newURL, err := copyFile(v)
+			if err != nil {
+				return nil, err
+			}
+			entries[i] = newURL
+		case map[string]interface{}:
+			if url, ok := v["url"].(string); ok {
+				newURL, err := copyFile(url)
+				if err != nil {
+					return nil, err
+				}
+				v["url"] = newURL
+			}
+		}
+	}
+
+	cloned, err := json.Marshal(entries)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode problem images: %w", err)
+	}
+	return types.JSONData(cloned), nil
+}
@@ -0,0 +1,124 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	models "github.com/Project-DSView/backend/go/internal/domain/entities"
+	"github.com/Project-DSView/backend/go/internal/types"
+	"github.com/Project-DSView/backend/go/pkg/logger"
+	"gorm.io/gorm"
+)
+
+// Errors returned by TransferOwnership
+var (
+	ErrNotAdmin             = errors.New("only admins can transfer course ownership")
+	ErrNewOwnerNotTeacher   = errors.New("the new owner must be a teacher")
+	ErrNewOwnerAlreadyOwner = errors.New("the user already owns this course")
+)
+
+// SetAdminEmails sets the users allowed to run admin actions, matched by email
+func (s *CourseService) SetAdminEmails(emails []string) {
+	s.adminEmails = make(map[string]bool, len(emails))
+	for _, email := range emails {
+		if email = strings.ToLower(strings.TrimSpace(email)); email != "" {
+			s.adminEmails[email] = true
+		}
+	}
+}
+
+// IsAdmin reports whether the user is one of the configured admins
+func (s *CourseService) IsAdmin(userID string) (bool, error) {
+	if len(s.adminEmails) == 0 {
+		return false, nil
+	}
+	user, err := s.userService.GetUserByID(userID)
+	if err != nil {
+		return false, fmt.Errorf("get user: %w", err)
+	}
+	return user != nil && s.adminEmails[strings.ToLower(user.Email)], nil
+}
+
+// materialTables are the specific material tables whose rows record a creator
+var materialTables = []interface{}{
+	&models.Video{}, &models.Document{}, &models.CodeExercise{}, &models.PDFExercise{}, &models.Announcement{},
+}
+
+// TransferOwnership makes newOwnerID the owner of the course, e.g. when its teacher leaves.
+// Course access follows created_by, so the new owner manages the course from then on and
+// the previous owner keeps only what an enrollment gives them. The API keys the previous
+// owner made for the course are revoked, since the new owner did not hand them out.
+// Enrollments and materials are kept as they are; with reassignMaterials, weeks and
+// materials the previous owner created move to the new owner too.
+func (s *CourseService) TransferOwnership(courseID, newOwnerID, actorID string, reassignMaterials bool) (*types.OwnershipTransfer, error) {
+	isAdmin, err := s.IsAdmin(actorID)
+	if err != nil {
+		return nil, err
+	}
+	if !isAdmin {
+		return nil, ErrNotAdmin
+	}
+
+	newOwner, err := s.userService.GetUserByID(newOwnerID)
+	if err != nil {
+		return nil, fmt.Errorf("get new owner: %w", err)
+	}
+	if newOwner == nil || !newOwner.IsTeacher {
+		return nil, ErrNewOwnerNotTeacher
+	}
+
+	result := &types.OwnershipTransfer{CourseID: courseID, NewOwnerID: newOwnerID}
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		var course models.Course
+		if err := tx.Select("course_id", "created_by").Where("course_id = ?", courseID).First(&course).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return fmt.Errorf("course not found")
+			}
+			return fmt.Errorf("get course: %w", err)
+		}
+		if course.CreatedBy == newOwnerID {
+			return ErrNewOwnerAlreadyOwner
+		}
+		result.PreviousOwnerID = course.CreatedBy
+
+		if err := tx.Model(&models.Course{}).Where("course_id = ?", courseID).
+			Update("created_by", newOwnerID).Error; err != nil {
+			return fmt.Errorf("update course owner: %w", err)
+		}
+
+		revoked, err := revokeCourseKeys(tx, courseID, course.CreatedBy)
+		if err != nil {
+			return err
+		}
+		result.APIKeysRevoked = revoked
+
+		if !reassignMaterials {
+			return nil
+		}
+		weeks := tx.Model(&models.CourseWeek{}).
+			Where("course_id = ? AND created_by = ?", courseID, course.CreatedBy).
+			Update("created_by", newOwnerID)
+		if weeks.Error != nil {
+			return fmt.Errorf("reassign weeks: %w", weeks.Error)
+		}
+		result.WeeksReassigned = int(weeks.RowsAffected)
+		for _, table := range materialTables {
+			materials := tx.Model(table).
+				Where("course_id = ? AND created_by = ?", courseID, course.CreatedBy).
+				Update("created_by", newOwnerID)
+			if materials.Error != nil {
+				return fmt.Errorf("reassign materials: %w", materials.Error)
+			}
+			result.MaterialsReassigned += int(materials.RowsAffected)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Infof("Course %s transferred from %s to %s by %s (reassigned %d materials, %d weeks; revoked %d API keys)",
+		courseID, result.PreviousOwnerID, newOwnerID, actorID, result.MaterialsReassigned, result.WeeksReassigned, result.APIKeysRevoked)
+	return result, nil
+}
@@ -0,0 +1,50 @@
+package services
+
+import (
+	"testing"
+
+	models "github.com/Project-DSView/backend/go/internal/domain/entities"
+	"github.com/Project-DSView/backend/go/internal/types"
+)
+
+// TestTransferOwnershipRevokesAPIKeys checks that a transfer revokes the keys the previous
+// owner made for the course, and only those
+func TestTransferOwnershipRevokesAPIKeys(t *testing.T) {
+	db := newTestDB(t, &models.User{}, &models.Course{}, &models.CourseAPIKey{})
+	createRows(t, db,
+		&models.User{UserID: "admin", FirstName: "A", LastName: "Admin", Email: "admin@example.com"},
+		&models.User{UserID: "old-owner", FirstName: "O", LastName: "Owner", Email: "old@example.com", IsTeacher: true},
+		&models.User{UserID: "new-owner", FirstName: "N", LastName: "Owner", Email: "new@example.com", IsTeacher: true},
+		&models.Course{CourseID: "course-1", Name: "Data Structures", CreatedBy: "old-owner"},
+		&models.CourseAPIKey{KeyID: "course-key", KeyHash: "h1", KeyPrefix: "p1", Name: "LMS", CourseIDs: types.JSONData(`["course-1"]`), CreatedBy: "old-owner"},
+		&models.CourseAPIKey{KeyID: "shared-key", KeyHash: "h2", KeyPrefix: "p2", Name: "LMS", CourseIDs: types.JSONData(`["course-2","course-1"]`), CreatedBy: "old-owner"},
+		&models.CourseAPIKey{KeyID: "other-course-key", KeyHash: "h3", KeyPrefix: "p3", Name: "LMS", CourseIDs: types.JSONData(`["course-2"]`), CreatedBy: "old-owner"},
+		&models.CourseAPIKey{KeyID: "other-teacher-key", KeyHash: "h4", KeyPrefix: "p4", Name: "LMS", CourseIDs: types.JSONData(`["course-1"]`), CreatedBy: "ta-1"},
+	)
+
+	svc := NewCourseService(db, NewUserService(db), nil)
+	svc.SetAdminEmails([]string{"admin@example.com"})
+	result, err := svc.TransferOwnership("course-1", "new-owner", "admin", false)
+	if err != nil {
+		t.Fatalf("TransferOwnership() error = %v", err)
+	}
+	if result.APIKeysRevoked != 2 {
+		t.Errorf("APIKeysRevoked = %d, want 2", result.APIKeysRevoked)
+	}
+
+	wantRevoked := map[string]bool{
+		"course-key":        true,
+		"shared-key":        true,
+		"other-course-key":  false,
+		"other-teacher-key": false,
+	}
+	for keyID, want := range wantRevoked {
+		var key models.CourseAPIKey
+		if err := db.First(&key, "key_id = ?", keyID).Error; err != nil {
+			t.Fatalf("load key %s: %v", keyID, err)
+		}
+		if revoked := key.RevokedAt != nil; revoked != want {
+			t.Errorf("key %s revoked = %v, want %v", keyID, revoked, want)
+		}
+	}
+}
@@ -3,72 +3,32 @@ package services
 import (
 	"context"
 	"fmt"
+	"time"
 
 	entities "github.com/Project-DSView/backend/go/internal/domain/entities"
 	"github.com/Project-DSView/backend/go/internal/domain/repositories"
+	"github.com/Project-DSView/backend/go/pkg/authz"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // CourseScoreService handles course score business logic
 type CourseScoreService struct {
-	courseScoreRepo     repositories.CourseScoreRepository
-	studentProgressRepo repositories.StudentProgressRepository
+	db              *gorm.DB
+	courseScoreRepo repositories.CourseScoreRepository
 }
 
 // NewCourseScoreService creates a new course score service
-func NewCourseScoreService(
-	courseScoreRepo repositories.CourseScoreRepository,
-	studentProgressRepo repositories.StudentProgressRepository,
-) *CourseScoreService {
+func NewCourseScoreService(db *gorm.DB, courseScoreRepo repositories.CourseScoreRepository) *CourseScoreService {
 	return &CourseScoreService{
-		courseScoreRepo:     courseScoreRepo,
-		studentProgressRepo: studentProgressRepo,
+		db:              db,
+		courseScoreRepo: courseScoreRepo,
 	}
 }
 
-// UpdateCourseScore updates the total score for a student in a course
-// This method is optimized to avoid N+1 queries by using batch operations
+// UpdateCourseScore recomputes the total score for a student in a course
 func (s *CourseScoreService) UpdateCourseScore(ctx context.Context, userID, courseID string) error {
-	// Get all student progress for the student in this course (single query)
-	progressList, err := s.studentProgressRepo.GetByUserAndCourse(ctx, userID, courseID)
-	if err != nil {
-		return fmt.Errorf("failed to get student progress: %w", err)
-	}
-
-	if len(progressList) == 0 {
-		return nil // No progress to calculate
-	}
-
-	// Calculate total score directly from progress (no need for exercise lookup)
-
-	// Calculate total score
-	totalScore := 0
-	for _, progress := range progressList {
-		totalScore += progress.Score
-	}
-
-	// Create or update course score
-	courseScore := entities.NewCourseScore(userID, courseID, totalScore)
-
-	// Check if course score already exists
-	existingScore, err := s.courseScoreRepo.GetByUserAndCourse(ctx, userID, courseID)
-	if err != nil {
-		return fmt.Errorf("failed to check existing course score: %w", err)
-	}
-
-	if existingScore == nil {
-		// Create new course score
-		if err := s.courseScoreRepo.Create(ctx, courseScore); err != nil {
-			return fmt.Errorf("failed to create course score: %w", err)
-		}
-	} else {
-		// Update existing course score
-		existingScore.UpdateScore(totalScore)
-		if err := s.courseScoreRepo.Update(ctx, existingScore); err != nil {
-			return fmt.Errorf("failed to update course score: %w", err)
-		}
-	}
-
-	return nil
+	return recomputeCourseScore(s.db.WithContext(ctx), userID, courseID)
 }
 
 // GetStudentCourseScore gets the total score for a student in a course
@@ -86,7 +46,6 @@ func (s *CourseScoreService) GetStudentCourseScore(ctx context.Context, userID,
 	return courseScorePtr, nil
 }
 
-
 // GetCourseScoreStats gets statistics for scores in a course
 func (s *CourseScoreService) GetCourseScoreStats(ctx context.Context, courseID string) (*repositories.CourseScoreStats, error) {
 	// Get from database
@@ -98,6 +57,11 @@ func (s *CourseScoreService) GetCourseScoreStats(ctx context.Context, courseID s
 	return statsPtr, nil
 }
 
+// GetCourseScores gets the total scores of every student in a course
+func (s *CourseScoreService) GetCourseScores(ctx context.Context, courseID string) ([]entities.CourseScore, error) {
+	return s.courseScoreRepo.GetByCourse(ctx, courseID)
+}
+
 // GetStudentScoreStats gets statistics for a student's scores across all courses
 func (s *CourseScoreService) GetStudentScoreStats(ctx context.Context, userID string) (*repositories.StudentScoreStats, error) {
 	// Get from database
@@ -109,52 +73,52 @@ func (s *CourseScoreService) GetStudentScoreStats(ctx context.Context, userID st
 	return statsPtr, nil
 }
 
-// BatchUpdateCourseScores updates course scores for multiple students efficiently
+// BatchUpdateCourseScores recomputes the course scores of several students in one transaction
 func (s *CourseScoreService) BatchUpdateCourseScores(ctx context.Context, userIDs []string, courseID string) error {
-	// Get all student progress for all users in this course (single query)
-	progressMap := make(map[string][]entities.StudentProgress)
-
-	for _, userID := range userIDs {
-		progressList, err := s.studentProgressRepo.GetByUserAndCourse(ctx, userID, courseID)
-		if err != nil {
-			return fmt.Errorf("failed to get student progress for user %s: %w", userID, err)
-		}
-		progressMap[userID] = progressList
-	}
-
-	// Calculate scores directly from progress (no exercise lookup needed)
-
-	// Calculate and update course scores for each user
-	for _, userID := range userIDs {
-		progressList := progressMap[userID]
-
-		totalScore := 0
-		for _, progress := range progressList {
-			totalScore += progress.Score
-		}
-
-		courseScore := entities.NewCourseScore(userID, courseID, totalScore)
-
-		// Check if course score already exists
-		existingScore, err := s.courseScoreRepo.GetByUserAndCourse(ctx, userID, courseID)
-		if err != nil {
-			return fmt.Errorf("failed to check existing course score for user %s: %w", userID, err)
-		}
-
-		if existingScore == nil {
-			// Create new course score
-			if err := s.courseScoreRepo.Create(ctx, courseScore); err != nil {
-				return fmt.Errorf("failed to create course score for user %s: %w", userID, err)
-			}
-		} else {
-			// Update existing course score
-			existingScore.UpdateScore(totalScore)
-			if err := s.courseScoreRepo.Update(ctx, existingScore); err != nil {
-				return fmt.Errorf("failed to update course score for user %s: %w", userID, err)
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, userID := range userIDs {
+			if err := recomputeCourseScore(tx, userID, courseID); err != nil {
+				return fmt.Errorf("user %s: %w", userID, err)
 			}
 		}
+		return nil
+	})
+}
 
+// recomputeCourseScore sets a student's course total to the sum of their progress scores
+// in the course. Every operation that changes a progress score calls it in the same
+// transaction (grading, rejudging, resubmitting, approving or rejecting a review), so the
+// total cannot drift from the scores it is made of. CourseScoreService's updates use it too.
+func recomputeCourseScore(tx *gorm.DB, userID, courseID string) error {
+	var totalScore int
+	if err := tx.Table("student_progress sp").
+		Joins("INNER JOIN course_materials cm ON sp.material_id = cm.material_id").
+		Where("sp.user_id = ? AND cm.course_id = ?", userID, courseID).
+		Select("COALESCE(SUM(sp.score), 0)").
+		Scan(&totalScore).Error; err != nil {
+		return fmt.Errorf("sum progress scores: %w", err)
 	}
 
+	courseScore := entities.StudentCourseScore{
+		UserID:      userID,
+		CourseID:    courseID,
+		TotalScore:  totalScore,
+		LastUpdated: time.Now(),
+	}
+	if err := tx.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}, {Name: "course_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"total_score", "last_updated"}),
+	}).Create(&courseScore).Error; err != nil {
+		return fmt.Errorf("save course score: %w", err)
+	}
 	return nil
 }
+
+// recomputeCourseScoreForMaterial is recomputeCourseScore for the course a material belongs to
+func recomputeCourseScoreForMaterial(tx *gorm.DB, userID, materialID string) error {
+	courseID, err := authz.MaterialCourseID(tx, materialID)
+	if err != nil {
+		return err
+	}
+	return recomputeCourseScore(tx, userID, courseID)
+}
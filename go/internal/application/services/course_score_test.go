@@ -0,0 +1,179 @@
+package services
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	models "github.com/Project-DSView/backend/go/internal/domain/entities"
+	"github.com/Project-DSView/backend/go/internal/domain/enums"
+	"github.com/Project-DSView/backend/go/internal/types"
+	"github.com/Project-DSView/backend/go/pkg/external"
+	"gorm.io/gorm"
+)
+
+// fakeDockerExecutor returns an executor whose docker command, found first on PATH,
+// prints stdout for every run and exits 0
+func fakeDockerExecutor(t *testing.T, stdout string) *external.DockerExecutor {
+	t.Helper()
+	dir := t.TempDir()
+	script := "#!/bin/sh\ncat >/dev/null\nprintf '%s\\n' \"$FAKE_DOCKER_STDOUT\"\n"
+	if err := os.WriteFile(filepath.Join(dir, "docker"), []byte(script), 0o755); err != nil {
+		t.Fatalf("write fake docker: %v", err)
+	}
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	t.Setenv("FAKE_DOCKER_STDOUT", stdout)
+	return external.NewDockerExecutor(external.DockerConfig{})
+}
+
+// TestCourseScoreFollowsProgress checks that every path changing a progress score leaves
+// the student's course total equal to the sum of their progress scores. The course has a
+// 10-point code exercise with two test cases, a 20-point PDF exercise and a document the
+// student already has 5 points on; the stored total starts out wrong on purpose.
+func TestCourseScoreFollowsProgress(t *testing.T) {
+	tests := []struct {
+		name string
+		// setup adds the rows the operation works on
+		setup func(t *testing.T, db *gorm.DB, codeID, pdfID string)
+		run   func(t *testing.T, db *gorm.DB, codeID, pdfID string) error
+		want  int
+	}{
+		{
+			name: "grade",
+			setup: func(t *testing.T, db *gorm.DB, codeID, pdfID string) {
+				createRows(t, db, &models.Submission{SubmissionID: "sub-1", UserID: "student-1", MaterialID: codeID, Status: enums.SubmissionRunning})
+			},
+			run: func(t *testing.T, db *gorm.DB, codeID, pdfID string) error {
+				svc := NewSubmissionService(db, nil, nil, nil, nil, NewCourseMaterialService(db, nil), fakeDockerExecutor(t, `{"output": 3}`), nil, nil)
+				return svc.ExecuteCodeSubmission("sub-1", "print(3)", codeID)
+			},
+			want: 5 + 5, // one of two test cases
+		},
+		{
+			name: "rejudge",
+			setup: func(t *testing.T, db *gorm.DB, codeID, pdfID string) {
+				createRows(t, db,
+					&models.Submission{SubmissionID: "sub-1", UserID: "student-1", MaterialID: codeID, Status: enums.SubmissionRunning, PassedCount: 2, TotalScore: 10},
+					&models.StudentProgress{UserID: "student-1", MaterialID: codeID, Status: enums.ProgressInProgress, Score: 10})
+			},
+			run: func(t *testing.T, db *gorm.DB, codeID, pdfID string) error {
+				svc := NewSubmissionService(db, nil, nil, nil, nil, NewCourseMaterialService(db, nil), fakeDockerExecutor(t, `{"output": 4}`), nil, nil)
+				return svc.ExecuteRejudge("sub-1", "print(4)", codeID)
+			},
+			want: 5 + 5,
+		},
+		{
+			name: "review approve",
+			setup: func(t *testing.T, db *gorm.DB, codeID, pdfID string) {
+				createReviewJob(t, db, codeID, 10)
+			},
+			run: func(t *testing.T, db *gorm.DB, codeID, pdfID string) error {
+				_, err := NewQueueService(db, nil, nil).CompleteReview("job-1", "ta-1", "approved", "")
+				return err
+			},
+			want: 5 + 10,
+		},
+		{
+			name: "review reject",
+			setup: func(t *testing.T, db *gorm.DB, codeID, pdfID string) {
+				createReviewJob(t, db, codeID, 10)
+			},
+			run: func(t *testing.T, db *gorm.DB, codeID, pdfID string) error {
+				_, err := NewQueueService(db, nil, nil).CompleteReview("job-1", "ta-1", "rejected", "try again")
+				return err
+			},
+			want: 5,
+		},
+		{
+			name: "PDF approve",
+			setup: func(t *testing.T, db *gorm.DB, codeID, pdfID string) {
+				createRows(t, db,
+					&models.Submission{SubmissionID: "sub-1", UserID: "student-1", MaterialID: pdfID, Status: enums.SubmissionPending, FileURL: "s.pdf"},
+					&models.StudentProgress{UserID: "student-1", MaterialID: pdfID, Status: enums.ProgressInProgress})
+			},
+			run: func(t *testing.T, db *gorm.DB, codeID, pdfID string) error {
+				return NewPDFExerciseSubmissionService(db, nil, nil).ApprovePDFSubmission("sub-1", "ta-1", 18, "")
+			},
+			want: 5 + 18,
+		},
+		{
+			name: "course score service update",
+			setup: func(t *testing.T, db *gorm.DB, codeID, pdfID string) {
+				createRows(t, db, &models.StudentProgress{UserID: "student-1", MaterialID: codeID, Status: enums.ProgressCompleted, Score: 7})
+			},
+			run: func(t *testing.T, db *gorm.DB, codeID, pdfID string) error {
+				return NewCourseScoreService(db, nil).UpdateCourseScore(context.Background(), "student-1", "course-1")
+			},
+			want: 5 + 7,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := newTestDB(t, &models.Course{}, &models.CourseMaterial{}, &models.CodeExercise{}, &models.PDFExercise{},
+				&models.TestCase{}, &models.Submission{}, &models.SubmissionResult{}, &models.StudentProgress{},
+				&models.VerificationLog{}, &models.QueueJob{}, &models.StudentCourseScore{})
+
+			codePoints, pdfPoints := 10, 20
+			code := models.CodeExercise{
+				MaterialBase:     models.MaterialBase{CourseID: "course-1", Title: "Sum", CreatedBy: "teacher-1"},
+				TotalPoints:      &codePoints,
+				ProblemStatement: "Add the numbers",
+			}
+			pdf := models.PDFExercise{
+				MaterialBase: models.MaterialBase{CourseID: "course-1", Title: "Sheet", CreatedBy: "teacher-1"},
+				TotalPoints:  &pdfPoints,
+				FileURL:      "sheet.pdf",
+				FileName:     "sheet.pdf",
+			}
+			createRows(t, db, &models.Course{CourseID: "course-1", Name: "Data Structures", CreatedBy: "teacher-1"}, &code, &pdf)
+			codeType, pdfType := string(enums.MaterialTypeCodeExercise), string(enums.MaterialTypePDFExercise)
+			createRows(t, db,
+				&models.CourseMaterial{MaterialID: code.MaterialID, CourseID: "course-1", Type: enums.MaterialTypeCodeExercise, ReferenceID: &code.MaterialID, ReferenceType: &codeType},
+				&models.CourseMaterial{MaterialID: pdf.MaterialID, CourseID: "course-1", Type: enums.MaterialTypePDFExercise, ReferenceID: &pdf.MaterialID, ReferenceType: &pdfType},
+				&models.CourseMaterial{MaterialID: "doc-1", CourseID: "course-1", Type: enums.MaterialTypeDocument},
+				&models.TestCase{MaterialID: &code.MaterialID, MaterialType: codeType, InputData: types.JSONData(`[1,2]`), ExpectedOutput: types.JSONData(`{"output":3}`)},
+				&models.TestCase{MaterialID: &code.MaterialID, MaterialType: codeType, InputData: types.JSONData(`[2,2]`), ExpectedOutput: types.JSONData(`{"output":4}`)},
+				&models.StudentProgress{UserID: "student-1", MaterialID: "doc-1", Status: enums.ProgressCompleted, Score: 5},
+				&models.StudentCourseScore{UserID: "student-1", CourseID: "course-1", TotalScore: 999},
+			)
+			tt.setup(t, db, code.MaterialID, pdf.MaterialID)
+
+			if err := tt.run(t, db, code.MaterialID, pdf.MaterialID); err != nil {
+				t.Fatalf("%s: %v", tt.name, err)
+			}
+
+			var score models.StudentCourseScore
+			if err := db.First(&score, "user_id = ? AND course_id = ?", "student-1", "course-1").Error; err != nil {
+				t.Fatalf("get course score: %v", err)
+			}
+			if score.TotalScore != tt.want {
+				t.Errorf("course total = %d, want %d", score.TotalScore, tt.want)
+			}
+		})
+	}
+}
+
+// createRows inserts rows in order, failing the test on the first error
+func createRows(t *testing.T, db *gorm.DB, rows ...interface{}) {
+	t.Helper()
+	for _, row := range rows {
+		if err := db.Create(row).Error; err != nil {
+			t.Fatalf("create %T: %v", row, err)
+		}
+	}
+}
+
+// createReviewJob adds a code submission scoring score, waiting for review, with its
+// review job claimed by ta-1
+func createReviewJob(t *testing.T, db *gorm.DB, codeID string, score int) {
+	t.Helper()
+	reviewer, subID, courseID := "ta-1", "sub-1", "course-1"
+	createRows(t, db,
+		&models.Submission{SubmissionID: subID, UserID: "student-1", MaterialID: codeID, Status: enums.SubmissionPending, TotalScore: score},
+		&models.StudentProgress{UserID: "student-1", MaterialID: codeID, Status: enums.ProgressInProgress, Score: score},
+		&models.QueueJob{ID: "job-1", Type: enums.QueueTypeReview, Status: enums.QueueStatusProcessing, UserID: "student-1",
+			MaterialID: &codeID, CourseID: &courseID, SubmissionID: &subID, ProcessedBy: &reviewer},
+	)
+}
@@ -0,0 +1,172 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	models "github.com/Project-DSView/backend/go/internal/domain/entities"
+	"github.com/Project-DSView/backend/go/internal/domain/enums"
+	"github.com/Project-DSView/backend/go/internal/types"
+	"github.com/Project-DSView/backend/go/pkg/logger"
+	"gorm.io/gorm"
+)
+
+// courseStatsBatchSize is how many courses one round of snapshot queries covers
+const courseStatsBatchSize = 200
+
+// courseStatsRetention is how long snapshots are kept before the snapshot task deletes them
+const courseStatsRetention = 90 * 24 * time.Hour
+
+// courseAverage is one row of a per-course AVG
+type courseAverage struct {
+	CourseID string
+	Average  float64
+}
+
+// computeCourseStats runs the aggregate queries behind a stats snapshot for several
+// courses at once, one grouped query per figure. Courses without any rows get zeros.
+func (s *CourseService) computeCourseStats(courseIDs []string, takenAt time.Time) ([]models.CourseStatsSnapshot, error) {
+	exerciseTypes := []string{string(enums.MaterialTypeCodeExercise), string(enums.MaterialTypePDFExercise)}
+
+	students, err := scanCourseTotals(s.db.Model(&models.Enrollment{}).
+		Select("course_id, COUNT(*) AS total").
+		Where("course_id IN ? AND role = ?", courseIDs, enums.EnrollmentRoleStudent).
+		Group("course_id"))
+	if err != nil {
+		return nil, fmt.Errorf("count students: %w", err)
+	}
+
+	exercises, err := scanCourseTotals(s.db.Model(&models.CourseMaterial{}).
+		Select("course_id, COUNT(*) AS total").
+		Where("course_id IN ? AND type IN ?", courseIDs, exerciseTypes).
+		Group("course_id"))
+	if err != nil {
+		return nil, fmt.Errorf("count exercises: %w", err)
+	}
+
+	// Only students still enrolled count towards completion and the average score
+	completed, err := scanCourseTotals(s.db.Table("student_progress sp").
+		Select("cm.course_id AS course_id, COUNT(*) AS total").
+		Joins("INNER JOIN course_materials cm ON sp.material_id = cm.material_id").
+		Joins("INNER JOIN enrollments e ON e.course_id = cm.course_id AND e.user_id = sp.user_id AND e.role = ?", enums.EnrollmentRoleStudent).
+		Where("cm.course_id IN ? AND cm.type IN ? AND sp.status = ?", courseIDs, exerciseTypes, enums.ProgressCompleted).
+		Group("cm.course_id"))
+	if err != nil {
+		return nil, fmt.Errorf("count completed exercises: %w", err)
+	}
+
+	submissions, err := scanCourseTotals(s.db.Table("submissions sub").
+		Select("cm.course_id AS course_id, COUNT(*) AS total").
+		Joins("INNER JOIN course_materials cm ON sub.material_id = cm.material_id").
+		Where("cm.course_id IN ? AND sub.status <> ?", courseIDs, enums.SubmissionCancelled).
+		Group("cm.course_id"))
+	if err != nil {
+		return nil, fmt.Errorf("count submissions: %w", err)
+	}
+
+	var averageRows []courseAverage
+	if err := s.db.Table("enrollments e").
+		Select("e.course_id AS course_id, AVG(COALESCE(scs.total_score, 0)) AS average").
+		Joins("LEFT JOIN student_course_scores scs ON scs.course_id = e.course_id AND scs.user_id = e.user_id").
+		Where("e.course_id IN ? AND e.role = ?", courseIDs, enums.EnrollmentRoleStudent).
+		Group("e.course_id").
+		Scan(&averageRows).Error; err != nil {
+		return nil, fmt.Errorf("average scores: %w", err)
+	}
+	averages := make(map[string]float64, len(averageRows))
+	for _, row := range averageRows {
+		averages[row.CourseID] = row.Average
+	}
+
+	snapshots := make([]models.CourseStatsSnapshot, len(courseIDs))
+	for i, courseID := range courseIDs {
+		snapshot := models.CourseStatsSnapshot{
+			CourseID:        courseID,
+			StudentCount:    students[courseID],
+			ExerciseCount:   exercises[courseID],
+			AverageScore:    averages[courseID],
+			SubmissionCount: submissions[courseID],
+			TakenAt:         takenAt,
+		}
+		if possible := snapshot.StudentCount * snapshot.ExerciseCount; possible > 0 {
+			snapshot.CompletionRate = float64(completed[courseID]) * 100 / float64(possible)
+		}
+		snapshots[i] = snapshot
+	}
+	return snapshots, nil
+}
+
+// SnapshotCourseStats is the nightly scheduled task that records the stats of every active
+// course, a batch of courses at a time, and deletes snapshots past the retention period.
+// Courses that already have a snapshot from today are skipped, so running it again the
+// same day, e.g. when the scheduler starts after a restart, adds nothing.
+func (s *CourseService) SnapshotCourseStats(ctx context.Context) error {
+	takenAt := time.Now()
+	dayStart := time.Date(takenAt.Year(), takenAt.Month(), takenAt.Day(), 0, 0, 0, 0, takenAt.Location())
+
+	var courseIDs []string
+	if err := s.db.Model(&models.Course{}).
+		Where("status = ?", enums.CourseStatusActive).
+		Where("course_id NOT IN (?)", s.db.Model(&models.CourseStatsSnapshot{}).
+			Select("course_id").Where("taken_at >= ?", dayStart)).
+		Order("course_id").
+		Pluck("course_id", &courseIDs).Error; err != nil {
+		return fmt.Errorf("get active courses: %w", err)
+	}
+
+	for start := 0; start < len(courseIDs); start += courseStatsBatchSize {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		end := min(start+courseStatsBatchSize, len(courseIDs))
+		snapshots, err := s.computeCourseStats(courseIDs[start:end], takenAt)
+		if err != nil {
+			return err
+		}
+		if err := s.db.Create(&snapshots).Error; err != nil {
+			return fmt.Errorf("save course stats snapshots: %w", err)
+		}
+	}
+
+	if err := s.db.Where("taken_at < ?", takenAt.Add(-courseStatsRetention)).
+		Delete(&models.CourseStatsSnapshot{}).Error; err != nil {
+		return fmt.Errorf("delete old course stats snapshots: %w", err)
+	}
+
+	logger.Infof("Took course stats snapshots of %d active courses without one from today", len(courseIDs))
+	return nil
+}
+
+// GetCourseStats returns the course's latest stats snapshot. With live, or when the course
+// has no snapshot yet (e.g. it was created today), the figures are computed for this
+// request instead; live figures are not saved.
+func (s *CourseService) GetCourseStats(courseID string, live bool) (*types.CourseStats, error) {
+	var snapshot models.CourseStatsSnapshot
+	if !live {
+		err := s.db.Where("course_id = ?", courseID).Order("taken_at DESC").First(&snapshot).Error
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("get course stats snapshot: %w", err)
+		}
+		live = err != nil
+	}
+	if live {
+		snapshots, err := s.computeCourseStats([]string{courseID}, time.Now())
+		if err != nil {
+			return nil, err
+		}
+		snapshot = snapshots[0]
+	}
+
+	return &types.CourseStats{
+		CourseID:        snapshot.CourseID,
+		StudentCount:    snapshot.StudentCount,
+		ExerciseCount:   snapshot.ExerciseCount,
+		CompletionRate:  snapshot.CompletionRate,
+		AverageScore:    snapshot.AverageScore,
+		SubmissionCount: snapshot.SubmissionCount,
+		SnapshotAt:      snapshot.TakenAt,
+		Live:            live,
+	}, nil
+}
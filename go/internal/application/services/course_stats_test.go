@@ -0,0 +1,47 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	models "github.com/Project-DSView/backend/go/internal/domain/entities"
+	"github.com/Project-DSView/backend/go/internal/domain/enums"
+)
+
+// TestSnapshotCourseStatsOncePerDay checks that running the snapshot task again on the
+// same day, as the scheduler does when it starts, does not add a second snapshot, while a
+// course whose latest snapshot is from an earlier day gets a new one
+func TestSnapshotCourseStatsOncePerDay(t *testing.T) {
+	db := newTestDB(t, &models.Course{}, &models.Enrollment{}, &models.CourseMaterial{}, &models.StudentProgress{},
+		&models.Submission{}, &models.StudentCourseScore{}, &models.CourseStatsSnapshot{})
+	createRows(t, db,
+		&models.Course{CourseID: "course-1", Name: "Data Structures", CreatedBy: "teacher-1", Status: enums.CourseStatusActive},
+		&models.Course{CourseID: "course-2", Name: "Algorithms", CreatedBy: "teacher-1", Status: enums.CourseStatusActive},
+		&models.Enrollment{CourseID: "course-1", UserID: "student-1", Role: enums.EnrollmentRoleStudent},
+		&models.CourseStatsSnapshot{CourseID: "course-2", TakenAt: time.Now().AddDate(0, 0, -1)},
+	)
+	svc := NewCourseService(db, nil, nil)
+
+	for run := 1; run <= 2; run++ {
+		if err := svc.SnapshotCourseStats(context.Background()); err != nil {
+			t.Fatalf("SnapshotCourseStats() run %d error = %v", run, err)
+		}
+	}
+
+	for courseID, want := range map[string]int64{"course-1": 1, "course-2": 2} {
+		var count int64
+		db.Model(&models.CourseStatsSnapshot{}).Where("course_id = ?", courseID).Count(&count)
+		if count != want {
+			t.Errorf("%s has %d snapshots, want %d", courseID, count, want)
+		}
+	}
+
+	stats, err := svc.GetCourseStats("course-1", false)
+	if err != nil {
+		t.Fatalf("GetCourseStats() error = %v", err)
+	}
+	if stats.Live || stats.StudentCount != 1 {
+		t.Errorf("stats = %+v, want the snapshot with 1 student", stats)
+	}
+}
@@ -0,0 +1,43 @@
+package services
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// newTestDB opens an empty SQLite database in the test's temp dir and creates the
+// tables of the given models. Postgres casts in column defaults are dropped so the
+// entities' migrations run unchanged.
+func newTestDB(t *testing.T, models ...interface{}) *gorm.DB {
+	t.Helper()
+	dsn := filepath.Join(t.TempDir(), "test.db") + "?_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)"
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{
+		Logger:                                   logger.Default.LogMode(logger.Silent),
+		DisableForeignKeyConstraintWhenMigrating: true,
+	})
+	if err != nil {
+		t.Fatalf("open test database: %v", err)
+	}
+	castRemover := strings.NewReplacer("::jsonb", "", "::json", "")
+	if err := db.Callback().Raw().Before("gorm:raw").Register("test:strip_casts", func(tx *gorm.DB) {
+		sql := castRemover.Replace(tx.Statement.SQL.String())
+		tx.Statement.SQL.Reset()
+		tx.Statement.SQL.WriteString(sql)
+	}); err != nil {
+		t.Fatalf("register callback: %v", err)
+	}
+	if err := db.AutoMigrate(models...); err != nil {
+		t.Fatalf("migrate test database: %v", err)
+	}
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+	return db
+}
@@ -0,0 +1,169 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	models "github.com/Project-DSView/backend/go/internal/domain/entities"
+	"github.com/Project-DSView/backend/go/internal/domain/enums"
+	"github.com/Project-DSView/backend/go/internal/types"
+	"github.com/Project-DSView/backend/go/pkg/authz"
+)
+
+// ErrNotCourseMember is returned when a user asks for the calendar of a course they are not in
+var ErrNotCourseMember = errors.New("you are not enrolled in this course")
+
+// calendarExercise is the part of a code or PDF exercise the deadline calendar reads
+type calendarExercise struct {
+	MaterialID string
+	CourseID   string
+	Title      string
+	Week       int
+	Deadline   *string
+	OpensAt    *time.Time
+	ClosesAt   *time.Time
+}
+
+// GetDeadlineCalendar returns the user's deadlines in one course: every published exercise
+// with a deadline or a closing submission window, with their extension applied and their
+// progress, earliest effective deadline first
+func (s *CourseMaterialService) GetDeadlineCalendar(courseID, userID string) ([]types.DeadlineCalendarEntry, error) {
+	canView, err := authz.CanViewCourse(s.db, userID, courseID)
+	if err != nil {
+		return nil, err
+	}
+	if !canView {
+		return nil, ErrNotCourseMember
+	}
+	return s.deadlineCalendar([]string{courseID}, userID)
+}
+
+// GetMyDeadlineCalendar is GetDeadlineCalendar over every active course the user is
+// enrolled in as a student
+func (s *CourseMaterialService) GetMyDeadlineCalendar(userID string) ([]types.DeadlineCalendarEntry, error) {
+	var courseIDs []string
+	if err := s.db.Table("enrollments e").
+		Joins("INNER JOIN courses c ON c.course_id = e.course_id").
+		Where("e.user_id = ? AND e.role = ? AND c.status = ?", userID, enums.EnrollmentRoleStudent, enums.CourseStatusActive).
+		Pluck("e.course_id", &courseIDs).Error; err != nil {
+		return nil, fmt.Errorf("get enrolled courses: %w", err)
+	}
+	if len(courseIDs) == 0 {
+		return []types.DeadlineCalendarEntry{}, nil
+	}
+	return s.deadlineCalendar(courseIDs, userID)
+}
+
+func (s *CourseMaterialService) deadlineCalendar(courseIDs []string, userID string) ([]types.DeadlineCalendarEntry, error) {
+	hasDeadline := "course_id IN ? AND is_public = ? AND ((deadline IS NOT NULL AND deadline <> '') OR closes_at IS NOT NULL)"
+	columns := []string{"material_id", "course_id", "title", "week", "deadline", "opens_at", "closes_at"}
+
+	var exercises []calendarExercise
+	var exerciseTypes []enums.MaterialType
+	for _, source := range []struct {
+		model        interface{}
+		materialType enums.MaterialType
+	}{
+		{&models.CodeExercise{}, enums.MaterialTypeCodeExercise},
+		{&models.PDFExercise{}, enums.MaterialTypePDFExercise},
+	} {
+		var found []calendarExercise
+		if err := s.db.Model(source.model).Select(columns).
+			Where(hasDeadline, courseIDs, true).
+			Scan(&found).Error; err != nil {
+			return nil, fmt.Errorf("get %s deadlines: %w", source.materialType, err)
+		}
+		for range found {
+			exerciseTypes = append(exerciseTypes, source.materialType)
+		}
+		exercises = append(exercises, found...)
+	}
+
+	entries := []types.DeadlineCalendarEntry{}
+	if len(exercises) == 0 {
+		return entries, nil
+	}
+	materialIDs := make([]string, len(exercises))
+	for i, exercise := range exercises {
+		materialIDs[i] = exercise.MaterialID
+	}
+
+	var courses []models.Course
+	if err := s.db.Select("course_id", "name").Where("course_id IN ?", courseIDs).Find(&courses).Error; err != nil {
+		return nil, fmt.Errorf("get courses: %w", err)
+	}
+	courseNames := make(map[string]string, len(courses))
+	for _, course := range courses {
+		courseNames[course.CourseID] = course.Name
+	}
+
+	var extensions []models.DeadlineExtension
+	if err := s.db.Where("user_id = ? AND material_id IN ?", userID, materialIDs).Find(&extensions).Error; err != nil {
+		return nil, fmt.Errorf("get deadline extensions: %w", err)
+	}
+	extended := make(map[string]time.Time, len(extensions))
+	for _, ext := range extensions {
+		extended[ext.MaterialID] = ext.Deadline
+	}
+
+	var progress []models.StudentProgress
+	if err := s.db.Select("material_id", "status").
+		Where("user_id = ? AND material_id IN ?", userID, materialIDs).
+		Find(&progress).Error; err != nil {
+		return nil, fmt.Errorf("get progress: %w", err)
+	}
+	statuses := make(map[string]enums.ProgressStatus, len(progress))
+	for _, p := range progress {
+		statuses[p.MaterialID] = p.Status
+	}
+
+	now := time.Now()
+	for i, exercise := range exercises {
+		entry := types.DeadlineCalendarEntry{
+			MaterialID:     exercise.MaterialID,
+			CourseID:       exercise.CourseID,
+			CourseName:     courseNames[exercise.CourseID],
+			Title:          exercise.Title,
+			Type:           string(exerciseTypes[i]),
+			Week:           exercise.Week,
+			OpensAt:        exercise.OpensAt,
+			ClosesAt:       exercise.ClosesAt,
+			ProgressStatus: string(enums.ProgressNotStarted),
+		}
+		if status, ok := statuses[exercise.MaterialID]; ok {
+			entry.ProgressStatus = string(status)
+		}
+
+		// Same rules as CanSubmitMaterial: an extension replaces the deadline, and without
+		// a deadline the window's close is the last moment to submit
+		if exercise.Deadline != nil && *exercise.Deadline != "" {
+			deadline, err := time.Parse(time.RFC3339, *exercise.Deadline)
+			if err != nil {
+				continue // Skip invalid deadlines
+			}
+			entry.Deadline = &deadline
+			entry.EffectiveDeadline = deadline
+		} else if exercise.ClosesAt != nil {
+			entry.EffectiveDeadline = *exercise.ClosesAt
+		} else {
+			continue
+		}
+		if ext, ok := extended[exercise.MaterialID]; ok {
+			entry.ExtendedDeadline = &ext
+			entry.EffectiveDeadline = ext
+		}
+
+		switch enums.ProgressStatus(entry.ProgressStatus) {
+		case enums.ProgressNotStarted, enums.ProgressInProgress:
+			entry.Overdue = now.After(entry.EffectiveDeadline)
+		}
+		entries = append(entries, entry)
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].EffectiveDeadline.Before(entries[j].EffectiveDeadline)
+	})
+	return entries, nil
+}
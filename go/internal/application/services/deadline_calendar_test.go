@@ -0,0 +1,122 @@
+package services
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	models "github.com/Project-DSView/backend/go/internal/domain/entities"
+	"github.com/Project-DSView/backend/go/internal/domain/enums"
+	"github.com/Project-DSView/backend/go/internal/types"
+)
+
+// newDeadlineCalendarTest creates courses and exercises for the deadline calendar of
+// student-1, who is enrolled in course-1, course-2 and the archived course
+func newDeadlineCalendarTest(t *testing.T) *CourseMaterialService {
+	t.Helper()
+	db := newTestDB(t, &models.Course{}, &models.Enrollment{}, &models.CodeExercise{}, &models.PDFExercise{},
+		&models.DeadlineExtension{}, &models.StudentProgress{})
+	course := func(id string, status enums.CourseStatus) *models.Course {
+		return &models.Course{CourseID: id, Name: "Course " + id, CreatedBy: "teacher-1", EnrollKey: "key-" + id, Status: status}
+	}
+	createRows(t, db,
+		course("course-1", enums.CourseStatusActive),
+		course("course-2", enums.CourseStatusActive),
+		course("course-archived", enums.CourseStatusArchived),
+		course("course-other", enums.CourseStatusActive),
+		&models.Enrollment{CourseID: "course-1", UserID: "student-1", Role: enums.EnrollmentRoleStudent},
+		&models.Enrollment{CourseID: "course-2", UserID: "student-1", Role: enums.EnrollmentRoleStudent},
+		&models.Enrollment{CourseID: "course-archived", UserID: "student-1", Role: enums.EnrollmentRoleStudent},
+	)
+
+	now := time.Now()
+	in := func(d time.Duration) *string {
+		deadline := now.Add(d).Format(time.RFC3339)
+		return &deadline
+	}
+	closesAt := now.Add(24 * time.Hour)
+	points := 10
+	base := func(id, courseID string) models.MaterialBase {
+		return models.MaterialBase{MaterialID: id, CourseID: courseID, Title: id, CreatedBy: "teacher-1"}
+	}
+	createRows(t, db,
+		&models.CodeExercise{MaterialBase: base("code-late", "course-1"), TotalPoints: &points, Deadline: in(-48 * time.Hour)},
+		&models.PDFExercise{MaterialBase: base("pdf-done", "course-1"), TotalPoints: &points, Deadline: in(-24 * time.Hour),
+			FileURL: "sheet.pdf", FileName: "sheet.pdf"},
+		&models.CodeExercise{MaterialBase: base("code-extended", "course-1"), TotalPoints: &points, Deadline: in(-time.Hour)},
+		&models.CodeExercise{MaterialBase: base("code-window", "course-1"), TotalPoints: &points, ClosesAt: &closesAt},
+		&models.CodeExercise{MaterialBase: base("code-hidden", "course-1"), TotalPoints: &points, Deadline: in(24 * time.Hour)},
+		&models.CodeExercise{MaterialBase: base("code-no-deadline", "course-1"), TotalPoints: &points},
+		&models.CodeExercise{MaterialBase: base("code-algo", "course-2"), TotalPoints: &points, Deadline: in(72 * time.Hour)},
+		&models.CodeExercise{MaterialBase: base("code-archived", "course-archived"), TotalPoints: &points, Deadline: in(24 * time.Hour)},
+		&models.CodeExercise{MaterialBase: base("code-other", "course-other"), TotalPoints: &points, Deadline: in(24 * time.Hour)},
+		&models.DeadlineExtension{MaterialID: "code-extended", UserID: "student-1", Deadline: now.Add(48 * time.Hour),
+			Reason: "illness", GrantedBy: "teacher-1"},
+		&models.StudentProgress{UserID: "student-1", MaterialID: "pdf-done", Status: enums.ProgressCompleted},
+	)
+	if err := db.Model(&models.CodeExercise{}).Where("material_id = ?", "code-hidden").Update("is_public", false).Error; err != nil {
+		t.Fatalf("hide code-hidden: %v", err)
+	}
+	return NewCourseMaterialService(db, nil)
+}
+
+func calendarIDs(entries []types.DeadlineCalendarEntry) []string {
+	ids := make([]string, len(entries))
+	for i, entry := range entries {
+		ids[i] = entry.MaterialID
+	}
+	return ids
+}
+
+func TestGetDeadlineCalendar(t *testing.T) {
+	svc := newDeadlineCalendarTest(t)
+
+	entries, err := svc.GetDeadlineCalendar("course-1", "student-1")
+	if err != nil {
+		t.Fatalf("GetDeadlineCalendar() error = %v", err)
+	}
+	if got, want := calendarIDs(entries), []string{"code-late", "pdf-done", "code-window", "code-extended"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("calendar = %v, want %v", got, want)
+	}
+
+	byID := make(map[string]types.DeadlineCalendarEntry, len(entries))
+	for _, entry := range entries {
+		byID[entry.MaterialID] = entry
+	}
+	if e := byID["code-late"]; !e.Overdue || e.ProgressStatus != string(enums.ProgressNotStarted) || e.CourseName != "Course course-1" {
+		t.Errorf("code-late = %+v, want overdue and not started in Course course-1", e)
+	}
+	if e := byID["pdf-done"]; e.Overdue || e.Type != string(enums.MaterialTypePDFExercise) || e.ProgressStatus != string(enums.ProgressCompleted) {
+		t.Errorf("pdf-done = %+v, want a completed PDF exercise that is not overdue", e)
+	}
+	if e := byID["code-extended"]; e.Overdue || e.ExtendedDeadline == nil || !e.EffectiveDeadline.Equal(*e.ExtendedDeadline) ||
+		e.Deadline == nil || !e.Deadline.Before(e.EffectiveDeadline) {
+		t.Errorf("code-extended = %+v, want the extension as its effective deadline", e)
+	}
+	if e := byID["code-window"]; e.Deadline != nil || e.ClosesAt == nil || !e.EffectiveDeadline.Equal(*e.ClosesAt) {
+		t.Errorf("code-window = %+v, want the window's close as its effective deadline", e)
+	}
+
+	if _, err := svc.GetDeadlineCalendar("course-other", "student-1"); !errors.Is(err, ErrNotCourseMember) {
+		t.Errorf("calendar of a course not joined: error = %v, want %v", err, ErrNotCourseMember)
+	}
+}
+
+func TestGetMyDeadlineCalendar(t *testing.T) {
+	svc := newDeadlineCalendarTest(t)
+
+	entries, err := svc.GetMyDeadlineCalendar("student-1")
+	if err != nil {
+		t.Fatalf("GetMyDeadlineCalendar() error = %v", err)
+	}
+	want := []string{"code-late", "pdf-done", "code-window", "code-extended", "code-algo"}
+	if got := calendarIDs(entries); !reflect.DeepEqual(got, want) {
+		t.Errorf("calendar = %v, want %v", got, want)
+	}
+
+	entries, err = svc.GetMyDeadlineCalendar("student-without-courses")
+	if err != nil || entries == nil || len(entries) != 0 {
+		t.Errorf("calendar without courses = %#v, %v; want an empty list", entries, err)
+	}
+}
@@ -10,6 +10,21 @@ import (
 	"gorm.io/gorm"
 )
 
+// ErrSubmissionNotAllowed is wrapped with the reason from CanSubmitMaterial when a
+// submission is blocked (deadline passed, material hidden, ...)
+var ErrSubmissionNotAllowed = errors.New("cannot submit")
+
+// CanSubmitMaterial reasons that callers tell apart
+const (
+	// deadlinePassedMessage is returned for a past deadline. With a submission window
+	// that has a close time, the submission is still allowed and marked late
+	deadlinePassedMessage = "Submission deadline has passed"
+	// submissionNotOpenMessage is returned before the exercise's submission window opens
+	submissionNotOpenMessage = "Submissions are not open yet"
+	// submissionClosedMessage is returned after the exercise's submission window closes
+	submissionClosedMessage = "Submission window has closed"
+)
+
 type DeadlineCheckerService struct {
 	db *gorm.DB
 }
@@ -133,8 +148,9 @@ func (d *DeadlineCheckerService) CanSubmitMaterial(userID, materialID string) (b
 		return false, "", err
 	}
 
-	// Get the actual exercise to check deadline and is_public
+	// Get the actual exercise to check deadline, submission window and is_public
 	var deadline *string
+	var opensAt, closesAt *time.Time
 	var isPublic bool
 
 	if material.Type == enums.MaterialTypeCodeExercise {
@@ -146,6 +162,7 @@ func (d *DeadlineCheckerService) CanSubmitMaterial(userID, materialID string) (b
 			return false, "", err
 		}
 		deadline = codeExercise.Deadline
+		opensAt, closesAt = codeExercise.OpensAt, codeExercise.ClosesAt
 		isPublic = codeExercise.IsPublic
 	} else if material.Type == enums.MaterialTypePDFExercise {
 		var pdfExercise models.PDFExercise
@@ -156,6 +173,7 @@ func (d *DeadlineCheckerService) CanSubmitMaterial(userID, materialID string) (b
 			return false, "", err
 		}
 		deadline = pdfExercise.Deadline
+		opensAt, closesAt = pdfExercise.OpensAt, pdfExercise.ClosesAt
 		isPublic = pdfExercise.IsPublic
 	}
 
@@ -164,6 +182,14 @@ func (d *DeadlineCheckerService) CanSubmitMaterial(userID, materialID string) (b
 		return false, "Material is not available", nil
 	}
 
+	// The submission window is a hard limit; extensions only move the deadline
+	switch models.SubmissionWindowState(opensAt, closesAt, time.Now()) {
+	case models.SubmissionWindowNotOpen:
+		return false, submissionNotOpenMessage, nil
+	case models.SubmissionWindowClosed:
+		return false, submissionClosedMessage, nil
+	}
+
 	// Check if material has deadline
 	if deadline == nil || *deadline == "" {
 		return true, "", nil
@@ -176,8 +202,16 @@ func (d *DeadlineCheckerService) CanSubmitMaterial(userID, materialID string) (b
 	}
 
 	// Check if deadline has passed - block all submissions after deadline
+	// unless the student was granted an extension that is still running
 	if time.Now().After(deadlineTime) {
-		return false, "Submission deadline has passed", nil
+		extended, err := d.extendedDeadline(userID, materialID)
+		if err != nil {
+			return false, "", err
+		}
+		if extended == nil || time.Now().After(*extended) {
+			// Inside a window that closes later, the deadline only marks the submission late
+			return closesAt != nil, deadlinePassedMessage, nil
+		}
 	}
 
 	return true, "", nil
@@ -0,0 +1,158 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	models "github.com/Project-DSView/backend/go/internal/domain/entities"
+	"github.com/Project-DSView/backend/go/internal/types"
+	"github.com/Project-DSView/backend/go/pkg/authz"
+	"github.com/Project-DSView/backend/go/pkg/logger"
+	"gorm.io/gorm"
+)
+
+// ErrUsersNotEnrolled is wrapped with the offending user IDs when a bulk extension
+// names users who are not enrolled in the exercise's course
+var ErrUsersNotEnrolled = errors.New("users are not enrolled in the course")
+
+// maxBulkExtensions bounds how many students one bulk grant covers
+const maxBulkExtensions = 200
+
+// ErrTooManyExtensions is returned when a bulk extension names more than maxBulkExtensions students
+var ErrTooManyExtensions = fmt.Errorf("at most %d students can be extended at once", maxBulkExtensions)
+
+// BulkGrantExtension moves the exercise's deadline to newDeadline for each of the users.
+// All users must be enrolled in the course; the grant is all-or-nothing, and the result
+// reports per user whether an extension was granted or an earlier one replaced.
+func (d *DeadlineCheckerService) BulkGrantExtension(materialID string, userIDs []string, newDeadline time.Time, reason, actorID string) ([]types.ExtensionResult, error) {
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return nil, errors.New("a reason is required")
+	}
+
+	seen := make(map[string]bool, len(userIDs))
+	unique := make([]string, 0, len(userIDs))
+	for _, id := range userIDs {
+		if id != "" && !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+	if len(unique) == 0 {
+		return nil, errors.New("at least one student is required")
+	}
+	if len(unique) > maxBulkExtensions {
+		return nil, ErrTooManyExtensions
+	}
+	if !newDeadline.After(time.Now()) {
+		return nil, errors.New("the new deadline must be in the future")
+	}
+
+	var material models.CourseMaterial
+	if err := d.db.First(&material, "material_id = ?", materialID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("course material not found")
+		}
+		return nil, fmt.Errorf("get material: %w", err)
+	}
+	if !material.IsExercise() {
+		return nil, errors.New("only exercises have deadlines")
+	}
+
+	canManage, err := authz.CanManageCourse(d.db, actorID, material.CourseID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get course details: %w", err)
+	}
+	if !canManage {
+		return nil, errors.New("only the course teacher can grant extensions")
+	}
+
+	var enrolled []string
+	if err := d.db.Model(&models.Enrollment{}).
+		Where("course_id = ? AND user_id IN ?", material.CourseID, unique).
+		Pluck("user_id", &enrolled).Error; err != nil {
+		return nil, fmt.Errorf("get enrollments: %w", err)
+	}
+	if len(enrolled) < len(unique) {
+		isEnrolled := make(map[string]bool, len(enrolled))
+		for _, id := range enrolled {
+			isEnrolled[id] = true
+		}
+		var missing []string
+		for _, id := range unique {
+			if !isEnrolled[id] {
+				missing = append(missing, id)
+			}
+		}
+		return nil, fmt.Errorf("%w: %s", ErrUsersNotEnrolled, strings.Join(missing, ", "))
+	}
+
+	newDeadline = newDeadline.UTC()
+	results := make([]types.ExtensionResult, 0, len(unique))
+	err = d.db.Transaction(func(tx *gorm.DB) error {
+		var existing []models.DeadlineExtension
+		if err := tx.Where("material_id = ? AND user_id IN ?", materialID, unique).
+			Find(&existing).Error; err != nil {
+			return fmt.Errorf("get existing extensions: %w", err)
+		}
+		byUser := make(map[string]*models.DeadlineExtension, len(existing))
+		for i := range existing {
+			byUser[existing[i].UserID] = &existing[i]
+		}
+
+		for _, userID := range unique {
+			result := types.ExtensionResult{UserID: userID, Status: "granted", Deadline: newDeadline}
+			if ext, ok := byUser[userID]; ok {
+				previous := ext.Deadline
+				result.Status = "updated"
+				result.PreviousDeadline = &previous
+				if err := tx.Model(ext).Updates(map[string]interface{}{
+					"deadline":   newDeadline,
+					"reason":     reason,
+					"granted_by": actorID,
+				}).Error; err != nil {
+					return fmt.Errorf("update extension for user %s: %w", userID, err)
+				}
+			} else {
+				ext := &models.DeadlineExtension{
+					MaterialID: materialID,
+					UserID:     userID,
+					Deadline:   newDeadline,
+					Reason:     reason,
+					GrantedBy:  actorID,
+				}
+				if err := tx.Create(ext).Error; err != nil {
+					return fmt.Errorf("create extension for user %s: %w", userID, err)
+				}
+			}
+			results = append(results, result)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, result := range results {
+		logger.Infof("Deadline extension %s: material %s, user %s, deadline %s, by %s, reason: %s",
+			result.Status, materialID, result.UserID, result.Deadline.Format(time.RFC3339), actorID, reason)
+	}
+
+	return results, nil
+}
+
+// extendedDeadline returns the user's extended deadline for the material, or nil when
+// they have no extension
+func (d *DeadlineCheckerService) extendedDeadline(userID, materialID string) (*time.Time, error) {
+	var ext models.DeadlineExtension
+	err := d.db.Where("material_id = ? AND user_id = ?", materialID, userID).First(&ext).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &ext.Deadline, nil
+}
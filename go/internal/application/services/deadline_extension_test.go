@@ -0,0 +1,67 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	models "github.com/Project-DSView/backend/go/internal/domain/entities"
+	"github.com/Project-DSView/backend/go/internal/domain/enums"
+)
+
+func TestBulkGrantExtension(t *testing.T) {
+	tooMany := make([]string, maxBulkExtensions+1)
+	for i := range tooMany {
+		tooMany[i] = fmt.Sprintf("student-%d", i)
+	}
+
+	tests := []struct {
+		name        string
+		userIDs     []string
+		wantErr     error
+		wantResults map[string]string // user ID -> status
+	}{
+		{name: "too many students", userIDs: tooMany, wantErr: ErrTooManyExtensions},
+		{name: "student not enrolled", userIDs: []string{"student-1", "outsider"}, wantErr: ErrUsersNotEnrolled},
+		{
+			name:        "new and existing extensions",
+			userIDs:     []string{"student-1", "student-2", "student-1"},
+			wantResults: map[string]string{"student-1": "updated", "student-2": "granted"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := newTestDB(t, &models.Course{}, &models.Enrollment{}, &models.CourseMaterial{}, &models.DeadlineExtension{})
+			createRows(t, db,
+				&models.Course{CourseID: "course-1", Name: "Data Structures", CreatedBy: "teacher-1"},
+				&models.Enrollment{CourseID: "course-1", UserID: "student-1", Role: enums.EnrollmentRoleStudent},
+				&models.Enrollment{CourseID: "course-1", UserID: "student-2", Role: enums.EnrollmentRoleStudent},
+				&models.CourseMaterial{MaterialID: "code-1", CourseID: "course-1", Type: enums.MaterialTypeCodeExercise},
+				&models.DeadlineExtension{MaterialID: "code-1", UserID: "student-1", Deadline: time.Now().Add(time.Hour), Reason: "sick", GrantedBy: "teacher-1"})
+
+			deadline := time.Now().Add(48 * time.Hour)
+			results, err := NewDeadlineCheckerService(db).BulkGrantExtension("code-1", tt.userIDs, deadline, "lab outage", "teacher-1")
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("BulkGrantExtension() error = %v, want %v", err, tt.wantErr)
+			}
+
+			if len(results) != len(tt.wantResults) {
+				t.Fatalf("got %d results, want %d", len(results), len(tt.wantResults))
+			}
+			for _, result := range results {
+				if want := tt.wantResults[result.UserID]; result.Status != want {
+					t.Errorf("%s status = %q, want %q", result.UserID, result.Status, want)
+				}
+			}
+
+			// A rejected grant saves nothing
+			var count int64
+			db.Model(&models.DeadlineExtension{}).Where("reason = ?", "lab outage").Count(&count)
+			if int(count) != len(tt.wantResults) {
+				t.Errorf("%d extensions saved with the new reason, want %d", count, len(tt.wantResults))
+			}
+		})
+	}
+}
@@ -1,14 +1,20 @@
 package services
 
 import (
+	"errors"
 	"fmt"
 
 	models "github.com/Project-DSView/backend/go/internal/domain/entities"
 	"github.com/Project-DSView/backend/go/internal/domain/enums"
+	"github.com/Project-DSView/backend/go/internal/types"
 	"github.com/Project-DSView/backend/go/pkg/logger"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+// ErrAlreadyEnrolled is returned when a user already has an enrollment in the course
+var ErrAlreadyEnrolled = errors.New("already enrolled in this course")
+
 type EnrollmentService struct {
 	db          *gorm.DB
 	userService *UserService
@@ -31,25 +37,15 @@ func (s *EnrollmentService) EnrollUser(courseID, userID, enrollKey string, role
 		return nil, fmt.Errorf("failed to verify course: %w", err)
 	}
 
-	// Check if already enrolled
-	var existingEnrollment models.Enrollment
-	err := s.db.Where("course_id = ? AND user_id = ?", courseID, userID).First(&existingEnrollment).Error
-	if err == nil {
-		return nil, fmt.Errorf("already enrolled in this course")
-	}
-	if err != gorm.ErrRecordNotFound {
-		return nil, fmt.Errorf("failed to check existing enrollment: %w", err)
-	}
-
-	// Create enrollment
+	// Create enrollment (the unique index rejects duplicates, including concurrent requests)
 	enrollment := models.Enrollment{
 		CourseID: courseID,
 		UserID:   userID,
 		Role:     role,
 	}
 
-	if err := s.db.Create(&enrollment).Error; err != nil {
-		return nil, fmt.Errorf("failed to create enrollment: %w", err)
+	if err := s.createEnrollment(s.db, &enrollment); err != nil {
+		return nil, err
 	}
 
 	//  ดึงข้อมูล user มาใส่ใน enrollment
@@ -63,13 +59,67 @@ func (s *EnrollmentService) EnrollUser(courseID, userID, enrollKey string, role
 	return &enrollment, nil
 }
 
+// createEnrollment inserts an enrollment, relying on the unique (course_id, user_id)
+// index instead of a check-then-insert so concurrent requests cannot create duplicates
+func (s *EnrollmentService) createEnrollment(db *gorm.DB, enrollment *models.Enrollment) error {
+	result := db.Clauses(clause.OnConflict{DoNothing: true}).Create(enrollment)
+	if result.Error != nil {
+		return fmt.Errorf("failed to create enrollment: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrAlreadyEnrolled
+	}
+	return nil
+}
+
 func (s *EnrollmentService) GetCourseEnrollments(courseID string) ([]models.Enrollment, error) {
 	var enrollments []models.Enrollment
 	if err := s.db.Where("course_id = ?", courseID).Order("enrolled_at ASC").Find(&enrollments).Error; err != nil {
 		return nil, fmt.Errorf("failed to get enrollments: %w", err)
 	}
 
-	// Populate user info for each enrollment
+	s.populateUserInfo(enrollments)
+
+	return enrollments, nil
+}
+
+// GetCourseEnrollmentsPaginated returns one page of a course's enrollments and the total count
+func (s *EnrollmentService) GetCourseEnrollmentsPaginated(courseID string, page, limit int) ([]models.Enrollment, int, error) {
+	var total int64
+	query := s.db.Model(&models.Enrollment{}).Where("course_id = ?", courseID)
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count enrollments: %w", err)
+	}
+
+	var enrollments []models.Enrollment
+	offset := (page - 1) * limit
+	if err := query.Order("enrolled_at ASC").Limit(limit).Offset(offset).Find(&enrollments).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to get enrollments: %w", err)
+	}
+
+	s.populateUserInfo(enrollments)
+
+	return enrollments, int(total), nil
+}
+
+// GetEnrollment fetches a single user's enrollment in a course, with user info
+func (s *EnrollmentService) GetEnrollment(courseID, userID string) (*models.Enrollment, error) {
+	var enrollment models.Enrollment
+	if err := s.db.Where("course_id = ? AND user_id = ?", courseID, userID).First(&enrollment).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("enrollment not found")
+		}
+		return nil, fmt.Errorf("failed to get enrollment: %w", err)
+	}
+
+	enrollments := []models.Enrollment{enrollment}
+	s.populateUserInfo(enrollments)
+
+	return &enrollments[0], nil
+}
+
+// populateUserInfo fills in UserInfo for each enrollment, logging users that cannot be loaded
+func (s *EnrollmentService) populateUserInfo(enrollments []models.Enrollment) {
 	for i := range enrollments {
 		if enrollments[i].UserID != "" {
 			user, err := s.userService.GetUserByID(enrollments[i].UserID)
@@ -80,8 +130,128 @@ func (s *EnrollmentService) GetCourseEnrollments(courseID string) ([]models.Enro
 			}
 		}
 	}
+}
 
-	return enrollments, nil
+// GetUserCourses lists the courses a user is enrolled in, with their role, plus the
+// courses they created (as teacher). Students also get their completion percentage.
+// Courses are loaded in a fixed number of queries regardless of how many there are.
+func (s *EnrollmentService) GetUserCourses(userID string) ([]types.UserCourse, error) {
+	var enrollments []models.Enrollment
+	if err := s.db.Preload("Course").Where("user_id = ?", userID).
+		Order("enrolled_at DESC").Find(&enrollments).Error; err != nil {
+		return nil, fmt.Errorf("failed to get enrollments: %w", err)
+	}
+
+	var created []models.Course
+	if err := s.db.Where("created_by = ?", userID).Order("created_at DESC").Find(&created).Error; err != nil {
+		return nil, fmt.Errorf("failed to get created courses: %w", err)
+	}
+
+	courses := make([]types.UserCourse, 0, len(enrollments)+len(created))
+	seen := make(map[string]bool, len(enrollments)+len(created))
+	var studentCourseIDs []string
+
+	for _, course := range created {
+		seen[course.CourseID] = true
+		courses = append(courses, userCourse(course, enums.EnrollmentRoleTeacher, true))
+	}
+	for _, enrollment := range enrollments {
+		if seen[enrollment.CourseID] || enrollment.Course.CourseID == "" {
+			continue
+		}
+		seen[enrollment.CourseID] = true
+
+		course := userCourse(enrollment.Course, enrollment.Role, false)
+		enrolledAt := enrollment.EnrolledAt
+		course.EnrolledAt = &enrolledAt
+		courses = append(courses, course)
+
+		if enrollment.Role == enums.EnrollmentRoleStudent {
+			studentCourseIDs = append(studentCourseIDs, enrollment.CourseID)
+		}
+	}
+
+	if len(studentCourseIDs) > 0 {
+		completion, err := s.completionPercentages(userID, studentCourseIDs)
+		if err != nil {
+			return nil, err
+		}
+		for i := range courses {
+			if pct, ok := completion[courses[i].CourseID]; ok {
+				courses[i].CompletionPercentage = &pct
+			}
+		}
+	}
+
+	return courses, nil
+}
+
+func userCourse(course models.Course, role enums.EnrollmentRole, isCreator bool) types.UserCourse {
+	return types.UserCourse{
+		CourseID:    course.CourseID,
+		Name:        course.Name,
+		Description: course.Description,
+		ImageURL:    course.ImageURL,
+		Status:      string(course.Status),
+		Role:        string(role),
+		IsCreator:   isCreator,
+	}
+}
+
+// completionPercentages returns, per course, the share of published exercises the user
+// has completed. Hidden exercises, e.g. drafts, do not count. Courses without published
+// exercises report 0.
+func (s *EnrollmentService) completionPercentages(userID string, courseIDs []string) (map[string]float64, error) {
+	type courseCount struct {
+		CourseID string
+		Count    int64
+	}
+
+	var totals, completed []courseCount
+	for _, table := range []string{"code_exercises", "pdf_exercises"} {
+		var tableTotals []courseCount
+		if err := s.db.Table(table).
+			Select("course_id, COUNT(*) AS count").
+			Where("course_id IN ? AND is_public = ?", courseIDs, true).
+			Group("course_id").
+			Scan(&tableTotals).Error; err != nil {
+			return nil, fmt.Errorf("failed to count exercises: %w", err)
+		}
+		totals = append(totals, tableTotals...)
+
+		var tableCompleted []courseCount
+		if err := s.db.Table("student_progress sp").
+			Select("ex.course_id, COUNT(*) AS count").
+			Joins("INNER JOIN "+table+" ex ON sp.material_id = ex.material_id").
+			Where("sp.user_id = ? AND sp.status = ? AND ex.course_id IN ? AND ex.is_public = ?",
+				userID, enums.ProgressCompleted, courseIDs, true).
+			Group("ex.course_id").
+			Scan(&tableCompleted).Error; err != nil {
+			return nil, fmt.Errorf("failed to count completed exercises: %w", err)
+		}
+		completed = append(completed, tableCompleted...)
+	}
+
+	completedByCourse := make(map[string]int64, len(completed))
+	for _, row := range completed {
+		completedByCourse[row.CourseID] += row.Count
+	}
+	totalByCourse := make(map[string]int64, len(totals))
+	for _, row := range totals {
+		totalByCourse[row.CourseID] += row.Count
+	}
+
+	percentages := make(map[string]float64, len(courseIDs))
+	for _, courseID := range courseIDs {
+		percentages[courseID] = 0
+	}
+	for courseID, total := range totalByCourse {
+		if total > 0 {
+			percentages[courseID] = float64(completedByCourse[courseID]) * 100 / float64(total)
+		}
+	}
+
+	return percentages, nil
 }
 
 func (s *EnrollmentService) UnenrollUser(courseID, userID string) error {
@@ -121,6 +291,22 @@ func (s *EnrollmentService) GetUserEnrollmentInCourse(courseID, userID string) (
 	return &enrollment, nil
 }
 
+// GetUserRole returns the user's role in a course with a single indexed lookup.
+// The bool is false when the user is not enrolled.
+func (s *EnrollmentService) GetUserRole(courseID, userID string) (enums.EnrollmentRole, bool, error) {
+	var roles []enums.EnrollmentRole
+	if err := s.db.Model(&models.Enrollment{}).
+		Where("course_id = ? AND user_id = ?", courseID, userID).
+		Limit(1).
+		Pluck("role", &roles).Error; err != nil {
+		return "", false, fmt.Errorf("failed to get enrollment role: %w", err)
+	}
+	if len(roles) == 0 {
+		return "", false, nil
+	}
+	return roles[0], true, nil
+}
+
 func (s *EnrollmentService) UpdateEnrollmentRole(courseID, userID string, newRole enums.EnrollmentRole) error {
 	result := s.db.Model(&models.Enrollment{}).
 		Where("course_id = ? AND user_id = ?", courseID, userID).
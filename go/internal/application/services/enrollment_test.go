@@ -0,0 +1,94 @@
+package services
+
+import (
+	"errors"
+	"sync"
+	"testing"
+
+	models "github.com/Project-DSView/backend/go/internal/domain/entities"
+	"github.com/Project-DSView/backend/go/internal/domain/enums"
+)
+
+func TestEnrollUserConcurrently(t *testing.T) {
+	db := newTestDB(t, &models.User{}, &models.Course{}, &models.Enrollment{})
+	course := models.Course{CourseID: "course-1", Name: "Data Structures", CreatedBy: "teacher-1", EnrollKey: "key-1"}
+	if err := db.Create(&course).Error; err != nil {
+		t.Fatalf("create course: %v", err)
+	}
+	svc := NewEnrollmentService(db, NewUserService(db))
+
+	const requests = 8
+	errs := make([]error, requests)
+	var wg sync.WaitGroup
+	start := make(chan struct{})
+	for i := range errs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			<-start
+			_, errs[i] = svc.EnrollUser("course-1", "student-1", "key-1", enums.EnrollmentRoleStudent)
+		}(i)
+	}
+	close(start)
+	wg.Wait()
+
+	enrolled := 0
+	for _, err := range errs {
+		switch {
+		case err == nil:
+			enrolled++
+		case !errors.Is(err, ErrAlreadyEnrolled):
+			t.Errorf("EnrollUser() error = %v, want nil or ErrAlreadyEnrolled", err)
+		}
+	}
+	if enrolled != 1 {
+		t.Errorf("%d of %d concurrent requests enrolled the student, want 1", enrolled, requests)
+	}
+
+	var count int64
+	db.Model(&models.Enrollment{}).Where("course_id = ? AND user_id = ?", "course-1", "student-1").Count(&count)
+	if count != 1 {
+		t.Errorf("%d enrollment rows, want 1", count)
+	}
+}
+
+// TestGetUserCoursesCompletionCountsPublishedExercises checks that hidden exercises are
+// left out of a student's completion percentage, whether completed or not
+func TestGetUserCoursesCompletionCountsPublishedExercises(t *testing.T) {
+	db := newTestDB(t, &models.User{}, &models.Course{}, &models.Enrollment{}, &models.CourseMaterial{},
+		&models.CodeExercise{}, &models.PDFExercise{}, &models.StudentProgress{})
+	points := 10
+	base := func(id string) models.MaterialBase {
+		return models.MaterialBase{MaterialID: id, CourseID: "course-1", Title: id, CreatedBy: "teacher-1"}
+	}
+	createRows(t, db,
+		&models.Course{CourseID: "course-1", Name: "Data Structures", CreatedBy: "teacher-1"},
+		&models.Enrollment{CourseID: "course-1", UserID: "student-1", Role: enums.EnrollmentRoleStudent},
+		&models.CodeExercise{MaterialBase: base("code-1"), TotalPoints: &points},
+		&models.CodeExercise{MaterialBase: base("code-hidden"), TotalPoints: &points},
+		&models.PDFExercise{MaterialBase: base("pdf-1"), TotalPoints: &points, FileURL: "pdf-1.pdf", FileName: "pdf-1.pdf"},
+		&models.PDFExercise{MaterialBase: base("pdf-hidden"), TotalPoints: &points, FileURL: "pdf-2.pdf", FileName: "pdf-2.pdf"},
+		&models.StudentProgress{UserID: "student-1", MaterialID: "code-1", Status: enums.ProgressCompleted},
+		&models.StudentProgress{UserID: "student-1", MaterialID: "code-hidden", Status: enums.ProgressCompleted},
+	)
+	for _, row := range []struct {
+		model interface{}
+		id    string
+	}{{&models.CodeExercise{}, "code-hidden"}, {&models.PDFExercise{}, "pdf-hidden"}} {
+		if err := db.Model(row.model).Where("material_id = ?", row.id).Update("is_public", false).Error; err != nil {
+			t.Fatalf("hide %s: %v", row.id, err)
+		}
+	}
+
+	courses, err := NewEnrollmentService(db, NewUserService(db)).GetUserCourses("student-1")
+	if err != nil {
+		t.Fatalf("GetUserCourses() error = %v", err)
+	}
+	if len(courses) != 1 || courses[0].CompletionPercentage == nil {
+		t.Fatalf("courses = %+v, want course-1 with a completion percentage", courses)
+	}
+	// One of the two published exercises is completed
+	if got := *courses[0].CompletionPercentage; got != 50 {
+		t.Errorf("completion percentage = %v, want 50", got)
+	}
+}
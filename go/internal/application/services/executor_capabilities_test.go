@@ -0,0 +1,32 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Project-DSView/backend/go/pkg/external"
+)
+
+// TestExecutorCapabilities checks that the reported limits are the ones runs and test
+// cases are held to, with the executor's defaults filled in
+func TestExecutorCapabilities(t *testing.T) {
+	testCases := NewTestCaseService(nil)
+	testCases.SetLimits(TestCaseLimits{MaxInputBytes: 1024, MaxOutputBytes: 2048})
+	exec := external.NewDockerExecutor(external.DockerConfig{Image: "python:3.12-slim", Timeout: 3 * time.Second, MaxContainers: 4})
+	svc := NewSubmissionService(nil, testCases, nil, nil, nil, nil, exec, nil, nil)
+
+	got := svc.ExecutorCapabilities()
+	if len(got.Languages) != 1 || got.Languages[0] != (external.Language{Name: "python", Image: "python:3.12-slim", Version: "3.12"}) {
+		t.Errorf("languages = %+v, want python 3.12 in python:3.12-slim", got.Languages)
+	}
+	if got.TimeoutSeconds != 3 || got.MaxContainers != 4 {
+		t.Errorf("timeout %vs, %d containers; want 3s, 4", got.TimeoutSeconds, got.MaxContainers)
+	}
+	// Unset limits report the executor's defaults
+	if got.Memory != "256m" || got.CPUs != "0.5" {
+		t.Errorf("memory %s, cpus %s; want the defaults 256m, 0.5", got.Memory, got.CPUs)
+	}
+	if got.MaxTestCaseInputBytes != 1024 || got.MaxTestCaseOutputBytes != 2048 {
+		t.Errorf("test case limits %d/%d bytes, want 1024/2048", got.MaxTestCaseInputBytes, got.MaxTestCaseOutputBytes)
+	}
+}
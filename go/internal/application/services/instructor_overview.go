@@ -0,0 +1,133 @@
+package services
+
+import (
+	"fmt"
+
+	models "github.com/Project-DSView/backend/go/internal/domain/entities"
+	"github.com/Project-DSView/backend/go/internal/domain/enums"
+	"github.com/Project-DSView/backend/go/internal/types"
+	"gorm.io/gorm"
+)
+
+// courseTotal is one row of a per-course COUNT or SUM
+type courseTotal struct {
+	CourseID string
+	Total    int64
+}
+
+// scanCourseTotals runs a query selecting course_id and total, grouped by course
+func scanCourseTotals(query *gorm.DB) (map[string]int64, error) {
+	var rows []courseTotal
+	if err := query.Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	totals := make(map[string]int64, len(rows))
+	for _, row := range rows {
+		totals[row.CourseID] = row.Total
+	}
+	return totals, nil
+}
+
+// GetInstructorOverview summarizes every course the user owns for a portfolio dashboard:
+// students, average exercise completion, progress waiting for review, and the size of
+// stored material and submission files. Each figure is one grouped query over all the
+// courses, so the cost does not grow with the number of courses.
+func (s *CourseService) GetInstructorOverview(userID string) (*types.InstructorOverview, error) {
+	var courses []models.Course
+	if err := s.db.Select("course_id", "name", "status").
+		Where("created_by = ?", userID).
+		Order("created_at DESC").
+		Find(&courses).Error; err != nil {
+		return nil, fmt.Errorf("get courses: %w", err)
+	}
+
+	overview := &types.InstructorOverview{Courses: []types.InstructorCourseSummary{}}
+	if len(courses) == 0 {
+		return overview, nil
+	}
+	courseIDs := make([]string, len(courses))
+	for i, course := range courses {
+		courseIDs[i] = course.CourseID
+	}
+	exerciseTypes := []string{string(enums.MaterialTypeCodeExercise), string(enums.MaterialTypePDFExercise)}
+
+	students, err := scanCourseTotals(s.db.Model(&models.Enrollment{}).
+		Select("course_id, COUNT(*) AS total").
+		Where("course_id IN ? AND role = ?", courseIDs, enums.EnrollmentRoleStudent).
+		Group("course_id"))
+	if err != nil {
+		return nil, fmt.Errorf("count students: %w", err)
+	}
+
+	exercises, err := scanCourseTotals(s.db.Model(&models.CourseMaterial{}).
+		Select("course_id, COUNT(*) AS total").
+		Where("course_id IN ? AND type IN ?", courseIDs, exerciseTypes).
+		Group("course_id"))
+	if err != nil {
+		return nil, fmt.Errorf("count exercises: %w", err)
+	}
+
+	// Only students still enrolled count towards completion
+	completed, err := scanCourseTotals(s.db.Table("student_progress sp").
+		Select("cm.course_id AS course_id, COUNT(*) AS total").
+		Joins("INNER JOIN course_materials cm ON sp.material_id = cm.material_id").
+		Joins("INNER JOIN enrollments e ON e.course_id = cm.course_id AND e.user_id = sp.user_id AND e.role = ?", enums.EnrollmentRoleStudent).
+		Where("cm.course_id IN ? AND cm.type IN ? AND sp.status = ?", courseIDs, exerciseTypes, enums.ProgressCompleted).
+		Group("cm.course_id"))
+	if err != nil {
+		return nil, fmt.Errorf("count completed exercises: %w", err)
+	}
+
+	pendingReviews, err := scanCourseTotals(s.db.Table("student_progress sp").
+		Select("cm.course_id AS course_id, COUNT(*) AS total").
+		Joins("INNER JOIN course_materials cm ON sp.material_id = cm.material_id").
+		Where("cm.course_id IN ? AND sp.status IN ?", courseIDs,
+			[]enums.ProgressStatus{enums.ProgressWaitingReview, enums.ProgressWaitingApproval}).
+		Group("cm.course_id"))
+	if err != nil {
+		return nil, fmt.Errorf("count pending reviews: %w", err)
+	}
+
+	storage := make(map[string]int64, len(courses))
+	for _, query := range []*gorm.DB{
+		s.db.Model(&models.Document{}).
+			Select("course_id, COALESCE(SUM(file_size), 0) AS total").
+			Where("course_id IN ?", courseIDs).Group("course_id"),
+		s.db.Model(&models.PDFExercise{}).
+			Select("course_id, COALESCE(SUM(file_size), 0) AS total").
+			Where("course_id IN ?", courseIDs).Group("course_id"),
+		s.db.Table("submissions sub").
+			Select("cm.course_id AS course_id, COALESCE(SUM(sub.file_size), 0) AS total").
+			Joins("INNER JOIN course_materials cm ON sub.material_id = cm.material_id").
+			Where("cm.course_id IN ?", courseIDs).Group("cm.course_id"),
+	} {
+		totals, err := scanCourseTotals(query)
+		if err != nil {
+			return nil, fmt.Errorf("sum storage usage: %w", err)
+		}
+		for courseID, bytes := range totals {
+			storage[courseID] += bytes
+		}
+	}
+
+	for _, course := range courses {
+		summary := types.InstructorCourseSummary{
+			CourseID:          course.CourseID,
+			Name:              course.Name,
+			Status:            string(course.Status),
+			StudentCount:      students[course.CourseID],
+			ExerciseCount:     exercises[course.CourseID],
+			PendingReviews:    pendingReviews[course.CourseID],
+			StorageUsageBytes: storage[course.CourseID],
+		}
+		if possible := summary.StudentCount * summary.ExerciseCount; possible > 0 {
+			summary.AverageCompletion = float64(completed[course.CourseID]) * 100 / float64(possible)
+		}
+
+		overview.Courses = append(overview.Courses, summary)
+		overview.TotalStudents += summary.StudentCount
+		overview.TotalPendingReviews += summary.PendingReviews
+		overview.TotalStorageUsageBytes += summary.StorageUsageBytes
+	}
+	return overview, nil
+}
@@ -0,0 +1,74 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	models "github.com/Project-DSView/backend/go/internal/domain/entities"
+	"github.com/Project-DSView/backend/go/internal/domain/enums"
+	"github.com/Project-DSView/backend/go/internal/types"
+)
+
+func TestGetInstructorOverview(t *testing.T) {
+	db := newTestDB(t, &models.Course{}, &models.Enrollment{}, &models.CourseMaterial{}, &models.StudentProgress{},
+		&models.Document{}, &models.PDFExercise{}, &models.Submission{})
+	points := 10
+	createRows(t, db,
+		&models.Course{CourseID: "course-a", Name: "Data Structures", CreatedBy: "teacher-1", CreatedAt: time.Now()},
+		&models.Course{CourseID: "course-b", Name: "Algorithms", CreatedBy: "teacher-1", CreatedAt: time.Now().Add(-time.Hour)},
+		&models.Course{CourseID: "course-c", Name: "Someone else's", CreatedBy: "teacher-2"},
+		&models.Enrollment{CourseID: "course-a", UserID: "student-1", Role: enums.EnrollmentRoleStudent},
+		&models.Enrollment{CourseID: "course-a", UserID: "student-2", Role: enums.EnrollmentRoleStudent},
+		&models.Enrollment{CourseID: "course-a", UserID: "ta-1", Role: enums.EnrollmentRoleTA},
+		&models.Enrollment{CourseID: "course-c", UserID: "student-1", Role: enums.EnrollmentRoleStudent},
+		&models.CourseMaterial{MaterialID: "code-1", CourseID: "course-a", Type: enums.MaterialTypeCodeExercise},
+		&models.CourseMaterial{MaterialID: "pdf-1", CourseID: "course-a", Type: enums.MaterialTypePDFExercise},
+		&models.CourseMaterial{MaterialID: "doc-1", CourseID: "course-a", Type: enums.MaterialTypeDocument},
+		&models.Document{MaterialBase: models.MaterialBase{MaterialID: "doc-1", CourseID: "course-a", Title: "Notes", CreatedBy: "teacher-1"},
+			FileURL: "notes.pdf", FileName: "notes.pdf", FileSize: 100},
+		&models.PDFExercise{MaterialBase: models.MaterialBase{MaterialID: "pdf-1", CourseID: "course-a", Title: "Sheet", CreatedBy: "teacher-1"},
+			TotalPoints: &points, FileURL: "sheet.pdf", FileName: "sheet.pdf", FileSize: 200},
+		&models.Submission{SubmissionID: "sub-1", UserID: "student-2", MaterialID: "pdf-1", FileURL: "work.pdf", FileSize: 50},
+		&models.StudentProgress{UserID: "student-1", MaterialID: "code-1", Status: enums.ProgressCompleted},
+		&models.StudentProgress{UserID: "student-1", MaterialID: "pdf-1", Status: enums.ProgressCompleted},
+		&models.StudentProgress{UserID: "student-2", MaterialID: "code-1", Status: enums.ProgressCompleted},
+		&models.StudentProgress{UserID: "student-2", MaterialID: "pdf-1", Status: enums.ProgressWaitingReview},
+		// A student who has left the course no longer counts towards completion
+		&models.StudentProgress{UserID: "student-3", MaterialID: "code-1", Status: enums.ProgressCompleted},
+	)
+
+	overview, err := NewCourseService(db, nil, nil).GetInstructorOverview("teacher-1")
+	if err != nil {
+		t.Fatalf("GetInstructorOverview() error = %v", err)
+	}
+
+	want := []types.InstructorCourseSummary{
+		{CourseID: "course-a", Name: "Data Structures", StudentCount: 2, ExerciseCount: 2,
+			AverageCompletion: 75, PendingReviews: 1, StorageUsageBytes: 350},
+		{CourseID: "course-b", Name: "Algorithms"},
+	}
+	if len(overview.Courses) != len(want) {
+		t.Fatalf("overview lists %d courses, want %d: %+v", len(overview.Courses), len(want), overview.Courses)
+	}
+	for i, got := range overview.Courses {
+		got.Status = ""
+		if got != want[i] {
+			t.Errorf("course %d = %+v, want %+v", i, got, want[i])
+		}
+	}
+	if overview.TotalStudents != 2 || overview.TotalPendingReviews != 1 || overview.TotalStorageUsageBytes != 350 {
+		t.Errorf("totals: %d students, %d pending reviews, %d bytes; want 2, 1, 350",
+			overview.TotalStudents, overview.TotalPendingReviews, overview.TotalStorageUsageBytes)
+	}
+}
+
+func TestGetInstructorOverviewWithoutCourses(t *testing.T) {
+	db := newTestDB(t, &models.Course{})
+	overview, err := NewCourseService(db, nil, nil).GetInstructorOverview("teacher-1")
+	if err != nil {
+		t.Fatalf("GetInstructorOverview() error = %v", err)
+	}
+	if overview.Courses == nil || len(overview.Courses) != 0 {
+		t.Errorf("courses = %#v, want an empty list", overview.Courses)
+	}
+}
@@ -9,6 +9,7 @@ import (
 
 	models "github.com/Project-DSView/backend/go/internal/domain/entities"
 	"github.com/Project-DSView/backend/go/internal/domain/enums"
+	"github.com/Project-DSView/backend/go/pkg/authz"
 	"gorm.io/gorm"
 )
 
@@ -47,7 +48,7 @@ func (s *InvitationService) CreateInvitation(courseID, userID string) (*models.C
 	}
 
 	// Verify user is the creator
-	if course.CreatedBy != userID {
+	if !authz.ManagesCourse(course, userID) {
 		return nil, errors.New("only course creator can create invitations")
 	}
 
@@ -97,7 +98,7 @@ func (s *InvitationService) GetCourseInvitations(courseID, userID string) ([]mod
 		return nil, errors.New("course not found")
 	}
 
-	if course.CreatedBy != userID {
+	if !authz.ManagesCourse(course, userID) {
 		return nil, errors.New("only course creator can view invitations")
 	}
 
@@ -141,24 +142,16 @@ func (s *InvitationService) EnrollViaInvitation(token, userID string) (*models.E
 		return nil, errors.New("course not found")
 	}
 
-	// Check if already enrolled
-	isEnrolled, err := s.enrollmentService.IsUserEnrolled(invitation.CourseID, userID)
-	if err != nil {
-		return nil, fmt.Errorf("failed to check enrollment: %w", err)
-	}
-	if isEnrolled {
-		return nil, errors.New("already enrolled in this course")
-	}
-
-	// Create enrollment directly without checking enroll_key (invitation bypasses this)
+	// Create enrollment directly without checking enroll_key (invitation bypasses this).
+	// Duplicates are rejected by the unique (course_id, user_id) index.
 	enrollment := models.Enrollment{
 		CourseID: invitation.CourseID,
 		UserID:   userID,
 		Role:     enums.EnrollmentRoleStudent,
 	}
 
-	if err := s.db.Create(&enrollment).Error; err != nil {
-		return nil, fmt.Errorf("failed to create enrollment: %w", err)
+	if err := s.enrollmentService.createEnrollment(s.db, &enrollment); err != nil {
+		return nil, err
 	}
 
 	// Populate user info
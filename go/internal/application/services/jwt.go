@@ -11,6 +11,8 @@ import (
 type JWTService struct {
 	secret    []byte
 	expiresIn time.Duration
+	// isSessionRevoked, when set, rejects tokens whose session has been revoked
+	isSessionRevoked func(sessionID string) bool
 }
 
 // Claims is now defined in internal/types/services.go
@@ -22,13 +24,29 @@ func NewJWTService(secret string, expiresIn time.Duration) *JWTService {
 	}
 }
 
+// SetSessionRevocationCheck installs the denylist lookup used by ValidateToken
+func (s *JWTService) SetSessionRevocationCheck(isRevoked func(sessionID string) bool) {
+	s.isSessionRevoked = isRevoked
+}
+
+// ExpiresIn returns the lifetime of issued access tokens
+func (s *JWTService) ExpiresIn() time.Duration {
+	return s.expiresIn
+}
+
 func (s *JWTService) GenerateToken(userID, email, name string, isTeacher bool) (string, error) {
+	return s.GenerateSessionToken(userID, email, name, isTeacher, "")
+}
+
+// GenerateSessionToken issues a token tied to a login session so it can be revoked with it
+func (s *JWTService) GenerateSessionToken(userID, email, name string, isTeacher bool, sessionID string) (string, error) {
 	now := time.Now()
 	claims := types.Claims{
 		UserID:    userID,
 		Email:     email,
 		Name:      name,
 		IsTeacher: isTeacher,
+		SessionID: sessionID,
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(now.Add(s.expiresIn)),
 			IssuedAt:  jwt.NewNumericDate(now),
@@ -64,6 +82,10 @@ func (s *JWTService) ValidateToken(tokenString string) (*types.Claims, error) {
 		return nil, fmt.Errorf("invalid token")
 	}
 
+	if err := s.checkSession(claims); err != nil {
+		return nil, err
+	}
+
 	return claims, nil
 }
 
@@ -91,9 +113,20 @@ func (s *JWTService) ValidateTokenAllowExpired(tokenString string) (*types.Claim
 		return nil, fmt.Errorf("token too old for refresh")
 	}
 
+	if err := s.checkSession(claims); err != nil {
+		return nil, err
+	}
+
 	return claims, nil
 }
 
+func (s *JWTService) checkSession(claims *types.Claims) error {
+	if claims.SessionID != "" && s.isSessionRevoked != nil && s.isSessionRevoked(claims.SessionID) {
+		return fmt.Errorf("session has been revoked")
+	}
+	return nil
+}
+
 // GetTokenExpiry returns the expiration time of a token without full validation
 func (s *JWTService) GetTokenExpiry(tokenString string) (*time.Time, error) {
 	token, err := jwt.ParseWithClaims(tokenString, &types.Claims{}, func(token *jwt.Token) (interface{}, error) {
@@ -0,0 +1,125 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	models "github.com/Project-DSView/backend/go/internal/domain/entities"
+	"github.com/Project-DSView/backend/go/internal/domain/enums"
+	"github.com/Project-DSView/backend/go/pkg/external"
+	"github.com/Project-DSView/backend/go/pkg/logger"
+	"gorm.io/gorm"
+)
+
+// materialTypeLabels names each material type in auto-posted announcement titles
+var materialTypeLabels = map[string]string{
+	string(enums.MaterialTypeCodeExercise): "code exercise",
+	string(enums.MaterialTypePDFExercise):  "PDF exercise",
+	string(enums.MaterialTypeDocument):     "document",
+	string(enums.MaterialTypeVideo):        "video",
+}
+
+// SetPublishNotifier sets the webhook told about auto-announced materials; nil disables it
+func (s *CourseMaterialService) SetPublishNotifier(notifier *external.WebhookNotifier) {
+	s.publishNotifier = notifier
+}
+
+// announcePublished posts an announcement linking to a newly published material when
+// the course has announce_new_materials enabled. The material is already saved, so
+// failures are logged rather than returned.
+func (s *CourseMaterialService) announcePublished(m models.Material) {
+	if !m.GetIsPublic() {
+		return
+	}
+	label, ok := materialTypeLabels[m.GetMaterialType()]
+	if !ok {
+		return // announcements are not announced
+	}
+
+	var course models.Course
+	if err := s.db.Select("course_id", "name", "settings").Where("course_id = ?", m.GetCourseID()).First(&course).Error; err != nil {
+		logger.Warnf("Failed to load settings of course %s to announce material %s: %v", m.GetCourseID(), m.GetMaterialID(), err)
+		return
+	}
+	if !course.Settings.IsAnnounceNewMaterials() {
+		return
+	}
+
+	materialID := m.GetMaterialID()
+	announcement := &models.Announcement{
+		MaterialBase: models.MaterialBase{
+			CourseID:    m.GetCourseID(),
+			Title:       fmt.Sprintf("New %s: %s", label, m.GetTitle()),
+			Description: m.GetDescription(),
+			Week:        m.GetWeek(),
+			IsPublic:    true,
+			CreatedBy:   m.GetCreatedBy(),
+		},
+		Content:          fmt.Sprintf("A new %s \"%s\" has been published for week %d.", label, m.GetTitle(), m.GetWeek()),
+		LinkedMaterialID: &materialID,
+	}
+	if err := s.CreateAnnouncement(announcement); err != nil {
+		logger.Warnf("Failed to announce material %s: %v", materialID, err)
+		return
+	}
+
+	if s.publishNotifier != nil {
+		go s.notifyPublished(course, m, announcement.MaterialID)
+	}
+}
+
+// notifyPublished sends the material.published webhook for an auto-announced material
+func (s *CourseMaterialService) notifyPublished(course models.Course, m models.Material, announcementID string) {
+	event := external.WebhookEvent{
+		Event: external.WebhookEventMaterialPublished,
+		Data: map[string]interface{}{
+			"material": map[string]interface{}{
+				"material_id": m.GetMaterialID(),
+				"type":        m.GetMaterialType(),
+				"title":       m.GetTitle(),
+				"week":        m.GetWeek(),
+			},
+			"course": map[string]interface{}{
+				"course_id": course.CourseID,
+				"name":      course.Name,
+			},
+			"announcement_id": announcementID,
+		},
+		OccurredAt: time.Now(),
+	}
+
+	if err := s.publishNotifier.Notify(context.Background(), event); err != nil {
+		logger.Errorf("Failed to send material.published webhook for material %s: %v", m.GetMaterialID(), err)
+	}
+}
+
+// loadPublishableMaterial loads the specific record behind a course material so its
+// visibility can be checked around an update; announcements are skipped
+func (s *CourseMaterialService) loadPublishableMaterial(material *models.CourseMaterial) (models.Material, error) {
+	if material.ReferenceID == nil || material.ReferenceType == nil {
+		return nil, errors.New("course material has no reference")
+	}
+
+	var m models.Material
+	switch *material.ReferenceType {
+	case "code_exercise":
+		m = &models.CodeExercise{}
+	case "pdf_exercise":
+		m = &models.PDFExercise{}
+	case "document":
+		m = &models.Document{}
+	case "video":
+		m = &models.Video{}
+	default:
+		return nil, nil
+	}
+	if err := s.db.Where("material_id = ?", *material.ReferenceID).First(m).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return m, nil
+}
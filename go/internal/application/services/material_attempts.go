@@ -0,0 +1,113 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	models "github.com/Project-DSView/backend/go/internal/domain/entities"
+	"github.com/Project-DSView/backend/go/internal/domain/enums"
+	"github.com/Project-DSView/backend/go/internal/types"
+	"gorm.io/gorm"
+)
+
+// Reasons reported in AttemptStatus.BlockedReason
+const (
+	attemptBlockedInProgress = "in_progress"
+	attemptBlockedLocked     = "locked_after_approval"
+	attemptBlockedLimit      = "attempt_limit_reached"
+)
+
+// GetAttemptStatus derives a student's attempt state for an exercise from their
+// submissions, progress and queue jobs, applying the same rules as submitting and
+// retrying. It returns nil for materials that are not exercises.
+func (s *CourseMaterialService) GetAttemptStatus(userID, materialID string) (*types.AttemptStatus, error) {
+	var exercises []struct {
+		CourseID          string
+		LockAfterApproval bool
+		MaxAttempts       int
+	}
+	if err := s.db.Raw(`SELECT course_id, lock_after_approval, max_attempts FROM code_exercises WHERE material_id = ?
+		UNION ALL SELECT course_id, lock_after_approval, max_attempts FROM pdf_exercises WHERE material_id = ?`,
+		materialID, materialID).Scan(&exercises).Error; err != nil {
+		return nil, fmt.Errorf("get exercise: %w", err)
+	}
+	if len(exercises) == 0 {
+		return nil, nil
+	}
+	exercise := exercises[0]
+
+	status := &types.AttemptStatus{CanSubmit: true}
+
+	// Attempts are the count claimAttempt keeps on the progress row, which is what the limit applies to
+	var attempts []int
+	if err := s.db.Model(&models.StudentProgress{}).
+		Where("user_id = ? AND material_id = ?", userID, materialID).
+		Pluck("attempt_count", &attempts).Error; err != nil {
+		return nil, fmt.Errorf("get attempt count: %w", err)
+	}
+	if len(attempts) > 0 {
+		status.AttemptsUsed = attempts[0]
+	}
+	if exercise.MaxAttempts > 0 {
+		remaining := max(exercise.MaxAttempts-status.AttemptsUsed, 0)
+		status.RemainingAttempts = &remaining
+		if remaining == 0 {
+			status.CanSubmit = false
+			status.BlockedReason = attemptBlockedLimit
+		}
+	}
+
+	if err := checkResubmissionAllowed(s.db, userID, materialID, exercise.LockAfterApproval); err != nil {
+		if !errors.Is(err, ErrResubmissionLocked) {
+			return nil, err
+		}
+		if status.CanSubmit {
+			status.CanSubmit = false
+			status.BlockedReason = attemptBlockedLocked
+		}
+	}
+
+	var job models.QueueJob
+	err := s.db.Where("user_id = ? AND material_id = ?", userID, materialID).
+		Order("created_at DESC").
+		First(&job).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return status, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get latest queue job: %w", err)
+	}
+
+	retryWindow := models.CourseSettings{}.GetRetryWindow()
+	var course models.Course
+	if err := s.db.Select("course_id", "settings").Where("course_id = ?", exercise.CourseID).First(&course).Error; err == nil {
+		retryWindow = course.Settings.GetRetryWindow()
+	}
+
+	// Mirrors CanRetryQueueJob, which measures from the submission when there is one
+	since := job.CreatedAt
+	if job.SubmissionID != nil {
+		var submission models.Submission
+		if err := s.db.Select("submitted_at").Where("submission_id = ?", *job.SubmissionID).Take(&submission).Error; err == nil {
+			since = submission.SubmittedAt
+		}
+	}
+	if wait := time.Until(since.Add(retryWindow)); wait > 0 {
+		status.RetryAfterSeconds = int64(math.Ceil(wait.Seconds()))
+	} else {
+		status.CanRetry = true
+	}
+
+	// A code execution still queued or running blocks resubmission until the retry window
+	// passes, as in checkNoActiveSubmission
+	active := job.Type == enums.QueueTypeCodeExecution &&
+		(job.Status == enums.QueueStatusPending || job.Status == enums.QueueStatusProcessing) &&
+		time.Since(job.CreatedAt) < retryWindow
+	if active && status.CanSubmit {
+		status.CanSubmit = false
+		status.BlockedReason = attemptBlockedInProgress
+	}
+	return status, nil
+}
@@ -0,0 +1,75 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+
+	models "github.com/Project-DSView/backend/go/internal/domain/entities"
+	"gorm.io/gorm"
+)
+
+// ErrMaterialHasStudentWork is returned by DeleteCourseMaterial, unless forced, for a
+// material students have submitted to or made progress on
+var ErrMaterialHasStudentWork = errors.New("course material has student submissions or progress")
+
+// countMaterialStudentWork counts the submissions and progress records of a material
+func countMaterialStudentWork(db *gorm.DB, materialID string) (submissions, progress int64, err error) {
+	if err := db.Model(&models.Submission{}).Where("material_id = ?", materialID).Count(&submissions).Error; err != nil {
+		return 0, 0, fmt.Errorf("count submissions: %w", err)
+	}
+	if err := db.Model(&models.StudentProgress{}).Where("material_id = ?", materialID).Count(&progress).Error; err != nil {
+		return 0, 0, fmt.Errorf("count progress: %w", err)
+	}
+	return submissions, progress, nil
+}
+
+// deleteMaterialStudentWork deletes everything students have on a material: submissions
+// and their results, progress and its verification logs, drafts, deadline extensions and
+// queue jobs. The course scores of the affected students are recomputed without the
+// material. It returns the storage URLs of the deleted submission files, for the caller
+// to remove once the transaction has committed.
+func deleteMaterialStudentWork(tx *gorm.DB, material *models.CourseMaterial) ([]string, error) {
+	materialID := material.MaterialID
+
+	var fileURLs []string
+	if err := tx.Model(&models.Submission{}).
+		Where("material_id = ? AND file_url <> ''", materialID).
+		Pluck("file_url", &fileURLs).Error; err != nil {
+		return nil, fmt.Errorf("get submission files: %w", err)
+	}
+	var userIDs []string
+	if err := tx.Model(&models.StudentProgress{}).
+		Where("material_id = ?", materialID).
+		Pluck("user_id", &userIDs).Error; err != nil {
+		return nil, fmt.Errorf("get students with progress: %w", err)
+	}
+
+	submissionIDs := tx.Model(&models.Submission{}).Select("submission_id").Where("material_id = ?", materialID)
+	if err := tx.Where("submission_id IN (?)", submissionIDs).Delete(&models.SubmissionResult{}).Error; err != nil {
+		return nil, fmt.Errorf("delete submission results: %w", err)
+	}
+	if err := tx.Where("material_id = ?", materialID).Delete(&models.Submission{}).Error; err != nil {
+		return nil, fmt.Errorf("delete submissions: %w", err)
+	}
+
+	progressIDs := tx.Model(&models.StudentProgress{}).Select("progress_id").Where("material_id = ?", materialID)
+	if err := tx.Where("progress_id IN (?)", progressIDs).Delete(&models.VerificationLog{}).Error; err != nil {
+		return nil, fmt.Errorf("delete verification logs: %w", err)
+	}
+	if err := tx.Where("material_id = ?", materialID).Delete(&models.StudentProgress{}).Error; err != nil {
+		return nil, fmt.Errorf("delete progress: %w", err)
+	}
+
+	for _, related := range []interface{}{&models.ExerciseDraft{}, &models.DeadlineExtension{}, &models.QueueJob{}} {
+		if err := tx.Where("material_id = ?", materialID).Delete(related).Error; err != nil {
+			return nil, fmt.Errorf("delete %T: %w", related, err)
+		}
+	}
+
+	for _, userID := range userIDs {
+		if err := recomputeCourseScore(tx, userID, material.CourseID); err != nil {
+			return nil, err
+		}
+	}
+	return fileURLs, nil
+}
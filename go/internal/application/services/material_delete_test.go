@@ -0,0 +1,167 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"testing"
+
+	models "github.com/Project-DSView/backend/go/internal/domain/entities"
+	"github.com/Project-DSView/backend/go/internal/domain/enums"
+	"github.com/Project-DSView/backend/go/pkg/storage"
+)
+
+// deletedFiles records the files deleted from storage; other storage calls panic
+type deletedFiles struct {
+	storage.StorageService
+	urls []string
+}
+
+func (s *deletedFiles) DeleteFile(_ context.Context, url string) error {
+	s.urls = append(s.urls, url)
+	return nil
+}
+
+func TestDeleteCourseMaterial(t *testing.T) {
+	tests := []struct {
+		name string
+		// withWork gives the material two students' submissions and progress
+		withWork bool
+		userID   string
+		force    bool
+		wantErr  string // substring of the error; empty for success
+		// wantLeft counts the material's rows left afterwards, by table
+		wantLeft map[string]int64
+		// wantScores are the course totals afterwards, by student
+		wantScores map[string]int
+		wantFiles  []string
+	}{
+		{
+			name:       "material with student work is refused without force",
+			withWork:   true,
+			userID:     "teacher-1",
+			wantErr:    "(2 submissions, 2 progress records)",
+			wantLeft:   map[string]int64{"materials": 1, "submissions": 2, "results": 1, "progress": 2},
+			wantScores: map[string]int{"student-1": 15, "student-2": 8},
+		},
+		{
+			name:       "forced delete removes student work and recomputes totals",
+			withWork:   true,
+			userID:     "teacher-1",
+			force:      true,
+			wantLeft:   map[string]int64{"materials": 0, "submissions": 0, "results": 0, "progress": 0},
+			wantScores: map[string]int{"student-1": 5, "student-2": 0},
+			wantFiles:  []string{"materials/sheet.pdf", "submissions/s1.pdf", "submissions/s2.pdf"},
+		},
+		{
+			name:      "material without student work needs no force",
+			userID:    "teacher-1",
+			wantLeft:  map[string]int64{"materials": 0},
+			wantFiles: []string{"materials/sheet.pdf"},
+		},
+		{
+			name:       "only the creator can delete",
+			withWork:   true,
+			userID:     "teacher-2",
+			force:      true,
+			wantErr:    "only the creator",
+			wantLeft:   map[string]int64{"materials": 1, "submissions": 2, "results": 1, "progress": 2},
+			wantScores: map[string]int{"student-1": 15, "student-2": 8},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := newTestDB(t, &models.CourseMaterial{}, &models.PDFExercise{}, &models.Submission{},
+				&models.SubmissionResult{}, &models.StudentProgress{}, &models.VerificationLog{},
+				&models.ExerciseDraft{}, &models.DeadlineExtension{}, &models.QueueJob{}, &models.StudentCourseScore{})
+
+			exercise := models.PDFExercise{
+				MaterialBase: models.MaterialBase{CourseID: "course-1", Title: "Sheet", CreatedBy: "teacher-1"},
+				TotalPoints:  new(int),
+				FileURL:      "materials/sheet.pdf",
+				FileName:     "sheet.pdf",
+			}
+			if err := db.Create(&exercise).Error; err != nil {
+				t.Fatalf("create exercise: %v", err)
+			}
+			refType := string(enums.MaterialTypePDFExercise)
+			materialID := exercise.MaterialID
+			rows := []interface{}{
+				&models.CourseMaterial{MaterialID: materialID, CourseID: "course-1", Type: enums.MaterialTypePDFExercise,
+					ReferenceID: &materialID, ReferenceType: &refType},
+				// Another material in the course, whose score student-1 keeps
+				&models.CourseMaterial{MaterialID: "other-material", CourseID: "course-1", Type: enums.MaterialTypeDocument},
+				&models.StudentProgress{UserID: "student-1", MaterialID: "other-material", Status: enums.ProgressCompleted, Score: 5},
+			}
+			if tt.withWork {
+				rows = append(rows,
+					&models.Submission{SubmissionID: "sub-1", UserID: "student-1", MaterialID: materialID, FileURL: "submissions/s1.pdf"},
+					&models.Submission{SubmissionID: "sub-2", UserID: "student-2", MaterialID: materialID, FileURL: "submissions/s2.pdf"},
+					&models.SubmissionResult{SubmissionID: "sub-1", TestCaseID: "tc-1", Status: "passed"},
+					&models.StudentProgress{UserID: "student-1", MaterialID: materialID, Status: enums.ProgressCompleted, Score: 10},
+					&models.StudentProgress{UserID: "student-2", MaterialID: materialID, Status: enums.ProgressCompleted, Score: 8},
+				)
+			}
+			for _, row := range rows {
+				if err := db.Create(row).Error; err != nil {
+					t.Fatalf("create %T: %v", row, err)
+				}
+			}
+			for _, userID := range []string{"student-1", "student-2"} {
+				if err := recomputeCourseScore(db, userID, "course-1"); err != nil {
+					t.Fatalf("recompute score: %v", err)
+				}
+			}
+
+			files := &deletedFiles{}
+			err := NewCourseMaterialService(db, files).DeleteCourseMaterial(materialID, tt.userID, tt.force)
+
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("DeleteCourseMaterial() error = %v, want it to contain %q", err, tt.wantErr)
+				}
+				if tt.withWork && tt.userID == "teacher-1" && !errors.Is(err, ErrMaterialHasStudentWork) {
+					t.Errorf("DeleteCourseMaterial() error = %v, want ErrMaterialHasStudentWork", err)
+				}
+			} else if err != nil {
+				t.Fatalf("DeleteCourseMaterial() error = %v", err)
+			}
+
+			for table, want := range tt.wantLeft {
+				query := db.Model(&models.SubmissionResult{})
+				switch table {
+				case "materials":
+					query = db.Model(&models.CourseMaterial{}).Where("material_id = ?", materialID)
+				case "submissions":
+					query = db.Model(&models.Submission{}).Where("material_id = ?", materialID)
+				case "progress":
+					query = db.Model(&models.StudentProgress{}).Where("material_id = ?", materialID)
+				}
+				var count int64
+				if err := query.Count(&count).Error; err != nil {
+					t.Fatalf("count %s: %v", table, err)
+				}
+				if count != want {
+					t.Errorf("%d %s left, want %d", count, table, want)
+				}
+			}
+
+			for userID, wantScore := range tt.wantScores {
+				var score models.StudentCourseScore
+				if err := db.First(&score, "user_id = ? AND course_id = ?", userID, "course-1").Error; err != nil {
+					t.Fatalf("get course score of %s: %v", userID, err)
+				}
+				if score.TotalScore != wantScore {
+					t.Errorf("course score of %s = %d, want %d", userID, score.TotalScore, wantScore)
+				}
+			}
+
+			sort.Strings(files.urls)
+			if strings.Join(files.urls, ",") != strings.Join(tt.wantFiles, ",") {
+				t.Errorf("deleted files %v, want %v", files.urls, tt.wantFiles)
+			}
+		})
+	}
+}
@@ -0,0 +1,118 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	models "github.com/Project-DSView/backend/go/internal/domain/entities"
+	"github.com/Project-DSView/backend/go/internal/domain/enums"
+	"github.com/Project-DSView/backend/go/internal/types"
+	"github.com/Project-DSView/backend/go/pkg/authz"
+	"github.com/Project-DSView/backend/go/pkg/logger"
+	"gorm.io/gorm"
+)
+
+// materialAccessDedupWindow is how long repeat accesses of the same kind by the same
+// student, e.g. reloading the page, count as one
+const materialAccessDedupWindow = 5 * time.Minute
+
+// Errors returned by GetMaterialEngagement
+var (
+	ErrEngagementNotTracked = errors.New("engagement is only tracked for documents and videos")
+	ErrEngagementForbidden  = errors.New("only the course teacher can view engagement")
+)
+
+// RecordMaterialAccess records that the user viewed or downloaded a material. It returns
+// at once and writes in the background, so it never slows down or fails the request; only
+// students' accesses of documents and videos are kept.
+func (s *CourseMaterialService) RecordMaterialAccess(userID, materialID string, kind enums.MaterialAccessKind) {
+	accessedAt := time.Now()
+	go func() {
+		if err := s.recordMaterialAccess(userID, materialID, kind, accessedAt); err != nil {
+			logger.Warnf("Failed to record %s of material %s by %s: %v", kind, materialID, userID, err)
+		}
+	}()
+}
+
+func (s *CourseMaterialService) recordMaterialAccess(userID, materialID string, kind enums.MaterialAccessKind, accessedAt time.Time) error {
+	var material models.CourseMaterial
+	if err := s.db.Select("material_id", "course_id", "type").Where("material_id = ?", materialID).Take(&material).Error; err != nil {
+		return fmt.Errorf("get material: %w", err)
+	}
+	if !tracksEngagement(material.Type) {
+		return nil
+	}
+
+	// The teacher and TAs checking a material are not engagement
+	canReview, err := authz.CanReviewInCourse(s.db, userID, material.CourseID)
+	if err != nil {
+		return err
+	}
+	if canReview {
+		return nil
+	}
+
+	var recent int64
+	if err := s.db.Model(&models.MaterialAccess{}).
+		Where("material_id = ? AND user_id = ? AND kind = ? AND accessed_at > ?",
+			materialID, userID, kind, accessedAt.Add(-materialAccessDedupWindow)).
+		Count(&recent).Error; err != nil {
+		return fmt.Errorf("check recent access: %w", err)
+	}
+	if recent > 0 {
+		return nil
+	}
+
+	access := &models.MaterialAccess{
+		MaterialID: materialID,
+		UserID:     userID,
+		Kind:       kind,
+		AccessedAt: accessedAt,
+	}
+	if err := s.db.Create(access).Error; err != nil {
+		return fmt.Errorf("create access: %w", err)
+	}
+	return nil
+}
+
+// GetMaterialEngagement reports how many students viewed or downloaded a document or
+// video material and how often. Only the course teacher may see it.
+func (s *CourseMaterialService) GetMaterialEngagement(materialID, actorID string) (*types.MaterialEngagement, error) {
+	var material models.CourseMaterial
+	if err := s.db.Select("material_id", "course_id", "type").Where("material_id = ?", materialID).Take(&material).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("course material not found")
+		}
+		return nil, fmt.Errorf("get material: %w", err)
+	}
+	if !tracksEngagement(material.Type) {
+		return nil, ErrEngagementNotTracked
+	}
+
+	canManage, err := authz.CanManageCourse(s.db, actorID, material.CourseID)
+	if err != nil {
+		return nil, err
+	}
+	if !canManage {
+		return nil, ErrEngagementForbidden
+	}
+
+	engagement := &types.MaterialEngagement{MaterialID: materialID}
+	if err := s.db.Model(&models.MaterialAccess{}).
+		Where("material_id = ?", materialID).
+		Select(`COUNT(DISTINCT user_id) AS unique_viewers,
+			COUNT(*) FILTER (WHERE kind = ?) AS total_views,
+			COUNT(*) FILTER (WHERE kind = ?) AS total_downloads,
+			MAX(accessed_at) AS last_accessed_at`,
+			enums.MaterialAccessView, enums.MaterialAccessDownload).
+		Scan(engagement).Error; err != nil {
+		return nil, fmt.Errorf("count accesses: %w", err)
+	}
+	return engagement, nil
+}
+
+// tracksEngagement reports whether accesses of the material type are recorded
+func tracksEngagement(materialType enums.MaterialType) bool {
+	return materialType == enums.MaterialTypeDocument || materialType == enums.MaterialTypeVideo
+}
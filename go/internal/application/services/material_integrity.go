@@ -0,0 +1,137 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	models "github.com/Project-DSView/backend/go/internal/domain/entities"
+	"github.com/Project-DSView/backend/go/internal/domain/enums"
+	"github.com/Project-DSView/backend/go/internal/types"
+	"github.com/Project-DSView/backend/go/pkg/logger"
+	materialpkg "github.com/Project-DSView/backend/go/pkg/material"
+	"gorm.io/gorm"
+)
+
+// Kinds of MaterialIntegrityIssue
+const (
+	integrityDanglingReference = "dangling_reference"
+	integrityInvalidReference  = "invalid_reference"
+	integrityOrphanedRecord    = "orphaned_record"
+)
+
+// integrityCheckBatchSize is how many course_materials rows are verified per query
+const integrityCheckBatchSize = 200
+
+// specificMaterialTables are the tables a course_materials reference can point into
+var specificMaterialTables = []struct {
+	materialType enums.MaterialType
+	table        string
+}{
+	{enums.MaterialTypeVideo, "videos"},
+	{enums.MaterialTypeDocument, "documents"},
+	{enums.MaterialTypeCodeExercise, "code_exercises"},
+	{enums.MaterialTypePDFExercise, "pdf_exercises"},
+	{enums.MaterialTypeAnnouncement, "announcements"},
+}
+
+// CheckMaterialIntegrity looks for course_materials rows whose reference is broken and for
+// specific material records no course_materials row references, as left behind by a
+// failed create or by deleting a material (which removes only its course_materials row).
+// With repair, dangling rows and orphaned records are deleted; references of the wrong
+// type or course are only reported, since which side is wrong needs a person to decide.
+// Files in storage are not touched.
+func (s *CourseMaterialService) CheckMaterialIntegrity(ctx context.Context, repair bool) (*types.MaterialIntegrityReport, error) {
+	report := &types.MaterialIntegrityReport{Repair: repair, Issues: []types.MaterialIntegrityIssue{}}
+
+	var batch []models.CourseMaterial
+	result := s.db.WithContext(ctx).FindInBatches(&batch, integrityCheckBatchSize, func(tx *gorm.DB, _ int) error {
+		for i := range batch {
+			material := &batch[i]
+			report.CheckedMaterials++
+
+			err := materialpkg.VerifyReference(s.db.WithContext(ctx), material)
+			if err == nil {
+				continue
+			}
+			issue := types.MaterialIntegrityIssue{
+				Kind:       integrityInvalidReference,
+				Table:      models.CourseMaterial{}.TableName(),
+				MaterialID: material.MaterialID,
+				CourseID:   material.CourseID,
+				Detail:     err.Error(),
+			}
+			if errors.Is(err, materialpkg.ErrReferenceMissing) || errors.Is(err, materialpkg.ErrReferenceNotFound) {
+				issue.Kind = integrityDanglingReference
+				issue.Repairable = true
+			}
+			report.Issues = append(report.Issues, issue)
+		}
+		return ctx.Err()
+	})
+	if result.Error != nil {
+		return nil, fmt.Errorf("check course materials: %w", result.Error)
+	}
+
+	for _, specific := range specificMaterialTables {
+		var orphans []struct {
+			MaterialID string
+			CourseID   string
+		}
+		if err := s.db.WithContext(ctx).Table(specific.table).
+			Select("material_id, course_id").
+			Where(`NOT EXISTS (SELECT 1 FROM course_materials AS cm
+				WHERE cm.reference_type = ? AND cm.reference_id = `+specific.table+`.material_id)`, string(specific.materialType)).
+			Order("material_id").
+			Scan(&orphans).Error; err != nil {
+			return nil, fmt.Errorf("check %s: %w", specific.table, err)
+		}
+		for _, orphan := range orphans {
+			report.Issues = append(report.Issues, types.MaterialIntegrityIssue{
+				Kind:       integrityOrphanedRecord,
+				Table:      specific.table,
+				MaterialID: orphan.MaterialID,
+				CourseID:   orphan.CourseID,
+				Detail:     "no course material references this " + string(specific.materialType),
+				Repairable: true,
+			})
+		}
+	}
+
+	if repair {
+		for i := range report.Issues {
+			issue := &report.Issues[i]
+			if !issue.Repairable {
+				continue
+			}
+			// Table names come from specificMaterialTables or the model, never from input
+			err := s.db.WithContext(ctx).Exec("DELETE FROM "+issue.Table+" WHERE material_id = ?", issue.MaterialID).Error
+			if err != nil {
+				issue.RepairError = err.Error()
+				continue
+			}
+			issue.Repaired = true
+		}
+	}
+
+	return report, nil
+}
+
+// RunMaterialIntegrityCheck is the report-only scheduled form of CheckMaterialIntegrity,
+// logging a warning when issues are found
+func (s *CourseMaterialService) RunMaterialIntegrityCheck(ctx context.Context) error {
+	report, err := s.CheckMaterialIntegrity(ctx, false)
+	if err != nil {
+		return err
+	}
+
+	counts := make(map[string]int)
+	for _, issue := range report.Issues {
+		counts[issue.Kind]++
+	}
+	if len(report.Issues) > 0 {
+		logger.Warnf("%d course material integrity issues found: %v (see GET /api/admin/materials/integrity)",
+			len(report.Issues), counts)
+	}
+	return nil
+}
@@ -0,0 +1,190 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	models "github.com/Project-DSView/backend/go/internal/domain/entities"
+	"github.com/Project-DSView/backend/go/internal/domain/enums"
+	"github.com/Project-DSView/backend/go/internal/types"
+	"gorm.io/gorm"
+)
+
+// Prerequisites sequence exercises: a code or PDF exercise lists other exercises of its
+// course that a student must complete (progress status completed) before submitting to
+// it. Prerequisites that have since been deleted no longer block.
+
+var (
+	// ErrPrerequisitesNotMet is returned when a student submits to an exercise before completing its prerequisites
+	ErrPrerequisitesNotMet = errors.New("complete the prerequisite exercises first")
+	// ErrInvalidPrerequisites is returned by ValidatePrerequisites
+	ErrInvalidPrerequisites = errors.New("invalid prerequisites")
+)
+
+// exercisePrerequisites returns the prerequisites of an exercise; none for other materials
+func exercisePrerequisites(db *gorm.DB, materialID string) ([]string, error) {
+	var code models.CodeExercise
+	err := db.Select("material_id", "prerequisites").Take(&code, "material_id = ?", materialID).Error
+	if err == nil {
+		return code.Prerequisites, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("get code exercise: %w", err)
+	}
+
+	var pdf models.PDFExercise
+	err = db.Select("material_id", "prerequisites").Take(&pdf, "material_id = ?", materialID).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get PDF exercise: %w", err)
+	}
+	return pdf.Prerequisites, nil
+}
+
+// prerequisiteExercises returns the exercises among materialIDs, in materialIDs order
+func prerequisiteExercises(db *gorm.DB, materialIDs []string) ([]types.MaterialRef, map[string]string, error) {
+	var rows []struct {
+		MaterialID string
+		Title      string
+		CourseID   string
+	}
+	if err := db.Raw(`SELECT material_id, title, course_id FROM code_exercises WHERE material_id IN ?
+		UNION ALL SELECT material_id, title, course_id FROM pdf_exercises WHERE material_id IN ?`,
+		materialIDs, materialIDs).Scan(&rows).Error; err != nil {
+		return nil, nil, fmt.Errorf("get prerequisite exercises: %w", err)
+	}
+
+	titles := make(map[string]string, len(rows))
+	courses := make(map[string]string, len(rows))
+	for _, row := range rows {
+		titles[row.MaterialID] = row.Title
+		courses[row.MaterialID] = row.CourseID
+	}
+
+	exercises := make([]types.MaterialRef, 0, len(rows))
+	for _, id := range materialIDs {
+		if title, ok := titles[id]; ok {
+			exercises = append(exercises, types.MaterialRef{MaterialID: id, Title: title})
+		}
+	}
+	return exercises, courses, nil
+}
+
+// prerequisiteStatus reports which prerequisites of an exercise the student has not completed
+func prerequisiteStatus(db *gorm.DB, userID, materialID string) (*types.PrerequisiteStatus, error) {
+	status := &types.PrerequisiteStatus{Missing: []types.MaterialRef{}}
+
+	prerequisites, err := exercisePrerequisites(db, materialID)
+	if err != nil || len(prerequisites) == 0 {
+		return status, err
+	}
+
+	exercises, _, err := prerequisiteExercises(db, prerequisites)
+	if err != nil || len(exercises) == 0 {
+		return status, err
+	}
+
+	var completed []string
+	if err := db.Model(&models.StudentProgress{}).
+		Where("user_id = ? AND material_id IN ? AND status = ?", userID, prerequisites, enums.ProgressCompleted).
+		Pluck("material_id", &completed).Error; err != nil {
+		return nil, fmt.Errorf("check progress: %w", err)
+	}
+	done := make(map[string]bool, len(completed))
+	for _, id := range completed {
+		done[id] = true
+	}
+
+	for _, exercise := range exercises {
+		if !done[exercise.MaterialID] {
+			status.Missing = append(status.Missing, exercise)
+		}
+	}
+	status.Locked = len(status.Missing) > 0
+	return status, nil
+}
+
+// checkPrerequisitesMet rejects a submission while the student has prerequisites of the
+// exercise left to complete, naming them in the error
+func checkPrerequisitesMet(db *gorm.DB, userID, materialID string) error {
+	status, err := prerequisiteStatus(db, userID, materialID)
+	if err != nil {
+		return err
+	}
+	if !status.Locked {
+		return nil
+	}
+
+	titles := make([]string, len(status.Missing))
+	for i, missing := range status.Missing {
+		titles[i] = fmt.Sprintf("%q", missing.Title)
+	}
+	return fmt.Errorf("%w: %s", ErrPrerequisitesNotMet, strings.Join(titles, ", "))
+}
+
+// GetPrerequisiteStatus reports whether the exercise is still locked for the student and
+// which prerequisites are missing
+func (s *CourseMaterialService) GetPrerequisiteStatus(userID, materialID string) (*types.PrerequisiteStatus, error) {
+	return prerequisiteStatus(s.db, userID, materialID)
+}
+
+// ValidatePrerequisites cleans up the prerequisites of an exercise (materialID is empty
+// for a new one): duplicates and blanks are dropped, and every prerequisite must be
+// another exercise of the course without making the prerequisites circular.
+func (s *CourseMaterialService) ValidatePrerequisites(courseID, materialID string, prerequisites []string) (types.StringList, error) {
+	cleaned := types.StringList{}
+	seen := make(map[string]bool, len(prerequisites))
+	for _, id := range prerequisites {
+		id = strings.TrimSpace(id)
+		if id == "" || seen[id] {
+			continue
+		}
+		if id == materialID {
+			return nil, fmt.Errorf("%w: an exercise cannot be its own prerequisite", ErrInvalidPrerequisites)
+		}
+		seen[id] = true
+		cleaned = append(cleaned, id)
+	}
+	if len(cleaned) == 0 {
+		return cleaned, nil
+	}
+
+	_, courses, err := prerequisiteExercises(s.db, cleaned)
+	if err != nil {
+		return nil, err
+	}
+	for _, id := range cleaned {
+		if courses[id] != courseID {
+			return nil, fmt.Errorf("%w: %s is not an exercise of this course", ErrInvalidPrerequisites, id)
+		}
+	}
+
+	// A new exercise cannot be anyone's prerequisite yet, so it cannot close a cycle
+	if materialID == "" {
+		return cleaned, nil
+	}
+
+	visited := make(map[string]bool)
+	pending := append([]string(nil), cleaned...)
+	for len(pending) > 0 {
+		id := pending[len(pending)-1]
+		pending = pending[:len(pending)-1]
+		if id == materialID {
+			return nil, fmt.Errorf("%w: the prerequisites would be circular", ErrInvalidPrerequisites)
+		}
+		if visited[id] {
+			continue
+		}
+		visited[id] = true
+
+		next, err := exercisePrerequisites(s.db, id)
+		if err != nil {
+			return nil, err
+		}
+		pending = append(pending, next...)
+	}
+	return cleaned, nil
+}
@@ -0,0 +1,124 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	models "github.com/Project-DSView/backend/go/internal/domain/entities"
+	"github.com/Project-DSView/backend/go/internal/types"
+	"github.com/Project-DSView/backend/go/pkg/logger"
+	"gorm.io/gorm"
+)
+
+// ErrNotMaterialCreator is returned when a bulk change includes a material the user did not create
+var ErrNotMaterialCreator = errors.New("only the creator can change this course material")
+
+// BulkSetVisibility shows or hides several materials at once, e.g. a week's worth at the
+// start of a lab. Every material must exist and have been created by the user; otherwise
+// nothing changes. Materials that become public are announced as if published one by one.
+func (s *CourseMaterialService) BulkSetVisibility(materialIDs []string, isPublic bool, userID string) (*types.BulkVisibilityResult, error) {
+	seen := make(map[string]bool, len(materialIDs))
+	ids := make([]string, 0, len(materialIDs))
+	for _, id := range materialIDs {
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+
+	var courseMaterials []models.CourseMaterial
+	if err := s.db.Where("material_id IN ?", ids).Find(&courseMaterials).Error; err != nil {
+		return nil, fmt.Errorf("get course materials: %w", err)
+	}
+	byID := make(map[string]*models.CourseMaterial, len(courseMaterials))
+	for i := range courseMaterials {
+		byID[courseMaterials[i].MaterialID] = &courseMaterials[i]
+	}
+
+	result := &types.BulkVisibilityResult{IsPublic: isPublic, Updated: []string{}, Unchanged: []string{}}
+	var missing []string
+	var published []*models.CourseMaterial
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		for _, id := range ids {
+			material, ok := byID[id]
+			if !ok {
+				missing = append(missing, id)
+				continue
+			}
+			specific, err := loadSpecificMaterial(tx, material)
+			if err != nil {
+				return fmt.Errorf("get material %s: %w", id, err)
+			}
+			if specific == nil {
+				missing = append(missing, id)
+				continue
+			}
+			if specific.GetCreatedBy() != userID {
+				return fmt.Errorf("%w: %s", ErrNotMaterialCreator, id)
+			}
+			if specific.GetIsPublic() == isPublic {
+				result.Unchanged = append(result.Unchanged, id)
+				continue
+			}
+
+			if err := tx.Model(specific).Where("material_id = ?", *material.ReferenceID).
+				Update("is_public", isPublic).Error; err != nil {
+				return fmt.Errorf("update material %s: %w", id, err)
+			}
+			result.Updated = append(result.Updated, id)
+			if isPublic {
+				published = append(published, material)
+			}
+		}
+		if len(missing) > 0 {
+			return fmt.Errorf("%w: %s", ErrMaterialNotFound, strings.Join(missing, ", "))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, material := range published {
+		if m, err := s.loadPublishableMaterial(material); err != nil {
+			logger.Warnf("Failed to reload published material %s: %v", material.MaterialID, err)
+		} else if m != nil {
+			s.announcePublished(m)
+		}
+	}
+
+	logger.Infof("User %s set is_public=%t on %d materials (%d already so)", userID, isPublic, len(result.Updated), len(result.Unchanged))
+	return result, nil
+}
+
+// loadSpecificMaterial loads the specific record behind a course material of any type,
+// or nil when it is missing
+func loadSpecificMaterial(db *gorm.DB, material *models.CourseMaterial) (models.Material, error) {
+	if material.ReferenceID == nil || material.ReferenceType == nil {
+		return nil, nil
+	}
+
+	var m models.Material
+	switch *material.ReferenceType {
+	case "code_exercise":
+		m = &models.CodeExercise{}
+	case "pdf_exercise":
+		m = &models.PDFExercise{}
+	case "document":
+		m = &models.Document{}
+	case "video":
+		m = &models.Video{}
+	case "announcement":
+		m = &models.Announcement{}
+	default:
+		return nil, nil
+	}
+	if err := db.Where("material_id = ?", *material.ReferenceID).First(m).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return m, nil
+}
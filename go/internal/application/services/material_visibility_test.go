@@ -0,0 +1,106 @@
+package services
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	models "github.com/Project-DSView/backend/go/internal/domain/entities"
+	"github.com/Project-DSView/backend/go/internal/domain/enums"
+)
+
+func TestBulkSetVisibility(t *testing.T) {
+	tests := []struct {
+		name     string
+		ids      []string
+		isPublic bool
+		wantErr  error
+		// wantUpdated and wantUnchanged are the result lists of a successful change
+		wantUpdated   []string
+		wantUnchanged []string
+		// wantPublic is which materials are public afterwards
+		wantPublic map[string]bool
+		// wantAnnounced is how many materials were announced on publishing
+		wantAnnounced int64
+	}{
+		{
+			name:          "publish",
+			ids:           []string{"code-hidden", "doc-public", "code-hidden"},
+			isPublic:      true,
+			wantUpdated:   []string{"code-hidden"},
+			wantUnchanged: []string{"doc-public"},
+			wantPublic:    map[string]bool{"code-hidden": true, "doc-public": true, "doc-other": true},
+			wantAnnounced: 1,
+		},
+		{
+			name:          "hide",
+			ids:           []string{"doc-public", "code-hidden"},
+			wantUpdated:   []string{"doc-public"},
+			wantUnchanged: []string{"code-hidden"},
+			wantPublic:    map[string]bool{"code-hidden": false, "doc-public": false, "doc-other": true},
+		},
+		{
+			name:       "another teacher's material",
+			ids:        []string{"doc-public", "doc-other"},
+			wantErr:    ErrNotMaterialCreator,
+			wantPublic: map[string]bool{"code-hidden": false, "doc-public": true, "doc-other": true},
+		},
+		{
+			name:       "unknown material",
+			ids:        []string{"code-hidden", "not-a-material"},
+			isPublic:   true,
+			wantErr:    ErrMaterialNotFound,
+			wantPublic: map[string]bool{"code-hidden": false, "doc-public": true, "doc-other": true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := newTestDB(t, &models.Course{}, &models.CourseMaterial{}, &models.CodeExercise{}, &models.Document{}, &models.Announcement{})
+			announce := true
+			createRows(t, db, &models.Course{CourseID: "source", Name: "Data Structures", CreatedBy: "teacher-1",
+				Settings: models.CourseSettings{AnnounceNewMaterials: &announce}})
+			base := func(id, createdBy string) models.MaterialBase {
+				return models.MaterialBase{MaterialID: id, CourseID: "source", Title: id, CreatedBy: createdBy}
+			}
+			points := 10
+			createMaterial(t, db, enums.MaterialTypeCodeExercise, &models.CodeExercise{MaterialBase: base("code-hidden", "teacher-1"), TotalPoints: &points})
+			createMaterial(t, db, enums.MaterialTypeDocument, &models.Document{MaterialBase: base("doc-public", "teacher-1")})
+			createMaterial(t, db, enums.MaterialTypeDocument, &models.Document{MaterialBase: base("doc-other", "teacher-2")})
+			if err := db.Model(&models.CodeExercise{}).Where("material_id = ?", "code-hidden").Update("is_public", false).Error; err != nil {
+				t.Fatalf("hide code-hidden: %v", err)
+			}
+
+			result, err := NewCourseMaterialService(db, nil).BulkSetVisibility(tt.ids, tt.isPublic, "teacher-1")
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("BulkSetVisibility() error = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil {
+				if !reflect.DeepEqual(result.Updated, tt.wantUpdated) || !reflect.DeepEqual(result.Unchanged, tt.wantUnchanged) {
+					t.Errorf("updated %v, unchanged %v; want %v, %v", result.Updated, result.Unchanged, tt.wantUpdated, tt.wantUnchanged)
+				}
+			}
+
+			got := make(map[string]bool)
+			var code []models.CodeExercise
+			var docs []models.Document
+			db.Find(&code)
+			db.Find(&docs)
+			for _, m := range code {
+				got[m.MaterialID] = m.IsPublic
+			}
+			for _, m := range docs {
+				got[m.MaterialID] = m.IsPublic
+			}
+			if !reflect.DeepEqual(got, tt.wantPublic) {
+				t.Errorf("is_public = %v, want %v", got, tt.wantPublic)
+			}
+
+			var announced int64
+			db.Model(&models.Announcement{}).Where("linked_material_id IS NOT NULL").Count(&announced)
+			if announced != tt.wantAnnounced {
+				t.Errorf("%d materials announced, want %d", announced, tt.wantAnnounced)
+			}
+		})
+	}
+}
@@ -1,7 +1,9 @@
 package services
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"strings"
@@ -11,14 +13,23 @@ import (
 	"github.com/Project-DSView/backend/go/internal/domain/enums"
 	"github.com/Project-DSView/backend/go/pkg/logger"
 	"github.com/Project-DSView/backend/go/pkg/storage"
+	"github.com/Project-DSView/backend/go/pkg/validation"
 	"gorm.io/gorm"
 )
 
+// ErrPDFTooManyPages is returned when a submitted PDF is longer than the exercise allows
+var ErrPDFTooManyPages = errors.New("PDF has too many pages")
+
+// ErrScoreOutOfRange is returned when an approval score is outside 0..the exercise's total points
+var ErrScoreOutOfRange = errors.New("score out of range")
+
 // PDFExerciseSubmissionService handles PDF exercise submissions
 type PDFExerciseSubmissionService struct {
-	db              *gorm.DB
-	deadlineService *DeadlineCheckerService
-	storageService  storage.StorageService
+	db                *gorm.DB
+	deadlineService   *DeadlineCheckerService
+	storageService    storage.StorageService
+	completionService *CourseCompletionService // Optional: emits course.completed after approvals
+	maxPDFPages       int                      // Default page limit; 0 = unlimited
 }
 
 func NewPDFExerciseSubmissionService(db *gorm.DB, deadlineService *DeadlineCheckerService, storageService storage.StorageService) *PDFExerciseSubmissionService {
@@ -29,62 +40,37 @@ func NewPDFExerciseSubmissionService(db *gorm.DB, deadlineService *DeadlineCheck
 	}
 }
 
-// CleanupOldSubmissions deletes submissions where the material deadline has passed
-func (s *PDFExerciseSubmissionService) CleanupOldSubmissions() error {
-	now := time.Now().Format(time.RFC3339)
-
-	// Find submissions where material deadline has passed
-	// Join with course_materials to check deadline
-	var oldSubmissions []models.Submission
-	if err := s.db.Table("submissions s").
-		Joins("INNER JOIN course_materials cm ON s.material_id = cm.material_id").
-		Where("cm.deadline IS NOT NULL AND cm.deadline != '' AND cm.deadline <= ?", now).
-		Select("s.*").
-		Find(&oldSubmissions).Error; err != nil {
-		return fmt.Errorf("failed to find old submissions: %w", err)
-	}
-
-	deletedCount := 0
-	for _, submission := range oldSubmissions {
-		// Delete file from MinIO if exists
-		if submission.FileURL != "" {
-			if err := s.storageService.DeleteFile(context.Background(), submission.FileURL); err != nil {
-				// Log error but continue
-				logger.Warnf("Failed to delete file from MinIO for submission %s: %v", submission.SubmissionID, err)
-			}
-		}
-
-		// Delete submission results
-		if err := s.db.Where("submission_id = ?", submission.SubmissionID).Delete(&models.SubmissionResult{}).Error; err != nil {
-			logger.Warnf("Failed to delete submission results for %s: %v", submission.SubmissionID, err)
-			continue
-		}
+// SetCourseCompletionService sets the service used to detect course completion after approvals
+func (s *PDFExerciseSubmissionService) SetCourseCompletionService(completionService *CourseCompletionService) {
+	s.completionService = completionService
+}
 
-		// Delete submission
-		if err := s.db.Delete(&submission).Error; err != nil {
-			logger.Warnf("Failed to delete submission %s: %v", submission.SubmissionID, err)
-			continue
-		}
+// SetMaxPDFPages sets the default page limit for exercises that do not set their own
+func (s *PDFExerciseSubmissionService) SetMaxPDFPages(maxPages int) {
+	s.maxPDFPages = maxPages
+}
 
-		deletedCount++
+// checkPDFPageLimit rejects a PDF with more pages than the exercise's max_pages
+// (or defaultMax when the exercise has none). Files whose pages cannot be
+// counted are let through.
+func checkPDFPageLimit(db *gorm.DB, materialID string, defaultMax int, content []byte) error {
+	maxPages := defaultMax
+	var exercise models.PDFExercise
+	if err := db.Select("max_pages").Where("material_id = ?", materialID).Take(&exercise).Error; err == nil && exercise.MaxPages != nil {
+		maxPages = *exercise.MaxPages
 	}
-
-	if deletedCount > 0 {
-		logger.Infof("Cleaned up %d old submissions (past deadline)", deletedCount)
+	if maxPages <= 0 {
+		return nil
 	}
 
+	if pages := validation.CountPDFPages(content); pages > maxPages {
+		return fmt.Errorf("%w: the file has %d pages, the maximum for this exercise is %d", ErrPDFTooManyPages, pages, maxPages)
+	}
 	return nil
 }
 
 // GetUserSubmissionForMaterial gets a user's submission for a specific material
 func (s *PDFExerciseSubmissionService) GetUserSubmissionForMaterial(userID, materialID string) (*models.Submission, error) {
-	// Cleanup old submissions before querying (async, don't wait for result)
-	go func() {
-		if err := s.CleanupOldSubmissions(); err != nil {
-			logger.Warnf("Failed to cleanup old submissions: %v", err)
-		}
-	}()
-
 	var submission models.Submission
 	err := s.db.Where("user_id = ? AND material_id = ?", userID, materialID).First(&submission).Error
 	if err != nil {
@@ -96,6 +82,12 @@ func (s *PDFExerciseSubmissionService) GetUserSubmissionForMaterial(userID, mate
 	return &submission, nil
 }
 
+// CheckSubmissionType verifies that the material exists and is of materialType;
+// see SubmissionService.CheckSubmissionType
+func (s *PDFExerciseSubmissionService) CheckSubmissionType(materialID string, materialType enums.MaterialType) error {
+	return checkSubmissionType(s.db, materialID, materialType)
+}
+
 // SubmitPDFExercise submits a PDF file for an exercise
 func (s *PDFExerciseSubmissionService) SubmitPDFExercise(
 	userID, materialID string,
@@ -104,6 +96,10 @@ func (s *PDFExerciseSubmissionService) SubmitPDFExercise(
 	fileSize int64,
 	mimeType string,
 ) (*models.Submission, error) {
+	if err := checkSubmissionType(s.db, materialID, enums.MaterialTypePDFExercise); err != nil {
+		return nil, err
+	}
+
 	// Get course material to validate
 	var material models.CourseMaterial
 	if err := s.db.Preload("Course").Where("material_id = ? AND type = ?", materialID, enums.MaterialTypePDFExercise).First(&material).Error; err != nil {
@@ -112,6 +108,10 @@ func (s *PDFExerciseSubmissionService) SubmitPDFExercise(
 		}
 		return nil, fmt.Errorf("failed to get material: %w", err)
 	}
+	if material.Course.Status == enums.CourseStatusArchived {
+		return nil, ErrCourseArchived
+	}
+	fileName = s.storageService.SanitizeFilename(fileName)
 
 	// Check if user is enrolled in the course
 	var enrollment models.Enrollment
@@ -128,9 +128,32 @@ func (s *PDFExerciseSubmissionService) SubmitPDFExercise(
 		return nil, fmt.Errorf("check submission eligibility: %w", err)
 	}
 	if !canSubmit {
-		return nil, fmt.Errorf("cannot submit: %s", message)
+		return nil, fmt.Errorf("%w: %s", ErrSubmissionNotAllowed, message)
 	}
 
+	if err := checkPDFResubmissionAllowed(s.db, userID, materialID); err != nil {
+		return nil, err
+	}
+
+	if err := checkPrerequisitesMet(s.db, userID, materialID); err != nil {
+		return nil, err
+	}
+
+	content, err := io.ReadAll(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read PDF file: %w", err)
+	}
+	if err := checkPDFPageLimit(s.db, materialID, s.maxPDFPages, content); err != nil {
+		return nil, err
+	}
+	file = bytes.NewReader(content)
+
+	if err := claimPDFAttempt(s.db, userID, materialID); err != nil {
+		return nil, err
+	}
+	stored := false
+	defer refundUnstoredAttempt(s.db, userID, materialID, &stored)
+
 	// Cancel pending/processing queue jobs and reset progress status for resubmission
 	// Keep old submissions for history
 	err = s.db.Transaction(func(tx *gorm.DB) error {
@@ -192,11 +215,14 @@ func (s *PDFExerciseSubmissionService) SubmitPDFExercise(
 		MimeType:    mimeType,
 		Status:      enums.SubmissionPending, // PDF exercises need manual review
 		SubmittedAt: time.Now(),
+
+		IsLateSubmission: message == deadlinePassedMessage,
 	}
 
 	if err := s.db.Create(submission).Error; err != nil {
 		return nil, fmt.Errorf("failed to create submission: %w", err)
 	}
+	stored = true
 
 	// Create or update student progress
 	var progress models.StudentProgress
@@ -246,7 +272,8 @@ func (s *PDFExerciseSubmissionService) ApprovePDFSubmissionWithFile(
 	feedbackFileSize int64,
 	feedbackFileMimeType string,
 ) error {
-	return s.db.Transaction(func(tx *gorm.DB) error {
+	var studentID, courseID string
+	err := s.db.Transaction(func(tx *gorm.DB) error {
 		// Get submission
 		var submission models.Submission
 		if err := tx.Where("submission_id = ?", submissionID).First(&submission).Error; err != nil {
@@ -258,6 +285,12 @@ func (s *PDFExerciseSubmissionService) ApprovePDFSubmissionWithFile(
 		if err := tx.Where("material_id = ?", submission.MaterialID).First(&material).Error; err != nil {
 			return fmt.Errorf("material not found: %w", err)
 		}
+		studentID, courseID = submission.UserID, material.CourseID
+
+		// The exercise's own total is the maximum, whatever the reviewer's client assumes
+		if err := checkApprovalScore(tx, submission.MaterialID, score); err != nil {
+			return err
+		}
 
 		// Upload feedback file if provided
 		feedbackFileURL := ""
@@ -332,12 +365,37 @@ func (s *PDFExerciseSubmissionService) ApprovePDFSubmissionWithFile(
 		}
 
 		// Update course score
-		if err := s.updateCourseScore(tx, submission.UserID, material.CourseID); err != nil {
+		if err := recomputeCourseScore(tx, submission.UserID, material.CourseID); err != nil {
 			return fmt.Errorf("failed to update course score: %w", err)
 		}
 
 		return nil
 	})
+	if err != nil {
+		return err
+	}
+
+	// Only check completion once the approval is committed
+	if s.completionService != nil {
+		s.completionService.CheckCourseCompletion(studentID, courseID)
+	}
+	return nil
+}
+
+// checkApprovalScore rejects a score below 0 or above the PDF exercise's total points
+func checkApprovalScore(db *gorm.DB, materialID string, score int) error {
+	var exercise models.PDFExercise
+	if err := db.Select("total_points").Where("material_id = ?", materialID).Take(&exercise).Error; err != nil {
+		return fmt.Errorf("PDF exercise not found: %w", err)
+	}
+	totalPoints := 0
+	if exercise.TotalPoints != nil {
+		totalPoints = *exercise.TotalPoints
+	}
+	if score < 0 || score > totalPoints {
+		return fmt.Errorf("%w: score must be between 0 and %d", ErrScoreOutOfRange, totalPoints)
+	}
+	return nil
 }
 
 // RejectPDFSubmission rejects a PDF submission
@@ -390,13 +448,6 @@ func (s *PDFExerciseSubmissionService) RejectPDFSubmission(
 
 // GetPDFSubmissions gets PDF submissions for a material (for teachers/TAs)
 func (s *PDFExerciseSubmissionService) GetPDFSubmissions(materialID string) ([]models.Submission, error) {
-	// Cleanup old submissions before querying (async, don't wait for result)
-	go func() {
-		if err := s.CleanupOldSubmissions(); err != nil {
-			logger.Warnf("Failed to cleanup old submissions: %v", err)
-		}
-	}()
-
 	var submissions []models.Submission
 	if err := s.db.Where("material_id = ?", materialID).
 		Order("submitted_at DESC").
@@ -415,13 +466,6 @@ type CoursePDFSubmission struct {
 
 // GetPDFSubmissionsByCourse gets all PDF submissions for a course (for teachers/TAs)
 func (s *PDFExerciseSubmissionService) GetPDFSubmissionsByCourse(courseID string) ([]CoursePDFSubmission, error) {
-	// Cleanup old submissions before querying (async, don't wait for result)
-	go func() {
-		if err := s.CleanupOldSubmissions(); err != nil {
-			logger.Warnf("Failed to cleanup old submissions: %v", err)
-		}
-	}()
-
 	// Get all PDF exercise materials for this course
 	var pdfExercises []models.PDFExercise
 	if err := s.db.Where("course_id = ?", courseID).Find(&pdfExercises).Error; err != nil {
@@ -483,13 +527,6 @@ func (s *PDFExerciseSubmissionService) GetPDFSubmissionsByCourse(courseID string
 
 // GetPDFSubmission gets a specific PDF submission
 func (s *PDFExerciseSubmissionService) GetPDFSubmission(submissionID string) (*models.Submission, error) {
-	// Cleanup old submissions before querying (async, don't wait for result)
-	go func() {
-		if err := s.CleanupOldSubmissions(); err != nil {
-			logger.Warnf("Failed to cleanup old submissions: %v", err)
-		}
-	}()
-
 	var submission models.Submission
 	if err := s.db.Where("submission_id = ?", submissionID).First(&submission).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
@@ -640,48 +677,3 @@ func (s *PDFExerciseSubmissionService) CancelPDFSubmission(submissionID, userID
 		return nil
 	})
 }
-
-// updateCourseScore updates course score for a student
-func (s *PDFExerciseSubmissionService) updateCourseScore(tx *gorm.DB, userID, courseID string) error {
-	// Get all progress for the student in this course
-	var progressList []models.StudentProgress
-	if err := tx.Table("student_progress sp").
-		Joins("INNER JOIN course_materials cm ON sp.material_id = cm.material_id").
-		Where("sp.user_id = ? AND cm.course_id = ?", userID, courseID).
-		Find(&progressList).Error; err != nil {
-		return err
-	}
-
-	// Calculate total score
-	totalScore := 0
-	for _, progress := range progressList {
-		totalScore += progress.Score
-	}
-
-	// Update or create course score
-	var courseScore models.StudentCourseScore
-	err := tx.Where("user_id = ? AND course_id = ?", userID, courseID).First(&courseScore).Error
-	if err == gorm.ErrRecordNotFound {
-		// Create new course score
-		courseScore = models.StudentCourseScore{
-			UserID:      userID,
-			CourseID:    courseID,
-			TotalScore:  totalScore,
-			LastUpdated: time.Now(),
-		}
-		if err := tx.Create(&courseScore).Error; err != nil {
-			return err
-		}
-	} else if err != nil {
-		return err
-	} else {
-		// Update existing course score
-		courseScore.TotalScore = totalScore
-		courseScore.LastUpdated = time.Now()
-		if err := tx.Where("user_id = ? AND course_id = ?", userID, courseID).Updates(&courseScore).Error; err != nil {
-			return err
-		}
-	}
-
-	return nil
-}
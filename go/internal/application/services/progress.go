@@ -1,18 +1,21 @@
 package services
 
 import (
+	"errors"
 	"fmt"
 	"time"
 
 	models "github.com/Project-DSView/backend/go/internal/domain/entities"
 	"github.com/Project-DSView/backend/go/internal/domain/enums"
+	"github.com/Project-DSView/backend/go/internal/types"
 	"gorm.io/gorm"
 )
 
 type ProgressService struct {
-	db            *gorm.DB
-	userService   *UserService
-	courseService *CourseService
+	db                *gorm.DB
+	userService       *UserService
+	courseService     *CourseService
+	completionService *CourseCompletionService // Optional: emits course.completed after approvals
 }
 
 func NewProgressService(db *gorm.DB, userSvc *UserService, courseSvc *CourseService) *ProgressService {
@@ -23,6 +26,11 @@ func NewProgressService(db *gorm.DB, userSvc *UserService, courseSvc *CourseServ
 	}
 }
 
+// SetCourseCompletionService sets the service used to detect course completion after approvals
+func (s *ProgressService) SetCourseCompletionService(completionService *CourseCompletionService) {
+	s.completionService = completionService
+}
+
 func (s *ProgressService) GetSelfProgress(userID, courseID string) ([]map[string]interface{}, error) {
 	var results []struct {
 		ProgressID      string     `gorm:"column:progress_id"`
@@ -104,6 +112,21 @@ func (s *ProgressService) GetSelfProgress(userID, courseID string) ([]map[string
 
 // CourseProgressRow is now defined in internal/types/services.go
 
+// GetProgressForMaterials returns one user's progress on several materials in a single
+// query, keyed by material ID. Materials the user has not started are absent.
+func (s *ProgressService) GetProgressForMaterials(userID string, materialIDs []string) (map[string]models.StudentProgress, error) {
+	var rows []models.StudentProgress
+	if err := s.db.Where("user_id = ? AND material_id IN ?", userID, materialIDs).Find(&rows).Error; err != nil {
+		return nil, fmt.Errorf("get progress: %w", err)
+	}
+
+	progress := make(map[string]models.StudentProgress, len(rows))
+	for _, row := range rows {
+		progress[row.MaterialID] = row
+	}
+	return progress, nil
+}
+
 func (s *ProgressService) GetCourseProgress(courseID string) ([]map[string]interface{}, error) {
 	// Single optimized query to get all data at once
 	var results []struct {
@@ -165,6 +188,44 @@ func (s *ProgressService) GetCourseProgress(courseID string) ([]map[string]inter
 	return out, nil
 }
 
+// GetNonSubmitters lists the users enrolled in the material's course who have no
+// submission for it; with includeIncomplete, also those who submitted but whose progress
+// is not completed. role limits the list to one enrollment role, e.g. students only;
+// empty means every role.
+func (s *ProgressService) GetNonSubmitters(materialID string, role enums.EnrollmentRole, includeIncomplete bool) ([]types.NonSubmitter, error) {
+	var material models.CourseMaterial
+	if err := s.db.Select("material_id", "course_id").First(&material, "material_id = ?", materialID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("course material not found")
+		}
+		return nil, fmt.Errorf("get material: %w", err)
+	}
+
+	query := s.db.Table("enrollments e").
+		Select(`e.user_id, u.first_name, u.last_name, u.email, e.role,
+			EXISTS (SELECT 1 FROM submissions s WHERE s.user_id = e.user_id AND s.material_id = ?) AS has_submitted,
+			COALESCE(sp.status, ?) AS progress_status`, materialID, enums.ProgressNotStarted).
+		Joins("JOIN users u ON u.user_id = e.user_id").
+		Joins("LEFT JOIN student_progress sp ON sp.user_id = e.user_id AND sp.material_id = ?", materialID).
+		Where("e.course_id = ?", material.CourseID)
+
+	noSubmission := "NOT EXISTS (SELECT 1 FROM submissions s WHERE s.user_id = e.user_id AND s.material_id = ?)"
+	if includeIncomplete {
+		query = query.Where("("+noSubmission+" OR sp.status IS DISTINCT FROM ?)", materialID, enums.ProgressCompleted)
+	} else {
+		query = query.Where(noSubmission, materialID)
+	}
+	if role != "" {
+		query = query.Where("e.role = ?", role)
+	}
+
+	nonSubmitters := []types.NonSubmitter{}
+	if err := query.Order("u.first_name, u.last_name").Scan(&nonSubmitters).Error; err != nil {
+		return nil, fmt.Errorf("get non-submitters: %w", err)
+	}
+	return nonSubmitters, nil
+}
+
 func (s *ProgressService) VerifyProgress(progressID, verifiedBy string, status enums.VerificationStatus, comment string) (*models.VerificationLog, error) {
 	if status != enums.VerificationApproved && status != enums.VerificationRejected {
 		return nil, fmt.Errorf("invalid verification status")
@@ -189,10 +250,22 @@ func (s *ProgressService) VerifyProgress(progressID, verifiedBy string, status e
 			Update("status", newStatus).Error; err != nil {
 			return fmt.Errorf("update progress: %w", err)
 		}
-		return nil
+
+		var prog models.StudentProgress
+		if err := tx.Select("user_id, material_id").Where("progress_id = ?", progressID).First(&prog).Error; err != nil {
+			return fmt.Errorf("get progress: %w", err)
+		}
+		return recomputeCourseScoreForMaterial(tx, prog.UserID, prog.MaterialID)
 	}); err != nil {
 		return nil, err
 	}
+
+	if status == enums.VerificationApproved && s.completionService != nil {
+		var prog models.StudentProgress
+		if err := s.db.Select("user_id, material_id").Where("progress_id = ?", progressID).First(&prog).Error; err == nil {
+			s.completionService.CheckMaterialCompletion(prog.UserID, prog.MaterialID)
+		}
+	}
 	return log, nil
 }
 
@@ -206,6 +279,32 @@ func (s *ProgressService) GetVerificationLogs(progressID string) ([]models.Verif
 	return logs, nil
 }
 
+// GetVerificationHistory returns every review of the student's progress on the material,
+// oldest first, so a grade that changed over several review cycles can be traced.
+// A student without progress on the material has an empty history.
+func (s *ProgressService) GetVerificationHistory(userID, materialID string) ([]types.VerificationHistoryEntry, error) {
+	var material models.CourseMaterial
+	if err := s.db.Select("material_id").First(&material, "material_id = ?", materialID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("course material not found")
+		}
+		return nil, fmt.Errorf("get material: %w", err)
+	}
+
+	history := []types.VerificationHistoryEntry{}
+	if err := s.db.Table("verification_logs vl").
+		Select(`vl.log_id, vl.status, vl.comment, vl.verified_at,
+			vl.verified_by AS reviewer_id, COALESCE(u.first_name, '') AS reviewer_first_name, COALESCE(u.last_name, '') AS reviewer_last_name`).
+		Joins("JOIN student_progress sp ON sp.progress_id = vl.progress_id").
+		Joins("LEFT JOIN users u ON u.user_id = vl.verified_by").
+		Where("sp.user_id = ? AND sp.material_id = ?", userID, materialID).
+		Order("vl.verified_at ASC").
+		Scan(&history).Error; err != nil {
+		return nil, fmt.Errorf("get verification history: %w", err)
+	}
+	return history, nil
+}
+
 func (s *ProgressService) CanRequestReview(userID, exerciseID string) (bool, error) {
 	// ตรวจสอบว่ามี StudentProgress และสถานะ in_progress
 	var prog models.StudentProgress
@@ -0,0 +1,37 @@
+package services
+
+import (
+	"testing"
+
+	models "github.com/Project-DSView/backend/go/internal/domain/entities"
+	"github.com/Project-DSView/backend/go/internal/domain/enums"
+)
+
+func TestGetProgressForMaterials(t *testing.T) {
+	db := newTestDB(t, &models.StudentProgress{})
+	createRows(t, db,
+		&models.StudentProgress{UserID: "student-1", MaterialID: "code-1", Status: enums.ProgressCompleted, Score: 10},
+		&models.StudentProgress{UserID: "student-1", MaterialID: "code-2", Status: enums.ProgressWaitingReview},
+		&models.StudentProgress{UserID: "student-1", MaterialID: "code-other-week", Status: enums.ProgressCompleted},
+		&models.StudentProgress{UserID: "student-2", MaterialID: "code-3", Status: enums.ProgressCompleted},
+	)
+
+	progress, err := NewProgressService(db, nil, nil).GetProgressForMaterials("student-1", []string{"code-1", "code-2", "code-3"})
+	if err != nil {
+		t.Fatalf("GetProgressForMaterials() error = %v", err)
+	}
+
+	want := map[string]enums.ProgressStatus{"code-1": enums.ProgressCompleted, "code-2": enums.ProgressWaitingReview}
+	if len(progress) != len(want) {
+		t.Fatalf("progress on %d materials, want %d: %+v", len(progress), len(want), progress)
+	}
+	for id, status := range want {
+		got, ok := progress[id]
+		if !ok || got.Status != status || got.UserID != "student-1" {
+			t.Errorf("progress[%s] = %+v, want student-1's %s progress", id, got, status)
+		}
+	}
+	if progress["code-1"].Score != 10 {
+		t.Errorf("code-1 score = %d, want 10", progress["code-1"].Score)
+	}
+}
@@ -3,7 +3,9 @@ package services
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"sync"
 	"time"
 
 	models "github.com/Project-DSView/backend/go/internal/domain/entities"
@@ -19,16 +21,65 @@ type QueueService struct {
 	rabbitMQ          *external.RabbitMQService
 	userService       *UserService
 	submissionService *SubmissionService // Optional: set after initialization to avoid circular dependency
+	completionService *CourseCompletionService
+
+	// Separate budgets so heavy file processing cannot starve code grading (and vice versa)
+	codeExecutionLimiter  *jobLimiter
+	fileProcessingLimiter *jobLimiter
+
+	consumers *consumerRegistry
+	// stopConsumers ends the context the consumers were started with
+	stopConsumers context.CancelFunc
+	drainTimeout  time.Duration
+
+	// consumerCtx is the context the consumers were started with; each course's
+	// consumers run under a child of it, cancelled by StopCourseConsumers
+	consumerCtx        context.Context
+	courseConsumersMu  sync.Mutex
+	courseConsumerStop map[string]context.CancelFunc
+
+	stuckJobThreshold time.Duration
 }
 
+// Default worker limits, used until SetConcurrencyLimits is called
+const (
+	defaultCodeExecutionConcurrency  = 4
+	defaultFileProcessingConcurrency = 2
+)
+
 // QueueJobData, QueueJobResult, and TestResult are now defined in internal/types/services.go
 
 func NewQueueService(db *gorm.DB, rabbitMQ *external.RabbitMQService, userService *UserService) *QueueService {
 	return &QueueService{
-		db:          db,
-		rabbitMQ:    rabbitMQ,
-		userService: userService,
+		db:                    db,
+		rabbitMQ:              rabbitMQ,
+		userService:           userService,
+		codeExecutionLimiter:  newJobLimiter(defaultCodeExecutionConcurrency),
+		fileProcessingLimiter: newJobLimiter(defaultFileProcessingConcurrency),
+		consumers:             newConsumerRegistry(),
+		courseConsumerStop:    make(map[string]context.CancelFunc),
+		drainTimeout:          defaultDrainTimeout,
+		stuckJobThreshold:     defaultStuckJobThreshold,
+	}
+}
+
+// SetConcurrencyLimits sets how many code execution and file processing messages
+// may be handled at once. Must be called before StartQueueConsumer.
+func (s *QueueService) SetConcurrencyLimits(codeExecution, fileProcessing int) {
+	s.codeExecutionLimiter = newJobLimiter(codeExecution)
+	s.fileProcessingLimiter = newJobLimiter(fileProcessing)
+}
+
+// BrokerStatus reports the RabbitMQ connection state: "ok", "reconnecting",
+// or "disabled" when the service started without a broker
+func (s *QueueService) BrokerStatus() string {
+	if s.rabbitMQ == nil {
+		return "disabled"
 	}
+	if !s.rabbitMQ.IsConnected() {
+		return "reconnecting"
+	}
+	return "ok"
 }
 
 // SetSubmissionService sets the submission service (called after initialization to avoid circular dependency)
@@ -36,6 +87,11 @@ func (s *QueueService) SetSubmissionService(submissionService *SubmissionService
 	s.submissionService = submissionService
 }
 
+// SetCourseCompletionService sets the service used to detect course completion after review approvals
+func (s *QueueService) SetCourseCompletionService(completionService *CourseCompletionService) {
+	s.completionService = completionService
+}
+
 // GetDB returns the database instance (for handler access)
 func (s *QueueService) GetDB() *gorm.DB {
 	return s.db
@@ -43,14 +99,31 @@ func (s *QueueService) GetDB() *gorm.DB {
 
 // SubmitCodeExecutionJob submits a code execution job to the queue
 func (s *QueueService) SubmitCodeExecutionJob(ctx context.Context, userID, materialID, submissionID, code, courseID string) (*models.QueueJob, error) {
-	// Create job data
-	jobData := types.QueueJobData{
+	return s.submitCodeExecution(ctx, userID, types.QueueJobData{
 		Code:         code,
 		MaterialID:   materialID,
 		SubmissionID: submissionID,
 		FileName:     "submission.py",
 		CourseID:     courseID,
-	}
+	})
+}
+
+// SubmitRejudgeJob queues an existing code submission to be graded again against the current test cases
+func (s *QueueService) SubmitRejudgeJob(ctx context.Context, sub *models.Submission, courseID string) (*models.QueueJob, error) {
+	return s.submitCodeExecution(ctx, sub.UserID, types.QueueJobData{
+		Code:         sub.Code,
+		MaterialID:   sub.MaterialID,
+		SubmissionID: sub.SubmissionID,
+		FileName:     sub.FileName,
+		CourseID:     courseID,
+		Rejudge:      true,
+	})
+}
+
+// submitCodeExecution records a code execution job and publishes it to the course's queue
+func (s *QueueService) submitCodeExecution(ctx context.Context, userID string, jobData types.QueueJobData) (*models.QueueJob, error) {
+	materialID := jobData.MaterialID
+	courseID := jobData.CourseID
 
 	dataJSON, err := json.Marshal(jobData)
 	if err != nil {
@@ -67,24 +140,15 @@ func (s *QueueService) SubmitCodeExecutionJob(ctx context.Context, userID, mater
 		Data:       string(dataJSON),
 	}
 
-	if err := s.db.Create(queueJob).Error; err != nil {
-		return nil, fmt.Errorf("failed to create queue job: %w", err)
-	}
-
-	// Publish to RabbitMQ using course-specific queue
-	message := &external.QueueMessage{
-		ID:   queueJob.ID,
-		Type: string(enums.QueueTypeCodeExecution),
-		Data: map[string]interface{}{"job_id": queueJob.ID},
+	// Jobs of an exercise with a concurrency cap may be held back (see releaseHeldJobs)
+	publish, err := s.createCodeExecutionJob(queueJob)
+	if err != nil {
+		return nil, err
 	}
-
-	if err := s.rabbitMQ.PublishMessage(context.Background(), string(enums.QueueTypeCodeExecution), courseID, message); err != nil {
-		// Update job status to failed
-		s.db.Model(&models.QueueJob{}).Where("id = ?", queueJob.ID).Updates(map[string]interface{}{
-			"status": enums.QueueStatusFailed,
-			"error":  fmt.Sprintf("Failed to publish to queue: %v", err),
-		})
-		return nil, fmt.Errorf("failed to publish message: %w", err)
+	if publish {
+		if err := s.publishCodeExecutionJob(queueJob); err != nil {
+			return nil, err
+		}
 	}
 
 	return queueJob, nil
@@ -202,13 +266,6 @@ func (s *QueueService) GetQueueJobs(queueType string, status string, courseID st
 
 // GetQueueJobsWithDateFilter retrieves queue jobs with filtering including date range
 func (s *QueueService) GetQueueJobsWithDateFilter(queueType string, status string, courseID string, userID string, isTeacher bool, page, limit int, fromDate, toDate string) ([]models.QueueJob, int, error) {
-	// Cleanup old queue jobs before querying (async, don't wait for result)
-	go func() {
-		if err := s.CleanupOldQueueJobs(); err != nil {
-			logger.Warnf("Failed to cleanup old queue jobs: %v", err)
-		}
-	}()
-
 	var jobs []models.QueueJob
 	var total int64
 
@@ -486,18 +543,6 @@ func (s *QueueService) UpdateJobStatus(jobID string, status enums.QueueStatus, p
 	return nil
 }
 
-// IsUserTAInCourse checks if a user is a TA in a specific course
-func (s *QueueService) IsUserTAInCourse(courseID, userID string) (bool, error) {
-	var count int64
-	err := s.db.Model(&models.Enrollment{}).
-		Where("course_id = ? AND user_id = ? AND role = ?", courseID, userID, enums.EnrollmentRoleTA).
-		Count(&count).Error
-	if err != nil {
-		return false, fmt.Errorf("failed to check TA status: %w", err)
-	}
-	return count > 0, nil
-}
-
 // CancelJob cancels a pending job
 func (s *QueueService) CancelJob(jobID, userID string, isTeacher bool) error {
 	var job models.QueueJob
@@ -518,7 +563,17 @@ func (s *QueueService) CancelJob(jobID, userID string, isTeacher bool) error {
 		return fmt.Errorf("can only cancel pending jobs")
 	}
 
-	return s.UpdateJobStatus(jobID, enums.QueueStatusCancelled, "", nil, "")
+	if err := s.UpdateJobStatus(jobID, enums.QueueStatusCancelled, "", nil, ""); err != nil {
+		return err
+	}
+
+	// A cancelled code execution job frees its slot under the exercise's concurrency cap
+	if job.Type == enums.QueueTypeCodeExecution && job.MaterialID != nil {
+		if err := s.releaseHeldJobs(*job.MaterialID); err != nil {
+			logger.Warnf("Failed to release held jobs for material %s: %v", *job.MaterialID, err)
+		}
+	}
+	return nil
 }
 
 // GetQueueStats returns statistics about the queue
@@ -568,11 +623,42 @@ func (s *QueueService) GetQueueStats() (map[string]interface{}, error) {
 	}
 	stats["total"] = total
 
+	// Messages currently being handled by this instance's consumers
+	stats["in_flight"] = map[string]interface{}{
+		string(enums.QueueTypeCodeExecution):  s.codeExecutionLimiter.stats(),
+		string(enums.QueueTypeFileProcessing): s.fileProcessingLimiter.stats(),
+	}
+
+	// Executor containers on this host, shared by every course and job type
+	if s.submissionService != nil {
+		stats["containers"] = s.submissionService.ContainerUsage()
+	}
+
+	// Jobs processing for longer than the stuck job threshold
+	stuckCounts, err := s.CountStuckJobs()
+	if err != nil {
+		return nil, err
+	}
+	var stuckTotal int64
+	for _, count := range stuckCounts {
+		stuckTotal += count
+	}
+	stats["stuck_processing"] = map[string]interface{}{
+		"total":     stuckTotal,
+		"by_type":   stuckCounts,
+		"threshold": s.stuckJobThreshold.String(),
+	}
+
 	return stats, nil
 }
 
-// StartQueueConsumer starts consuming messages from course-specific queues
+// StartQueueConsumer starts consuming messages from course-specific queues.
+// The consumers run until ctx ends or DrainConsumers is called.
 func (s *QueueService) StartQueueConsumer(ctx context.Context) error {
+	s.courseConsumersMu.Lock()
+	s.consumerCtx, s.stopConsumers = context.WithCancel(ctx)
+	s.courseConsumersMu.Unlock()
+
 	// Get all active course IDs
 	courseIDs, err := s.GetActiveCourseIDs()
 	if err != nil {
@@ -586,31 +672,93 @@ func (s *QueueService) StartQueueConsumer(ctx context.Context) error {
 
 	// Start consumers for each course
 	for _, courseID := range courseIDs {
-		// Start code execution consumer for this course
-		if err := s.rabbitMQ.ConsumeMessages(ctx, string(enums.QueueTypeCodeExecution), courseID, s.handleCodeExecutionMessage); err != nil {
-			logger.Warnf("Failed to start code execution consumer for course %s: %v", courseID, err)
-			continue
+		if err := s.StartCourseConsumers(courseID); err != nil {
+			logger.Warnf("Failed to start queue consumers for course %s: %v", courseID, err)
 		}
+	}
 
-		// Start code review consumer for this course
-		if err := s.rabbitMQ.ConsumeMessages(ctx, string(enums.QueueTypeReview), courseID, s.handleCodeReviewMessage); err != nil {
-			logger.Warnf("Failed to start code review consumer for course %s: %v", courseID, err)
-			continue
-		}
+	logger.Infof("Started queue consumers for %d courses", len(courseIDs))
+	return nil
+}
 
-		// Start file processing consumer for this course
-		if err := s.rabbitMQ.ConsumeMessages(ctx, string(enums.QueueTypeFileProcessing), courseID, s.handleFileProcessingMessage); err != nil {
-			logger.Warnf("Failed to start file processing consumer for course %s: %v", courseID, err)
-			continue
+// StartCourseConsumers starts the code execution, review and file processing consumers
+// of one course, e.g. when an archived course is reactivated. It does nothing without
+// a broker, before StartQueueConsumer, or when the course's consumers already run.
+// If one fails to start, those already started are stopped again.
+func (s *QueueService) StartCourseConsumers(courseID string) error {
+	s.courseConsumersMu.Lock()
+	defer s.courseConsumersMu.Unlock()
+	if s.rabbitMQ == nil || s.consumerCtx == nil || s.consumerCtx.Err() != nil {
+		return nil
+	}
+	if _, running := s.courseConsumerStop[courseID]; running {
+		return nil
+	}
+
+	ctx, stop := context.WithCancel(s.consumerCtx)
+	for _, consumer := range []struct {
+		queueType enums.QueueType
+		handler   func(*external.QueueMessage) error
+	}{
+		{enums.QueueTypeCodeExecution, s.codeExecutionLimiter.wrap(ctx, s.handleCodeExecutionMessage)},
+		{enums.QueueTypeReview, s.handleCodeReviewMessage},
+		{enums.QueueTypeFileProcessing, s.fileProcessingLimiter.wrap(ctx, s.handleFileProcessingMessage)},
+	} {
+		if err := s.startCourseConsumer(ctx, courseID, consumer.queueType, consumer.handler); err != nil {
+			stop()
+			return fmt.Errorf("start %s consumer: %w", consumer.queueType, err)
 		}
+	}
 
-		logger.Infof("Started queue consumers for course: %s", courseID)
+	s.courseConsumerStop[courseID] = stop
+	logger.Infof("Started queue consumers for course: %s", courseID)
+	return nil
+}
+
+// StopCourseConsumers stops the consumers of one course, e.g. when it is archived.
+// Messages being handled finish; messages not yet handled stay in the course's queues
+// until its consumers are started again.
+func (s *QueueService) StopCourseConsumers(courseID string) {
+	s.courseConsumersMu.Lock()
+	stop, running := s.courseConsumerStop[courseID]
+	delete(s.courseConsumerStop, courseID)
+	s.courseConsumersMu.Unlock()
+
+	if running {
+		stop()
+		logger.Infof("Stopped queue consumers for course: %s", courseID)
 	}
+}
 
-	logger.Infof("Started queue consumers for %d courses", len(courseIDs))
+// startCourseConsumer starts one course queue consumer and records it in the consumer registry
+func (s *QueueService) startCourseConsumer(ctx context.Context, courseID string, queueType enums.QueueType, handler func(*external.QueueMessage) error) error {
+	handler = s.consumers.wrap(courseID, string(queueType), handler)
+	if err := s.rabbitMQ.ConsumeMessages(ctx, string(queueType), courseID, handler); err != nil {
+		s.consumers.startFailed(ctx, courseID, string(queueType), err)
+		return err
+	}
+	s.consumers.started(ctx, courseID, string(queueType))
 	return nil
 }
 
+// ListConsumers reports the queue consumers started by this instance with their
+// status, last message time and recent errors
+func (s *QueueService) ListConsumers() []types.QueueConsumerStatus {
+	return s.consumers.snapshot(s.rabbitMQ != nil && s.rabbitMQ.IsConnected())
+}
+
+// skipRedeliveredJob reports whether a consumed message belongs to a job that already
+// finished. RabbitMQ redelivers messages that were not acked (e.g. the consumer crashed
+// after grading), so such messages are acked without running the job again to avoid
+// grading twice and updating progress twice. Retries create a new job, so they still run.
+func skipRedeliveredJob(job *models.QueueJob) bool {
+	if job.Status != enums.QueueStatusCompleted && job.Status != enums.QueueStatusCancelled {
+		return false
+	}
+	logger.Infof("Skipping redelivered %s job %s: already %s", job.Type, job.ID, job.Status)
+	return true
+}
+
 // handleCodeExecutionMessage processes code execution messages
 func (s *QueueService) handleCodeExecutionMessage(msg *external.QueueMessage) error {
 	jobID, ok := msg.Data["job_id"].(string)
@@ -626,6 +774,18 @@ func (s *QueueService) handleCodeExecutionMessage(msg *external.QueueMessage) er
 	if job == nil {
 		return fmt.Errorf("job not found: %s", jobID)
 	}
+	if skipRedeliveredJob(job) {
+		return nil
+	}
+
+	// Once this job is done its slot under the exercise's concurrency cap is free
+	if job.MaterialID != nil {
+		defer func() {
+			if err := s.releaseHeldJobs(*job.MaterialID); err != nil {
+				logger.Warnf("Failed to release held jobs for material %s: %v", *job.MaterialID, err)
+			}
+		}()
+	}
 
 	// Update status to processing
 	if err := s.UpdateJobStatus(jobID, enums.QueueStatusProcessing, "", nil, ""); err != nil {
@@ -646,7 +806,15 @@ func (s *QueueService) handleCodeExecutionMessage(msg *external.QueueMessage) er
 	}
 
 	// Execute code submission
-	if err := s.submissionService.ExecuteCodeSubmission(jobData.SubmissionID, jobData.Code, jobData.MaterialID); err != nil {
+	execute := s.submissionService.ExecuteCodeSubmission
+	if jobData.Rejudge {
+		execute = s.submissionService.ExecuteRejudge
+	}
+	if err := execute(jobData.SubmissionID, jobData.Code, jobData.MaterialID); err != nil {
+		if errors.Is(err, ErrSubmissionCancelled) {
+			logger.Infof("Code execution job %s stopped: submission %s was cancelled", jobID, jobData.SubmissionID)
+			return s.UpdateJobStatus(jobID, enums.QueueStatusCancelled, "", nil, "")
+		}
 		s.UpdateJobStatus(jobID, enums.QueueStatusFailed, "", nil, fmt.Sprintf("Code execution failed: %v", err))
 		return fmt.Errorf("code execution failed: %w", err)
 	}
@@ -674,6 +842,9 @@ func (s *QueueService) handleCodeReviewMessage(msg *external.QueueMessage) error
 	if job == nil {
 		return fmt.Errorf("job not found: %s", jobID)
 	}
+	if skipRedeliveredJob(job) {
+		return nil
+	}
 
 	// Update status to processing
 	if err := s.UpdateJobStatus(jobID, enums.QueueStatusProcessing, "", nil, ""); err != nil {
@@ -724,6 +895,9 @@ func (s *QueueService) handleFileProcessingMessage(msg *external.QueueMessage) e
 	if job == nil {
 		return fmt.Errorf("job not found: %s", jobID)
 	}
+	if skipRedeliveredJob(job) {
+		return nil
+	}
 
 	// Update status to processing
 	if err := s.UpdateJobStatus(jobID, enums.QueueStatusProcessing, "", nil, ""); err != nil {
@@ -910,7 +1084,7 @@ func (s *QueueService) updateProgressFromReview(job *models.QueueJob, status, co
 			}).Error; err != nil {
 			return fmt.Errorf("reset progress: %w", err)
 		}
-		return nil
+		return recomputeCourseScoreForMaterial(s.db, job.UserID, *job.MaterialID)
 	}
 
 	// Update progress for approval
@@ -919,6 +1093,21 @@ func (s *QueueService) updateProgressFromReview(job *models.QueueJob, status, co
 		Update("status", newStatus).Error; err != nil {
 		return fmt.Errorf("update progress: %w", err)
 	}
+	if err := recomputeCourseScoreForMaterial(s.db, job.UserID, *job.MaterialID); err != nil {
+		return fmt.Errorf("update course score: %w", err)
+	}
+
+	if s.completionService != nil {
+		courseID := ""
+		if job.CourseID != nil {
+			courseID = *job.CourseID
+		}
+		if courseID != "" {
+			s.completionService.CheckCourseCompletion(job.UserID, courseID)
+		} else {
+			s.completionService.CheckMaterialCompletion(job.UserID, *job.MaterialID)
+		}
+	}
 
 	return nil
 }
@@ -959,6 +1148,42 @@ func (s *QueueService) GetCoursesByTA(userID string) ([]string, error) {
 	return courseIDs, nil
 }
 
+// retryWindow returns how long a job must wait before it can be retried, which comes
+// from the course settings (defaults to 1 day)
+func (s *QueueService) retryWindow(courseID *string) time.Duration {
+	retryWindow := models.CourseSettings{}.GetRetryWindow()
+	if courseID != nil {
+		var course models.Course
+		if err := s.db.Select("course_id", "settings").Where("course_id = ?", *courseID).First(&course).Error; err == nil {
+			retryWindow = course.Settings.GetRetryWindow()
+		}
+	}
+	return retryWindow
+}
+
+// ActiveCodeExecutionJob returns the user's pending or processing code execution job for
+// a material, or nil. A job older than the retry window is presumed stuck and may be
+// retried, so it is not reported.
+func (s *QueueService) ActiveCodeExecutionJob(userID, materialID string) (*models.QueueJob, error) {
+	var job models.QueueJob
+	err := s.db.Where("type = ? AND user_id = ? AND material_id = ? AND status IN ?",
+		enums.QueueTypeCodeExecution, userID, materialID,
+		[]enums.QueueStatus{enums.QueueStatusPending, enums.QueueStatusProcessing}).
+		Order("created_at DESC").
+		First(&job).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get active queue job: %w", err)
+	}
+
+	if job.CreatedAt.Before(time.Now().Add(-s.retryWindow(job.CourseID))) {
+		return nil, nil
+	}
+	return &job, nil
+}
+
 // CanRetryQueueJob checks if a queue job can be retried (must be >1 day old and belong to user)
 func (s *QueueService) CanRetryQueueJob(jobID, userID string) (bool, error) {
 	// Get the original job
@@ -975,6 +1200,9 @@ func (s *QueueService) CanRetryQueueJob(jobID, userID string) (bool, error) {
 		return false, fmt.Errorf("queue job does not belong to user")
 	}
 
+	retryWindow := s.retryWindow(job.CourseID)
+	retryAfter := time.Now().Add(-retryWindow)
+
 	// Get the submission to check submission date
 	var submission models.Submission
 	if job.SubmissionID != nil {
@@ -986,16 +1214,14 @@ func (s *QueueService) CanRetryQueueJob(jobID, userID string) (bool, error) {
 			return false, fmt.Errorf("failed to get submission: %w", err)
 		}
 
-		// Check if submission is more than 1 day old
-		oneDayAgo := time.Now().Add(-24 * time.Hour)
-		if submission.SubmittedAt.After(oneDayAgo) {
-			return false, fmt.Errorf("submission is less than 1 day old, cannot retry yet")
+		// Check if submission is still inside the retry window
+		if submission.SubmittedAt.After(retryAfter) {
+			return false, fmt.Errorf("submission is less than %s old, cannot retry yet", retryWindow)
 		}
 	} else {
 		// If no submission, check queue job creation date
-		oneDayAgo := time.Now().Add(-24 * time.Hour)
-		if job.CreatedAt.After(oneDayAgo) {
-			return false, fmt.Errorf("queue job is less than 1 day old, cannot retry yet")
+		if job.CreatedAt.After(retryAfter) {
+			return false, fmt.Errorf("queue job is less than %s old, cannot retry yet", retryWindow)
 		}
 	}
 
@@ -1161,17 +1387,23 @@ func (s *QueueService) CleanupOrphanedQueues() error {
 	return nil
 }
 
-// CleanupOldQueueJobs deletes queue jobs that are older than today (based on created_at)
+// QueueJobRetentionDays is how many days before today finished queue jobs are kept, so
+// that a term's queue history can still be exported
+const QueueJobRetentionDays = 180
+
+// CleanupOldQueueJobs deletes finished queue jobs created more than QueueJobRetentionDays
+// before today (based on created_at)
 func (s *QueueService) CleanupOldQueueJobs() error {
 	// Use Thailand timezone (UTC+7)
 	thailandLocation, _ := time.LoadLocation("Asia/Bangkok")
 	today := time.Now().In(thailandLocation)
 	todayStart := time.Date(today.Year(), today.Month(), today.Day(), 0, 0, 0, 0, thailandLocation)
+	cutoff := todayStart.AddDate(0, 0, -QueueJobRetentionDays)
 
-	// Find old queue jobs (created_at < today)
+	// Find old queue jobs (created_at < cutoff)
 	// Only delete completed, failed, or cancelled jobs (not pending/processing)
 	var oldJobs []models.QueueJob
-	if err := s.db.Where("created_at < ? AND status IN ?", todayStart, []string{
+	if err := s.db.Where("created_at < ? AND status IN ?", cutoff, []string{
 		string(enums.QueueStatusCompleted),
 		string(enums.QueueStatusFailed),
 		string(enums.QueueStatusCancelled),
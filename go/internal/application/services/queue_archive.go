@@ -0,0 +1,84 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	models "github.com/Project-DSView/backend/go/internal/domain/entities"
+	"github.com/Project-DSView/backend/go/internal/domain/enums"
+	"github.com/Project-DSView/backend/go/pkg/logger"
+)
+
+// archivedJobTypes are the jobs an archived course's job policy applies to. Review jobs
+// are work for TAs rather than the consumers, so they are left as they are.
+var archivedJobTypes = []enums.QueueType{enums.QueueTypeCodeExecution, enums.QueueTypeFileProcessing}
+
+// CountActiveCourseJobs counts the course's code execution and file processing jobs
+// that are pending or processing
+func (s *QueueService) CountActiveCourseJobs(courseID string) (int64, error) {
+	var count int64
+	if err := s.db.Model(&models.QueueJob{}).
+		Where("course_id = ? AND type IN ? AND status IN ?", courseID, archivedJobTypes,
+			[]enums.QueueStatus{enums.QueueStatusPending, enums.QueueStatusProcessing}).
+		Count(&count).Error; err != nil {
+		return 0, fmt.Errorf("failed to count active course jobs: %w", err)
+	}
+	return count, nil
+}
+
+// CancelCourseJobs stops the course's outstanding grading work and returns how many jobs
+// it cancelled. Code submissions still running are cancelled like CancelRunningSubmission
+// does, giving students back their attempt; a rejudge still waiting is dropped and its
+// submission keeps the results of its previous run. Other pending code execution and
+// file processing jobs are cancelled; jobs already processing are left to finish.
+func (s *QueueService) CancelCourseJobs(courseID string) (int, error) {
+	var subs []models.Submission
+	if err := s.db.Where("status = ? AND material_id IN (?)", enums.SubmissionRunning,
+		s.db.Model(&models.CourseMaterial{}).Select("material_id").Where("course_id = ?", courseID)).
+		Find(&subs).Error; err != nil {
+		return 0, fmt.Errorf("failed to get running submissions: %w", err)
+	}
+
+	cancelled := 0
+	for i := range subs {
+		sub := &subs[i]
+		if sub.PassedCount+sub.FailedCount == 0 {
+			if s.submissionService == nil {
+				continue
+			}
+			if err := s.submissionService.cancelSubmission(sub); err != nil {
+				if !errors.Is(err, ErrSubmissionNotRunning) {
+					logger.Warnf("Failed to cancel submission %s of archived course %s: %v", sub.SubmissionID, courseID, err)
+				}
+				continue
+			}
+			cancelled++
+			continue
+		}
+
+		res := s.db.Model(&models.QueueJob{}).
+			Where("submission_id = ? AND type = ? AND status = ?", sub.SubmissionID, enums.QueueTypeCodeExecution, enums.QueueStatusPending).
+			Updates(map[string]interface{}{"status": enums.QueueStatusCancelled, "completed_at": time.Now()})
+		if res.Error != nil {
+			return cancelled, fmt.Errorf("failed to cancel rejudge of submission %s: %w", sub.SubmissionID, res.Error)
+		}
+		if res.RowsAffected == 0 {
+			continue
+		}
+		if err := s.db.Model(&models.Submission{}).
+			Where("submission_id = ? AND status = ?", sub.SubmissionID, enums.SubmissionRunning).
+			Update("status", enums.SubmissionCompleted).Error; err != nil {
+			return cancelled, fmt.Errorf("failed to restore submission %s: %w", sub.SubmissionID, err)
+		}
+		cancelled++
+	}
+
+	res := s.db.Model(&models.QueueJob{}).
+		Where("course_id = ? AND type IN ? AND status = ?", courseID, archivedJobTypes, enums.QueueStatusPending).
+		Updates(map[string]interface{}{"status": enums.QueueStatusCancelled, "completed_at": time.Now()})
+	if res.Error != nil {
+		return cancelled, fmt.Errorf("failed to cancel pending course jobs: %w", res.Error)
+	}
+	return cancelled + int(res.RowsAffected), nil
+}
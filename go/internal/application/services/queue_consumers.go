@@ -0,0 +1,172 @@
+package services
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/Project-DSView/backend/go/internal/types"
+	"github.com/Project-DSView/backend/go/pkg/external"
+)
+
+// Consumer lifecycle states reported by ListConsumers
+const (
+	ConsumerStatusRunning      = "running"
+	ConsumerStatusReconnecting = "reconnecting" // Broker connection lost; restarted on recovery
+	ConsumerStatusFailed       = "failed"       // Could not be started
+	ConsumerStatusStopped      = "stopped"      // Its context ended (shutdown)
+)
+
+// maxRecentConsumerErrors bounds how many errors are kept per consumer
+const maxRecentConsumerErrors = 5
+
+// consumerState is the tracked lifecycle of one course queue consumer
+type consumerState struct {
+	ctx             context.Context
+	courseID        string
+	queueType       string
+	failed          bool
+	startedAt       *time.Time
+	lastMessageAt   *time.Time
+	messagesHandled int64
+	recentErrors    []types.QueueConsumerError
+}
+
+// consumerRegistry tracks the queue consumers started by this instance, keyed by course and queue type
+type consumerRegistry struct {
+	mu        sync.Mutex
+	consumers map[string]*consumerState
+
+	// Messages being handled, with the job ID each one carries
+	inFlightJobs map[*external.QueueMessage]string
+}
+
+func newConsumerRegistry() *consumerRegistry {
+	return &consumerRegistry{
+		consumers:    make(map[string]*consumerState),
+		inFlightJobs: make(map[*external.QueueMessage]string),
+	}
+}
+
+func (r *consumerRegistry) stateLocked(ctx context.Context, courseID, queueType string) *consumerState {
+	key := courseID + "/" + queueType
+	state, ok := r.consumers[key]
+	if !ok {
+		state = &consumerState{courseID: courseID, queueType: queueType}
+		r.consumers[key] = state
+	}
+	state.ctx = ctx
+	return state
+}
+
+func (state *consumerState) addError(err error) {
+	state.recentErrors = append(state.recentErrors, types.QueueConsumerError{Message: err.Error(), At: time.Now()})
+	if len(state.recentErrors) > maxRecentConsumerErrors {
+		state.recentErrors = state.recentErrors[len(state.recentErrors)-maxRecentConsumerErrors:]
+	}
+}
+
+// started records that a consumer is subscribed
+func (r *consumerRegistry) started(ctx context.Context, courseID, queueType string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	state := r.stateLocked(ctx, courseID, queueType)
+	now := time.Now()
+	state.failed = false
+	state.startedAt = &now
+}
+
+// startFailed records that a consumer could not be started
+func (r *consumerRegistry) startFailed(ctx context.Context, courseID, queueType string, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	state := r.stateLocked(ctx, courseID, queueType)
+	state.failed = true
+	state.addError(err)
+}
+
+// wrap returns a handler that records message times and handler errors for the consumer,
+// and tracks the message as in flight while it is handled
+func (r *consumerRegistry) wrap(courseID, queueType string, handler func(*external.QueueMessage) error) func(*external.QueueMessage) error {
+	return func(msg *external.QueueMessage) error {
+		jobID, _ := msg.Data["job_id"].(string)
+		r.mu.Lock()
+		r.inFlightJobs[msg] = jobID
+		r.mu.Unlock()
+
+		err := handler(msg)
+
+		r.mu.Lock()
+		delete(r.inFlightJobs, msg)
+		if state, ok := r.consumers[courseID+"/"+queueType]; ok {
+			now := time.Now()
+			state.lastMessageAt = &now
+			state.messagesHandled++
+			if err != nil {
+				state.addError(err)
+			}
+		}
+		r.mu.Unlock()
+
+		return err
+	}
+}
+
+// inFlightCount returns how many messages are being handled
+func (r *consumerRegistry) inFlightCount() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.inFlightJobs)
+}
+
+// inFlightJobIDs returns the job IDs of the messages being handled
+func (r *consumerRegistry) inFlightJobIDs() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	jobIDs := make([]string, 0, len(r.inFlightJobs))
+	for _, jobID := range r.inFlightJobs {
+		if jobID != "" {
+			jobIDs = append(jobIDs, jobID)
+		}
+	}
+	return jobIDs
+}
+
+// snapshot returns the consumers sorted by course and queue type.
+// brokerConnected=false reports running consumers as reconnecting.
+func (r *consumerRegistry) snapshot(brokerConnected bool) []types.QueueConsumerStatus {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	consumers := make([]types.QueueConsumerStatus, 0, len(r.consumers))
+	for _, state := range r.consumers {
+		status := ConsumerStatusRunning
+		switch {
+		case state.failed:
+			status = ConsumerStatusFailed
+		case state.ctx != nil && state.ctx.Err() != nil:
+			status = ConsumerStatusStopped
+		case !brokerConnected:
+			status = ConsumerStatusReconnecting
+		}
+
+		consumers = append(consumers, types.QueueConsumerStatus{
+			CourseID:        state.courseID,
+			QueueType:       state.queueType,
+			Status:          status,
+			StartedAt:       state.startedAt,
+			LastMessageAt:   state.lastMessageAt,
+			MessagesHandled: state.messagesHandled,
+			RecentErrors:    append([]types.QueueConsumerError{}, state.recentErrors...),
+		})
+	}
+
+	sort.Slice(consumers, func(i, j int) bool {
+		if consumers[i].CourseID != consumers[j].CourseID {
+			return consumers[i].CourseID < consumers[j].CourseID
+		}
+		return consumers[i].QueueType < consumers[j].QueueType
+	})
+	return consumers
+}
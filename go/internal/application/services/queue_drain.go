@@ -0,0 +1,70 @@
+package services
+
+import (
+	"errors"
+	"time"
+
+	models "github.com/Project-DSView/backend/go/internal/domain/entities"
+	"github.com/Project-DSView/backend/go/internal/domain/enums"
+	"github.com/Project-DSView/backend/go/pkg/logger"
+)
+
+// defaultDrainTimeout is used until SetDrainTimeout is called
+const defaultDrainTimeout = 30 * time.Second
+
+// drainPollInterval is how often DrainConsumers checks whether in-flight messages are done
+const drainPollInterval = 100 * time.Millisecond
+
+// errConsumerStopped is returned for messages that arrive while the consumers are
+// draining; the message is nacked and requeued for the next instance
+var errConsumerStopped = errors.New("queue consumer stopped")
+
+// SetDrainTimeout sets how long DrainConsumers waits for messages being handled to finish
+func (s *QueueService) SetDrainTimeout(timeout time.Duration) {
+	if timeout > 0 {
+		s.drainTimeout = timeout
+	}
+}
+
+// DrainConsumers stops the queue consumers on shutdown. They stop taking new messages,
+// messages being handled get up to the drain timeout to finish, and the jobs of those
+// still running are reset to pending so they run again after a restart instead of
+// staying in processing. Closing the broker connection then returns every unacked
+// message to its queue.
+func (s *QueueService) DrainConsumers() {
+	if s.rabbitMQ == nil {
+		return
+	}
+	if s.stopConsumers != nil {
+		s.stopConsumers()
+	}
+
+	s.drainInFlight()
+
+	if err := s.rabbitMQ.Close(); err != nil {
+		logger.Warnf("Failed to close RabbitMQ connection: %v", err)
+	}
+}
+
+// drainInFlight waits up to the drain timeout for the messages being handled to finish,
+// then resets the jobs of those still running to pending
+func (s *QueueService) drainInFlight() {
+	deadline := time.Now().Add(s.drainTimeout)
+	for s.consumers.inFlightCount() > 0 && time.Now().Before(deadline) {
+		time.Sleep(drainPollInterval)
+	}
+
+	if s.consumers.inFlightCount() == 0 {
+		logger.Info("Queue consumers drained")
+	} else {
+		jobIDs := s.consumers.inFlightJobIDs()
+		logger.Warnf("Queue consumers still handling %d jobs after %s, resetting them to pending", len(jobIDs), s.drainTimeout)
+		if len(jobIDs) > 0 {
+			if err := s.db.Model(&models.QueueJob{}).
+				Where("id IN ? AND status = ?", jobIDs, enums.QueueStatusProcessing).
+				Updates(map[string]interface{}{"status": enums.QueueStatusPending, "started_at": nil}).Error; err != nil {
+				logger.Errorf("Failed to reset in-flight queue jobs %v: %v", jobIDs, err)
+			}
+		}
+	}
+}
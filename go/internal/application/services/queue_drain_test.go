@@ -0,0 +1,124 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	models "github.com/Project-DSView/backend/go/internal/domain/entities"
+	"github.com/Project-DSView/backend/go/internal/domain/enums"
+	"github.com/Project-DSView/backend/go/pkg/external"
+)
+
+// TestDrainInFlightRequeuesUnfinishedJobs checks that a job still being handled when the
+// drain timeout ends goes back to pending, and that other processing jobs are left alone
+func TestDrainInFlightRequeuesUnfinishedJobs(t *testing.T) {
+	db := newTestDB(t, &models.QueueJob{})
+	startedAt := time.Now().Add(-time.Minute)
+	createRows(t, db,
+		&models.QueueJob{ID: "job-running", Type: enums.QueueTypeCodeExecution, Status: enums.QueueStatusProcessing, UserID: "student-1", StartedAt: &startedAt},
+		&models.QueueJob{ID: "job-elsewhere", Type: enums.QueueTypeCodeExecution, Status: enums.QueueStatusProcessing, UserID: "student-2", StartedAt: &startedAt},
+	)
+	svc := NewQueueService(db, nil, nil)
+	svc.SetDrainTimeout(10 * time.Millisecond)
+
+	release := make(chan struct{})
+	handler := svc.consumers.wrap("course-1", string(enums.QueueTypeCodeExecution), func(*external.QueueMessage) error {
+		<-release
+		return nil
+	})
+	done := make(chan struct{})
+	go func() {
+		handler(&external.QueueMessage{ID: "job-running", Data: map[string]interface{}{"job_id": "job-running"}})
+		close(done)
+	}()
+	defer func() {
+		close(release)
+		<-done
+	}()
+	for deadline := time.Now().Add(5 * time.Second); svc.consumers.inFlightCount() == 0; {
+		if time.Now().After(deadline) {
+			t.Fatal("message never became in flight")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	svc.drainInFlight()
+
+	want := map[string]enums.QueueStatus{"job-running": enums.QueueStatusPending, "job-elsewhere": enums.QueueStatusProcessing}
+	for id, status := range want {
+		var job models.QueueJob
+		if err := db.First(&job, "id = ?", id).Error; err != nil {
+			t.Fatalf("load %s: %v", id, err)
+		}
+		if job.Status != status {
+			t.Errorf("%s status = %s, want %s", id, job.Status, status)
+		}
+		if requeued := job.StartedAt == nil; requeued != (status == enums.QueueStatusPending) {
+			t.Errorf("%s started_at = %v, want it cleared only when requeued", id, job.StartedAt)
+		}
+	}
+}
+
+// TestDrainInFlightWaitsForHandlers checks that a message finishing within the drain
+// timeout keeps the status its handler gave the job
+func TestDrainInFlightWaitsForHandlers(t *testing.T) {
+	db := newTestDB(t, &models.QueueJob{})
+	createRows(t, db, &models.QueueJob{ID: "job-1", Type: enums.QueueTypeCodeExecution, Status: enums.QueueStatusProcessing, UserID: "student-1"})
+	svc := NewQueueService(db, nil, nil)
+	svc.SetDrainTimeout(5 * time.Second)
+
+	started := make(chan struct{})
+	handler := svc.consumers.wrap("course-1", string(enums.QueueTypeCodeExecution), func(*external.QueueMessage) error {
+		close(started)
+		time.Sleep(20 * time.Millisecond)
+		return db.Model(&models.QueueJob{}).Where("id = ?", "job-1").Update("status", enums.QueueStatusCompleted).Error
+	})
+	go handler(&external.QueueMessage{ID: "job-1", Data: map[string]interface{}{"job_id": "job-1"}})
+	<-started
+
+	svc.drainInFlight()
+
+	var job models.QueueJob
+	if err := db.First(&job, "id = ?", "job-1").Error; err != nil {
+		t.Fatalf("load job: %v", err)
+	}
+	if job.Status != enums.QueueStatusCompleted {
+		t.Errorf("status = %s, want %s", job.Status, enums.QueueStatusCompleted)
+	}
+}
+
+// TestJobLimiterGivesBackMessagesOnStop checks that a message waiting for a slot when the
+// consumers stop is returned unhandled
+func TestJobLimiterGivesBackMessagesOnStop(t *testing.T) {
+	limiter := newJobLimiter(1)
+	ctx, stop := context.WithCancel(context.Background())
+
+	release := make(chan struct{})
+	busy := limiter.wrap(ctx, func(*external.QueueMessage) error {
+		<-release
+		return nil
+	})
+	go busy(&external.QueueMessage{ID: "job-1"})
+	for deadline := time.Now().Add(5 * time.Second); limiter.stats()["in_flight"].(int64) == 0; {
+		if time.Now().After(deadline) {
+			t.Fatal("first message never took the slot")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	defer close(release)
+
+	handled := false
+	waiting := limiter.wrap(ctx, func(*external.QueueMessage) error {
+		handled = true
+		return nil
+	})
+	time.AfterFunc(10*time.Millisecond, stop)
+	if err := waiting(&external.QueueMessage{ID: "job-2"}); !errors.Is(err, errConsumerStopped) {
+		t.Errorf("err = %v, want %v", err, errConsumerStopped)
+	}
+	if handled {
+		t.Error("message handled after the consumers stopped")
+	}
+}
@@ -0,0 +1,126 @@
+package services
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	models "github.com/Project-DSView/backend/go/internal/domain/entities"
+)
+
+// queueExportHeader is the header row of ExportJobsCSV
+var queueExportHeader = []string{
+	"job_id", "student_id", "student_name", "student_email",
+	"material_id", "material_title", "type", "status",
+	"submitted_at", "claimed_at", "completed_at",
+	"reviewer_id", "reviewer_name",
+}
+
+// queueExportRow is one line of ExportJobsCSV
+type queueExportRow struct {
+	ID               string
+	UserID           string
+	StudentFirstName string
+	StudentLastName  string
+	StudentEmail     string
+	MaterialID       string
+	MaterialTitle    string
+	Type             string
+	Status           string
+	SubmittedAt      time.Time
+	ClaimedAt        *time.Time
+	CompletedAt      *time.Time
+	ProcessedBy      string
+	ReviewerName     string
+}
+
+// ExportJobsCSV writes the course's queue jobs created in [from, to) to w as CSV, oldest
+// first. Rows are read and written one at a time, so a term's history is never held in
+// memory. Finished jobs are kept for QueueJobRetentionDays, so older ones are missing.
+// Cells that a spreadsheet would read as a formula are escaped.
+func (s *QueueService) ExportJobsCSV(w io.Writer, courseID string, from, to time.Time) error {
+	rows, err := s.db.Model(&models.QueueJob{}).
+		Select(`queue_jobs.id, queue_jobs.user_id,
+			COALESCE(students.first_name, '') AS student_first_name, COALESCE(students.last_name, '') AS student_last_name,
+			COALESCE(students.email, '') AS student_email,
+			COALESCE(queue_jobs.material_id, '') AS material_id, COALESCE(code_exercises.title, pdf_exercises.title, '') AS material_title,
+			queue_jobs.type, queue_jobs.status,
+			COALESCE(submissions.submitted_at, queue_jobs.created_at) AS submitted_at,
+			queue_jobs.claimed_at, queue_jobs.completed_at, COALESCE(queue_jobs.processed_by, '') AS processed_by,
+			TRIM(COALESCE(reviewers.first_name, '') || ' ' || COALESCE(reviewers.last_name, '')) AS reviewer_name`).
+		Joins("LEFT JOIN users AS students ON students.user_id = queue_jobs.user_id").
+		Joins("LEFT JOIN users AS reviewers ON reviewers.user_id = queue_jobs.processed_by").
+		Joins("LEFT JOIN submissions ON submissions.submission_id = queue_jobs.submission_id").
+		Joins("LEFT JOIN code_exercises ON code_exercises.material_id = queue_jobs.material_id").
+		Joins("LEFT JOIN pdf_exercises ON pdf_exercises.material_id = queue_jobs.material_id").
+		Where("queue_jobs.course_id = ? AND queue_jobs.created_at >= ? AND queue_jobs.created_at < ?", courseID, from, to).
+		Order("queue_jobs.created_at ASC").
+		Rows()
+	if err != nil {
+		return fmt.Errorf("failed to query queue jobs: %w", err)
+	}
+	defer rows.Close()
+
+	writer := csv.NewWriter(w)
+	if err := writer.Write(queueExportHeader); err != nil {
+		return fmt.Errorf("failed to write CSV header: %w", err)
+	}
+
+	for rows.Next() {
+		var row queueExportRow
+		if err := s.db.ScanRows(rows, &row); err != nil {
+			return fmt.Errorf("failed to read queue job: %w", err)
+		}
+
+		if err := writer.Write(queueExportRecord(row)); err != nil {
+			return fmt.Errorf("failed to write CSV row: %w", err)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to read queue jobs: %w", err)
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// queueExportRecord formats row as the CSV fields of queueExportHeader
+func queueExportRecord(row queueExportRow) []string {
+	record := []string{
+		row.ID,
+		row.UserID,
+		strings.TrimSpace(row.StudentFirstName + " " + row.StudentLastName),
+		row.StudentEmail,
+		row.MaterialID,
+		row.MaterialTitle,
+		row.Type,
+		row.Status,
+		row.SubmittedAt.Format(time.RFC3339),
+		formatExportTime(row.ClaimedAt),
+		formatExportTime(row.CompletedAt),
+		row.ProcessedBy,
+		row.ReviewerName,
+	}
+	for i := range record {
+		record[i] = escapeCSVFormula(record[i])
+	}
+	return record
+}
+
+// escapeCSVFormula prefixes a cell starting with a formula character with a quote, so
+// names and titles are shown as text rather than run by spreadsheet applications
+func escapeCSVFormula(cell string) string {
+	if cell != "" && strings.ContainsRune("=+-@\t\r", rune(cell[0])) {
+		return "'" + cell
+	}
+	return cell
+}
+
+func formatExportTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
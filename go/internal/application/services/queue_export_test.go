@@ -0,0 +1,82 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	models "github.com/Project-DSView/backend/go/internal/domain/entities"
+	"github.com/Project-DSView/backend/go/internal/domain/enums"
+)
+
+// TestQueueExportRecordEscapesFormulas checks that student-controlled cells a spreadsheet
+// would evaluate are written as text
+func TestQueueExportRecordEscapesFormulas(t *testing.T) {
+	record := queueExportRecord(queueExportRow{
+		ID:               "job-1",
+		StudentFirstName: "=HYPERLINK(\"http://x\")",
+		StudentLastName:  "Doe",
+		StudentEmail:     "@evil.example",
+		MaterialTitle:    "-1 Linked lists",
+		SubmittedAt:      time.Date(2024, 10, 1, 9, 0, 0, 0, time.UTC),
+	})
+
+	if len(record) != len(queueExportHeader) {
+		t.Fatalf("got %d fields, want %d", len(record), len(queueExportHeader))
+	}
+	want := map[int]string{
+		0: "job-1",
+		2: "'=HYPERLINK(\"http://x\") Doe",
+		3: "'@evil.example",
+		5: "'-1 Linked lists",
+		8: "2024-10-01T09:00:00Z",
+	}
+	for i, w := range want {
+		if record[i] != w {
+			t.Errorf("%s = %q, want %q", queueExportHeader[i], record[i], w)
+		}
+	}
+}
+
+func TestEscapeCSVFormula(t *testing.T) {
+	tests := map[string]string{
+		"":           "",
+		"Alice":      "Alice",
+		"=1+1":       "'=1+1",
+		"+66 81":     "'+66 81",
+		"-2":         "'-2",
+		"@SUM(A1)":   "'@SUM(A1)",
+		"\t=1":       "'\t=1",
+		"a=b":        "a=b",
+		"2024-10-01": "2024-10-01",
+	}
+	for cell, want := range tests {
+		if got := escapeCSVFormula(cell); got != want {
+			t.Errorf("escapeCSVFormula(%q) = %q, want %q", cell, got, want)
+		}
+	}
+}
+
+// TestCleanupOldQueueJobsKeepsRetentionWindow checks that finished jobs stay exportable
+// for QueueJobRetentionDays and that unfinished jobs are never deleted
+func TestCleanupOldQueueJobsKeepsRetentionWindow(t *testing.T) {
+	db := newTestDB(t, &models.QueueJob{})
+	now := time.Now()
+	expired := now.AddDate(0, 0, -QueueJobRetentionDays-2)
+	createRows(t, db,
+		&models.QueueJob{ID: "yesterday", Type: enums.QueueTypeCodeExecution, Status: enums.QueueStatusCompleted, UserID: "student-1", CreatedAt: now.AddDate(0, 0, -1)},
+		&models.QueueJob{ID: "expired", Type: enums.QueueTypeCodeExecution, Status: enums.QueueStatusCompleted, UserID: "student-1", CreatedAt: expired},
+		&models.QueueJob{ID: "expired-pending", Type: enums.QueueTypeCodeExecution, Status: enums.QueueStatusPending, UserID: "student-1", CreatedAt: expired},
+	)
+
+	if err := NewQueueService(db, nil, nil).CleanupOldQueueJobs(); err != nil {
+		t.Fatalf("CleanupOldQueueJobs() error = %v", err)
+	}
+
+	var ids []string
+	if err := db.Model(&models.QueueJob{}).Order("id").Pluck("id", &ids).Error; err != nil {
+		t.Fatalf("list queue jobs: %v", err)
+	}
+	if len(ids) != 2 || ids[0] != "expired-pending" || ids[1] != "yesterday" {
+		t.Errorf("remaining jobs = %v, want [expired-pending yesterday]", ids)
+	}
+}
@@ -0,0 +1,49 @@
+package services
+
+import (
+	"context"
+	"sync/atomic"
+
+	"github.com/Project-DSView/backend/go/pkg/external"
+)
+
+// jobLimiter caps how many queue messages of one kind are handled at once,
+// across all course consumers, and tracks how many are currently in flight
+type jobLimiter struct {
+	slots    chan struct{}
+	inFlight int64
+}
+
+func newJobLimiter(limit int) *jobLimiter {
+	if limit < 1 {
+		limit = 1
+	}
+	return &jobLimiter{slots: make(chan struct{}, limit)}
+}
+
+// wrap returns a handler that waits for a free slot before calling handler.
+// While waiting the message stays unacked, so RabbitMQ keeps it for us; when ctx
+// ends first the message is given back without being handled.
+func (l *jobLimiter) wrap(ctx context.Context, handler func(*external.QueueMessage) error) func(*external.QueueMessage) error {
+	return func(msg *external.QueueMessage) error {
+		select {
+		case l.slots <- struct{}{}:
+		case <-ctx.Done():
+			return errConsumerStopped
+		}
+		atomic.AddInt64(&l.inFlight, 1)
+		defer func() {
+			atomic.AddInt64(&l.inFlight, -1)
+			<-l.slots
+		}()
+		return handler(msg)
+	}
+}
+
+// stats reports the in-flight count alongside the configured limit
+func (l *jobLimiter) stats() map[string]interface{} {
+	return map[string]interface{}{
+		"in_flight": atomic.LoadInt64(&l.inFlight),
+		"limit":     cap(l.slots),
+	}
+}
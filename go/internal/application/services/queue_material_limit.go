@@ -0,0 +1,196 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	models "github.com/Project-DSView/backend/go/internal/domain/entities"
+	"github.com/Project-DSView/backend/go/internal/domain/enums"
+	"github.com/Project-DSView/backend/go/pkg/external"
+	"github.com/Project-DSView/backend/go/pkg/logger"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Per-exercise admission: a code exercise with max_concurrent_submissions set only has
+// that many of its code execution jobs published at a time. Further jobs are stored
+// held and published oldest first as running ones finish (releaseHeldJobs), so one busy
+// exam exercise cannot fill the course queue and the executor ahead of everything else.
+
+// lockMaterialConcurrencyLimit returns the exercise's max_concurrent_submissions (0 when
+// uncapped or not a code exercise), locking its row so admission is serialized per exercise
+func lockMaterialConcurrencyLimit(tx *gorm.DB, materialID string) (int, error) {
+	var exercise models.CodeExercise
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Select("material_id", "max_concurrent_submissions").
+		First(&exercise, "material_id = ?", materialID).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to get max concurrent submissions: %w", err)
+	}
+	return exercise.MaxConcurrentSubmissions, nil
+}
+
+// materialCodeJobs selects the material's code execution jobs
+func materialCodeJobs(db *gorm.DB, materialID string) *gorm.DB {
+	return db.Model(&models.QueueJob{}).
+		Where("type = ? AND material_id = ?", enums.QueueTypeCodeExecution, materialID)
+}
+
+// createCodeExecutionJob stores a new code execution job. It reports whether the job
+// must be published now; when its exercise has a concurrency cap the job is stored held
+// and admitted by releaseHeldJobs instead.
+func (s *QueueService) createCodeExecutionJob(job *models.QueueJob) (publish bool, err error) {
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		limit, err := lockMaterialConcurrencyLimit(tx, *job.MaterialID)
+		if err != nil {
+			return err
+		}
+		job.Held = limit > 0
+		return tx.Create(job).Error
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to create queue job: %w", err)
+	}
+	if !job.Held {
+		return true, nil
+	}
+
+	// The new job waits behind older held ones; admit whatever fits now
+	if err := s.releaseHeldJobs(*job.MaterialID); err != nil {
+		logger.Warnf("Failed to release held jobs for material %s: %v", *job.MaterialID, err)
+	}
+	if err := s.db.Select("held", "status", "error").First(job, "id = ?", job.ID).Error; err != nil {
+		return false, fmt.Errorf("failed to reload queue job: %w", err)
+	}
+	if job.Status == enums.QueueStatusFailed {
+		return false, fmt.Errorf("failed to publish message: %s", job.Error)
+	}
+	return false, nil
+}
+
+// releaseHeldJobs publishes the oldest held jobs of a material while it has free slots.
+// It is safe to call at any time: after a job finishes or is cancelled, and periodically.
+func (s *QueueService) releaseHeldJobs(materialID string) error {
+	var released []models.QueueJob
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		limit, err := lockMaterialConcurrencyLimit(tx, materialID)
+		if err != nil {
+			return err
+		}
+
+		held := materialCodeJobs(tx, materialID).
+			Where("held AND status = ?", enums.QueueStatusPending).
+			Order("created_at ASC")
+		if limit > 0 {
+			var active int64
+			if err := materialCodeJobs(tx, materialID).
+				Where("NOT held AND status IN ?", []enums.QueueStatus{enums.QueueStatusPending, enums.QueueStatusProcessing}).
+				Count(&active).Error; err != nil {
+				return fmt.Errorf("failed to count active jobs: %w", err)
+			}
+			free := limit - int(active)
+			if free <= 0 {
+				return nil
+			}
+			held = held.Limit(free)
+		}
+		// Without a cap (it was removed) every held job is released
+
+		if err := held.Find(&released).Error; err != nil {
+			return fmt.Errorf("failed to get held jobs: %w", err)
+		}
+		if len(released) == 0 {
+			return nil
+		}
+
+		ids := make([]string, len(released))
+		for i, job := range released {
+			ids[i] = job.ID
+		}
+		return tx.Model(&models.QueueJob{}).Where("id IN ?", ids).Update("held", false).Error
+	})
+	if err != nil {
+		return err
+	}
+
+	for i := range released {
+		if err := s.publishCodeExecutionJob(&released[i]); err != nil {
+			logger.Warnf("Failed to publish released job %s: %v", released[i].ID, err)
+		}
+	}
+	return nil
+}
+
+// publishCodeExecutionJob publishes a stored code execution job to its course queue,
+// marking the job failed when that is not possible
+func (s *QueueService) publishCodeExecutionJob(job *models.QueueJob) error {
+	var err error
+	if s.rabbitMQ == nil {
+		err = fmt.Errorf("RabbitMQ service not available")
+	} else {
+		message := &external.QueueMessage{
+			ID:   job.ID,
+			Type: string(enums.QueueTypeCodeExecution),
+			Data: map[string]interface{}{"job_id": job.ID},
+		}
+		courseID := ""
+		if job.CourseID != nil {
+			courseID = *job.CourseID
+		}
+		err = s.rabbitMQ.PublishMessage(context.Background(), string(enums.QueueTypeCodeExecution), courseID, message)
+	}
+
+	if err != nil {
+		s.db.Model(&models.QueueJob{}).Where("id = ?", job.ID).Updates(map[string]interface{}{
+			"status": enums.QueueStatusFailed,
+			"error":  fmt.Sprintf("Failed to publish to queue: %v", err),
+		})
+		return fmt.Errorf("failed to publish message: %w", err)
+	}
+	return nil
+}
+
+// ReleaseAllHeldJobs runs releaseHeldJobs for every material with held jobs. Run
+// periodically by the scheduler to catch slots freed outside the queue consumers,
+// e.g. by resubmissions cancelling a running job.
+func (s *QueueService) ReleaseAllHeldJobs() error {
+	var materialIDs []string
+	if err := s.db.Model(&models.QueueJob{}).
+		Where("type = ? AND held AND status = ?", enums.QueueTypeCodeExecution, enums.QueueStatusPending).
+		Distinct().
+		Pluck("material_id", &materialIDs).Error; err != nil {
+		return fmt.Errorf("failed to get materials with held jobs: %w", err)
+	}
+
+	var failed int
+	for _, materialID := range materialIDs {
+		if err := s.releaseHeldJobs(materialID); err != nil {
+			logger.Warnf("Failed to release held jobs for material %s: %v", materialID, err)
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("failed to release held jobs for %d of %d materials", failed, len(materialIDs))
+	}
+	return nil
+}
+
+// QueuePosition returns a held job's place in line for its exercise (1 = next to be
+// admitted), or 0 when the job is not waiting
+func (s *QueueService) QueuePosition(job *models.QueueJob) (int, error) {
+	if !job.Held || job.Status != enums.QueueStatusPending || job.MaterialID == nil {
+		return 0, nil
+	}
+
+	var ahead int64
+	if err := materialCodeJobs(s.db, *job.MaterialID).
+		Where("held AND status = ? AND created_at < ?", enums.QueueStatusPending, job.CreatedAt).
+		Count(&ahead).Error; err != nil {
+		return 0, fmt.Errorf("failed to get queue position: %w", err)
+	}
+	return int(ahead) + 1, nil
+}
@@ -0,0 +1,133 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	models "github.com/Project-DSView/backend/go/internal/domain/entities"
+	"github.com/Project-DSView/backend/go/internal/domain/enums"
+	"gorm.io/gorm"
+)
+
+// newHeldJobsTest creates a code exercise with the given max_concurrent_submissions, one
+// job of it that is processing, and three held jobs created a minute apart
+func newHeldJobsTest(t *testing.T, limit int) (*gorm.DB, *QueueService, string) {
+	t.Helper()
+	db := newTestDB(t, &models.CodeExercise{}, &models.QueueJob{})
+	points := 10
+	exercise := models.CodeExercise{
+		MaterialBase:             models.MaterialBase{CourseID: "course-1", Title: "Exam", CreatedBy: "teacher-1"},
+		TotalPoints:              &points,
+		MaxConcurrentSubmissions: limit,
+	}
+	createRows(t, db, &exercise)
+
+	materialID := exercise.MaterialID
+	job := func(id string, status enums.QueueStatus, held bool, minute int) *models.QueueJob {
+		return &models.QueueJob{ID: id, Type: enums.QueueTypeCodeExecution, Status: status, UserID: "student-" + id,
+			MaterialID: &materialID, Held: held, CreatedAt: time.Now().Add(time.Duration(minute) * time.Minute)}
+	}
+	createRows(t, db,
+		job("running", enums.QueueStatusProcessing, false, 0),
+		job("held-1", enums.QueueStatusPending, true, 1),
+		job("held-2", enums.QueueStatusPending, true, 2),
+		job("held-3", enums.QueueStatusPending, true, 3),
+	)
+	return db, NewQueueService(db, nil, nil), materialID
+}
+
+func TestReleaseHeldJobs(t *testing.T) {
+	tests := []struct {
+		name  string
+		limit int
+		// wantHeld are the jobs still held afterwards
+		wantHeld []string
+	}{
+		{"no free slot", 1, []string{"held-1", "held-2", "held-3"}},
+		{"oldest job takes the free slot", 2, []string{"held-2", "held-3"}},
+		{"more slots than held jobs", 10, nil},
+		{"cap removed", 0, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, svc, materialID := newHeldJobsTest(t, tt.limit)
+
+			if err := svc.releaseHeldJobs(materialID); err != nil {
+				t.Fatalf("releaseHeldJobs() error = %v", err)
+			}
+
+			var held []string
+			db.Model(&models.QueueJob{}).Where("held").Order("created_at").Pluck("id", &held)
+			if len(held) != len(tt.wantHeld) {
+				t.Fatalf("held jobs = %v, want %v", held, tt.wantHeld)
+			}
+			for i := range held {
+				if held[i] != tt.wantHeld[i] {
+					t.Fatalf("held jobs = %v, want %v", held, tt.wantHeld)
+				}
+			}
+
+			// Without a broker, released jobs cannot be published and fail
+			var failed int64
+			db.Model(&models.QueueJob{}).Where("NOT held AND status = ?", enums.QueueStatusFailed).Count(&failed)
+			if want := int64(3 - len(tt.wantHeld)); failed != want {
+				t.Errorf("%d released jobs marked failed, want %d", failed, want)
+			}
+		})
+	}
+}
+
+func TestQueuePosition(t *testing.T) {
+	db, svc, _ := newHeldJobsTest(t, 1)
+
+	for id, want := range map[string]int{"held-1": 1, "held-2": 2, "held-3": 3, "running": 0} {
+		var job models.QueueJob
+		if err := db.First(&job, "id = ?", id).Error; err != nil {
+			t.Fatalf("load %s: %v", id, err)
+		}
+		got, err := svc.QueuePosition(&job)
+		if err != nil {
+			t.Fatalf("QueuePosition(%s) error = %v", id, err)
+		}
+		if got != want {
+			t.Errorf("QueuePosition(%s) = %d, want %d", id, got, want)
+		}
+	}
+}
+
+func TestCreateCodeExecutionJob(t *testing.T) {
+	tests := []struct {
+		name        string
+		limit       int
+		wantPublish bool
+		wantHeld    bool
+	}{
+		{"uncapped exercise", 0, true, false},
+		{"capped exercise without a free slot", 1, false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, svc, materialID := newHeldJobsTest(t, tt.limit)
+
+			job := &models.QueueJob{ID: "new", Type: enums.QueueTypeCodeExecution, Status: enums.QueueStatusPending,
+				UserID: "student-new", MaterialID: &materialID}
+			publish, err := svc.createCodeExecutionJob(job)
+			if err != nil {
+				t.Fatalf("createCodeExecutionJob() error = %v", err)
+			}
+			if publish != tt.wantPublish {
+				t.Errorf("publish = %v, want %v", publish, tt.wantPublish)
+			}
+
+			var stored models.QueueJob
+			if err := db.First(&stored, "id = ?", "new").Error; err != nil {
+				t.Fatalf("load job: %v", err)
+			}
+			if stored.Held != tt.wantHeld || stored.Status != enums.QueueStatusPending {
+				t.Errorf("stored job held %v, status %s; want held %v, pending", stored.Held, stored.Status, tt.wantHeld)
+			}
+		})
+	}
+}
@@ -0,0 +1,77 @@
+package services
+
+import (
+	"testing"
+
+	models "github.com/Project-DSView/backend/go/internal/domain/entities"
+	"github.com/Project-DSView/backend/go/internal/domain/enums"
+	"github.com/Project-DSView/backend/go/internal/types"
+	"github.com/Project-DSView/backend/go/pkg/external"
+)
+
+// TestRedeliveredCodeExecutionJob checks that a code execution message delivered twice,
+// as RabbitMQ does when the ack is lost, grades the submission only once, and that a
+// message for a job that already finished is acked without running it
+func TestRedeliveredCodeExecutionJob(t *testing.T) {
+	tests := []struct {
+		name        string
+		status      enums.QueueStatus
+		wantStatus  enums.QueueStatus
+		wantResults int64
+	}{
+		{name: "pending job runs once", status: enums.QueueStatusPending, wantStatus: enums.QueueStatusCompleted, wantResults: 2},
+		{name: "completed job is skipped", status: enums.QueueStatusCompleted, wantStatus: enums.QueueStatusCompleted, wantResults: 0},
+		{name: "cancelled job is skipped", status: enums.QueueStatusCancelled, wantStatus: enums.QueueStatusCancelled, wantResults: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := newTestDB(t, &models.Course{}, &models.CourseMaterial{}, &models.CodeExercise{}, &models.TestCase{},
+				&models.Submission{}, &models.SubmissionResult{}, &models.StudentProgress{}, &models.QueueJob{},
+				&models.StudentCourseScore{})
+
+			points, courseID, submissionID := 10, "course-1", "sub-1"
+			code := models.CodeExercise{
+				MaterialBase:     models.MaterialBase{CourseID: "course-1", Title: "Sum", CreatedBy: "teacher-1"},
+				TotalPoints:      &points,
+				ProblemStatement: "Add the numbers",
+			}
+			createRows(t, db, &models.Course{CourseID: "course-1", Name: "Data Structures", CreatedBy: "teacher-1"}, &code)
+			codeType := string(enums.MaterialTypeCodeExercise)
+			createRows(t, db,
+				&models.CourseMaterial{MaterialID: code.MaterialID, CourseID: "course-1", Type: enums.MaterialTypeCodeExercise, ReferenceID: &code.MaterialID, ReferenceType: &codeType},
+				&models.TestCase{MaterialID: &code.MaterialID, MaterialType: codeType, InputData: types.JSONData(`[1,2]`), ExpectedOutput: types.JSONData(`{"output":3}`)},
+				&models.TestCase{MaterialID: &code.MaterialID, MaterialType: codeType, InputData: types.JSONData(`[2,1]`), ExpectedOutput: types.JSONData(`{"output":3}`)},
+				&models.Submission{SubmissionID: "sub-1", UserID: "student-1", MaterialID: code.MaterialID, Status: enums.SubmissionRunning},
+				&models.QueueJob{ID: "job-1", Type: enums.QueueTypeCodeExecution, Status: tt.status, UserID: "student-1",
+					CourseID: &courseID, MaterialID: &code.MaterialID, SubmissionID: &submissionID,
+					Data: `{"code":"print(3)","material_id":"` + code.MaterialID + `","submission_id":"sub-1"}`},
+			)
+
+			queue := NewQueueService(db, nil, nil)
+			queue.SetSubmissionService(NewSubmissionService(db, nil, nil, nil, nil, NewCourseMaterialService(db, nil),
+				fakeDockerExecutor(t, `{"output": 3}`), nil, nil))
+			msg := &external.QueueMessage{ID: "job-1", Type: string(enums.QueueTypeCodeExecution), Data: map[string]interface{}{"job_id": "job-1"}}
+			for delivery := 1; delivery <= 2; delivery++ {
+				if err := queue.handleCodeExecutionMessage(msg); err != nil {
+					t.Fatalf("delivery %d: %v", delivery, err)
+				}
+			}
+
+			var job models.QueueJob
+			if err := db.First(&job, "id = ?", "job-1").Error; err != nil {
+				t.Fatalf("load job: %v", err)
+			}
+			if job.Status != tt.wantStatus {
+				t.Errorf("job status = %s, want %s", job.Status, tt.wantStatus)
+			}
+			var results int64
+			if err := db.Model(&models.SubmissionResult{}).Where("submission_id = ?", "sub-1").Count(&results).Error; err != nil {
+				t.Fatalf("count results: %v", err)
+			}
+			if results != tt.wantResults {
+				t.Errorf("submission results = %d, want %d", results, tt.wantResults)
+			}
+		})
+	}
+}
@@ -0,0 +1,114 @@
+package services
+
+import (
+	"fmt"
+	"time"
+
+	models "github.com/Project-DSView/backend/go/internal/domain/entities"
+	"github.com/Project-DSView/backend/go/internal/domain/enums"
+	"github.com/Project-DSView/backend/go/internal/types"
+	"github.com/Project-DSView/backend/go/pkg/logger"
+	"gorm.io/gorm"
+)
+
+// defaultStuckJobThreshold is used until SetStuckJobThreshold is called
+const defaultStuckJobThreshold = 30 * time.Minute
+
+// processingSinceColumn is when a job entered processing: started_at for worker jobs,
+// claimed_at for TA reviews, updated_at for rows written before either was set
+const processingSinceColumn = "COALESCE(started_at, claimed_at, updated_at)"
+
+// SetStuckJobThreshold sets how long a job may stay in processing before it is reported as stuck
+func (s *QueueService) SetStuckJobThreshold(threshold time.Duration) {
+	if threshold > 0 {
+		s.stuckJobThreshold = threshold
+	}
+}
+
+// StuckJobThreshold returns how long a job may stay in processing before it is reported as stuck
+func (s *QueueService) StuckJobThreshold() time.Duration {
+	return s.stuckJobThreshold
+}
+
+// stuckJobsQuery selects jobs that have been processing for longer than the threshold,
+// e.g. a review a TA claimed and abandoned or a message whose worker died
+func (s *QueueService) stuckJobsQuery() *gorm.DB {
+	cutoff := time.Now().Add(-s.stuckJobThreshold)
+	return s.db.Model(&models.QueueJob{}).
+		Where("status = ?", enums.QueueStatusProcessing).
+		Where(processingSinceColumn+" < ?", cutoff)
+}
+
+// CountStuckJobs returns the number of stuck jobs per queue type. Every queue type is
+// present in the result so the metric reports 0 rather than disappearing.
+func (s *QueueService) CountStuckJobs() (map[string]int64, error) {
+	var rows []struct {
+		Type  string
+		Count int64
+	}
+	if err := s.stuckJobsQuery().
+		Select("type, count(*) as count").
+		Group("type").
+		Scan(&rows).Error; err != nil {
+		return nil, fmt.Errorf("failed to count stuck jobs: %w", err)
+	}
+
+	counts := map[string]int64{
+		string(enums.QueueTypeCodeExecution):  0,
+		string(enums.QueueTypeReview):         0,
+		string(enums.QueueTypeFileProcessing): 0,
+	}
+	for _, row := range rows {
+		counts[row.Type] = row.Count
+	}
+	return counts, nil
+}
+
+// GetStuckJobs lists the stuck jobs, oldest first
+func (s *QueueService) GetStuckJobs() ([]types.StuckQueueJob, error) {
+	var rows []struct {
+		models.QueueJob
+		ProcessingSince time.Time
+	}
+	if err := s.stuckJobsQuery().
+		Select("*, " + processingSinceColumn + " AS processing_since").
+		Order("processing_since ASC").
+		Scan(&rows).Error; err != nil {
+		return nil, fmt.Errorf("failed to get stuck jobs: %w", err)
+	}
+
+	now := time.Now()
+	jobs := make([]types.StuckQueueJob, 0, len(rows))
+	for _, row := range rows {
+		jobs = append(jobs, types.StuckQueueJob{
+			ID:              row.ID,
+			Type:            string(row.Type),
+			UserID:          row.UserID,
+			CourseID:        row.CourseID,
+			MaterialID:      row.MaterialID,
+			SubmissionID:    row.SubmissionID,
+			ProcessedBy:     row.ProcessedBy,
+			ProcessingSince: row.ProcessingSince,
+			AgeSeconds:      int64(now.Sub(row.ProcessingSince).Seconds()),
+		})
+	}
+	return jobs, nil
+}
+
+// CheckStuckJobs logs a warning while any job is stuck in processing. Run periodically by the scheduler.
+func (s *QueueService) CheckStuckJobs() error {
+	counts, err := s.CountStuckJobs()
+	if err != nil {
+		return err
+	}
+
+	var total int64
+	for _, count := range counts {
+		total += count
+	}
+	if total > 0 {
+		logger.Warnf("%d queue jobs have been processing for more than %s: %v (see GET /api/admin/queue/stuck)",
+			total, s.stuckJobThreshold, counts)
+	}
+	return nil
+}
@@ -0,0 +1,68 @@
+package services
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	models "github.com/Project-DSView/backend/go/internal/domain/entities"
+	"github.com/Project-DSView/backend/go/internal/domain/enums"
+)
+
+// TestStuckJobs checks that jobs count as stuck by when they entered processing:
+// started_at, else claimed_at, else updated_at
+func TestStuckJobs(t *testing.T) {
+	db := newTestDB(t, &models.QueueJob{})
+	now := time.Now()
+	ago := func(d time.Duration) *time.Time {
+		at := now.Add(-d)
+		return &at
+	}
+	createRows(t, db,
+		&models.QueueJob{ID: "worker-died", Type: enums.QueueTypeCodeExecution, Status: enums.QueueStatusProcessing, UserID: "student-1", StartedAt: ago(2 * time.Hour)},
+		&models.QueueJob{ID: "review-abandoned", Type: enums.QueueTypeReview, Status: enums.QueueStatusProcessing, UserID: "student-1", ClaimedAt: ago(time.Hour)},
+		&models.QueueJob{ID: "legacy", Type: enums.QueueTypeCodeExecution, Status: enums.QueueStatusProcessing, UserID: "student-1"},
+		&models.QueueJob{ID: "running", Type: enums.QueueTypeCodeExecution, Status: enums.QueueStatusProcessing, UserID: "student-1", StartedAt: ago(time.Minute)},
+		&models.QueueJob{ID: "restarted", Type: enums.QueueTypeFileProcessing, Status: enums.QueueStatusProcessing, UserID: "student-1", StartedAt: ago(time.Minute)},
+		&models.QueueJob{ID: "waiting", Type: enums.QueueTypeCodeExecution, Status: enums.QueueStatusPending, UserID: "student-1"},
+	)
+	// updated_at is set on write, so age it afterwards
+	if err := db.Model(&models.QueueJob{}).Where("id IN ?", []string{"legacy", "restarted", "waiting"}).
+		UpdateColumn("updated_at", now.Add(-3*time.Hour)).Error; err != nil {
+		t.Fatalf("age jobs: %v", err)
+	}
+
+	svc := NewQueueService(db, nil, nil)
+	svc.SetStuckJobThreshold(30 * time.Minute)
+
+	counts, err := svc.CountStuckJobs()
+	if err != nil {
+		t.Fatalf("CountStuckJobs() error = %v", err)
+	}
+	wantCounts := map[string]int64{
+		string(enums.QueueTypeCodeExecution):  2,
+		string(enums.QueueTypeReview):         1,
+		string(enums.QueueTypeFileProcessing): 0,
+	}
+	if !reflect.DeepEqual(counts, wantCounts) {
+		t.Errorf("CountStuckJobs() = %v, want %v", counts, wantCounts)
+	}
+
+	// GetStuckJobs scans processing_since, which SQLite returns as text, so list the
+	// same query by ID
+	var ids []string
+	if err := svc.stuckJobsQuery().Order(processingSinceColumn+" ASC").Pluck("id", &ids).Error; err != nil {
+		t.Fatalf("list stuck jobs: %v", err)
+	}
+	if want := []string{"legacy", "worker-died", "review-abandoned"}; !reflect.DeepEqual(ids, want) {
+		t.Errorf("stuck jobs = %v, want %v, oldest first", ids, want)
+	}
+}
+
+func TestSetStuckJobThresholdKeepsDefault(t *testing.T) {
+	svc := NewQueueService(nil, nil, nil)
+	svc.SetStuckJobThreshold(0)
+	if got := svc.StuckJobThreshold(); got != defaultStuckJobThreshold {
+		t.Errorf("threshold = %s, want the default %s", got, defaultStuckJobThreshold)
+	}
+}
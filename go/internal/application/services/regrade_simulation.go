@@ -0,0 +1,142 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	models "github.com/Project-DSView/backend/go/internal/domain/entities"
+	"github.com/Project-DSView/backend/go/internal/types"
+	"github.com/Project-DSView/backend/go/pkg/authz"
+	"github.com/Project-DSView/backend/go/pkg/external"
+	"gorm.io/gorm"
+)
+
+const (
+	// maxSimulatedSubmissions bounds how many submissions one simulation runs
+	maxSimulatedSubmissions = 100
+	// maxSimulatedRuns bounds the container runs of one simulation, since every
+	// submission costs a run per proposed test case
+	maxSimulatedRuns = 500
+)
+
+// ErrTooManySimulatedTestCases is returned when a simulation would exceed maxSimulatedRuns
+// with a single submission
+var ErrTooManySimulatedTestCases = fmt.Errorf("at most %d test cases can be simulated", maxSimulatedRuns)
+
+// simulationLimit returns how many submissions a simulation with testCaseCount
+// proposed test cases may run
+func simulationLimit(testCaseCount int) int {
+	if testCaseCount <= 0 {
+		return maxSimulatedSubmissions
+	}
+	return min(maxSimulatedSubmissions, maxSimulatedRuns/testCaseCount)
+}
+
+// SimulateRegrade runs each student's latest code submission to the exercise against
+// proposed test cases and reports how the scores would change. Nothing is saved:
+// submissions, results and progress are left as they are. The runs stop when ctx is
+// done; the submissions not run by then are left out and the result is truncated.
+func (s *SubmissionService) SimulateRegrade(ctx context.Context, materialID string, proposed []models.TestCase, actorID string) (*types.RegradeSimulation, error) {
+	if len(proposed) == 0 {
+		return nil, errors.New("at least one test case is required")
+	}
+	limit := simulationLimit(len(proposed))
+	if limit == 0 {
+		return nil, ErrTooManySimulatedTestCases
+	}
+
+	var material models.CourseMaterial
+	if err := s.db.First(&material, "material_id = ?", materialID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("course material not found")
+		}
+		return nil, fmt.Errorf("get material: %w", err)
+	}
+	if !material.IsCodeExercise() {
+		return nil, errors.New("only code exercises can be regraded")
+	}
+
+	canManage, err := authz.CanManageCourse(s.db, actorID, material.CourseID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get course details: %w", err)
+	}
+	if !canManage {
+		return nil, errors.New("only the course teacher can simulate a regrade")
+	}
+
+	if err := s.materialService.ValidateTestCases(proposed); err != nil {
+		return nil, err
+	}
+
+	var codeExercise models.CodeExercise
+	if err := s.db.First(&codeExercise, "material_id = ?", materialID).Error; err != nil {
+		return nil, fmt.Errorf("failed to get code exercise details: %w", err)
+	}
+	totalPoints := 0
+	if codeExercise.TotalPoints != nil {
+		totalPoints = *codeExercise.TotalPoints
+	}
+
+	// Latest code submission of each student, one more than the cap to detect truncation
+	var submissions []models.Submission
+	if err := s.db.Raw(`SELECT DISTINCT ON (user_id) * FROM submissions
+		WHERE material_id = ? AND code <> ''
+		ORDER BY user_id, submitted_at DESC
+		LIMIT ?`, materialID, limit+1).
+		Scan(&submissions).Error; err != nil {
+		return nil, fmt.Errorf("get submissions: %w", err)
+	}
+
+	simulation := &types.RegradeSimulation{
+		TestCaseCount: len(proposed),
+		Distribution:  []types.ScoreCount{},
+		Submissions:   make([]types.SimulatedScore, 0, len(submissions)),
+	}
+	if len(submissions) > limit {
+		submissions = submissions[:limit]
+		simulation.Truncated = true
+	}
+
+	counts := make(map[int]int)
+	currentSum, projectedSum := 0, 0
+	for _, sub := range submissions {
+		_, passed := s.gradeCode(ctx, sub.SubmissionID, sub.Code, &codeExercise, proposed)
+		if ctx.Err() != nil {
+			// The last run may have been cut short; its score would be wrong
+			simulation.Truncated = true
+			break
+		}
+		projected := external.ScoreFromCounts(totalPoints, passed, len(proposed))
+
+		simulation.Submissions = append(simulation.Submissions, types.SimulatedScore{
+			SubmissionID:   sub.SubmissionID,
+			UserID:         sub.UserID,
+			CurrentScore:   sub.TotalScore,
+			ProjectedScore: projected,
+			PassedCount:    passed,
+		})
+		counts[projected]++
+		currentSum += sub.TotalScore
+		projectedSum += projected
+		if projected != sub.TotalScore {
+			simulation.ChangedCount++
+		}
+	}
+
+	simulation.SubmissionCount = len(simulation.Submissions)
+
+	for score, count := range counts {
+		simulation.Distribution = append(simulation.Distribution, types.ScoreCount{Score: score, Count: count})
+	}
+	sort.Slice(simulation.Distribution, func(i, j int) bool {
+		return simulation.Distribution[i].Score < simulation.Distribution[j].Score
+	})
+	if simulation.SubmissionCount > 0 {
+		simulation.CurrentAverage = float64(currentSum) / float64(simulation.SubmissionCount)
+		simulation.ProjectedAverage = float64(projectedSum) / float64(simulation.SubmissionCount)
+	}
+
+	return simulation, nil
+}
@@ -0,0 +1,36 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	models "github.com/Project-DSView/backend/go/internal/domain/entities"
+)
+
+func TestSimulationLimit(t *testing.T) {
+	tests := []struct {
+		testCases int
+		want      int
+	}{
+		{testCases: 1, want: maxSimulatedSubmissions},
+		{testCases: 5, want: maxSimulatedSubmissions},
+		{testCases: 10, want: 50},
+		{testCases: 499, want: 1},
+		{testCases: maxSimulatedRuns + 1, want: 0},
+	}
+	for _, tt := range tests {
+		if got := simulationLimit(tt.testCases); got != tt.want {
+			t.Errorf("simulationLimit(%d) = %d, want %d", tt.testCases, got, tt.want)
+		}
+	}
+}
+
+func TestSimulateRegradeRejectsTooManyTestCases(t *testing.T) {
+	svc := NewSubmissionService(nil, nil, nil, nil, nil, nil, nil, nil, nil)
+
+	_, err := svc.SimulateRegrade(context.Background(), "code-1", make([]models.TestCase, maxSimulatedRuns+1), "teacher-1")
+	if !errors.Is(err, ErrTooManySimulatedTestCases) {
+		t.Fatalf("SimulateRegrade() error = %v, want %v", err, ErrTooManySimulatedTestCases)
+	}
+}
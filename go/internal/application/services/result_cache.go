@@ -0,0 +1,103 @@
+package services
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	models "github.com/Project-DSView/backend/go/internal/domain/entities"
+	"github.com/Project-DSView/backend/go/internal/domain/enums"
+	"gorm.io/gorm"
+)
+
+// hashCode identifies submitted code for the result cache
+func hashCode(code string) string {
+	sum := sha256.Sum256([]byte(code))
+	return hex.EncodeToString(sum[:])
+}
+
+// hashTestSet identifies everything besides the code that decides a code submission's
+// results: the test cases in grading order, the exercise's comparison settings and the
+// executor image. Editing, adding, removing or reordering a test case changes it, so
+// cached results never outlive the test cases they were graded against.
+func hashTestSet(codeExercise *models.CodeExercise, testCases []models.TestCase, image string) string {
+	h := sha256.New()
+	fmt.Fprintf(h, "image=%s\nmode=%s\ntrim=%t\ntolerance=%g\n", image,
+		codeExercise.GetOutputMode(), codeExercise.GetTrimWhitespace(), codeExercise.GetNumberTolerance())
+	for _, tc := range testCases {
+		inputKey := ""
+		if tc.InputKey != nil {
+			inputKey = *tc.InputKey
+		}
+		fmt.Fprintf(h, "case=%s\ninput=%s\ninput_key=%s\nexpected=%s\n",
+			tc.TestCaseID, []byte(tc.InputData), inputKey, []byte(tc.ExpectedOutput))
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// reusableResults reports whether graded results may be served to a later identical
+// submission: every test case ran, and none failed because of the executor or timed out,
+// since those may pass on another run
+func reusableResults(results []models.SubmissionResult, testCases []models.TestCase) bool {
+	if len(results) != len(testCases) {
+		return false
+	}
+	for _, result := range results {
+		if strings.HasPrefix(result.ErrorMessage, executionErrorPrefix) || result.ErrorMessage == executionTimedOutMessage {
+			return false
+		}
+	}
+	return true
+}
+
+// resultCacheEnabled reports whether the course has opted in to the result cache
+func (s *SubmissionService) resultCacheEnabled(courseID string) (bool, error) {
+	var course models.Course
+	if err := s.db.Select("course_id", "settings").First(&course, "course_id = ?", courseID).Error; err != nil {
+		return false, fmt.Errorf("get course settings: %w", err)
+	}
+	return course.Settings.IsResultCacheEnabled(), nil
+}
+
+// cachedResults returns the results of the latest graded submission to the material with
+// the same code and test set, copied for submissionID in test case order, and how many
+// passed; nil when there is none
+func (s *SubmissionService) cachedResults(submissionID, materialID, codeHash, testSetHash string, testCases []models.TestCase) ([]models.SubmissionResult, int, error) {
+	var prior models.Submission
+	err := s.db.Preload("Results").
+		Where("material_id = ? AND code_hash = ? AND test_set_hash = ? AND submission_id <> ?",
+			materialID, codeHash, testSetHash, submissionID).
+		Where("status IN ?", []enums.SubmissionStatus{enums.SubmissionPending, enums.SubmissionCompleted}).
+		Order("submitted_at DESC").
+		First(&prior).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, 0, nil
+	}
+	if err != nil {
+		return nil, 0, fmt.Errorf("find cached results: %w", err)
+	}
+
+	byTestCase := make(map[string]models.SubmissionResult, len(prior.Results))
+	for _, result := range prior.Results {
+		byTestCase[result.TestCaseID] = result
+	}
+	results := make([]models.SubmissionResult, 0, len(testCases))
+	passed := 0
+	for _, tc := range testCases {
+		result, ok := byTestCase[tc.TestCaseID]
+		if !ok {
+			return nil, 0, nil
+		}
+		result.ResultID = ""
+		result.SubmissionID = submissionID
+		result.CreatedAt = time.Time{}
+		if result.Status == "passed" {
+			passed++
+		}
+		results = append(results, result)
+	}
+	return results, passed, nil
+}
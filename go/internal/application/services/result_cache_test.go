@@ -0,0 +1,154 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	models "github.com/Project-DSView/backend/go/internal/domain/entities"
+	"github.com/Project-DSView/backend/go/internal/domain/enums"
+	"github.com/Project-DSView/backend/go/internal/types"
+)
+
+// cacheTestCases returns two test cases with fixed IDs, in grading order
+func cacheTestCases() []models.TestCase {
+	return []models.TestCase{
+		{TestCaseID: "tc-1", InputData: types.JSONData(`[1,2]`), ExpectedOutput: types.JSONData(`{"output":3}`)},
+		{TestCaseID: "tc-2", InputData: types.JSONData(`[2,2]`), ExpectedOutput: types.JSONData(`{"output":4}`)},
+	}
+}
+
+func TestHashTestSet(t *testing.T) {
+	base := hashTestSet(&models.CodeExercise{}, cacheTestCases(), "python:3.12")
+	if again := hashTestSet(&models.CodeExercise{}, cacheTestCases(), "python:3.12"); again != base {
+		t.Fatalf("hash of an identical test set = %s, want %s", again, base)
+	}
+
+	off, tolerance, inputKey := false, 0.5, "inputs/big.json"
+	tests := []struct {
+		name      string
+		exercise  models.CodeExercise
+		testCases func([]models.TestCase) []models.TestCase
+		image     string
+	}{
+		{name: "input edited", testCases: func(tcs []models.TestCase) []models.TestCase {
+			tcs[0].InputData = types.JSONData(`[1,3]`)
+			return tcs
+		}},
+		{name: "expected output edited", testCases: func(tcs []models.TestCase) []models.TestCase {
+			tcs[1].ExpectedOutput = types.JSONData(`{"output":5}`)
+			return tcs
+		}},
+		{name: "stored input set", testCases: func(tcs []models.TestCase) []models.TestCase {
+			tcs[0].InputKey = &inputKey
+			return tcs
+		}},
+		{name: "test case added", testCases: func(tcs []models.TestCase) []models.TestCase {
+			return append(tcs, models.TestCase{TestCaseID: "tc-3", InputData: types.JSONData(`[0,0]`), ExpectedOutput: types.JSONData(`{"output":0}`)})
+		}},
+		{name: "test case removed", testCases: func(tcs []models.TestCase) []models.TestCase { return tcs[:1] }},
+		{name: "test cases reordered", testCases: func(tcs []models.TestCase) []models.TestCase {
+			return []models.TestCase{tcs[1], tcs[0]}
+		}},
+		{name: "output mode", exercise: models.CodeExercise{OutputMode: string(enums.OutputModePlain)}},
+		{name: "whitespace kept", exercise: models.CodeExercise{TrimWhitespace: &off}},
+		{name: "number tolerance", exercise: models.CodeExercise{NumberTolerance: &tolerance}},
+		{name: "executor image", image: "python:3.13"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testCases := cacheTestCases()
+			if tt.testCases != nil {
+				testCases = tt.testCases(testCases)
+			}
+			image := "python:3.12"
+			if tt.image != "" {
+				image = tt.image
+			}
+			if got := hashTestSet(&tt.exercise, testCases, image); got == base {
+				t.Errorf("hashTestSet() did not change")
+			}
+		})
+	}
+}
+
+func TestReusableResults(t *testing.T) {
+	tests := []struct {
+		name    string
+		results []models.SubmissionResult
+		want    bool
+	}{
+		{name: "every case graded", results: []models.SubmissionResult{{Status: "passed"}, {Status: "failed", ErrorMessage: "Wrong answer"}}, want: true},
+		{name: "case missing", results: []models.SubmissionResult{{Status: "passed"}}, want: false},
+		{name: "executor error", results: []models.SubmissionResult{{Status: "passed"}, {Status: "error", ErrorMessage: executionErrorPrefix + "docker daemon"}}, want: false},
+		{name: "timed out", results: []models.SubmissionResult{{Status: "passed"}, {Status: "error", ErrorMessage: executionTimedOutMessage}}, want: false},
+	}
+	for _, tt := range tests {
+		if got := reusableResults(tt.results, cacheTestCases()); got != tt.want {
+			t.Errorf("%s: reusableResults() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+// TestCachedResults checks that results are served only from a graded submission with the
+// same code and test set, and are copied for the new submission in test case order
+func TestCachedResults(t *testing.T) {
+	codeHash, otherCode := hashCode("print(3)"), hashCode("print(4)")
+	testSet := hashTestSet(&models.CodeExercise{}, cacheTestCases(), "python:3.12")
+	editedTestCases := cacheTestCases()
+	editedTestCases[0].ExpectedOutput = types.JSONData(`{"output":30}`)
+	editedSet := hashTestSet(&models.CodeExercise{}, editedTestCases, "python:3.12")
+
+	tests := []struct {
+		name       string
+		codeHash   string
+		testSet    string
+		testCases  []models.TestCase
+		wantHit    bool
+		wantPassed int
+	}{
+		{name: "same code and test set", codeHash: codeHash, testSet: testSet, testCases: cacheTestCases(), wantHit: true, wantPassed: 1},
+		{name: "code changed", codeHash: otherCode, testSet: testSet, testCases: cacheTestCases()},
+		{name: "test case edited", codeHash: codeHash, testSet: editedSet, testCases: editedTestCases},
+		{name: "test case added since", codeHash: codeHash, testSet: testSet,
+			testCases: append(cacheTestCases(), models.TestCase{TestCaseID: "tc-3"})},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := newTestDB(t, &models.Submission{}, &models.SubmissionResult{})
+			submittedAt := time.Now().Add(-time.Hour)
+			createRows(t, db,
+				&models.Submission{SubmissionID: "prior", UserID: "student-1", MaterialID: "code-1", Status: enums.SubmissionPending,
+					CodeHash: &codeHash, TestSetHash: &testSet, SubmittedAt: submittedAt},
+				// A cancelled run never finished grading, so it is never served
+				&models.Submission{SubmissionID: "cancelled", UserID: "student-2", MaterialID: "code-1", Status: enums.SubmissionCancelled,
+					CodeHash: &codeHash, TestSetHash: &editedSet, SubmittedAt: submittedAt},
+				&models.SubmissionResult{SubmissionID: "prior", TestCaseID: "tc-2", Status: "failed", ErrorMessage: "Wrong answer"},
+				&models.SubmissionResult{SubmissionID: "prior", TestCaseID: "tc-1", Status: "passed"},
+				&models.SubmissionResult{SubmissionID: "cancelled", TestCaseID: "tc-1", Status: "passed"},
+				&models.SubmissionResult{SubmissionID: "cancelled", TestCaseID: "tc-2", Status: "passed"},
+			)
+			svc := NewSubmissionService(db, nil, nil, nil, nil, nil, nil, nil, nil)
+
+			results, passed, err := svc.cachedResults("new", "code-1", tt.codeHash, tt.testSet, tt.testCases)
+			if err != nil {
+				t.Fatalf("cachedResults() error = %v", err)
+			}
+			if hit := results != nil; hit != tt.wantHit {
+				t.Fatalf("cache hit = %v, want %v", hit, tt.wantHit)
+			}
+			if !tt.wantHit {
+				return
+			}
+			if passed != tt.wantPassed {
+				t.Errorf("passed = %d, want %d", passed, tt.wantPassed)
+			}
+			for i, result := range results {
+				if result.TestCaseID != tt.testCases[i].TestCaseID || result.SubmissionID != "new" || result.ResultID != "" {
+					t.Errorf("result %d = %+v, want a copy of %s for submission new", i, result, tt.testCases[i].TestCaseID)
+				}
+			}
+		})
+	}
+}
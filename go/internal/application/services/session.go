@@ -0,0 +1,215 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	models "github.com/Project-DSView/backend/go/internal/domain/entities"
+	"github.com/Project-DSView/backend/go/pkg/logger"
+	"gorm.io/gorm"
+)
+
+// sessionMaxIdle matches the 30-day limit already applied to stored refresh tokens
+const sessionMaxIdle = 30 * 24 * time.Hour
+
+// defaultRevocationCacheTTL is how long a session's revoked_at lookup is reused. A
+// session revoked by another instance is rejected here at most this long afterwards.
+const defaultRevocationCacheTTL = 15 * time.Second
+
+var (
+	ErrSessionNotFound = errors.New("session not found")
+	ErrSessionRevoked  = errors.New("session has been revoked")
+)
+
+type SessionService struct {
+	db *gorm.DB
+	// accessTokenTTL is how long an already-issued JWT stays valid, and so how
+	// long a revoked session has to stay cached as revoked.
+	accessTokenTTL time.Duration
+	// revocationCacheTTL is how long a session found not revoked is trusted
+	// before user_sessions is read again
+	revocationCacheTTL time.Duration
+
+	mu         sync.RWMutex
+	revocation map[string]sessionRevocation // session ID -> cached revoked_at lookup
+}
+
+// sessionRevocation is a cached answer of IsSessionRevoked
+type sessionRevocation struct {
+	revoked bool
+	until   time.Time // when the answer has to be looked up again
+}
+
+func NewSessionService(db *gorm.DB, accessTokenTTL time.Duration) *SessionService {
+	return &SessionService{
+		db:                 db,
+		accessTokenTTL:     accessTokenTTL,
+		revocationCacheTTL: defaultRevocationCacheTTL,
+		revocation:         make(map[string]sessionRevocation),
+	}
+}
+
+// CreateSession records a new signed-in device for the user
+func (s *SessionService) CreateSession(userID, refreshToken, userAgent, ipAddress string) (*models.UserSession, error) {
+	session := &models.UserSession{
+		UserID:       userID,
+		RefreshToken: refreshToken,
+		UserAgent:    userAgent,
+		IPAddress:    ipAddress,
+		LastUsedAt:   time.Now(),
+	}
+	if err := s.db.Create(session).Error; err != nil {
+		return nil, fmt.Errorf("failed to create session: %w", err)
+	}
+	return session, nil
+}
+
+// GetActiveSession returns the session if it belongs to the user and can still be refreshed
+func (s *SessionService) GetActiveSession(userID, sessionID string) (*models.UserSession, error) {
+	var session models.UserSession
+	if err := s.db.Where("session_id = ? AND user_id = ?", sessionID, userID).First(&session).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrSessionNotFound
+		}
+		return nil, fmt.Errorf("failed to get session: %w", err)
+	}
+	if !session.IsActive(sessionMaxIdle) {
+		return nil, ErrSessionRevoked
+	}
+	return &session, nil
+}
+
+// TouchSession marks the session as used, replacing the refresh token if Google issued a new one
+func (s *SessionService) TouchSession(sessionID, refreshToken string) error {
+	updates := map[string]interface{}{
+		"last_used_at": time.Now(),
+	}
+	if refreshToken != "" {
+		updates["refresh_token"] = refreshToken
+	}
+	return s.db.Model(&models.UserSession{}).Where("session_id = ?", sessionID).Updates(updates).Error
+}
+
+// ListActiveSessions returns the user's sessions that have not been revoked or gone idle, newest first
+func (s *SessionService) ListActiveSessions(userID string) ([]models.UserSession, error) {
+	var sessions []models.UserSession
+	err := s.db.Where("user_id = ? AND revoked_at IS NULL AND last_used_at > ?", userID, time.Now().Add(-sessionMaxIdle)).
+		Order("last_used_at DESC").
+		Find(&sessions).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sessions: %w", err)
+	}
+	return sessions, nil
+}
+
+// RevokeSession revokes a single session of the user. Later refreshes fail and
+// access tokens already issued for it are rejected straight away.
+func (s *SessionService) RevokeSession(userID, sessionID string) error {
+	now := time.Now()
+	result := s.db.Model(&models.UserSession{}).
+		Where("session_id = ? AND user_id = ? AND revoked_at IS NULL", sessionID, userID).
+		Updates(map[string]interface{}{"revoked_at": now, "refresh_token": nil})
+	if result.Error != nil {
+		return fmt.Errorf("failed to revoke session: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrSessionNotFound
+	}
+	s.cacheRevoked([]string{sessionID})
+	return nil
+}
+
+// RevokeAllSessions revokes every active session of the user and returns how many were revoked
+func (s *SessionService) RevokeAllSessions(userID string) (int, error) {
+	var sessionIDs []string
+	if err := s.db.Model(&models.UserSession{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Pluck("session_id", &sessionIDs).Error; err != nil {
+		return 0, fmt.Errorf("failed to list sessions: %w", err)
+	}
+	if len(sessionIDs) == 0 {
+		return 0, nil
+	}
+
+	if err := s.db.Model(&models.UserSession{}).
+		Where("session_id IN ?", sessionIDs).
+		Updates(map[string]interface{}{"revoked_at": time.Now(), "refresh_token": nil}).Error; err != nil {
+		return 0, fmt.Errorf("failed to revoke sessions: %w", err)
+	}
+	s.cacheRevoked(sessionIDs)
+	return len(sessionIDs), nil
+}
+
+// IsSessionRevoked reports whether access tokens for the session must be rejected. It
+// reads revoked_at from user_sessions, so revocations made by any instance, or before a
+// restart, are seen; answers are cached for a short time to keep the lookup off most
+// requests. A session that no longer exists counts as revoked. If the lookup fails the
+// token is accepted, as it was issued for a session that was valid when signed.
+func (s *SessionService) IsSessionRevoked(sessionID string) bool {
+	now := time.Now()
+	s.mu.RLock()
+	cached, ok := s.revocation[sessionID]
+	s.mu.RUnlock()
+	if ok && now.Before(cached.until) {
+		return cached.revoked
+	}
+
+	var sessions []models.UserSession
+	if err := s.db.Select("session_id", "revoked_at").
+		Where("session_id = ?", sessionID).
+		Limit(1).
+		Find(&sessions).Error; err != nil {
+		logger.Warnf("Failed to check revocation of session %s: %v", sessionID, err)
+		return false
+	}
+	revoked := len(sessions) == 0 || sessions[0].RevokedAt != nil
+
+	ttl := s.revocationCacheTTL
+	if revoked {
+		ttl = s.accessTokenTTL
+	}
+	s.cacheRevocation(map[string]bool{sessionID: revoked}, now.Add(ttl))
+	return revoked
+}
+
+// cacheRevoked records sessions this instance revoked, so their tokens are rejected
+// here without waiting for the cache to expire
+func (s *SessionService) cacheRevoked(sessionIDs []string) {
+	revoked := make(map[string]bool, len(sessionIDs))
+	for _, id := range sessionIDs {
+		revoked[id] = true
+	}
+	s.cacheRevocation(revoked, time.Now().Add(s.accessTokenTTL))
+}
+
+// cacheRevocation stores lookups valid until the given time, dropping expired ones
+func (s *SessionService) cacheRevocation(revoked map[string]bool, until time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	for id, cached := range s.revocation {
+		if now.After(cached.until) {
+			delete(s.revocation, id)
+		}
+	}
+	for id, isRevoked := range revoked {
+		s.revocation[id] = sessionRevocation{revoked: isRevoked, until: until}
+	}
+}
+
+// CanManageSessions reports whether the teacher may view or revoke the user's
+// sessions: the user must be enrolled in a course the teacher created.
+func (s *SessionService) CanManageSessions(teacherID, userID string) (bool, error) {
+	var count int64
+	err := s.db.Table("enrollments").
+		Joins("JOIN courses ON courses.course_id = enrollments.course_id").
+		Where("enrollments.user_id = ? AND courses.created_by = ?", userID, teacherID).
+		Count(&count).Error
+	if err != nil {
+		return false, fmt.Errorf("failed to check enrollment: %w", err)
+	}
+	return count > 0, nil
+}
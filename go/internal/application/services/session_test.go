@@ -0,0 +1,104 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	models "github.com/Project-DSView/backend/go/internal/domain/entities"
+)
+
+func TestIsSessionRevoked(t *testing.T) {
+	tests := []struct {
+		name string
+		// revoke revokes the session, through svc or behind its back like another instance
+		revoke func(t *testing.T, svc *SessionService, session *models.UserSession)
+		// cacheTTL is how long svc trusts a session it found not revoked
+		cacheTTL time.Duration
+		want     bool
+	}{
+		{
+			name:     "active session",
+			revoke:   func(*testing.T, *SessionService, *models.UserSession) {},
+			cacheTTL: time.Hour,
+			want:     false,
+		},
+		{
+			name: "revoked through this service",
+			revoke: func(t *testing.T, svc *SessionService, session *models.UserSession) {
+				if err := svc.RevokeSession(session.UserID, session.SessionID); err != nil {
+					t.Fatalf("RevokeSession() error = %v", err)
+				}
+			},
+			cacheTTL: time.Hour,
+			want:     true,
+		},
+		{
+			name: "revoked by another instance after the cache expired",
+			revoke: func(t *testing.T, svc *SessionService, session *models.UserSession) {
+				if err := svc.db.Model(session).Update("revoked_at", time.Now()).Error; err != nil {
+					t.Fatalf("revoke session: %v", err)
+				}
+			},
+			cacheTTL: 0,
+			want:     true,
+		},
+		{
+			name: "revoked by another instance within the cache ttl",
+			revoke: func(t *testing.T, svc *SessionService, session *models.UserSession) {
+				if err := svc.db.Model(session).Update("revoked_at", time.Now()).Error; err != nil {
+					t.Fatalf("revoke session: %v", err)
+				}
+			},
+			cacheTTL: time.Hour,
+			want:     false,
+		},
+		{
+			name: "deleted session",
+			revoke: func(t *testing.T, svc *SessionService, session *models.UserSession) {
+				if err := svc.db.Delete(session).Error; err != nil {
+					t.Fatalf("delete session: %v", err)
+				}
+			},
+			cacheTTL: 0,
+			want:     true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := newTestDB(t, &models.UserSession{})
+			svc := NewSessionService(db, time.Hour)
+			svc.revocationCacheTTL = tt.cacheTTL
+
+			session, err := svc.CreateSession("user-1", "refresh", "test", "127.0.0.1")
+			if err != nil {
+				t.Fatalf("CreateSession() error = %v", err)
+			}
+			if svc.IsSessionRevoked(session.SessionID) {
+				t.Fatalf("IsSessionRevoked() = true for a new session")
+			}
+
+			tt.revoke(t, svc, session)
+			if got := svc.IsSessionRevoked(session.SessionID); got != tt.want {
+				t.Errorf("IsSessionRevoked() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsSessionRevokedAfterRestart(t *testing.T) {
+	db := newTestDB(t, &models.UserSession{})
+	before := NewSessionService(db, time.Hour)
+	session, err := before.CreateSession("user-1", "refresh", "test", "127.0.0.1")
+	if err != nil {
+		t.Fatalf("CreateSession() error = %v", err)
+	}
+	if err := before.RevokeSession("user-1", session.SessionID); err != nil {
+		t.Fatalf("RevokeSession() error = %v", err)
+	}
+
+	after := NewSessionService(db, time.Hour)
+	if !after.IsSessionRevoked(session.SessionID) {
+		t.Errorf("IsSessionRevoked() = false on a new service for a revoked session")
+	}
+}
@@ -1,9 +1,12 @@
 package services
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"mime/multipart"
 	"strings"
 	"time"
@@ -11,12 +14,30 @@ import (
 	models "github.com/Project-DSView/backend/go/internal/domain/entities"
 	"github.com/Project-DSView/backend/go/internal/domain/enums"
 	"github.com/Project-DSView/backend/go/internal/types"
+	"github.com/Project-DSView/backend/go/pkg/authz"
 	"github.com/Project-DSView/backend/go/pkg/external"
 	"github.com/Project-DSView/backend/go/pkg/logger"
 	"github.com/Project-DSView/backend/go/pkg/storage"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+// ErrResubmissionLocked is returned when an exercise with LockAfterApproval is resubmitted after approval
+var ErrResubmissionLocked = errors.New("your work on this exercise has been approved and it does not accept resubmissions")
+
+// ErrSubmissionInProgress is returned when a student resubmits while their previous submission is still queued or running
+var ErrSubmissionInProgress = errors.New("you already have a submission processing for this exercise")
+
+// Errors returned by CheckSubmissionType
+var (
+	ErrMaterialNotFound = errors.New("material not found")
+	ErrNotCodeExercise  = errors.New("this material does not accept code submissions")
+	ErrNotPDFExercise   = errors.New("this material does not accept PDF submissions")
+)
+
+// ErrUnsupportedLanguage is returned by Validate for a language the executor does not run
+var ErrUnsupportedLanguage = errors.New("language is not supported by the executor")
+
 type SubmissionService struct {
 	db                 *gorm.DB
 	testCaseService    *TestCaseService
@@ -27,6 +48,7 @@ type SubmissionService struct {
 	exec               *external.DockerExecutor
 	storageService     storage.StorageService
 	queueService       *QueueService
+	maxPDFPages        int // Default page limit for PDF submissions; 0 = unlimited
 }
 
 func NewSubmissionService(
@@ -53,6 +75,106 @@ func NewSubmissionService(
 	}
 }
 
+// SetMaxPDFPages sets the default page limit for PDF exercises that do not set their own
+func (s *SubmissionService) SetMaxPDFPages(maxPages int) {
+	s.maxPDFPages = maxPages
+}
+
+// ContainerUsage reports how many executor containers are running and waiting on this host
+func (s *SubmissionService) ContainerUsage() external.ContainerUsage {
+	return s.exec.ContainerUsage()
+}
+
+// ExecutorCapabilities reports the languages code exercises can be written in and the
+// limits their runs and test cases are held to
+func (s *SubmissionService) ExecutorCapabilities() *types.ExecutorCapabilities {
+	limits := s.testCaseService.Limits()
+	return &types.ExecutorCapabilities{
+		ExecutorCapabilities:   s.exec.Capabilities(),
+		MaxTestCaseInputBytes:  limits.MaxInputBytes,
+		MaxTestCaseOutputBytes: limits.MaxOutputBytes,
+	}
+}
+
+// Validate compiles code in the executor without running it or any test case, and returns
+// the errors found with their positions; none means the code compiles. Nothing is saved
+// and the queue is not used, so it suits a quick "check syntax" before submitting.
+// Cancelling ctx stops the check, also while it waits for a container slot.
+func (s *SubmissionService) Validate(ctx context.Context, language, code string) ([]external.Diagnostic, error) {
+	supported := false
+	for _, l := range s.exec.Capabilities().Languages {
+		if l.Name == language {
+			supported = true
+			break
+		}
+	}
+	if !supported {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedLanguage, language)
+	}
+
+	diagnostics, err := s.exec.CheckSyntax(ctx, code)
+	if err != nil {
+		return nil, fmt.Errorf("check syntax: %w", err)
+	}
+	return diagnostics, nil
+}
+
+// checkResubmissionAllowed rejects a new submission when the exercise locks after
+// approval and the student's progress on it is already completed
+func checkResubmissionAllowed(db *gorm.DB, userID, materialID string, lockAfterApproval bool) error {
+	if !lockAfterApproval {
+		return nil
+	}
+	var completed int64
+	if err := db.Model(&models.StudentProgress{}).
+		Where("user_id = ? AND material_id = ? AND status = ?", userID, materialID, enums.ProgressCompleted).
+		Count(&completed).Error; err != nil {
+		return fmt.Errorf("check progress: %w", err)
+	}
+	if completed > 0 {
+		return ErrResubmissionLocked
+	}
+	return nil
+}
+
+// checkNoActiveSubmission rejects a resubmission while the student's previous submission
+// is still waiting in or running through the queue, so slow grading is not answered with
+// more work. A job stuck past the retry window no longer blocks; the resubmission cancels it.
+func (s *SubmissionService) checkNoActiveSubmission(userID, materialID string) error {
+	if s.queueService == nil {
+		return nil
+	}
+	active, err := s.queueService.ActiveCodeExecutionJob(userID, materialID)
+	if err != nil {
+		return err
+	}
+	if active != nil {
+		return fmt.Errorf("%w (job %s)", ErrSubmissionInProgress, active.ID)
+	}
+	return nil
+}
+
+// checkPDFResubmissionAllowed applies checkResubmissionAllowed using the PDF exercise's policy
+func checkPDFResubmissionAllowed(db *gorm.DB, userID, materialID string) error {
+	var exercise models.PDFExercise
+	if err := db.Select("lock_after_approval").Where("material_id = ?", materialID).Take(&exercise).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil
+		}
+		return fmt.Errorf("get PDF exercise: %w", err)
+	}
+	return checkResubmissionAllowed(db, userID, materialID, exercise.LockAfterApproval)
+}
+
+// claimPDFAttempt applies claimAttempt using the PDF exercise's limit
+func claimPDFAttempt(db *gorm.DB, userID, materialID string) error {
+	var exercise models.PDFExercise
+	if err := db.Select("max_attempts").Where("material_id = ?", materialID).Take(&exercise).Error; err != nil {
+		return fmt.Errorf("get PDF exercise: %w", err)
+	}
+	return claimAttempt(db, userID, materialID, enums.MaterialTypePDFExercise, exercise.MaxAttempts)
+}
+
 // SubmitResult is now defined in internal/types/services.go
 
 // getValueType returns a human-readable type description
@@ -63,9 +185,7 @@ func getValueType(value interface{}) string {
 	case bool:
 		return "boolean"
 	case float64:
-		if v == float64(int64(v)) {
-			return "integer"
-		}
+		// 1 and 1.0 are the same JSON number, so integers are not a separate type
 		return "number"
 	case string:
 		return "string"
@@ -79,7 +199,7 @@ func getValueType(value interface{}) string {
 }
 
 // analyzeFailureReason provides detailed analysis of why a test case failed
-func analyzeFailureReason(expected, actual interface{}) string {
+func analyzeFailureReason(expected, actual interface{}, opts external.CompareOptions) string {
 	expectedType := getValueType(expected)
 	actualType := getValueType(actual)
 
@@ -95,7 +215,7 @@ func analyzeFailureReason(expected, actual interface{}) string {
 			}
 			// Find first differing element
 			for i := 0; i < len(exp) && i < len(act); i++ {
-				if !external.CompareJSON(exp[i], act[i]) {
+				if !external.CompareJSONWith(exp[i], act[i], opts) {
 					return fmt.Sprintf("Array element at index %d differs: expected %v, got %v",
 						i, exp[i], act[i])
 				}
@@ -118,7 +238,7 @@ func analyzeFailureReason(expected, actual interface{}) string {
 			// Check for different values
 			for key, expectedVal := range exp {
 				if actualVal, exists := act[key]; exists {
-					if !external.CompareJSON(expectedVal, actualVal) {
+					if !external.CompareJSONWith(expectedVal, actualVal, opts) {
 						return fmt.Sprintf("Value mismatch for key '%s': expected %v, got %v",
 							key, expectedVal, actualVal)
 					}
@@ -159,13 +279,6 @@ func (s *SubmissionService) ListExerciseSubmissions(exerciseID string, f types.S
 }
 
 func (s *SubmissionService) GetSubmissionByID(id string) (*models.Submission, error) {
-	// Cleanup old submissions before querying (async, don't wait for result)
-	go func() {
-		if err := s.CleanupOldSubmissions(); err != nil {
-			logger.Warnf("Failed to cleanup old submissions: %v", err)
-		}
-	}()
-
 	var sub models.Submission
 	if err := s.db.Preload("Results").
 		Where("submission_id = ?", id).
@@ -178,9 +291,81 @@ func (s *SubmissionService) GetSubmissionByID(id string) (*models.Submission, er
 	return &sub, nil
 }
 
+// CheckSubmissionType verifies that a material exists and is of materialType, the kind
+// of exercise a submission endpoint accepts. Handlers call it before reading the
+// submission so that code sent to a PDF exercise (or the reverse) gets a clear error.
+func (s *SubmissionService) CheckSubmissionType(materialID string, materialType enums.MaterialType) error {
+	return checkSubmissionType(s.db, materialID, materialType)
+}
+
+// checkSubmissionType returns ErrMaterialNotFound, or ErrNotCodeExercise/ErrNotPDFExercise
+// when the material is not of materialType
+func checkSubmissionType(db *gorm.DB, materialID string, materialType enums.MaterialType) error {
+	var material models.CourseMaterial
+	if err := db.Select("material_id", "type").First(&material, "material_id = ?", materialID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrMaterialNotFound
+		}
+		return fmt.Errorf("get material: %w", err)
+	}
+
+	if material.Type == materialType {
+		return nil
+	}
+	if materialType == enums.MaterialTypePDFExercise {
+		return ErrNotPDFExercise
+	}
+	return ErrNotCodeExercise
+}
+
 // SubmitMaterialExercise submits code for a material-based exercise (new system)
 func (s *SubmissionService) SubmitMaterialExercise(
 	userID, materialID, code string,
+) (*types.SubmitResult, error) {
+	return s.submitMaterialCode(userID, materialID, code, "")
+}
+
+// SubmitOnBehalf lets a course teacher submit code attributed to a student, e.g. when
+// the student's environment is broken. The submission is graded and updates progress
+// like a normal one; the acting teacher is kept in SubmittedBy for auditing.
+// The deadline is not enforced, but a submission past it is still marked late.
+func (s *SubmissionService) SubmitOnBehalf(
+	teacherID, studentID, materialID, code string,
+) (*types.SubmitResult, error) {
+	var material models.CourseMaterial
+	if err := s.db.First(&material, "material_id = ?", materialID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("material not found")
+		}
+		return nil, fmt.Errorf("get material: %w", err)
+	}
+
+	canManage, err := authz.CanManageCourse(s.db, teacherID, material.CourseID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get course details: %w", err)
+	}
+	if !canManage {
+		return nil, fmt.Errorf("only course creator can submit on behalf of students")
+	}
+
+	var enrolled int64
+	if err := s.db.Model(&models.Enrollment{}).
+		Where("course_id = ? AND user_id = ? AND role = ?", material.CourseID, studentID, enums.EnrollmentRoleStudent).
+		Count(&enrolled).Error; err != nil {
+		return nil, fmt.Errorf("check enrollment: %w", err)
+	}
+	if enrolled == 0 {
+		return nil, fmt.Errorf("student is not enrolled in this course")
+	}
+
+	logger.Infof("Teacher %s is submitting material %s on behalf of student %s", teacherID, materialID, studentID)
+	return s.submitMaterialCode(studentID, materialID, code, teacherID)
+}
+
+// submitMaterialCode records and grades a code submission for userID.
+// actingTeacherID is empty for a student's own submission.
+func (s *SubmissionService) submitMaterialCode(
+	userID, materialID, code, actingTeacherID string,
 ) (*types.SubmitResult, error) {
 	// Get material with test cases
 	material, testCases, err := s.materialService.GetCourseMaterialWithTestCases(materialID)
@@ -190,7 +375,7 @@ func (s *SubmissionService) SubmitMaterialExercise(
 
 	// Validate material is a code exercise
 	if !material.IsCodeExercise() {
-		return nil, fmt.Errorf("material is not a code exercise")
+		return nil, ErrNotCodeExercise
 	}
 
 	// Check if material has test cases (only code exercises have test cases)
@@ -209,25 +394,52 @@ func (s *SubmissionService) SubmitMaterialExercise(
 	if err := s.db.First(&course, "course_id = ?", material.CourseID).Error; err != nil {
 		return nil, fmt.Errorf("failed to get course details: %w", err)
 	}
+	if course.Status == enums.CourseStatusArchived {
+		return nil, ErrCourseArchived
+	}
 
-	// Check deadline (if material has deadline)
-	var isLateSubmission bool
-	if codeExercise.Deadline != nil {
-		canSubmit, message, err := s.deadlineService.CanSubmitMaterial(userID, materialID)
-		if err != nil {
-			return nil, fmt.Errorf("check deadline: %w", err)
+	// Teachers submitting on behalf of a student may override the lock and the
+	// prerequisites, as with the deadline
+	if actingTeacherID == "" {
+		if err := checkResubmissionAllowed(s.db, userID, materialID, codeExercise.LockAfterApproval); err != nil {
+			return nil, err
 		}
-		if !canSubmit {
-			return nil, fmt.Errorf("cannot submit: %s", message)
+		if err := checkPrerequisitesMet(s.db, userID, materialID); err != nil {
+			return nil, err
 		}
-		// Mark as late submission if message indicates it
-		if message != "" {
-			isLateSubmission = true
+		if err := s.checkNoActiveSubmission(userID, materialID); err != nil {
+			return nil, err
 		}
 	}
 
+	// Check deadline and availability the same way as PDF submissions
+	canSubmit, message, err := s.deadlineService.CanSubmitMaterial(userID, materialID)
+	if err != nil {
+		return nil, fmt.Errorf("check deadline: %w", err)
+	}
+	if !canSubmit && actingTeacherID == "" {
+		return nil, fmt.Errorf("%w: %s", ErrSubmissionNotAllowed, message)
+	}
+	// Submissions past the deadline, whether inside a submission window or by a teacher
+	// override, are recorded as late rather than blocked
+	isLateSubmission := message == deadlinePassedMessage
+
+	// Claimed last so a submission rejected above does not use up an attempt, and given
+	// back if the submission cannot be stored. A teacher submitting on behalf of a
+	// student still counts, but is not capped
+	maxAttempts := codeExercise.MaxAttempts
+	if actingTeacherID != "" {
+		maxAttempts = 0
+	}
+	if err := claimAttempt(s.db, userID, materialID, enums.MaterialTypeCodeExercise, maxAttempts); err != nil {
+		return nil, err
+	}
+	stored := false
+	defer refundUnstoredAttempt(s.db, userID, materialID, &stored)
+
 	// Cancel pending/processing queue jobs and reset progress status for resubmission
 	// Keep old submissions for history
+	var priorProgressStatus *enums.ProgressStatus
 	err = s.db.Transaction(func(tx *gorm.DB) error {
 		// Cancel pending and processing queue jobs for this material (if any)
 		// This allows resubmission to work properly
@@ -241,6 +453,12 @@ func (s *SubmissionService) SubmitMaterialExercise(
 			logger.Warnf("Failed to cancel queue jobs: %v", err)
 		}
 
+		// Remember the status being reset, to restore it if this submission is cancelled
+		var prog models.StudentProgress
+		if err := tx.Select("status").Where("user_id = ? AND material_id = ?", userID, materialID).Take(&prog).Error; err == nil {
+			priorProgressStatus = &prog.Status
+		}
+
 		// Reset progress status to in_progress if it was waiting_approval or completed
 		// This allows the student to request approval again after resubmission
 		if err := tx.Model(&models.StudentProgress{}).
@@ -294,11 +512,17 @@ func (s *SubmissionService) SubmitMaterialExercise(
 		Status:           enums.SubmissionRunning,
 		IsLateSubmission: isLateSubmission,
 		SubmittedAt:      time.Now(),
+
+		PriorProgressStatus: priorProgressStatus,
+	}
+	if actingTeacherID != "" {
+		sub.SubmittedBy = &actingTeacherID
 	}
 
 	if err := s.db.Create(sub).Error; err != nil {
 		return nil, fmt.Errorf("create submission: %w", err)
 	}
+	stored = true
 
 	// Update submission status to running
 	if err := s.db.Model(&models.Submission{}).
@@ -307,9 +531,12 @@ func (s *SubmissionService) SubmitMaterialExercise(
 		return nil, fmt.Errorf("update submission status: %w", err)
 	}
 
-	// Submit to code execution queue (async processing)
+	// Submit to code execution queue (async processing). The queue job ID is only set
+	// when the job was accepted; every other path grades synchronously below.
+	var jobID string
+	var queuePosition int
 	if s.queueService != nil {
-		_, err := s.queueService.SubmitCodeExecutionJob(
+		job, err := s.queueService.SubmitCodeExecutionJob(
 			context.Background(),
 			userID,
 			materialID,
@@ -320,13 +547,18 @@ func (s *SubmissionService) SubmitMaterialExercise(
 		if err != nil {
 			// If queue submission fails, fall back to synchronous execution
 			logger.Warnf("Failed to submit to queue, falling back to synchronous execution: %v", err)
-			if err := s.ExecuteCodeSubmission(sub.SubmissionID, code, materialID); err != nil {
+			if err := s.ExecuteCodeSubmission(sub.SubmissionID, code, materialID); err != nil && !errors.Is(err, ErrSubmissionCancelled) {
 				return nil, fmt.Errorf("code execution failed: %w", err)
 			}
+		} else {
+			jobID = job.ID
+			if queuePosition, err = s.queueService.QueuePosition(job); err != nil {
+				logger.Warnf("Failed to get queue position of job %s: %v", job.ID, err)
+			}
 		}
 	} else {
 		// No queue available, execute synchronously
-		if err := s.ExecuteCodeSubmission(sub.SubmissionID, code, materialID); err != nil {
+		if err := s.ExecuteCodeSubmission(sub.SubmissionID, code, materialID); err != nil && !errors.Is(err, ErrSubmissionCancelled) {
 			return nil, fmt.Errorf("code execution failed: %w", err)
 		}
 	}
@@ -343,18 +575,155 @@ func (s *SubmissionService) SubmitMaterialExercise(
 	// Progress status is set to in_progress when all tests pass, ready for manual review request
 
 	return &types.SubmitResult{
-		Submission: &saved,
-		Results:    saved.Results,
+		Submission:    &saved,
+		Results:       saved.Results,
+		Queued:        jobID != "",
+		JobID:         jobID,
+		QueuePosition: queuePosition,
+	}, nil
+}
+
+// GetTestCasePassRates counts, for every test case of the material, how many students
+// passed or failed it on their latest graded submission. Test cases nobody has run yet
+// are included with zero counts.
+func (s *SubmissionService) GetTestCasePassRates(materialID string) ([]types.TestCasePassRate, error) {
+	latest := s.db.Table("submissions").
+		Select("DISTINCT ON (user_id) submission_id").
+		Where("material_id = ? AND status = ?", materialID, enums.SubmissionCompleted).
+		Order("user_id, submitted_at DESC")
+
+	var rates []types.TestCasePassRate
+	err := s.db.Table("test_cases AS tc").
+		Select(`tc.test_case_id, tc.display_name,
+			COUNT(sr.result_id) FILTER (WHERE sr.status = 'passed') AS passed_count,
+			COUNT(sr.result_id) FILTER (WHERE sr.status <> 'passed') AS failed_count`).
+		Joins("LEFT JOIN submission_results AS sr ON sr.test_case_id = tc.test_case_id AND sr.submission_id IN (?)", latest).
+		Where("tc.material_id = ?", materialID).
+		Group("tc.test_case_id, tc.display_name, tc.created_at").
+		Order("tc.created_at ASC").
+		Scan(&rates).Error
+	if err != nil {
+		return nil, fmt.Errorf("get test case pass rates: %w", err)
+	}
+
+	for i := range rates {
+		if total := rates[i].PassedCount + rates[i].FailedCount; total > 0 {
+			rates[i].PassRate = float64(rates[i].PassedCount) / float64(total)
+		}
+	}
+	return rates, nil
+}
+
+// codeFileExtensions maps the MIME type stored on a code submission to a file extension
+var codeFileExtensions = map[string]string{
+	"text/x-python": ".py",
+}
+
+// OpenSubmissionCode returns the source of a code submission as a downloadable file:
+// reader, filename, content type and size. The code stored on the submission is used
+// when present; otherwise the copy in storage is streamed.
+func (s *SubmissionService) OpenSubmissionCode(sub *models.Submission) (io.Reader, string, string, int64, error) {
+	if sub.Code == "" && sub.FileURL == "" {
+		return nil, "", "", 0, fmt.Errorf("submission has no code")
+	}
+	if sub.Code == "" && !strings.HasPrefix(sub.MimeType, "text/") {
+		return nil, "", "", 0, fmt.Errorf("submission has no code")
+	}
+
+	contentType := sub.MimeType
+	if contentType == "" {
+		contentType = "text/x-python"
+	}
+	ext, ok := codeFileExtensions[contentType]
+	if !ok {
+		ext = ".txt"
+	}
+
+	// Name the file after the student so downloads from several students don't collide
+	baseName := sub.SubmissionID
+	if user, err := s.userService.GetUserByID(sub.UserID); err == nil && user != nil {
+		shortID := sub.SubmissionID
+		if len(shortID) > 8 {
+			shortID = shortID[:8]
+		}
+		baseName = strings.SplitN(user.Email, "@", 2)[0] + "_" + shortID
+	}
+	filename := baseName + ext
+
+	if sub.Code != "" {
+		return strings.NewReader(sub.Code), filename, contentType + "; charset=utf-8", int64(len(sub.Code)), nil
+	}
+
+	reader, storedType, size, err := s.storageService.StreamStudentPDFSubmission(context.Background(), sub.FileURL)
+	if err != nil {
+		return nil, "", "", 0, fmt.Errorf("failed to stream code file: %w", err)
+	}
+	if storedType != "" {
+		contentType = storedType
+	}
+	return reader, filename, contentType, size, nil
+}
+
+// GetLatestCode returns the source of the student's most recent code submission to a
+// material, for showing inline, or nil when they have not submitted code
+func (s *SubmissionService) GetLatestCode(userID, materialID string) (*types.StudentCode, error) {
+	var sub models.Submission
+	if err := s.db.Where("user_id = ? AND material_id = ?", userID, materialID).
+		Where("code <> '' OR (file_url <> '' AND mime_type LIKE 'text/%')").
+		Order("submitted_at DESC").
+		First(&sub).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("get latest submission: %w", err)
+	}
+
+	code := sub.Code
+	if code == "" {
+		reader, _, _, _, err := s.OpenSubmissionCode(&sub)
+		if err != nil {
+			return nil, err
+		}
+		if closer, ok := reader.(io.Closer); ok {
+			defer closer.Close()
+		}
+		data, err := io.ReadAll(reader)
+		if err != nil {
+			return nil, fmt.Errorf("read code file: %w", err)
+		}
+		code = string(data)
+	}
+
+	return &types.StudentCode{
+		SubmissionID: sub.SubmissionID,
+		UserID:       sub.UserID,
+		MaterialID:   sub.MaterialID,
+		Code:         code,
+		Status:       string(sub.Status),
+		SubmittedAt:  sub.SubmittedAt,
 	}, nil
 }
 
 // ExecuteCodeSubmission executes code and runs test cases for a submission (called by queue worker)
 func (s *SubmissionService) ExecuteCodeSubmission(submissionID, code, materialID string) error {
+	return s.executeCodeSubmission(submissionID, code, materialID, false)
+}
+
+// ExecuteRejudge grades an existing submission again, replacing its previous results.
+// Progress is recomputed from the student's submissions instead of being treated as a new attempt.
+func (s *SubmissionService) ExecuteRejudge(submissionID, code, materialID string) error {
+	return s.executeCodeSubmission(submissionID, code, materialID, true)
+}
+
+func (s *SubmissionService) executeCodeSubmission(submissionID, code, materialID string, rejudge bool) error {
 	// Get submission
 	var sub models.Submission
 	if err := s.db.Where("submission_id = ?", submissionID).First(&sub).Error; err != nil {
 		return fmt.Errorf("get submission: %w", err)
 	}
+	if sub.Status == enums.SubmissionCancelled {
+		return ErrSubmissionCancelled
+	}
 
 	// Get material with test cases
 	material, testCases, err := s.materialService.GetCourseMaterialWithTestCases(materialID)
@@ -373,92 +742,42 @@ func (s *SubmissionService) ExecuteCodeSubmission(submissionID, code, materialID
 		return fmt.Errorf("failed to get code exercise details: %w", err)
 	}
 
-	// Run test cases
-	passed := 0
-	results := make([]models.SubmissionResult, 0, len(testCases))
 	totalPoints := 0
 	if codeExercise.TotalPoints != nil {
 		totalPoints = *codeExercise.TotalPoints
 	}
 
-	for i, tc := range testCases {
-
-		// input JSON for STDIN
-		stdinBytes, _ := json.Marshal(tc.InputData)
-
-		execRes, runErr := s.exec.RunPython(code, string(stdinBytes))
-
-		result := models.SubmissionResult{
-			SubmissionID: sub.SubmissionID,
-			TestCaseID:   tc.TestCaseID,
-		}
-
-		if runErr != nil {
-			result.Status = "error"
-			result.ErrorMessage = fmt.Sprintf("Execution error: %s", runErr.Error())
-
-		} else if execRes.TimedOut {
-			result.Status = "error"
-			result.ErrorMessage = "Code execution timed out. Your program may have an infinite loop or is taking too long to execute."
-
-		} else if execRes.ExitCode != 0 {
-			result.Status = "error"
-			errorMsg := "Your code encountered an error during execution."
-			if execRes.Stderr != "" {
-				errorMsg = fmt.Sprintf("Runtime error: %s", execRes.Stderr)
-			} else {
-				errorMsg = fmt.Sprintf("Your code exited with error code %d", execRes.ExitCode)
-			}
-			result.ErrorMessage = errorMsg
-
-		} else {
-			// parse student's stdout as JSON
-
-			var actual interface{}
-			if err := json.Unmarshal([]byte(execRes.Stdout), &actual); err != nil {
-				result.Status = "error"
-				result.ErrorMessage = fmt.Sprintf("Your code output is not valid JSON: %s\nOutput received: %s",
-					err.Error(), execRes.Stdout)
-
-			} else {
-				result.ActualOutput = types.JSONData{}
-				_ = json.Unmarshal([]byte(execRes.Stdout), &result.ActualOutput)
-
-				var expected interface{}
-				expected = tc.ExpectedOutput
-
-				if external.CompareJSON(expected, actual) {
-					result.Status = "passed"
-					passed++
-
-				} else {
-					result.Status = "failed"
-
-					// Create detailed error message
-					inputJSON, _ := json.Marshal(tc.InputData)
-					expectedJSON, _ := json.Marshal(expected)
-					actualJSON, _ := json.Marshal(actual)
-
-					failureReason := analyzeFailureReason(expected, actual)
-
-					result.ErrorMessage = fmt.Sprintf(
-						"Test case %d failed\n\n"+
-							"Input:\n%s\n\n"+
-							"Expected output:\n%s\n\n"+
-							"Your output:\n%s\n\n"+
-							"Reason: %s",
-						i+1,
-						string(inputJSON),
-						string(expectedJSON),
-						string(actualJSON),
-						failureReason,
-					)
-
-				}
+	// Identical code graded against the same test cases can reuse earlier results when the
+	// course opts in; a rejudge always runs the code again
+	codeHash := hashCode(code)
+	testSetHash := hashTestSet(&codeExercise, testCases, s.exec.Capabilities().Languages[0].Image)
+	var results []models.SubmissionResult
+	var passed int
+	if !rejudge {
+		if enabled, err := s.resultCacheEnabled(material.CourseID); err != nil {
+			logger.Warnf("Failed to check the result cache setting for submission %s: %v", sub.SubmissionID, err)
+		} else if enabled {
+			if results, passed, err = s.cachedResults(sub.SubmissionID, materialID, codeHash, testSetHash, testCases); err != nil {
+				logger.Warnf("Failed to look up cached results for submission %s: %v", sub.SubmissionID, err)
+			} else if results != nil {
+				logger.Infof("Reused cached results for submission %s", sub.SubmissionID)
 			}
 		}
+	}
 
-		results = append(results, result)
+	if results == nil {
+		// Run test cases as a tracked job, so Terminate can stop its container; done also runs on panic
+		ctx, done := s.exec.TrackJob(sub.SubmissionID)
+		defer done()
+		results, passed = s.gradeCode(ctx, sub.SubmissionID, code, &codeExercise, testCases)
+		if ctx.Err() != nil {
+			return ErrSubmissionCancelled
+		}
+	}
+	// Only results that would come out the same on another run are offered to the cache
+	var cacheCodeHash, cacheTestSetHash *string
+	if reusableResults(results, testCases) {
+		cacheCodeHash, cacheTestSetHash = &codeHash, &testSetHash
 	}
 
 	failed := len(testCases) - passed
@@ -466,6 +785,22 @@ func (s *SubmissionService) ExecuteCodeSubmission(submissionID, code, materialID
 
 	// persist results
 	if err := s.db.Transaction(func(tx *gorm.DB) error {
+		// The submission may have been cancelled on another instance while it ran
+		var current models.Submission
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("status").
+			Where("submission_id = ?", sub.SubmissionID).Take(&current).Error; err != nil {
+			return fmt.Errorf("get submission: %w", err)
+		}
+		if current.Status == enums.SubmissionCancelled {
+			return ErrSubmissionCancelled
+		}
+
+		if rejudge {
+			if err := tx.Where("submission_id = ?", sub.SubmissionID).Delete(&models.SubmissionResult{}).Error; err != nil {
+				return fmt.Errorf("delete previous results: %w", err)
+			}
+		}
+
 		// save results
 		if len(results) > 0 {
 			if err := tx.Create(&results).Error; err != nil {
@@ -478,6 +813,9 @@ func (s *SubmissionService) ExecuteCodeSubmission(submissionID, code, materialID
 			"failed_count": failed,
 			"total_score":  score,
 			"status":       enums.SubmissionPending,
+
+			"code_hash":     cacheCodeHash,
+			"test_set_hash": cacheTestSetHash,
 		}
 		if err := tx.Model(&models.Submission{}).
 			Where("submission_id = ?", sub.SubmissionID).
@@ -485,15 +823,23 @@ func (s *SubmissionService) ExecuteCodeSubmission(submissionID, code, materialID
 			return fmt.Errorf("update submission: %w", err)
 		}
 
+		if rejudge {
+			if err := s.recomputeProgressAfterRejudge(tx, &sub, codeExercise.MeetsPassThreshold(passed, len(testCases))); err != nil {
+				return err
+			}
+			return recomputeCourseScore(tx, sub.UserID, material.CourseID)
+		}
+
 		// upsert student progress
 		var prog models.StudentProgress
 		err := tx.Where("user_id = ? AND material_id = ?", sub.UserID, materialID).
 			First(&prog).Error
 		now := time.Now()
 		// กำหนดสถานะตามการผ่าน test case
+		meetsThreshold := codeExercise.MeetsPassThreshold(passed, len(testCases))
 		var newStatus enums.ProgressStatus
-		if passed == len(testCases) && len(testCases) > 0 {
-			// ผ่านทุก test case -> พร้อมขอ review
+		if meetsThreshold {
+			// ผ่านตามเกณฑ์ (ค่าเริ่มต้นคือทุก test case) -> พร้อมขอ review
 			newStatus = enums.ProgressInProgress
 		} else {
 			// ไม่ผ่านหรือไม่มี test case
@@ -518,8 +864,8 @@ func (s *SubmissionService) ExecuteCodeSubmission(submissionID, code, materialID
 			if score > prog.Score {
 				prog.Score = score
 			}
-			// อัพเดทสถานะถ้าผ่านทุก test case
-			if passed == len(testCases) && len(testCases) > 0 {
+			// อัพเดทสถานะถ้าผ่านตามเกณฑ์
+			if meetsThreshold {
 				prog.Status = enums.ProgressInProgress
 			}
 			prog.LastSubmittedAt = &now
@@ -528,14 +874,481 @@ func (s *SubmissionService) ExecuteCodeSubmission(submissionID, code, materialID
 			}
 		}
 
-		return nil
+		return recomputeCourseScore(tx, sub.UserID, material.CourseID)
 	}); err != nil {
+		if errors.Is(err, ErrSubmissionCancelled) {
+			return err
+		}
 		return fmt.Errorf("persist results: %w", err)
 	}
 
 	return nil
 }
 
+// Messages gradeCode gives results whose run may succeed if tried again; such results
+// are never reused by the result cache
+const (
+	executionErrorPrefix     = "Execution error: "
+	executionTimedOutMessage = "Code execution timed out. Your program may have an infinite loop or is taking too long to execute."
+)
+
+// gradeCode runs code against the test cases of a code exercise and returns a result per
+// test case (not yet saved) and how many passed. It reads nothing and writes nothing to
+// the database, so it also serves simulations. It stops early when ctx is cancelled,
+// leaving the results incomplete.
+func (s *SubmissionService) gradeCode(ctx context.Context, submissionID, code string, codeExercise *models.CodeExercise, testCases []models.TestCase) ([]models.SubmissionResult, int) {
+	passed := 0
+	results := make([]models.SubmissionResult, 0, len(testCases))
+
+	// Compile once before running test cases; broken code fails every case
+	// without spending a container run per test case
+	compileErr := ""
+	if compileRes, err := s.exec.CompileCheckContext(ctx, code); err != nil {
+		logger.Warnf("Compile check failed to run for submission %s, running test cases anyway: %v", submissionID, err)
+	} else if compileRes.IsInfrastructureFailure() {
+		logger.Warnf("Compile check hit an executor failure for submission %s, running test cases anyway: %s", submissionID, describeExecFailure(compileRes, nil))
+	} else if !compileRes.TimedOut && compileRes.ExitCode != 0 {
+		compileErr = strings.TrimSpace(compileRes.Stderr)
+		if compileErr == "" {
+			compileErr = fmt.Sprintf("compiler exited with code %d", compileRes.ExitCode)
+		}
+	}
+
+	compareOpts := external.CompareOptions{
+		TrimStrings:     codeExercise.GetTrimWhitespace(),
+		NumberTolerance: codeExercise.GetNumberTolerance(),
+	}
+	plainOutput := codeExercise.GetOutputMode() == enums.OutputModePlain
+
+	for i, tc := range testCases {
+		if ctx.Err() != nil {
+			break
+		}
+		if compileErr != "" {
+			results = append(results, models.SubmissionResult{
+				SubmissionID: submissionID,
+				TestCaseID:   tc.TestCaseID,
+				Status:       "error",
+				ErrorMessage: fmt.Sprintf("Compilation error: %s", compileErr),
+			})
+			continue
+		}
+
+		execRes, runErr := s.runPythonWithRetry(ctx, code, plainOutput, s.testCaseStdin(tc))
+
+		result := models.SubmissionResult{
+			SubmissionID: submissionID,
+			TestCaseID:   tc.TestCaseID,
+		}
+		if execRes != nil {
+			result.ExecutionTimeMs = execRes.Duration.Milliseconds()
+		}
+
+		if runErr != nil {
+			result.Status = "error"
+			result.ErrorMessage = executionErrorPrefix + runErr.Error()
+
+		} else if execRes.TimedOut {
+			result.Status = "error"
+			result.ErrorMessage = executionTimedOutMessage
+
+		} else if execRes.ExitCode != 0 {
+			result.Status = "error"
+			errorMsg := "Your code encountered an error during execution."
+			if execRes.Stderr != "" {
+				errorMsg = fmt.Sprintf("Runtime error: %s", execRes.Stderr)
+			} else {
+				errorMsg = fmt.Sprintf("Your code exited with error code %d", execRes.ExitCode)
+			}
+			result.ErrorMessage = errorMsg
+
+		} else if plainOutput {
+			// Plain mode compares the printed text and never parses stdout as JSON
+			actualText := external.PlainActualOutput(execRes.Stdout, codeExercise.GetTrimWhitespace())
+			expectedText := external.PlainExpectedOutput(tc.ExpectedOutput, codeExercise.GetTrimWhitespace())
+			actualJSON, _ := json.Marshal(actualText)
+			result.ActualOutput = types.JSONData(actualJSON)
+
+			if actualText == expectedText {
+				result.Status = "passed"
+				passed++
+			} else {
+				result.Status = "failed"
+				inputJSON := testCaseInputText(tc)
+				result.ErrorMessage = fmt.Sprintf(
+					"Test case %d failed\n\n"+
+						"Input:\n%s\n\n"+
+						"Expected output:\n%s\n\n"+
+						"Your output:\n%s",
+					i+1,
+					inputJSON,
+					expectedText,
+					actualText,
+				)
+			}
+
+		} else {
+			// parse student's stdout as JSON; a single trailing newline from print() is ignored
+			stdout := external.TrimTrailingNewline(execRes.Stdout)
+
+			var actual interface{}
+			if strings.TrimSpace(stdout) == "" {
+				result.Status = "error"
+				result.ErrorMessage = "Your code did not print any output. Print the result as JSON."
+
+			} else if err := json.Unmarshal([]byte(stdout), &actual); err != nil {
+				result.Status = "error"
+				result.ErrorMessage = fmt.Sprintf("Your code output is not valid JSON: %s\nOutput received: %s",
+					err.Error(), stdout)
+
+			} else {
+				result.ActualOutput = types.JSONData{}
+				_ = json.Unmarshal([]byte(stdout), &result.ActualOutput)
+
+				// Compare the raw JSON so integers keep every digit
+				if external.CompareJSONWith(tc.ExpectedOutput, json.RawMessage(stdout), compareOpts) {
+					result.Status = "passed"
+					passed++
+
+				} else {
+					result.Status = "failed"
+
+					// Create detailed error message
+					var expected interface{}
+					_ = json.Unmarshal(tc.ExpectedOutput, &expected)
+					inputJSON := testCaseInputText(tc)
+					expectedJSON, _ := json.Marshal(tc.ExpectedOutput)
+					actualJSON, _ := json.Marshal(json.RawMessage(stdout))
+
+					failureReason := analyzeFailureReason(expected, actual, compareOpts)
+
+					result.ErrorMessage = fmt.Sprintf(
+						"Test case %d failed\n\n"+
+							"Input:\n%s\n\n"+
+							"Expected output:\n%s\n\n"+
+							"Your output:\n%s\n\n"+
+							"Reason: %s",
+						i+1,
+						inputJSON,
+						string(expectedJSON),
+						string(actualJSON),
+						failureReason,
+					)
+
+				}
+			}
+		}
+
+		results = append(results, result)
+	}
+
+	return results, passed
+}
+
+// recomputeProgressAfterRejudge brings the student's progress in line with a rejudged
+// submission: the score becomes the best score across their submissions, and the
+// in_progress/not_started state follows whether it meets the pass threshold when this
+// is their latest submission. Approval states (waiting_approval, completed) are left to reviewers.
+func (s *SubmissionService) recomputeProgressAfterRejudge(tx *gorm.DB, sub *models.Submission, passed bool) error {
+	var prog models.StudentProgress
+	if err := tx.Where("user_id = ? AND material_id = ?", sub.UserID, sub.MaterialID).First(&prog).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil
+		}
+		return fmt.Errorf("get progress: %w", err)
+	}
+
+	var bestScore int
+	if err := tx.Model(&models.Submission{}).
+		Where("user_id = ? AND material_id = ?", sub.UserID, sub.MaterialID).
+		Select("COALESCE(MAX(total_score), 0)").
+		Scan(&bestScore).Error; err != nil {
+		return fmt.Errorf("get best score: %w", err)
+	}
+
+	var latestID string
+	if err := tx.Model(&models.Submission{}).
+		Where("user_id = ? AND material_id = ?", sub.UserID, sub.MaterialID).
+		Order("submitted_at DESC").
+		Limit(1).
+		Pluck("submission_id", &latestID).Error; err != nil {
+		return fmt.Errorf("get latest submission: %w", err)
+	}
+
+	updates := map[string]interface{}{"score": bestScore}
+	if latestID == sub.SubmissionID {
+		switch {
+		case passed && prog.Status == enums.ProgressNotStarted:
+			updates["status"] = enums.ProgressInProgress
+		case !passed && prog.Status == enums.ProgressInProgress:
+			updates["status"] = enums.ProgressNotStarted
+		}
+	}
+
+	if err := tx.Model(&models.StudentProgress{}).
+		Where("progress_id = ?", prog.ProgressID).
+		Updates(updates).Error; err != nil {
+		return fmt.Errorf("update progress: %w", err)
+	}
+	return nil
+}
+
+// Rejudge grades an existing code submission again against the material's current
+// test cases, e.g. after a test case was fixed. Only the course creator or a TA of
+// the course may rejudge. The work goes through the code execution queue when it is
+// available; otherwise it runs synchronously.
+func (s *SubmissionService) Rejudge(submissionID, actorID string) (*models.Submission, error) {
+	var sub models.Submission
+	if err := s.db.Where("submission_id = ?", submissionID).First(&sub).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("submission not found")
+		}
+		return nil, fmt.Errorf("get submission: %w", err)
+	}
+
+	var material models.CourseMaterial
+	if err := s.db.First(&material, "material_id = ?", sub.MaterialID).Error; err != nil {
+		return nil, fmt.Errorf("get material: %w", err)
+	}
+	if !material.IsCodeExercise() || sub.Code == "" {
+		return nil, fmt.Errorf("only code submissions can be rejudged")
+	}
+
+	canReview, err := authz.CanReviewInCourse(s.db, actorID, material.CourseID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get course details: %w", err)
+	}
+	if !canReview {
+		return nil, fmt.Errorf("only course teachers and TAs can rejudge submissions")
+	}
+	if err := checkCourseNotArchived(s.db, material.CourseID); err != nil {
+		return nil, err
+	}
+
+	if err := s.db.Model(&models.Submission{}).
+		Where("submission_id = ?", sub.SubmissionID).
+		Update("status", enums.SubmissionRunning).Error; err != nil {
+		return nil, fmt.Errorf("update submission status: %w", err)
+	}
+	sub.Status = enums.SubmissionRunning
+
+	logger.Infof("User %s requested a rejudge of submission %s", actorID, sub.SubmissionID)
+
+	queued := false
+	if s.queueService != nil {
+		if _, err := s.queueService.SubmitRejudgeJob(context.Background(), &sub, material.CourseID); err != nil {
+			logger.Warnf("Failed to queue rejudge, falling back to synchronous execution: %v", err)
+		} else {
+			queued = true
+		}
+	}
+	if !queued {
+		if err := s.ExecuteRejudge(sub.SubmissionID, sub.Code, sub.MaterialID); err != nil {
+			return nil, fmt.Errorf("code execution failed: %w", err)
+		}
+	}
+
+	var saved models.Submission
+	if err := s.db.Preload("Results").
+		Where("submission_id = ?", sub.SubmissionID).
+		First(&saved).Error; err != nil {
+		return nil, fmt.Errorf("reload submission: %w", err)
+	}
+	return &saved, nil
+}
+
+// Retry policy for executor infrastructure failures (Docker daemon/CLI errors)
+const (
+	maxExecAttempts  = 3
+	execRetryBackoff = 500 * time.Millisecond
+)
+
+// runPythonWithRetry runs a single test case, retrying only when the executor itself failed.
+// openStdin is called for every attempt, since a streamed input can only be read once.
+// With plainOutput, stdout is the program's output as printed (see OutputModePlain).
+func (s *SubmissionService) runPythonWithRetry(ctx context.Context, code string, plainOutput bool, openStdin func() (io.ReadCloser, error)) (*external.ExecResult, error) {
+	runPython := s.exec.RunPythonStreamContext
+	if plainOutput {
+		runPython = s.exec.RunPythonPlainStreamContext
+	}
+	var execRes *external.ExecResult
+	var runErr error
+	for attempt := 1; attempt <= maxExecAttempts; attempt++ {
+		stdin, err := openStdin()
+		if err != nil {
+			return nil, err
+		}
+		execRes, runErr = runPython(ctx, code, stdin)
+		stdin.Close()
+		if runErr == nil && !execRes.IsInfrastructureFailure() {
+			return execRes, nil
+		}
+		if errors.Is(runErr, external.ErrRunCancelled) {
+			return nil, runErr
+		}
+		if attempt < maxExecAttempts {
+			logger.Warnf("Executor failure on attempt %d/%d, retrying: %v", attempt, maxExecAttempts, describeExecFailure(execRes, runErr))
+			select {
+			case <-ctx.Done():
+				return nil, external.ErrRunCancelled
+			case <-time.After(time.Duration(attempt) * execRetryBackoff):
+			}
+		}
+	}
+	if runErr == nil {
+		runErr = fmt.Errorf("executor failed after %d attempts: %s", maxExecAttempts, describeExecFailure(execRes, nil))
+	}
+	return nil, runErr
+}
+
+// testCaseStdin returns a function opening the test case's JSON input: the stored object
+// for a test case with an input key, otherwise its inline input_data
+func (s *SubmissionService) testCaseStdin(tc models.TestCase) func() (io.ReadCloser, error) {
+	if !tc.HasStoredInput() {
+		stdinBytes, _ := json.Marshal(tc.InputData)
+		return func() (io.ReadCloser, error) {
+			return io.NopCloser(bytes.NewReader(stdinBytes)), nil
+		}
+	}
+	key := *tc.InputKey
+	return func() (io.ReadCloser, error) {
+		if s.storageService == nil {
+			return nil, fmt.Errorf("stored input %s cannot be read: storage is not configured", key)
+		}
+		stdin, _, err := s.storageService.OpenObject(context.Background(), key)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open stored input %s: %w", key, err)
+		}
+		return stdin, nil
+	}
+}
+
+// testCaseInputText is the input shown in a failed test case's message; stored inputs are
+// named rather than reproduced
+func testCaseInputText(tc models.TestCase) string {
+	if tc.HasStoredInput() {
+		return fmt.Sprintf("(stored input %s)", *tc.InputKey)
+	}
+	inputJSON, _ := json.Marshal(tc.InputData)
+	return string(inputJSON)
+}
+
+// describeExecFailure summarizes an executor failure for logs and error messages
+func describeExecFailure(execRes *external.ExecResult, runErr error) string {
+	if runErr != nil {
+		return runErr.Error()
+	}
+	if execRes != nil {
+		return strings.TrimSpace(execRes.Stderr)
+	}
+	return "unknown error"
+}
+
+// newFileProcessingJob builds the file processing job (text extraction, thumbnails, ...)
+// for a file submission; submissionType selects the processing, see ProcessFile
+func newFileProcessingJob(sub *models.Submission, submissionType, courseID string) *models.QueueJob {
+	dataJSON, _ := json.Marshal(types.QueueJobData{
+		FileURL:        sub.FileURL,
+		FileName:       sub.FileName,
+		FileSize:       sub.FileSize,
+		SubmissionType: submissionType,
+		MaterialID:     sub.MaterialID,
+		CourseID:       courseID,
+		SubmissionID:   sub.SubmissionID,
+	})
+
+	materialID := sub.MaterialID
+	submissionID := sub.SubmissionID
+	return &models.QueueJob{
+		Type:         enums.QueueTypeFileProcessing,
+		Status:       enums.QueueStatusPending,
+		UserID:       sub.UserID,
+		MaterialID:   &materialID,
+		CourseID:     &courseID,
+		SubmissionID: &submissionID,
+		Data:         string(dataJSON),
+	}
+}
+
+// fileSubmissionType maps a submission's MIME type to the file processing it gets
+func fileSubmissionType(mimeType string) string {
+	switch {
+	case mimeType == "application/pdf":
+		return "pdf"
+	case strings.HasPrefix(mimeType, "image/"):
+		return "image"
+	case strings.HasPrefix(mimeType, "video/"):
+		return "video"
+	default:
+		return ""
+	}
+}
+
+// ReprocessFile queues the file processing job of an existing file submission again,
+// e.g. after text extraction failed or a processing bug was fixed, so the student does
+// not have to resubmit. Only the course creator or a TA of the course may reprocess.
+func (s *SubmissionService) ReprocessFile(submissionID, actorID string) (*models.QueueJob, error) {
+	var sub models.Submission
+	if err := s.db.Where("submission_id = ?", submissionID).First(&sub).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("submission not found")
+		}
+		return nil, fmt.Errorf("get submission: %w", err)
+	}
+
+	submissionType := fileSubmissionType(sub.MimeType)
+	if sub.FileURL == "" || submissionType == "" {
+		return nil, fmt.Errorf("submission has no file to process")
+	}
+
+	var material models.CourseMaterial
+	if err := s.db.First(&material, "material_id = ?", sub.MaterialID).Error; err != nil {
+		return nil, fmt.Errorf("get material: %w", err)
+	}
+
+	canReview, err := authz.CanReviewInCourse(s.db, actorID, material.CourseID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get course details: %w", err)
+	}
+	if !canReview {
+		return nil, fmt.Errorf("only course teachers and TAs can reprocess submissions")
+	}
+
+	if s.queueService == nil {
+		return nil, fmt.Errorf("file processing queue is not available")
+	}
+
+	// Jobs created before submission_id was recorded only carry it in their data
+	var inProgress int64
+	if err := s.db.Model(&models.QueueJob{}).
+		Where("type = ? AND status IN ?", enums.QueueTypeFileProcessing, []enums.QueueStatus{enums.QueueStatusPending, enums.QueueStatusProcessing}).
+		Where("submission_id = ? OR data::jsonb->>'submission_id' = ?", sub.SubmissionID, sub.SubmissionID).
+		Count(&inProgress).Error; err != nil {
+		return nil, fmt.Errorf("check file processing jobs: %w", err)
+	}
+	if inProgress > 0 {
+		return nil, fmt.Errorf("file processing is already in progress for this submission")
+	}
+
+	job := newFileProcessingJob(&sub, submissionType, material.CourseID)
+	if err := s.db.Create(job).Error; err != nil {
+		return nil, fmt.Errorf("create file processing job: %w", err)
+	}
+
+	message := &external.QueueMessage{
+		ID:   job.ID,
+		Type: string(enums.QueueTypeFileProcessing),
+		Data: map[string]interface{}{"job_id": job.ID},
+	}
+	if err := s.queueService.PublishFileProcessingJob(context.Background(), material.CourseID, message); err != nil {
+		s.queueService.UpdateJobStatus(job.ID, enums.QueueStatusFailed, "", nil, fmt.Sprintf("Failed to publish to queue: %v", err))
+		return nil, fmt.Errorf("file processing queue is not available: %w", err)
+	}
+
+	logger.Infof("User %s requested reprocessing of submission %s (job %s)", actorID, sub.SubmissionID, job.ID)
+	return job, nil
+}
+
 // ProcessFile processes uploaded files (PDF, images, videos) - called by queue worker
 func (s *SubmissionService) ProcessFile(jobData types.QueueJobData) error {
 	// Get submission if submission ID is provided
@@ -562,6 +1375,21 @@ func (s *SubmissionService) ProcessFile(jobData types.QueueJobData) error {
 	}
 }
 
+// checkPDFPages reads the uploaded PDF and applies the exercise's page limit
+func (s *SubmissionService) checkPDFPages(materialID string, file *multipart.FileHeader) error {
+	src, err := file.Open()
+	if err != nil {
+		return fmt.Errorf("open file: %w", err)
+	}
+	defer src.Close()
+
+	content, err := io.ReadAll(src)
+	if err != nil {
+		return fmt.Errorf("read file: %w", err)
+	}
+	return checkPDFPageLimit(s.db, materialID, s.maxPDFPages, content)
+}
+
 // processPDFFile processes PDF files: extract text, get page count, generate thumbnail
 func (s *SubmissionService) processPDFFile(submission models.Submission, jobData types.QueueJobData) error {
 	// TODO: Implement PDF processing
@@ -604,6 +1432,10 @@ func (s *SubmissionService) processVideoFile(submission models.Submission, jobDa
 
 // SubmitPDFExercise handles PDF file submission for PDF exercises
 func (s *SubmissionService) SubmitPDFExercise(userID, materialID string, file *multipart.FileHeader) (*models.Submission, error) {
+	if err := checkSubmissionType(s.db, materialID, enums.MaterialTypePDFExercise); err != nil {
+		return nil, err
+	}
+
 	// Validate material exists and is PDF exercise
 	var material models.CourseMaterial
 	if err := s.db.Where("material_id = ? AND type = ?", materialID, enums.MaterialTypePDFExercise).First(&material).Error; err != nil {
@@ -622,8 +1454,37 @@ func (s *SubmissionService) SubmitPDFExercise(userID, materialID string, file *m
 		return nil, fmt.Errorf("check enrollment: %w", err)
 	}
 
+	if err := checkCourseNotArchived(s.db, material.CourseID); err != nil {
+		return nil, err
+	}
+
+	canSubmit, message, err := s.deadlineService.CanSubmitMaterial(userID, materialID)
+	if err != nil {
+		return nil, fmt.Errorf("check submission eligibility: %w", err)
+	}
+	if !canSubmit {
+		return nil, fmt.Errorf("%w: %s", ErrSubmissionNotAllowed, message)
+	}
+
+	if err := checkPDFResubmissionAllowed(s.db, userID, materialID); err != nil {
+		return nil, err
+	}
+
+	if err := checkPrerequisitesMet(s.db, userID, materialID); err != nil {
+		return nil, err
+	}
+
+	if err := s.checkPDFPages(materialID, file); err != nil {
+		return nil, err
+	}
+
 	var submission *models.Submission
-	err := s.db.Transaction(func(tx *gorm.DB) error {
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		// Claimed with the insert, so a failed upload does not use up an attempt
+		if err := claimPDFAttempt(tx, userID, materialID); err != nil {
+			return err
+		}
+
 		// Delete old submission if exists
 		if err := s.deleteOldSubmission(tx, userID, materialID); err != nil {
 			return fmt.Errorf("delete old submission: %w", err)
@@ -640,10 +1501,12 @@ func (s *SubmissionService) SubmitPDFExercise(userID, materialID string, file *m
 			UserID:     userID,
 			MaterialID: materialID,
 			FileURL:    fileURL,
-			FileName:   file.Filename,
+			FileName:   s.storageService.SanitizeFilename(file.Filename),
 			FileSize:   file.Size,
 			MimeType:   "application/pdf",
 			Status:     enums.SubmissionPending,
+
+			IsLateSubmission: message == deadlinePassedMessage,
 		}
 
 		if err := tx.Create(submission).Error; err != nil {
@@ -674,29 +1537,13 @@ func (s *SubmissionService) SubmitPDFExercise(userID, materialID string, file *m
 			if err := tx.Where("user_id = ? AND material_id = ?", userID, materialID).Updates(&prog).Error; err != nil {
 				return fmt.Errorf("update progress: %w", err)
 			}
+			if err := recomputeCourseScore(tx, userID, material.CourseID); err != nil {
+				return err
+			}
 		}
 
 		// Create queue job for file processing (extract text, generate thumbnails, etc.)
-		fileProcessingJobData := types.QueueJobData{
-			FileURL:        fileURL,
-			FileName:       file.Filename,
-			FileSize:       file.Size,
-			SubmissionType: "pdf",
-			MaterialID:     materialID,
-			CourseID:       material.CourseID,
-			SubmissionID:   submission.SubmissionID,
-		}
-
-		fileProcessingDataJSON, _ := json.Marshal(fileProcessingJobData)
-		fileProcessingJob := &models.QueueJob{
-			Type:       enums.QueueTypeFileProcessing,
-			Status:     enums.QueueStatusPending,
-			UserID:     userID,
-			MaterialID: &materialID,
-			CourseID:   &material.CourseID,
-			Data:       string(fileProcessingDataJSON),
-		}
-
+		fileProcessingJob := newFileProcessingJob(submission, "pdf", material.CourseID)
 		if err := tx.Create(fileProcessingJob).Error; err != nil {
 			return fmt.Errorf("create file processing job: %w", err)
 		}
@@ -720,7 +1567,7 @@ func (s *SubmissionService) SubmitPDFExercise(userID, materialID string, file *m
 		// Create queue job for PDF review
 		queueJobData := types.QueueJobData{
 			FileURL:        fileURL,
-			FileName:       file.Filename,
+			FileName:       s.storageService.SanitizeFilename(file.Filename),
 			FileSize:       file.Size,
 			SubmissionType: "pdf",
 			MaterialID:     materialID,
@@ -754,19 +1601,44 @@ func (s *SubmissionService) SubmitPDFExercise(userID, materialID string, file *m
 	return submission, nil
 }
 
-// CleanupOldSubmissions deletes submissions where the material deadline has passed
+// CleanupOldSubmissions deletes submissions, with their files, once their material's
+// deadline is further in the past than the course's submission retention period. Courses
+// that keep submissions indefinitely are skipped.
 func (s *SubmissionService) CleanupOldSubmissions() error {
-	now := time.Now().Format(time.RFC3339)
+	now := time.Now()
+
+	// Courses with their own retention period; every other course uses the default
+	var courses []models.Course
+	if err := s.db.Select("course_id", "settings").
+		Where("settings ->> 'submission_retention_days' IS NOT NULL").
+		Find(&courses).Error; err != nil {
+		return fmt.Errorf("failed to get course retention settings: %w", err)
+	}
+	configured := make([]string, 0, len(courses))
+	byRetention := make(map[int][]string)
+	for _, course := range courses {
+		days := course.Settings.GetSubmissionRetentionDays()
+		configured = append(configured, course.CourseID)
+		byRetention[days] = append(byRetention[days], course.CourseID)
+	}
 
-	// Find submissions where material deadline has passed
-	// Join with course_materials to check deadline
-	var oldSubmissions []models.Submission
-	if err := s.db.Table("submissions s").
-		Joins("INNER JOIN course_materials cm ON s.material_id = cm.material_id").
-		Where("cm.deadline IS NOT NULL AND cm.deadline != '' AND cm.deadline <= ?", now).
-		Select("s.*").
-		Find(&oldSubmissions).Error; err != nil {
-		return fmt.Errorf("failed to find old submissions: %w", err)
+	oldSubmissions, err := s.findExpiredSubmissions(now, models.DefaultSubmissionRetentionDays, func(db *gorm.DB) *gorm.DB {
+		if len(configured) == 0 {
+			return db
+		}
+		return db.Where("cm.course_id NOT IN ?", configured)
+	})
+	if err != nil {
+		return err
+	}
+	for days, courseIDs := range byRetention {
+		found, err := s.findExpiredSubmissions(now, days, func(db *gorm.DB) *gorm.DB {
+			return db.Where("cm.course_id IN ?", courseIDs)
+		})
+		if err != nil {
+			return err
+		}
+		oldSubmissions = append(oldSubmissions, found...)
 	}
 
 	deletedCount := 0
@@ -795,12 +1667,36 @@ func (s *SubmissionService) CleanupOldSubmissions() error {
 	}
 
 	if deletedCount > 0 {
-		logger.Infof("Cleaned up %d old submissions (past deadline)", deletedCount)
+		logger.Infof("Cleaned up %d old submissions (past deadline and retention period)", deletedCount)
 	}
 
 	return nil
 }
 
+// findExpiredSubmissions returns the submissions, among the materials selected by scope,
+// whose material deadline passed more than retentionDays before now; none when the
+// retention is RetainSubmissionsIndefinitely
+func (s *SubmissionService) findExpiredSubmissions(now time.Time, retentionDays int, scope func(*gorm.DB) *gorm.DB) ([]models.Submission, error) {
+	if retentionDays == models.RetainSubmissionsIndefinitely {
+		return nil, nil
+	}
+	cutoff := now.AddDate(0, 0, -retentionDays).Format(time.RFC3339)
+
+	// Deadlines live on the exercise tables; course_materials gives the course
+	var submissions []models.Submission
+	if err := s.db.Table("submissions s").
+		Joins("INNER JOIN course_materials cm ON s.material_id = cm.material_id").
+		Joins("LEFT JOIN code_exercises ce ON ce.material_id = s.material_id").
+		Joins("LEFT JOIN pdf_exercises pe ON pe.material_id = s.material_id").
+		Where("COALESCE(ce.deadline, pe.deadline, '') != '' AND COALESCE(ce.deadline, pe.deadline) <= ?", cutoff).
+		Scopes(scope).
+		Select("s.*").
+		Find(&submissions).Error; err != nil {
+		return nil, fmt.Errorf("failed to find old submissions: %w", err)
+	}
+	return submissions, nil
+}
+
 // deleteOldSubmission deletes old submission and its file from MinIO
 func (s *SubmissionService) deleteOldSubmission(tx *gorm.DB, userID, materialID string) error {
 	var oldSubmission models.Submission
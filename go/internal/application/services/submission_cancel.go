@@ -0,0 +1,112 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	models "github.com/Project-DSView/backend/go/internal/domain/entities"
+	"github.com/Project-DSView/backend/go/internal/domain/enums"
+	"github.com/Project-DSView/backend/go/pkg/logger"
+	"gorm.io/gorm"
+)
+
+// Errors returned by CancelRunningSubmission
+var (
+	// ErrSubmissionCancelled is returned by the grading paths when the submission was
+	// cancelled while it waited or ran; its results are discarded
+	ErrSubmissionCancelled  = errors.New("submission was cancelled")
+	ErrSubmissionNotRunning = errors.New("only a queued or running submission can be cancelled")
+	ErrNotSubmissionOwner   = errors.New("you can only cancel your own submission")
+)
+
+// CancelRunningSubmission stops a code submission that is still queued or running, e.g.
+// one stuck in an infinite loop. Its queue job is cancelled, the executor on this
+// instance kills the container running it, and the student's progress goes back to the
+// status it had before the submission along with the attempt it used. A run on another
+// instance finishes, but its results are discarded. Rejudges cannot be cancelled.
+// A submission to an exercise that is not a code exercise gets ErrNotCodeExercise.
+func (s *SubmissionService) CancelRunningSubmission(submissionID, userID string) (*models.Submission, error) {
+	var sub models.Submission
+	if err := s.db.Where("submission_id = ?", submissionID).First(&sub).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("submission not found")
+		}
+		return nil, fmt.Errorf("get submission: %w", err)
+	}
+	if err := checkSubmissionType(s.db, sub.MaterialID, enums.MaterialTypeCodeExercise); err != nil {
+		return nil, err
+	}
+	if sub.UserID != userID && (sub.SubmittedBy == nil || *sub.SubmittedBy != userID) {
+		return nil, ErrNotSubmissionOwner
+	}
+	// A rejudged submission has results from its first run
+	if sub.Status != enums.SubmissionRunning || sub.PassedCount+sub.FailedCount > 0 {
+		return nil, ErrSubmissionNotRunning
+	}
+
+	if err := s.cancelSubmission(&sub); err != nil {
+		return nil, err
+	}
+	logger.Infof("User %s cancelled submission %s", userID, sub.SubmissionID)
+	return &sub, nil
+}
+
+// cancelSubmission cancels a first run of a code submission that is still running, as
+// described on CancelRunningSubmission. It returns ErrSubmissionNotRunning when grading
+// finished first.
+func (s *SubmissionService) cancelSubmission(sub *models.Submission) error {
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		// Grading takes the same row lock before saving results, so exactly one side wins
+		res := tx.Model(&models.Submission{}).
+			Where("submission_id = ? AND status = ?", sub.SubmissionID, enums.SubmissionRunning).
+			Update("status", enums.SubmissionCancelled)
+		if res.Error != nil {
+			return fmt.Errorf("update submission status: %w", res.Error)
+		}
+		if res.RowsAffected == 0 {
+			return ErrSubmissionNotRunning
+		}
+
+		if err := tx.Model(&models.QueueJob{}).
+			Where("submission_id = ? AND type = ? AND status IN ?", sub.SubmissionID, enums.QueueTypeCodeExecution,
+				[]enums.QueueStatus{enums.QueueStatusPending, enums.QueueStatusProcessing}).
+			Updates(map[string]interface{}{
+				"status":       enums.QueueStatusCancelled,
+				"completed_at": time.Now(),
+			}).Error; err != nil {
+			return fmt.Errorf("cancel queue job: %w", err)
+		}
+
+		// Only a completed exercise is restored; one waiting for approval had its
+		// approval request cancelled on resubmission, so it stays in progress
+		if sub.PriorProgressStatus != nil && *sub.PriorProgressStatus == enums.ProgressCompleted {
+			if err := tx.Model(&models.StudentProgress{}).
+				Where("user_id = ? AND material_id = ? AND status = ?", sub.UserID, sub.MaterialID, enums.ProgressInProgress).
+				Update("status", enums.ProgressCompleted).Error; err != nil {
+				return fmt.Errorf("restore progress: %w", err)
+			}
+		}
+
+		// Give back the attempt the submission used
+		return refundAttempt(tx, sub.UserID, sub.MaterialID)
+	})
+	if err != nil {
+		return err
+	}
+
+	if running, err := s.exec.Terminate(sub.SubmissionID); err != nil {
+		logger.Warnf("Failed to stop the run of submission %s: %v", sub.SubmissionID, err)
+	} else if running {
+		logger.Infof("Stopped the run of submission %s", sub.SubmissionID)
+	}
+	// The cancelled job frees its slot under the exercise's concurrency cap
+	if s.queueService != nil {
+		if err := s.queueService.releaseHeldJobs(sub.MaterialID); err != nil {
+			logger.Warnf("Failed to release held jobs for material %s: %v", sub.MaterialID, err)
+		}
+	}
+
+	sub.Status = enums.SubmissionCancelled
+	return nil
+}
@@ -0,0 +1,140 @@
+package services
+
+import (
+	"errors"
+	"testing"
+
+	models "github.com/Project-DSView/backend/go/internal/domain/entities"
+	"github.com/Project-DSView/backend/go/internal/domain/enums"
+	"gorm.io/gorm"
+)
+
+func TestCancelRunningSubmission(t *testing.T) {
+	completed := enums.ProgressCompleted
+
+	tests := []struct {
+		name string
+		// sub is the submission cancelled by student-1; its ID is sub-1
+		sub          models.Submission
+		wantErr      error
+		wantStatus   enums.SubmissionStatus
+		wantJob      enums.QueueStatus
+		wantProgress enums.ProgressStatus
+		wantAttempts int
+	}{
+		{
+			name:         "running code submission",
+			sub:          models.Submission{MaterialID: "code-1", UserID: "student-1", Status: enums.SubmissionRunning},
+			wantStatus:   enums.SubmissionCancelled,
+			wantJob:      enums.QueueStatusCancelled,
+			wantProgress: enums.ProgressInProgress,
+			wantAttempts: 0,
+		},
+		{
+			name:         "completed exercise is restored",
+			sub:          models.Submission{MaterialID: "code-1", UserID: "student-1", Status: enums.SubmissionRunning, PriorProgressStatus: &completed},
+			wantStatus:   enums.SubmissionCancelled,
+			wantJob:      enums.QueueStatusCancelled,
+			wantProgress: enums.ProgressCompleted,
+			wantAttempts: 0,
+		},
+		{
+			name:         "another student's submission",
+			sub:          models.Submission{MaterialID: "code-1", UserID: "student-2", Status: enums.SubmissionRunning},
+			wantErr:      ErrNotSubmissionOwner,
+			wantStatus:   enums.SubmissionRunning,
+			wantJob:      enums.QueueStatusProcessing,
+			wantProgress: enums.ProgressInProgress,
+			wantAttempts: 1,
+		},
+		{
+			name:         "graded submission",
+			sub:          models.Submission{MaterialID: "code-1", UserID: "student-1", Status: enums.SubmissionCompleted},
+			wantErr:      ErrSubmissionNotRunning,
+			wantStatus:   enums.SubmissionCompleted,
+			wantJob:      enums.QueueStatusProcessing,
+			wantProgress: enums.ProgressInProgress,
+			wantAttempts: 1,
+		},
+		{
+			name:         "rejudge",
+			sub:          models.Submission{MaterialID: "code-1", UserID: "student-1", Status: enums.SubmissionRunning, PassedCount: 1},
+			wantErr:      ErrSubmissionNotRunning,
+			wantStatus:   enums.SubmissionRunning,
+			wantJob:      enums.QueueStatusProcessing,
+			wantProgress: enums.ProgressInProgress,
+			wantAttempts: 1,
+		},
+		{
+			name:         "PDF submission",
+			sub:          models.Submission{MaterialID: "pdf-1", UserID: "student-1", Status: enums.SubmissionPending, FileURL: "submissions/a.pdf"},
+			wantErr:      ErrNotCodeExercise,
+			wantStatus:   enums.SubmissionPending,
+			wantJob:      enums.QueueStatusProcessing,
+			wantProgress: enums.ProgressInProgress,
+			wantAttempts: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := newTestDB(t, &models.CourseMaterial{}, &models.Submission{}, &models.QueueJob{}, &models.StudentProgress{})
+			tt.sub.SubmissionID = "sub-1"
+			subID := tt.sub.SubmissionID
+			createRows(t, db,
+				&models.CourseMaterial{MaterialID: "code-1", CourseID: "course-1", Type: enums.MaterialTypeCodeExercise},
+				&models.CourseMaterial{MaterialID: "pdf-1", CourseID: "course-1", Type: enums.MaterialTypePDFExercise},
+				&tt.sub,
+				&models.QueueJob{ID: "job-1", Type: enums.QueueTypeCodeExecution, Status: enums.QueueStatusProcessing, UserID: tt.sub.UserID, SubmissionID: &subID},
+				&models.StudentProgress{UserID: tt.sub.UserID, MaterialID: tt.sub.MaterialID, Status: enums.ProgressInProgress, AttemptCount: 1})
+
+			exec := fakeDockerExecutor(t, "")
+			runCtx, done := exec.TrackJob("sub-1")
+			defer done()
+			svc := NewSubmissionService(db, nil, nil, nil, nil, nil, exec, nil, nil)
+
+			_, err := svc.CancelRunningSubmission("sub-1", "student-1")
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("CancelRunningSubmission() error = %v, want %v", err, tt.wantErr)
+			}
+
+			// The executor stops the run only when the cancellation went through
+			if stopped := runCtx.Err() != nil; stopped != (tt.wantErr == nil) {
+				t.Errorf("run stopped = %v, want %v", stopped, tt.wantErr == nil)
+			}
+			assertCancelRows(t, db, tt.sub, tt.wantStatus, tt.wantJob, tt.wantProgress, tt.wantAttempts)
+		})
+	}
+}
+
+// assertCancelRows checks the submission, its queue job and its author's progress
+func assertCancelRows(t *testing.T, db *gorm.DB, sub models.Submission, wantStatus enums.SubmissionStatus,
+	wantJob enums.QueueStatus, wantProgress enums.ProgressStatus, wantAttempts int) {
+	t.Helper()
+	var got models.Submission
+	if err := db.First(&got, "submission_id = ?", sub.SubmissionID).Error; err != nil {
+		t.Fatalf("load submission: %v", err)
+	}
+	if got.Status != wantStatus {
+		t.Errorf("submission status = %s, want %s", got.Status, wantStatus)
+	}
+
+	var job models.QueueJob
+	if err := db.First(&job, "id = ?", "job-1").Error; err != nil {
+		t.Fatalf("load queue job: %v", err)
+	}
+	if job.Status != wantJob {
+		t.Errorf("queue job status = %s, want %s", job.Status, wantJob)
+	}
+
+	var progress models.StudentProgress
+	if err := db.First(&progress, "user_id = ? AND material_id = ?", sub.UserID, sub.MaterialID).Error; err != nil {
+		t.Fatalf("load progress: %v", err)
+	}
+	if progress.Status != wantProgress {
+		t.Errorf("progress status = %s, want %s", progress.Status, wantProgress)
+	}
+	if progress.AttemptCount != wantAttempts {
+		t.Errorf("attempt_count = %d, want %d", progress.AttemptCount, wantAttempts)
+	}
+}
@@ -0,0 +1,110 @@
+package services
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	models "github.com/Project-DSView/backend/go/internal/domain/entities"
+	"github.com/Project-DSView/backend/go/internal/domain/enums"
+)
+
+// TestCleanupOldSubmissions checks that submissions are deleted once their exercise's
+// deadline is older than the course's retention period, and kept forever in courses
+// retaining them indefinitely
+func TestCleanupOldSubmissions(t *testing.T) {
+	db := newTestDB(t, &models.Course{}, &models.CourseMaterial{}, &models.CodeExercise{}, &models.PDFExercise{},
+		&models.Submission{}, &models.SubmissionResult{})
+	thirtyDays, forever := 30, models.RetainSubmissionsIndefinitely
+	createRows(t, db,
+		&models.Course{CourseID: "course-default", Name: "Default retention", CreatedBy: "teacher-1"},
+		&models.Course{CourseID: "course-30", Name: "Thirty days", CreatedBy: "teacher-1",
+			Settings: models.CourseSettings{SubmissionRetentionDays: &thirtyDays}},
+		&models.Course{CourseID: "course-forever", Name: "Accredited", CreatedBy: "teacher-1",
+			Settings: models.CourseSettings{SubmissionRetentionDays: &forever}},
+	)
+
+	points := 10
+	daysAgo := func(days int) *string {
+		deadline := time.Now().AddDate(0, 0, -days).Format(time.RFC3339)
+		return &deadline
+	}
+	exercises := []struct {
+		id       string
+		courseID string
+		pdf      bool
+		deadline *string
+	}{
+		{"code-past", "course-default", false, daysAgo(2)},
+		{"pdf-past", "course-default", true, daysAgo(2)},
+		{"code-open", "course-default", false, daysAgo(-1)},
+		{"code-no-deadline", "course-default", false, nil},
+		{"code-within-retention", "course-30", false, daysAgo(10)},
+		{"pdf-past-retention", "course-30", true, daysAgo(40)},
+		{"code-kept-forever", "course-forever", false, daysAgo(400)},
+	}
+	for _, e := range exercises {
+		base := models.MaterialBase{MaterialID: e.id, CourseID: e.courseID, Title: e.id, CreatedBy: "teacher-1"}
+		materialType := enums.MaterialTypeCodeExercise
+		if e.pdf {
+			materialType = enums.MaterialTypePDFExercise
+			createRows(t, db, &models.PDFExercise{MaterialBase: base, TotalPoints: &points, Deadline: e.deadline,
+				FileURL: "sheet.pdf", FileName: "sheet.pdf"})
+		} else {
+			createRows(t, db, &models.CodeExercise{MaterialBase: base, TotalPoints: &points, Deadline: e.deadline})
+		}
+		createRows(t, db,
+			&models.CourseMaterial{MaterialID: e.id, CourseID: e.courseID, Type: materialType},
+			&models.Submission{SubmissionID: "sub-" + e.id, UserID: "student-1", MaterialID: e.id},
+			&models.SubmissionResult{ResultID: "result-" + e.id, SubmissionID: "sub-" + e.id, TestCaseID: "tc-1", Status: "passed"},
+		)
+	}
+
+	svc := NewSubmissionService(db, nil, nil, nil, nil, nil, nil, nil, nil)
+	if err := svc.CleanupOldSubmissions(); err != nil {
+		t.Fatalf("CleanupOldSubmissions() error = %v", err)
+	}
+
+	want := []string{"sub-code-kept-forever", "sub-code-no-deadline", "sub-code-open", "sub-code-within-retention"}
+	var kept []string
+	db.Model(&models.Submission{}).Pluck("submission_id", &kept)
+	sort.Strings(kept)
+	if !reflect.DeepEqual(kept, want) {
+		t.Errorf("kept submissions = %v, want %v", kept, want)
+	}
+	var results int64
+	db.Model(&models.SubmissionResult{}).Count(&results)
+	if results != int64(len(want)) {
+		t.Errorf("%d submission results left, want %d", results, len(want))
+	}
+}
+
+func TestUpdateCourseSettingsSubmissionRetention(t *testing.T) {
+	tests := []struct {
+		name    string
+		days    int
+		wantErr bool
+	}{
+		{"keep indefinitely", models.RetainSubmissionsIndefinitely, false},
+		{"delete at the deadline", 0, false},
+		{"retention period", 90, false},
+		{"below keep indefinitely", -2, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := newTestDB(t, &models.Course{})
+			createRows(t, db, &models.Course{CourseID: "course-1", Name: "Data Structures", CreatedBy: "teacher-1"})
+
+			days := tt.days
+			settings, err := NewCourseService(db, nil, nil).UpdateCourseSettings("course-1", models.CourseSettings{SubmissionRetentionDays: &days})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("UpdateCourseSettings() error = %v, want error %v", err, tt.wantErr)
+			}
+			if err == nil && settings.GetSubmissionRetentionDays() != tt.days {
+				t.Errorf("submission retention = %d days, want %d", settings.GetSubmissionRetentionDays(), tt.days)
+			}
+		})
+	}
+}
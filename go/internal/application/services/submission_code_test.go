@@ -0,0 +1,109 @@
+package services
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	models "github.com/Project-DSView/backend/go/internal/domain/entities"
+	"github.com/Project-DSView/backend/go/pkg/storage"
+)
+
+// TestOpenSubmissionCodeFilename checks that downloads are named after the student and a
+// prefix of the submission ID, including IDs shorter than the prefix
+func TestOpenSubmissionCodeFilename(t *testing.T) {
+	tests := []struct {
+		name         string
+		submissionID string
+		userID       string
+		want         string
+	}{
+		{name: "uuid", submissionID: "3f2b9c1e-8d4a-4f6b-9e2d-7a1c5b8e0f3d", userID: "student-1", want: "ann_3f2b9c1e.py"},
+		{name: "short id", submissionID: "sub-1", userID: "student-1", want: "ann_sub-1.py"},
+		{name: "unknown student", submissionID: "sub-1", userID: "missing", want: "sub-1.py"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := newTestDB(t, &models.User{})
+			createRows(t, db, &models.User{UserID: "student-1", FirstName: "Ann", LastName: "Lee", Email: "ann@example.com"})
+			svc := NewSubmissionService(db, nil, NewUserService(db), nil, nil, nil, nil, nil, nil)
+
+			reader, filename, _, _, err := svc.OpenSubmissionCode(&models.Submission{SubmissionID: tt.submissionID, UserID: tt.userID, Code: "print(3)"})
+			if err != nil {
+				t.Fatalf("OpenSubmissionCode() error = %v", err)
+			}
+			if filename != tt.want {
+				t.Errorf("filename = %q, want %q", filename, tt.want)
+			}
+			if code, _ := io.ReadAll(reader); string(code) != "print(3)" {
+				t.Errorf("code = %q, want print(3)", code)
+			}
+		})
+	}
+}
+
+// TestGetLatestCode checks that the student's newest submission with code is shown,
+// whether the code is stored on the submission or as a text file, and that PDF
+// submissions and other students' code are skipped
+func TestGetLatestCode(t *testing.T) {
+	root := t.TempDir()
+	store, err := storage.NewLocalStorageService(&storage.LocalConfig{RootDir: root, BaseURL: "http://localhost/files", SigningKey: "key"})
+	if err != nil {
+		t.Fatalf("create storage: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Join(root, "submissions"), 0o755); err != nil {
+		t.Fatalf("create submissions dir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "submissions", "sum.py"), []byte("print(sum([1, 2]))"), 0o644); err != nil {
+		t.Fatalf("write code file: %v", err)
+	}
+
+	at := func(minutes int) time.Time { return time.Now().Add(time.Duration(minutes) * time.Minute) }
+	inline := func(id string, minutes int) *models.Submission {
+		return &models.Submission{SubmissionID: id, UserID: "student-1", MaterialID: "code-1", Code: "print(" + id + ")", SubmittedAt: at(minutes)}
+	}
+	file := &models.Submission{SubmissionID: "file", UserID: "student-1", MaterialID: "code-1", SubmittedAt: at(-1),
+		FileURL: "http://localhost/files/submissions/sum.py", MimeType: "text/x-python"}
+	pdf := &models.Submission{SubmissionID: "pdf", UserID: "student-1", MaterialID: "code-1", SubmittedAt: at(0),
+		FileURL: "http://localhost/files/submissions/work.pdf", MimeType: "application/pdf"}
+	other := &models.Submission{SubmissionID: "other", UserID: "student-2", MaterialID: "code-1", Code: "print(other)", SubmittedAt: at(0)}
+
+	tests := []struct {
+		name     string
+		rows     []*models.Submission
+		wantID   string
+		wantCode string
+	}{
+		{"newest inline code", []*models.Submission{inline("old", -10), inline("new", -5), pdf, other}, "new", "print(new)"},
+		{"code file in storage", []*models.Submission{inline("old", -10), file, pdf}, "file", "print(sum([1, 2]))"},
+		{"no code submitted", []*models.Submission{pdf, other}, "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := newTestDB(t, &models.User{}, &models.Submission{})
+			for _, row := range tt.rows {
+				sub := *row
+				createRows(t, db, &sub)
+			}
+			svc := NewSubmissionService(db, nil, NewUserService(db), nil, nil, nil, nil, store, nil)
+
+			got, err := svc.GetLatestCode("student-1", "code-1")
+			if err != nil {
+				t.Fatalf("GetLatestCode() error = %v", err)
+			}
+			if tt.wantID == "" {
+				if got != nil {
+					t.Errorf("GetLatestCode() = %+v, want nil", got)
+				}
+				return
+			}
+			if got == nil || got.SubmissionID != tt.wantID || got.Code != tt.wantCode {
+				t.Errorf("GetLatestCode() = %+v, want submission %s with code %q", got, tt.wantID, tt.wantCode)
+			}
+		})
+	}
+}
@@ -0,0 +1,140 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	models "github.com/Project-DSView/backend/go/internal/domain/entities"
+	"github.com/Project-DSView/backend/go/internal/domain/enums"
+	"github.com/Project-DSView/backend/go/internal/types"
+	"github.com/Project-DSView/backend/go/pkg/storage"
+	"gorm.io/gorm"
+)
+
+// uploadedCode accepts code submission uploads, failing them with err when set; other
+// storage calls panic
+type uploadedCode struct {
+	storage.StorageService
+	err     error
+	uploads int
+}
+
+func (s *uploadedCode) UploadStudentCodeSubmission(_ context.Context, courseID, _ string, _ int, _ string, _ io.Reader, filename, _ string) (string, error) {
+	if s.err != nil {
+		return "", s.err
+	}
+	s.uploads++
+	return courseID + "/" + filename, nil
+}
+
+// newCodeSubmissionDB returns a database with a course taught by teacher-1, student-1
+// enrolled in it and a public code exercise with one test case, and the exercise's ID
+func newCodeSubmissionDB(t *testing.T) (*gorm.DB, string) {
+	t.Helper()
+	db := newTestDB(t, &models.User{}, &models.Course{}, &models.Enrollment{}, &models.CourseMaterial{},
+		&models.CodeExercise{}, &models.TestCase{}, &models.Submission{}, &models.SubmissionResult{},
+		&models.StudentProgress{}, &models.QueueJob{}, &models.DeadlineExtension{}, &models.StudentCourseScore{})
+
+	points := 10
+	code := models.CodeExercise{
+		MaterialBase:     models.MaterialBase{CourseID: "course-1", Title: "Sum", CreatedBy: "teacher-1"},
+		TotalPoints:      &points,
+		ProblemStatement: "Add the numbers",
+	}
+	createRows(t, db,
+		&models.User{UserID: "teacher-1", FirstName: "Ada", LastName: "Lovelace", Email: "ada@example.com", IsTeacher: true},
+		&models.User{UserID: "student-1", FirstName: "Alan", LastName: "Turing", Email: "alan@example.com"},
+		&models.Course{CourseID: "course-1", Name: "Data Structures", CreatedBy: "teacher-1"},
+		&models.Enrollment{EnrollmentID: "enr-1", CourseID: "course-1", UserID: "student-1", Role: enums.EnrollmentRoleStudent},
+		&code)
+	codeType := string(enums.MaterialTypeCodeExercise)
+	createRows(t, db,
+		&models.CourseMaterial{MaterialID: code.MaterialID, CourseID: "course-1", Type: enums.MaterialTypeCodeExercise, ReferenceID: &code.MaterialID, ReferenceType: &codeType},
+		&models.TestCase{MaterialID: &code.MaterialID, MaterialType: codeType, InputData: types.JSONData(`[1,2]`), ExpectedOutput: types.JSONData(`{"output":3}`)})
+	return db, code.MaterialID
+}
+
+// newCodeSubmissionService returns a submission service grading synchronously with an
+// executor that answers every test case with {"output": 3}
+func newCodeSubmissionService(t *testing.T, db *gorm.DB, store storage.StorageService) *SubmissionService {
+	t.Helper()
+	return NewSubmissionService(db, nil, NewUserService(db), NewDeadlineCheckerService(db), nil,
+		NewCourseMaterialService(db, nil), fakeDockerExecutor(t, `{"output": 3}`), store, nil)
+}
+
+// TestSubmitCodeDeadline checks that code submissions get the same deadline and
+// availability checks as PDF submissions, and that a teacher submitting on a student's
+// behalf overrides the deadline but is still recorded as late
+func TestSubmitCodeDeadline(t *testing.T) {
+	past := time.Now().Add(-time.Hour).Format(time.RFC3339)
+	future := time.Now().Add(time.Hour).Format(time.RFC3339)
+
+	tests := []struct {
+		name     string
+		deadline string
+		hidden   bool
+		// onBehalf submits as teacher-1 for student-1
+		onBehalf bool
+		wantErr  error
+		wantLate bool
+	}{
+		{name: "no deadline"},
+		{name: "before the deadline", deadline: future},
+		{name: "after the deadline", deadline: past, wantErr: ErrSubmissionNotAllowed},
+		{name: "hidden exercise", hidden: true, wantErr: ErrSubmissionNotAllowed},
+		{name: "teacher before the deadline", deadline: future, onBehalf: true},
+		{name: "teacher after the deadline", deadline: past, onBehalf: true, wantLate: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, materialID := newCodeSubmissionDB(t)
+			if tt.deadline != "" {
+				if err := db.Model(&models.CodeExercise{}).Where("material_id = ?", materialID).Update("deadline", tt.deadline).Error; err != nil {
+					t.Fatalf("set deadline: %v", err)
+				}
+			}
+			if tt.hidden {
+				if err := db.Model(&models.CodeExercise{}).Where("material_id = ?", materialID).Update("is_public", false).Error; err != nil {
+					t.Fatalf("hide exercise: %v", err)
+				}
+			}
+			svc := newCodeSubmissionService(t, db, &uploadedCode{})
+
+			var err error
+			if tt.onBehalf {
+				_, err = svc.SubmitOnBehalf("teacher-1", "student-1", materialID, "print(3)")
+			} else {
+				_, err = svc.SubmitMaterialExercise("student-1", materialID, "print(3)")
+			}
+
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("err = %v, want %v", err, tt.wantErr)
+				}
+				var count int64
+				db.Model(&models.Submission{}).Count(&count)
+				if count != 0 {
+					t.Errorf("%d submissions stored, want none", count)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("submit: %v", err)
+			}
+			var sub models.Submission
+			if err := db.First(&sub, "user_id = ?", "student-1").Error; err != nil {
+				t.Fatalf("load submission: %v", err)
+			}
+			if sub.IsLateSubmission != tt.wantLate {
+				t.Errorf("late = %v, want %v", sub.IsLateSubmission, tt.wantLate)
+			}
+			if sub.PassedCount != 1 {
+				t.Errorf("passed = %d, want the submission graded with 1 passed", sub.PassedCount)
+			}
+		})
+	}
+}
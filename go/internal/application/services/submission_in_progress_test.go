@@ -0,0 +1,78 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	models "github.com/Project-DSView/backend/go/internal/domain/entities"
+	"github.com/Project-DSView/backend/go/internal/domain/enums"
+)
+
+// TestCheckNoActiveSubmission checks that a resubmission is refused while the student's
+// previous code execution job is queued or running, unless it is past the course's
+// retry window and presumed stuck
+func TestCheckNoActiveSubmission(t *testing.T) {
+	strPtr := func(s string) *string { return &s }
+	retryHours := 2
+
+	tests := []struct {
+		name    string
+		job     *models.QueueJob
+		wantErr error
+	}{
+		{name: "no earlier job"},
+		{
+			name:    "pending job",
+			job:     &models.QueueJob{Status: enums.QueueStatusPending, CreatedAt: time.Now().Add(-time.Minute)},
+			wantErr: ErrSubmissionInProgress,
+		},
+		{
+			name:    "processing job",
+			job:     &models.QueueJob{Status: enums.QueueStatusProcessing, CreatedAt: time.Now().Add(-time.Hour)},
+			wantErr: ErrSubmissionInProgress,
+		},
+		{
+			name: "job stuck past the course retry window",
+			job:  &models.QueueJob{Status: enums.QueueStatusProcessing, CreatedAt: time.Now().Add(-3 * time.Hour)},
+		},
+		{
+			name: "finished job",
+			job:  &models.QueueJob{Status: enums.QueueStatusCompleted, CreatedAt: time.Now().Add(-time.Minute)},
+		},
+		{
+			name: "another student's job",
+			job:  &models.QueueJob{Status: enums.QueueStatusPending, UserID: "student-2", CreatedAt: time.Now().Add(-time.Minute)},
+		},
+		{
+			name: "job for another exercise",
+			job:  &models.QueueJob{Status: enums.QueueStatusPending, MaterialID: strPtr("code-2"), CreatedAt: time.Now().Add(-time.Minute)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := newTestDB(t, &models.Course{}, &models.QueueJob{})
+			createRows(t, db, &models.Course{CourseID: "course-1", Name: "Data Structures", CreatedBy: "teacher-1",
+				Settings: models.CourseSettings{RetryWindowHours: &retryHours}})
+			if tt.job != nil {
+				tt.job.ID = "job-1"
+				tt.job.Type = enums.QueueTypeCodeExecution
+				tt.job.CourseID = strPtr("course-1")
+				if tt.job.UserID == "" {
+					tt.job.UserID = "student-1"
+				}
+				if tt.job.MaterialID == nil {
+					tt.job.MaterialID = strPtr("code-1")
+				}
+				createRows(t, db, tt.job)
+			}
+
+			svc := NewSubmissionService(db, nil, nil, nil, nil, nil, nil, nil, NewQueueService(db, nil, nil))
+			err := svc.checkNoActiveSubmission("student-1", "code-1")
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("checkNoActiveSubmission() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
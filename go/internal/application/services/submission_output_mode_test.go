@@ -0,0 +1,152 @@
+package services
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	models "github.com/Project-DSView/backend/go/internal/domain/entities"
+	"github.com/Project-DSView/backend/go/internal/domain/enums"
+	"github.com/Project-DSView/backend/go/internal/types"
+	"github.com/Project-DSView/backend/go/pkg/external"
+)
+
+// TestGradeCodeOutputMode checks how stdout is matched against a test case's expected
+// output in each output mode. The fake executor prints stdout followed by the newline
+// print() adds.
+func TestGradeCodeOutputMode(t *testing.T) {
+	tests := []struct {
+		name     string
+		mode     enums.OutputMode
+		expected string
+		stdout   string
+		want     string
+	}{
+		{"json value", enums.OutputModeJSON, `{"output":3}`, `{"output": 3}`, "passed"},
+		{"json wrong value", enums.OutputModeJSON, `{"output":3}`, `{"output": 4}`, "failed"},
+		{"json with an extra blank line", enums.OutputModeJSON, `[1,2]`, "[1, 2]\n", "passed"},
+		{"json rejects text", enums.OutputModeJSON, `"hello"`, `hello`, "error"},
+		{"json without output", enums.OutputModeJSON, `3`, ``, "error"},
+		{"default mode is json", "", `3`, `3`, "passed"},
+		{"plain text", enums.OutputModePlain, `"hello world"`, `hello world`, "passed"},
+		{"plain text with surrounding spaces", enums.OutputModePlain, `"hello"`, `  hello  `, "passed"},
+		{"plain never parses json", enums.OutputModePlain, `"[1, 2]"`, `[1,2]`, "failed"},
+		{"plain expected that is not a json string", enums.OutputModePlain, `42`, `42`, "passed"},
+		{"plain wrong text", enums.OutputModePlain, `"hello"`, `goodbye`, "failed"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := &SubmissionService{exec: fakeDockerExecutor(t, tt.stdout)}
+			exercise := &models.CodeExercise{OutputMode: string(tt.mode)}
+			testCases := []models.TestCase{{TestCaseID: "tc-1", InputData: types.JSONData(`[]`), ExpectedOutput: types.JSONData(tt.expected)}}
+
+			results, passed := svc.gradeCode(context.Background(), "sub-1", "print()", exercise, testCases)
+			if len(results) != 1 {
+				t.Fatalf("got %d results, want 1", len(results))
+			}
+			if results[0].Status != tt.want {
+				t.Errorf("status = %s, want %s (%s)", results[0].Status, tt.want, results[0].ErrorMessage)
+			}
+			wantPassed := 0
+			if tt.want == "passed" {
+				wantPassed = 1
+			}
+			if passed != wantPassed {
+				t.Errorf("passed = %d, want %d", passed, wantPassed)
+			}
+		})
+	}
+}
+
+// pythonDockerExecutor returns an executor whose docker command runs the container's
+// python command with the local python3, so runs go through the real code wrapper. The
+// test is skipped when python3 is not installed.
+func pythonDockerExecutor(t *testing.T) *external.DockerExecutor {
+	t.Helper()
+	if _, err := exec.LookPath("python3"); err != nil {
+		t.Skip("python3 not installed")
+	}
+	dir := t.TempDir()
+	script := "#!/bin/sh\nwhile [ $# -gt 0 ] && [ \"$1\" != python ]; do shift; done\nshift\nexec python3 \"$@\"\n"
+	if err := os.WriteFile(filepath.Join(dir, "docker"), []byte(script), 0o755); err != nil {
+		t.Fatalf("write fake docker: %v", err)
+	}
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	return external.NewDockerExecutor(external.DockerConfig{})
+}
+
+// TestGradeCodeOutputModeWithWrapper grades code run through the real wrapper, whose
+// JSON framing of stdout must not reach the plain mode comparison
+func TestGradeCodeOutputModeWithWrapper(t *testing.T) {
+	tests := []struct {
+		name     string
+		mode     enums.OutputMode
+		code     string
+		expected string
+		want     string
+	}{
+		{"json value", enums.OutputModeJSON, `print(3)`, `{"output":3}`, "passed"},
+		{"json list", enums.OutputModeJSON, `print([1, 2])`, `{"output":[1,2]}`, "passed"},
+		{"plain text", enums.OutputModePlain, `print("hello world")`, `"hello world"`, "passed"},
+		{"plain number is text", enums.OutputModePlain, `print(42)`, `"42"`, "passed"},
+		{"plain lines", enums.OutputModePlain, `print("a")` + "\n" + `print("b")`, `"a\nb"`, "passed"},
+		{"plain text is not parsed", enums.OutputModePlain, `print("[1, 2]")`, `"[1,2]"`, "failed"},
+		{"plain wrong text", enums.OutputModePlain, `print("goodbye")`, `"hello"`, "failed"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := &SubmissionService{exec: pythonDockerExecutor(t)}
+			exercise := &models.CodeExercise{OutputMode: string(tt.mode)}
+			testCases := []models.TestCase{{TestCaseID: "tc-1", InputData: types.JSONData(`{}`), ExpectedOutput: types.JSONData(tt.expected)}}
+
+			results, _ := svc.gradeCode(context.Background(), "sub-1", tt.code, exercise, testCases)
+			if len(results) != 1 {
+				t.Fatalf("got %d results, want 1", len(results))
+			}
+			if results[0].Status != tt.want {
+				t.Errorf("status = %s, want %s (%s)", results[0].Status, tt.want, results[0].ErrorMessage)
+			}
+		})
+	}
+}
+
+// TestGradeCodeTrimWhitespaceWithWrapper checks that whitespace the program prints around
+// its output reaches the comparison, which ignores it only when trim_whitespace is set
+func TestGradeCodeTrimWhitespaceWithWrapper(t *testing.T) {
+	tests := []struct {
+		name     string
+		mode     enums.OutputMode
+		trim     bool
+		code     string
+		expected string
+		want     string
+	}{
+		{"json trailing spaces trimmed", enums.OutputModeJSON, true, `print("hello  ")`, `{"output":"hello"}`, "passed"},
+		{"json trailing spaces strict", enums.OutputModeJSON, false, `print("hello  ")`, `{"output":"hello"}`, "failed"},
+		{"json exact strict", enums.OutputModeJSON, false, `print("hello")`, `{"output":"hello"}`, "passed"},
+		{"plain trailing spaces trimmed", enums.OutputModePlain, true, `print("hello  ")`, `"hello"`, "passed"},
+		{"plain trailing spaces strict", enums.OutputModePlain, false, `print("hello  ")`, `"hello"`, "failed"},
+		{"plain trailing spaces expected strict", enums.OutputModePlain, false, `print("hello  ")`, `"hello  "`, "passed"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := &SubmissionService{exec: pythonDockerExecutor(t)}
+			trim := tt.trim
+			exercise := &models.CodeExercise{OutputMode: string(tt.mode), TrimWhitespace: &trim}
+			testCases := []models.TestCase{{TestCaseID: "tc-1", InputData: types.JSONData(`{}`), ExpectedOutput: types.JSONData(tt.expected)}}
+
+			results, _ := svc.gradeCode(context.Background(), "sub-1", tt.code, exercise, testCases)
+			if len(results) != 1 {
+				t.Fatalf("got %d results, want 1", len(results))
+			}
+			if results[0].Status != tt.want {
+				t.Errorf("status = %s, want %s (%s)", results[0].Status, tt.want, results[0].ErrorMessage)
+			}
+		})
+	}
+}
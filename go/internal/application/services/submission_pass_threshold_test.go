@@ -0,0 +1,71 @@
+package services
+
+import (
+	"testing"
+
+	models "github.com/Project-DSView/backend/go/internal/domain/entities"
+	"github.com/Project-DSView/backend/go/internal/domain/enums"
+	"github.com/Project-DSView/backend/go/internal/types"
+)
+
+// TestGradePassThreshold checks that a graded submission makes the student's progress
+// ready for review once it passes the exercise's pass threshold. The submission passes
+// three of the exercise's four test cases.
+func TestGradePassThreshold(t *testing.T) {
+	threshold := func(percent int) *int { return &percent }
+
+	tests := []struct {
+		name      string
+		threshold *int
+		want      enums.ProgressStatus
+	}{
+		{"default requires every test case", nil, enums.ProgressNotStarted},
+		{"all test cases", threshold(100), enums.ProgressNotStarted},
+		{"above the share passed", threshold(80), enums.ProgressNotStarted},
+		{"exactly the share passed", threshold(75), enums.ProgressInProgress},
+		{"below the share passed", threshold(50), enums.ProgressInProgress},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := newTestDB(t, &models.Course{}, &models.CourseMaterial{}, &models.CodeExercise{}, &models.TestCase{},
+				&models.Submission{}, &models.SubmissionResult{}, &models.StudentProgress{}, &models.StudentCourseScore{})
+
+			points := 8
+			code := models.CodeExercise{
+				MaterialBase:     models.MaterialBase{CourseID: "course-1", Title: "Sum", CreatedBy: "teacher-1"},
+				TotalPoints:      &points,
+				ProblemStatement: "Add the numbers",
+			}
+			createRows(t, db, &models.Course{CourseID: "course-1", Name: "Data Structures", CreatedBy: "teacher-1"}, &code)
+			if tt.threshold != nil {
+				if err := db.Model(&code).Update("pass_threshold", *tt.threshold).Error; err != nil {
+					t.Fatalf("set pass threshold: %v", err)
+				}
+			}
+			codeType := string(enums.MaterialTypeCodeExercise)
+			createRows(t, db,
+				&models.CourseMaterial{MaterialID: code.MaterialID, CourseID: "course-1", Type: enums.MaterialTypeCodeExercise, ReferenceID: &code.MaterialID, ReferenceType: &codeType},
+				&models.Submission{SubmissionID: "sub-1", UserID: "student-1", MaterialID: code.MaterialID, Status: enums.SubmissionRunning})
+			for _, expected := range []string{`3`, `3`, `3`, `4`} {
+				createRows(t, db, &models.TestCase{MaterialID: &code.MaterialID, MaterialType: codeType, InputData: types.JSONData(`[]`), ExpectedOutput: types.JSONData(expected)})
+			}
+
+			svc := NewSubmissionService(db, nil, nil, nil, nil, NewCourseMaterialService(db, nil), fakeDockerExecutor(t, `3`), nil, nil)
+			if err := svc.ExecuteCodeSubmission("sub-1", "print(3)", code.MaterialID); err != nil {
+				t.Fatalf("grade: %v", err)
+			}
+
+			var progress models.StudentProgress
+			if err := db.First(&progress, "user_id = ? AND material_id = ?", "student-1", code.MaterialID).Error; err != nil {
+				t.Fatalf("load progress: %v", err)
+			}
+			if progress.Status != tt.want {
+				t.Errorf("progress status = %s, want %s", progress.Status, tt.want)
+			}
+			if progress.Score != 6 {
+				t.Errorf("progress score = %d, want 6 whatever the threshold", progress.Score)
+			}
+		})
+	}
+}
@@ -0,0 +1,108 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Project-DSView/backend/go/pkg/external"
+)
+
+// failingDockerExecutor puts a docker command first on PATH that fails the first
+// failures runs with stderr and exit code 125, then prints {"output": 3}. It returns the
+// file counting the runs.
+func failingDockerExecutor(t *testing.T, failures int, stderr string) (*external.DockerExecutor, string) {
+	t.Helper()
+	dir := t.TempDir()
+	countFile := filepath.Join(dir, "runs")
+	script := "#!/bin/sh\ncat >/dev/null\necho run >> \"$FAKE_DOCKER_RUNS\"\n" +
+		"if [ \"$(wc -l < \"$FAKE_DOCKER_RUNS\")\" -le \"$FAKE_DOCKER_FAILURES\" ]; then\n" +
+		"  printf '%s' \"$FAKE_DOCKER_STDERR\" >&2\n  exit 125\nfi\n" +
+		"echo '{\"output\": 3}'\n"
+	if err := os.WriteFile(filepath.Join(dir, "docker"), []byte(script), 0o755); err != nil {
+		t.Fatalf("write fake docker: %v", err)
+	}
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	t.Setenv("FAKE_DOCKER_RUNS", countFile)
+	t.Setenv("FAKE_DOCKER_FAILURES", strconv.Itoa(failures))
+	t.Setenv("FAKE_DOCKER_STDERR", stderr)
+	return external.NewDockerExecutor(external.DockerConfig{}), countFile
+}
+
+// TestRunPythonWithRetry checks that only failures of the docker daemon are retried, not
+// user code that happens to exit with the daemon's exit code
+func TestRunPythonWithRetry(t *testing.T) {
+	const daemonError = "docker: Error response from daemon: failed to create task.\n"
+	tests := []struct {
+		name     string
+		failures int
+		stderr   string
+		wantRuns int
+		wantExit int
+		wantErr  bool
+	}{
+		{name: "succeeds first time", failures: 0, wantRuns: 1},
+		{name: "daemon failure is retried", failures: 1, stderr: daemonError, wantRuns: 2},
+		{name: "daemon keeps failing", failures: maxExecAttempts, stderr: daemonError, wantRuns: maxExecAttempts, wantErr: true},
+		{name: "user code exiting 125 is not retried", failures: 1, stderr: "SystemExit: 125\n", wantRuns: 1, wantExit: 125},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			exec, countFile := failingDockerExecutor(t, tt.failures, tt.stderr)
+			svc := NewSubmissionService(nil, nil, nil, nil, nil, nil, exec, nil, nil)
+
+			res, err := svc.runPythonWithRetry(context.Background(), "print(3)", false, noStdin)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("runPythonWithRetry() error = %v, want error %v", err, tt.wantErr)
+			}
+			if err == nil && res.ExitCode != tt.wantExit {
+				t.Errorf("exit code = %d, want %d", res.ExitCode, tt.wantExit)
+			}
+			if got := countRuns(t, countFile); got != tt.wantRuns {
+				t.Errorf("docker ran %d times, want %d", got, tt.wantRuns)
+			}
+		})
+	}
+}
+
+// TestRunPythonWithRetryStopsWhenCancelled checks that the wait between retries ends as
+// soon as the run's context is done
+func TestRunPythonWithRetryStopsWhenCancelled(t *testing.T) {
+	exec, countFile := failingDockerExecutor(t, maxExecAttempts, "docker: Error response from daemon: failed to create task.\n")
+	svc := NewSubmissionService(nil, nil, nil, nil, nil, nil, exec, nil, nil)
+
+	ctx, cancel := context.WithTimeout(context.Background(), execRetryBackoff/5)
+	defer cancel()
+	started := time.Now()
+	_, err := svc.runPythonWithRetry(ctx, "print(3)", false, noStdin)
+	if !errors.Is(err, external.ErrRunCancelled) {
+		t.Fatalf("runPythonWithRetry() error = %v, want %v", err, external.ErrRunCancelled)
+	}
+	if elapsed := time.Since(started); elapsed >= execRetryBackoff {
+		t.Errorf("returned after %s, want before the first backoff of %s ends", elapsed, execRetryBackoff)
+	}
+	if got := countRuns(t, countFile); got != 1 {
+		t.Errorf("docker ran %d times, want 1", got)
+	}
+}
+
+func noStdin() (io.ReadCloser, error) {
+	return io.NopCloser(bytes.NewReader([]byte("[]"))), nil
+}
+
+func countRuns(t *testing.T, countFile string) int {
+	t.Helper()
+	data, err := os.ReadFile(countFile)
+	if err != nil {
+		t.Fatalf("read run count: %v", err)
+	}
+	return strings.Count(string(data), "\n")
+}
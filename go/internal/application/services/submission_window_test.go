@@ -0,0 +1,102 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	models "github.com/Project-DSView/backend/go/internal/domain/entities"
+	"github.com/Project-DSView/backend/go/internal/domain/enums"
+)
+
+// TestCanSubmitMaterialWindow checks that the submission window is a hard limit, and that
+// a deadline inside a window that closes later only marks submissions late
+func TestCanSubmitMaterialWindow(t *testing.T) {
+	now := time.Now()
+	at := func(d time.Duration) *time.Time {
+		v := now.Add(d)
+		return &v
+	}
+
+	tests := []struct {
+		name        string
+		opensAt     *time.Time
+		closesAt    *time.Time
+		deadline    *time.Time
+		wantAllowed bool
+		wantMessage string
+	}{
+		{name: "no window", wantAllowed: true},
+		{name: "before the window opens", opensAt: at(time.Hour), wantMessage: submissionNotOpenMessage},
+		{name: "inside the window", opensAt: at(-time.Hour), closesAt: at(time.Hour), wantAllowed: true},
+		{name: "window open on one side", opensAt: at(-time.Hour), wantAllowed: true},
+		{name: "after the window closes", closesAt: at(-time.Minute), wantMessage: submissionClosedMessage},
+		{name: "closed window ignores a later deadline", closesAt: at(-time.Minute), deadline: at(time.Hour), wantMessage: submissionClosedMessage},
+		{name: "deadline passed inside the window", opensAt: at(-2 * time.Hour), closesAt: at(time.Hour), deadline: at(-time.Hour), wantAllowed: true, wantMessage: deadlinePassedMessage},
+		{name: "deadline passed without a window", deadline: at(-time.Hour), wantMessage: deadlinePassedMessage},
+		{name: "deadline passed in a window without a close", opensAt: at(-2 * time.Hour), deadline: at(-time.Hour), wantMessage: deadlinePassedMessage},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := newTestDB(t, &models.CourseMaterial{}, &models.CodeExercise{}, &models.DeadlineExtension{})
+
+			points := 10
+			code := models.CodeExercise{
+				MaterialBase:     models.MaterialBase{CourseID: "course-1", Title: "Sum", CreatedBy: "teacher-1"},
+				TotalPoints:      &points,
+				ProblemStatement: "Add the numbers",
+				OpensAt:          tt.opensAt,
+				ClosesAt:         tt.closesAt,
+			}
+			if tt.deadline != nil {
+				deadline := tt.deadline.Format(time.RFC3339)
+				code.Deadline = &deadline
+			}
+			createRows(t, db, &code)
+			createRows(t, db, &models.CourseMaterial{MaterialID: code.MaterialID, CourseID: "course-1", Type: enums.MaterialTypeCodeExercise})
+
+			allowed, message, err := NewDeadlineCheckerService(db).CanSubmitMaterial("student-1", code.MaterialID)
+			if err != nil {
+				t.Fatalf("CanSubmitMaterial: %v", err)
+			}
+			if allowed != tt.wantAllowed || message != tt.wantMessage {
+				t.Errorf("CanSubmitMaterial = %v, %q, want %v, %q", allowed, message, tt.wantAllowed, tt.wantMessage)
+			}
+		})
+	}
+}
+
+func TestSubmissionWindowState(t *testing.T) {
+	now := time.Now()
+	at := func(d time.Duration) *time.Time {
+		v := now.Add(d)
+		return &v
+	}
+
+	tests := []struct {
+		name      string
+		opensAt   *time.Time
+		closesAt  *time.Time
+		wantState string
+		wantValid bool
+	}{
+		{"no window", nil, nil, models.SubmissionWindowNone, true},
+		{"not open", at(time.Hour), at(2 * time.Hour), models.SubmissionWindowNotOpen, true},
+		{"open", at(-time.Hour), at(time.Hour), models.SubmissionWindowOpen, true},
+		{"closes exactly now", at(-time.Hour), at(0), models.SubmissionWindowClosed, true},
+		{"closed", nil, at(-time.Hour), models.SubmissionWindowClosed, true},
+		{"closes before it opens", at(time.Hour), at(-time.Hour), models.SubmissionWindowNotOpen, false},
+		{"closes when it opens", at(time.Hour), at(time.Hour), models.SubmissionWindowNotOpen, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := models.SubmissionWindowState(tt.opensAt, tt.closesAt, now); got != tt.wantState {
+				t.Errorf("state = %s, want %s", got, tt.wantState)
+			}
+			if got := models.ValidSubmissionWindow(tt.opensAt, tt.closesAt); got != tt.wantValid {
+				t.Errorf("valid = %v, want %v", got, tt.wantValid)
+			}
+		})
+	}
+}
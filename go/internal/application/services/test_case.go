@@ -1,14 +1,82 @@
 package services
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
 
 	models "github.com/Project-DSView/backend/go/internal/domain/entities"
+	"github.com/Project-DSView/backend/go/internal/types"
 	"gorm.io/gorm"
 )
 
+// ErrTestCaseTooLarge is wrapped by errors returned when test case data exceeds TestCaseLimits
+var ErrTestCaseTooLarge = errors.New("test case data too large")
+
+// ErrInvalidInputKey is wrapped when a test case's input_key does not name one of the course's stored files
+var ErrInvalidInputKey = errors.New("invalid input key")
+
+// TestCaseLimits caps the stored size of a test case's input_data and expected_output.
+// Inputs are also piped to the executor's stdin, so this protects both the DB and grading.
+// Stored inputs (input_key) are not limited. A zero limit means unlimited.
+type TestCaseLimits struct {
+	MaxInputBytes  int
+	MaxOutputBytes int
+}
+
+// Check validates the encoded size of a test case's input and expected output
+func (l TestCaseLimits) Check(inputData, expectedOutput []byte) error {
+	if l.MaxInputBytes > 0 && len(inputData) > l.MaxInputBytes {
+		return fmt.Errorf("%w: input_data is %d bytes, maximum is %d bytes", ErrTestCaseTooLarge, len(inputData), l.MaxInputBytes)
+	}
+	if l.MaxOutputBytes > 0 && len(expectedOutput) > l.MaxOutputBytes {
+		return fmt.Errorf("%w: expected_output is %d bytes, maximum is %d bytes", ErrTestCaseTooLarge, len(expectedOutput), l.MaxOutputBytes)
+	}
+	return nil
+}
+
+// CheckTestCases validates every test case, reporting the 1-based position of the first bad one
+func (l TestCaseLimits) CheckTestCases(testCases []models.TestCase) error {
+	for i := range testCases {
+		if err := l.Check(testCases[i].InputData, testCases[i].ExpectedOutput); err != nil {
+			return fmt.Errorf("test case %d: %w", i+1, err)
+		}
+	}
+	return nil
+}
+
+// CheckUpdates validates input_data/expected_output values in an update map
+func (l TestCaseLimits) CheckUpdates(updates map[string]interface{}) error {
+	var inputData, expectedOutput []byte
+	var err error
+	if v, ok := updates["input_data"]; ok {
+		if inputData, err = encodedSize(v); err != nil {
+			return fmt.Errorf("invalid input_data: %w", err)
+		}
+	}
+	if v, ok := updates["expected_output"]; ok {
+		if expectedOutput, err = encodedSize(v); err != nil {
+			return fmt.Errorf("invalid expected_output: %w", err)
+		}
+	}
+	return l.Check(inputData, expectedOutput)
+}
+
+// encodedSize returns the JSON bytes that will be stored for an update value
+func encodedSize(v interface{}) ([]byte, error) {
+	switch data := v.(type) {
+	case []byte:
+		return data, nil
+	case types.JSONData:
+		return data, nil
+	default:
+		return json.Marshal(v)
+	}
+}
+
 type TestCaseService struct {
-	db *gorm.DB
+	db     *gorm.DB
+	limits TestCaseLimits
 }
 
 func NewTestCaseService(db *gorm.DB) *TestCaseService {
@@ -17,9 +85,22 @@ func NewTestCaseService(db *gorm.DB) *TestCaseService {
 	}
 }
 
+// SetLimits sets the maximum test case input/output sizes
+func (s *TestCaseService) SetLimits(limits TestCaseLimits) {
+	s.limits = limits
+}
+
+// Limits returns the maximum test case input/output sizes
+func (s *TestCaseService) Limits() TestCaseLimits {
+	return s.limits
+}
+
 // TestCase operations
 
 func (s *TestCaseService) CreateTestCase(testCase *models.TestCase) error {
+	if err := s.limits.Check(testCase.InputData, testCase.ExpectedOutput); err != nil {
+		return err
+	}
 	return s.db.Create(testCase).Error
 }
 
@@ -48,6 +129,9 @@ func (s *TestCaseService) GetTestCasesByMaterialIDWithFilter(materialID string,
 }
 
 func (s *TestCaseService) UpdateTestCase(testCaseID string, updates map[string]interface{}) error {
+	if err := s.limits.CheckUpdates(updates); err != nil {
+		return err
+	}
 	result := s.db.Model(&models.TestCase{}).Where("test_case_id = ?", testCaseID).Updates(updates)
 	if result.Error != nil {
 		return fmt.Errorf("failed to update test case: %w", result.Error)
@@ -0,0 +1,62 @@
+package services
+
+import (
+	"testing"
+
+	models "github.com/Project-DSView/backend/go/internal/domain/entities"
+	"github.com/Project-DSView/backend/go/internal/domain/enums"
+	"github.com/Project-DSView/backend/go/internal/types"
+	materialpkg "github.com/Project-DSView/backend/go/pkg/material"
+)
+
+// TestTestCaseSummary checks that the student view, the batch view and the teacher's edit
+// view of a code exercise all count its scored, example and hidden test cases
+func TestTestCaseSummary(t *testing.T) {
+	db := newTestDB(t, &models.User{}, &models.CourseMaterial{}, &models.CodeExercise{}, &models.TestCase{})
+	points := 10
+	for _, id := range []string{"code-1", "code-empty"} {
+		createMaterial(t, db, enums.MaterialTypeCodeExercise, &models.CodeExercise{
+			MaterialBase: models.MaterialBase{MaterialID: id, CourseID: "source", Title: id, CreatedBy: "teacher-1"},
+			TotalPoints:  &points,
+		})
+	}
+	codeID, codeType := "code-1", string(enums.MaterialTypeCodeExercise)
+	for _, isPublic := range []bool{true, false, false} {
+		createRows(t, db, &models.TestCase{MaterialID: &codeID, MaterialType: codeType, IsPublic: isPublic,
+			InputData: types.JSONData(`[1]`), ExpectedOutput: types.JSONData(`{"output":1}`)})
+	}
+	svc := NewCourseMaterialService(db, nil)
+
+	want := map[string]materialpkg.TestCaseSummary{
+		"code-1":     {Scored: 3, Examples: 1, Hidden: 2},
+		"code-empty": {},
+	}
+	check := func(view, id string, details map[string]interface{}) {
+		t.Helper()
+		if got := details["test_case_summary"]; got != want[id] {
+			t.Errorf("%s view of %s: test_case_summary = %#v, want %#v", view, id, got, want[id])
+		}
+	}
+
+	for id := range want {
+		details, err := svc.GetCourseMaterialByID(id)
+		if err != nil {
+			t.Fatalf("GetCourseMaterialByID(%s) error = %v", id, err)
+		}
+		check("student", id, details)
+
+		details, err = svc.GetMaterialForEdit(id, "teacher-1")
+		if err != nil {
+			t.Fatalf("GetMaterialForEdit(%s) error = %v", id, err)
+		}
+		check("edit", id, details)
+	}
+
+	batch, err := svc.GetMaterialsByIDs([]string{"code-1", "code-empty"})
+	if err != nil {
+		t.Fatalf("GetMaterialsByIDs() error = %v", err)
+	}
+	for id := range want {
+		check("batch", id, batch[id])
+	}
+}
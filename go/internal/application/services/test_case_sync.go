@@ -0,0 +1,177 @@
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+
+	models "github.com/Project-DSView/backend/go/internal/domain/entities"
+	"github.com/Project-DSView/backend/go/internal/domain/enums"
+	"github.com/Project-DSView/backend/go/internal/types"
+	materialpkg "github.com/Project-DSView/backend/go/pkg/material"
+	"gorm.io/gorm"
+)
+
+// Errors wrapped by SyncTestCases for an invalid entry
+var (
+	ErrUnknownTestCase    = errors.New("test case does not belong to this material")
+	ErrIncompleteTestCase = errors.New("a new test case needs input_data and expected_output")
+)
+
+// SyncTestCases makes a code exercise's test cases match changes, the complete list from
+// the edit form. Existing test cases are matched by ID: changed ones are updated in
+// place, with the fields an entry leaves out keeping their values, unchanged ones are
+// left alone, and those missing from the list are deleted. Entries without an ID are
+// created. Only public test cases are returned as examples. Test case IDs, and so the submission results that
+// refer to them, survive edits to other test cases. Everything happens in one
+// transaction.
+func (s *CourseMaterialService) SyncTestCases(materialID, userID string, changes []types.TestCaseChange) (*types.TestCaseSyncResult, error) {
+	var material models.CourseMaterial
+	if err := s.db.First(&material, "material_id = ?", materialID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("course material not found")
+		}
+		return nil, err
+	}
+
+	// Only code exercises have test cases
+	if !material.IsCodeExercise() {
+		return nil, errors.New("test cases can only be updated for code exercises")
+	}
+
+	// Get creator from actual material table
+	var createdBy string
+	if material.ReferenceID != nil && material.ReferenceType != nil {
+		createdBy, _ = materialpkg.GetMaterialCreator(s.db, *material.ReferenceID, *material.ReferenceType)
+	}
+
+	// Check if user is the creator of the material
+	if createdBy != "" && createdBy != userID {
+		return nil, errors.New("only the creator can update test cases")
+	}
+
+	for i, change := range changes {
+		if change.TestCaseID == "" && (change.InputData == nil || change.ExpectedOutput == nil) {
+			return nil, fmt.Errorf("test case %d: %w", i+1, ErrIncompleteTestCase)
+		}
+		if err := s.testCaseLimits.Check(change.InputData, change.ExpectedOutput); err != nil {
+			return nil, fmt.Errorf("test case %d: %w", i+1, err)
+		}
+	}
+
+	result := &types.TestCaseSyncResult{}
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var existing []models.TestCase
+		if err := tx.Where("material_id = ?", materialID).Find(&existing).Error; err != nil {
+			return fmt.Errorf("failed to get test cases: %w", err)
+		}
+		byID := make(map[string]*models.TestCase, len(existing))
+		for i := range existing {
+			byID[existing[i].TestCaseID] = &existing[i]
+		}
+
+		kept := make(map[string]bool, len(changes))
+		for _, change := range changes {
+			if change.TestCaseID == "" {
+				testCase := models.TestCase{
+					MaterialID:     &materialID,
+					MaterialType:   string(enums.MaterialTypeCodeExercise),
+					InputData:      change.InputData,
+					ExpectedOutput: change.ExpectedOutput,
+					IsPublic:       change.IsPublic == nil || *change.IsPublic,
+				}
+				if change.DisplayName != nil {
+					testCase.DisplayName = *change.DisplayName
+				}
+				if err := tx.Create(&testCase).Error; err != nil {
+					return fmt.Errorf("failed to create test case: %w", err)
+				}
+				result.Created++
+				addSyncExample(result, &testCase)
+				continue
+			}
+
+			current, ok := byID[change.TestCaseID]
+			if !ok || kept[change.TestCaseID] {
+				return fmt.Errorf("%w: %s", ErrUnknownTestCase, change.TestCaseID)
+			}
+			kept[change.TestCaseID] = true
+
+			updates := testCaseUpdates(current, change)
+			if len(updates) == 0 {
+				result.Unchanged++
+			} else {
+				if err := tx.Model(current).Updates(updates).Error; err != nil {
+					return fmt.Errorf("failed to update test case %s: %w", current.TestCaseID, err)
+				}
+				result.Updated++
+			}
+			addSyncExample(result, current)
+		}
+
+		var removed []string
+		for _, testCase := range existing {
+			if !kept[testCase.TestCaseID] {
+				removed = append(removed, testCase.TestCaseID)
+			}
+		}
+		if len(removed) > 0 {
+			if err := tx.Where("test_case_id IN ?", removed).Delete(&models.TestCase{}).Error; err != nil {
+				return fmt.Errorf("failed to delete test cases: %w", err)
+			}
+			result.Deleted = len(removed)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// addSyncExample adds a public test case's input and expected output to the examples of
+// a sync result; hidden test cases stay out of what students see
+func addSyncExample(result *types.TestCaseSyncResult, testCase *models.TestCase) {
+	if !testCase.IsPublic {
+		return
+	}
+	result.InputData = append(result.InputData, testCase.InputData)
+	result.ExpectedOutput = append(result.ExpectedOutput, testCase.ExpectedOutput)
+}
+
+// testCaseUpdates returns the columns of current that change sets to a different value.
+// JSON is compared by value, since the database does not keep the submitted formatting.
+// A new inline input replaces a stored one.
+func testCaseUpdates(current *models.TestCase, change types.TestCaseChange) map[string]interface{} {
+	updates := make(map[string]interface{})
+	if change.InputData != nil && !jsonEqual(current.InputData, change.InputData) {
+		updates["input_data"] = change.InputData
+		if current.HasStoredInput() {
+			updates["input_key"] = nil
+		}
+	}
+	if change.ExpectedOutput != nil && !jsonEqual(current.ExpectedOutput, change.ExpectedOutput) {
+		updates["expected_output"] = change.ExpectedOutput
+	}
+	if change.DisplayName != nil && current.DisplayName != *change.DisplayName {
+		updates["display_name"] = *change.DisplayName
+	}
+	if change.IsPublic != nil && current.IsPublic != *change.IsPublic {
+		updates["is_public"] = *change.IsPublic
+	}
+	return updates
+}
+
+// jsonEqual reports whether two JSON documents hold the same value
+func jsonEqual(a, b []byte) bool {
+	if bytes.Equal(a, b) {
+		return true
+	}
+	var va, vb interface{}
+	if json.Unmarshal(a, &va) != nil || json.Unmarshal(b, &vb) != nil {
+		return false
+	}
+	return reflect.DeepEqual(va, vb)
+}
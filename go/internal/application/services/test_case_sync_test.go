@@ -0,0 +1,241 @@
+package services
+
+import (
+	"errors"
+	"testing"
+
+	models "github.com/Project-DSView/backend/go/internal/domain/entities"
+	"github.com/Project-DSView/backend/go/internal/domain/enums"
+	"github.com/Project-DSView/backend/go/internal/types"
+)
+
+func TestSyncTestCases(t *testing.T) {
+	strPtr := func(s string) *string { return &s }
+	boolPtr := func(b bool) *bool { return &b }
+
+	tests := []struct {
+		name    string
+		changes func(inline, stored string) []types.TestCaseChange
+		wantErr error
+		// want maps the test cases left afterwards by display name to their input and output
+		want map[string][2]string
+		// wantStoredKey is the input key the stored-input test case should still have
+		wantStoredKey string
+		// wantExamples is how many of the test cases left are public and become examples
+		wantExamples int
+	}{
+		{
+			name: "entries with only an id keep every field",
+			changes: func(inline, stored string) []types.TestCaseChange {
+				return []types.TestCaseChange{{TestCaseID: inline}, {TestCaseID: stored}}
+			},
+			want: map[string][2]string{
+				"inline": {`[1,2]`, `{"output":3}`},
+				"stored": {`null`, `{"output":"big"}`},
+			},
+			wantStoredKey: "inputs/big.json",
+			wantExamples:  2,
+		},
+		{
+			name: "partial entries change only the fields they carry",
+			changes: func(inline, stored string) []types.TestCaseChange {
+				return []types.TestCaseChange{
+					{TestCaseID: inline, DisplayName: strPtr("renamed")},
+					{TestCaseID: stored, ExpectedOutput: types.JSONData(`{"output":"bigger"}`), IsPublic: boolPtr(false)},
+				}
+			},
+			want: map[string][2]string{
+				"renamed": {`[1,2]`, `{"output":3}`},
+				"stored":  {`null`, `{"output":"bigger"}`},
+			},
+			wantStoredKey: "inputs/big.json",
+			wantExamples:  1,
+		},
+		{
+			name: "omitted test cases are deleted and new ones created",
+			changes: func(inline, stored string) []types.TestCaseChange {
+				return []types.TestCaseChange{
+					{TestCaseID: stored},
+					{InputData: types.JSONData(`[5]`), ExpectedOutput: types.JSONData(`{"output":5}`), DisplayName: strPtr("new")},
+				}
+			},
+			want: map[string][2]string{
+				"stored": {`null`, `{"output":"big"}`},
+				"new":    {`[5]`, `{"output":5}`},
+			},
+			wantStoredKey: "inputs/big.json",
+			wantExamples:  2,
+		},
+		{
+			name: "new hidden test case is not an example",
+			changes: func(inline, stored string) []types.TestCaseChange {
+				return []types.TestCaseChange{
+					{TestCaseID: inline},
+					{InputData: types.JSONData(`[7]`), ExpectedOutput: types.JSONData(`{"output":"secret"}`), DisplayName: strPtr("hidden"), IsPublic: boolPtr(false)},
+				}
+			},
+			want: map[string][2]string{
+				"inline": {`[1,2]`, `{"output":3}`},
+				"hidden": {`[7]`, `{"output":"secret"}`},
+			},
+			wantExamples: 1,
+		},
+		{
+			name: "new inline input replaces a stored one",
+			changes: func(inline, stored string) []types.TestCaseChange {
+				return []types.TestCaseChange{{TestCaseID: inline}, {TestCaseID: stored, InputData: types.JSONData(`[9]`)}}
+			},
+			want: map[string][2]string{
+				"inline": {`[1,2]`, `{"output":3}`},
+				"stored": {`[9]`, `{"output":"big"}`},
+			},
+			wantExamples: 2,
+		},
+		{
+			name: "new entry without expected output is rejected",
+			changes: func(inline, stored string) []types.TestCaseChange {
+				return []types.TestCaseChange{{TestCaseID: inline}, {TestCaseID: stored}, {InputData: types.JSONData(`[5]`)}}
+			},
+			wantErr: ErrIncompleteTestCase,
+		},
+		{
+			name: "new entry without input is rejected",
+			changes: func(inline, stored string) []types.TestCaseChange {
+				return []types.TestCaseChange{{ExpectedOutput: types.JSONData(`{"output":5}`)}}
+			},
+			wantErr: ErrIncompleteTestCase,
+		},
+		{
+			name: "unknown id is rejected",
+			changes: func(inline, stored string) []types.TestCaseChange {
+				return []types.TestCaseChange{{TestCaseID: inline}, {TestCaseID: "not-a-test-case"}}
+			},
+			wantErr: ErrUnknownTestCase,
+		},
+		{
+			name: "repeated id is rejected",
+			changes: func(inline, stored string) []types.TestCaseChange {
+				return []types.TestCaseChange{{TestCaseID: inline}, {TestCaseID: inline}}
+			},
+			wantErr: ErrUnknownTestCase,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := newTestDB(t, &models.CourseMaterial{}, &models.CodeExercise{}, &models.TestCase{})
+
+			exercise := models.CodeExercise{
+				MaterialBase:     models.MaterialBase{CourseID: "course-1", Title: "Sum", CreatedBy: "teacher-1"},
+				TotalPoints:      new(int),
+				ProblemStatement: "Add the numbers",
+			}
+			if err := db.Create(&exercise).Error; err != nil {
+				t.Fatalf("create exercise: %v", err)
+			}
+			refType := string(enums.MaterialTypeCodeExercise)
+			material := models.CourseMaterial{
+				MaterialID:    exercise.MaterialID,
+				CourseID:      "course-1",
+				Type:          enums.MaterialTypeCodeExercise,
+				ReferenceID:   &exercise.MaterialID,
+				ReferenceType: &refType,
+			}
+			if err := db.Create(&material).Error; err != nil {
+				t.Fatalf("create material: %v", err)
+			}
+			inputKey := "inputs/big.json"
+			inline := models.TestCase{
+				MaterialID: &material.MaterialID, MaterialType: refType, DisplayName: "inline", IsPublic: true,
+				InputData: types.JSONData(`[1,2]`), ExpectedOutput: types.JSONData(`{"output":3}`),
+			}
+			stored := models.TestCase{
+				MaterialID: &material.MaterialID, MaterialType: refType, DisplayName: "stored", IsPublic: true,
+				InputData: types.JSONData(`null`), InputKey: &inputKey, ExpectedOutput: types.JSONData(`{"output":"big"}`),
+			}
+			for _, tc := range []*models.TestCase{&inline, &stored} {
+				if err := db.Create(tc).Error; err != nil {
+					t.Fatalf("create test case: %v", err)
+				}
+			}
+
+			svc := NewCourseMaterialService(db, nil)
+			result, err := svc.SyncTestCases(material.MaterialID, "teacher-1", tt.changes(inline.TestCaseID, stored.TestCaseID))
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("SyncTestCases() error = %v, want %v", err, tt.wantErr)
+				}
+				var count int64
+				db.Model(&models.TestCase{}).Where("material_id = ?", material.MaterialID).Count(&count)
+				if count != 2 {
+					t.Errorf("%d test cases left after a rejected sync, want 2", count)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("SyncTestCases() error = %v", err)
+			}
+			if len(result.InputData) != tt.wantExamples || len(result.ExpectedOutput) != tt.wantExamples {
+				t.Errorf("result lists %d example inputs and %d outputs, want %d", len(result.InputData), len(result.ExpectedOutput), tt.wantExamples)
+			}
+
+			var got []models.TestCase
+			if err := db.Where("material_id = ?", material.MaterialID).Find(&got).Error; err != nil {
+				t.Fatalf("get test cases: %v", err)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %d test cases, want %d", len(got), len(tt.want))
+			}
+			for _, tc := range got {
+				want, ok := tt.want[tc.DisplayName]
+				if !ok {
+					t.Errorf("unexpected test case %q", tc.DisplayName)
+					continue
+				}
+				if string(tc.InputData) != want[0] || string(tc.ExpectedOutput) != want[1] {
+					t.Errorf("test case %q = %s -> %s, want %s -> %s", tc.DisplayName, tc.InputData, tc.ExpectedOutput, want[0], want[1])
+				}
+				if !tc.IsPublic {
+					for _, output := range result.ExpectedOutput {
+						if jsonEqual(output, tc.ExpectedOutput) {
+							t.Errorf("hidden test case %q is listed as an example", tc.DisplayName)
+						}
+					}
+				}
+				if tc.TestCaseID == stored.TestCaseID {
+					gotKey := ""
+					if tc.InputKey != nil {
+						gotKey = *tc.InputKey
+					}
+					if gotKey != tt.wantStoredKey {
+						t.Errorf("stored test case input key = %q, want %q", gotKey, tt.wantStoredKey)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestSyncTestCasesRejectsOtherTeachers(t *testing.T) {
+	db := newTestDB(t, &models.CourseMaterial{}, &models.CodeExercise{}, &models.TestCase{})
+	exercise := models.CodeExercise{
+		MaterialBase:     models.MaterialBase{CourseID: "course-1", Title: "Sum", CreatedBy: "teacher-1"},
+		TotalPoints:      new(int),
+		ProblemStatement: "Add the numbers",
+	}
+	if err := db.Create(&exercise).Error; err != nil {
+		t.Fatalf("create exercise: %v", err)
+	}
+	refType := string(enums.MaterialTypeCodeExercise)
+	if err := db.Create(&models.CourseMaterial{
+		MaterialID: exercise.MaterialID, CourseID: "course-1", Type: enums.MaterialTypeCodeExercise,
+		ReferenceID: &exercise.MaterialID, ReferenceType: &refType,
+	}).Error; err != nil {
+		t.Fatalf("create material: %v", err)
+	}
+
+	_, err := NewCourseMaterialService(db, nil).SyncTestCases(exercise.MaterialID, "teacher-2", nil)
+	if err == nil || err.Error() != "only the creator can update test cases" {
+		t.Errorf("SyncTestCases() error = %v, want only the creator can update test cases", err)
+	}
+}
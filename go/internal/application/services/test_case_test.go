@@ -0,0 +1,101 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	models "github.com/Project-DSView/backend/go/internal/domain/entities"
+	"github.com/Project-DSView/backend/go/internal/domain/enums"
+	"github.com/Project-DSView/backend/go/internal/types"
+)
+
+func TestTestCaseSizeLimits(t *testing.T) {
+	limits := TestCaseLimits{MaxInputBytes: 8, MaxOutputBytes: 16}
+	small := types.JSONData(`[1,2]`)
+	largeInput := types.JSONData(`[1,2,3,4,5]`)
+	largeOutput := types.JSONData(`{"output":"too long"}`)
+
+	tests := []struct {
+		name    string
+		input   types.JSONData
+		output  types.JSONData
+		wantErr error
+	}{
+		{"within the limits", small, small, nil},
+		{"input too large", largeInput, small, ErrTestCaseTooLarge},
+		{"expected output too large", small, largeOutput, ErrTestCaseTooLarge},
+	}
+
+	for _, tt := range tests {
+		t.Run("create "+tt.name, func(t *testing.T) {
+			db := newTestDB(t, &models.TestCase{})
+			svc := NewTestCaseService(db)
+			svc.SetLimits(limits)
+
+			materialID := "code-1"
+			err := svc.CreateTestCase(&models.TestCase{MaterialID: &materialID, InputData: tt.input, ExpectedOutput: tt.output})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("CreateTestCase() error = %v, want %v", err, tt.wantErr)
+			}
+			var count int64
+			db.Model(&models.TestCase{}).Count(&count)
+			want := int64(1)
+			if tt.wantErr != nil {
+				want = 0
+			}
+			if count != want {
+				t.Errorf("%d test cases stored, want %d", count, want)
+			}
+		})
+
+		t.Run("update "+tt.name, func(t *testing.T) {
+			db := newTestDB(t, &models.TestCase{})
+			svc := NewTestCaseService(db)
+			svc.SetLimits(limits)
+
+			materialID := "code-1"
+			tc := models.TestCase{MaterialID: &materialID, InputData: small, ExpectedOutput: small}
+			createRows(t, db, &tc)
+
+			err := svc.UpdateTestCase(tc.TestCaseID, map[string]interface{}{"input_data": tt.input, "expected_output": tt.output})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("UpdateTestCase() error = %v, want %v", err, tt.wantErr)
+			}
+			var got models.TestCase
+			if err := db.First(&got, "test_case_id = ?", tc.TestCaseID).Error; err != nil {
+				t.Fatalf("load test case: %v", err)
+			}
+			wantInput := tt.input
+			if tt.wantErr != nil {
+				wantInput = small
+			}
+			if string(got.InputData) != string(wantInput) {
+				t.Errorf("input_data = %s, want %s", got.InputData, wantInput)
+			}
+		})
+	}
+}
+
+// TestAddTestCaseSizeLimit checks that test cases added to an exercise are held to the
+// material service's limits and the error names the offending field
+func TestAddTestCaseSizeLimit(t *testing.T) {
+	db := newTestDB(t, &models.CourseMaterial{}, &models.TestCase{})
+	createRows(t, db, &models.CourseMaterial{MaterialID: "code-1", CourseID: "course-1", Type: enums.MaterialTypeCodeExercise})
+	svc := NewCourseMaterialService(db, nil)
+	svc.SetTestCaseLimits(TestCaseLimits{MaxInputBytes: 8})
+
+	err := svc.AddTestCase("code-1", &models.TestCase{InputData: types.JSONData(`[1,2,3,4,5]`), ExpectedOutput: types.JSONData(`3`)})
+	if !errors.Is(err, ErrTestCaseTooLarge) || !strings.Contains(err.Error(), "input_data") {
+		t.Fatalf("AddTestCase() error = %v, want %v naming input_data", err, ErrTestCaseTooLarge)
+	}
+
+	if err := svc.AddTestCase("code-1", &models.TestCase{InputData: types.JSONData(`[1,2]`), ExpectedOutput: types.JSONData(`3`)}); err != nil {
+		t.Fatalf("AddTestCase() within the limit error = %v", err)
+	}
+	var count int64
+	db.Model(&models.TestCase{}).Where("material_id = ?", "code-1").Count(&count)
+	if count != 1 {
+		t.Errorf("%d test cases stored, want 1", count)
+	}
+}
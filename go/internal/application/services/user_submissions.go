@@ -0,0 +1,78 @@
+package services
+
+import (
+	"fmt"
+
+	models "github.com/Project-DSView/backend/go/internal/domain/entities"
+	"github.com/Project-DSView/backend/go/internal/types"
+)
+
+// GetUserRecentSubmissions returns one page of the user's submissions across every course
+// they are enrolled in, newest first, and the total count. Exercise titles and course names
+// are loaded for the whole page at once.
+func (s *SubmissionService) GetUserRecentSubmissions(userID string, page, limit int) ([]types.UserSubmission, int, error) {
+	enrolledExercises := s.db.Raw(`SELECT material_id FROM code_exercises WHERE course_id IN (SELECT course_id FROM enrollments WHERE user_id = ?)
+		UNION ALL SELECT material_id FROM pdf_exercises WHERE course_id IN (SELECT course_id FROM enrollments WHERE user_id = ?)`,
+		userID, userID)
+
+	var total int64
+	query := s.db.Model(&models.Submission{}).Where("user_id = ? AND material_id IN (?)", userID, enrolledExercises)
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count submissions: %w", err)
+	}
+
+	var submissions []models.Submission
+	offset := (page - 1) * limit
+	if err := query.Omit("code").Order("submitted_at DESC").Limit(limit).Offset(offset).Find(&submissions).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to get submissions: %w", err)
+	}
+	if len(submissions) == 0 {
+		return []types.UserSubmission{}, int(total), nil
+	}
+
+	materialIDs := make([]string, 0, len(submissions))
+	for _, submission := range submissions {
+		materialIDs = append(materialIDs, submission.MaterialID)
+	}
+
+	var exercises []struct {
+		MaterialID string
+		Title      string
+		CourseID   string
+		CourseName string
+	}
+	if err := s.db.Raw(`SELECT e.material_id, e.title, e.course_id, COALESCE(c.name, '') AS course_name
+		FROM (SELECT material_id, title, course_id FROM code_exercises WHERE material_id IN ?
+			UNION ALL SELECT material_id, title, course_id FROM pdf_exercises WHERE material_id IN ?) AS e
+		LEFT JOIN courses AS c ON c.course_id = e.course_id`,
+		materialIDs, materialIDs).Scan(&exercises).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to get exercises: %w", err)
+	}
+	exerciseByID := make(map[string]int, len(exercises))
+	for i, exercise := range exercises {
+		exerciseByID[exercise.MaterialID] = i
+	}
+
+	feed := make([]types.UserSubmission, 0, len(submissions))
+	for _, submission := range submissions {
+		entry := types.UserSubmission{
+			SubmissionID:     submission.SubmissionID,
+			MaterialID:       submission.MaterialID,
+			MaterialType:     submission.MaterialType,
+			Status:           string(submission.Status),
+			ReviewStatus:     submission.ReviewStatus,
+			TotalScore:       submission.TotalScore,
+			PassedCount:      submission.PassedCount,
+			FailedCount:      submission.FailedCount,
+			IsLateSubmission: submission.IsLateSubmission,
+			SubmittedAt:      submission.SubmittedAt,
+		}
+		if i, ok := exerciseByID[submission.MaterialID]; ok {
+			entry.MaterialTitle = exercises[i].Title
+			entry.CourseID = exercises[i].CourseID
+			entry.CourseName = exercises[i].CourseName
+		}
+		feed = append(feed, entry)
+	}
+	return feed, int(total), nil
+}
@@ -1,6 +1,8 @@
 package models
 
 import (
+	"time"
+
 	"github.com/Project-DSView/backend/go/internal/domain/enums"
 	"github.com/Project-DSView/backend/go/pkg/week"
 	"gorm.io/gorm"
@@ -10,7 +12,11 @@ import (
 // Uses MaterialBase for common fields and adds Content field
 type Announcement struct {
 	MaterialBase
-	Content string `json:"content" gorm:"type:text;not null"` // Announcement content
+	Content   string     `json:"content" gorm:"type:text;not null"`          // Announcement content
+	IsPinned  bool       `json:"is_pinned" gorm:"default:false;not null"`    // Pinned announcements are listed first
+	ExpiresAt *time.Time `json:"expires_at,omitempty" gorm:"type:timestamp"` // Hidden from students after this time
+	// Material this announcement was posted for, when generated on publish
+	LinkedMaterialID *string `json:"linked_material_id,omitempty" gorm:"type:varchar(36);index"`
 }
 
 // TableName returns the table name
@@ -28,6 +34,10 @@ func (a *Announcement) ToJSON() map[string]interface{} {
 	result := a.MaterialBase.ToJSONBase()
 	result["type"] = enums.MaterialTypeAnnouncement
 	result["content"] = a.Content
+	result["is_pinned"] = a.IsPinned
+	result["expires_at"] = a.ExpiresAt
+	result["is_expired"] = a.IsExpired(time.Now())
+	result["linked_material_id"] = a.LinkedMaterialID
 
 	if a.Creator.UserID != "" {
 		result["creator"] = a.Creator.ToJSON()
@@ -36,6 +46,11 @@ func (a *Announcement) ToJSON() map[string]interface{} {
 	return result
 }
 
+// IsExpired reports whether the announcement has passed its expiry time
+func (a *Announcement) IsExpired(now time.Time) bool {
+	return a.ExpiresAt != nil && !a.ExpiresAt.After(now)
+}
+
 // BeforeCreate sets the material ID if not already set
 func (a *Announcement) BeforeCreate(tx *gorm.DB) error {
 	return a.MaterialBase.BeforeCreate(tx)
@@ -1,6 +1,8 @@
 package models
 
 import (
+	"time"
+
 	"github.com/Project-DSView/backend/go/internal/domain/enums"
 	"github.com/Project-DSView/backend/go/internal/types"
 	"github.com/Project-DSView/backend/go/pkg/week"
@@ -19,6 +21,30 @@ type CodeExercise struct {
 	ExampleOutputs   types.JSONData `json:"example_outputs,omitempty" gorm:"type:jsonb;default:'[]'::jsonb"`
 	Constraints      string         `json:"constraints,omitempty" gorm:"type:text"`
 	Hints            string         `json:"hints,omitempty" gorm:"type:text"`
+	OutputMode       string         `json:"output_mode,omitempty" gorm:"type:varchar(10);not null;default:'json'"`
+	// TrimWhitespace ignores leading and trailing whitespace when comparing output (in
+	// plain text, or in JSON string values); nil means the default, true
+	TrimWhitespace *bool `json:"trim_whitespace" gorm:"not null;default:true"`
+	// NumberTolerance is how far apart numbers in JSON output may be and still match
+	// (relative above 1); nil means DefaultNumberTolerance
+	NumberTolerance *float64 `json:"number_tolerance" gorm:"not null;default:0.000000001"`
+	// PassThreshold is the percentage of test cases a submission must pass for the student's
+	// progress to become ready for review; nil means DefaultPassThreshold, all of them
+	PassThreshold *int `json:"pass_threshold" gorm:"not null;default:100"`
+	// LockAfterApproval rejects resubmissions once the student's progress is completed
+	LockAfterApproval bool `json:"lock_after_approval" gorm:"not null;default:false"`
+	// MaxConcurrentSubmissions caps how many submissions of this exercise are graded at
+	// once, e.g. during an exam; the rest wait in line. 0 = no cap
+	MaxConcurrentSubmissions int `json:"max_concurrent_submissions" gorm:"not null;default:0"`
+	// MaxAttempts is how many times a student may submit. 0 = unlimited
+	MaxAttempts int `json:"max_attempts" gorm:"not null;default:0"`
+	// Prerequisites are the material IDs of exercises in the same course a student must
+	// complete before submitting to this one
+	Prerequisites types.StringList `json:"prerequisites" gorm:"type:jsonb;not null;default:'[]'::jsonb"`
+	// OpensAt and ClosesAt bound when students may submit, e.g. a timed lab; nil leaves
+	// that side open. Inside the window the deadline only marks submissions late
+	OpensAt  *time.Time `json:"opens_at,omitempty" gorm:"type:timestamp"`
+	ClosesAt *time.Time `json:"closes_at,omitempty" gorm:"type:timestamp"`
 
 	// Relations
 	TestCases []TestCase `json:"test_cases,omitempty" gorm:"foreignKey:MaterialID;references:MaterialID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
@@ -46,6 +72,13 @@ func (ce *CodeExercise) ToJSON() map[string]interface{} {
 	if ce.Deadline != nil {
 		result["deadline"] = *ce.Deadline
 	}
+	if ce.OpensAt != nil {
+		result["opens_at"] = *ce.OpensAt
+	}
+	if ce.ClosesAt != nil {
+		result["closes_at"] = *ce.ClosesAt
+	}
+	result["submission_window"] = SubmissionWindowState(ce.OpensAt, ce.ClosesAt, time.Now())
 	if ce.IsGraded != nil {
 		result["is_graded"] = *ce.IsGraded
 	}
@@ -67,6 +100,14 @@ func (ce *CodeExercise) ToJSON() map[string]interface{} {
 	if ce.Hints != "" {
 		result["hints"] = ce.Hints
 	}
+	result["output_mode"] = ce.GetOutputMode()
+	result["trim_whitespace"] = ce.GetTrimWhitespace()
+	result["number_tolerance"] = ce.GetNumberTolerance()
+	result["pass_threshold"] = ce.GetPassThreshold()
+	result["lock_after_approval"] = ce.LockAfterApproval
+	result["max_concurrent_submissions"] = ce.MaxConcurrentSubmissions
+	result["max_attempts"] = ce.MaxAttempts
+	result["prerequisites"] = ce.Prerequisites
 
 	if ce.Creator.UserID != "" {
 		result["creator"] = ce.Creator.ToJSON()
@@ -89,6 +130,48 @@ func (ce *CodeExercise) BeforeCreate(tx *gorm.DB) error {
 	return ce.MaterialBase.BeforeCreate(tx)
 }
 
+// GetOutputMode returns how stdout is compared, defaulting to JSON
+func (ce *CodeExercise) GetOutputMode() enums.OutputMode {
+	if ce.OutputMode == "" {
+		return enums.OutputModeJSON
+	}
+	return enums.OutputMode(ce.OutputMode)
+}
+
+// GetTrimWhitespace reports whether whitespace around output is ignored, defaulting to true
+func (ce *CodeExercise) GetTrimWhitespace() bool {
+	return ce.TrimWhitespace == nil || *ce.TrimWhitespace
+}
+
+// DefaultNumberTolerance absorbs floating point noise such as 0.1+0.2 without accepting
+// wrong answers
+const DefaultNumberTolerance = 1e-9
+
+// GetNumberTolerance returns how far apart numbers may be and still match
+func (ce *CodeExercise) GetNumberTolerance() float64 {
+	if ce.NumberTolerance == nil {
+		return DefaultNumberTolerance
+	}
+	return *ce.NumberTolerance
+}
+
+// DefaultPassThreshold requires every test case to pass
+const DefaultPassThreshold = 100
+
+// GetPassThreshold returns the percentage of test cases a submission must pass
+func (ce *CodeExercise) GetPassThreshold() int {
+	if ce.PassThreshold == nil {
+		return DefaultPassThreshold
+	}
+	return *ce.PassThreshold
+}
+
+// MeetsPassThreshold reports whether passing passed of total test cases is enough for the
+// student's progress to become ready for review. An exercise without test cases never is.
+func (ce *CodeExercise) MeetsPassThreshold(passed, total int) bool {
+	return total > 0 && passed*100 >= ce.GetPassThreshold()*total
+}
+
 // IsCodeExercise returns true
 func (ce *CodeExercise) IsCodeExercise() bool {
 	return true
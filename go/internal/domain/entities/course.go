@@ -16,6 +16,7 @@ type Course struct {
 	CreatedBy   string             `json:"created_by" gorm:"type:varchar(36);not null"`
 	EnrollKey   string             `json:"enroll_key" gorm:"type:varchar(255);uniqueIndex;not null"`
 	Status      enums.CourseStatus `json:"status" gorm:"type:varchar(20);check:status IN ('active','archived');default:'active';not null"`
+	Settings    CourseSettings     `json:"settings" gorm:"type:jsonb;default:'{}'"`
 	CreatedAt   time.Time          `json:"created_at" gorm:"autoCreateTime"`
 	UpdatedAt   time.Time          `json:"updated_at" gorm:"autoUpdateTime"`
 
@@ -56,6 +57,7 @@ func (c *Course) ToJSON() map[string]interface{} {
 		"created_by":  c.CreatedBy,
 		"enroll_key":  c.EnrollKey,
 		"status":      c.Status,
+		"settings":    c.Settings.Resolved(),
 		"created_at":  c.CreatedAt,
 		"updated_at":  c.UpdatedAt,
 	}
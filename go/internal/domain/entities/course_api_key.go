@@ -0,0 +1,87 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/Project-DSView/backend/go/internal/domain/enums"
+	"github.com/Project-DSView/backend/go/internal/types"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// CourseAPIKey is an API key bound to a set of courses, used by external
+// systems such as an LMS. Only the SHA-256 hash of the key is stored.
+type CourseAPIKey struct {
+	KeyID      string           `json:"key_id" gorm:"primaryKey;type:varchar(36)"`
+	Name       string           `json:"name" gorm:"type:varchar(255);not null"`
+	KeyHash    string           `json:"-" gorm:"type:varchar(64);uniqueIndex;not null"`
+	KeyPrefix  string           `json:"key_prefix" gorm:"type:varchar(16);not null"`
+	CourseIDs  types.JSONData   `json:"course_ids" gorm:"type:jsonb;not null"`
+	Role       enums.APIKeyRole `json:"role" gorm:"type:varchar(20);not null;default:'read_only'"`
+	CreatedBy  string           `json:"created_by" gorm:"type:varchar(36);not null;index"`
+	ExpiresAt  *time.Time       `json:"expires_at" gorm:"type:timestamp"`
+	RevokedAt  *time.Time       `json:"revoked_at" gorm:"type:timestamp"`
+	LastUsedAt *time.Time       `json:"last_used_at" gorm:"type:timestamp"`
+	CreatedAt  time.Time        `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt  time.Time        `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+func (k *CourseAPIKey) BeforeCreate(tx *gorm.DB) error {
+	if k.KeyID == "" {
+		k.KeyID = uuid.New().String()
+	}
+	if k.Role == "" {
+		k.Role = enums.APIKeyRoleReadOnly
+	}
+	return nil
+}
+
+func (CourseAPIKey) TableName() string {
+	return "course_api_keys"
+}
+
+func (k *CourseAPIKey) ToJSON() map[string]interface{} {
+	return map[string]interface{}{
+		"key_id":       k.KeyID,
+		"name":         k.Name,
+		"key_prefix":   k.KeyPrefix,
+		"course_ids":   k.BoundCourseIDs(),
+		"role":         k.Role,
+		"created_by":   k.CreatedBy,
+		"expires_at":   k.ExpiresAt,
+		"revoked_at":   k.RevokedAt,
+		"last_used_at": k.LastUsedAt,
+		"is_active":    k.IsActive(),
+		"created_at":   k.CreatedAt,
+		"updated_at":   k.UpdatedAt,
+	}
+}
+
+// BoundCourseIDs returns the course IDs this key may access
+func (k *CourseAPIKey) BoundCourseIDs() []string {
+	var courseIDs []string
+	if len(k.CourseIDs) == 0 {
+		return courseIDs
+	}
+	_ = json.Unmarshal(k.CourseIDs, &courseIDs)
+	return courseIDs
+}
+
+// CanAccessCourse checks if the key is bound to the given course
+func (k *CourseAPIKey) CanAccessCourse(courseID string) bool {
+	for _, id := range k.BoundCourseIDs() {
+		if id == courseID {
+			return true
+		}
+	}
+	return false
+}
+
+// IsActive checks that the key has been neither revoked nor expired
+func (k *CourseAPIKey) IsActive() bool {
+	if k.RevokedAt != nil {
+		return false
+	}
+	return k.ExpiresAt == nil || time.Now().Before(*k.ExpiresAt)
+}
@@ -0,0 +1,42 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// CourseCompletion records the first time a student completed every exercise
+// in a course. The unique (user_id, course_id) pair keeps completion events
+// from firing more than once.
+type CourseCompletion struct {
+	CompletionID string     `json:"completion_id" gorm:"primaryKey;type:varchar(36)"`
+	UserID       string     `json:"user_id" gorm:"type:varchar(36);not null;uniqueIndex:idx_course_completion_user_course"`
+	CourseID     string     `json:"course_id" gorm:"type:varchar(36);not null;uniqueIndex:idx_course_completion_user_course;index"`
+	CompletedAt  time.Time  `json:"completed_at" gorm:"type:timestamp;not null"`
+	NotifiedAt   *time.Time `json:"notified_at" gorm:"type:timestamp"`
+	CreatedAt    time.Time  `json:"created_at" gorm:"autoCreateTime"`
+}
+
+func (cc *CourseCompletion) BeforeCreate(tx *gorm.DB) error {
+	if cc.CompletionID == "" {
+		cc.CompletionID = uuid.New().String()
+	}
+	return nil
+}
+
+func (CourseCompletion) TableName() string {
+	return "course_completions"
+}
+
+func (cc *CourseCompletion) ToJSON() map[string]interface{} {
+	return map[string]interface{}{
+		"completion_id": cc.CompletionID,
+		"user_id":       cc.UserID,
+		"course_id":     cc.CourseID,
+		"completed_at":  cc.CompletedAt,
+		"notified_at":   cc.NotifiedAt,
+		"created_at":    cc.CreatedAt,
+	}
+}
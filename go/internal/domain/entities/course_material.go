@@ -1,6 +1,7 @@
 package models
 
 import (
+	"fmt"
 	"time"
 
 	"github.com/Project-DSView/backend/go/internal/domain/enums"
@@ -29,6 +30,21 @@ func (cm *CourseMaterial) BeforeCreate(tx *gorm.DB) error {
 	if cm.MaterialID == "" {
 		cm.MaterialID = uuid.New().String()
 	}
+	return cm.ValidateReferenceType()
+}
+
+// ValidateReferenceType checks that reference_type, when set, is a known material type
+// and the material's own type, since it names the table reference_id is looked up in
+func (cm *CourseMaterial) ValidateReferenceType() error {
+	if cm.ReferenceType == nil {
+		return nil
+	}
+	if !IsValidMaterialType(*cm.ReferenceType) {
+		return fmt.Errorf("invalid reference type %q", *cm.ReferenceType)
+	}
+	if enums.MaterialType(*cm.ReferenceType) != cm.Type {
+		return fmt.Errorf("reference type %q does not match material type %q", *cm.ReferenceType, cm.Type)
+	}
 	return nil
 }
 
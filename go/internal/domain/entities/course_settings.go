@@ -0,0 +1,158 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// Default values used when a course has not configured a setting
+const (
+	DefaultRetryWindowHours = 24
+	// 0 disables week auto-increment and the default deadline for new materials
+	DefaultWeekIncrement        = 0
+	DefaultDeadlineOffsetDays   = 0
+	DefaultAnnounceNewMaterials = false
+	// 0 deletes submissions as soon as their material's deadline passes
+	DefaultSubmissionRetentionDays = 0
+	DefaultResultCacheEnabled      = false
+)
+
+// RetainSubmissionsIndefinitely is the SubmissionRetentionDays value that keeps a course's
+// submissions and their files forever, e.g. for accreditation
+const RetainSubmissionsIndefinitely = -1
+
+// CourseSettings holds per-course feature flags and policies.
+// Fields are pointers so that "unset" can be told apart from an explicit zero;
+// use the accessor methods to read values with defaults applied.
+type CourseSettings struct {
+	RetryWindowHours *int `json:"retry_window_hours,omitempty"`
+	// New materials without a week go this many weeks after the course's latest week
+	WeekIncrement *int `json:"week_increment,omitempty"`
+	// New exercises without a deadline are due this many days after creation
+	DeadlineOffsetDays *int `json:"deadline_offset_days,omitempty"`
+	// Post an announcement linking to each material when it is published
+	AnnounceNewMaterials *bool `json:"announce_new_materials,omitempty"`
+	// Submissions and their files are deleted this many days after the material's
+	// deadline; RetainSubmissionsIndefinitely keeps them
+	SubmissionRetentionDays *int `json:"submission_retention_days,omitempty"`
+	// Grade a code submission identical to an earlier one by reusing the earlier results,
+	// as long as the test cases have not changed since
+	ResultCacheEnabled *bool `json:"result_cache_enabled,omitempty"`
+}
+
+// GetRetryWindow returns how long a student must wait before retrying a queue job
+func (s CourseSettings) GetRetryWindow() time.Duration {
+	hours := DefaultRetryWindowHours
+	if s.RetryWindowHours != nil {
+		hours = *s.RetryWindowHours
+	}
+	return time.Duration(hours) * time.Hour
+}
+
+// GetWeekIncrement returns how many weeks after the latest one a new material is placed; 0 = off
+func (s CourseSettings) GetWeekIncrement() int {
+	if s.WeekIncrement == nil {
+		return DefaultWeekIncrement
+	}
+	return *s.WeekIncrement
+}
+
+// GetDeadlineOffset returns the default time from creation to an exercise's deadline; 0 = off
+func (s CourseSettings) GetDeadlineOffset() time.Duration {
+	days := DefaultDeadlineOffsetDays
+	if s.DeadlineOffsetDays != nil {
+		days = *s.DeadlineOffsetDays
+	}
+	return time.Duration(days) * 24 * time.Hour
+}
+
+// IsAnnounceNewMaterials reports whether publishing a material posts an announcement for it
+func (s CourseSettings) IsAnnounceNewMaterials() bool {
+	if s.AnnounceNewMaterials == nil {
+		return DefaultAnnounceNewMaterials
+	}
+	return *s.AnnounceNewMaterials
+}
+
+// GetSubmissionRetentionDays returns how many days after a deadline submissions are kept,
+// or RetainSubmissionsIndefinitely
+func (s CourseSettings) GetSubmissionRetentionDays() int {
+	if s.SubmissionRetentionDays == nil {
+		return DefaultSubmissionRetentionDays
+	}
+	return *s.SubmissionRetentionDays
+}
+
+// IsResultCacheEnabled reports whether identical code submissions reuse earlier results
+func (s CourseSettings) IsResultCacheEnabled() bool {
+	if s.ResultCacheEnabled == nil {
+		return DefaultResultCacheEnabled
+	}
+	return *s.ResultCacheEnabled
+}
+
+// Merge applies the non-nil fields of updates on top of the current settings
+func (s CourseSettings) Merge(updates CourseSettings) CourseSettings {
+	if updates.RetryWindowHours != nil {
+		s.RetryWindowHours = updates.RetryWindowHours
+	}
+	if updates.WeekIncrement != nil {
+		s.WeekIncrement = updates.WeekIncrement
+	}
+	if updates.DeadlineOffsetDays != nil {
+		s.DeadlineOffsetDays = updates.DeadlineOffsetDays
+	}
+	if updates.AnnounceNewMaterials != nil {
+		s.AnnounceNewMaterials = updates.AnnounceNewMaterials
+	}
+	if updates.SubmissionRetentionDays != nil {
+		s.SubmissionRetentionDays = updates.SubmissionRetentionDays
+	}
+	if updates.ResultCacheEnabled != nil {
+		s.ResultCacheEnabled = updates.ResultCacheEnabled
+	}
+	return s
+}
+
+// Resolved returns the effective settings with every default filled in
+func (s CourseSettings) Resolved() map[string]interface{} {
+	return map[string]interface{}{
+		"retry_window_hours":        int(s.GetRetryWindow() / time.Hour),
+		"week_increment":            s.GetWeekIncrement(),
+		"deadline_offset_days":      int(s.GetDeadlineOffset() / (24 * time.Hour)),
+		"announce_new_materials":    s.IsAnnounceNewMaterials(),
+		"submission_retention_days": s.GetSubmissionRetentionDays(),
+		"result_cache_enabled":      s.IsResultCacheEnabled(),
+	}
+}
+
+// Value implements the driver.Valuer interface for jsonb storage
+func (s CourseSettings) Value() (driver.Value, error) {
+	return json.Marshal(s)
+}
+
+// Scan implements the sql.Scanner interface for jsonb reading
+func (s *CourseSettings) Scan(value interface{}) error {
+	if value == nil {
+		*s = CourseSettings{}
+		return nil
+	}
+
+	var bytes []byte
+	switch v := value.(type) {
+	case []byte:
+		bytes = v
+	case string:
+		bytes = []byte(v)
+	default:
+		return errors.New("cannot scan non-json value into CourseSettings")
+	}
+
+	if len(bytes) == 0 {
+		*s = CourseSettings{}
+		return nil
+	}
+	return json.Unmarshal(bytes, s)
+}
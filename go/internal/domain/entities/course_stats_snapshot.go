@@ -0,0 +1,35 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// CourseStatsSnapshot records a course's aggregate figures at one point in time. A
+// scheduled task takes one per active course each night, so dashboards and reports can
+// read them instead of running the aggregate queries on every request.
+type CourseStatsSnapshot struct {
+	SnapshotID    string `json:"snapshot_id" gorm:"primaryKey;type:varchar(36)"`
+	CourseID      string `json:"course_id" gorm:"type:varchar(36);not null;index:idx_course_stats_course_taken"`
+	StudentCount  int64  `json:"student_count" gorm:"not null;default:0"`
+	ExerciseCount int64  `json:"exercise_count" gorm:"not null;default:0"`
+	// Percentage of exercises completed, averaged over the enrolled students
+	CompletionRate float64 `json:"completion_rate" gorm:"not null;default:0"`
+	// Mean course score of the enrolled students
+	AverageScore    float64   `json:"average_score" gorm:"not null;default:0"`
+	SubmissionCount int64     `json:"submission_count" gorm:"not null;default:0"`
+	TakenAt         time.Time `json:"taken_at" gorm:"type:timestamp;not null;index:idx_course_stats_course_taken"`
+}
+
+func (s *CourseStatsSnapshot) BeforeCreate(tx *gorm.DB) error {
+	if s.SnapshotID == "" {
+		s.SnapshotID = uuid.New().String()
+	}
+	return nil
+}
+
+func (CourseStatsSnapshot) TableName() string {
+	return "course_stats_snapshots"
+}
@@ -0,0 +1,45 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// DeadlineExtension moves one student's deadline for an exercise. The row doubles as
+// the audit record of the latest grant: who granted it, when and why.
+type DeadlineExtension struct {
+	ExtensionID string    `json:"extension_id" gorm:"primaryKey;type:varchar(36)"`
+	MaterialID  string    `json:"material_id" gorm:"type:varchar(36);not null;uniqueIndex:idx_deadline_extension_material_user"`
+	UserID      string    `json:"user_id" gorm:"type:varchar(36);not null;uniqueIndex:idx_deadline_extension_material_user;index"`
+	Deadline    time.Time `json:"deadline" gorm:"type:timestamp;not null"`
+	Reason      string    `json:"reason" gorm:"type:text;not null"`
+	GrantedBy   string    `json:"granted_by" gorm:"type:varchar(36);not null"`
+	CreatedAt   time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt   time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+func (de *DeadlineExtension) BeforeCreate(tx *gorm.DB) error {
+	if de.ExtensionID == "" {
+		de.ExtensionID = uuid.New().String()
+	}
+	return nil
+}
+
+func (DeadlineExtension) TableName() string {
+	return "deadline_extensions"
+}
+
+func (de *DeadlineExtension) ToJSON() map[string]interface{} {
+	return map[string]interface{}{
+		"extension_id": de.ExtensionID,
+		"material_id":  de.MaterialID,
+		"user_id":      de.UserID,
+		"deadline":     de.Deadline,
+		"reason":       de.Reason,
+		"granted_by":   de.GrantedBy,
+		"created_at":   de.CreatedAt,
+		"updated_at":   de.UpdatedAt,
+	}
+}
@@ -10,8 +10,8 @@ import (
 
 type Enrollment struct {
 	EnrollmentID string               `json:"enrollment_id" gorm:"primaryKey;type:varchar(36)"`
-	CourseID     string               `json:"course_id" gorm:"type:varchar(36);not null;index"`
-	UserID       string               `json:"user_id" gorm:"type:varchar(36);not null;index"`
+	CourseID     string               `json:"course_id" gorm:"type:varchar(36);not null;index;uniqueIndex:idx_enrollments_course_user"`
+	UserID       string               `json:"user_id" gorm:"type:varchar(36);not null;index;uniqueIndex:idx_enrollments_course_user"` // One enrollment per user per course
 	Role         enums.EnrollmentRole `json:"role" gorm:"type:varchar(20);check:role IN ('student','ta','teacher');default:'student';not null"`
 	EnrolledAt   time.Time            `json:"enrolled_at" gorm:"autoCreateTime"`
 	UpdatedAt    time.Time            `json:"updated_at" gorm:"autoUpdateTime"`
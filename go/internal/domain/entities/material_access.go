@@ -0,0 +1,30 @@
+package models
+
+import (
+	"time"
+
+	"github.com/Project-DSView/backend/go/internal/domain/enums"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// MaterialAccess records a student viewing or downloading a document or video material,
+// for the engagement report teachers see
+type MaterialAccess struct {
+	AccessID   string                   `json:"access_id" gorm:"primaryKey;type:varchar(36)"`
+	MaterialID string                   `json:"material_id" gorm:"type:varchar(36);not null;index:idx_material_access_material_user"`
+	UserID     string                   `json:"user_id" gorm:"type:varchar(36);not null;index:idx_material_access_material_user"`
+	Kind       enums.MaterialAccessKind `json:"kind" gorm:"type:varchar(20);not null"`
+	AccessedAt time.Time                `json:"accessed_at" gorm:"type:timestamp;not null"`
+}
+
+func (ma *MaterialAccess) BeforeCreate(tx *gorm.DB) error {
+	if ma.AccessID == "" {
+		ma.AccessID = uuid.New().String()
+	}
+	return nil
+}
+
+func (MaterialAccess) TableName() string {
+	return "material_accesses"
+}
@@ -3,6 +3,7 @@ package models
 import (
 	"time"
 
+	"github.com/Project-DSView/backend/go/internal/types"
 	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
@@ -19,6 +20,9 @@ type MaterialBase struct {
 	CreatedAt   time.Time `json:"created_at" gorm:"autoCreateTime"`
 	UpdatedAt   time.Time `json:"updated_at" gorm:"autoUpdateTime"`
 
+	// Free-form labels for organizing materials across weeks, normalized by material.NormalizeTags
+	Tags types.StringList `json:"tags" gorm:"type:jsonb;not null;default:'[]'::jsonb"`
+
 	// Relations
 	Course  Course `json:"course,omitempty" gorm:"foreignKey:CourseID;references:CourseID"`
 	Creator User   `json:"creator,omitempty" gorm:"foreignKey:CreatedBy;references:UserID"`
@@ -71,6 +75,11 @@ func (mb *MaterialBase) GetIsPublic() bool {
 	return mb.IsPublic
 }
 
+// GetTags returns the material's tags
+func (mb *MaterialBase) GetTags() []string {
+	return mb.Tags
+}
+
 // GetCreatedBy returns the creator user ID
 func (mb *MaterialBase) GetCreatedBy() string {
 	return mb.CreatedBy
@@ -102,10 +111,11 @@ func (mb *MaterialBase) ToJSONBase() map[string]interface{} {
 		"title":       mb.Title,
 		"description": mb.Description,
 		"week":        mb.Week,
-		"is_public":    mb.IsPublic,
-		"created_by":   mb.CreatedBy,
-		"created_at":   mb.CreatedAt,
-		"updated_at":   mb.UpdatedAt,
+		"is_public":   mb.IsPublic,
+		"tags":        mb.Tags,
+		"created_by":  mb.CreatedBy,
+		"created_at":  mb.CreatedAt,
+		"updated_at":  mb.UpdatedAt,
 	}
 }
 
@@ -1,7 +1,10 @@
 package models
 
 import (
+	"time"
+
 	"github.com/Project-DSView/backend/go/internal/domain/enums"
+	"github.com/Project-DSView/backend/go/internal/types"
 	"github.com/Project-DSView/backend/go/pkg/week"
 	"gorm.io/gorm"
 )
@@ -11,11 +14,24 @@ type PDFExercise struct {
 	MaterialBase
 	TotalPoints *int    `json:"total_points,omitempty" gorm:"type:int;not null"`
 	Deadline    *string `json:"deadline,omitempty" gorm:"type:varchar(50)"`
-	IsGraded     *bool   `json:"is_graded,omitempty" gorm:"type:boolean;default:true"`
+	IsGraded    *bool   `json:"is_graded,omitempty" gorm:"type:boolean;default:true"`
 	FileURL     string  `json:"file_url" gorm:"type:text;not null"`
 	FileName    string  `json:"file_name" gorm:"type:varchar(255);not null"`
 	FileSize    int64   `json:"file_size" gorm:"type:bigint;default:0"`
 	MimeType    string  `json:"mime_type" gorm:"type:varchar(100)"`
+	// MaxPages overrides the configured page limit for submissions; nil uses the default, 0 = unlimited
+	MaxPages *int `json:"max_pages,omitempty" gorm:"type:int"`
+	// LockAfterApproval rejects resubmissions once the student's progress is completed
+	LockAfterApproval bool `json:"lock_after_approval" gorm:"not null;default:false"`
+	// MaxAttempts is how many times a student may submit. 0 = unlimited
+	MaxAttempts int `json:"max_attempts" gorm:"not null;default:0"`
+	// Prerequisites are the material IDs of exercises in the same course a student must
+	// complete before submitting to this one
+	Prerequisites types.StringList `json:"prerequisites" gorm:"type:jsonb;not null;default:'[]'::jsonb"`
+	// OpensAt and ClosesAt bound when students may submit, e.g. a timed lab; nil leaves
+	// that side open. Inside the window the deadline only marks submissions late
+	OpensAt  *time.Time `json:"opens_at,omitempty" gorm:"type:timestamp"`
+	ClosesAt *time.Time `json:"closes_at,omitempty" gorm:"type:timestamp"`
 }
 
 // TableName returns the table name
@@ -40,13 +56,26 @@ func (pe *PDFExercise) ToJSON() map[string]interface{} {
 	if pe.Deadline != nil {
 		result["deadline"] = *pe.Deadline
 	}
+	if pe.OpensAt != nil {
+		result["opens_at"] = *pe.OpensAt
+	}
+	if pe.ClosesAt != nil {
+		result["closes_at"] = *pe.ClosesAt
+	}
+	result["submission_window"] = SubmissionWindowState(pe.OpensAt, pe.ClosesAt, time.Now())
 	if pe.IsGraded != nil {
 		result["is_graded"] = *pe.IsGraded
 	}
+	if pe.MaxPages != nil {
+		result["max_pages"] = *pe.MaxPages
+	}
 	result["file_url"] = pe.FileURL
 	result["file_name"] = pe.FileName
 	result["file_size"] = pe.FileSize
 	result["mime_type"] = pe.MimeType
+	result["lock_after_approval"] = pe.LockAfterApproval
+	result["max_attempts"] = pe.MaxAttempts
+	result["prerequisites"] = pe.Prerequisites
 
 	if pe.Creator.UserID != "" {
 		result["creator"] = pe.Creator.ToJSON()
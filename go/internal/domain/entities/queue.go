@@ -28,13 +28,16 @@ type QueueJob struct {
 	UpdatedAt    time.Time         `json:"updated_at" gorm:"autoUpdateTime"`
 	StartedAt    *time.Time        `json:"started_at"`
 	CompletedAt  *time.Time        `json:"completed_at"`
+	// Held is set on a pending job waiting for a slot under its exercise's
+	// max_concurrent_submissions; it is published once one frees up
+	Held bool `json:"held" gorm:"not null;default:false"`
 
 	// Relations (without foreign key constraints to avoid database issues)
-	User           *User           `json:"user,omitempty" gorm:"-"`
-	CourseMaterial *CourseMaterial `json:"course_material,omitempty" gorm:"-"`
-	Course         *Course         `json:"course,omitempty" gorm:"-"`
-	ProcessedByUser *User          `json:"processed_by_user,omitempty" gorm:"-"` // User who processed/claimed the job
-	ReviewStatus   *string         `json:"review_status,omitempty" gorm:"-"`     // Review status from submission ('approved' or 'rejected')
+	User            *User           `json:"user,omitempty" gorm:"-"`
+	CourseMaterial  *CourseMaterial `json:"course_material,omitempty" gorm:"-"`
+	Course          *Course         `json:"course,omitempty" gorm:"-"`
+	ProcessedByUser *User           `json:"processed_by_user,omitempty" gorm:"-"` // User who processed/claimed the job
+	ReviewStatus    *string         `json:"review_status,omitempty" gorm:"-"`     // Review status from submission ('approved' or 'rejected')
 }
 
 func (q *QueueJob) BeforeCreate(tx *gorm.DB) error {
@@ -71,6 +74,7 @@ func (q *QueueJob) ToJSON() map[string]interface{} {
 		"updated_at":    q.UpdatedAt,
 		"started_at":    q.StartedAt,
 		"completed_at":  q.CompletedAt,
+		"held":          q.Held,
 	}
 
 	if q.User != nil {
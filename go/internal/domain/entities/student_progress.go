@@ -9,13 +9,15 @@ import (
 // StudentProgress represents the domain entity for student progress
 type StudentProgress struct {
 	ProgressID      string                `json:"progress_id" gorm:"primaryKey;type:varchar(36)"`
-	UserID          string                `json:"user_id" gorm:"type:varchar(36);index;not null"`
-	MaterialID      string                `json:"material_id" gorm:"type:varchar(36);index;not null"` // สำหรับ course materials
+	UserID          string                `json:"user_id" gorm:"type:varchar(36);index;not null;uniqueIndex:idx_student_progress_user_material"`
+	MaterialID      string                `json:"material_id" gorm:"type:varchar(36);index;not null;uniqueIndex:idx_student_progress_user_material"` // สำหรับ course materials
 	MaterialType    string                `json:"material_type,omitempty" gorm:"type:varchar(20);index"` // Polymorphic: video, document, code_exercise, pdf_exercise
 	Status          enums.ProgressStatus  `json:"status" gorm:"type:varchar(20);not null;default:'not_started'"`
 	Score           int                   `json:"score" gorm:"default:0;not null"`
 	SeatNumber      string                `json:"seat_number" gorm:"type:varchar(50)"`
 	LastSubmittedAt *time.Time            `json:"last_submitted_at" gorm:"type:timestamp"`
+	// AttemptCount is how many times the student has submitted; only changed by claimAttempt
+	AttemptCount    int                   `json:"attempt_count" gorm:"not null;default:0"`
 	CreatedAt       time.Time             `json:"created_at" gorm:"autoCreateTime"`
 	UpdatedAt       time.Time             `json:"updated_at" gorm:"autoUpdateTime"`
 }
@@ -25,13 +25,22 @@ type Submission struct {
 	Status           enums.SubmissionStatus `json:"status" gorm:"type:varchar(20);not null;default:'pending'"`
 	IsLateSubmission bool                   `json:"is_late_submission" gorm:"default:false;not null"` // ส่งช้าหรือไม่ (สำหรับ practice)
 	ErrorMessage     string                 `json:"error_message" gorm:"type:text"`
-	Feedback         string                 `json:"feedback" gorm:"type:text"`             // คำติชมจากอาจารย์/TA
-	FeedbackFileURL  string                 `json:"feedback_file_url" gorm:"type:text"`    // URL ของไฟล์ feedback ที่อาจารย์/TA อัปโหลด
-	GradedAt         *time.Time             `json:"graded_at" gorm:"type:timestamp"`       // เวลาที่ตรวจแล้ว
-	GradedBy         string                 `json:"graded_by" gorm:"type:varchar(36)"`     // ID ของอาจารย์/TA ที่ตรวจ
-	ReviewStatus     string                 `json:"review_status" gorm:"type:varchar(20)"` // 'approved' or 'rejected'
-	QueueJobID       *string                `json:"queue_job_id" gorm:"type:varchar(36)"`  // Link to queue job
+	Feedback         string                 `json:"feedback" gorm:"type:text"`                      // คำติชมจากอาจารย์/TA
+	FeedbackFileURL  string                 `json:"feedback_file_url" gorm:"type:text"`             // URL ของไฟล์ feedback ที่อาจารย์/TA อัปโหลด
+	GradedAt         *time.Time             `json:"graded_at" gorm:"type:timestamp"`                // เวลาที่ตรวจแล้ว
+	GradedBy         string                 `json:"graded_by" gorm:"type:varchar(36)"`              // ID ของอาจารย์/TA ที่ตรวจ
+	ReviewStatus     string                 `json:"review_status" gorm:"type:varchar(20)"`          // 'approved' or 'rejected'
+	QueueJobID       *string                `json:"queue_job_id" gorm:"type:varchar(36)"`           // Link to queue job
+	SubmittedBy      *string                `json:"submitted_by,omitempty" gorm:"type:varchar(36)"` // Teacher who submitted on the student's behalf (nil for the student's own submission)
 	SubmittedAt      time.Time              `json:"submitted_at" gorm:"autoCreateTime"`
+	// PriorProgressStatus is the student's progress status before this code submission reset
+	// it for re-approval; it is restored if the submission is cancelled
+	PriorProgressStatus *enums.ProgressStatus `json:"-" gorm:"type:varchar(20)"`
+	// CodeHash and TestSetHash identify the code and the test cases a code submission was
+	// graded against, so an identical later submission can reuse its results (see the
+	// result_cache_enabled course setting). They stay nil when the run is not reusable.
+	CodeHash    *string `json:"-" gorm:"type:varchar(64);index:idx_submission_result_cache"`
+	TestSetHash *string `json:"-" gorm:"type:varchar(64);index:idx_submission_result_cache"`
 
 	Results []SubmissionResult `json:"results,omitempty" gorm:"foreignKey:SubmissionID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
 }
@@ -71,6 +80,17 @@ func (s *Submission) GetReferenceID() string {
 	return s.MaterialID
 }
 
+// SlowestResult returns the test case result with the longest execution time, or nil if there are none
+func (s *Submission) SlowestResult() *SubmissionResult {
+	var slowest *SubmissionResult
+	for i := range s.Results {
+		if slowest == nil || s.Results[i].ExecutionTimeMs > slowest.ExecutionTimeMs {
+			slowest = &s.Results[i]
+		}
+	}
+	return slowest
+}
+
 // IsMaterialBased returns true (always true now)
 func (s *Submission) IsMaterialBased() bool {
 	return true
@@ -83,7 +103,9 @@ type SubmissionResult struct {
 	Status       string         `json:"status" gorm:"type:varchar(10);not null"`
 	ActualOutput types.JSONData `json:"actual_output" gorm:"type:jsonb"`
 	ErrorMessage string         `json:"error_message" gorm:"type:text"`
-	CreatedAt    time.Time
+	// ExecutionTimeMs is the wall-clock execution time of this test case in milliseconds
+	ExecutionTimeMs int64 `json:"execution_time_ms" gorm:"type:bigint;default:0;not null"`
+	CreatedAt       time.Time
 }
 
 func (sr *SubmissionResult) BeforeCreate(tx *gorm.DB) error {
@@ -0,0 +1,32 @@
+package models
+
+import "time"
+
+// Where the current time falls in an exercise's submission window, reported as submission_window
+const (
+	SubmissionWindowNone    = "none" // no window is set; only the deadline applies
+	SubmissionWindowNotOpen = "not_open"
+	SubmissionWindowOpen    = "open"
+	SubmissionWindowClosed  = "closed"
+)
+
+// SubmissionWindowState reports where now falls in the window from opensAt to closesAt.
+// Either end may be nil, leaving the window unbounded on that side.
+func SubmissionWindowState(opensAt, closesAt *time.Time, now time.Time) string {
+	switch {
+	case opensAt == nil && closesAt == nil:
+		return SubmissionWindowNone
+	case opensAt != nil && now.Before(*opensAt):
+		return SubmissionWindowNotOpen
+	case closesAt != nil && !now.Before(*closesAt):
+		return SubmissionWindowClosed
+	default:
+		return SubmissionWindowOpen
+	}
+}
+
+// ValidSubmissionWindow reports whether a window closes after it opens; a window with an
+// open end is always valid
+func ValidSubmissionWindow(opensAt, closesAt *time.Time) bool {
+	return opensAt == nil || closesAt == nil || closesAt.After(*opensAt)
+}
@@ -9,10 +9,13 @@ import (
 )
 
 type TestCase struct {
-	TestCaseID     string         `json:"test_case_id" gorm:"primaryKey;type:varchar(36)"`
-	MaterialID     *string        `json:"material_id,omitempty" gorm:"type:varchar(36);index"` // For course material test cases
-	MaterialType   string         `json:"material_type,omitempty" gorm:"type:varchar(20);index"` // Polymorphic: code_exercise (only code exercises have test cases)
-	InputData      types.JSONData `json:"input_data" gorm:"type:jsonb;not null"`
+	TestCaseID   string         `json:"test_case_id" gorm:"primaryKey;type:varchar(36)"`
+	MaterialID   *string        `json:"material_id,omitempty" gorm:"type:varchar(36);index"`   // For course material test cases
+	MaterialType string         `json:"material_type,omitempty" gorm:"type:varchar(20);index"` // Polymorphic: code_exercise (only code exercises have test cases)
+	InputData    types.JSONData `json:"input_data" gorm:"type:jsonb;not null"`
+	// InputKey names a storage object holding the JSON input, streamed to the program instead
+	// of InputData, for inputs too large to keep in the row
+	InputKey       *string        `json:"input_key,omitempty" gorm:"type:text"`
 	ExpectedOutput types.JSONData `json:"expected_output" gorm:"type:jsonb;not null"`
 	IsPublic       bool           `json:"is_public" gorm:"default:false;not null"`         // Whether this test case is visible to students
 	DisplayName    string         `json:"display_name,omitempty" gorm:"type:varchar(255)"` // Human-readable name for the test case
@@ -57,6 +60,10 @@ func (tc *TestCase) ToJSON() map[string]interface{} {
 		"updated_at":      tc.UpdatedAt,
 	}
 
+	if tc.HasStoredInput() {
+		result["input_key"] = *tc.InputKey
+	}
+
 	// Add material_id if present (for new system)
 	if tc.MaterialID != nil {
 		result["material_id"] = *tc.MaterialID
@@ -70,6 +77,11 @@ func (tc *TestCase) ToJSON() map[string]interface{} {
 	return result
 }
 
+// HasStoredInput reports whether the input is read from storage rather than InputData
+func (tc *TestCase) HasStoredInput() bool {
+	return tc.InputKey != nil && *tc.InputKey != ""
+}
+
 // Helper methods for test case types
 func (tc *TestCase) IsCodeExercise() bool {
 	return tc.MaterialID != nil && tc.CourseMaterial != nil && tc.CourseMaterial.IsCodeExercise()
@@ -0,0 +1,56 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// UserSession is one signed-in device. Each login gets its own row holding the
+// Google refresh token for that device, so sessions can be listed and revoked
+// individually instead of sharing the single token stored on the user.
+type UserSession struct {
+	SessionID    string     `json:"session_id" gorm:"primaryKey;type:varchar(36)"`
+	UserID       string     `json:"user_id" gorm:"type:varchar(36);not null;index"`
+	RefreshToken string     `json:"-" gorm:"type:text"` // Hide from JSON
+	UserAgent    string     `json:"user_agent" gorm:"type:text"`
+	IPAddress    string     `json:"ip_address" gorm:"type:varchar(64)"`
+	LastUsedAt   time.Time  `json:"last_used_at" gorm:"type:timestamp;not null"`
+	RevokedAt    *time.Time `json:"revoked_at" gorm:"type:timestamp"`
+	CreatedAt    time.Time  `json:"created_at" gorm:"autoCreateTime"`
+}
+
+func (s *UserSession) BeforeCreate(tx *gorm.DB) error {
+	if s.SessionID == "" {
+		s.SessionID = uuid.New().String()
+	}
+	if s.LastUsedAt.IsZero() {
+		s.LastUsedAt = time.Now()
+	}
+	return nil
+}
+
+func (UserSession) TableName() string {
+	return "user_sessions"
+}
+
+// IsActive reports whether the session can still be refreshed
+func (s *UserSession) IsActive(maxAge time.Duration) bool {
+	if s.RevokedAt != nil {
+		return false
+	}
+	return time.Since(s.LastUsedAt) <= maxAge
+}
+
+func (s *UserSession) ToJSON() map[string]interface{} {
+	return map[string]interface{}{
+		"session_id":   s.SessionID,
+		"user_id":      s.UserID,
+		"user_agent":   s.UserAgent,
+		"ip_address":   s.IPAddress,
+		"last_used_at": s.LastUsedAt,
+		"revoked_at":   s.RevokedAt,
+		"created_at":   s.CreatedAt,
+	}
+}
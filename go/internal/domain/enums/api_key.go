@@ -0,0 +1,18 @@
+package enums
+
+// APIKeyRole represents what a course-scoped API key is allowed to do
+type APIKeyRole string
+
+const (
+	APIKeyRoleReadOnly APIKeyRole = "read_only"
+)
+
+// IsValidAPIKeyRole checks if the API key role is valid
+func IsValidAPIKeyRole(role string) bool {
+	switch APIKeyRole(role) {
+	case APIKeyRoleReadOnly:
+		return true
+	default:
+		return false
+	}
+}
@@ -18,3 +18,21 @@ func IsValidExerciseStatus(status string) bool {
 		return false
 	}
 }
+
+// OutputMode controls how a code exercise compares student stdout with the expected output
+type OutputMode string
+
+const (
+	OutputModeJSON  OutputMode = "json"  // stdout is parsed as JSON and compared structurally
+	OutputModePlain OutputMode = "plain" // stdout is compared as trimmed text, never parsed
+)
+
+// IsValidOutputMode checks if the output mode is valid
+func IsValidOutputMode(mode string) bool {
+	switch OutputMode(mode) {
+	case OutputModeJSON, OutputModePlain:
+		return true
+	default:
+		return false
+	}
+}
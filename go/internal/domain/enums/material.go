@@ -10,3 +10,11 @@ const (
 	MaterialTypeCodeExercise MaterialType = "code_exercise" // แบบฝึกหัดโค้ด (Code exercises)
 	MaterialTypePDFExercise  MaterialType = "pdf_exercise"  // แบบฝึกหัด PDF (PDF exercises)
 )
+
+// MaterialAccessKind is how a student accessed a document or video material
+type MaterialAccessKind string
+
+const (
+	MaterialAccessView     MaterialAccessKind = "view"     // Opened the material
+	MaterialAccessDownload MaterialAccessKind = "download" // Downloaded its file
+)
@@ -8,6 +8,7 @@ const (
 	SubmissionRunning   SubmissionStatus = "running"
 	SubmissionError     SubmissionStatus = "error"
 	SubmissionCompleted SubmissionStatus = "completed"
+	SubmissionCancelled SubmissionStatus = "cancelled" // Stopped by the student before grading finished
 )
 
 // VerificationStatus represents the verification status
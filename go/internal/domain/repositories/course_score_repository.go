@@ -23,6 +23,9 @@ type CourseScoreRepository interface {
 	// GetStudentScoreStats gets statistics for a student's scores across all courses
 	GetStudentScoreStats(ctx context.Context, userID string) (*StudentScoreStats, error)
 
+	// GetByCourse gets all course scores for a course
+	GetByCourse(ctx context.Context, courseID string) ([]entities.CourseScore, error)
+
 	// BatchGetByUserAndCourses gets multiple course scores by user ID and course IDs
 	BatchGetByUserAndCourses(ctx context.Context, userID string, courseIDs []string) ([]entities.CourseScore, error)
 }
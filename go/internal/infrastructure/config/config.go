@@ -9,22 +9,33 @@ import (
 )
 
 type Config struct {
-	Server   ServerConfig
-	Database DatabaseConfig
-	Google   GoogleConfig
-	JWT      JWTConfig
-	Frontend FrontendConfig
-	Fastapi  FastapiConfig
-	Executor ExecutorConfig
-	MinIO    MinIOConfig
-	RabbitMQ RabbitMQConfig
-	APIKey   APIKeyConfig
+	Server     ServerConfig
+	Database   DatabaseConfig
+	Google     GoogleConfig
+	JWT        JWTConfig
+	Frontend   FrontendConfig
+	Fastapi    FastapiConfig
+	Executor   ExecutorConfig
+	MinIO      MinIOConfig
+	Storage    StorageConfig
+	RabbitMQ   RabbitMQConfig
+	APIKey     APIKeyConfig
+	Admin      AdminConfig
+	Webhook    WebhookConfig
+	Queue      QueueConfig
+	Submission SubmissionConfig
+	Upload     UploadConfig
+	Scheduler  SchedulerConfig
 }
 
 type ServerConfig struct {
 	Port        string
 	Host        string
 	Environment string
+	// Largest page size a listing returns, whatever limit is requested
+	MaxPageLimit int
+	// Format of the log files: "json" for log aggregators or "text" for reading
+	LogFormat string
 }
 
 // database configuration
@@ -59,6 +70,9 @@ type FrontendConfig struct {
 	AuthSuccessPath string
 	AuthErrorPath   string
 	AllowedOrigins  []string
+	// StreamingAllowedOrigins are the origins allowed to fetch file streams
+	// (PDF submissions, feedback files) with credentials
+	StreamingAllowedOrigins []string
 }
 
 type FastapiConfig struct {
@@ -74,6 +88,15 @@ type ExecutorConfig struct {
 	Timeout time.Duration
 	Memory  string
 	CPUs    string
+	// Maximum containers running at once on this host, across all courses and job types
+	MaxContainers int
+	// Maximum stored size (bytes, JSON-encoded) of a test case's input and expected output; 0 = unlimited
+	MaxTestCaseInputBytes  int
+	MaxTestCaseOutputBytes int
+	// User (uid:gid or name) student code runs as inside the container
+	User string
+	// Keep the container's root filesystem read-only; only /tmp is writable
+	ReadOnlyRootFS bool
 }
 
 type MinIOConfig struct {
@@ -87,6 +110,22 @@ type MinIOConfig struct {
 	PublicBucket    bool
 }
 
+// StorageConfig selects the file storage backend. "minio" is the default; "local" stores
+// files on disk and serves them from the API so development works without MinIO.
+type StorageConfig struct {
+	Backend         string
+	LocalPath       string // Directory for the local backend
+	LocalBaseURL    string // Public URL of the files route, e.g. http://localhost:8080/files
+	LocalSigningKey string // Signs presigned local URLs; defaults to the JWT secret
+	LocalPublicRead bool   // Serve unsigned reads, like a public bucket
+}
+
+// Storage backends accepted in StorageConfig.Backend
+const (
+	StorageBackendMinIO = "minio"
+	StorageBackendLocal = "local"
+)
+
 type RabbitMQConfig struct {
 	URL      string
 	Exchange string
@@ -95,6 +134,13 @@ type RabbitMQConfig struct {
 	Host     string
 	Port     int
 	VHost    string
+	// Prepended to every queue name so environments can share a broker; empty keeps plain names
+	QueuePrefix string
+
+	// Publish retry for transient broker errors
+	PublishMaxAttempts int
+	PublishBackoff     time.Duration // Initial delay, doubled after each failed attempt
+	PublishMaxBackoff  time.Duration
 }
 
 type APIKeyConfig struct {
@@ -102,6 +148,67 @@ type APIKeyConfig struct {
 	APIKey     string
 }
 
+// AdminConfig lists the users allowed to run admin actions across courses, such as
+// transferring a course to a new owner
+type AdminConfig struct {
+	Emails []string
+}
+
+// QueueConfig holds per-queue-type worker limits for the RabbitMQ consumers
+type QueueConfig struct {
+	CodeExecutionConcurrency  int
+	FileProcessingConcurrency int
+	// How long a job may stay in processing before it is reported as stuck
+	StuckJobThreshold time.Duration
+	// How long shutdown waits for messages being handled before resetting their jobs to pending
+	DrainTimeout time.Duration
+	// What archiving a course does with its queued grading jobs: "finish" or "cancel"
+	ArchiveJobPolicy string
+	// How long the finish policy waits for an archived course's jobs before cancelling the rest
+	ArchiveFinishTimeout time.Duration
+}
+
+// SubmissionConfig holds limits applied to student file submissions
+type SubmissionConfig struct {
+	// Default maximum page count of a PDF submission; exercises may override it. 0 = unlimited
+	PDFMaxPages int
+}
+
+// UploadConfig holds the maximum size of an uploaded file per kind, e.g. "10MB".
+// The same limits are enforced on the request body before it is buffered and on
+// the parsed file in handlers.
+type UploadConfig struct {
+	MaxPDFSize      string // PDF exercises, PDF submissions and feedback files
+	MaxDocumentSize string // Document materials
+	MaxImageSize    string // Course and problem images
+	// Longest uploaded filename kept after sanitizing, in bytes
+	MaxFilenameLength int
+	// Server-wide request body limit; empty or below the largest file limit (plus
+	// multipart overhead) uses that instead
+	MaxBodySize string
+	// Request bodies up to this size are read into memory; larger ones are read from
+	// the connection as they are parsed, with multipart file parts kept in temporary files
+	MultipartMemoryLimit string
+}
+
+// SchedulerConfig holds the run intervals of the periodic background tasks; 0 disables a task
+type SchedulerConfig struct {
+	SubmissionCleanupInterval time.Duration // Deletes submissions past their deadline and course retention period
+	QueueJobCleanupInterval   time.Duration // Deletes finished queue jobs past their retention period
+	StuckJobCheckInterval     time.Duration // Logs a warning while jobs are stuck in processing
+	HeldJobReleaseInterval    time.Duration // Admits submissions held by a per-exercise concurrency cap whose slots freed up
+	MaterialIntegrityInterval time.Duration // Logs a warning while course materials and their specific records disagree
+	CourseStatsInterval       time.Duration // Snapshots the aggregate stats of every active course for dashboards and reports
+}
+
+// WebhookConfig configures outbound event notifications to external systems
+type WebhookConfig struct {
+	CourseCompletedURL   string // Empty disables the course.completed event
+	MaterialPublishedURL string // Empty disables the material.published event
+	Secret               string // Used to sign payloads (HMAC-SHA256)
+	Timeout              time.Duration
+}
+
 func Load(env string) (*Config, error) {
 	config := &Config{}
 
@@ -110,6 +217,9 @@ func Load(env string) (*Config, error) {
 		Host:        getEnvOrDefault("SERVER_HOST", "127.0.0.1"),
 		Port:        getEnvOrDefault("SERVER_PORT", "8080"),
 		Environment: getEnvOrDefault("SERVER_ENV", env),
+
+		MaxPageLimit: getEnvAsInt("SERVER_MAX_PAGE_LIMIT", 100),
+		LogFormat:    getEnvOrDefault("LOG_FORMAT", "json"),
 	}
 
 	// Load database configuration
@@ -159,6 +269,7 @@ func Load(env string) (*Config, error) {
 			"http://127.0.0.1:5500",
 			"http://localhost:5500",
 		}),
+		StreamingAllowedOrigins: getEnvAsStringSlice("FRONTEND_STREAMING_ALLOWED_ORIGINS", nil),
 	}
 
 	// Load FastAPI configuration
@@ -176,6 +287,14 @@ func Load(env string) (*Config, error) {
 		Timeout: getEnvAsDuration("EXECUTOR_TIMEOUT", 15*time.Second),
 		Memory:  getEnvOrDefault("EXECUTOR_MEMORY", "512m"),
 		CPUs:    getEnvOrDefault("EXECUTOR_CPUS", "1.0"),
+
+		MaxContainers: getEnvAsInt("EXECUTOR_MAX_CONTAINERS", 8),
+
+		MaxTestCaseInputBytes:  getEnvAsInt("EXECUTOR_MAX_TEST_CASE_INPUT_BYTES", 64*1024),
+		MaxTestCaseOutputBytes: getEnvAsInt("EXECUTOR_MAX_TEST_CASE_OUTPUT_BYTES", 64*1024),
+
+		User:           getEnvOrDefault("EXECUTOR_USER", "65534:65534"),
+		ReadOnlyRootFS: getEnvAsBool("EXECUTOR_READ_ONLY_ROOTFS", true),
 	}
 
 	// Load MinIO configuration
@@ -190,7 +309,14 @@ func Load(env string) (*Config, error) {
 		PublicBucket:    getEnvAsBool("MINIO_PUBLIC_BUCKET", true),
 	}
 
-	// Storage configuration removed - using MinIO only
+	// Load storage backend configuration
+	config.Storage = StorageConfig{
+		Backend:         getEnvOrDefault("STORAGE_BACKEND", StorageBackendMinIO),
+		LocalPath:       getEnvOrDefault("STORAGE_LOCAL_PATH", "./storage"),
+		LocalBaseURL:    getEnvOrDefault("STORAGE_LOCAL_BASE_URL", ""),
+		LocalSigningKey: getEnvOrDefault("STORAGE_LOCAL_SIGNING_KEY", ""),
+		LocalPublicRead: getEnvAsBool("STORAGE_LOCAL_PUBLIC_READ", true),
+	}
 
 	// Load RabbitMQ configuration
 	config.RabbitMQ = RabbitMQConfig{
@@ -201,6 +327,12 @@ func Load(env string) (*Config, error) {
 		VHost:    getEnvOrDefault("RABBITMQ_VHOST", "/"),
 		Exchange: getEnvOrDefault("RABBITMQ_EXCHANGE", "dsview_exchange"),
 		URL:      getEnvOrDefault("RABBITMQ_URL", ""),
+
+		QueuePrefix: getEnvOrDefault("RABBITMQ_QUEUE_PREFIX", ""),
+
+		PublishMaxAttempts: getEnvAsInt("RABBITMQ_PUBLISH_MAX_ATTEMPTS", 3),
+		PublishBackoff:     getEnvAsDuration("RABBITMQ_PUBLISH_BACKOFF", 200*time.Millisecond),
+		PublishMaxBackoff:  getEnvAsDuration("RABBITMQ_PUBLISH_MAX_BACKOFF", 2*time.Second),
 	}
 
 	// Generate RabbitMQ URL if not provided
@@ -216,6 +348,55 @@ func Load(env string) (*Config, error) {
 		APIKey:     getEnvOrDefault("API_KEY", ""),
 	}
 
+	// Load admin configuration
+	config.Admin = AdminConfig{
+		Emails: getEnvAsStringSlice("ADMIN_EMAILS", nil),
+	}
+
+	// Load queue consumer configuration
+	config.Queue = QueueConfig{
+		CodeExecutionConcurrency:  getEnvAsInt("QUEUE_CODE_EXECUTION_CONCURRENCY", 4),
+		FileProcessingConcurrency: getEnvAsInt("QUEUE_FILE_PROCESSING_CONCURRENCY", 2),
+		StuckJobThreshold:         getEnvAsDuration("QUEUE_STUCK_JOB_THRESHOLD", 30*time.Minute),
+		DrainTimeout:              getEnvAsDuration("QUEUE_DRAIN_TIMEOUT", 30*time.Second),
+		ArchiveJobPolicy:          getEnvOrDefault("QUEUE_ARCHIVE_JOB_POLICY", "finish"),
+		ArchiveFinishTimeout:      getEnvAsDuration("QUEUE_ARCHIVE_FINISH_TIMEOUT", 30*time.Minute),
+	}
+
+	// Load submission configuration
+	config.Submission = SubmissionConfig{
+		PDFMaxPages: getEnvAsInt("SUBMISSION_PDF_MAX_PAGES", 50),
+	}
+
+	// Load upload size limits
+	config.Upload = UploadConfig{
+		MaxPDFSize:        getEnvOrDefault("UPLOAD_MAX_PDF_SIZE", "10MB"),
+		MaxDocumentSize:   getEnvOrDefault("UPLOAD_MAX_DOCUMENT_SIZE", "10MB"),
+		MaxImageSize:      getEnvOrDefault("UPLOAD_MAX_IMAGE_SIZE", "5MB"),
+		MaxFilenameLength: getEnvAsInt("UPLOAD_MAX_FILENAME_LENGTH", 128),
+
+		MaxBodySize:          getEnvOrDefault("UPLOAD_MAX_BODY_SIZE", ""),
+		MultipartMemoryLimit: getEnvOrDefault("UPLOAD_MULTIPART_MEMORY_LIMIT", "1MB"),
+	}
+
+	// Load scheduled task intervals
+	config.Scheduler = SchedulerConfig{
+		SubmissionCleanupInterval: getEnvAsDuration("SCHEDULER_SUBMISSION_CLEANUP_INTERVAL", 10*time.Minute),
+		QueueJobCleanupInterval:   getEnvAsDuration("SCHEDULER_QUEUE_JOB_CLEANUP_INTERVAL", time.Hour),
+		StuckJobCheckInterval:     getEnvAsDuration("SCHEDULER_STUCK_JOB_CHECK_INTERVAL", 5*time.Minute),
+		HeldJobReleaseInterval:    getEnvAsDuration("SCHEDULER_HELD_JOB_RELEASE_INTERVAL", 30*time.Second),
+		MaterialIntegrityInterval: getEnvAsDuration("SCHEDULER_MATERIAL_INTEGRITY_INTERVAL", 24*time.Hour),
+		CourseStatsInterval:       getEnvAsDuration("SCHEDULER_COURSE_STATS_INTERVAL", 24*time.Hour),
+	}
+
+	// Load webhook configuration
+	config.Webhook = WebhookConfig{
+		CourseCompletedURL:   getEnvOrDefault("WEBHOOK_COURSE_COMPLETED_URL", ""),
+		MaterialPublishedURL: getEnvOrDefault("WEBHOOK_MATERIAL_PUBLISHED_URL", ""),
+		Secret:               getEnvOrDefault("WEBHOOK_SECRET", ""),
+		Timeout:              getEnvAsDuration("WEBHOOK_TIMEOUT", 10*time.Second),
+	}
+
 	// Set defaults if not provided
 	config.setDefaults()
 
@@ -240,6 +421,10 @@ func (c *Config) setDefaults() {
 			"http://localhost:5500",
 		}
 	}
+	// Streaming endpoints fall back to the general CORS allowlist
+	if len(c.Frontend.StreamingAllowedOrigins) == 0 {
+		c.Frontend.StreamingAllowedOrigins = c.Frontend.AllowedOrigins
+	}
 
 	// Set default database name if not specified
 	if c.Database.DBName == "" {
@@ -283,6 +468,17 @@ func (c *Config) setDefaults() {
 		c.MinIO.MaxFileSize = "10MB" // เพิ่มจาก 1MB เป็น 10MB สำหรับ PDF
 	}
 
+	// Storage defaults
+	if c.Storage.Backend == "" {
+		c.Storage.Backend = StorageBackendMinIO
+	}
+	if c.Storage.LocalBaseURL == "" {
+		c.Storage.LocalBaseURL = fmt.Sprintf("http://localhost:%s/files", c.Server.Port)
+	}
+	if c.Storage.LocalSigningKey == "" {
+		c.Storage.LocalSigningKey = c.JWT.Secret
+	}
+
 	// RabbitMQ defaults
 	if c.RabbitMQ.Host == "" {
 		c.RabbitMQ.Host = "rabbitmq"
@@ -306,6 +502,9 @@ func (c *Config) setDefaults() {
 		c.RabbitMQ.URL = fmt.Sprintf("amqp://%s:%s@%s:%d%s",
 			c.RabbitMQ.Username, c.RabbitMQ.Password, c.RabbitMQ.Host, c.RabbitMQ.Port, c.RabbitMQ.VHost)
 	}
+	if c.RabbitMQ.PublishMaxAttempts <= 0 {
+		c.RabbitMQ.PublishMaxAttempts = 1
+	}
 
 	// API Key defaults
 	if c.APIKey.APIKeyName == "" {
@@ -334,6 +533,48 @@ func (m *MinIOConfig) GetMaxFileSizeBytes() int64 {
 	return parseFileSize(m.MaxFileSize)
 }
 
+func (u *UploadConfig) GetMaxPDFSizeBytes() int64 {
+	return parseFileSize(u.MaxPDFSize)
+}
+
+func (u *UploadConfig) GetMaxDocumentSizeBytes() int64 {
+	return parseFileSize(u.MaxDocumentSize)
+}
+
+func (u *UploadConfig) GetMaxImageSizeBytes() int64 {
+	return parseFileSize(u.MaxImageSize)
+}
+
+// GetLargestSizeBytes returns the largest per-kind limit, used for routes that accept
+// any kind of file and as the server-wide body limit
+func (u *UploadConfig) GetLargestSizeBytes() int64 {
+	largest := u.GetMaxPDFSizeBytes()
+	for _, size := range []int64{u.GetMaxDocumentSizeBytes(), u.GetMaxImageSizeBytes()} {
+		if size > largest {
+			largest = size
+		}
+	}
+	return largest
+}
+
+// GetMaxBodySizeBytes returns the server-wide request body limit: the configured size,
+// but never less than the largest file limit plus overhead for the rest of the form
+func (u *UploadConfig) GetMaxBodySizeBytes(overhead int64) int64 {
+	least := u.GetLargestSizeBytes() + overhead
+	if u.MaxBodySize == "" {
+		return least
+	}
+	if size := parseFileSize(u.MaxBodySize); size > least {
+		return size
+	}
+	return least
+}
+
+// GetMultipartMemoryLimitBytes returns the largest request body read into memory
+func (u *UploadConfig) GetMultipartMemoryLimitBytes() int64 {
+	return parseFileSize(u.MultipartMemoryLimit)
+}
+
 // GetMaxFileSizeBytes is now handled by MinIOConfig
 // func (s *StorageConfig) GetMaxFileSizeBytes() int64 {
 //	return parseFileSize(s.MaxFileSize)
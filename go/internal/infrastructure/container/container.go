@@ -69,8 +69,8 @@ func (c *Container) initRepositories() {
 // initServices initializes all services
 func (c *Container) initServices() {
 	c.CourseScoreService = services.NewCourseScoreService(
+		c.DB,
 		c.CourseScoreRepo,
-		c.StudentProgressRepo,
 	)
 }
 
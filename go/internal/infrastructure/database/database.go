@@ -78,6 +78,12 @@ func NewDatabase(cfg *config.DatabaseConfig) (*Database, error) {
 		&entities.CourseWeek{},
 		&entities.StudentCourseScore{},
 		&entities.QueueJob{},
+		&entities.CourseAPIKey{},
+		&entities.CourseCompletion{},
+		&entities.UserSession{},
+		&entities.DeadlineExtension{},
+		&entities.MaterialAccess{},
+		&entities.CourseStatsSnapshot{},
 	); err != nil {
 		return nil, fmt.Errorf("failed to migrate tables: %w", err)
 	}
@@ -159,6 +159,26 @@ func (r *GormCourseScoreRepository) GetStudentScoreStats(ctx context.Context, us
 	}, nil
 }
 
+// GetByCourse gets all course scores for a course
+func (r *GormCourseScoreRepository) GetByCourse(ctx context.Context, courseID string) ([]entities.CourseScore, error) {
+	var models []entities.StudentCourseScore
+	err := r.db.WithContext(ctx).
+		Where("course_id = ?", courseID).
+		Order("total_score DESC").
+		Find(&models).Error
+
+	if err != nil {
+		return nil, err
+	}
+
+	entities := make([]entities.CourseScore, len(models))
+	for i, model := range models {
+		entities[i] = *r.modelToEntity(&model)
+	}
+
+	return entities, nil
+}
+
 // BatchGetByUserAndCourses gets multiple course scores by user ID and course IDs
 func (r *GormCourseScoreRepository) BatchGetByUserAndCourses(ctx context.Context, userID string, courseIDs []string) ([]entities.CourseScore, error) {
 	var models []entities.StudentCourseScore
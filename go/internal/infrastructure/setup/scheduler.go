@@ -0,0 +1,185 @@
+package setup
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/Project-DSView/backend/go/internal/types"
+	"github.com/Project-DSView/backend/go/pkg/logger"
+)
+
+// Last run results reported by Scheduler.Tasks
+const (
+	TaskStatusNever  = "never"
+	TaskStatusOK     = "ok"
+	TaskStatusFailed = "failed"
+)
+
+// TaskFunc is the body of a scheduled task. ctx is cancelled when the scheduler stops.
+type TaskFunc func(ctx context.Context) error
+
+// scheduledTask is a registered task and the outcome of its latest run.
+// The run state is guarded by Scheduler.mu.
+type scheduledTask struct {
+	name     string
+	interval time.Duration
+	run      TaskFunc
+
+	running      bool
+	lastStatus   string
+	lastRunAt    *time.Time
+	lastDuration time.Duration
+	lastError    string
+	nextRunAt    *time.Time
+	runs         int64
+	failures     int64
+}
+
+// Scheduler runs named tasks at fixed intervals in background goroutines. Tasks are
+// registered during setup, started once the services are ready and stopped on shutdown.
+// A task that fails or panics is logged and retried at its next interval.
+type Scheduler struct {
+	mu      sync.Mutex
+	tasks   []*scheduledTask
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+	started bool
+}
+
+func NewScheduler() *Scheduler {
+	return &Scheduler{}
+}
+
+// Register adds a task that runs every interval, first right after Start.
+// An interval of 0 or less disables the task. Tasks must be registered before Start.
+func (s *Scheduler) Register(name string, interval time.Duration, run TaskFunc) {
+	if interval <= 0 {
+		logger.Infof("Scheduled task %s is disabled", name)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.started {
+		logger.Warnf("Scheduled task %s registered after the scheduler started; it will not run", name)
+	}
+	s.tasks = append(s.tasks, &scheduledTask{
+		name:       name,
+		interval:   interval,
+		run:        run,
+		lastStatus: TaskStatusNever,
+	})
+}
+
+// Start runs every registered task in its own goroutine until ctx is done or Stop is called
+func (s *Scheduler) Start(ctx context.Context) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.started {
+		return
+	}
+	s.started = true
+
+	ctx, s.cancel = context.WithCancel(ctx)
+	for _, task := range s.tasks {
+		s.wg.Add(1)
+		go s.loop(ctx, task)
+	}
+	logger.Infof("Scheduler started with %d tasks", len(s.tasks))
+}
+
+// Stop cancels the running tasks and waits for them to return
+func (s *Scheduler) Stop() {
+	s.mu.Lock()
+	cancel := s.cancel
+	s.mu.Unlock()
+
+	if cancel == nil {
+		return
+	}
+	cancel()
+	s.wg.Wait()
+	logger.Info("Scheduler stopped")
+}
+
+func (s *Scheduler) loop(ctx context.Context, task *scheduledTask) {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(task.interval)
+	defer ticker.Stop()
+
+	for {
+		s.runOnce(ctx, task)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// runOnce runs the task, recording its outcome and recovering from panics
+func (s *Scheduler) runOnce(ctx context.Context, task *scheduledTask) {
+	startedAt := time.Now()
+	s.mu.Lock()
+	task.running = true
+	s.mu.Unlock()
+
+	err := func() (err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				err = fmt.Errorf("panic: %v", r)
+			}
+		}()
+		return task.run(ctx)
+	}()
+
+	duration := time.Since(startedAt)
+	nextRunAt := startedAt.Add(task.interval)
+
+	s.mu.Lock()
+	task.running = false
+	task.lastRunAt = &startedAt
+	task.lastDuration = duration
+	task.nextRunAt = &nextRunAt
+	task.runs++
+	if err != nil {
+		task.lastStatus = TaskStatusFailed
+		task.lastError = err.Error()
+		task.failures++
+	} else {
+		task.lastStatus = TaskStatusOK
+		task.lastError = ""
+	}
+	s.mu.Unlock()
+
+	if err != nil {
+		logger.Warnf("Scheduled task %s failed after %s: %v", task.name, duration, err)
+	}
+}
+
+// Tasks returns the registered tasks in registration order with their latest run
+func (s *Scheduler) Tasks() []types.ScheduledTaskStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tasks := make([]types.ScheduledTaskStatus, 0, len(s.tasks))
+	for _, task := range s.tasks {
+		tasks = append(tasks, types.ScheduledTaskStatus{
+			Name:           task.name,
+			Interval:       task.interval.String(),
+			Running:        task.running,
+			LastStatus:     task.lastStatus,
+			LastRunAt:      task.lastRunAt,
+			LastDurationMs: task.lastDuration.Milliseconds(),
+			LastError:      task.lastError,
+			NextRunAt:      task.nextRunAt,
+			Runs:           task.runs,
+			Failures:       task.failures,
+		})
+	}
+	return tasks
+}
@@ -0,0 +1,113 @@
+package setup
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Project-DSView/backend/go/internal/types"
+)
+
+func TestSchedulerRecordsTaskRuns(t *testing.T) {
+	s := NewScheduler()
+	s.Register("ok", time.Hour, func(ctx context.Context) error { return nil })
+	s.Register("failing", time.Hour, func(ctx context.Context) error { return errors.New("database is down") })
+	s.Register("panicking", time.Hour, func(ctx context.Context) error { panic("nil map") })
+	s.Register("disabled", 0, func(ctx context.Context) error {
+		t.Error("disabled task ran")
+		return nil
+	})
+
+	before := s.Tasks()
+	if len(before) != 3 {
+		t.Fatalf("%d tasks registered, want 3 (disabled tasks are skipped)", len(before))
+	}
+	for _, task := range before {
+		if task.LastStatus != TaskStatusNever || task.Runs != 0 {
+			t.Errorf("%s before Start: status %s, %d runs, want never and 0", task.Name, task.LastStatus, task.Runs)
+		}
+	}
+
+	s.Start(context.Background())
+	tasks := waitForRuns(t, s)
+	s.Stop()
+
+	want := map[string]struct {
+		status   string
+		err      string
+		failures int64
+	}{
+		"ok":        {TaskStatusOK, "", 0},
+		"failing":   {TaskStatusFailed, "database is down", 1},
+		"panicking": {TaskStatusFailed, "panic: nil map", 1},
+	}
+	for _, task := range tasks {
+		w := want[task.Name]
+		if task.LastStatus != w.status || task.LastError != w.err || task.Runs != 1 || task.Failures != w.failures {
+			t.Errorf("%s: status %s, error %q, %d runs, %d failures; want %s, %q, 1, %d",
+				task.Name, task.LastStatus, task.LastError, task.Runs, task.Failures, w.status, w.err, w.failures)
+		}
+		if task.Interval != "1h0m0s" {
+			t.Errorf("%s: interval %s, want 1h0m0s", task.Name, task.Interval)
+		}
+		if task.LastRunAt == nil || task.NextRunAt == nil || !task.NextRunAt.Equal(task.LastRunAt.Add(time.Hour)) {
+			t.Errorf("%s: last run %v, next run %v, want the next run an interval after the last", task.Name, task.LastRunAt, task.NextRunAt)
+		}
+	}
+}
+
+// TestSchedulerStopCancelsRunningTasks checks that Stop cancels the context of a task
+// that is running and waits for it to return
+func TestSchedulerStopCancelsRunningTasks(t *testing.T) {
+	s := NewScheduler()
+	started := make(chan struct{})
+	returned := false
+	s.Register("blocking", time.Hour, func(ctx context.Context) error {
+		close(started)
+		<-ctx.Done()
+		returned = true
+		return ctx.Err()
+	})
+	s.Start(context.Background())
+	<-started
+
+	stopped := make(chan struct{})
+	go func() {
+		s.Stop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Stop did not return while a task was running")
+	}
+	if !returned {
+		t.Error("Stop returned before the task did")
+	}
+	if task := s.Tasks()[0]; task.Running || task.LastStatus != TaskStatusFailed {
+		t.Errorf("after Stop: running %v, status %s, want false and failed", task.Running, task.LastStatus)
+	}
+}
+
+// waitForRuns waits until every registered task has run once and returns their status
+func waitForRuns(t *testing.T, s *Scheduler) []types.ScheduledTaskStatus {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		tasks := s.Tasks()
+		done := true
+		for _, task := range tasks {
+			if task.Runs == 0 {
+				done = false
+			}
+		}
+		if done {
+			return tasks
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("tasks did not all run: %+v", tasks)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
@@ -2,6 +2,7 @@ package setup
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/Project-DSView/backend/go/internal/application/services"
 	"github.com/Project-DSView/backend/go/internal/infrastructure/config"
@@ -31,6 +32,9 @@ type Services struct {
 	DeadlineCheckerService *services.DeadlineCheckerService
 	CourseScoreService     *services.CourseScoreService
 	CourseMaterialService  *services.CourseMaterialService
+	CompletionService      *services.CourseCompletionService
+	SessionService         *services.SessionService
+	Scheduler              *Scheduler
 }
 
 // SetupDatabase initializes database connection
@@ -58,21 +62,14 @@ func SetupExternalServices(cfg *config.Config) (*external.DockerExecutor, storag
 		Timeout: cfg.Executor.Timeout,
 		Memory:  cfg.Executor.Memory,
 		CPUs:    cfg.Executor.CPUs,
-	})
 
-	// Initialize MinIO storage service
-	minioConfig := &storage.MinIOConfig{
-		Endpoint:         cfg.MinIO.Endpoint,
-		PublicEndpoint:   cfg.MinIO.PublicEndpoint,
-		AccessKeyID:      cfg.MinIO.AccessKeyID,
-		SecretAccessKey:  cfg.MinIO.SecretAccessKey,
-		BucketName:       cfg.MinIO.BucketName,
-		MaxFileSizeBytes: cfg.MinIO.GetMaxFileSizeBytes(),
-		UseSSL:           cfg.MinIO.UseSSL,
-		PublicBucket:     cfg.MinIO.PublicBucket,
-	}
+		MaxContainers: cfg.Executor.MaxContainers,
+
+		User:           cfg.Executor.User,
+		WritableRootFS: !cfg.Executor.ReadOnlyRootFS,
+	})
 
-	storageService, err := storage.NewMinIOService(minioConfig)
+	storageService, err := newStorageService(cfg)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -80,11 +77,39 @@ func SetupExternalServices(cfg *config.Config) (*external.DockerExecutor, storag
 	return exec, storageService, nil
 }
 
+// newStorageService creates the storage backend selected by cfg.Storage.Backend
+func newStorageService(cfg *config.Config) (storage.StorageService, error) {
+	switch cfg.Storage.Backend {
+	case config.StorageBackendLocal:
+		return storage.NewLocalStorageService(&storage.LocalConfig{
+			RootDir:           cfg.Storage.LocalPath,
+			BaseURL:           cfg.Storage.LocalBaseURL,
+			SigningKey:        cfg.Storage.LocalSigningKey,
+			MaxFileSizeBytes:  cfg.MinIO.GetMaxFileSizeBytes(),
+			PublicRead:        cfg.Storage.LocalPublicRead,
+			MaxFilenameLength: cfg.Upload.MaxFilenameLength,
+		})
+	case config.StorageBackendMinIO:
+		return storage.NewMinIOService(&storage.MinIOConfig{
+			Endpoint:          cfg.MinIO.Endpoint,
+			PublicEndpoint:    cfg.MinIO.PublicEndpoint,
+			AccessKeyID:       cfg.MinIO.AccessKeyID,
+			SecretAccessKey:   cfg.MinIO.SecretAccessKey,
+			BucketName:        cfg.MinIO.BucketName,
+			MaxFileSizeBytes:  cfg.MinIO.GetMaxFileSizeBytes(),
+			UseSSL:            cfg.MinIO.UseSSL,
+			PublicBucket:      cfg.MinIO.PublicBucket,
+			MaxFilenameLength: cfg.Upload.MaxFilenameLength,
+		})
+	default:
+		return nil, fmt.Errorf("unknown storage backend %q (expected %q or %q)", cfg.Storage.Backend, config.StorageBackendMinIO, config.StorageBackendLocal)
+	}
+}
+
 // SetupCoreServices initializes core application services
 func SetupCoreServices(db *gorm.DB, cfg *config.Config) (*Services, error) {
 	// Initialize repositories
 	courseScoreRepo := repositories.NewGormCourseScoreRepository(db)
-	studentProgressRepo := repositories.NewGormStudentProgressRepository(db)
 
 	// Initialize OAuth and JWT services
 	oauthService := services.NewOAuthService(
@@ -95,15 +120,24 @@ func SetupCoreServices(db *gorm.DB, cfg *config.Config) (*Services, error) {
 	)
 	jwtService := services.NewJWTService(cfg.JWT.Secret, cfg.JWT.ExpiresIn)
 
+	// Login sessions; revoked sessions are denied until their access tokens expire
+	sessionService := services.NewSessionService(db, cfg.JWT.ExpiresIn)
+	jwtService.SetSessionRevocationCheck(sessionService.IsSessionRevoked)
+
 	// Initialize core services
 	userService := services.NewUserService(db)
 	enrollmentService := services.NewEnrollmentService(db, userService)
 	courseService := services.NewCourseService(db, userService, enrollmentService)
 	invitationService := services.NewInvitationService(db, courseService, enrollmentService)
 	testCaseService := services.NewTestCaseService(db)
+	testCaseLimits := services.TestCaseLimits{
+		MaxInputBytes:  cfg.Executor.MaxTestCaseInputBytes,
+		MaxOutputBytes: cfg.Executor.MaxTestCaseOutputBytes,
+	}
+	testCaseService.SetLimits(testCaseLimits)
 
 	// Initialize advanced services
-	courseScoreService := services.NewCourseScoreService(courseScoreRepo, studentProgressRepo)
+	courseScoreService := services.NewCourseScoreService(db, courseScoreRepo)
 	deadlineCheckerService := services.NewDeadlineCheckerService(db)
 	progressService := services.NewProgressService(db, userService, courseService)
 	draftService := services.NewDraftService(db)
@@ -115,12 +149,23 @@ func SetupCoreServices(db *gorm.DB, cfg *config.Config) (*Services, error) {
 	}
 
 	courseMaterialService := services.NewCourseMaterialService(db, storageService)
+	courseMaterialService.SetTestCaseLimits(testCaseLimits)
+	// Material published events for courses that auto-announce (disabled when no URL is configured)
+	courseMaterialService.SetPublishNotifier(external.NewWebhookNotifier(cfg.Webhook.MaterialPublishedURL, cfg.Webhook.Secret, cfg.Webhook.Timeout))
+	courseService.SetCourseMaterialService(courseMaterialService)
+	courseService.SetAdminEmails(cfg.Admin.Emails)
 
 	// Initialize queue service with retry logic
 	logger.Info("Initializing RabbitMQ service...")
 	rabbitMQService, err := external.NewRabbitMQService(&external.RabbitMQConfig{
 		URL:      cfg.RabbitMQ.URL,
 		Exchange: cfg.RabbitMQ.Exchange,
+
+		QueuePrefix: cfg.RabbitMQ.QueuePrefix,
+
+		PublishMaxAttempts: cfg.RabbitMQ.PublishMaxAttempts,
+		PublishBackoff:     cfg.RabbitMQ.PublishBackoff,
+		PublishMaxBackoff:  cfg.RabbitMQ.PublishMaxBackoff,
 	})
 	if err != nil {
 		logger.Warnf("Failed to initialize RabbitMQ service: %v", err)
@@ -131,6 +176,9 @@ func SetupCoreServices(db *gorm.DB, cfg *config.Config) (*Services, error) {
 	}
 
 	queueService := services.NewQueueService(db, rabbitMQService, userService)
+	queueService.SetConcurrencyLimits(cfg.Queue.CodeExecutionConcurrency, cfg.Queue.FileProcessingConcurrency)
+	queueService.SetStuckJobThreshold(cfg.Queue.StuckJobThreshold)
+	queueService.SetDrainTimeout(cfg.Queue.DrainTimeout)
 
 	// Initialize submission service with all dependencies
 	submissionService := services.NewSubmissionService(
@@ -138,10 +186,25 @@ func SetupCoreServices(db *gorm.DB, cfg *config.Config) (*Services, error) {
 		deadlineCheckerService, courseScoreService, courseMaterialService, exec,
 		storageService, queueService,
 	)
+	submissionService.SetMaxPDFPages(cfg.Submission.PDFMaxPages)
 
 	// Set submission service in queue service (to avoid circular dependency)
 	queueService.SetSubmissionService(submissionService)
 
+	// Course completion events (webhook is disabled when no URL is configured)
+	webhookNotifier := external.NewWebhookNotifier(cfg.Webhook.CourseCompletedURL, cfg.Webhook.Secret, cfg.Webhook.Timeout)
+	completionService := services.NewCourseCompletionService(db, webhookNotifier)
+	queueService.SetCourseCompletionService(completionService)
+
+	// Archiving a course stops its consumers and finishes or cancels its queued jobs
+	archiveJobPolicy, err := services.ParseArchiveJobPolicy(cfg.Queue.ArchiveJobPolicy)
+	if err != nil {
+		return nil, err
+	}
+	courseService.SetQueueService(queueService)
+	courseService.SetArchiveJobPolicy(archiveJobPolicy, cfg.Queue.ArchiveFinishTimeout)
+	progressService.SetCourseCompletionService(completionService)
+
 	// Start queue consumer if RabbitMQ is available
 	if rabbitMQService != nil {
 		ctx := context.Background()
@@ -152,6 +215,23 @@ func SetupCoreServices(db *gorm.DB, cfg *config.Config) (*Services, error) {
 		}
 	}
 
+	// Periodic background tasks; started by the caller once the server is set up
+	scheduler := NewScheduler()
+	scheduler.Register("submission-cleanup", cfg.Scheduler.SubmissionCleanupInterval, func(ctx context.Context) error {
+		return submissionService.CleanupOldSubmissions()
+	})
+	scheduler.Register("queue-job-cleanup", cfg.Scheduler.QueueJobCleanupInterval, func(ctx context.Context) error {
+		return queueService.CleanupOldQueueJobs()
+	})
+	scheduler.Register("stuck-job-check", cfg.Scheduler.StuckJobCheckInterval, func(ctx context.Context) error {
+		return queueService.CheckStuckJobs()
+	})
+	scheduler.Register("held-job-release", cfg.Scheduler.HeldJobReleaseInterval, func(ctx context.Context) error {
+		return queueService.ReleaseAllHeldJobs()
+	})
+	scheduler.Register("material-integrity-check", cfg.Scheduler.MaterialIntegrityInterval, courseMaterialService.RunMaterialIntegrityCheck)
+	scheduler.Register("course-stats-snapshot", cfg.Scheduler.CourseStatsInterval, courseService.SnapshotCourseStats)
+
 	return &Services{
 		DB:                     db,
 		OAuthService:           oauthService,
@@ -169,5 +249,8 @@ func SetupCoreServices(db *gorm.DB, cfg *config.Config) (*Services, error) {
 		DeadlineCheckerService: deadlineCheckerService,
 		CourseScoreService:     courseScoreService,
 		CourseMaterialService:  courseMaterialService,
+		CompletionService:      completionService,
+		SessionService:         sessionService,
+		Scheduler:              scheduler,
 	}, nil
 }
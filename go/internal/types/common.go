@@ -2,6 +2,7 @@ package types
 
 import (
 	"database/sql/driver"
+	"encoding/json"
 	"errors"
 )
 
@@ -24,12 +25,14 @@ func (j *JSONData) Scan(value interface{}) error {
 		return nil
 	}
 
-	bytes, ok := value.([]byte)
-	if !ok {
-		return errors.New("cannot scan non-[]byte value into JSONData")
+	switch v := value.(type) {
+	case []byte:
+		*j = JSONData(v)
+	case string:
+		*j = JSONData(v)
+	default:
+		return errors.New("cannot scan non-JSON value into JSONData")
 	}
-
-	*j = JSONData(bytes)
 	return nil
 }
 
@@ -46,3 +49,42 @@ func (j JSONData) MarshalJSON() ([]byte, error) {
 	}
 	return []byte(j), nil
 }
+
+// StringList is a list of strings stored as a JSON array, e.g. material tags.
+// A nil list is stored and serialized as [].
+type StringList []string
+
+// Value implements the driver.Valuer interface for database storage
+func (l StringList) Value() (driver.Value, error) {
+	if l == nil {
+		return []byte("[]"), nil
+	}
+	return json.Marshal([]string(l))
+}
+
+// Scan implements the sql.Scanner interface for database reading
+func (l *StringList) Scan(value interface{}) error {
+	if value == nil {
+		*l = StringList{}
+		return nil
+	}
+
+	var bytes []byte
+	switch v := value.(type) {
+	case []byte:
+		bytes = v
+	case string:
+		bytes = []byte(v)
+	default:
+		return errors.New("cannot scan non-JSON value into StringList")
+	}
+	return json.Unmarshal(bytes, (*[]string)(l))
+}
+
+// MarshalJSON implements json.Marshaler interface
+func (l StringList) MarshalJSON() ([]byte, error) {
+	if l == nil {
+		return []byte("[]"), nil
+	}
+	return json.Marshal([]string(l))
+}
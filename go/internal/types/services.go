@@ -3,6 +3,7 @@ package types
 import (
 	"time"
 
+	"github.com/Project-DSView/backend/go/pkg/external"
 	"github.com/golang-jwt/jwt/v5"
 )
 
@@ -10,6 +11,13 @@ import (
 type SubmitResult struct {
 	Submission interface{} `json:"submission"`
 	Results    interface{} `json:"results"`
+	// Queued is true when grading was handed to the execution queue; Results are then
+	// empty until the job finishes and JobID identifies the queue job
+	Queued bool   `json:"queued"`
+	JobID  string `json:"job_id,omitempty"`
+	// QueuePosition is the job's place in line when the exercise's concurrent
+	// submission cap is reached (1 = next); 0 when grading starts right away
+	QueuePosition int `json:"queue_position,omitempty"`
 }
 
 type SubmissionFilter struct {
@@ -35,6 +43,7 @@ type QueueJobData struct {
 	TestCases      interface{} `json:"test_cases,omitempty"`
 	Language       string      `json:"language,omitempty"`
 	ReviewNotes    string      `json:"review_notes,omitempty"`
+	Rejudge        bool        `json:"rejudge,omitempty"` // Re-run an existing submission instead of grading a new one
 }
 
 type QueueJobResult struct {
@@ -73,6 +82,330 @@ type CourseProgressRow struct {
 	CompletedAt string `json:"completed_at,omitempty"`
 }
 
+// TestCasePassRate aggregates how the latest submission of each student did on one test case
+type TestCasePassRate struct {
+	TestCaseID  string  `json:"test_case_id"`
+	DisplayName string  `json:"display_name"`
+	PassedCount int     `json:"passed_count"`
+	FailedCount int     `json:"failed_count"`
+	PassRate    float64 `json:"pass_rate"` // passed / (passed + failed), 0 when nobody ran it
+}
+
+// WeekSummary is one week of a course that has materials
+type WeekSummary struct {
+	Week  int      `json:"week"`
+	Count int      `json:"count"`
+	Types []string `json:"types"` // Distinct material types in the week, sorted
+}
+
+// TagCount is a material tag used in a course and how many materials carry it
+type TagCount struct {
+	Tag   string `json:"tag"`
+	Count int    `json:"count"`
+}
+
+// MaterialRef identifies a material by ID and title, e.g. a missing prerequisite
+type MaterialRef struct {
+	MaterialID string `json:"material_id"`
+	Title      string `json:"title"`
+}
+
+// PrerequisiteStatus is whether a student may submit to an exercise yet: it is locked
+// while any of its prerequisites is not completed
+type PrerequisiteStatus struct {
+	Locked  bool          `json:"locked"`
+	Missing []MaterialRef `json:"missing_prerequisites"`
+}
+
+// AttemptStatus is a student's submission state for one exercise: how many attempts they
+// have used and whether and when they can submit or retry again
+type AttemptStatus struct {
+	AttemptsUsed int `json:"attempts_used"`
+	// Nil: the exercise has no attempt limit
+	RemainingAttempts *int `json:"remaining_attempts"`
+	CanSubmit         bool `json:"can_submit"`
+	// Why CanSubmit is false: "in_progress", "locked_after_approval" or "attempt_limit_reached"
+	BlockedReason string `json:"blocked_reason,omitempty"`
+	// Whether the latest queue job may be retried now, and if not, seconds until the
+	// course's retry window has passed
+	CanRetry          bool  `json:"can_retry"`
+	RetryAfterSeconds int64 `json:"retry_after_seconds"`
+}
+
+// NonSubmitter is an enrolled user who has not submitted to a material, or (when asked
+// for) has submitted but not completed it, with contact details for following up
+type NonSubmitter struct {
+	UserID         string `json:"user_id"`
+	FirstName      string `json:"firstname"`
+	LastName       string `json:"lastname"`
+	Email          string `json:"email"`
+	Role           string `json:"role"`
+	HasSubmitted   bool   `json:"has_submitted"`
+	ProgressStatus string `json:"progress_status"`
+}
+
+// VerificationHistoryEntry is one review of a student's progress on a material. The
+// reviewer fields are empty for entries without a reviewer, e.g. a student's own review request.
+type VerificationHistoryEntry struct {
+	LogID             string    `json:"log_id"`
+	Status            string    `json:"status"`
+	Comment           string    `json:"comment"`
+	VerifiedAt        time.Time `json:"verified_at"`
+	ReviewerID        string    `json:"reviewer_id"`
+	ReviewerFirstName string    `json:"reviewer_firstname"`
+	ReviewerLastName  string    `json:"reviewer_lastname"`
+}
+
+// SimulatedScore is how a submission scores now and would score under proposed test cases
+type SimulatedScore struct {
+	SubmissionID   string `json:"submission_id"`
+	UserID         string `json:"user_id"`
+	CurrentScore   int    `json:"current_score"`
+	ProjectedScore int    `json:"projected_score"`
+	PassedCount    int    `json:"passed_count"`
+}
+
+// ScoreCount is how many submissions reached a score
+type ScoreCount struct {
+	Score int `json:"score"`
+	Count int `json:"count"`
+}
+
+// RegradeSimulation is the projected outcome of regrading an exercise's submissions
+// against proposed test cases. Truncated is set when only the first students'
+// submissions were run.
+type RegradeSimulation struct {
+	TestCaseCount    int              `json:"test_case_count"`
+	SubmissionCount  int              `json:"submission_count"`
+	Truncated        bool             `json:"truncated"`
+	CurrentAverage   float64          `json:"current_average"`
+	ProjectedAverage float64          `json:"projected_average"`
+	ChangedCount     int              `json:"changed_count"`
+	Distribution     []ScoreCount     `json:"distribution"`
+	Submissions      []SimulatedScore `json:"submissions"`
+}
+
+// ExtensionResult is the outcome of extending one student's deadline in a bulk grant
+type ExtensionResult struct {
+	UserID string `json:"user_id"`
+	// granted: the student had no extension; updated: an earlier extension was replaced
+	Status           string     `json:"status"`
+	PreviousDeadline *time.Time `json:"previous_deadline,omitempty"`
+	Deadline         time.Time  `json:"deadline"`
+}
+
+// OwnershipTransfer is the outcome of moving a course to a new owner
+type OwnershipTransfer struct {
+	CourseID        string `json:"course_id"`
+	PreviousOwnerID string `json:"previous_owner_id"`
+	NewOwnerID      string `json:"new_owner_id"`
+	// Weeks and materials the previous owner created that now record the new owner as creator
+	MaterialsReassigned int `json:"materials_reassigned"`
+	WeeksReassigned     int `json:"weeks_reassigned"`
+	// API keys the previous owner made for the course, revoked by the transfer
+	APIKeysRevoked int `json:"api_keys_revoked"`
+}
+
+// MaterialEngagement is how students have used a document or video material
+type MaterialEngagement struct {
+	MaterialID string `json:"material_id"`
+	// Students who viewed or downloaded the material at least once
+	UniqueViewers int `json:"unique_viewers"`
+	// Views and downloads; repeats by a student within a few minutes count once
+	TotalViews     int        `json:"total_views"`
+	TotalDownloads int        `json:"total_downloads"`
+	LastAccessedAt *time.Time `json:"last_accessed_at"`
+}
+
+// BulkVisibilityResult is the outcome of showing or hiding several materials at once
+type BulkVisibilityResult struct {
+	IsPublic bool `json:"is_public"`
+	// Materials whose visibility changed, and those that already had it
+	Updated   []string `json:"updated"`
+	Unchanged []string `json:"unchanged"`
+}
+
+// TestCaseChange is one test case of a code exercise as the teacher's edit form submits
+// it. TestCaseID is empty for a new test case, which needs InputData and ExpectedOutput.
+// For an existing test case a nil field keeps its current value, so an entry may carry
+// only what changes. IsPublic nil makes a new test case public.
+type TestCaseChange struct {
+	TestCaseID     string
+	InputData      JSONData
+	ExpectedOutput JSONData
+	DisplayName    *string
+	IsPublic       *bool
+}
+
+// TestCaseSyncResult counts what a test case sync did to a code exercise
+type TestCaseSyncResult struct {
+	Created   int `json:"created"`
+	Updated   int `json:"updated"`
+	Unchanged int `json:"unchanged"`
+	Deleted   int `json:"deleted"`
+
+	// The inline input and expected output of the resulting public test cases, in list
+	// order; they are shown to students as the exercise's examples
+	InputData      []JSONData `json:"-"`
+	ExpectedOutput []JSONData `json:"-"`
+}
+
+// ExecutorCapabilities are the languages code can be submitted in and the limits it runs under
+type ExecutorCapabilities struct {
+	external.ExecutorCapabilities
+	// Largest test case input and expected output a teacher can store; 0 is unlimited
+	MaxTestCaseInputBytes  int `json:"max_test_case_input_bytes"`
+	MaxTestCaseOutputBytes int `json:"max_test_case_output_bytes"`
+}
+
+// StudentCode is the source of a student's latest code submission to a material
+type StudentCode struct {
+	SubmissionID string    `json:"submission_id"`
+	UserID       string    `json:"user_id"`
+	MaterialID   string    `json:"material_id"`
+	Code         string    `json:"code"`
+	Status       string    `json:"status"`
+	SubmittedAt  time.Time `json:"submitted_at"`
+}
+
+// InstructorCourseSummary is one course in a teacher's portfolio overview
+type InstructorCourseSummary struct {
+	CourseID      string `json:"course_id"`
+	Name          string `json:"name"`
+	Status        string `json:"status"`
+	StudentCount  int64  `json:"student_count"`
+	ExerciseCount int64  `json:"exercise_count"`
+	// Percentage of exercises completed, averaged over the enrolled students
+	AverageCompletion float64 `json:"average_completion"`
+	// Student progress waiting for a review or approval
+	PendingReviews int64 `json:"pending_reviews"`
+	// Size of the course's document, PDF exercise and submission files
+	StorageUsageBytes int64 `json:"storage_usage_bytes"`
+}
+
+// InstructorOverview summarizes every course a teacher owns
+type InstructorOverview struct {
+	Courses                []InstructorCourseSummary `json:"courses"`
+	TotalStudents          int64                     `json:"total_students"`
+	TotalPendingReviews    int64                     `json:"total_pending_reviews"`
+	TotalStorageUsageBytes int64                     `json:"total_storage_usage_bytes"`
+}
+
+// CourseStats is a course's aggregate figures, read from its latest nightly snapshot or
+// computed live when asked for or when no snapshot exists yet
+type CourseStats struct {
+	CourseID      string `json:"course_id"`
+	StudentCount  int64  `json:"student_count"`
+	ExerciseCount int64  `json:"exercise_count"`
+	// Percentage of exercises completed, averaged over the enrolled students
+	CompletionRate float64 `json:"completion_rate"`
+	// Mean course score of the enrolled students
+	AverageScore    float64 `json:"average_score"`
+	SubmissionCount int64   `json:"submission_count"`
+	// When the figures were computed; Live is set when that was for this request
+	SnapshotAt time.Time `json:"snapshot_at"`
+	Live       bool      `json:"live"`
+}
+
+// DeadlineCalendarEntry is one exercise on a student's deadline calendar
+type DeadlineCalendarEntry struct {
+	MaterialID string `json:"material_id"`
+	CourseID   string `json:"course_id"`
+	CourseName string `json:"course_name"`
+	Title      string `json:"title"`
+	Type       string `json:"type"`
+	Week       int    `json:"week"`
+	// Deadline is the exercise's own; ExtendedDeadline the one granted to the student
+	Deadline         *time.Time `json:"deadline,omitempty"`
+	ExtendedDeadline *time.Time `json:"extended_deadline,omitempty"`
+	// EffectiveDeadline is when the student's submissions become late, or are refused
+	// when the exercise has no submission window closing later
+	EffectiveDeadline time.Time  `json:"effective_deadline"`
+	OpensAt           *time.Time `json:"opens_at,omitempty"`
+	ClosesAt          *time.Time `json:"closes_at,omitempty"`
+	ProgressStatus    string     `json:"progress_status"`
+	// Overdue is set past the effective deadline while nothing good enough was submitted
+	Overdue bool `json:"overdue"`
+}
+
+// DiscoverableCourse is an active course a student can join, as listed in the course
+// catalog. It never carries the enroll key.
+type DiscoverableCourse struct {
+	CourseID     string    `json:"course_id"`
+	Name         string    `json:"name"`
+	Description  string    `json:"description"`
+	ImageURL     string    `json:"image_url"`
+	TeacherName  string    `json:"teacher_name"`
+	StudentCount int64     `json:"student_count"`
+	CreatedAt    time.Time `json:"created_at"`
+	// Joining needs the enroll key from the teacher
+	RequiresEnrollKey bool `json:"requires_enroll_key"`
+}
+
+// MaterialDefaults are the suggested week and deadline for a new material in a course
+type MaterialDefaults struct {
+	Week     int     `json:"week"`
+	Deadline *string `json:"deadline,omitempty"` // RFC 3339; only set when the course has a deadline offset
+}
+
+// UserCourse is a course the user takes part in, either through an enrollment or as its creator
+type UserCourse struct {
+	CourseID    string     `json:"course_id"`
+	Name        string     `json:"name"`
+	Description string     `json:"description"`
+	ImageURL    string     `json:"image_url"`
+	Status      string     `json:"status"` // Course status: active or archived
+	Role        string     `json:"role"`
+	IsCreator   bool       `json:"is_creator"`
+	EnrolledAt  *time.Time `json:"enrolled_at,omitempty"` // Unset for courses the user created
+	// Percentage of the course's exercises completed; only reported for students
+	CompletionPercentage *float64 `json:"completion_percentage,omitempty"`
+}
+
+// QueueConsumerStatus describes one course queue consumer run by this instance
+type QueueConsumerStatus struct {
+	CourseID        string               `json:"course_id"`
+	QueueType       string               `json:"queue_type"`
+	Status          string               `json:"status"` // running, reconnecting, failed or stopped
+	StartedAt       *time.Time           `json:"started_at,omitempty"`
+	LastMessageAt   *time.Time           `json:"last_message_at,omitempty"`
+	MessagesHandled int64                `json:"messages_handled"`
+	RecentErrors    []QueueConsumerError `json:"recent_errors"` // Newest last
+}
+
+// StuckQueueJob is a job that has been in processing for longer than the stuck job threshold
+type StuckQueueJob struct {
+	ID              string    `json:"id"`
+	Type            string    `json:"type"`
+	UserID          string    `json:"user_id"`
+	CourseID        *string   `json:"course_id"`
+	MaterialID      *string   `json:"material_id"`
+	SubmissionID    *string   `json:"submission_id"`
+	ProcessedBy     *string   `json:"processed_by"` // TA who claimed a review job
+	ProcessingSince time.Time `json:"processing_since"`
+	AgeSeconds      int64     `json:"age_seconds"`
+}
+
+// ScheduledTaskStatus describes a periodic background task and its most recent run
+type ScheduledTaskStatus struct {
+	Name           string     `json:"name"`
+	Interval       string     `json:"interval"`
+	Running        bool       `json:"running"`
+	LastStatus     string     `json:"last_status"` // never, ok or failed
+	LastRunAt      *time.Time `json:"last_run_at,omitempty"`
+	LastDurationMs int64      `json:"last_duration_ms"`
+	LastError      string     `json:"last_error,omitempty"`
+	NextRunAt      *time.Time `json:"next_run_at,omitempty"`
+	Runs           int64      `json:"runs"`
+	Failures       int64      `json:"failures"`
+}
+
+// QueueConsumerError is a consumer start or message handling failure
+type QueueConsumerError struct {
+	Message string    `json:"message"`
+	At      time.Time `json:"at"`
+}
+
 type GoogleUser struct {
 	ID            string `json:"id"`
 	Email         string `json:"email"`
@@ -89,5 +422,47 @@ type Claims struct {
 	Email     string `json:"email"`
 	Name      string `json:"name"`
 	IsTeacher bool   `json:"is_teacher"`
+	SessionID string `json:"session_id,omitempty"`
 	jwt.RegisteredClaims
 }
+
+// UserSubmission is one entry of a student's cross-course submission feed
+type UserSubmission struct {
+	SubmissionID     string    `json:"submission_id"`
+	CourseID         string    `json:"course_id"`
+	CourseName       string    `json:"course_name"`
+	MaterialID       string    `json:"material_id"`
+	MaterialType     string    `json:"material_type"`
+	MaterialTitle    string    `json:"material_title"`
+	Status           string    `json:"status"`
+	ReviewStatus     string    `json:"review_status,omitempty"`
+	TotalScore       int       `json:"total_score"`
+	PassedCount      int       `json:"passed_count"`
+	FailedCount      int       `json:"failed_count"`
+	IsLateSubmission bool      `json:"is_late_submission"`
+	SubmittedAt      time.Time `json:"submitted_at"`
+}
+
+// MaterialIntegrityIssue is a mismatch between a course_materials row and the specific
+// material tables (videos, documents, code_exercises, pdf_exercises, announcements)
+type MaterialIntegrityIssue struct {
+	// dangling_reference: the row's reference is unset or its record does not exist
+	// invalid_reference: the reference has the wrong type or points into another course
+	// orphaned_record: a specific record no course_materials row references
+	Kind       string `json:"kind"`
+	Table      string `json:"table"`
+	MaterialID string `json:"material_id"`
+	CourseID   string `json:"course_id"`
+	Detail     string `json:"detail"`
+	// Repairable issues are fixed by deleting the dangling row or orphaned record
+	Repairable  bool   `json:"repairable"`
+	Repaired    bool   `json:"repaired"`
+	RepairError string `json:"repair_error,omitempty"`
+}
+
+// MaterialIntegrityReport is the outcome of one material reference integrity check
+type MaterialIntegrityReport struct {
+	Repair           bool                     `json:"repair"`
+	CheckedMaterials int                      `json:"checked_materials"`
+	Issues           []MaterialIntegrityIssue `json:"issues"`
+}
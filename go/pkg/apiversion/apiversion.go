@@ -0,0 +1,88 @@
+// Package apiversion names the versions of the API's response shapes. Clients may ask for
+// one with the Accept-Version request header; handlers whose response shape changed between
+// versions branch on FromContext. Every response carries the version it was shaped for in
+// X-API-Version and the server build in X-API-Build.
+package apiversion
+
+import (
+	"runtime/debug"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	// V1 is the original response shape
+	V1 = "1"
+
+	// Current is served when the request does not ask for a version
+	Current = V1
+
+	// RequestHeader selects the response shape version
+	RequestHeader = "Accept-Version"
+	// ResponseHeader reports the version the response was shaped for
+	ResponseHeader = "X-API-Version"
+	// BuildHeader reports the server build
+	BuildHeader = "X-API-Build"
+
+	localsKey = "api_version"
+)
+
+// Supported lists the versions a client may ask for, oldest first
+var Supported = []string{V1}
+
+// IsSupported reports whether the version can be served
+func IsSupported(version string) bool {
+	for _, v := range Supported {
+		if v == version {
+			return true
+		}
+	}
+	return false
+}
+
+// SetContext records the version negotiated for the request
+func SetContext(c *fiber.Ctx, version string) {
+	c.Locals(localsKey, version)
+}
+
+// FromContext returns the version negotiated for the request, or Current when the
+// request did not go through the versioning middleware
+func FromContext(c *fiber.Ctx) string {
+	if version, ok := c.Locals(localsKey).(string); ok && version != "" {
+		return version
+	}
+	return Current
+}
+
+// Build identifies the running server: the VCS revision it was built from (with a
+// -dirty suffix for uncommitted changes) or the module version, else "dev"
+var Build = readBuild()
+
+func readBuild() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return "dev"
+	}
+	var revision, modified string
+	for _, setting := range info.Settings {
+		switch setting.Key {
+		case "vcs.revision":
+			revision = setting.Value
+		case "vcs.modified":
+			modified = setting.Value
+		}
+	}
+	if revision != "" {
+		if len(revision) > 12 {
+			revision = revision[:12]
+		}
+		if modified == "true" {
+			revision += "-dirty"
+		}
+		return revision
+	}
+	if info.Main.Version != "" && info.Main.Version != "(devel)" {
+		return info.Main.Version
+	}
+	return "dev"
+}
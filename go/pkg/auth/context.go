@@ -0,0 +1,24 @@
+package auth
+
+import (
+	"errors"
+
+	"github.com/Project-DSView/backend/go/internal/types"
+	"github.com/gofiber/fiber/v2"
+)
+
+// ErrNoAuthenticatedUser is returned when the auth middleware did not set a user on the request
+var ErrNoAuthenticatedUser = errors.New("no authenticated user in request context")
+
+// UserIDFromContext returns the ID of the user the auth middleware authenticated, from
+// the user_id local or else the claims. Handlers use it instead of asserting the locals
+// directly, so a route missing its middleware answers 401 rather than panicking.
+func UserIDFromContext(c *fiber.Ctx) (string, error) {
+	if userID, ok := c.Locals("user_id").(string); ok && userID != "" {
+		return userID, nil
+	}
+	if claims, ok := c.Locals("claims").(*types.Claims); ok && claims != nil && claims.UserID != "" {
+		return claims.UserID, nil
+	}
+	return "", ErrNoAuthenticatedUser
+}
@@ -0,0 +1,62 @@
+package auth
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Project-DSView/backend/go/internal/types"
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestUserIDFromContext(t *testing.T) {
+	tests := []struct {
+		name    string
+		locals  map[string]interface{}
+		want    string
+		wantErr error
+	}{
+		{name: "user_id local", locals: map[string]interface{}{"user_id": "user-1"}, want: "user-1"},
+		{name: "claims", locals: map[string]interface{}{"claims": &types.Claims{UserID: "user-2"}}, want: "user-2"},
+		{
+			name:   "user_id local preferred over claims",
+			locals: map[string]interface{}{"user_id": "user-1", "claims": &types.Claims{UserID: "user-2"}},
+			want:   "user-1",
+		},
+		{
+			name:   "empty user_id local falls back to claims",
+			locals: map[string]interface{}{"user_id": "", "claims": &types.Claims{UserID: "user-2"}},
+			want:   "user-2",
+		},
+		{name: "no middleware", wantErr: ErrNoAuthenticatedUser},
+		{name: "user_id of another type", locals: map[string]interface{}{"user_id": 42}, wantErr: ErrNoAuthenticatedUser},
+		{name: "nil claims", locals: map[string]interface{}{"claims": (*types.Claims)(nil)}, wantErr: ErrNoAuthenticatedUser},
+		{name: "claims without a user", locals: map[string]interface{}{"claims": &types.Claims{}}, wantErr: ErrNoAuthenticatedUser},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got string
+			var err error
+			app := fiber.New()
+			app.Get("/", func(c *fiber.Ctx) error {
+				for key, value := range tt.locals {
+					c.Locals(key, value)
+				}
+				got, err = UserIDFromContext(c)
+				return c.SendStatus(fiber.StatusOK)
+			})
+
+			if _, reqErr := app.Test(httptest.NewRequest(http.MethodGet, "/", nil)); reqErr != nil {
+				t.Fatalf("request failed: %v", reqErr)
+			}
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("UserIDFromContext() error = %v, want %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("UserIDFromContext() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -10,20 +10,6 @@ func CanCreateCourse(isTeacher bool) bool {
 	return isTeacher
 }
 
-// CanEditCourse checks if a user can edit a course
-func CanEditCourse(isTeacher bool, createdBy, userID string) bool {
-	if isTeacher {
-		return true
-	}
-	// Students cannot edit courses
-	return false
-}
-
-// CanDeleteCourse checks if a user can delete a course
-func CanDeleteCourse(isTeacher bool, createdBy, userID string) bool {
-	return CanEditCourse(isTeacher, createdBy, userID)
-}
-
 // CanViewEnrollments checks if a user can view course enrollments
 func CanViewEnrollments(isTeacher bool) bool {
 	return true
@@ -0,0 +1,96 @@
+package authz
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	models "github.com/Project-DSView/backend/go/internal/domain/entities"
+	"github.com/Project-DSView/backend/go/internal/domain/enums"
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// newTestDB opens an SQLite database holding one course, created by "creator", with a
+// TA, a student and one material
+func newTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "test.db")), &gorm.Config{
+		Logger:                                   logger.Default.LogMode(logger.Silent),
+		DisableForeignKeyConstraintWhenMigrating: true,
+	})
+	if err != nil {
+		t.Fatalf("open test database: %v", err)
+	}
+	if err := db.AutoMigrate(&models.Course{}, &models.Enrollment{}, &models.CourseMaterial{}); err != nil {
+		t.Fatalf("migrate test database: %v", err)
+	}
+
+	rows := []interface{}{
+		&models.Course{CourseID: "course-1", Name: "Data Structures", CreatedBy: "creator"},
+		&models.Enrollment{CourseID: "course-1", UserID: "ta", Role: enums.EnrollmentRoleTA},
+		&models.Enrollment{CourseID: "course-1", UserID: "student", Role: enums.EnrollmentRoleStudent},
+		&models.CourseMaterial{MaterialID: "material-1", CourseID: "course-1", Type: enums.MaterialTypeDocument},
+	}
+	for _, row := range rows {
+		if err := db.Create(row).Error; err != nil {
+			t.Fatalf("create %T: %v", row, err)
+		}
+	}
+	return db
+}
+
+func TestCourseAccess(t *testing.T) {
+	db := newTestDB(t)
+
+	checks := map[string]func(db *gorm.DB, userID, id string) (bool, error){
+		"CanManageCourse":   CanManageCourse,
+		"CanReviewInCourse": CanReviewInCourse,
+		"CanViewCourse":     CanViewCourse,
+		"CanViewMaterial":   CanViewMaterial,
+	}
+	tests := []struct {
+		check   string
+		userID  string
+		id      string // course ID, or material ID for CanViewMaterial
+		want    bool
+		wantErr error
+	}{
+		{check: "CanManageCourse", userID: "creator", id: "course-1", want: true},
+		{check: "CanManageCourse", userID: "ta", id: "course-1", want: false},
+		{check: "CanManageCourse", userID: "student", id: "course-1", want: false},
+		{check: "CanManageCourse", userID: "other-teacher", id: "course-1", want: false},
+		{check: "CanManageCourse", userID: "creator", id: "missing", wantErr: ErrCourseNotFound},
+
+		{check: "CanReviewInCourse", userID: "creator", id: "course-1", want: true},
+		{check: "CanReviewInCourse", userID: "ta", id: "course-1", want: true},
+		{check: "CanReviewInCourse", userID: "student", id: "course-1", want: false},
+		{check: "CanReviewInCourse", userID: "other-teacher", id: "course-1", want: false},
+		{check: "CanReviewInCourse", userID: "ta", id: "missing", wantErr: ErrCourseNotFound},
+
+		{check: "CanViewCourse", userID: "creator", id: "course-1", want: true},
+		{check: "CanViewCourse", userID: "ta", id: "course-1", want: true},
+		{check: "CanViewCourse", userID: "student", id: "course-1", want: true},
+		{check: "CanViewCourse", userID: "other-teacher", id: "course-1", want: false},
+		{check: "CanViewCourse", userID: "student", id: "missing", wantErr: ErrCourseNotFound},
+
+		{check: "CanViewMaterial", userID: "creator", id: "material-1", want: true},
+		{check: "CanViewMaterial", userID: "ta", id: "material-1", want: true},
+		{check: "CanViewMaterial", userID: "student", id: "material-1", want: true},
+		{check: "CanViewMaterial", userID: "other-teacher", id: "material-1", want: false},
+		{check: "CanViewMaterial", userID: "student", id: "missing", wantErr: ErrMaterialNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.check+"/"+tt.userID+"/"+tt.id, func(t *testing.T) {
+			got, err := checks[tt.check](db, tt.userID, tt.id)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("%s() error = %v, want %v", tt.check, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("%s() = %v, want %v", tt.check, got, tt.want)
+			}
+		})
+	}
+}
@@ -1,7 +1,10 @@
 package config
 
 import (
+	"errors"
 	"fmt"
+	"reflect"
+	"strings"
 
 	"github.com/Project-DSView/backend/go/internal/infrastructure/config"
 	"github.com/go-playground/validator/v10"
@@ -12,6 +15,63 @@ var Validate *validator.Validate
 
 func init() {
 	Validate = validator.New()
+	// Report fields by their JSON names so clients can map errors back to inputs
+	Validate.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name := strings.SplitN(field.Tag.Get("json"), ",", 2)[0]
+		if name == "-" {
+			return ""
+		}
+		return name
+	})
+}
+
+// FieldErrors converts a Validate.Struct error into a map of JSON field name to a
+// readable message. Nested fields use dotted paths (e.g. "input_data.value").
+// It returns nil when err is not a field validation error.
+func FieldErrors(err error) map[string]string {
+	var validationErrors validator.ValidationErrors
+	if !errors.As(err, &validationErrors) {
+		return nil
+	}
+
+	fields := make(map[string]string, len(validationErrors))
+	for _, fe := range validationErrors {
+		field := fe.Namespace()
+		// Drop the struct name prefix
+		if i := strings.Index(field, "."); i >= 0 {
+			field = field[i+1:]
+		}
+		if _, exists := fields[field]; !exists {
+			fields[field] = fieldErrorMessage(fe)
+		}
+	}
+	return fields
+}
+
+func fieldErrorMessage(fe validator.FieldError) string {
+	isString := fe.Kind() == reflect.String
+	switch fe.Tag() {
+	case "required":
+		return fe.Field() + " is required"
+	case "min", "gte":
+		if isString {
+			return fmt.Sprintf("%s must be at least %s characters", fe.Field(), fe.Param())
+		}
+		return fmt.Sprintf("%s must be at least %s", fe.Field(), fe.Param())
+	case "max", "lte":
+		if isString {
+			return fmt.Sprintf("%s must be at most %s characters", fe.Field(), fe.Param())
+		}
+		return fmt.Sprintf("%s must be at most %s", fe.Field(), fe.Param())
+	case "oneof":
+		return fmt.Sprintf("%s must be one of: %s", fe.Field(), strings.ReplaceAll(fe.Param(), " ", ", "))
+	case "email":
+		return fe.Field() + " must be a valid email address"
+	case "url":
+		return fe.Field() + " must be a valid URL"
+	default:
+		return fmt.Sprintf("%s failed the '%s' check", fe.Field(), fe.Tag())
+	}
 }
 
 // ValidateConfig validates required configuration values
@@ -0,0 +1,69 @@
+package config
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestFieldErrors(t *testing.T) {
+	type testCase struct {
+		Value int `json:"value" validate:"min=1"`
+	}
+	type request struct {
+		Title      string   `json:"title" validate:"required,max=5"`
+		Visibility string   `json:"visibility,omitempty" validate:"omitempty,oneof=public private"`
+		Points     int      `json:"points" validate:"gte=0,lte=100"`
+		Contact    string   `json:"contact" validate:"omitempty,email"`
+		Internal   string   `json:"-" validate:"required"`
+		InputData  testCase `json:"input_data"`
+	}
+
+	tests := []struct {
+		name string
+		req  request
+		want map[string]string
+	}{
+		{
+			name: "valid",
+			req:  request{Title: "Sum", Points: 10, Internal: "x", InputData: testCase{Value: 1}},
+		},
+		{
+			name: "every kind of failure",
+			req:  request{Visibility: "secret", Points: 101, Contact: "nobody", Internal: "x"},
+			want: map[string]string{
+				"title":            "title is required",
+				"visibility":       "visibility must be one of: public, private",
+				"points":           "points must be at most 100",
+				"contact":          "contact must be a valid email address",
+				"input_data.value": "value must be at least 1",
+			},
+		},
+		{
+			name: "string lengths count characters",
+			req:  request{Title: "Linked lists", Internal: "x", InputData: testCase{Value: 1}},
+			want: map[string]string{"title": "title must be at most 5 characters"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Validate.Struct(tt.req)
+			got := FieldErrors(err)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("FieldErrors() = %v, want %v", got, tt.want)
+			}
+			// The raw message names fields the way clients send them too
+			if err != nil && strings.Contains(err.Error(), "InputData") {
+				t.Errorf("error %q uses Go field names", err)
+			}
+		})
+	}
+}
+
+func TestFieldErrorsIgnoresOtherErrors(t *testing.T) {
+	if got := FieldErrors(errors.New("boom")); got != nil {
+		t.Errorf("FieldErrors() = %v, want nil", got)
+	}
+}
@@ -5,20 +5,22 @@ import (
 
 	"github.com/Project-DSView/backend/go/internal/application/services"
 	"github.com/Project-DSView/backend/go/internal/types"
+	"github.com/Project-DSView/backend/go/pkg/authz"
 	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
 )
 
 // EnrollmentValidator provides methods to validate course enrollment access
 type EnrollmentValidator struct {
 	enrollmentService *services.EnrollmentService
-	courseService     *services.CourseService
+	db                *gorm.DB
 }
 
 // NewEnrollmentValidator creates a new enrollment validator
-func NewEnrollmentValidator(enrollmentService *services.EnrollmentService, courseService *services.CourseService) *EnrollmentValidator {
+func NewEnrollmentValidator(enrollmentService *services.EnrollmentService, db *gorm.DB) *EnrollmentValidator {
 	return &EnrollmentValidator{
 		enrollmentService: enrollmentService,
-		courseService:     courseService,
+		db:                db,
 	}
 }
 
@@ -31,80 +33,36 @@ func (v *EnrollmentValidator) ValidateCourseAccess(c *fiber.Ctx, courseID string
 		return fmt.Errorf("invalid authentication claims")
 	}
 
-	// If user is a teacher, check if they are the creator
-	if claims.IsTeacher {
-		course, err := v.courseService.GetCourseByID(courseID)
-		if err != nil {
-			return fmt.Errorf("failed to get course: %w", err)
-		}
-		if course == nil {
-			return fmt.Errorf("course not found")
-		}
-		// If teacher is the creator, grant access
-		if course.CreatedBy == claims.UserID {
-			return nil
-		}
-		// If teacher is not the creator, check enrollment
-		isEnrolled, err := v.enrollmentService.IsUserEnrolled(courseID, claims.UserID)
-		if err != nil {
-			return fmt.Errorf("failed to check enrollment: %w", err)
-		}
-		if !isEnrolled {
-			return fmt.Errorf("access denied: not enrolled in course")
-		}
-		return nil
-	}
+	return v.ValidateCourseAccessFromClaims(claims, courseID)
+}
 
-	// For non-teachers, check if they are enrolled in the course
-	isEnrolled, err := v.enrollmentService.IsUserEnrolled(courseID, claims.UserID)
+// ValidateCourseAccessFromClaims validates course access using provided claims
+// This is useful when you already have the claims and don't want to extract from context
+func (v *EnrollmentValidator) ValidateCourseAccessFromClaims(claims *types.Claims, courseID string) error {
+	canView, err := authz.CanViewCourse(v.db, claims.UserID, courseID)
 	if err != nil {
-		return fmt.Errorf("failed to check enrollment: %w", err)
+		return err
 	}
-
-	if !isEnrolled {
+	if !canView {
 		return fmt.Errorf("access denied: not enrolled in course")
 	}
-
 	return nil
 }
 
-// ValidateCourseAccessFromClaims validates course access using provided claims
-// This is useful when you already have the claims and don't want to extract from context
-func (v *EnrollmentValidator) ValidateCourseAccessFromClaims(claims *types.Claims, courseID string) error {
-	// If user is a teacher, check if they are the creator
-	if claims.IsTeacher {
-		course, err := v.courseService.GetCourseByID(courseID)
-		if err != nil {
-			return fmt.Errorf("failed to get course: %w", err)
-		}
-		if course == nil {
-			return fmt.Errorf("course not found")
-		}
-		// If teacher is the creator, grant access
-		if course.CreatedBy == claims.UserID {
-			return nil
-		}
-		// If teacher is not the creator, check enrollment
-		isEnrolled, err := v.enrollmentService.IsUserEnrolled(courseID, claims.UserID)
-		if err != nil {
-			return fmt.Errorf("failed to check enrollment: %w", err)
-		}
-		if !isEnrolled {
-			return fmt.Errorf("access denied: not enrolled in course")
-		}
-		return nil
+// ValidateMaterialAccess validates if a user has access to a material's course
+func (v *EnrollmentValidator) ValidateMaterialAccess(c *fiber.Ctx, materialID string) error {
+	claims, ok := c.Locals("claims").(*types.Claims)
+	if !ok {
+		return fmt.Errorf("invalid authentication claims")
 	}
 
-	// For non-teachers, check if they are enrolled in the course
-	isEnrolled, err := v.enrollmentService.IsUserEnrolled(courseID, claims.UserID)
+	canView, err := authz.CanViewMaterial(v.db, claims.UserID, materialID)
 	if err != nil {
-		return fmt.Errorf("failed to check enrollment: %w", err)
+		return err
 	}
-
-	if !isEnrolled {
+	if !canView {
 		return fmt.Errorf("access denied: not enrolled in course")
 	}
-
 	return nil
 }
 
@@ -3,10 +3,18 @@ package external
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"os/exec"
+	"regexp"
+	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
+
+	"github.com/google/uuid"
 )
 
 type DockerConfig struct {
@@ -14,6 +22,43 @@ type DockerConfig struct {
 	Timeout time.Duration
 	Memory  string // e.g. "256m"
 	CPUs    string // e.g. "0.5"
+	// MaxContainers caps how many containers this host runs at once, across every
+	// course and job type; further runs wait for a free slot
+	MaxContainers int
+	// User is the uid:gid (or name) code runs as inside the container
+	User string
+	// WritableRootFS lets code write to the container's root filesystem; by default only
+	// the /tmp working directory is writable
+	WritableRootFS bool
+}
+
+// defaultSandboxUser is the unprivileged "nobody" user, used when DockerConfig.User is not set
+const defaultSandboxUser = "65534:65534"
+
+// defaultMaxContainers is used when DockerConfig.MaxContainers is not set
+const defaultMaxContainers = 8
+
+// ContainerUsage is how many executor containers are running, waiting for a slot, and allowed at once
+type ContainerUsage struct {
+	Running int64 `json:"running"`
+	Waiting int64 `json:"waiting"`
+	Limit   int   `json:"limit"`
+}
+
+// Language is a language the executor runs code in, with the image it runs in
+type Language struct {
+	Name    string `json:"name"`
+	Image   string `json:"image"`
+	Version string `json:"version,omitempty"`
+}
+
+// ExecutorCapabilities are the languages the executor runs and the limits every run gets
+type ExecutorCapabilities struct {
+	Languages      []Language `json:"languages"`
+	TimeoutSeconds float64    `json:"timeout_seconds"`
+	Memory         string     `json:"memory"`
+	CPUs           string     `json:"cpus"`
+	MaxContainers  int        `json:"max_containers"`
 }
 
 type ExecResult struct {
@@ -21,12 +66,56 @@ type ExecResult struct {
 	Stderr   string
 	ExitCode int
 	TimedOut bool
+	// Duration is the wall-clock time of the container run, including container startup.
+	// CPU time is not reported because the docker CLI does not expose it per run.
+	Duration time.Duration
+}
+
+// dockerRunFailureExitCode is returned by `docker run` when the daemon itself fails
+// (image pull, container create, resource errors) before user code starts
+const dockerRunFailureExitCode = 125
+
+// IsInfrastructureFailure reports whether the run failed because of Docker rather than
+// the user's code. Such failures are transient and safe to retry. User code can exit with
+// 125 too, so the docker CLI must also have reported its own error, which it prefixes
+// with "docker: ".
+func (r *ExecResult) IsInfrastructureFailure() bool {
+	if r == nil || r.TimedOut || r.ExitCode != dockerRunFailureExitCode {
+		return false
+	}
+	for _, line := range strings.Split(r.Stderr, "\n") {
+		if strings.HasPrefix(line, "docker: ") {
+			return true
+		}
+	}
+	return false
 }
 
+// ErrRunCancelled is returned for the runs of a job stopped with CancelJob
+var ErrRunCancelled = errors.New("run cancelled")
+
 type DockerExecutor struct {
 	cfg DockerConfig
+
+	// Host-wide container slots, taken by every run
+	slots   chan struct{}
+	running int64
+	waiting int64
+
+	// Jobs started with TrackJob, by job ID
+	jobsMu sync.Mutex
+	jobs   map[string]*trackedJob
+}
+
+// trackedJob is a job registered with TrackJob
+type trackedJob struct {
+	cancel    context.CancelFunc
+	container string // name of the container the job is running now, if any
 }
 
+// jobIDKey carries the ID of a tracked job in the context passed to its runs
+type jobIDKey struct{}
+
 func NewDockerExecutor(cfg DockerConfig) *DockerExecutor {
 	if cfg.Image == "" {
 		cfg.Image = "python:3.11"
@@ -40,11 +129,131 @@ func NewDockerExecutor(cfg DockerConfig) *DockerExecutor {
 	if cfg.CPUs == "" {
 		cfg.CPUs = "0.5"
 	}
-	return &DockerExecutor{cfg: cfg}
+	if cfg.MaxContainers < 1 {
+		cfg.MaxContainers = defaultMaxContainers
+	}
+	if cfg.User == "" {
+		cfg.User = defaultSandboxUser
+	}
+	return &DockerExecutor{
+		cfg:   cfg,
+		slots: make(chan struct{}, cfg.MaxContainers),
+		jobs:  make(map[string]*trackedJob),
+	}
 }
 
-// wrapUserCode wraps user's Python code with JSON output wrapper
-func (e *DockerExecutor) wrapUserCode(userCode string) string {
+// TrackJob registers a job made of several runs, e.g. grading a submission, and returns
+// the context to pass to its runs. The container of the run in progress is recorded so
+// Terminate can remove it. done must be called when the job finishes, also on panic.
+func (e *DockerExecutor) TrackJob(jobID string) (ctx context.Context, done func()) {
+	ctx, cancel := context.WithCancel(context.WithValue(context.Background(), jobIDKey{}, jobID))
+	e.jobsMu.Lock()
+	e.jobs[jobID] = &trackedJob{cancel: cancel}
+	e.jobsMu.Unlock()
+
+	return ctx, func() {
+		e.jobsMu.Lock()
+		delete(e.jobs, jobID)
+		e.jobsMu.Unlock()
+		cancel()
+	}
+}
+
+// setJobContainer records the container a tracked job is running, or clears it
+func (e *DockerExecutor) setJobContainer(jobID, container string) {
+	e.jobsMu.Lock()
+	defer e.jobsMu.Unlock()
+	if job, ok := e.jobs[jobID]; ok {
+		job.container = container
+	}
+}
+
+// Terminate stops a tracked job: its later runs fail with ErrRunCancelled and the
+// container it is running is stopped and removed. It reports whether the job was running
+// on this executor.
+func (e *DockerExecutor) Terminate(jobID string) (bool, error) {
+	e.jobsMu.Lock()
+	job, ok := e.jobs[jobID]
+	var container string
+	if ok {
+		container = job.container
+		job.cancel()
+	}
+	e.jobsMu.Unlock()
+	if !ok || container == "" {
+		return ok, nil
+	}
+
+	// The run may have finished and --rm removed the container in the meantime
+	if out, err := exec.Command("docker", "rm", "-f", container).CombinedOutput(); err != nil &&
+		!strings.Contains(string(out), "No such container") {
+		return true, fmt.Errorf("remove container %s: %w: %s", container, err, strings.TrimSpace(string(out)))
+	}
+	return true, nil
+}
+
+// ContainerUsage reports the host's current container usage against its limit
+func (e *DockerExecutor) ContainerUsage() ContainerUsage {
+	return ContainerUsage{
+		Running: atomic.LoadInt64(&e.running),
+		Waiting: atomic.LoadInt64(&e.waiting),
+		Limit:   cap(e.slots),
+	}
+}
+
+// Capabilities reports the executor's languages and effective resource limits, after defaults
+func (e *DockerExecutor) Capabilities() ExecutorCapabilities {
+	return ExecutorCapabilities{
+		Languages:      []Language{{Name: "python", Image: e.cfg.Image, Version: imageVersion(e.cfg.Image)}},
+		TimeoutSeconds: e.cfg.Timeout.Seconds(),
+		Memory:         e.cfg.Memory,
+		CPUs:           e.cfg.CPUs,
+		MaxContainers:  cap(e.slots),
+	}
+}
+
+// imageVersion returns the version in an image tag, e.g. "3.12" for "python:3.12-alpine",
+// or "" when the image is untagged or tagged latest
+func imageVersion(image string) string {
+	// A digest also contains a colon, so drop it before looking for the tag
+	if j := strings.Index(image, "@"); j >= 0 {
+		image = image[:j]
+	}
+	i := strings.LastIndex(image, ":")
+	if i < 0 || strings.Contains(image[i:], "/") {
+		return ""
+	}
+	tag := image[i+1:]
+	if j := strings.Index(tag, "-"); j >= 0 {
+		tag = tag[:j]
+	}
+	if tag == "latest" {
+		return ""
+	}
+	return tag
+}
+
+// acquire waits for a free container slot and returns the function that releases it,
+// or ctx.Err() when ctx is done before a slot frees up
+func (e *DockerExecutor) acquire(ctx context.Context) (func(), error) {
+	atomic.AddInt64(&e.waiting, 1)
+	select {
+	case e.slots <- struct{}{}:
+	case <-ctx.Done():
+		atomic.AddInt64(&e.waiting, -1)
+		return nil, ctx.Err()
+	}
+	atomic.AddInt64(&e.waiting, -1)
+	atomic.AddInt64(&e.running, 1)
+	return func() {
+		atomic.AddInt64(&e.running, -1)
+		<-e.slots
+	}, nil
+}
+
+// wrapUserCode wraps user's Python code with JSON output wrapper. With plainOutput the
+// wrapper prints the program's stdout unchanged instead, for exercises compared as text.
+func (e *DockerExecutor) wrapUserCode(userCode string, plainOutput bool) string {
 	wrapper := `import json
 import sys
 import io
@@ -54,15 +263,16 @@ from contextlib import redirect_stdout
 # Create StringIO to capture stdout
 output_buffer = io.StringIO()
 
+# Whether the program's stdout is printed as is instead of being wrapped as JSON
+PLAIN_OUTPUT = PLAIN_OUTPUT_PLACEHOLDER
+
 def parse_and_wrap_output(raw_output, use_last_only=False):
     """Parse output and wrap in appropriate structure"""
-    raw_output = raw_output.strip()
-    
-    if not raw_output:
+    if not raw_output.strip():
         return ""
     
-    # Split into lines
-    lines = [line.strip() for line in raw_output.split('\n') if line.strip()]
+    # Split into lines, keeping the whitespace around text for the comparison to judge
+    lines = [line for line in raw_output.split('\n') if line.strip()]
     
     if not lines:
         return ""
@@ -73,7 +283,7 @@ def parse_and_wrap_output(raw_output, use_last_only=False):
         # Try to evaluate as Python literal first
         try:
             import ast
-            evaluated = ast.literal_eval(line)
+            evaluated = ast.literal_eval(line.strip())
             parsed_lines.append(evaluated)
         except:
             # If not a literal, keep as string
@@ -159,7 +369,10 @@ def execute_operations(operations, instance_name="mylist"):
                                 method(*params)
                             else:
                                 method()
-                        last_output = temp_buffer.getvalue().strip()
+                        last_output = temp_buffer.getvalue()
+                        # Drop only the newline print() adds
+                        if last_output.endswith("\n"):
+                            last_output = last_output[:-1]
                     else:
                         # For other operations, execute normally (no output capture needed)
                         if params:
@@ -197,19 +410,27 @@ USER_CODE_PLACEHOLDER
         # Execute operations and capture only the last traverse() output
         last_output = execute_operations(input_data["operations"])
         
-        if last_output:
+        if PLAIN_OUTPUT:
+            result = last_output or ""
+        elif last_output:
             # Use the last output from traverse()
             result = {"output": last_output}
         else:
             # If no traverse() was called, return empty output
             result = {"output": ""}
+    elif PLAIN_OUTPUT:
+        # Plain mode compares the text the program printed, so it is passed through as is
+        result = class_def_buffer.getvalue()
     else:
         # No operations, use output from user code as normal
         raw_output = class_def_buffer.getvalue()
         result = parse_and_wrap_output(raw_output)
     
-    # Output as JSON
-    print(json.dumps(result, ensure_ascii=False, separators=(',', ':')))
+    if PLAIN_OUTPUT:
+        sys.stdout.write(result)
+    else:
+        # Output as JSON
+        print(json.dumps(result, ensure_ascii=False, separators=(',', ':')))
 
 except Exception as e:
     # Handle errors gracefully
@@ -217,6 +438,13 @@ except Exception as e:
     print(json.dumps(error_info, ensure_ascii=False))
 `
 
+	// Set the output flag before inserting the user code, which could contain the placeholder
+	plainFlag := "False"
+	if plainOutput {
+		plainFlag = "True"
+	}
+	wrapper = strings.Replace(wrapper, "PLAIN_OUTPUT_PLACEHOLDER", plainFlag, 1)
+
 	// Indent user code properly
 	indentedCode := "        " + strings.ReplaceAll(userCode, "\n", "\n        ")
 	finalCode := strings.ReplaceAll(wrapper, "USER_CODE_PLACEHOLDER", indentedCode)
@@ -227,18 +455,127 @@ except Exception as e:
 // RunPython runs user code (Python) with JSON input on STDIN.
 func (e *DockerExecutor) RunPython(code string, stdinJSON string) (*ExecResult, error) {
 	// Wrap user code with JSON output wrapper
-	wrappedCode := e.wrapUserCode(code)
+	wrappedCode := e.wrapUserCode(code, false)
 
 	// For Docker-in-Docker, we'll pass the code directly via stdin instead of mounting files
 	// This avoids volume mounting issues in DinD environments
+	return e.run(context.Background(), strings.NewReader(stdinJSON), "python", "-c", wrappedCode)
+}
+
+// RunPythonStream is RunPython with the JSON input read from stdin as the program consumes
+// it, so large inputs are never held in memory
+func (e *DockerExecutor) RunPythonStream(code string, stdin io.Reader) (*ExecResult, error) {
+	return e.RunPythonStreamContext(context.Background(), code, stdin)
+}
+
+// RunPythonStreamContext is RunPythonStream as part of a job; cancelling ctx kills the container
+func (e *DockerExecutor) RunPythonStreamContext(ctx context.Context, code string, stdin io.Reader) (*ExecResult, error) {
+	return e.run(ctx, stdin, "python", "-c", e.wrapUserCode(code, false))
+}
+
+// RunPythonPlainStreamContext is RunPythonStreamContext for exercises in plain output
+// mode: Stdout is what the program printed, not parsed or wrapped as JSON
+func (e *DockerExecutor) RunPythonPlainStreamContext(ctx context.Context, code string, stdin io.Reader) (*ExecResult, error) {
+	return e.run(ctx, stdin, "python", "-c", e.wrapUserCode(code, true))
+}
+
+// compileCheckScript reads source from STDIN and byte-compiles it without executing it
+const compileCheckScript = `import sys
+compile(sys.stdin.read(), "submission.py", "exec")`
+
+// CompileCheck runs the language's compile step once for the submitted code.
+// For Python this is a syntax check: the code is compiled but never executed.
+// A non-zero ExitCode means compilation failed and Stderr holds the compiler output.
+func (e *DockerExecutor) CompileCheck(code string) (*ExecResult, error) {
+	return e.CompileCheckContext(context.Background(), code)
+}
+
+// CompileCheckContext is CompileCheck as part of a job; cancelling ctx kills the container
+func (e *DockerExecutor) CompileCheckContext(ctx context.Context, code string) (*ExecResult, error) {
+	return e.run(ctx, strings.NewReader(code), "python", "-c", compileCheckScript)
+}
+
+// Diagnostic is an error found in source code. Line and Column are 1-based, or 0 when
+// the compiler did not report a position.
+type Diagnostic struct {
+	Line    int    `json:"line"`
+	Column  int    `json:"column"`
+	Message string `json:"message"`
+}
+
+// CheckSyntax runs CompileCheckContext and returns the error the compiler reports as a
+// diagnostic, or none when the code compiles. An error means the check itself could not run.
+func (e *DockerExecutor) CheckSyntax(ctx context.Context, code string) ([]Diagnostic, error) {
+	res, err := e.CompileCheckContext(ctx, code)
+	if err != nil {
+		return nil, err
+	}
+	if res.TimedOut {
+		return nil, fmt.Errorf("syntax check timed out after %s", e.cfg.Timeout)
+	}
+	if res.IsInfrastructureFailure() {
+		return nil, fmt.Errorf("syntax check failed to run: %s", strings.TrimSpace(res.Stderr))
+	}
+	if res.ExitCode == 0 {
+		return []Diagnostic{}, nil
+	}
+	diagnostic, ok := parseCompileError(res.Stderr, code)
+	if !ok {
+		return nil, fmt.Errorf("syntax check exited with code %d: %s", res.ExitCode, strings.TrimSpace(res.Stderr))
+	}
+	return []Diagnostic{diagnostic}, nil
+}
+
+// compileErrorFrame matches the traceback frame of compileCheckScript's source
+var compileErrorFrame = regexp.MustCompile(`File "submission\.py", line (\d+)`)
+
+// parseCompileError reads the traceback compileCheckScript prints for code that does not
+// compile. Its last line names the error; the frame in submission.py, when present, gives
+// the line and is followed by that source line, printed without its indentation, and a
+// caret under the column.
+func parseCompileError(stderr, code string) (Diagnostic, bool) {
+	lines := strings.Split(strings.TrimRight(stderr, "\n"), "\n")
+	name, message, ok := strings.Cut(lines[len(lines)-1], ": ")
+	if !ok || !strings.HasSuffix(name, "Error") {
+		return Diagnostic{}, false
+	}
+	diagnostic := Diagnostic{Message: message}
+
+	for i, line := range lines {
+		match := compileErrorFrame.FindStringSubmatch(line)
+		if match == nil {
+			continue
+		}
+		diagnostic.Line, _ = strconv.Atoi(match[1])
+		codeLines := strings.Split(code, "\n")
+		if i+2 >= len(lines) || diagnostic.Line < 1 || diagnostic.Line > len(codeLines) {
+			break
+		}
+		shown := lines[i+1]
+		shownStart := len(shown) - len(strings.TrimLeft(shown, " "))
+		caret := strings.Index(lines[i+2], "^")
+		if caret >= shownStart {
+			source := codeLines[diagnostic.Line-1]
+			indent := len(source) - len(strings.TrimLeft(source, " \t"))
+			diagnostic.Column = caret - shownStart + indent + 1
+		}
+		break
+	}
+	return diagnostic, true
+}
+
+// run executes a command inside a fresh sandboxed container, feeding stdin to it.
+// The container is named so that it can be killed when the run times out or ctx is
+// cancelled; killing the docker CLI alone would leave it running.
+func (e *DockerExecutor) run(ctx context.Context, stdin io.Reader, command ...string) (*ExecResult, error) {
+	name := "dsview-run-" + uuid.New().String()
 	args := []string{
 		"run", "--rm", "-i",
+		"--name", name,
 		// Disable network access inside the container
 		"--network", "none",
-		// Enforce read-only root filesystem
-		"--read-only",
-		// Run as root to avoid permission issues
-		"--user", "0:0",
+		// Run as an unprivileged user unless configured otherwise
+		"--user", e.cfg.User,
 		// Drop all Linux capabilities and prevent gaining new privileges
 		"--cap-drop", "ALL",
 		"--security-opt", "no-new-privileges",
@@ -252,26 +589,54 @@ func (e *DockerExecutor) RunPython(code string, stdinJSON string) (*ExecResult,
 		"--cpus", e.cfg.CPUs,
 		// Set working directory
 		"-w", "/tmp",
-		e.cfg.Image,
-		"python", "-c", wrappedCode,
 	}
+	// Enforce read-only root filesystem; /tmp above stays writable
+	if !e.cfg.WritableRootFS {
+		args = append(args, "--read-only")
+	}
+	args = append(args, e.cfg.Image)
+	args = append(args, command...)
 
-	ctx, cancel := context.WithTimeout(context.Background(), e.cfg.Timeout)
+	// Wait for a slot before the timeout starts, so time spent waiting is not charged to the run
+	release, err := e.acquire(ctx)
+	if err != nil {
+		return nil, ErrRunCancelled
+	}
+	defer release()
+	if ctx.Err() != nil {
+		return nil, ErrRunCancelled
+	}
+	if jobID, ok := ctx.Value(jobIDKey{}).(string); ok {
+		e.setJobContainer(jobID, name)
+		defer e.setJobContainer(jobID, "")
+	}
+
+	runCtx, cancel := context.WithTimeout(ctx, e.cfg.Timeout)
 	defer cancel()
 
-	cmd := exec.CommandContext(ctx, "docker", args...)
+	cmd := exec.CommandContext(runCtx, "docker", args...)
+	cmd.Cancel = func() error {
+		// The container may already be gone, e.g. when it never started
+		_ = exec.Command("docker", "kill", name).Run()
+		return cmd.Process.Kill()
+	}
 	var stdout, stderr bytes.Buffer
 	cmd.Stdout = &stdout
 	cmd.Stderr = &stderr
-	cmd.Stdin = bytes.NewBufferString(stdinJSON)
+	cmd.Stdin = stdin
 
-	err := cmd.Run()
+	started := time.Now()
+	err = cmd.Run()
 
 	result := &ExecResult{
-		Stdout: strings.TrimSpace(stdout.String()), // ลบ whitespace ส่วนเกิน
-		Stderr: stderr.String(),
+		Stdout:   stdout.String(),
+		Stderr:   stderr.String(),
+		Duration: time.Since(started),
+	}
+	if ctx.Err() != nil {
+		return nil, ErrRunCancelled
 	}
-	if ctx.Err() == context.DeadlineExceeded {
+	if runCtx.Err() == context.DeadlineExceeded {
 		result.TimedOut = true
 		return result, nil
 	}
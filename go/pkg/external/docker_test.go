@@ -0,0 +1,426 @@
+package external
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeDocker puts a docker command first on PATH that records its arguments, one per
+// line, and exits 0. It returns the file the arguments are written to.
+func fakeDocker(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	argsFile := filepath.Join(dir, "args")
+	script := "#!/bin/sh\ncat >/dev/null\nprintf '%s\\n' \"$@\" > \"$FAKE_DOCKER_ARGS\"\n"
+	if err := os.WriteFile(filepath.Join(dir, "docker"), []byte(script), 0o755); err != nil {
+		t.Fatalf("write fake docker: %v", err)
+	}
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	t.Setenv("FAKE_DOCKER_ARGS", argsFile)
+	return argsFile
+}
+
+func TestDockerSandboxArgs(t *testing.T) {
+	tests := []struct {
+		name         string
+		cfg          DockerConfig
+		wantUser     string
+		wantReadOnly bool
+	}{
+		{"defaults", DockerConfig{}, defaultSandboxUser, true},
+		{"configured user", DockerConfig{User: "1000:1000"}, "1000:1000", true},
+		{"writable root filesystem", DockerConfig{WritableRootFS: true}, defaultSandboxUser, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			argsFile := fakeDocker(t)
+			tt.cfg.Image = "python:3.12"
+			if _, err := NewDockerExecutor(tt.cfg).CompileCheckContext(context.Background(), "print(1)"); err != nil {
+				t.Fatalf("run: %v", err)
+			}
+
+			data, err := os.ReadFile(argsFile)
+			if err != nil {
+				t.Fatalf("read docker args: %v", err)
+			}
+			args := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+
+			user := ""
+			readOnly := false
+			image := -1
+			for i, arg := range args {
+				switch {
+				case arg == "--user" && i+1 < len(args):
+					user = args[i+1]
+				case arg == "--read-only":
+					readOnly = true
+				case arg == tt.cfg.Image && image < 0:
+					image = i
+				}
+			}
+			if user != tt.wantUser {
+				t.Errorf("--user = %q, want %q", user, tt.wantUser)
+			}
+			if readOnly != tt.wantReadOnly {
+				t.Errorf("--read-only set = %v, want %v", readOnly, tt.wantReadOnly)
+			}
+			if image < 0 {
+				t.Fatalf("image missing from the docker args: %v", args)
+			}
+			// Flags after the image would be passed to the command instead of docker
+			for _, arg := range args[image+1:] {
+				if arg == "--read-only" || arg == "--user" {
+					t.Errorf("%s comes after the image: %v", arg, args)
+				}
+			}
+		})
+	}
+}
+
+// TestRunWaitingForSlotIsCancellable checks that a run waiting for a container slot on a
+// full host gives up when its context ends, instead of blocking until a slot frees up
+func TestRunWaitingForSlotIsCancellable(t *testing.T) {
+	tests := []struct {
+		name   string
+		cancel func(ctx context.Context) (context.Context, context.CancelFunc)
+	}{
+		{"cancelled", func(ctx context.Context) (context.Context, context.CancelFunc) {
+			ctx, cancel := context.WithCancel(ctx)
+			time.AfterFunc(20*time.Millisecond, cancel)
+			return ctx, cancel
+		}},
+		{"deadline", func(ctx context.Context) (context.Context, context.CancelFunc) {
+			return context.WithTimeout(ctx, 20*time.Millisecond)
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			argsFile := fakeDocker(t)
+			e := NewDockerExecutor(DockerConfig{Image: "python:3.12", MaxContainers: 1})
+			release, err := e.acquire(context.Background())
+			if err != nil {
+				t.Fatalf("take the only slot: %v", err)
+			}
+			defer release()
+
+			ctx, cancel := tt.cancel(context.Background())
+			defer cancel()
+			done := make(chan error, 1)
+			go func() {
+				_, err := e.CompileCheckContext(ctx, "print(1)")
+				done <- err
+			}()
+
+			select {
+			case err := <-done:
+				if !errors.Is(err, ErrRunCancelled) {
+					t.Errorf("err = %v, want %v", err, ErrRunCancelled)
+				}
+			case <-time.After(5 * time.Second):
+				t.Fatal("run still waiting for a slot after its context ended")
+			}
+			if usage := e.ContainerUsage(); usage.Waiting != 0 || usage.Running != 1 {
+				t.Errorf("usage = %+v, want 0 waiting and 1 running", usage)
+			}
+			if _, err := os.Stat(argsFile); !os.IsNotExist(err) {
+				t.Errorf("docker was run for a cancelled run (stat: %v)", err)
+			}
+		})
+	}
+}
+
+func TestAcquireReturnsContextError(t *testing.T) {
+	e := NewDockerExecutor(DockerConfig{MaxContainers: 1})
+	release, err := e.acquire(context.Background())
+	if err != nil {
+		t.Fatalf("take the only slot: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := e.acquire(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("err = %v, want %v", err, context.DeadlineExceeded)
+	}
+
+	// The slot is handed out again once released
+	release()
+	release, err = e.acquire(context.Background())
+	if err != nil {
+		t.Fatalf("acquire after release: %v", err)
+	}
+	release()
+}
+
+// TestRunKeepsOutputWhitespace checks that stdout is returned as the container printed
+// it; whether surrounding whitespace matters is up to the comparison
+func TestRunKeepsOutputWhitespace(t *testing.T) {
+	dir := t.TempDir()
+	script := "#!/bin/sh\ncat >/dev/null\nprintf '  out  \\n\\n'\n"
+	if err := os.WriteFile(filepath.Join(dir, "docker"), []byte(script), 0o755); err != nil {
+		t.Fatalf("write fake docker: %v", err)
+	}
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	res, err := NewDockerExecutor(DockerConfig{}).RunPython("print('out')", "{}")
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if want := "  out  \n\n"; res.Stdout != want {
+		t.Errorf("Stdout = %q, want %q", res.Stdout, want)
+	}
+}
+
+func TestParseCompileError(t *testing.T) {
+	tests := []struct {
+		name   string
+		code   string
+		stderr string
+		want   Diagnostic
+		wantOK bool
+	}{
+		{
+			name: "unclosed bracket in an indented line",
+			code: "def f():\n    print(\n",
+			stderr: "Traceback (most recent call last):\n  File \"<string>\", line 2, in <module>\n" +
+				"  File \"submission.py\", line 2\n    print(\n         ^\nSyntaxError: '(' was never closed\n",
+			want:   Diagnostic{Line: 2, Column: 10, Message: "'(' was never closed"},
+			wantOK: true,
+		},
+		{
+			name: "invalid syntax",
+			code: "if True:\n    x = 1 +* 2\n",
+			stderr: "Traceback (most recent call last):\n  File \"<string>\", line 2, in <module>\n" +
+				"  File \"submission.py\", line 2\n    x = 1 +* 2\n           ^\nSyntaxError: invalid syntax\n",
+			want:   Diagnostic{Line: 2, Column: 12, Message: "invalid syntax"},
+			wantOK: true,
+		},
+		{
+			name: "no caret",
+			code: "x = 1\n    y = 2\n",
+			stderr: "Traceback (most recent call last):\n  File \"<string>\", line 2, in <module>\n" +
+				"  File \"submission.py\", line 2\n    y = 2\nIndentationError: unexpected indent\n",
+			want:   Diagnostic{Line: 2, Message: "unexpected indent"},
+			wantOK: true,
+		},
+		{
+			name: "no position",
+			code: "a\x00b",
+			stderr: "Traceback (most recent call last):\n  File \"<string>\", line 2, in <module>\n" +
+				"SyntaxError: source code string cannot contain null bytes\n",
+			want:   Diagnostic{Message: "source code string cannot contain null bytes"},
+			wantOK: true,
+		},
+		{
+			name:   "not a compile error",
+			code:   "print(1)",
+			stderr: "Killed\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := parseCompileError(tt.stderr, tt.code)
+			if ok != tt.wantOK {
+				t.Fatalf("ok = %v, want %v", ok, tt.wantOK)
+			}
+			if got != tt.want {
+				t.Errorf("diagnostic = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsInfrastructureFailure(t *testing.T) {
+	tests := []struct {
+		name string
+		res  *ExecResult
+		want bool
+	}{
+		{"daemon error", &ExecResult{ExitCode: 125, Stderr: "docker: Error response from daemon: no space left on device.\n"}, true},
+		{"image pull", &ExecResult{ExitCode: 125, Stderr: "Unable to find image 'python:3.12' locally\ndocker: Error response from daemon: pull access denied.\n"}, true},
+		{"user code exits 125", &ExecResult{ExitCode: 125, Stderr: ""}, false},
+		{"user code prints an error", &ExecResult{ExitCode: 125, Stderr: "Traceback (most recent call last):\nSystemExit: 125\n"}, false},
+		{"other exit code", &ExecResult{ExitCode: 1, Stderr: "docker: Error response from daemon\n"}, false},
+		{"timed out", &ExecResult{ExitCode: 125, TimedOut: true, Stderr: "docker: Error response from daemon\n"}, false},
+		{"no result", nil, false},
+	}
+	for _, tt := range tests {
+		if got := tt.res.IsInfrastructureFailure(); got != tt.want {
+			t.Errorf("%s: IsInfrastructureFailure() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestTerminate(t *testing.T) {
+	const container = "dsview-run-1"
+
+	t.Run("running job", func(t *testing.T) {
+		argsFile := fakeDocker(t)
+		e := NewDockerExecutor(DockerConfig{})
+		ctx, done := e.TrackJob("job-1")
+		defer done()
+		e.setJobContainer("job-1", container)
+
+		running, err := e.Terminate("job-1")
+		if err != nil || !running {
+			t.Fatalf("Terminate() = %v, %v, want true, nil", running, err)
+		}
+		if ctx.Err() == nil {
+			t.Error("job context not cancelled")
+		}
+		data, err := os.ReadFile(argsFile)
+		if err != nil {
+			t.Fatalf("read docker args: %v", err)
+		}
+		if got, want := string(data), "rm\n-f\n"+container+"\n"; got != want {
+			t.Errorf("docker args = %q, want %q", got, want)
+		}
+	})
+
+	t.Run("between runs", func(t *testing.T) {
+		argsFile := fakeDocker(t)
+		e := NewDockerExecutor(DockerConfig{})
+		ctx, done := e.TrackJob("job-1")
+		defer done()
+
+		running, err := e.Terminate("job-1")
+		if err != nil || !running {
+			t.Fatalf("Terminate() = %v, %v, want true, nil", running, err)
+		}
+		if ctx.Err() == nil {
+			t.Error("job context not cancelled")
+		}
+		if _, err := os.Stat(argsFile); !os.IsNotExist(err) {
+			t.Errorf("docker was run without a container to remove (stat: %v)", err)
+		}
+	})
+
+	t.Run("finished job", func(t *testing.T) {
+		argsFile := fakeDocker(t)
+		e := NewDockerExecutor(DockerConfig{})
+		_, done := e.TrackJob("job-1")
+		e.setJobContainer("job-1", container)
+		done()
+
+		running, err := e.Terminate("job-1")
+		if err != nil || running {
+			t.Fatalf("Terminate() = %v, %v, want false, nil", running, err)
+		}
+		if _, err := os.Stat(argsFile); !os.IsNotExist(err) {
+			t.Errorf("docker was run for a finished job (stat: %v)", err)
+		}
+	})
+
+	for _, tt := range []struct {
+		name    string
+		stderr  string
+		wantErr bool
+	}{
+		{"container already removed", "Error response from daemon: No such container: " + container, false},
+		{"daemon error", "Cannot connect to the Docker daemon", true},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			script := "#!/bin/sh\necho '" + tt.stderr + "' >&2\nexit 1\n"
+			if err := os.WriteFile(filepath.Join(dir, "docker"), []byte(script), 0o755); err != nil {
+				t.Fatalf("write fake docker: %v", err)
+			}
+			t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+			e := NewDockerExecutor(DockerConfig{})
+			_, done := e.TrackJob("job-1")
+			defer done()
+			e.setJobContainer("job-1", container)
+
+			running, err := e.Terminate("job-1")
+			if !running {
+				t.Error("Terminate() reported the job as not running")
+			}
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Terminate() error = %v, want error %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// TestRunRecordsJobContainer checks that a tracked job's container is recorded under the
+// name it is started with while it runs, and cleared once the run ends
+func TestRunRecordsJobContainer(t *testing.T) {
+	dir := t.TempDir()
+	release := filepath.Join(dir, "release")
+	// Wait until the test creates release, then print the container name
+	script := "#!/bin/sh\ncat >/dev/null\nwhile [ \"$1\" != --name ]; do shift; done\n" +
+		"while [ ! -e \"$FAKE_DOCKER_RELEASE\" ]; do sleep 0.01; done\necho \"$2\"\n"
+	if err := os.WriteFile(filepath.Join(dir, "docker"), []byte(script), 0o755); err != nil {
+		t.Fatalf("write fake docker: %v", err)
+	}
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	t.Setenv("FAKE_DOCKER_RELEASE", release)
+
+	e := NewDockerExecutor(DockerConfig{Timeout: 10 * time.Second})
+	ctx, done := e.TrackJob("job-1")
+	defer done()
+	recorded := func() string {
+		e.jobsMu.Lock()
+		defer e.jobsMu.Unlock()
+		return e.jobs["job-1"].container
+	}
+
+	type result struct {
+		res *ExecResult
+		err error
+	}
+	finished := make(chan result, 1)
+	go func() {
+		res, err := e.CompileCheckContext(ctx, "print(1)")
+		finished <- result{res, err}
+	}()
+
+	var during string
+	for deadline := time.Now().Add(5 * time.Second); during == "" && time.Now().Before(deadline); {
+		time.Sleep(5 * time.Millisecond)
+		during = recorded()
+	}
+	if err := os.WriteFile(release, nil, 0o644); err != nil {
+		t.Fatalf("release fake docker: %v", err)
+	}
+	r := <-finished
+	if r.err != nil {
+		t.Fatalf("run: %v", r.err)
+	}
+
+	if name := strings.TrimSpace(r.res.Stdout); during != name {
+		t.Errorf("container recorded during the run = %q, want %q", during, name)
+	}
+	if after := recorded(); after != "" {
+		t.Errorf("container %q still recorded after the run", after)
+	}
+}
+
+func TestImageVersion(t *testing.T) {
+	tests := []struct {
+		image string
+		want  string
+	}{
+		{"python:3.12", "3.12"},
+		{"python:3.12-alpine", "3.12"},
+		{"python:3.12@sha256:0123abcd", "3.12"},
+		{"python@sha256:0123abcd", ""},
+		{"python:latest", ""},
+		{"python", ""},
+		{"registry.local:5000/python", ""},
+		{"registry.local:5000/python:3.11-slim", "3.11"},
+	}
+	for _, tt := range tests {
+		if got := imageVersion(tt.image); got != tt.want {
+			t.Errorf("imageVersion(%q) = %q, want %q", tt.image, got, tt.want)
+		}
+	}
+}
@@ -4,6 +4,8 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/Project-DSView/backend/go/pkg/logger"
@@ -13,12 +15,28 @@ import (
 type RabbitMQConfig struct {
 	URL      string
 	Exchange string
+	// QueuePrefix namespaces every queue this service declares, e.g. "staging",
+	// so several environments can share one broker. Empty keeps the plain names.
+	QueuePrefix string
+
+	// PublishMaxAttempts bounds how often a publish is tried on transient
+	// errors; PublishBackoff is the first delay and doubles up to PublishMaxBackoff.
+	PublishMaxAttempts int
+	PublishBackoff     time.Duration
+	PublishMaxBackoff  time.Duration
 }
 
 type RabbitMQService struct {
 	conn    *amqp.Connection
 	channel *amqp.Channel
 	config  *RabbitMQConfig
+
+	// mu guards conn/channel, which are replaced when the connection is recovered
+	mu         sync.RWMutex
+	connected  bool
+	recovering bool
+	closed     bool
+	consumers  []*consumerRegistration
 }
 
 type QueueMessage struct {
@@ -66,36 +84,57 @@ func NewRabbitMQService(config *RabbitMQConfig) (*RabbitMQService, error) {
 		return nil, fmt.Errorf("failed to connect to RabbitMQ after retries: %w", err)
 	}
 
-	channel, err := conn.Channel()
+	channel, err := openChannel(conn, config.Exchange)
 	if err != nil {
 		conn.Close()
+		return nil, err
+	}
+
+	// Note: Queues are now created dynamically per course
+	// No global queues are created at startup
+
+	r := &RabbitMQService{
+		conn:      conn,
+		channel:   channel,
+		config:    config,
+		connected: true,
+	}
+	go r.watch(conn, channel)
+	return r, nil
+}
+
+// openChannel opens a channel and declares the exchange on it
+func openChannel(conn *amqp.Connection, exchange string) (*amqp.Channel, error) {
+	channel, err := conn.Channel()
+	if err != nil {
 		return nil, fmt.Errorf("failed to open channel: %w", err)
 	}
 
 	// Declare exchange
 	err = channel.ExchangeDeclare(
-		config.Exchange, // name
-		"direct",        // type
-		true,            // durable
-		false,           // auto-deleted
-		false,           // internal
-		false,           // no-wait
-		nil,             // arguments
+		exchange, // name
+		"direct", // type
+		true,     // durable
+		false,    // auto-deleted
+		false,    // internal
+		false,    // no-wait
+		nil,      // arguments
 	)
 	if err != nil {
 		channel.Close()
-		conn.Close()
 		return nil, fmt.Errorf("failed to declare exchange: %w", err)
 	}
+	return channel, nil
+}
 
-	// Note: Queues are now created dynamically per course
-	// No global queues are created at startup
-
-	return &RabbitMQService{
-		conn:    conn,
-		channel: channel,
-		config:  config,
-	}, nil
+// currentChannel returns the live channel, or nil while the connection is being recovered
+func (r *RabbitMQService) currentChannel() *amqp.Channel {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if !r.connected {
+		return nil
+	}
+	return r.channel
 }
 
 // GetQueueName generates a course-specific queue name
@@ -103,10 +142,28 @@ func GetQueueName(queueType, courseID string) string {
 	return fmt.Sprintf("%s_course_%s", queueType, courseID)
 }
 
+// queueName returns the course-specific queue name with the configured prefix
+func (r *RabbitMQService) queueName(queueType, courseID string) string {
+	return r.prefixed(GetQueueName(queueType, courseID))
+}
+
+// prefixed applies the configured queue prefix to name
+func (r *RabbitMQService) prefixed(name string) string {
+	if r.config.QueuePrefix == "" {
+		return name
+	}
+	return r.config.QueuePrefix + "_" + name
+}
+
 // EnsureQueueExists ensures that a queue exists, creating it if necessary
 func (r *RabbitMQService) EnsureQueueExists(queueName string) error {
+	channel := r.currentChannel()
+	if channel == nil {
+		return amqp.ErrClosed
+	}
+
 	// Try to declare queue (idempotent operation)
-	_, err := r.channel.QueueDeclare(
+	_, err := channel.QueueDeclare(
 		queueName, // name
 		true,      // durable
 		false,     // delete when unused
@@ -119,7 +176,7 @@ func (r *RabbitMQService) EnsureQueueExists(queueName string) error {
 	}
 
 	// Bind queue to exchange with routing key = queue name
-	err = r.channel.QueueBind(
+	err = channel.QueueBind(
 		queueName,         // queue name
 		queueName,         // routing key
 		r.config.Exchange, // exchange
@@ -136,7 +193,7 @@ func (r *RabbitMQService) EnsureQueueExists(queueName string) error {
 // PublishMessage publishes a message to the specified course-specific queue
 func (r *RabbitMQService) PublishMessage(ctx context.Context, queueType, courseID string, message *QueueMessage) error {
 	// Generate course-specific queue name
-	queueName := GetQueueName(queueType, courseID)
+	queueName := r.queueName(queueType, courseID)
 
 	// Ensure queue exists
 	if err := r.EnsureQueueExists(queueName); err != nil {
@@ -150,18 +207,28 @@ func (r *RabbitMQService) PublishMessage(ctx context.Context, queueType, courseI
 		return fmt.Errorf("failed to marshal message: %w", err)
 	}
 
-	err = r.channel.Publish(
-		r.config.Exchange, // exchange
-		queueName,         // routing key (use queue name as routing key)
-		false,             // mandatory
-		false,             // immediate
-		amqp.Publishing{
-			ContentType: "application/json",
-			Body:        body,
-			Timestamp:   message.CreatedAt,
-			MessageId:   message.ID,
-		},
-	)
+	publishing := amqp.Publishing{
+		ContentType: "application/json",
+		Body:        body,
+		Timestamp:   message.CreatedAt,
+		MessageId:   message.ID,
+	}
+
+	err = publishWithRetry(ctx, r.publishRetryPolicy(), func() error {
+		channel := r.currentChannel()
+		if channel == nil {
+			return amqp.ErrClosed
+		}
+		return channel.Publish(
+			r.config.Exchange, // exchange
+			queueName,         // routing key (use queue name as routing key)
+			false,             // mandatory
+			false,             // immediate
+			publishing,
+		)
+	}, func(attempt int, err error) {
+		logger.Warnf("Publish to queue %s failed (attempt %d): %v", queueName, attempt, err)
+	})
 	if err != nil {
 		return fmt.Errorf("failed to publish message: %w", err)
 	}
@@ -170,24 +237,59 @@ func (r *RabbitMQService) PublishMessage(ctx context.Context, queueType, courseI
 	return nil
 }
 
-// ConsumeMessages starts consuming messages from the specified course-specific queue
+func (r *RabbitMQService) publishRetryPolicy() retryPolicy {
+	return retryPolicy{
+		maxAttempts: r.config.PublishMaxAttempts,
+		backoff:     r.config.PublishBackoff,
+		maxBackoff:  r.config.PublishMaxBackoff,
+	}
+}
+
+// consumerSeq numbers consumer tags, which must be unique per channel
+var consumerSeq uint64
+
+// ConsumeMessages starts consuming messages from the specified course-specific queue.
+// The consumer is registered with the service so it is restarted after a reconnect,
+// and is unsubscribed from the queue when ctx ends.
 func (r *RabbitMQService) ConsumeMessages(ctx context.Context, queueType, courseID string, handler func(*QueueMessage) error) error {
-	// Generate course-specific queue name
-	queueName := GetQueueName(queueType, courseID)
+	reg := &consumerRegistration{
+		ctx: ctx,
+		// Generate course-specific queue name
+		queueName: r.queueName(queueType, courseID),
+		handler:   handler,
+	}
+
+	if err := r.startConsumer(reg); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	r.consumers = append(r.consumers, reg)
+	r.mu.Unlock()
+	return nil
+}
 
+// startConsumer subscribes to the registration's queue on the current channel.
+// The delivery loop ends when ctx is done or the channel closes.
+func (r *RabbitMQService) startConsumer(reg *consumerRegistration) error {
 	// Ensure queue exists before consuming
-	if err := r.EnsureQueueExists(queueName); err != nil {
+	if err := r.EnsureQueueExists(reg.queueName); err != nil {
 		return fmt.Errorf("failed to ensure queue exists: %w", err)
 	}
 
-	msgs, err := r.channel.Consume(
-		queueName, // queue
-		"",        // consumer
-		false,     // auto-ack
-		false,     // exclusive
-		false,     // no-local
-		false,     // no-wait
-		nil,       // args
+	channel := r.currentChannel()
+	if channel == nil {
+		return fmt.Errorf("failed to register consumer: %w", amqp.ErrClosed)
+	}
+	tag := fmt.Sprintf("%s-%d", reg.queueName, atomic.AddUint64(&consumerSeq, 1))
+	msgs, err := channel.Consume(
+		reg.queueName, // queue
+		tag,           // consumer
+		false,         // auto-ack
+		false,         // exclusive
+		false,         // no-local
+		false,         // no-wait
+		nil,           // args
 	)
 	if err != nil {
 		return fmt.Errorf("failed to register consumer: %w", err)
@@ -196,9 +298,23 @@ func (r *RabbitMQService) ConsumeMessages(ctx context.Context, queueType, course
 	go func() {
 		for {
 			select {
-			case <-ctx.Done():
+			case <-reg.ctx.Done():
+				// Stop the broker delivering to this consumer and give back what it already
+				// sent, so the messages wait in the queue for the next consumer. When the
+				// channel is gone the broker requeues them itself.
+				if err := channel.Cancel(tag, false); err != nil {
+					return
+				}
+				for msg := range msgs {
+					msg.Nack(false, true)
+				}
 				return
-			case msg := <-msgs:
+			case msg, ok := <-msgs:
+				if !ok {
+					// Channel closed; the watcher restarts this consumer once recovered
+					return
+				}
+
 				var queueMessage QueueMessage
 				if err := json.Unmarshal(msg.Body, &queueMessage); err != nil {
 					logger.Errorf("Failed to unmarshal message: %v", err)
@@ -206,7 +322,7 @@ func (r *RabbitMQService) ConsumeMessages(ctx context.Context, queueType, course
 					continue
 				}
 
-				if err := handler(&queueMessage); err != nil {
+				if err := reg.handler(&queueMessage); err != nil {
 					logger.Errorf("Failed to process message %s: %v", queueMessage.ID, err)
 					msg.Nack(false, true) // Requeue on error
 				} else {
@@ -222,8 +338,12 @@ func (r *RabbitMQService) ConsumeMessages(ctx context.Context, queueType, course
 
 // GetQueueInfo returns information about the specified course-specific queue
 func (r *RabbitMQService) GetQueueInfo(queueType, courseID string) (*amqp.Queue, error) {
-	queueName := GetQueueName(queueType, courseID)
-	queue, err := r.channel.QueueInspect(queueName)
+	queueName := r.queueName(queueType, courseID)
+	channel := r.currentChannel()
+	if channel == nil {
+		return nil, fmt.Errorf("failed to inspect queue %s: %w", queueName, amqp.ErrClosed)
+	}
+	queue, err := channel.QueueInspect(queueName)
 	if err != nil {
 		return nil, fmt.Errorf("failed to inspect queue %s: %w", queueName, err)
 	}
@@ -232,8 +352,12 @@ func (r *RabbitMQService) GetQueueInfo(queueType, courseID string) (*amqp.Queue,
 
 // PurgeQueue removes all messages from the specified course-specific queue
 func (r *RabbitMQService) PurgeQueue(queueType, courseID string) (int, error) {
-	queueName := GetQueueName(queueType, courseID)
-	count, err := r.channel.QueuePurge(queueName, false)
+	queueName := r.queueName(queueType, courseID)
+	channel := r.currentChannel()
+	if channel == nil {
+		return 0, fmt.Errorf("failed to purge queue %s: %w", queueName, amqp.ErrClosed)
+	}
+	count, err := channel.QueuePurge(queueName, false)
 	if err != nil {
 		return 0, fmt.Errorf("failed to purge queue %s: %w", queueName, err)
 	}
@@ -242,8 +366,12 @@ func (r *RabbitMQService) PurgeQueue(queueType, courseID string) (int, error) {
 
 // DeleteQueue deletes a course-specific queue
 func (r *RabbitMQService) DeleteQueue(queueType, courseID string) error {
-	queueName := GetQueueName(queueType, courseID)
-	_, err := r.channel.QueueDelete(queueName, false, false, false)
+	queueName := r.queueName(queueType, courseID)
+	channel := r.currentChannel()
+	if channel == nil {
+		return fmt.Errorf("failed to delete queue %s: %w", queueName, amqp.ErrClosed)
+	}
+	_, err := channel.QueueDelete(queueName, false, false, false)
 	if err != nil {
 		return fmt.Errorf("failed to delete queue %s: %w", queueName, err)
 	}
@@ -265,6 +393,13 @@ func (r *RabbitMQService) DeleteCourseQueues(courseID string) error {
 
 // Close closes the RabbitMQ connection
 func (r *RabbitMQService) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// Stop the watcher from reconnecting
+	r.closed = true
+	r.connected = false
+
 	if r.channel != nil {
 		if err := r.channel.Close(); err != nil {
 			logger.Warnf("Failed to close channel: %v", err)
@@ -280,13 +415,14 @@ func (r *RabbitMQService) Close() error {
 
 // Health check
 func (r *RabbitMQService) HealthCheck(ctx context.Context) error {
-	if r.conn == nil || r.channel == nil {
-		return fmt.Errorf("RabbitMQ connection is not initialized")
+	channel := r.currentChannel()
+	if channel == nil {
+		return fmt.Errorf("RabbitMQ connection is not available")
 	}
 
 	// Try to declare a test queue to check connection
-	testQueue := "health_check_test"
-	_, err := r.channel.QueueDeclare(
+	testQueue := r.prefixed("health_check_test")
+	_, err := channel.QueueDeclare(
 		testQueue,
 		false, // not durable
 		true,  // delete when unused
@@ -299,7 +435,7 @@ func (r *RabbitMQService) HealthCheck(ctx context.Context) error {
 	}
 
 	// Clean up test queue
-	_, err = r.channel.QueueDelete(testQueue, false, false, false)
+	_, err = channel.QueueDelete(testQueue, false, false, false)
 	if err != nil {
 		logger.Warnf("Failed to delete test queue: %v", err)
 	}
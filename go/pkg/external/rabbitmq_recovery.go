@@ -0,0 +1,125 @@
+package external
+
+import (
+	"context"
+	"time"
+
+	"github.com/Project-DSView/backend/go/pkg/logger"
+	"github.com/streadway/amqp"
+)
+
+const (
+	reconnectInitialDelay = time.Second
+	reconnectMaxDelay     = 30 * time.Second
+)
+
+// consumerRegistration remembers a consumer so it can be restarted on a new channel
+type consumerRegistration struct {
+	ctx       context.Context
+	queueName string
+	handler   func(*QueueMessage) error
+}
+
+// IsConnected reports whether the broker connection is currently usable
+func (r *RabbitMQService) IsConnected() bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.connected
+}
+
+// watch waits for the connection or channel to close and then starts recovery
+func (r *RabbitMQService) watch(conn *amqp.Connection, channel *amqp.Channel) {
+	connClosed := conn.NotifyClose(make(chan *amqp.Error, 1))
+	channelClosed := channel.NotifyClose(make(chan *amqp.Error, 1))
+
+	var reason *amqp.Error
+	select {
+	case reason = <-connClosed:
+	case reason = <-channelClosed:
+	}
+
+	r.mu.Lock()
+	if r.closed || r.recovering {
+		r.mu.Unlock()
+		return
+	}
+	r.connected = false
+	r.recovering = true
+	r.mu.Unlock()
+
+	if reason != nil {
+		logger.Warnf("RabbitMQ connection lost: %v", reason)
+	} else {
+		logger.Warn("RabbitMQ connection lost")
+	}
+	r.recover()
+}
+
+// recover redials if the connection is gone (or only reopens the channel if it
+// is still up), then re-declares queues and restarts every registered consumer.
+// It keeps trying with capped exponential backoff until it succeeds or Close is called.
+func (r *RabbitMQService) recover() {
+	delay := reconnectInitialDelay
+	for attempt := 1; ; attempt++ {
+		r.mu.RLock()
+		closed := r.closed
+		conn := r.conn
+		r.mu.RUnlock()
+		if closed {
+			return
+		}
+
+		var err error
+		if conn == nil || conn.IsClosed() {
+			conn, err = amqp.Dial(r.config.URL)
+		}
+		var channel *amqp.Channel
+		if err == nil {
+			channel, err = openChannel(conn, r.config.Exchange)
+		}
+		if err == nil {
+			r.mu.Lock()
+			if r.closed {
+				r.mu.Unlock()
+				channel.Close()
+				conn.Close()
+				return
+			}
+			r.conn = conn
+			r.channel = channel
+			r.connected = true
+			r.recovering = false
+			consumers := r.pruneConsumersLocked()
+			r.mu.Unlock()
+
+			logger.Infof("RabbitMQ connection recovered after %d attempt(s)", attempt)
+			for _, reg := range consumers {
+				if err := r.startConsumer(reg); err != nil {
+					logger.Errorf("Failed to restart consumer for queue %s: %v", reg.queueName, err)
+				}
+			}
+			go r.watch(conn, channel)
+			return
+		}
+
+		logger.Warnf("RabbitMQ reconnect attempt %d failed, retrying in %v: %v", attempt, delay, err)
+		time.Sleep(delay)
+		delay *= 2
+		if delay > reconnectMaxDelay {
+			delay = reconnectMaxDelay
+		}
+	}
+}
+
+// pruneConsumersLocked drops consumers whose context has ended and returns the rest.
+// Callers must hold r.mu.
+func (r *RabbitMQService) pruneConsumersLocked() []*consumerRegistration {
+	active := r.consumers[:0]
+	for _, reg := range r.consumers {
+		if reg.ctx.Err() == nil {
+			active = append(active, reg)
+		}
+	}
+	r.consumers = active
+	return append([]*consumerRegistration(nil), active...)
+}
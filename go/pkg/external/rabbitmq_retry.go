@@ -0,0 +1,79 @@
+package external
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/streadway/amqp"
+)
+
+// retryPolicy controls how publishes are retried on transient broker errors
+type retryPolicy struct {
+	maxAttempts int
+	backoff     time.Duration
+	maxBackoff  time.Duration
+}
+
+// delay returns the wait before the given retry (1-based), doubling each time
+func (p retryPolicy) delay(retry int) time.Duration {
+	d := p.backoff
+	for i := 1; i < retry; i++ {
+		d *= 2
+		if p.maxBackoff > 0 && d >= p.maxBackoff {
+			return p.maxBackoff
+		}
+	}
+	if p.maxBackoff > 0 && d > p.maxBackoff {
+		return p.maxBackoff
+	}
+	return d
+}
+
+// isTransientAMQPError reports whether an error is worth retrying: a closed
+// channel/connection or a broker error flagged as recoverable.
+func isTransientAMQPError(err error) bool {
+	if errors.Is(err, amqp.ErrClosed) {
+		return true
+	}
+	var amqpErr *amqp.Error
+	if errors.As(err, &amqpErr) {
+		return amqpErr.Recover || amqpErr.Code == amqp.ConnectionForced
+	}
+	return false
+}
+
+// publishWithRetry calls publish until it succeeds, fails with a permanent
+// error, the attempts run out or ctx is done. onFailure runs after each
+// transient failure that will be retried.
+func publishWithRetry(ctx context.Context, policy retryPolicy, publish func() error, onFailure func(attempt int, err error)) error {
+	attempts := policy.maxAttempts
+	if attempts < 1 {
+		attempts = 1
+	}
+
+	var err error
+	for attempt := 1; attempt <= attempts; attempt++ {
+		if err = publish(); err == nil {
+			return nil
+		}
+		if !isTransientAMQPError(err) || attempt == attempts {
+			break
+		}
+		if onFailure != nil {
+			onFailure(attempt, err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("publish cancelled after %d attempts: %w", attempt, err)
+		case <-time.After(policy.delay(attempt)):
+		}
+	}
+
+	if attempts > 1 && isTransientAMQPError(err) {
+		return fmt.Errorf("giving up after %d attempts: %w", attempts, err)
+	}
+	return err
+}
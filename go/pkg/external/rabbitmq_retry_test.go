@@ -0,0 +1,97 @@
+package external
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/streadway/amqp"
+)
+
+func TestPublishWithRetry(t *testing.T) {
+	errPermanent := errors.New("message too large")
+	policy := retryPolicy{maxAttempts: 3, backoff: time.Millisecond, maxBackoff: 2 * time.Millisecond}
+
+	tests := []struct {
+		name string
+		// errs are returned by successive publishes; once they run out publish succeeds
+		errs         []error
+		wantErr      error
+		wantCalls    int
+		wantFailures int
+	}{
+		{"first attempt succeeds", nil, nil, 1, 0},
+		{"success after a closed channel", []error{amqp.ErrClosed}, nil, 2, 1},
+		{"success after a recoverable broker error", []error{&amqp.Error{Code: amqp.ResourceError, Recover: true}, amqp.ErrClosed}, nil, 3, 2},
+		{"attempts exhausted", []error{amqp.ErrClosed, amqp.ErrClosed, amqp.ErrClosed, amqp.ErrClosed}, amqp.ErrClosed, 3, 2},
+		{"permanent error is not retried", []error{errPermanent}, errPermanent, 1, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls, failures := 0, 0
+			err := publishWithRetry(context.Background(), policy, func() error {
+				calls++
+				if calls <= len(tt.errs) {
+					return tt.errs[calls-1]
+				}
+				return nil
+			}, func(attempt int, err error) {
+				failures++
+				if attempt != failures {
+					t.Errorf("onFailure attempt = %d, want %d", attempt, failures)
+				}
+			})
+
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("err = %v, want %v", err, tt.wantErr)
+			}
+			if calls != tt.wantCalls {
+				t.Errorf("publish calls = %d, want %d", calls, tt.wantCalls)
+			}
+			if failures != tt.wantFailures {
+				t.Errorf("onFailure calls = %d, want %d", failures, tt.wantFailures)
+			}
+		})
+	}
+}
+
+func TestPublishWithRetryStopsWhenCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	policy := retryPolicy{maxAttempts: 5, backoff: time.Hour}
+
+	calls := 0
+	err := publishWithRetry(ctx, policy, func() error {
+		calls++
+		return amqp.ErrClosed
+	}, func(int, error) { cancel() })
+
+	if !errors.Is(err, amqp.ErrClosed) {
+		t.Errorf("err = %v, want it to wrap %v", err, amqp.ErrClosed)
+	}
+	if calls != 1 {
+		t.Errorf("publish calls = %d, want 1", calls)
+	}
+}
+
+func TestRetryPolicyDelay(t *testing.T) {
+	policy := retryPolicy{backoff: 100 * time.Millisecond, maxBackoff: time.Second}
+
+	tests := []struct {
+		retry int
+		want  time.Duration
+	}{
+		{1, 100 * time.Millisecond},
+		{2, 200 * time.Millisecond},
+		{4, 800 * time.Millisecond},
+		{5, time.Second},
+		{10, time.Second},
+	}
+
+	for _, tt := range tests {
+		if got := policy.delay(tt.retry); got != tt.want {
+			t.Errorf("delay(%d) = %s, want %s", tt.retry, got, tt.want)
+		}
+	}
+}
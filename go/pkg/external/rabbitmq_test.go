@@ -0,0 +1,23 @@
+package external
+
+import "testing"
+
+func TestQueueNamePrefix(t *testing.T) {
+	tests := []struct {
+		prefix     string
+		wantCourse string
+		wantHealth string
+	}{
+		{"", "code_execution_course_course-1", "health_check_test"},
+		{"staging", "staging_code_execution_course_course-1", "staging_health_check_test"},
+	}
+	for _, tt := range tests {
+		r := &RabbitMQService{config: &RabbitMQConfig{QueuePrefix: tt.prefix}}
+		if got := r.queueName("code_execution", "course-1"); got != tt.wantCourse {
+			t.Errorf("prefix %q: queueName() = %q, want %q", tt.prefix, got, tt.wantCourse)
+		}
+		if got := r.prefixed("health_check_test"); got != tt.wantHealth {
+			t.Errorf("prefix %q: prefixed() = %q, want %q", tt.prefix, got, tt.wantHealth)
+		}
+	}
+}
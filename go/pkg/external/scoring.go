@@ -1,8 +1,12 @@
 package external
 
 import (
+	"bytes"
 	"encoding/json"
+	"math"
+	"math/big"
 	"reflect"
+	"strings"
 )
 
 func CompareJSON(expected, actual interface{}) bool {
@@ -22,6 +26,147 @@ func CompareJSON(expected, actual interface{}) bool {
 	return reflect.DeepEqual(expectedNormalized, actualNormalized)
 }
 
+// CompareOptions control how CompareJSONWith matches expected and actual output
+type CompareOptions struct {
+	// TrimStrings ignores leading and trailing whitespace in string values, so "hello "
+	// matches "hello"
+	TrimStrings bool
+	// NumberTolerance is how far apart two numbers may be and still match: an absolute
+	// difference for numbers up to 1 and relative to the larger one above that. 0 requires
+	// equal values.
+	NumberTolerance float64
+}
+
+// CompareJSONWith compares expected and actual as JSON values. Numbers compare by value
+// whatever their representation, so 1 matches 1.0 and 1e2 matches 100. Two integers
+// written without a fraction or exponent compare exactly, so large integers beyond
+// float64 precision do not collapse into each other; any other pair of numbers matches
+// within NumberTolerance.
+func CompareJSONWith(expected, actual interface{}, opts CompareOptions) bool {
+	expectedValue, err1 := decodeJSONNumbers(expected)
+	actualValue, err2 := decodeJSONNumbers(actual)
+	if err1 != nil || err2 != nil {
+		return CompareJSON(expected, actual)
+	}
+	return opts.equal(expectedValue, actualValue)
+}
+
+// decodeJSONNumbers round-trips v through JSON keeping numbers as json.Number, so raw
+// JSON bytes and decoded values compare alike and integer digits are not rounded
+func decodeJSONNumbers(v interface{}) (interface{}, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var decoded interface{}
+	if err := decoder.Decode(&decoded); err != nil {
+		return nil, err
+	}
+	return decoded, nil
+}
+
+func (o CompareOptions) equal(expected, actual interface{}) bool {
+	switch exp := expected.(type) {
+	case json.Number:
+		act, ok := actual.(json.Number)
+		return ok && o.numbersEqual(exp, act)
+	case string:
+		act, ok := actual.(string)
+		if !ok {
+			return false
+		}
+		if o.TrimStrings {
+			return strings.TrimSpace(exp) == strings.TrimSpace(act)
+		}
+		return exp == act
+	case []interface{}:
+		act, ok := actual.([]interface{})
+		if !ok || len(exp) != len(act) {
+			return false
+		}
+		for i := range exp {
+			if !o.equal(exp[i], act[i]) {
+				return false
+			}
+		}
+		return true
+	case map[string]interface{}:
+		act, ok := actual.(map[string]interface{})
+		if !ok || len(exp) != len(act) {
+			return false
+		}
+		for key, value := range exp {
+			actualValue, exists := act[key]
+			if !exists || !o.equal(value, actualValue) {
+				return false
+			}
+		}
+		return true
+	default:
+		// null and booleans
+		return expected == actual
+	}
+}
+
+func (o CompareOptions) numbersEqual(expected, actual json.Number) bool {
+	if isIntegerLiteral(expected) && isIntegerLiteral(actual) {
+		exp, ok1 := new(big.Int).SetString(expected.String(), 10)
+		act, ok2 := new(big.Int).SetString(actual.String(), 10)
+		if ok1 && ok2 {
+			return exp.Cmp(act) == 0
+		}
+	}
+
+	exp, err1 := expected.Float64()
+	act, err2 := actual.Float64()
+	if err1 != nil || err2 != nil {
+		return expected == actual
+	}
+	if exp == act {
+		return true
+	}
+	scale := math.Max(1, math.Max(math.Abs(exp), math.Abs(act)))
+	return math.Abs(exp-act) <= o.NumberTolerance*scale
+}
+
+func isIntegerLiteral(n json.Number) bool {
+	return !strings.ContainsAny(n.String(), ".eE")
+}
+
+// PlainExpectedOutput returns the expected output of a test case as text for plain-mode
+// comparison, trimmed unless the exercise matches whitespace strictly. A stored JSON
+// string is unquoted; anything else is used as written.
+func PlainExpectedOutput(expected []byte, trimWhitespace bool) string {
+	text := string(expected)
+	var unquoted string
+	if err := json.Unmarshal(expected, &unquoted); err == nil {
+		text = unquoted
+	}
+	if trimWhitespace {
+		return strings.TrimSpace(text)
+	}
+	return text
+}
+
+// PlainActualOutput returns stdout as text for plain-mode comparison: trimmed, or with
+// only the trailing newline added by print removed when whitespace is matched strictly
+func PlainActualOutput(stdout string, trimWhitespace bool) string {
+	if trimWhitespace {
+		return strings.TrimSpace(stdout)
+	}
+	return TrimTrailingNewline(stdout)
+}
+
+// TrimTrailingNewline removes one trailing newline (as added by print) from stdout
+func TrimTrailingNewline(stdout string) string {
+	if strings.HasSuffix(stdout, "\r\n") {
+		return stdout[:len(stdout)-2]
+	}
+	return strings.TrimSuffix(stdout, "\n")
+}
+
 func ScoreFromCounts(totalPoints, passed, total int) int {
 	if total <= 0 {
 		return 0
@@ -0,0 +1,42 @@
+package external
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestCompareJSONWithNumbers(t *testing.T) {
+	const tolerance = 1e-9
+
+	tests := []struct {
+		name      string
+		expected  string
+		actual    string
+		tolerance float64
+		want      bool
+	}{
+		{"integer and float", `1`, `1.0`, 0, true},
+		{"exponent", `100`, `1e2`, 0, true},
+		{"float noise", `0.3`, `0.30000000000000004`, tolerance, true},
+		{"float noise without tolerance", `0.3`, `0.30000000000000004`, 0, false},
+		{"wrong answer", `0.3`, `0.31`, tolerance, false},
+		{"absolute tolerance below 1", `0.001`, `0.0015`, 0.001, true},
+		{"relative tolerance above 1", `1000000.0`, `1000500.0`, 0.001, true},
+		{"outside relative tolerance", `1000000.0`, `1002000.0`, 0.001, false},
+		{"integers ignore the tolerance", `1000000`, `1000500`, 0.001, false},
+		{"large integers compare exactly", `9007199254740993`, `9007199254740992`, tolerance, false},
+		{"large equal integers", `123456789012345678901234567890`, `123456789012345678901234567890`, 0, true},
+		{"numbers nested in arrays", `[1.5,[2,3]]`, `[1.5000000000001,[2.0,3]]`, tolerance, true},
+		{"numbers nested in objects", `{"area":3.14159}`, `{"area":3.14158}`, tolerance, false},
+		{"number against string", `1`, `"1"`, tolerance, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := CompareJSONWith(json.RawMessage(tt.expected), json.RawMessage(tt.actual), CompareOptions{NumberTolerance: tt.tolerance})
+			if got != tt.want {
+				t.Errorf("CompareJSONWith(%s, %s) = %v, want %v", tt.expected, tt.actual, got, tt.want)
+			}
+		})
+	}
+}
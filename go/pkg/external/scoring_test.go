@@ -0,0 +1,65 @@
+package external
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestCompareJSONWithTrimStrings(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected string
+		actual   string
+		trim     bool
+		want     bool
+	}{
+		{"equal strings", `"hello"`, `"hello"`, false, true},
+		{"trailing space kept", `"hello"`, `"hello "`, false, false},
+		{"trailing space trimmed", `"hello"`, `"hello "`, true, true},
+		{"newlines trimmed", `"hello"`, `"\nhello\n"`, true, true},
+		{"inner space still compared", `"hello world"`, `"hello  world"`, true, false},
+		{"strings nested in objects", `{"name":"Ada","tags":["a","b"]}`, `{"name":" Ada","tags":["a ","b"]}`, true, true},
+		{"strings nested in objects kept", `{"name":"Ada"}`, `{"name":" Ada"}`, false, false},
+		{"trim does not turn numbers into strings", `3`, `" 3 "`, true, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := CompareJSONWith(json.RawMessage(tt.expected), json.RawMessage(tt.actual), CompareOptions{TrimStrings: tt.trim})
+			if got != tt.want {
+				t.Errorf("CompareJSONWith(%s, %s) = %v, want %v", tt.expected, tt.actual, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPlainOutput(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected string
+		stdout   string
+		trim     bool
+		want     bool
+	}{
+		{"print newline ignored when strict", `"hello"`, "hello\n", false, true},
+		{"windows newline ignored when strict", `"hello"`, "hello\r\n", false, true},
+		{"second newline counts when strict", `"hello"`, "hello\n\n", false, false},
+		{"leading space counts when strict", `"hello"`, " hello\n", false, false},
+		{"leading space trimmed", `"hello"`, " hello\n", true, true},
+		{"blank lines trimmed", `"hello"`, "\nhello\n\n", true, true},
+		{"expected whitespace trimmed", `"  hello\n"`, "hello\n", true, true},
+		{"expected whitespace kept when strict", `"hello "`, "hello \n", false, true},
+		{"expected not a json string", `42`, "42\n", false, true},
+		{"multi-line output", `"1\n2"`, "1\n2\n", false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expected := PlainExpectedOutput([]byte(tt.expected), tt.trim)
+			actual := PlainActualOutput(tt.stdout, tt.trim)
+			if got := expected == actual; got != tt.want {
+				t.Errorf("expected %q, actual %q: match = %v, want %v", expected, actual, got, tt.want)
+			}
+		})
+	}
+}
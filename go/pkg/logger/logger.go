@@ -5,6 +5,9 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
@@ -39,6 +42,27 @@ func (l LogLevel) String() string {
 	}
 }
 
+// LogFormat selects how log entries are written
+type LogFormat string
+
+const (
+	// FormatJSON writes one JSON object per line, for log aggregators such as ELK or Loki
+	FormatJSON LogFormat = "json"
+	// FormatText writes one human-readable line per entry, followed by key=value fields
+	FormatText LogFormat = "text"
+)
+
+// ParseLogFormat returns the format named by s; an empty name is JSON
+func ParseLogFormat(s string) (LogFormat, error) {
+	switch LogFormat(strings.ToLower(strings.TrimSpace(s))) {
+	case "", FormatJSON:
+		return FormatJSON, nil
+	case FormatText:
+		return FormatText, nil
+	}
+	return "", fmt.Errorf("unknown log format %q (want json or text)", s)
+}
+
 // LogEntry represents a structured log entry
 type LogEntry struct {
 	Timestamp  string                 `json:"timestamp"`
@@ -65,6 +89,7 @@ type LogEntry struct {
 type Logger struct {
 	name         string
 	level        LogLevel
+	format       LogFormat
 	logFile      *os.File
 	appFile      *os.File
 	errorFile    *os.File
@@ -77,16 +102,24 @@ var (
 	globalLogger *Logger
 )
 
-// NewLogger creates a new structured logger
-func NewLogger(name string, level LogLevel, logDir string) (*Logger, error) {
+// NewLogger creates a new structured logger writing entries in the given format
+func NewLogger(name string, level LogLevel, logDir string, format LogFormat) (*Logger, error) {
+	if format == "" {
+		format = FormatJSON
+	}
+	if format != FormatJSON && format != FormatText {
+		return nil, fmt.Errorf("unknown log format %q", format)
+	}
+
 	// Ensure log directory exists
 	if err := os.MkdirAll(logDir, 0755); err != nil {
 		return nil, fmt.Errorf("failed to create log directory: %w", err)
 	}
 
 	logger := &Logger{
-		name:  name,
-		level: level,
+		name:   name,
+		level:  level,
+		format: format,
 	}
 
 	// Open log files
@@ -145,6 +178,12 @@ func (l *Logger) writeLog(level LogLevel, entry LogEntry, file *os.File) {
 	entry.Timestamp = time.Now().UTC().Format(time.RFC3339Nano)
 	entry.Logger = l.name
 	entry.Level = level.String()
+	entry.promoteExtra()
+
+	if l.format == FormatText {
+		fmt.Fprintln(file, entry.text())
+		return
+	}
 
 	data, err := json.Marshal(entry)
 	if err != nil {
@@ -155,6 +194,90 @@ func (l *Logger) writeLog(level LogLevel, entry LogEntry, file *os.File) {
 	fmt.Fprintln(file, string(data))
 }
 
+// promoteExtra moves the extra fields that LogEntry has its own field for, such as
+// request_id, out of Extra, so every entry carries them under the same top-level keys
+func (e *LogEntry) promoteExtra() {
+	if len(e.Extra) == 0 {
+		return
+	}
+	for key, field := range map[string]*string{
+		"request_id": &e.RequestID,
+		"method":     &e.Method,
+		"path":       &e.Path,
+		"client_ip":  &e.ClientIP,
+		"user_agent": &e.UserAgent,
+		"user_id":    &e.UserID,
+		"error":      &e.Error,
+		"error_type": &e.ErrorType,
+	} {
+		if v, ok := e.Extra[key]; ok && v != nil {
+			*field = fmt.Sprint(v)
+			delete(e.Extra, key)
+		}
+	}
+	if v, ok := e.Extra["status_code"].(int); ok {
+		e.StatusCode = v
+		delete(e.Extra, "status_code")
+	}
+	if v, ok := e.Extra["duration"].(float64); ok {
+		e.Duration = v
+		delete(e.Extra, "duration")
+	}
+}
+
+// text formats the entry as "timestamp LEVEL logger: message key=value ...", with the
+// same keys as the JSON format; extra fields follow in key order
+func (e LogEntry) text() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s %-5s %s: %s", e.Timestamp, e.Level, e.Logger, e.Message)
+
+	field := func(key string, value interface{}) {
+		v := fmt.Sprint(value)
+		if v == "" || strings.ContainsAny(v, " \t\r\n\"=") {
+			v = strconv.Quote(v)
+		}
+		fmt.Fprintf(&b, " %s=%s", key, v)
+	}
+	for _, f := range []struct {
+		key   string
+		value string
+	}{
+		{"module", e.Module},
+		{"function", e.Function},
+		{"request_id", e.RequestID},
+		{"method", e.Method},
+		{"path", e.Path},
+		{"client_ip", e.ClientIP},
+		{"user_agent", e.UserAgent},
+		{"user_id", e.UserID},
+		{"error", e.Error},
+		{"error_type", e.ErrorType},
+	} {
+		if f.value != "" {
+			field(f.key, f.value)
+		}
+	}
+	if e.Line != 0 {
+		field("line", e.Line)
+	}
+	if e.StatusCode != 0 {
+		field("status_code", e.StatusCode)
+	}
+	if e.Duration != 0 {
+		field("duration", e.Duration)
+	}
+
+	keys := make([]string, 0, len(e.Extra))
+	for key := range e.Extra {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		field(key, e.Extra[key])
+	}
+	return b.String()
+}
+
 // log writes a log entry if the level is appropriate
 func (l *Logger) log(level LogLevel, message string, extra map[string]interface{}) {
 	if level < l.level {
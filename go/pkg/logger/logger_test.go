@@ -0,0 +1,100 @@
+package logger
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestParseLogFormat(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    LogFormat
+		wantErr bool
+	}{
+		{"", FormatJSON, false},
+		{"json", FormatJSON, false},
+		{" Text ", FormatText, false},
+		{"logfmt", "", true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseLogFormat(tt.in)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ParseLogFormat(%q) = %q, %v; want %q, error %v", tt.in, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestNewLoggerRejectsUnknownFormat(t *testing.T) {
+	if _, err := NewLogger("test", INFO, t.TempDir(), "logfmt"); err == nil {
+		t.Error("NewLogger() accepted an unknown format")
+	}
+}
+
+// logLines creates a logger in the given format, writes one error entry and returns the
+// lines of the error log
+func logLines(t *testing.T, format LogFormat) []string {
+	t.Helper()
+	dir := t.TempDir()
+	l, err := NewLogger("go-app", INFO, dir, format)
+	if err != nil {
+		t.Fatalf("NewLogger() error = %v", err)
+	}
+	l.Debug("below the level")
+	l.Error("Failed to save", errors.New("disk full"), map[string]interface{}{
+		"request_id": "req-1",
+		"course_id":  "course 1",
+		"attempt":    2,
+	})
+	if err := l.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	if data, err := os.ReadFile(filepath.Join(dir, "go_app.log")); err != nil || len(data) != 0 {
+		t.Errorf("app log = %q, %v; want the debug entry dropped", data, err)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "go_error.log"))
+	if err != nil {
+		t.Fatalf("read error log: %v", err)
+	}
+	return strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+}
+
+func TestJSONFormat(t *testing.T) {
+	lines := logLines(t, FormatJSON)
+	if len(lines) != 1 {
+		t.Fatalf("error log has %d lines, want 1: %q", len(lines), lines)
+	}
+
+	var entry LogEntry
+	if err := json.Unmarshal([]byte(lines[0]), &entry); err != nil {
+		t.Fatalf("entry is not JSON: %v", err)
+	}
+	if entry.Level != "ERROR" || entry.Logger != "go-app" || entry.Message != "Failed to save" ||
+		entry.RequestID != "req-1" || entry.Error != "disk full" || entry.ErrorType != "*errors.errorString" {
+		t.Errorf("entry = %+v", entry)
+	}
+	if _, ok := entry.Extra["request_id"]; ok {
+		t.Error("request_id left in extra after promoting it")
+	}
+	if entry.Extra["course_id"] != "course 1" || entry.Extra["attempt"] != float64(2) {
+		t.Errorf("extra = %v, want course_id and attempt", entry.Extra)
+	}
+}
+
+func TestTextFormat(t *testing.T) {
+	lines := logLines(t, FormatText)
+	if len(lines) != 1 {
+		t.Fatalf("error log has %d lines, want 1: %q", len(lines), lines)
+	}
+
+	want := regexp.MustCompile(`^\S+ ERROR go-app: Failed to save request_id=req-1 error="disk full" error_type=\*errors.errorString attempt=2 course_id="course 1"$`)
+	if !want.MatchString(lines[0]) {
+		t.Errorf("entry = %q, want it to match %s", lines[0], want)
+	}
+}
@@ -0,0 +1,87 @@
+package material
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+
+	models "github.com/Project-DSView/backend/go/internal/domain/entities"
+)
+
+// Lint reports likely misconfigurations of a material that do not make it invalid,
+// such as an exercise whose deadline has already passed. testCases are the code
+// exercise's test cases and are ignored for other material types.
+// The returned warnings are meant to be shown to the teacher; nil means none were found.
+func Lint(m models.Material, testCases []models.TestCase) []string {
+	var warnings []string
+
+	switch material := m.(type) {
+	case *models.CodeExercise:
+		if strings.TrimSpace(material.ProblemStatement) == "" {
+			warnings = append(warnings, "Problem statement is empty")
+		}
+		warnings = append(warnings, lintDeadline(material.Deadline)...)
+		warnings = append(warnings, lintTestCases(testCases)...)
+		if len(testCases) > 0 && !hasNonEmptyEntry(material.ExampleOutputs) {
+			warnings = append(warnings, "Example outputs are empty, so students see no expected output")
+		}
+
+	case *models.PDFExercise:
+		warnings = append(warnings, lintDeadline(material.Deadline)...)
+		if material.FileURL == "" {
+			warnings = append(warnings, "No exercise file is attached")
+		}
+	}
+
+	return warnings
+}
+
+func lintDeadline(deadline *string) []string {
+	if deadline == nil || *deadline == "" {
+		return nil
+	}
+	deadlineTime, err := time.Parse(time.RFC3339, *deadline)
+	if err != nil {
+		return []string{"Deadline is not a valid RFC 3339 timestamp and will block all submissions"}
+	}
+	if deadlineTime.Before(time.Now()) {
+		return []string{"Deadline is in the past, so students cannot submit"}
+	}
+	return nil
+}
+
+func lintTestCases(testCases []models.TestCase) []string {
+	if len(testCases) == 0 {
+		return []string{"No test cases, so submissions cannot be graded"}
+	}
+	for _, tc := range testCases {
+		if !tc.IsPublic {
+			return nil
+		}
+	}
+	return []string{"No hidden test cases; every test case is visible to students"}
+}
+
+// hasNonEmptyEntry reports whether a JSON array of example strings has at least one
+// entry with content. Plain-text outputs are stored as {"output": "..."}.
+func hasNonEmptyEntry(data []byte) bool {
+	var entries []string
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return false
+	}
+	for _, entry := range entries {
+		var wrapped struct {
+			Output *string `json:"output"`
+		}
+		if json.Unmarshal([]byte(entry), &wrapped) == nil && wrapped.Output != nil {
+			if strings.TrimSpace(*wrapped.Output) != "" {
+				return true
+			}
+			continue
+		}
+		if strings.TrimSpace(entry) != "" {
+			return true
+		}
+	}
+	return false
+}
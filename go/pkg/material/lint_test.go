@@ -0,0 +1,75 @@
+package material
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	models "github.com/Project-DSView/backend/go/internal/domain/entities"
+	"github.com/Project-DSView/backend/go/internal/types"
+)
+
+func TestLint(t *testing.T) {
+	future := time.Now().Add(24 * time.Hour).Format(time.RFC3339)
+	past := time.Now().Add(-24 * time.Hour).Format(time.RFC3339)
+	invalid := "next friday"
+	examples := types.JSONData(`["{\"output\":\"3\"}"]`)
+	hidden := []models.TestCase{{IsPublic: true}, {IsPublic: false}}
+
+	tests := []struct {
+		name      string
+		material  models.Material
+		testCases []models.TestCase
+		want      []string
+	}{
+		{
+			name:      "complete code exercise",
+			material:  &models.CodeExercise{ProblemStatement: "Add the numbers", Deadline: &future, ExampleOutputs: examples},
+			testCases: hidden,
+		},
+		{
+			name:     "empty code exercise",
+			material: &models.CodeExercise{ProblemStatement: "  "},
+			want:     []string{"Problem statement is empty", "No test cases, so submissions cannot be graded"},
+		},
+		{
+			name:      "only public test cases and blank example outputs",
+			material:  &models.CodeExercise{ProblemStatement: "Add the numbers", ExampleOutputs: types.JSONData(`["{\"output\":\" \"}", ""]`)},
+			testCases: []models.TestCase{{IsPublic: true}},
+			want: []string{
+				"No hidden test cases; every test case is visible to students",
+				"Example outputs are empty, so students see no expected output",
+			},
+		},
+		{
+			name:      "code exercise past its deadline",
+			material:  &models.CodeExercise{ProblemStatement: "Add the numbers", Deadline: &past, ExampleOutputs: examples},
+			testCases: hidden,
+			want:      []string{"Deadline is in the past, so students cannot submit"},
+		},
+		{
+			name:     "PDF exercise with an invalid deadline and no file",
+			material: &models.PDFExercise{Deadline: &invalid},
+			want: []string{
+				"Deadline is not a valid RFC 3339 timestamp and will block all submissions",
+				"No exercise file is attached",
+			},
+		},
+		{
+			name:     "complete PDF exercise",
+			material: &models.PDFExercise{Deadline: &future, FileURL: "exercises/sheet.pdf"},
+		},
+		{
+			name:     "other material types are not linted",
+			material: &models.Document{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Lint(tt.material, tt.testCases); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Lint() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
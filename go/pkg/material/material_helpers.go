@@ -2,11 +2,21 @@ package material
 
 import (
 	"errors"
+	"fmt"
+	"strings"
 
 	models "github.com/Project-DSView/backend/go/internal/domain/entities"
+	"github.com/Project-DSView/backend/go/internal/domain/enums"
 	"gorm.io/gorm"
 )
 
+var (
+	// ErrReferenceMissing is returned by VerifyReference for a material without a reference
+	ErrReferenceMissing = errors.New("reference_id and reference_type are required")
+	// ErrReferenceNotFound is wrapped by VerifyReference when the referenced record does not exist
+	ErrReferenceNotFound = errors.New("not found")
+)
+
 // GetMaterialCreator gets the creator user ID from the actual material table
 func GetMaterialCreator(db *gorm.DB, referenceID, referenceType string) (string, error) {
 	switch referenceType {
@@ -65,36 +75,41 @@ func GetMaterialFileURL(db *gorm.DB, referenceID, referenceType string) (string,
 	}
 }
 
-// VerifyReferenceExists verifies that the referenced material exists in the appropriate table
-func VerifyReferenceExists(db *gorm.DB, referenceID, referenceType string) error {
-	switch referenceType {
-	case "video":
-		var video models.Video
-		if err := db.First(&video, "material_id = ?", referenceID).Error; err != nil {
-			return errors.New("referenced video not found")
-		}
-	case "document":
-		var document models.Document
-		if err := db.First(&document, "material_id = ?", referenceID).Error; err != nil {
-			return errors.New("referenced document not found")
-		}
-	case "code_exercise":
-		var codeExercise models.CodeExercise
-		if err := db.First(&codeExercise, "material_id = ?", referenceID).Error; err != nil {
-			return errors.New("referenced code exercise not found")
-		}
-	case "pdf_exercise":
-		var pdfExercise models.PDFExercise
-		if err := db.First(&pdfExercise, "material_id = ?", referenceID).Error; err != nil {
-			return errors.New("referenced PDF exercise not found")
-		}
-	case "announcement":
-		var announcement models.Announcement
-		if err := db.First(&announcement, "material_id = ?", referenceID).Error; err != nil {
-			return errors.New("referenced announcement not found")
-		}
-	default:
-		return errors.New("invalid reference type")
+// VerifyReference checks a course material's reference before the row is written: the
+// reference_type must be the material's own type, and reference_id must name a record
+// in the table that type implies, belonging to the same course
+func VerifyReference(db *gorm.DB, material *models.CourseMaterial) error {
+	if material.ReferenceID == nil || material.ReferenceType == nil {
+		return ErrReferenceMissing
+	}
+	if err := material.ValidateReferenceType(); err != nil {
+		return err
+	}
+
+	referenceType := *material.ReferenceType
+	var record interface{}
+	switch enums.MaterialType(referenceType) {
+	case enums.MaterialTypeVideo:
+		record = &models.Video{}
+	case enums.MaterialTypeDocument:
+		record = &models.Document{}
+	case enums.MaterialTypeCodeExercise:
+		record = &models.CodeExercise{}
+	case enums.MaterialTypePDFExercise:
+		record = &models.PDFExercise{}
+	case enums.MaterialTypeAnnouncement:
+		record = &models.Announcement{}
+	}
+
+	var courseIDs []string
+	if err := db.Model(record).Where("material_id = ?", *material.ReferenceID).Pluck("course_id", &courseIDs).Error; err != nil {
+		return fmt.Errorf("failed to look up referenced %s: %w", referenceType, err)
+	}
+	if len(courseIDs) == 0 {
+		return fmt.Errorf("referenced %s %w", strings.ReplaceAll(referenceType, "_", " "), ErrReferenceNotFound)
+	}
+	if courseIDs[0] != material.CourseID {
+		return fmt.Errorf("referenced %s belongs to another course", strings.ReplaceAll(referenceType, "_", " "))
 	}
 	return nil
 }
@@ -0,0 +1,113 @@
+package material
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	models "github.com/Project-DSView/backend/go/internal/domain/entities"
+	"github.com/Project-DSView/backend/go/internal/domain/enums"
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// newTestDB opens an SQLite database with a document and a code exercise in course-1.
+// The material tables only get the columns VerifyReference reads.
+func newTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "test.db")), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("open test database: %v", err)
+	}
+	if err := db.AutoMigrate(&models.CourseMaterial{}); err != nil {
+		t.Fatalf("migrate test database: %v", err)
+	}
+	for _, stmt := range []string{
+		"CREATE TABLE documents (material_id TEXT PRIMARY KEY, course_id TEXT)",
+		"CREATE TABLE code_exercises (material_id TEXT PRIMARY KEY, course_id TEXT)",
+		"INSERT INTO documents VALUES ('doc-1', 'course-1')",
+		"INSERT INTO code_exercises VALUES ('code-1', 'course-1')",
+	} {
+		if err := db.Exec(stmt).Error; err != nil {
+			t.Fatalf("%s: %v", stmt, err)
+		}
+	}
+	return db
+}
+
+func TestVerifyReference(t *testing.T) {
+	db := newTestDB(t)
+
+	tests := []struct {
+		name          string
+		courseID      string
+		materialType  enums.MaterialType
+		referenceID   string
+		referenceType string
+		wantErr       string // substring of the error; empty for success
+	}{
+		{"document", "course-1", enums.MaterialTypeDocument, "doc-1", "document", ""},
+		{"code exercise", "course-1", enums.MaterialTypeCodeExercise, "code-1", "code_exercise", ""},
+		{"type differs from the material", "course-1", enums.MaterialTypeDocument, "code-1", "code_exercise", "does not match material type"},
+		{"unknown type", "course-1", enums.MaterialTypeDocument, "doc-1", "spreadsheet", "invalid reference type"},
+		{"record in another table", "course-1", enums.MaterialTypeCodeExercise, "doc-1", "code_exercise", "referenced code exercise not found"},
+		{"missing record", "course-1", enums.MaterialTypeDocument, "doc-2", "document", "referenced document not found"},
+		{"record of another course", "course-2", enums.MaterialTypeDocument, "doc-1", "document", "belongs to another course"},
+		{"no reference", "course-1", enums.MaterialTypeDocument, "", "", "are required"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			material := &models.CourseMaterial{CourseID: tt.courseID, Type: tt.materialType}
+			if tt.referenceID != "" {
+				material.ReferenceID = &tt.referenceID
+				material.ReferenceType = &tt.referenceType
+			}
+
+			err := VerifyReference(db, material)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("err = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// TestCreateMismatchedReference checks that a course material whose reference_type
+// does not match its type is refused on insert, whichever path creates it
+func TestCreateMismatchedReference(t *testing.T) {
+	db := newTestDB(t)
+
+	tests := []struct {
+		name          string
+		referenceType string
+		wantErr       bool
+	}{
+		{"matching type", "code_exercise", false},
+		{"other material type", "document", true},
+		{"unknown type", "spreadsheet", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			referenceID := "code-1"
+			err := db.Create(&models.CourseMaterial{
+				CourseID:      "course-1",
+				Type:          enums.MaterialTypeCodeExercise,
+				ReferenceID:   &referenceID,
+				ReferenceType: &tt.referenceType,
+			}).Error
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, want error: %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -8,7 +8,9 @@ import (
 	"gorm.io/gorm"
 )
 
-// GetMaterialWithDetails retrieves the actual material data from the specific table and combines it with CourseMaterial reference
+// GetMaterialWithDetails retrieves the actual material data from the specific table and combines it with CourseMaterial reference.
+// This is the student-facing projection: code exercises include only their public test cases,
+// plus a test_case_summary counting the scored and example cases without revealing hidden ones.
 func GetMaterialWithDetails(db *gorm.DB, material *models.CourseMaterial) (map[string]interface{}, error) {
 	// If no reference, return basic CourseMaterial data
 	if material.ReferenceID == nil || material.ReferenceType == nil {
@@ -23,14 +25,20 @@ func GetMaterialWithDetails(db *gorm.DB, material *models.CourseMaterial) (map[s
 	switch referenceType {
 	case "code_exercise":
 		var codeExercise models.CodeExercise
-		if err := db.Preload("Creator").Preload("TestCases").First(&codeExercise, "material_id = ?", referenceID).Error; err != nil {
+		if err := db.Preload("Creator").Preload("TestCases", "is_public = ?", true).First(&codeExercise, "material_id = ?", referenceID).Error; err != nil {
 			if errors.Is(err, gorm.ErrRecordNotFound) {
 				// If not found, return basic CourseMaterial data
 				return material.ToJSON(), nil
 			}
 			return nil, fmt.Errorf("failed to get code exercise: %w", err)
 		}
-		return codeExercise.ToJSON(), nil
+		summaries, err := GetTestCaseSummaries(db, []string{codeExercise.MaterialID})
+		if err != nil {
+			return nil, err
+		}
+		result := codeExercise.ToJSON()
+		result["test_case_summary"] = summaries[codeExercise.MaterialID]
+		return result, nil
 
 	case "pdf_exercise":
 		var pdfExercise models.PDFExercise
@@ -78,6 +86,124 @@ func GetMaterialWithDetails(db *gorm.DB, material *models.CourseMaterial) (map[s
 	}
 }
 
+// GetMaterialsWithDetails is the batch form of GetMaterialWithDetails: it runs one query
+// per material type instead of one per material. The result is keyed by the
+// CourseMaterial's MaterialID; materials whose specific row is missing fall back to the
+// basic CourseMaterial data, as in GetMaterialWithDetails.
+func GetMaterialsWithDetails(db *gorm.DB, materials []models.CourseMaterial) (map[string]map[string]interface{}, error) {
+	// Reference IDs per type, and the CourseMaterials pointing at each reference
+	referenceIDs := make(map[string][]string)
+	byReference := make(map[string][]*models.CourseMaterial)
+	details := make(map[string]map[string]interface{}, len(materials))
+
+	for i := range materials {
+		material := &materials[i]
+		details[material.MaterialID] = material.ToJSON()
+		if material.ReferenceID == nil || material.ReferenceType == nil {
+			continue
+		}
+		referenceIDs[*material.ReferenceType] = append(referenceIDs[*material.ReferenceType], *material.ReferenceID)
+		byReference[*material.ReferenceID] = append(byReference[*material.ReferenceID], material)
+	}
+
+	set := func(referenceID string, data map[string]interface{}) {
+		for _, material := range byReference[referenceID] {
+			details[material.MaterialID] = data
+		}
+	}
+
+	for referenceType, ids := range referenceIDs {
+		switch referenceType {
+		case "code_exercise":
+			var rows []models.CodeExercise
+			if err := db.Preload("Creator").Preload("TestCases", "is_public = ?", true).Where("material_id IN ?", ids).Find(&rows).Error; err != nil {
+				return nil, fmt.Errorf("failed to get code exercises: %w", err)
+			}
+			summaries, err := GetTestCaseSummaries(db, ids)
+			if err != nil {
+				return nil, err
+			}
+			for i := range rows {
+				data := rows[i].ToJSON()
+				data["test_case_summary"] = summaries[rows[i].MaterialID]
+				set(rows[i].MaterialID, data)
+			}
+
+		case "pdf_exercise":
+			var rows []models.PDFExercise
+			if err := db.Preload("Creator").Where("material_id IN ?", ids).Find(&rows).Error; err != nil {
+				return nil, fmt.Errorf("failed to get PDF exercises: %w", err)
+			}
+			for i := range rows {
+				set(rows[i].MaterialID, rows[i].ToJSON())
+			}
+
+		case "video":
+			var rows []models.Video
+			if err := db.Preload("Creator").Where("material_id IN ?", ids).Find(&rows).Error; err != nil {
+				return nil, fmt.Errorf("failed to get videos: %w", err)
+			}
+			for i := range rows {
+				set(rows[i].MaterialID, rows[i].ToJSON())
+			}
+
+		case "document":
+			var rows []models.Document
+			if err := db.Preload("Creator").Where("material_id IN ?", ids).Find(&rows).Error; err != nil {
+				return nil, fmt.Errorf("failed to get documents: %w", err)
+			}
+			for i := range rows {
+				set(rows[i].MaterialID, rows[i].ToJSON())
+			}
+
+		case "announcement":
+			var rows []models.Announcement
+			if err := db.Preload("Creator").Where("material_id IN ?", ids).Find(&rows).Error; err != nil {
+				return nil, fmt.Errorf("failed to get announcements: %w", err)
+			}
+			for i := range rows {
+				set(rows[i].MaterialID, rows[i].ToJSON())
+			}
+		}
+	}
+
+	return details, nil
+}
+
+// TestCaseSummary counts a code exercise's test cases. Every test case is scored; the
+// public ones are also shown as examples, and the rest stay hidden.
+type TestCaseSummary struct {
+	Scored   int64 `json:"scored"`
+	Examples int64 `json:"examples"`
+	Hidden   int64 `json:"hidden"`
+}
+
+// GetTestCaseSummaries counts the test cases of each code exercise in one grouped query.
+// Every requested ID is in the result, with zero counts when it has no test cases.
+func GetTestCaseSummaries(db *gorm.DB, materialIDs []string) (map[string]TestCaseSummary, error) {
+	var rows []struct {
+		MaterialID string
+		Scored     int64
+		Examples   int64
+	}
+	if err := db.Model(&models.TestCase{}).
+		Select("material_id, COUNT(*) AS scored, COUNT(*) FILTER (WHERE is_public) AS examples").
+		Where("material_id IN ?", materialIDs).
+		Group("material_id").
+		Scan(&rows).Error; err != nil {
+		return nil, fmt.Errorf("failed to count test cases: %w", err)
+	}
+
+	summaries := make(map[string]TestCaseSummary, len(materialIDs))
+	for _, id := range materialIDs {
+		summaries[id] = TestCaseSummary{}
+	}
+	for _, row := range rows {
+		summaries[row.MaterialID] = TestCaseSummary{Scored: row.Scored, Examples: row.Examples, Hidden: row.Scored - row.Examples}
+	}
+	return summaries, nil
+}
+
 
 
 
@@ -0,0 +1,160 @@
+package material
+
+import (
+	"html"
+	"regexp"
+	"strings"
+)
+
+var (
+	headingPattern     = regexp.MustCompile(`^(#{1,6})\s+(.*)$`)
+	orderedItemPattern = regexp.MustCompile(`^\d+[.)]\s+(.*)$`)
+	boldPattern        = regexp.MustCompile(`\*\*([^*]+)\*\*|__([^_]+)__`)
+	italicPattern      = regexp.MustCompile(`\*([^*]+)\*|\b_([^_]+)_\b`)
+	linkPattern        = regexp.MustCompile(`\[([^\]]+)\]\(([^)\s]+)\)`)
+)
+
+// allowedLinkSchemes lists URL schemes that may appear in rendered links
+var allowedLinkSchemes = []string{"http://", "https://", "mailto:"}
+
+// RenderStatement converts teacher-provided markdown into sanitized HTML.
+// All raw HTML in the source is escaped, so the output only ever contains the
+// tags emitted by the renderer itself: h1-h6, p, br, strong, em, code, pre,
+// ul, ol, li and a (http, https and mailto links only).
+func RenderStatement(src string) string {
+	if strings.TrimSpace(src) == "" {
+		return ""
+	}
+
+	lines := strings.Split(strings.ReplaceAll(src, "\r\n", "\n"), "\n")
+
+	var out strings.Builder
+	var paragraph []string
+	listTag := ""
+	inCode := false
+
+	flushParagraph := func() {
+		if len(paragraph) == 0 {
+			return
+		}
+		rendered := make([]string, len(paragraph))
+		for i, line := range paragraph {
+			rendered[i] = renderInline(line)
+		}
+		out.WriteString("<p>" + strings.Join(rendered, "<br>") + "</p>\n")
+		paragraph = nil
+	}
+	closeList := func() {
+		if listTag != "" {
+			out.WriteString("</" + listTag + ">\n")
+			listTag = ""
+		}
+	}
+	openList := func(tag string) {
+		if listTag != tag {
+			closeList()
+			out.WriteString("<" + tag + ">\n")
+			listTag = tag
+		}
+	}
+
+	for _, line := range lines {
+		trimmed := strings.TrimSpace(line)
+
+		// Fenced code blocks are emitted verbatim (escaped)
+		if strings.HasPrefix(trimmed, "```") {
+			if inCode {
+				out.WriteString("</code></pre>\n")
+				inCode = false
+			} else {
+				flushParagraph()
+				closeList()
+				out.WriteString("<pre><code>")
+				inCode = true
+			}
+			continue
+		}
+		if inCode {
+			out.WriteString(html.EscapeString(line) + "\n")
+			continue
+		}
+
+		switch {
+		case trimmed == "":
+			flushParagraph()
+			closeList()
+		case headingPattern.MatchString(trimmed):
+			flushParagraph()
+			closeList()
+			m := headingPattern.FindStringSubmatch(trimmed)
+			level := string(rune('0' + len(m[1])))
+			out.WriteString("<h" + level + ">" + renderInline(m[2]) + "</h" + level + ">\n")
+		case strings.HasPrefix(trimmed, "- ") || strings.HasPrefix(trimmed, "* "):
+			flushParagraph()
+			openList("ul")
+			out.WriteString("<li>" + renderInline(strings.TrimSpace(trimmed[2:])) + "</li>\n")
+		case orderedItemPattern.MatchString(trimmed):
+			flushParagraph()
+			openList("ol")
+			m := orderedItemPattern.FindStringSubmatch(trimmed)
+			out.WriteString("<li>" + renderInline(m[1]) + "</li>\n")
+		default:
+			closeList()
+			paragraph = append(paragraph, trimmed)
+		}
+	}
+
+	if inCode {
+		out.WriteString("</code></pre>\n")
+	}
+	flushParagraph()
+	closeList()
+
+	return strings.TrimRight(out.String(), "\n")
+}
+
+// renderInline escapes a single line and applies inline markdown formatting.
+// Text inside backticks is left unformatted.
+func renderInline(text string) string {
+	segments := strings.Split(text, "`")
+	var out strings.Builder
+	for i, segment := range segments {
+		escaped := html.EscapeString(segment)
+		// Odd segments sit between a pair of backticks; an unmatched trailing
+		// backtick is rendered literally
+		if i%2 == 1 && i < len(segments)-1 {
+			out.WriteString("<code>" + escaped + "</code>")
+			continue
+		}
+		if i%2 == 1 {
+			out.WriteString("`")
+		}
+		out.WriteString(formatInline(escaped))
+	}
+	return out.String()
+}
+
+// formatInline applies links, bold and italic to already-escaped text
+func formatInline(escaped string) string {
+	escaped = linkPattern.ReplaceAllStringFunc(escaped, func(match string) string {
+		m := linkPattern.FindStringSubmatch(match)
+		if !isAllowedLink(html.UnescapeString(m[2])) {
+			return m[1]
+		}
+		return `<a href="` + m[2] + `" rel="noopener noreferrer nofollow" target="_blank">` + m[1] + `</a>`
+	})
+	escaped = boldPattern.ReplaceAllString(escaped, "<strong>$1$2</strong>")
+	escaped = italicPattern.ReplaceAllString(escaped, "<em>$1$2</em>")
+	return escaped
+}
+
+// isAllowedLink reports whether a link target uses an allowlisted scheme
+func isAllowedLink(target string) bool {
+	lower := strings.ToLower(strings.TrimSpace(target))
+	for _, scheme := range allowedLinkSchemes {
+		if strings.HasPrefix(lower, scheme) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,47 @@
+package material
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// Limits on the tags of one material
+const (
+	MaxTags      = 20
+	MaxTagLength = 50
+)
+
+// ErrInvalidTags is returned by NormalizeTags when the tags exceed the limits
+var ErrInvalidTags = errors.New("invalid tags")
+
+// NormalizeTags trims and lowercases tags, drops empty ones and duplicates, and keeps
+// the first-seen order, so "Recursion" and " recursion " are the same tag
+func NormalizeTags(tags []string) ([]string, error) {
+	normalized := make([]string, 0, len(tags))
+	seen := make(map[string]bool, len(tags))
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" || seen[tag] {
+			continue
+		}
+		if utf8.RuneCountInString(tag) > MaxTagLength {
+			return nil, fmt.Errorf("%w: tag %q is longer than %d characters", ErrInvalidTags, tag, MaxTagLength)
+		}
+		seen[tag] = true
+		normalized = append(normalized, tag)
+	}
+	if len(normalized) > MaxTags {
+		return nil, fmt.Errorf("%w: a material can have at most %d tags", ErrInvalidTags, MaxTags)
+	}
+	return normalized, nil
+}
+
+// SplitTags splits a comma-separated tag list, as sent in form fields and query strings
+func SplitTags(value string) []string {
+	if strings.TrimSpace(value) == "" {
+		return nil
+	}
+	return strings.Split(value, ",")
+}
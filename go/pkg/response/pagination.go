@@ -0,0 +1,57 @@
+package response
+
+import (
+	"github.com/Project-DSView/backend/go/pkg/apiversion"
+	"github.com/gofiber/fiber/v2"
+)
+
+// maxPageLimit caps the page size of listings that do not set their own maximum
+var maxPageLimit = 100
+
+// SetMaxPageLimit sets the largest page size ParsePagination allows when an endpoint
+// passes no maximum of its own
+func SetMaxPageLimit(limit int) {
+	if limit > 0 {
+		maxPageLimit = limit
+	}
+}
+
+// ParsePagination reads the page and limit query parameters of a paged listing. Page
+// defaults to 1; limit defaults to defaultLimit and is capped at maxLimit, or at the
+// configured maximum when maxLimit is 0. Missing, malformed, or non-positive values
+// fall back to the defaults.
+func ParsePagination(c *fiber.Ctx, defaultLimit, maxLimit int) (page, limit int) {
+	if maxLimit <= 0 {
+		maxLimit = maxPageLimit
+	}
+	if defaultLimit > maxLimit {
+		defaultLimit = maxLimit
+	}
+
+	page = c.QueryInt("page", 1)
+	if page < 1 {
+		page = 1
+	}
+	limit = c.QueryInt("limit", defaultLimit)
+	if limit < 1 {
+		limit = defaultLimit
+	}
+	if limit > maxLimit {
+		limit = maxLimit
+	}
+	return page, limit
+}
+
+// Pagination builds the pagination envelope of a paged list response in the shape of
+// the API version negotiated for the request
+func Pagination(c *fiber.Ctx, page, limit, total int) fiber.Map {
+	switch apiversion.FromContext(c) {
+	default: // apiversion.V1
+		return fiber.Map{
+			"page":        page,
+			"limit":       limit,
+			"total":       total,
+			"total_pages": (total + limit - 1) / limit,
+		}
+	}
+}
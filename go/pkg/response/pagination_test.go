@@ -0,0 +1,69 @@
+package response
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestParsePagination(t *testing.T) {
+	tests := []struct {
+		name         string
+		query        string
+		defaultLimit int
+		maxLimit     int
+		wantPage     int
+		wantLimit    int
+	}{
+		{"defaults", "", 20, 50, 1, 20},
+		{"requested page and limit", "?page=3&limit=10", 20, 50, 3, 10},
+		{"limit capped at the endpoint maximum", "?limit=500", 20, 50, 1, 50},
+		{"limit capped at the configured maximum", "?limit=500", 20, 0, 1, 100},
+		{"default above the maximum", "", 80, 50, 1, 50},
+		{"zero page and limit", "?page=0&limit=0", 20, 50, 1, 20},
+		{"negative page and limit", "?page=-2&limit=-5", 20, 50, 1, 20},
+		{"malformed values", "?page=two&limit=ten", 20, 50, 1, 20},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var page, limit int
+			app := fiber.New()
+			app.Get("/", func(c *fiber.Ctx) error {
+				page, limit = ParsePagination(c, tt.defaultLimit, tt.maxLimit)
+				return c.SendStatus(fiber.StatusOK)
+			})
+
+			if _, err := app.Test(httptest.NewRequest(http.MethodGet, "/"+tt.query, nil)); err != nil {
+				t.Fatalf("request failed: %v", err)
+			}
+			if page != tt.wantPage || limit != tt.wantLimit {
+				t.Errorf("page, limit = %d, %d, want %d, %d", page, limit, tt.wantPage, tt.wantLimit)
+			}
+		})
+	}
+}
+
+func TestSetMaxPageLimit(t *testing.T) {
+	defer func(limit int) { maxPageLimit = limit }(maxPageLimit)
+
+	tests := []struct {
+		name  string
+		limit int
+		want  int
+	}{
+		{"raised", 250, 250},
+		{"lowered", 30, 30},
+		{"zero ignored", 0, 30},
+		{"negative ignored", -1, 30},
+	}
+
+	for _, tt := range tests {
+		SetMaxPageLimit(tt.limit)
+		if maxPageLimit != tt.want {
+			t.Errorf("%s: max page limit = %d, want %d", tt.name, maxPageLimit, tt.want)
+		}
+	}
+}
@@ -138,6 +138,18 @@ func ErrorResponse(c *fiber.Ctx, statusCode int, message string, errorDetails in
 	})
 }
 
+// ValidationErrorResponse sends a 400 for a failed request validation. The per-field
+// messages go in data.fields so clients can highlight the offending inputs; error keeps
+// the raw validator message.
+func ValidationErrorResponse(c *fiber.Ctx, err error, fields map[string]string) error {
+	return c.Status(fiber.StatusBadRequest).JSON(StandardResponse{
+		Success: false,
+		Message: "Validation failed",
+		Data:    fiber.Map{"fields": fields},
+		Error:   err.Error(),
+	})
+}
+
 // SuccessResponse sends a success response with status code, message and data
 func SuccessResponse(c *fiber.Ctx, statusCode int, message string, data interface{}) error {
 	return c.Status(statusCode).JSON(StandardResponse{
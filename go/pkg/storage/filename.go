@@ -0,0 +1,118 @@
+package storage
+
+import (
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// DefaultMaxFilenameLength bounds sanitized filenames, in bytes, when no limit is configured
+const DefaultMaxFilenameLength = 128
+
+// maxExtensionLength is the longest extension kept; anything longer stays part of the name
+const maxExtensionLength = 16
+
+// SanitizeFilename returns the client-supplied filename made safe for object keys,
+// metadata and download headers (see sanitizeFilename)
+func (u *uploader) SanitizeFilename(filename string) string {
+	return sanitizeFilename(filename, u.maxFilenameLength)
+}
+
+// sanitizeFilename keeps only the last path element of name and replaces everything but
+// letters, digits, '.', '-' and '_' with '_'. Invisible format characters (bidi overrides,
+// zero-width spaces) are dropped, ".." and leading dots are removed, and the result is cut
+// to maxLength bytes with the lower-cased extension preserved. An unusable name becomes "file".
+func sanitizeFilename(name string, maxLength int) string {
+	if maxLength <= 0 {
+		maxLength = DefaultMaxFilenameLength
+	}
+
+	// Clients send either separator regardless of the server's OS
+	if i := strings.LastIndexAny(name, `/\`); i >= 0 {
+		name = name[i+1:]
+	}
+	name = strings.ToValidUTF8(name, "_")
+
+	var b strings.Builder
+	for _, r := range name {
+		switch {
+		case r == '.' || r == '-' || r == '_':
+			b.WriteRune(r)
+		case unicode.Is(unicode.Cf, r):
+			// Invisible, and used to disguise the real extension
+		case unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.Is(unicode.Mn, r):
+			b.WriteRune(r)
+		default:
+			b.WriteRune('_')
+		}
+	}
+	cleaned := b.String()
+	for strings.Contains(cleaned, "..") {
+		cleaned = strings.ReplaceAll(cleaned, "..", ".")
+	}
+	for strings.Contains(cleaned, "__") {
+		cleaned = strings.ReplaceAll(cleaned, "__", "_")
+	}
+
+	ext := filepath.Ext(cleaned)
+	stem := strings.TrimSuffix(cleaned, ext)
+	if !isSafeExtension(ext) {
+		stem, ext = cleaned, ""
+	}
+	ext = strings.ToLower(ext)
+
+	stem = strings.Trim(stem, "._-")
+	if stem == "" {
+		stem = "file"
+	}
+	if budget := maxLength - len(ext); budget < 1 {
+		ext = ""
+		stem = truncateUTF8(stem, maxLength)
+	} else {
+		stem = truncateUTF8(stem, budget)
+	}
+	return stem + ext
+}
+
+// isSafeExtension reports whether ext is a '.' followed by 1-15 ASCII letters or digits
+func isSafeExtension(ext string) bool {
+	if len(ext) < 2 || len(ext) > maxExtensionLength {
+		return false
+	}
+	for _, r := range ext[1:] {
+		if r > unicode.MaxASCII || !(unicode.IsLetter(r) || unicode.IsDigit(r)) {
+			return false
+		}
+	}
+	return true
+}
+
+// truncateUTF8 cuts s to at most n bytes without splitting a character
+func truncateUTF8(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n]
+}
+
+// ContentDisposition builds a Content-Disposition header ("attachment" or "inline") for
+// a sanitized filename. Non-ASCII names are sent in filename* (RFC 6266) with an ASCII
+// fallback in filename for older clients.
+func ContentDisposition(disposition, filename string) string {
+	fallback := strings.Map(func(r rune) rune {
+		if r > unicode.MaxASCII || r == '"' || r == '\\' || unicode.IsControl(r) {
+			return '_'
+		}
+		return r
+	}, filename)
+	if fallback == filename {
+		return fmt.Sprintf(`%s; filename="%s"`, disposition, filename)
+	}
+	return fmt.Sprintf(`%s; filename="%s"; filename*=UTF-8''%s`, disposition, fallback, url.PathEscape(filename))
+}
@@ -0,0 +1,66 @@
+package storage
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestSanitizeFilename(t *testing.T) {
+	tests := []struct {
+		name      string
+		input     string
+		maxLength int
+		want      string
+	}{
+		{name: "plain name", input: "report.pdf", want: "report.pdf"},
+		{name: "parent directories", input: "../../etc/passwd", want: "passwd"},
+		{name: "backslash path", input: `..\..\windows\system32\evil.exe`, want: "evil.exe"},
+		{name: "mixed separators", input: `uploads/..\lab1.py`, want: "lab1.py"},
+		{name: "trailing separator", input: "a/b/../", want: "file"},
+		{name: "only dots", input: "...", want: "file"},
+		{name: "dot dot inside name", input: "a..b.txt", want: "a.b.txt"},
+		{name: "leading dot", input: ".env", want: "file.env"},
+		{name: "right-to-left override", input: "invoice\u202efdp.exe", want: "invoicefdp.exe"},
+		{name: "zero-width space", input: "re\u200bport.PDF", want: "report.pdf"},
+		{name: "byte order mark", input: "\ufeffnotes.txt", want: "notes.txt"},
+		{name: "spaces and punctuation", input: "my report (final).PDF", want: "my_report_final.pdf"},
+		{name: "control characters", input: "a\x00b\r\n.txt", want: "a_b.txt"},
+		{name: "invalid utf-8", input: "bad\xff\xfename.txt", want: "bad_name.txt"},
+		{name: "thai letters and marks", input: "รายงาน.pdf", want: "รายงาน.pdf"},
+		{name: "double extension keeps the last", input: "photo.jpg.exe", want: "photo.jpg.exe"},
+		{name: "too long extension stays in the name", input: "archive.abcdefghijklmnopq", want: "archive.abcdefghijklmnopq"},
+		{name: "non-ascii extension stays in the name", input: "file.pdfé", want: "file.pdfé"},
+		{
+			name:  "over-long name keeps the extension",
+			input: strings.Repeat("a", 200) + ".pdf",
+			want:  strings.Repeat("a", DefaultMaxFilenameLength-4) + ".pdf",
+		},
+		{
+			name:      "over-long multi-byte name is cut between characters",
+			input:     strings.Repeat("é", 100) + ".txt",
+			maxLength: 21,
+			want:      strings.Repeat("é", 8) + ".txt",
+		},
+		{name: "limit shorter than the extension drops it", input: "abc.pdf", maxLength: 3, want: "abc"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := sanitizeFilename(tt.input, tt.maxLength)
+			if got != tt.want {
+				t.Errorf("sanitizeFilename(%q, %d) = %q, want %q", tt.input, tt.maxLength, got, tt.want)
+			}
+			maxLength := tt.maxLength
+			if maxLength <= 0 {
+				maxLength = DefaultMaxFilenameLength
+			}
+			if len(got) > maxLength {
+				t.Errorf("sanitizeFilename(%q, %d) is %d bytes, limit %d", tt.input, tt.maxLength, len(got), maxLength)
+			}
+			if !utf8.ValidString(got) || strings.ContainsAny(got, `/\`) || strings.Contains(got, "..") {
+				t.Errorf("sanitizeFilename(%q, %d) = %q is not a safe filename", tt.input, tt.maxLength, got)
+			}
+		})
+	}
+}
@@ -44,11 +44,22 @@ type StorageService interface {
 	GetFileInfo(ctx context.Context, url string) (interface{}, error)
 	GeneratePresignedUploadURL(ctx context.Context, key string, contentType string, expiration time.Duration) (string, error)
 	GetFileURL(key string) string
+	// OpenObject opens an object by key for streaming; the caller closes the reader
+	OpenObject(ctx context.Context, key string) (io.ReadCloser, int64, error)
+	CopyFileToCourse(ctx context.Context, srcURL, courseID string) (string, error)
 
 	// Utility operations
 	HealthCheck(ctx context.Context) error
 	ValidateFileSize(size int64) error
+	// SanitizeFilename makes a client-supplied filename safe to store and to send in headers
+	SanitizeFilename(filename string) string
 }
 
 // StorageInterface is an alias for StorageService for backward compatibility
 type StorageInterface = StorageService
+
+// Both backends must implement the full interface
+var (
+	_ StorageService = (*MinIOService)(nil)
+	_ StorageService = (*LocalStorageService)(nil)
+)
@@ -0,0 +1,369 @@
+package storage
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Project-DSView/backend/go/pkg/logger"
+)
+
+// Errors returned by LocalStorageService.Authorize
+var (
+	ErrInvalidSignature = errors.New("invalid or missing signature")
+	ErrURLExpired       = errors.New("signed URL has expired")
+)
+
+// LocalConfig configures the filesystem storage backend used for local development
+type LocalConfig struct {
+	RootDir          string // Directory the objects are stored under
+	BaseURL          string // URL the files route is served at, e.g. http://localhost:8080/files
+	SigningKey       string // HMAC key for signed (presigned) URLs
+	MaxFileSizeBytes int64
+	PublicRead       bool // Serve unsigned GET requests, like a public MinIO bucket
+	// Longest filename kept after sanitizing, in bytes; 0 uses DefaultMaxFilenameLength
+	MaxFilenameLength int
+}
+
+// LocalStorageService stores objects as files below a local directory, using the same
+// keys as MinIO. Files are served by the files route (see Authorize and Open); presigned
+// URLs become URLs on that route signed with an HMAC and an expiry.
+// Object metadata is not persisted; content types are derived from the file extension.
+type LocalStorageService struct {
+	uploader
+	config *LocalConfig
+}
+
+// LocalFileInfo is returned by LocalStorageService.GetFileInfo
+type LocalFileInfo struct {
+	Key          string    `json:"key"`
+	Size         int64     `json:"size"`
+	ContentType  string    `json:"content_type"`
+	LastModified time.Time `json:"last_modified"`
+}
+
+func NewLocalStorageService(cfg *LocalConfig) (*LocalStorageService, error) {
+	if cfg.SigningKey == "" {
+		return nil, fmt.Errorf("local storage requires a signing key")
+	}
+
+	root, err := filepath.Abs(cfg.RootDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve storage directory %s: %w", cfg.RootDir, err)
+	}
+	if err := os.MkdirAll(root, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create storage directory %s: %w", root, err)
+	}
+
+	localConfig := *cfg
+	localConfig.RootDir = root
+	localConfig.BaseURL = strings.TrimSuffix(cfg.BaseURL, "/")
+
+	service := &LocalStorageService{config: &localConfig}
+	service.uploader = uploader{store: service, maxFilenameLength: cfg.MaxFilenameLength}
+
+	logger.Infof("Using local filesystem storage at %s (served at %s)", root, localConfig.BaseURL)
+	return service, nil
+}
+
+// RoutePath returns the path of the files route, taken from BaseURL
+func (l *LocalStorageService) RoutePath() string {
+	parsed, err := url.Parse(l.config.BaseURL)
+	if err != nil || parsed.Path == "" {
+		return "/files"
+	}
+	return parsed.Path
+}
+
+// filePath maps an object key to its path below RootDir, rejecting keys that escape it
+func (l *LocalStorageService) filePath(key string) (string, error) {
+	cleaned := filepath.Clean(filepath.FromSlash(strings.TrimPrefix(key, "/")))
+	if cleaned == "." || cleaned == ".." || strings.HasPrefix(cleaned, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("invalid object key: %s", key)
+	}
+	return filepath.Join(l.config.RootDir, cleaned), nil
+}
+
+// keyFromURL extracts the object key from a URL returned by objectURL or a signed URL
+func (l *LocalStorageService) keyFromURL(fileURL string) (string, error) {
+	rawPath, _, _ := strings.Cut(fileURL, "?")
+	if !strings.HasPrefix(rawPath, l.config.BaseURL+"/") {
+		return "", fmt.Errorf("invalid local storage URL format: %s", fileURL)
+	}
+	key, err := url.PathUnescape(strings.TrimPrefix(rawPath, l.config.BaseURL+"/"))
+	if err != nil {
+		return "", fmt.Errorf("invalid local storage URL format: %s", fileURL)
+	}
+	return key, nil
+}
+
+func (l *LocalStorageService) putObject(_ context.Context, key string, file io.Reader, _ string, _ map[string]string) error {
+	path, err := l.filePath(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	// Write to a temporary file first so readers never see a partial object
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".upload-*")
+	if err != nil {
+		return fmt.Errorf("failed to create file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(tmp, file); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+func (l *LocalStorageService) removePrefix(_ context.Context, prefix string) {
+	// Walk the deepest directory that contains every key with this prefix
+	dirKey := prefix
+	if !strings.HasSuffix(dirKey, "/") {
+		dirKey = filepath.ToSlash(filepath.Dir(dirKey))
+	}
+	dir, err := l.filePath(dirKey)
+	if err != nil {
+		logger.Errorf("Error listing files under %s: %v", prefix, err)
+		return
+	}
+
+	err = filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(l.config.RootDir, path)
+		if err != nil || !strings.HasPrefix(filepath.ToSlash(rel), prefix) {
+			return err
+		}
+		if err := os.Remove(path); err != nil {
+			logger.Errorf("Error deleting file %s: %v", rel, err)
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		logger.Errorf("Error listing files under %s: %v", prefix, err)
+	}
+}
+
+func (l *LocalStorageService) objectURL(key string) string {
+	segments := strings.Split(key, "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	return l.config.BaseURL + "/" + strings.Join(segments, "/")
+}
+
+// signature signs method, key and expiry with the configured key
+func (l *LocalStorageService) signature(method, key string, expires int64) string {
+	mac := hmac.New(sha256.New, []byte(l.config.SigningKey))
+	fmt.Fprintf(mac, "%s\n%s\n%d", method, key, expires)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// signedURL returns the object URL with an expiry and a signature for method
+func (l *LocalStorageService) signedURL(method, key string, expiration time.Duration) string {
+	expires := time.Now().Add(expiration).Unix()
+	return fmt.Sprintf("%s?expires=%d&signature=%s", l.objectURL(key), expires, l.signature(method, key, expires))
+}
+
+// Authorize checks a request to the files route. Writes always need a valid signature;
+// unsigned reads are allowed only when PublicRead is set.
+func (l *LocalStorageService) Authorize(method, key, expires, signature string) error {
+	if signature == "" {
+		if method == http.MethodGet && l.config.PublicRead {
+			return nil
+		}
+		return ErrInvalidSignature
+	}
+
+	expiresAt, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil {
+		return ErrInvalidSignature
+	}
+	if !hmac.Equal([]byte(signature), []byte(l.signature(method, key, expiresAt))) {
+		return ErrInvalidSignature
+	}
+	if time.Now().Unix() > expiresAt {
+		return ErrURLExpired
+	}
+	return nil
+}
+
+// Open opens the object stored at key for reading and returns its content type and size
+func (l *LocalStorageService) Open(key string) (*os.File, string, int64, error) {
+	path, err := l.filePath(key)
+	if err != nil {
+		return nil, "", 0, err
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, "", 0, err
+	}
+	info, err := file.Stat()
+	if err != nil || info.IsDir() {
+		file.Close()
+		return nil, "", 0, fs.ErrNotExist
+	}
+	return file, contentTypeFor(key), info.Size(), nil
+}
+
+// contentTypeFor derives a content type from the key's file extension
+func contentTypeFor(key string) string {
+	if contentType := mime.TypeByExtension(filepath.Ext(key)); contentType != "" {
+		return contentType
+	}
+	return "application/octet-stream"
+}
+
+// DeleteFile deletes a file; deleting a missing file is not an error, as with MinIO
+func (l *LocalStorageService) DeleteFile(_ context.Context, fileURL string) error {
+	key, err := l.keyFromURL(fileURL)
+	if err != nil {
+		return err
+	}
+	path, err := l.filePath(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("failed to delete file: %w", err)
+	}
+	return nil
+}
+
+// GetFileInfo returns a *LocalFileInfo for the file at fileURL
+func (l *LocalStorageService) GetFileInfo(_ context.Context, fileURL string) (interface{}, error) {
+	key, err := l.keyFromURL(fileURL)
+	if err != nil {
+		return nil, err
+	}
+	path, err := l.filePath(key)
+	if err != nil {
+		return nil, err
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get file info: %w", err)
+	}
+	return &LocalFileInfo{
+		Key:          key,
+		Size:         info.Size(),
+		ContentType:  contentTypeFor(key),
+		LastModified: info.ModTime(),
+	}, nil
+}
+
+// GeneratePresignedUploadURL returns a signed URL accepting a PUT of the file body
+func (l *LocalStorageService) GeneratePresignedUploadURL(_ context.Context, key string, _ string, expiration time.Duration) (string, error) {
+	if _, err := l.filePath(key); err != nil {
+		return "", err
+	}
+	return l.signedURL(http.MethodPut, key, expiration), nil
+}
+
+func (l *LocalStorageService) GetFileURL(key string) string {
+	return l.objectURL(key)
+}
+
+// CopyFileToCourse copies a file into another course's folder, keeping the path below
+// the source course folder (see MinIOService.CopyFileToCourse)
+func (l *LocalStorageService) CopyFileToCourse(ctx context.Context, srcURL, courseID string) (string, error) {
+	srcKey, err := l.keyFromURL(srcURL)
+	if err != nil {
+		return "", err
+	}
+
+	relative := srcKey
+	if idx := strings.Index(srcKey, "/"); idx >= 0 {
+		relative = srcKey[idx+1:]
+	}
+	dstKey := buildCoursePath(courseID) + relative
+
+	src, _, _, err := l.Open(srcKey)
+	if err != nil {
+		return "", fmt.Errorf("failed to open file %s: %w", srcKey, err)
+	}
+	defer src.Close()
+
+	if err := l.putObject(ctx, dstKey, src, "", nil); err != nil {
+		return "", fmt.Errorf("failed to copy file %s: %w", srcKey, err)
+	}
+	return l.objectURL(dstKey), nil
+}
+
+// DownloadStudentPDFSubmission returns a signed download URL for the file
+func (l *LocalStorageService) DownloadStudentPDFSubmission(_ context.Context, fileURL string, expiration time.Duration) (string, error) {
+	key, err := l.keyFromURL(fileURL)
+	if err != nil {
+		return "", err
+	}
+	path, err := l.filePath(key)
+	if err != nil {
+		return "", err
+	}
+	if _, err := os.Stat(path); err != nil {
+		return "", fmt.Errorf("failed to generate download URL: %w", err)
+	}
+	return l.signedURL(http.MethodGet, key, expiration), nil
+}
+
+// StreamStudentPDFSubmission opens the file for streaming; the reader is an *os.File
+func (l *LocalStorageService) StreamStudentPDFSubmission(_ context.Context, fileURL string) (io.Reader, string, int64, error) {
+	key, err := l.keyFromURL(fileURL)
+	if err != nil {
+		return nil, "", 0, err
+	}
+	file, contentType, size, err := l.Open(key)
+	if err != nil {
+		return nil, "", 0, fmt.Errorf("failed to open file: %w", err)
+	}
+	return file, contentType, size, nil
+}
+
+// OpenObject opens the file stored at key; the reader is an *os.File
+func (l *LocalStorageService) OpenObject(_ context.Context, key string) (io.ReadCloser, int64, error) {
+	file, _, size, err := l.Open(key)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to open file: %w", err)
+	}
+	return file, size, nil
+}
+
+// HealthCheck verifies that the storage directory exists and is writable
+func (l *LocalStorageService) HealthCheck(_ context.Context) error {
+	tmp, err := os.CreateTemp(l.config.RootDir, ".health-*")
+	if err != nil {
+		return fmt.Errorf("storage directory %s is not writable: %w", l.config.RootDir, err)
+	}
+	tmp.Close()
+	return os.Remove(tmp.Name())
+}
+
+func (l *LocalStorageService) ValidateFileSize(size int64) error {
+	if size > l.config.MaxFileSizeBytes {
+		return fmt.Errorf("file size %d bytes exceeds maximum allowed size %d bytes",
+			size, l.config.MaxFileSizeBytes)
+	}
+	return nil
+}
@@ -3,12 +3,62 @@ package storage
 import (
 	"context"
 	"fmt"
+	"io"
 	"strings"
 	"time"
 
+	"github.com/Project-DSView/backend/go/pkg/logger"
 	"github.com/minio/minio-go/v7"
 )
 
+// putObject uploads an object to the bucket with metadata stored as user metadata
+func (m *MinIOService) putObject(ctx context.Context, key string, file io.Reader, contentType string, metadata map[string]string) error {
+	_, err := m.client.PutObject(ctx, m.config.BucketName, key, file, -1, minio.PutObjectOptions{
+		ContentType:  contentType,
+		UserMetadata: metadata,
+	})
+	return err
+}
+
+// removePrefix deletes all objects whose key starts with prefix
+func (m *MinIOService) removePrefix(ctx context.Context, prefix string) {
+	objectCh := m.client.ListObjects(ctx, m.config.BucketName, minio.ListObjectsOptions{
+		Prefix:    prefix,
+		Recursive: true,
+	})
+
+	for object := range objectCh {
+		if object.Err != nil {
+			logger.Errorf("Error listing object: %v", object.Err)
+			continue
+		}
+
+		if err := m.client.RemoveObject(ctx, m.config.BucketName, object.Key, minio.RemoveObjectOptions{}); err != nil {
+			logger.Errorf("Error deleting object %s: %v", object.Key, err)
+		}
+	}
+}
+
+// objectURL returns the public URL of an object
+func (m *MinIOService) objectURL(key string) string {
+	return m.GetFileURL(key)
+}
+
+// OpenObject opens an object by key; the size comes from a stat, which also reports a
+// missing object up front rather than on the first read
+func (m *MinIOService) OpenObject(ctx context.Context, key string) (io.ReadCloser, int64, error) {
+	obj, err := m.client.GetObject(ctx, m.config.BucketName, key, minio.GetObjectOptions{})
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to get object: %w", err)
+	}
+	stat, err := obj.Stat()
+	if err != nil {
+		obj.Close()
+		return nil, 0, fmt.Errorf("failed to stat object: %w", err)
+	}
+	return obj, stat.Size, nil
+}
+
 // DeleteFile deletes a file from MinIO storage
 func (m *MinIOService) DeleteFile(ctx context.Context, url string) error {
 	// Extract key from URL
@@ -61,3 +111,40 @@ func (m *MinIOService) GeneratePresignedUploadURL(ctx context.Context, key strin
 func (m *MinIOService) GetFileURL(key string) string {
 	return fmt.Sprintf("http://%s/%s/%s", m.config.Endpoint, m.config.BucketName, key)
 }
+
+// CopyFileToCourse copies an existing object into another course's folder and returns the new URL.
+// The path below the source course folder is preserved, so copies keep their material layout.
+func (m *MinIOService) CopyFileToCourse(ctx context.Context, srcURL, courseID string) (string, error) {
+	srcKey, err := m.objectKeyFromURL(srcURL)
+	if err != nil {
+		return "", err
+	}
+
+	// Drop the source course folder ({shortID}/) and re-root under the target course
+	relative := srcKey
+	if idx := strings.Index(srcKey, "/"); idx >= 0 {
+		relative = srcKey[idx+1:]
+	}
+	dstKey := buildCoursePath(courseID) + relative
+
+	_, err = m.client.CopyObject(ctx,
+		minio.CopyDestOptions{Bucket: m.config.BucketName, Object: dstKey},
+		minio.CopySrcOptions{Bucket: m.config.BucketName, Object: srcKey},
+	)
+	if err != nil {
+		return "", fmt.Errorf("failed to copy MinIO object %s: %w", srcKey, err)
+	}
+
+	return m.GetFileURL(dstKey), nil
+}
+
+// objectKeyFromURL extracts the object key (everything after the bucket name) from a MinIO URL
+func (m *MinIOService) objectKeyFromURL(url string) (string, error) {
+	parts := strings.Split(url, "/")
+	for i, part := range parts {
+		if part == m.config.BucketName && i < len(parts)-1 {
+			return strings.Join(parts[i+1:], "/"), nil
+		}
+	}
+	return "", fmt.Errorf("invalid MinIO URL format: %s", url)
+}
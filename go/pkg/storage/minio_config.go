@@ -19,9 +19,12 @@ type MinIOConfig struct {
 	MaxFileSizeBytes int64
 	UseSSL           bool
 	PublicBucket     bool
+	// Longest filename kept after sanitizing, in bytes; 0 uses DefaultMaxFilenameLength
+	MaxFilenameLength int
 }
 
 type MinIOService struct {
+	uploader
 	client *minio.Client
 	config *MinIOConfig
 }
@@ -69,10 +72,12 @@ func NewMinIOService(cfg *MinIOConfig) (*MinIOService, error) {
 		logger.Warnf("Failed to apply lifecycle policy to bucket %s: %v", cfg.BucketName, err)
 	}
 
-	return &MinIOService{
+	service := &MinIOService{
 		client: minioClient,
 		config: cfg,
-	}, nil
+	}
+	service.uploader = uploader{store: service, maxFilenameLength: cfg.MaxFilenameLength}
+	return service, nil
 }
 
 // applyPublicReadPolicy applies a public read policy to the bucket
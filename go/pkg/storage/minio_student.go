@@ -11,128 +11,6 @@ import (
 	"github.com/minio/minio-go/v7"
 )
 
-// UploadStudentPDFSubmission uploads a PDF file submitted by a student for an exercise
-func (m *MinIOService) UploadStudentPDFSubmission(ctx context.Context, courseID, courseName string, week int, userEmail string, file io.Reader, filename, contentType string) (string, error) {
-	// Validate file type - only allow PDF files
-	if contentType != "application/pdf" {
-		return "", fmt.Errorf("invalid file type: %s. Only PDF files are allowed", contentType)
-	}
-
-	// Build new path structure: {courseID-8}/exercise/{emailPrefix}/pdf/week-{N}/submission_timestamp.pdf
-	coursePath := m.buildCoursePath(courseID)
-	emailPrefix := m.buildStudentEmailPrefix(userEmail)
-	weekPath := m.buildWeekPath(week)
-	timestampFilename := m.buildTimestampFilename(filename)
-
-	key := coursePath + "exercise/" + emailPrefix + "/pdf/" + weekPath + timestampFilename
-
-	// Debug: log the key being used
-
-	// Delete existing files in the same week directory to avoid duplicates
-	weekDir := coursePath + "exercise/" + emailPrefix + "/pdf/" + weekPath
-	m.deleteFilesInDirectory(ctx, weekDir)
-
-	// Upload to MinIO
-	_, err := m.client.PutObject(ctx, m.config.BucketName, key, file, -1, minio.PutObjectOptions{
-		ContentType: contentType,
-		UserMetadata: map[string]string{
-			"course-id":     courseID,
-			"course-name":   courseName,
-			"week":          fmt.Sprintf("%d", week),
-			"user-email":    userEmail,
-			"upload-type":   "student-pdf-submission",
-			"original-name": filename,
-		},
-	})
-	if err != nil {
-		return "", fmt.Errorf("failed to upload student PDF submission: %w", err)
-	}
-
-	// Generate public URL
-	url := fmt.Sprintf("http://%s/%s/%s", m.config.Endpoint, m.config.BucketName, key)
-	return url, nil
-}
-
-// UploadStudentCodeSubmission uploads a code file submitted by a student for an exercise
-func (m *MinIOService) UploadStudentCodeSubmission(ctx context.Context, courseID, courseName string, week int, userEmail string, file io.Reader, filename, contentType string) (string, error) {
-	// Validate file type - allow common code file types
-	if !m.isAllowedCodeType(contentType) {
-		return "", fmt.Errorf("invalid code file type: %s. Allowed types: Python, JavaScript, Java, C++, C", contentType)
-	}
-
-	// Build new path structure: {courseID-8}/exercise/{emailPrefix}/code/week-{N}/submission_timestamp.{ext}
-	coursePath := m.buildCoursePath(courseID)
-	emailPrefix := m.buildStudentEmailPrefix(userEmail)
-	weekPath := m.buildWeekPath(week)
-	timestampFilename := m.buildTimestampFilename(filename)
-
-	key := coursePath + "exercise/" + emailPrefix + "/code/" + weekPath + timestampFilename
-
-	// Debug: log the key being used
-
-	// Delete existing files in the same week directory to avoid duplicates
-	weekDir := coursePath + "exercise/" + emailPrefix + "/code/" + weekPath
-	m.deleteFilesInDirectory(ctx, weekDir)
-
-	// Upload to MinIO
-	_, err := m.client.PutObject(ctx, m.config.BucketName, key, file, -1, minio.PutObjectOptions{
-		ContentType: contentType,
-		UserMetadata: map[string]string{
-			"course-id":     courseID,
-			"course-name":   courseName,
-			"week":          fmt.Sprintf("%d", week),
-			"user-email":    userEmail,
-			"upload-type":   "student-code-submission",
-			"original-name": filename,
-		},
-	})
-	if err != nil {
-		return "", fmt.Errorf("failed to upload student code submission: %w", err)
-	}
-
-	// Generate public URL
-	url := fmt.Sprintf("http://%s/%s/%s", m.config.Endpoint, m.config.BucketName, key)
-	return url, nil
-}
-
-// UploadStudentFeedbackFile uploads a feedback PDF file for a student submission
-func (m *MinIOService) UploadStudentFeedbackFile(ctx context.Context, courseID, courseName string, week int, userEmail string, file io.Reader, filename, contentType string) (string, error) {
-	// Validate file type - only allow PDF files
-	if contentType != "application/pdf" {
-		return "", fmt.Errorf("invalid file type: %s. Only PDF files are allowed", contentType)
-	}
-
-	// Build new path structure: {courseID-8}/exercise/{emailPrefix}/feedback/week-{N}/feedback_timestamp.pdf
-	coursePath := m.buildCoursePath(courseID)
-	emailPrefix := m.buildStudentEmailPrefix(userEmail)
-	weekPath := m.buildWeekPath(week)
-	feedbackFilename := m.buildFeedbackFilename(filename)
-
-	key := coursePath + "exercise/" + emailPrefix + "/feedback/" + weekPath + feedbackFilename
-
-	// Debug: log the key being used
-
-	// Upload to MinIO (don't delete existing files, allow multiple feedback files)
-	_, err := m.client.PutObject(ctx, m.config.BucketName, key, file, -1, minio.PutObjectOptions{
-		ContentType: contentType,
-		UserMetadata: map[string]string{
-			"course-id":     courseID,
-			"course-name":   courseName,
-			"week":          fmt.Sprintf("%d", week),
-			"user-email":    userEmail,
-			"upload-type":   "student-feedback",
-			"original-name": filename,
-		},
-	})
-	if err != nil {
-		return "", fmt.Errorf("failed to upload student feedback file: %w", err)
-	}
-
-	// Generate public URL
-	url := fmt.Sprintf("http://%s/%s/%s", m.config.Endpoint, m.config.BucketName, key)
-	return url, nil
-}
-
 // DownloadStudentPDFSubmission generates a presigned URL for downloading student PDF submissions
 func (m *MinIOService) DownloadStudentPDFSubmission(ctx context.Context, fileURL string, expiration time.Duration) (string, error) {
 	// Extract key from URL
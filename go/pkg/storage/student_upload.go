@@ -0,0 +1,114 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"io"
+)
+
+// UploadStudentPDFSubmission uploads a PDF file submitted by a student for an exercise
+func (u *uploader) UploadStudentPDFSubmission(ctx context.Context, courseID, courseName string, week int, userEmail string, file io.Reader, filename, contentType string) (string, error) {
+	filename = u.SanitizeFilename(filename)
+
+	// Validate file type - only allow PDF files
+	if contentType != "application/pdf" {
+		return "", fmt.Errorf("invalid file type: %s. Only PDF files are allowed", contentType)
+	}
+
+	// Build new path structure: {courseID-8}/exercise/{emailPrefix}/pdf/week-{N}/submission_timestamp.pdf
+	coursePath := buildCoursePath(courseID)
+	emailPrefix := buildStudentEmailPrefix(userEmail)
+	weekPath := buildWeekPath(week)
+	timestampFilename := buildTimestampFilename(filename)
+
+	key := coursePath + "exercise/" + emailPrefix + "/pdf/" + weekPath + timestampFilename
+
+	// Delete existing files in the same week directory to avoid duplicates
+	weekDir := coursePath + "exercise/" + emailPrefix + "/pdf/" + weekPath
+	u.store.removePrefix(ctx, weekDir)
+
+	// Upload
+	err := u.store.putObject(ctx, key, file, contentType, map[string]string{
+		"course-id":     courseID,
+		"course-name":   courseName,
+		"week":          fmt.Sprintf("%d", week),
+		"user-email":    userEmail,
+		"upload-type":   "student-pdf-submission",
+		"original-name": filename,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to upload student PDF submission: %w", err)
+	}
+
+	return u.store.objectURL(key), nil
+}
+
+// UploadStudentCodeSubmission uploads a code file submitted by a student for an exercise
+func (u *uploader) UploadStudentCodeSubmission(ctx context.Context, courseID, courseName string, week int, userEmail string, file io.Reader, filename, contentType string) (string, error) {
+	filename = u.SanitizeFilename(filename)
+
+	// Validate file type - allow common code file types
+	if !isAllowedCodeType(contentType) {
+		return "", fmt.Errorf("invalid code file type: %s. Allowed types: Python, JavaScript, Java, C++, C", contentType)
+	}
+
+	// Build new path structure: {courseID-8}/exercise/{emailPrefix}/code/week-{N}/submission_timestamp.{ext}
+	coursePath := buildCoursePath(courseID)
+	emailPrefix := buildStudentEmailPrefix(userEmail)
+	weekPath := buildWeekPath(week)
+	timestampFilename := buildTimestampFilename(filename)
+
+	key := coursePath + "exercise/" + emailPrefix + "/code/" + weekPath + timestampFilename
+
+	// Delete existing files in the same week directory to avoid duplicates
+	weekDir := coursePath + "exercise/" + emailPrefix + "/code/" + weekPath
+	u.store.removePrefix(ctx, weekDir)
+
+	// Upload
+	err := u.store.putObject(ctx, key, file, contentType, map[string]string{
+		"course-id":     courseID,
+		"course-name":   courseName,
+		"week":          fmt.Sprintf("%d", week),
+		"user-email":    userEmail,
+		"upload-type":   "student-code-submission",
+		"original-name": filename,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to upload student code submission: %w", err)
+	}
+
+	return u.store.objectURL(key), nil
+}
+
+// UploadStudentFeedbackFile uploads a feedback PDF file for a student submission
+func (u *uploader) UploadStudentFeedbackFile(ctx context.Context, courseID, courseName string, week int, userEmail string, file io.Reader, filename, contentType string) (string, error) {
+	filename = u.SanitizeFilename(filename)
+
+	// Validate file type - only allow PDF files
+	if contentType != "application/pdf" {
+		return "", fmt.Errorf("invalid file type: %s. Only PDF files are allowed", contentType)
+	}
+
+	// Build new path structure: {courseID-8}/exercise/{emailPrefix}/feedback/week-{N}/feedback_timestamp.pdf
+	coursePath := buildCoursePath(courseID)
+	emailPrefix := buildStudentEmailPrefix(userEmail)
+	weekPath := buildWeekPath(week)
+	feedbackFilename := buildFeedbackFilename(filename)
+
+	key := coursePath + "exercise/" + emailPrefix + "/feedback/" + weekPath + feedbackFilename
+
+	// Upload (don't delete existing files, allow multiple feedback files)
+	err := u.store.putObject(ctx, key, file, contentType, map[string]string{
+		"course-id":     courseID,
+		"course-name":   courseName,
+		"week":          fmt.Sprintf("%d", week),
+		"user-email":    userEmail,
+		"upload-type":   "student-feedback",
+		"original-name": filename,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to upload student feedback file: %w", err)
+	}
+
+	return u.store.objectURL(key), nil
+}
@@ -9,11 +9,29 @@ import (
 	"time"
 
 	"github.com/google/uuid"
-	"github.com/minio/minio-go/v7"
 )
 
+// objectStore is the backend-specific part of a storage service: writing, listing and
+// addressing objects by key. Key layout and file type rules live in uploader, so every
+// backend stores files at the same keys.
+type objectStore interface {
+	putObject(ctx context.Context, key string, file io.Reader, contentType string, metadata map[string]string) error
+	// removePrefix deletes every object under prefix, logging failures
+	removePrefix(ctx context.Context, prefix string)
+	objectURL(key string) string
+}
+
+// uploader implements the Upload* methods of StorageService on top of an objectStore
+type uploader struct {
+	store objectStore
+	// Longest filename kept after sanitizing, in bytes; 0 uses DefaultMaxFilenameLength
+	maxFilenameLength int
+}
+
 // UploadCodeFile uploads code files to object storage under codes/{exerciseID}/{userID}/
-func (m *MinIOService) UploadCodeFile(ctx context.Context, userID, exerciseID string, file io.Reader, filename, contentType string) (string, error) {
+func (u *uploader) UploadCodeFile(ctx context.Context, userID, exerciseID string, file io.Reader, filename, contentType string) (string, error) {
+	filename = u.SanitizeFilename(filename)
+
 	// Generate unique filename preserving extension
 	ext := strings.ToLower(filepath.Ext(filename))
 	if ext == "" {
@@ -23,25 +41,22 @@ func (m *MinIOService) UploadCodeFile(ctx context.Context, userID, exerciseID st
 	key := "code/" + exerciseID + "/" + userID + "/" + uniqueFilename
 
 	// Upload
-	_, err := m.client.PutObject(ctx, m.config.BucketName, key, file, -1, minio.PutObjectOptions{
-		ContentType: contentType,
-		UserMetadata: map[string]string{
-			"uploaded-by": userID,
-			"upload-type": "code-file",
-			"exercise-id": exerciseID,
-		},
+	err := u.store.putObject(ctx, key, file, contentType, map[string]string{
+		"uploaded-by": userID,
+		"upload-type": "code-file",
+		"exercise-id": exerciseID,
 	})
 	if err != nil {
 		return "", fmt.Errorf("failed to upload code file: %w", err)
 	}
 
-	// Generate public URL
-	url := fmt.Sprintf("http://%s/%s/%s", m.config.Endpoint, m.config.BucketName, key)
-	return url, nil
+	return u.store.objectURL(key), nil
 }
 
 // UploadPDFFile uploads PDF files to object storage under pdf/{materialID}/{userID}/
-func (m *MinIOService) UploadPDFFile(ctx context.Context, userID, materialID string, file io.Reader, filename, contentType string) (string, error) {
+func (u *uploader) UploadPDFFile(ctx context.Context, userID, materialID string, file io.Reader, filename, contentType string) (string, error) {
+	filename = u.SanitizeFilename(filename)
+
 	// Validate file type - only allow PDF files
 	if contentType != "application/pdf" && contentType != "application/x-pdf" {
 		return "", fmt.Errorf("invalid file type: %s. Only PDF files are allowed", contentType)
@@ -56,28 +71,25 @@ func (m *MinIOService) UploadPDFFile(ctx context.Context, userID, materialID str
 	key := "pdf/" + materialID + "/" + userID + "/" + uniqueFilename
 
 	// Upload
-	_, err := m.client.PutObject(ctx, m.config.BucketName, key, file, -1, minio.PutObjectOptions{
-		ContentType: contentType,
-		UserMetadata: map[string]string{
-			"uploaded-by":   userID,
-			"upload-type":   "pdf-file",
-			"material-id":   materialID,
-			"original-name": filename,
-		},
+	err := u.store.putObject(ctx, key, file, contentType, map[string]string{
+		"uploaded-by":   userID,
+		"upload-type":   "pdf-file",
+		"material-id":   materialID,
+		"original-name": filename,
 	})
 	if err != nil {
 		return "", fmt.Errorf("failed to upload PDF file: %w", err)
 	}
 
-	// Generate public URL
-	url := fmt.Sprintf("http://%s/%s/%s", m.config.Endpoint, m.config.BucketName, key)
-	return url, nil
+	return u.store.objectURL(key), nil
 }
 
 // UploadCourseImage uploads course images
-func (m *MinIOService) UploadCourseImage(ctx context.Context, courseID string, file io.Reader, filename, contentType string) (string, error) {
+func (u *uploader) UploadCourseImage(ctx context.Context, courseID string, file io.Reader, filename, contentType string) (string, error) {
+	filename = u.SanitizeFilename(filename)
+
 	// Validate file type
-	if !m.isAllowedImageType(contentType) {
+	if !isAllowedImageType(contentType) {
 		return "", fmt.Errorf("invalid file type: %s. Allowed types: JPEG, PNG, WebP", contentType)
 	}
 
@@ -103,46 +115,38 @@ func (m *MinIOService) UploadCourseImage(ctx context.Context, courseID string, f
 		ext)
 
 	// Use course ID for path
-	coursePath := m.buildCoursePath(courseID)
+	coursePath := buildCoursePath(courseID)
 	key := coursePath + "image/" + uniqueFilename
 
-	// Upload to MinIO
-	_, err := m.client.PutObject(ctx, m.config.BucketName, key, file, -1, minio.PutObjectOptions{
-		ContentType: contentType,
-		UserMetadata: map[string]string{
-			"uploaded-for": courseID,
-			"upload-type":  "course-image",
-		},
+	// Upload
+	err := u.store.putObject(ctx, key, file, contentType, map[string]string{
+		"uploaded-for": courseID,
+		"upload-type":  "course-image",
 	})
 	if err != nil {
-		return "", fmt.Errorf("failed to upload to MinIO: %w", err)
+		return "", fmt.Errorf("failed to upload course image: %w", err)
 	}
 
-	// Generate public URL
-	url := fmt.Sprintf("http://%s/%s/%s", m.config.Endpoint, m.config.BucketName, key)
-	return url, nil
+	return u.store.objectURL(key), nil
 }
 
 // UploadFile uploads a file with a custom key
-func (m *MinIOService) UploadFile(ctx context.Context, key string, file io.Reader, contentType string) (string, error) {
-	// Upload to MinIO
-	_, err := m.client.PutObject(ctx, m.config.BucketName, key, file, -1, minio.PutObjectOptions{
-		ContentType: contentType,
-		UserMetadata: map[string]string{
-			"upload-type": "course-material",
-		},
+func (u *uploader) UploadFile(ctx context.Context, key string, file io.Reader, contentType string) (string, error) {
+	// Upload
+	err := u.store.putObject(ctx, key, file, contentType, map[string]string{
+		"upload-type": "course-material",
 	})
 	if err != nil {
-		return "", fmt.Errorf("failed to upload file to MinIO: %w", err)
+		return "", fmt.Errorf("failed to upload file: %w", err)
 	}
 
-	// Generate public URL
-	url := fmt.Sprintf("http://%s/%s/%s", m.config.Endpoint, m.config.BucketName, key)
-	return url, nil
+	return u.store.objectURL(key), nil
 }
 
 // UploadCourseMaterialFile uploads course material files based on type
-func (m *MinIOService) UploadCourseMaterialFile(ctx context.Context, courseID, materialID, materialType string, file io.Reader, filename, contentType string) (string, error) {
+func (u *uploader) UploadCourseMaterialFile(ctx context.Context, courseID, materialID, materialType string, file io.Reader, filename, contentType string) (string, error) {
+	filename = u.SanitizeFilename(filename)
+
 	var prefix string
 	var uploadType string
 
@@ -180,75 +184,70 @@ func (m *MinIOService) UploadCourseMaterialFile(ctx context.Context, courseID, m
 	}
 
 	// Use course ID for path
-	coursePath := m.buildCoursePath(courseID)
+	coursePath := buildCoursePath(courseID)
 	key := coursePath + prefix + cleanMaterialID + "/" + uniqueFilename
 
-	// Debug: log the key being used
-
 	// Upload
-	_, err := m.client.PutObject(ctx, m.config.BucketName, key, file, -1, minio.PutObjectOptions{
-		ContentType: contentType,
-		UserMetadata: map[string]string{
-			"course-id":     courseID,
-			"material-id":   materialID,
-			"material-type": materialType,
-			"upload-type":   uploadType,
-			"original-name": filename, // Store original filename in metadata
-		},
+	err := u.store.putObject(ctx, key, file, contentType, map[string]string{
+		"course-id":     courseID,
+		"material-id":   materialID,
+		"material-type": materialType,
+		"upload-type":   uploadType,
+		"original-name": filename, // Store original filename in metadata
 	})
 	if err != nil {
 		return "", fmt.Errorf("failed to upload course material file: %w", err)
 	}
 
-	// Generate public URL
-	url := fmt.Sprintf("http://%s/%s/%s", m.config.Endpoint, m.config.BucketName, key)
-	return url, nil
+	return u.store.objectURL(key), nil
 }
 
 // UploadCourseVideo uploads video files for course materials
-func (m *MinIOService) UploadCourseVideo(ctx context.Context, courseID, materialID string, file io.Reader, filename, contentType string) (string, error) {
+func (u *uploader) UploadCourseVideo(ctx context.Context, courseID, materialID string, file io.Reader, filename, contentType string) (string, error) {
 	// Validate file type
-	if !m.isAllowedVideoType(contentType) {
+	if !isAllowedVideoType(contentType) {
 		return "", fmt.Errorf("invalid video file type: %s. Allowed types: MP4, WebM, AVI", contentType)
 	}
 
-	return m.UploadCourseMaterialFile(ctx, courseID, materialID, "video", file, filename, contentType)
+	return u.UploadCourseMaterialFile(ctx, courseID, materialID, "video", file, filename, contentType)
 }
 
 // UploadCourseDocument uploads document files for course materials
-func (m *MinIOService) UploadCourseDocument(ctx context.Context, courseID, materialID string, file io.Reader, filename, contentType string) (string, error) {
+func (u *uploader) UploadCourseDocument(ctx context.Context, courseID, materialID string, file io.Reader, filename, contentType string) (string, error) {
 	// Validate file type
-	if !m.isAllowedDocumentType(contentType) {
+	if !isAllowedDocumentType(contentType) {
 		return "", fmt.Errorf("invalid document file type: %s. Allowed types: PDF, DOC, DOCX, TXT", contentType)
 	}
 
-	return m.UploadCourseMaterialFile(ctx, courseID, materialID, "document", file, filename, contentType)
+	return u.UploadCourseMaterialFile(ctx, courseID, materialID, "document", file, filename, contentType)
 }
 
 // UploadCourseCodeFile uploads code files for course materials
-func (m *MinIOService) UploadCourseCodeFile(ctx context.Context, courseID, materialID string, file io.Reader, filename, contentType string) (string, error) {
+func (u *uploader) UploadCourseCodeFile(ctx context.Context, courseID, materialID string, file io.Reader, filename, contentType string) (string, error) {
 	// Validate file type
-	if !m.isAllowedCodeType(contentType) {
+	if !isAllowedCodeType(contentType) {
 		return "", fmt.Errorf("invalid code file type: %s. Allowed types: Python, JavaScript, Java, C++, etc", contentType)
 	}
 
-	return m.UploadCourseMaterialFile(ctx, courseID, materialID, "code_exercise", file, filename, contentType)
+	return u.UploadCourseMaterialFile(ctx, courseID, materialID, "code_exercise", file, filename, contentType)
 }
 
 // UploadCoursePDFFile uploads PDF and image files for course materials
-func (m *MinIOService) UploadCoursePDFFile(ctx context.Context, courseID, materialID string, file io.Reader, filename, contentType string) (string, error) {
+func (u *uploader) UploadCoursePDFFile(ctx context.Context, courseID, materialID string, file io.Reader, filename, contentType string) (string, error) {
 	// Validate file type - allow both PDF and image files
-	if contentType != "application/pdf" && !m.isAllowedImageType(contentType) {
+	if contentType != "application/pdf" && !isAllowedImageType(contentType) {
 		return "", fmt.Errorf("invalid file type: %s. Only PDF files and images (JPEG, PNG, WebP) are allowed", contentType)
 	}
 
-	return m.UploadCourseMaterialFile(ctx, courseID, materialID, "pdf_exercise", file, filename, contentType)
+	return u.UploadCourseMaterialFile(ctx, courseID, materialID, "pdf_exercise", file, filename, contentType)
 }
 
 // UploadExerciseCodeFile uploads code files for exercise templates/materials
-func (m *MinIOService) UploadExerciseCodeFile(ctx context.Context, courseID, materialID string, file io.Reader, filename, contentType string) (string, error) {
+func (u *uploader) UploadExerciseCodeFile(ctx context.Context, courseID, materialID string, file io.Reader, filename, contentType string) (string, error) {
+	filename = u.SanitizeFilename(filename)
+
 	// Validate file type
-	if !m.isAllowedCodeType(contentType) {
+	if !isAllowedCodeType(contentType) {
 		return "", fmt.Errorf("invalid code file type: %s. Allowed types: Python, JavaScript, Java, C++, etc", contentType)
 	}
 
@@ -269,34 +268,29 @@ func (m *MinIOService) UploadExerciseCodeFile(ctx context.Context, courseID, mat
 	}
 
 	// Use course ID for path
-	coursePath := m.buildCoursePath(courseID)
+	coursePath := buildCoursePath(courseID)
 	key := coursePath + "exercise/code/" + cleanMaterialID + "/" + uniqueFilename
 
-	// Debug: log the key being used
-
 	// Upload
-	_, err := m.client.PutObject(ctx, m.config.BucketName, key, file, -1, minio.PutObjectOptions{
-		ContentType: contentType,
-		UserMetadata: map[string]string{
-			"course-id":     courseID,
-			"material-id":   materialID,
-			"upload-type":   "exercise-code",
-			"original-name": filename,
-		},
+	err := u.store.putObject(ctx, key, file, contentType, map[string]string{
+		"course-id":     courseID,
+		"material-id":   materialID,
+		"upload-type":   "exercise-code",
+		"original-name": filename,
 	})
 	if err != nil {
 		return "", fmt.Errorf("failed to upload exercise code file: %w", err)
 	}
 
-	// Generate public URL
-	url := fmt.Sprintf("http://%s/%s/%s", m.config.Endpoint, m.config.BucketName, key)
-	return url, nil
+	return u.store.objectURL(key), nil
 }
 
 // UploadExerciseImage uploads images for exercise problem statements
-func (m *MinIOService) UploadExerciseImage(ctx context.Context, courseID, materialID string, file io.Reader, filename, contentType string) (string, error) {
+func (u *uploader) UploadExerciseImage(ctx context.Context, courseID, materialID string, file io.Reader, filename, contentType string) (string, error) {
+	filename = u.SanitizeFilename(filename)
+
 	// Validate file type - only allow image files
-	if !m.isAllowedImageType(contentType) {
+	if !isAllowedImageType(contentType) {
 		return "", fmt.Errorf("invalid image file type: %s. Allowed types: JPEG, PNG, WebP, GIF", contentType)
 	}
 
@@ -317,34 +311,29 @@ func (m *MinIOService) UploadExerciseImage(ctx context.Context, courseID, materi
 	}
 
 	// Use course ID for path
-	coursePath := m.buildCoursePath(courseID)
+	coursePath := buildCoursePath(courseID)
 	key := coursePath + "exercise/image/" + cleanMaterialID + "/" + uniqueFilename
 
-	// Debug: log the key being used
-
 	// Upload
-	_, err := m.client.PutObject(ctx, m.config.BucketName, key, file, -1, minio.PutObjectOptions{
-		ContentType: contentType,
-		UserMetadata: map[string]string{
-			"course-id":     courseID,
-			"material-id":   materialID,
-			"upload-type":   "exercise-image",
-			"original-name": filename,
-		},
+	err := u.store.putObject(ctx, key, file, contentType, map[string]string{
+		"course-id":     courseID,
+		"material-id":   materialID,
+		"upload-type":   "exercise-image",
+		"original-name": filename,
 	})
 	if err != nil {
 		return "", fmt.Errorf("failed to upload exercise image: %w", err)
 	}
 
-	// Generate public URL
-	url := fmt.Sprintf("http://%s/%s/%s", m.config.Endpoint, m.config.BucketName, key)
-	return url, nil
+	return u.store.objectURL(key), nil
 }
 
 // UploadProblemImage uploads images for exercise problem statements
-func (m *MinIOService) UploadProblemImage(ctx context.Context, courseID, materialID string, file io.Reader, filename, contentType string) (string, error) {
+func (u *uploader) UploadProblemImage(ctx context.Context, courseID, materialID string, file io.Reader, filename, contentType string) (string, error) {
+	filename = u.SanitizeFilename(filename)
+
 	// Validate file type - only allow image files
-	if !m.isAllowedImageType(contentType) {
+	if !isAllowedImageType(contentType) {
 		return "", fmt.Errorf("invalid image file type: %s. Allowed types: JPEG, PNG, WebP, GIF", contentType)
 	}
 
@@ -365,26 +354,19 @@ func (m *MinIOService) UploadProblemImage(ctx context.Context, courseID, materia
 	}
 
 	// Use course ID for path
-	coursePath := m.buildCoursePath(courseID)
+	coursePath := buildCoursePath(courseID)
 	key := coursePath + "exercise/image/" + cleanMaterialID + "/" + uniqueFilename
 
-	// Debug: log the key being used
-
 	// Upload
-	_, err := m.client.PutObject(ctx, m.config.BucketName, key, file, -1, minio.PutObjectOptions{
-		ContentType: contentType,
-		UserMetadata: map[string]string{
-			"course-id":     courseID,
-			"material-id":   materialID,
-			"upload-type":   "problem-image",
-			"original-name": filename,
-		},
+	err := u.store.putObject(ctx, key, file, contentType, map[string]string{
+		"course-id":     courseID,
+		"material-id":   materialID,
+		"upload-type":   "problem-image",
+		"original-name": filename,
 	})
 	if err != nil {
 		return "", fmt.Errorf("failed to upload problem image: %w", err)
 	}
 
-	// Generate public URL
-	url := fmt.Sprintf("http://%s/%s/%s", m.config.Endpoint, m.config.BucketName, key)
-	return url, nil
+	return u.store.objectURL(key), nil
 }
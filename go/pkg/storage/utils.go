@@ -1,18 +1,14 @@
 package storage
 
 import (
-	"context"
 	"fmt"
 	"path/filepath"
 	"strings"
 	"time"
-
-	"github.com/Project-DSView/backend/go/pkg/logger"
-	"github.com/minio/minio-go/v7"
 )
 
 // Helper methods for file type validation
-func (m *MinIOService) isAllowedImageType(contentType string) bool {
+func isAllowedImageType(contentType string) bool {
 	allowedTypes := []string{
 		"image/jpeg",
 		"image/jpg",
@@ -28,7 +24,7 @@ func (m *MinIOService) isAllowedImageType(contentType string) bool {
 	return false
 }
 
-func (m *MinIOService) isAllowedVideoType(contentType string) bool {
+func isAllowedVideoType(contentType string) bool {
 	allowedTypes := []string{
 		"video/mp4",
 		"video/webm",
@@ -44,7 +40,7 @@ func (m *MinIOService) isAllowedVideoType(contentType string) bool {
 	return false
 }
 
-func (m *MinIOService) isAllowedDocumentType(contentType string) bool {
+func isAllowedDocumentType(contentType string) bool {
 	allowedTypes := []string{
 		"application/pdf",
 		"application/msword",
@@ -62,7 +58,7 @@ func (m *MinIOService) isAllowedDocumentType(contentType string) bool {
 	return false
 }
 
-func (m *MinIOService) isAllowedCodeType(contentType string) bool {
+func isAllowedCodeType(contentType string) bool {
 	allowedTypes := []string{
 		"text/plain",
 		"text/x-python",
@@ -90,7 +86,13 @@ func (m *MinIOService) isAllowedCodeType(contentType string) bool {
 // Helper methods for building paths
 
 // buildCoursePath creates: {shortID}/
-func (m *MinIOService) buildCoursePath(courseID string) string {
+// IsCourseKey reports whether key lies under the course's storage path, as the keys of
+// every file uploaded for the course do
+func IsCourseKey(courseID, key string) bool {
+	return strings.HasPrefix(key, buildCoursePath(courseID)) && !strings.Contains(key, "..")
+}
+
+func buildCoursePath(courseID string) string {
 	cleanCourseID := strings.ReplaceAll(courseID, "/", "_")
 	cleanCourseID = strings.ReplaceAll(cleanCourseID, "\\", "_")
 
@@ -104,7 +106,7 @@ func (m *MinIOService) buildCoursePath(courseID string) string {
 }
 
 // buildStudentEmailPrefix extracts email prefix (before @)
-func (m *MinIOService) buildStudentEmailPrefix(email string) string {
+func buildStudentEmailPrefix(email string) string {
 	// Extract part before @ and clean it
 	parts := strings.Split(email, "@")
 	if len(parts) > 0 {
@@ -114,12 +116,12 @@ func (m *MinIOService) buildStudentEmailPrefix(email string) string {
 }
 
 // buildWeekPath creates: week-{N}/
-func (m *MinIOService) buildWeekPath(week int) string {
+func buildWeekPath(week int) string {
 	return fmt.Sprintf("week-%d/", week)
 }
 
 // buildTimestampFilename creates: submission_YYYYMMDD_HHMMSS.{ext}
-func (m *MinIOService) buildTimestampFilename(originalFilename string) string {
+func buildTimestampFilename(originalFilename string) string {
 	ext := strings.ToLower(filepath.Ext(originalFilename))
 	if ext == "" {
 		ext = ".pdf" // default for submissions
@@ -133,7 +135,7 @@ func (m *MinIOService) buildTimestampFilename(originalFilename string) string {
 }
 
 // buildFeedbackFilename creates: feedback_YYYYMMDD_HHMMSS.{ext}
-func (m *MinIOService) buildFeedbackFilename(originalFilename string) string {
+func buildFeedbackFilename(originalFilename string) string {
 	ext := strings.ToLower(filepath.Ext(originalFilename))
 	if ext == "" {
 		ext = ".pdf" // default for feedback files
@@ -145,27 +147,3 @@ func (m *MinIOService) buildFeedbackFilename(originalFilename string) string {
 		now.Format("150405"),
 		ext)
 }
-
-// deleteFilesInDirectory deletes all files in a specific directory
-func (m *MinIOService) deleteFilesInDirectory(ctx context.Context, directoryPath string) {
-	// List objects with the directory prefix
-	objectCh := m.client.ListObjects(ctx, m.config.BucketName, minio.ListObjectsOptions{
-		Prefix:    directoryPath,
-		Recursive: true,
-	})
-
-	// Delete each object
-	for object := range objectCh {
-		if object.Err != nil {
-			logger.Errorf("Error listing object: %v", object.Err)
-			continue
-		}
-
-		err := m.client.RemoveObject(ctx, m.config.BucketName, object.Key, minio.RemoveObjectOptions{})
-		if err != nil {
-			logger.Errorf("Error deleting object %s: %v", object.Key, err)
-		} else {
-
-		}
-	}
-}
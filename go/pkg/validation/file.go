@@ -81,18 +81,6 @@ func ValidatePythonFile(filename string, content []byte) error {
 	return nil
 }
 
-// SanitizeFilename ทำความสะอาดชื่อไฟล์
-func SanitizeFilename(filename string) string {
-	// ลบ path และเอาแต่ชื่อไฟล์
-	filename = filepath.Base(filename)
-
-	// แทนที่อักขระที่ไม่ปลอดภัย
-	filename = strings.ReplaceAll(filename, " ", "_")
-	filename = strings.ReplaceAll(filename, "..", ".")
-
-	return filename
-}
-
 // ValidateCodeContent ตรวจสอบเนื้อหาโค้ดโดยตรง
 func ValidateCodeContent(code string) error {
 	if strings.TrimSpace(code) == "" {
@@ -111,3 +99,17 @@ func ValidateCodeContent(code string) error {
 
 	return nil
 }
+
+// FormatFileSize renders a byte count the way size limits are configured, e.g. "10MB"
+func FormatFileSize(size int64) string {
+	switch {
+	case size >= 1024*1024*1024 && size%(1024*1024*1024) == 0:
+		return fmt.Sprintf("%dGB", size/(1024*1024*1024))
+	case size >= 1024*1024 && size%(1024*1024) == 0:
+		return fmt.Sprintf("%dMB", size/(1024*1024))
+	case size >= 1024 && size%1024 == 0:
+		return fmt.Sprintf("%dKB", size/1024)
+	default:
+		return fmt.Sprintf("%d bytes", size)
+	}
+}
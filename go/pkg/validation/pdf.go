@@ -0,0 +1,47 @@
+package validation
+
+import (
+	"bytes"
+	"compress/zlib"
+	"io"
+	"regexp"
+)
+
+// maxObjectStreamSize caps how much of a single compressed object stream is inflated
+const maxObjectStreamSize = 4 * 1024 * 1024
+
+var (
+	// "/Type /Page" but not "/Type /Pages"
+	pdfPageObject   = regexp.MustCompile(`/Type\s*/Page\b`)
+	pdfObjectStream = regexp.MustCompile(`/Type\s*/ObjStm\b`)
+	pdfStreamStart  = regexp.MustCompile(`stream\r?\n`)
+)
+
+// CountPDFPages counts the page objects in a PDF, including those packed into
+// compressed object streams. It returns 0 when no pages can be found (e.g. an
+// encrypted or malformed file), so callers should treat 0 as "unknown".
+func CountPDFPages(content []byte) int {
+	count := len(pdfPageObject.FindAllIndex(content, -1))
+
+	for _, loc := range pdfObjectStream.FindAllIndex(content, -1) {
+		rest := content[loc[1]:]
+		start := pdfStreamStart.FindIndex(rest)
+		if start == nil {
+			continue
+		}
+		data := rest[start[1]:]
+		if end := bytes.Index(data, []byte("endstream")); end >= 0 {
+			data = data[:end]
+		}
+
+		r, err := zlib.NewReader(bytes.NewReader(data))
+		if err != nil {
+			continue
+		}
+		inflated, _ := io.ReadAll(io.LimitReader(r, maxObjectStreamSize))
+		r.Close()
+		count += len(pdfPageObject.FindAllIndex(inflated, -1))
+	}
+
+	return count
+}